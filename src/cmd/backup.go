@@ -0,0 +1,306 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Export the chat storage to a backup archive",
+	Long: `Export chats, messages, devices and the other chat storage tables to a gzipped tar archive holding one ` +
+		`NDJSON file per table. Backups don't depend on the SQL dialect, so a SQLite backup can be restored into ` +
+		`PostgreSQL or MySQL.`,
+	Run: backupChatStorage,
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore the chat storage from a backup archive",
+	Long: `Load a backup made with the backup command into the chat storage. Each table is restored in its own ` +
+		`transaction, overwriting rows with the same key. Restoring while the server runs would race with it, so ` +
+		`restore refuses to run when something listens on the server port unless --force is given.`,
+	Run: restoreChatStorage,
+}
+
+var (
+	backupOut      string
+	backupDeviceID string
+
+	restoreIn       string
+	restoreDeviceID string
+	restoreForce    bool
+)
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	backupCmd.Flags().StringVar(&backupOut, "out", "", "path of the backup archive to write, e.g. backup.tar.gz")
+	backupCmd.Flags().StringVar(&backupDeviceID, "device-id", "", "only back up this device's data")
+	_ = backupCmd.MarkFlagRequired("out")
+
+	rootCmd.AddCommand(restoreCmd)
+	restoreCmd.Flags().StringVar(&restoreIn, "in", "", "path of the backup archive to restore")
+	restoreCmd.Flags().StringVar(&restoreDeviceID, "device-id", "", "only restore this device's data")
+	restoreCmd.Flags().BoolVar(&restoreForce, "force", false, "restore even though the server seems to be running")
+	_ = restoreCmd.MarkFlagRequired("in")
+}
+
+// backupFormatVersion is bumped when archives change in a way older versions
+// can't restore.
+const backupFormatVersion = 1
+
+const backupManifestName = "manifest.json"
+
+// backupManifest is the first file of a backup archive.
+type backupManifest struct {
+	FormatVersion int                   `json:"format_version"`
+	AppVersion    string                `json:"app_version"`
+	CreatedAt     time.Time             `json:"created_at"`
+	DeviceID      string                `json:"device_id,omitempty"`
+	Tables        []backupManifestTable `json:"tables"`
+}
+
+type backupManifestTable struct {
+	Name string `json:"name"`
+	Rows int64  `json:"rows"`
+}
+
+// tableReport is called once a table has been exported or restored.
+type tableReport func(table string, rows int64, took time.Duration)
+
+func printTableReport(out io.Writer) tableReport {
+	return func(table string, rows int64, took time.Duration) {
+		fmt.Fprintf(out, "%-20s %8d rows  %s\n", table, rows, took.Round(time.Millisecond))
+	}
+}
+
+func backupChatStorage(cmd *cobra.Command, _ []string) {
+	started := time.Now()
+	out := cmd.OutOrStdout()
+
+	file, err := os.Create(backupOut)
+	if err != nil {
+		logrus.Fatalf("failed to create backup: %v", err)
+	}
+	manifest, err := writeBackup(context.Background(), chatStorageRepo, file, backupDeviceID, printTableReport(out))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(backupOut)
+		logrus.Fatalf("failed to back up chat storage: %v", err)
+	}
+	fmt.Fprintf(out, "Backed up %d rows from %d tables to %s in %s\n",
+		manifest.totalRows(), len(manifest.Tables), backupOut, time.Since(started).Round(time.Millisecond))
+}
+
+func restoreChatStorage(cmd *cobra.Command, _ []string) {
+	if !restoreForce && serverRunning() {
+		logrus.Fatalf("the server seems to be running on %s, stop it before restoring or pass --force", net.JoinHostPort(config.AppHost, config.AppPort))
+	}
+	started := time.Now()
+	out := cmd.OutOrStdout()
+
+	file, err := os.Open(restoreIn)
+	if err != nil {
+		logrus.Fatalf("failed to open backup: %v", err)
+	}
+	defer file.Close()
+
+	restored, err := readBackup(context.Background(), chatStorageRepo, file, restoreDeviceID, printTableReport(out))
+	if err != nil {
+		logrus.Fatalf("failed to restore chat storage: %v", err)
+	}
+	fmt.Fprintf(out, "Restored %d rows from %d tables in %s\n",
+		restored.totalRows(), len(restored.Tables), time.Since(started).Round(time.Millisecond))
+}
+
+func (m backupManifest) totalRows() int64 {
+	var rows int64
+	for _, table := range m.Tables {
+		rows += table.Rows
+	}
+	return rows
+}
+
+// serverRunning reports whether something accepts connections on the server
+// port.
+func serverRunning() bool {
+	host := config.AppHost
+	if host == "" || net.ParseIP(host) != nil && net.ParseIP(host).IsUnspecified() {
+		host = "127.0.0.1"
+	}
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, config.AppPort), time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// writeBackup exports every backup table, or only deviceID's rows, as a
+// gzipped tar archive to w. Tables are staged in temporary files first, as
+// tar headers need their size and the manifest their row counts.
+func writeBackup(ctx context.Context, repo domainChatStorage.IChatStorageRepository, w io.Writer, deviceID string, report tableReport) (backupManifest, error) {
+	manifest := backupManifest{
+		FormatVersion: backupFormatVersion,
+		AppVersion:    config.AppVersion,
+		CreatedAt:     time.Now().UTC(),
+		DeviceID:      deviceID,
+	}
+
+	dir, err := os.MkdirTemp("", "gowa-backup-")
+	if err != nil {
+		return manifest, err
+	}
+	defer os.RemoveAll(dir)
+
+	for _, table := range chatstorage.BackupTables() {
+		if deviceID != "" && !chatstorage.IsDeviceTable(table) {
+			continue
+		}
+		started := time.Now()
+		rows, err := exportTableFile(ctx, repo, table, deviceID, filepath.Join(dir, table+".ndjson"))
+		if err != nil {
+			return manifest, err
+		}
+		manifest.Tables = append(manifest.Tables, backupManifestTable{Name: table, Rows: rows})
+		report(table, rows, time.Since(started))
+	}
+
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+	encoded, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return manifest, err
+	}
+	if err := writeTarFile(archive, backupManifestName, bytes.NewReader(encoded), int64(len(encoded))); err != nil {
+		return manifest, err
+	}
+	for _, table := range manifest.Tables {
+		if err := copyTableFile(archive, filepath.Join(dir, table.Name+".ndjson")); err != nil {
+			return manifest, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return manifest, err
+	}
+	return manifest, gz.Close()
+}
+
+func exportTableFile(ctx context.Context, repo domainChatStorage.IChatStorageRepository, table, deviceID, path string) (int64, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	buffered := bufio.NewWriter(file)
+	var rows int64
+	err = repo.ExportTable(ctx, table, deviceID, func(row []byte) error {
+		rows++
+		if _, err := buffered.Write(row); err != nil {
+			return err
+		}
+		return buffered.WriteByte('\n')
+	})
+	if err != nil {
+		return rows, err
+	}
+	return rows, buffered.Flush()
+}
+
+func copyTableFile(archive *tar.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	return writeTarFile(archive, filepath.Base(path), file, info.Size())
+}
+
+func writeTarFile(archive *tar.Writer, name string, content io.Reader, size int64) error {
+	header := &tar.Header{Name: name, Mode: 0o600, Size: size, ModTime: time.Now()}
+	if err := archive.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := io.Copy(archive, content)
+	return err
+}
+
+// readBackup restores the archive in r, or only deviceID's rows of it. It
+// returns the tables it restored with the rows each had.
+func readBackup(ctx context.Context, repo domainChatStorage.IChatStorageRepository, r io.Reader, deviceID string, report tableReport) (backupManifest, error) {
+	var restored backupManifest
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return restored, fmt.Errorf("not a backup archive: %w", err)
+	}
+	defer gz.Close()
+	archive := tar.NewReader(gz)
+
+	header, err := archive.Next()
+	if err != nil || header.Name != backupManifestName {
+		return restored, errors.New("not a backup archive: missing " + backupManifestName)
+	}
+	if err := json.NewDecoder(archive).Decode(&restored); err != nil {
+		return restored, fmt.Errorf("invalid %s: %w", backupManifestName, err)
+	}
+	if restored.FormatVersion > backupFormatVersion {
+		return restored, fmt.Errorf("backup format %d is newer than this version supports (%d)", restored.FormatVersion, backupFormatVersion)
+	}
+	restored.Tables = nil
+
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			return restored, nil
+		}
+		if err != nil {
+			return restored, err
+		}
+		table, ok := strings.CutSuffix(header.Name, ".ndjson")
+		if !ok {
+			return restored, fmt.Errorf("unexpected file %s in backup", header.Name)
+		}
+
+		started := time.Now()
+		lines := bufio.NewReader(archive)
+		rows, err := repo.ImportTable(ctx, table, deviceID, func() ([]byte, error) {
+			line, err := lines.ReadBytes('\n')
+			if errors.Is(err, io.EOF) && len(line) > 0 {
+				return line, nil
+			}
+			return line, err
+		})
+		if err != nil {
+			return restored, err
+		}
+		restored.Tables = append(restored.Tables, backupManifestTable{Name: table, Rows: rows})
+		report(table, rows, time.Since(started))
+	}
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBackupRepository(t *testing.T) domainChatStorage.IChatStorageRepository {
+	t.Helper()
+	db, err := chatstorage.OpenSQLite(context.Background(), filepath.Join(t.TempDir(), "chatstorage.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+	return repo
+}
+
+func TestBackupRestore(t *testing.T) {
+	ctx := context.Background()
+	source := newBackupRepository(t)
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for _, device := range []string{"dev-1", "dev-2"} {
+		require.NoError(t, source.StoreChat(ctx, &domainChatStorage.Chat{JID: "628123@s.whatsapp.net", DeviceID: device, Name: "Bob", LastMessageTime: base}))
+		_, err := source.StoreMessagesBatch(ctx, []*domainChatStorage.Message{
+			{ID: "A", ChatJID: "628123@s.whatsapp.net", DeviceID: device, Content: "hi\nthere", Timestamp: base},
+			{ID: "B", ChatJID: "628123@s.whatsapp.net", DeviceID: device, Content: "bye", Timestamp: base.Add(time.Minute)},
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, source.StoreAPIKey(ctx, &domainChatStorage.APIKey{ID: "key-1", KeyHash: "hash", CreatedAt: base}))
+
+	reported := make(map[string]int64)
+	report := func(table string, rows int64, _ time.Duration) { reported[table] = rows }
+
+	var archive bytes.Buffer
+	manifest, err := writeBackup(ctx, source, &archive, "", report)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), manifest.totalRows())
+	assert.Len(t, manifest.Tables, len(chatstorage.BackupTables()))
+	assert.Equal(t, int64(4), reported["messages"])
+
+	t.Run("restores every device", func(t *testing.T) {
+		target := newBackupRepository(t)
+		restored, err := readBackup(ctx, target, bytes.NewReader(archive.Bytes()), "", func(string, int64, time.Duration) {})
+		require.NoError(t, err)
+		assert.Equal(t, manifest.Tables, restored.Tables)
+
+		message, err := target.GetMessageByDevice(ctx, "dev-2", "A")
+		require.NoError(t, err)
+		require.NotNil(t, message)
+		assert.Equal(t, "hi\nthere", message.Content)
+		assert.True(t, base.Equal(message.Timestamp))
+	})
+
+	t.Run("restores one device", func(t *testing.T) {
+		target := newBackupRepository(t)
+		restored, err := readBackup(ctx, target, bytes.NewReader(archive.Bytes()), "dev-1", func(string, int64, time.Duration) {})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), restored.totalRows())
+
+		message, err := target.GetMessageByDevice(ctx, "dev-2", "A")
+		require.NoError(t, err)
+		assert.Nil(t, message)
+		keys, err := target.ListAPIKeys(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+
+	t.Run("backs up one device", func(t *testing.T) {
+		var deviceArchive bytes.Buffer
+		manifest, err := writeBackup(ctx, source, &deviceArchive, "dev-2", func(string, int64, time.Duration) {})
+		require.NoError(t, err)
+		assert.Equal(t, "dev-2", manifest.DeviceID)
+		assert.Equal(t, int64(3), manifest.totalRows())
+		for _, table := range manifest.Tables {
+			assert.NotEqual(t, "api_keys", table.Name)
+		}
+	})
+
+	t.Run("rejects other archives", func(t *testing.T) {
+		_, err := readBackup(ctx, newBackupRepository(t), bytes.NewReader([]byte("not gzip")), "", func(string, int64, time.Duration) {})
+		assert.ErrorContains(t, err, "not a backup archive")
+	})
+}
+
+func TestServerRunning(t *testing.T) {
+	host, port := config.AppHost, config.AppPort
+	t.Cleanup(func() { config.AppHost, config.AppPort = host, port })
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	_, config.AppPort, _ = net.SplitHostPort(listener.Addr().String())
+	config.AppHost = "0.0.0.0"
+	assert.True(t, serverRunning())
+
+	require.NoError(t, listener.Close())
+	assert.False(t, serverRunning())
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var chatsCmd = &cobra.Command{
+	Use:   "chats",
+	Short: "Maintain the chat storage",
+}
+
+var chatsDedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Merge chats split across JID variants",
+	Long: `Find chats stored under agent or device variants of a user JID (e.g. 5511999.0:12@s.whatsapp.net) and merge them, ` +
+		`with their messages, into the canonical JID (5511999@s.whatsapp.net). All merges are applied in one transaction.`,
+	Run: chatsDedupe,
+}
+
+var chatsDedupeDryRun bool
+
+func init() {
+	rootCmd.AddCommand(chatsCmd)
+	chatsCmd.AddCommand(chatsDedupeCmd)
+	chatsDedupeCmd.Flags().BoolVar(&chatsDedupeDryRun, "dry-run", false, "print the merge plan without applying it")
+}
+
+func chatsDedupe(cmd *cobra.Command, _ []string) {
+	merges, err := chatStorageRepo.DedupeChats(context.Background(), chatsDedupeDryRun)
+	if err != nil {
+		logrus.Fatalf("failed to dedupe chats: %v", err)
+	}
+
+	out := cmd.OutOrStdout()
+	var messages int64
+	for _, merge := range merges {
+		fmt.Fprintf(out, "[%s] %s -> %s (%d messages)\n", merge.DeviceID, merge.FromJID, merge.ToJID, merge.Messages)
+		messages += merge.Messages
+	}
+	if chatsDedupeDryRun {
+		fmt.Fprintf(out, "Dry run: %d chats with %d messages would be merged\n", len(merges), messages)
+		return
+	}
+	fmt.Fprintf(out, "Merged %d chats, moved %d messages\n", len(merges), messages)
+}
@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 
 	"github.com/sirupsen/logrus"
 
@@ -67,7 +69,12 @@ func mcpServer(_ *cobra.Command, _ []string) {
 	logrus.Printf("SSE endpoint: http://%s:%s/sse", config.McpHost, config.McpPort)
 	logrus.Printf("Message endpoint: http://%s:%s/message", config.McpHost, config.McpPort)
 
-	if err := sseServer.Start(addr); err != nil {
-		logrus.Fatalf("Failed to start SSE server: %v", err)
-	}
+	go func() {
+		if err := sseServer.Start(addr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.Fatalf("Failed to start SSE server: %v", err)
+		}
+	}()
+
+	waitForShutdownSignal()
+	shutdown(sseServer.Shutdown, nil)
 }
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyChatStorage(t *testing.T) {
+	ctx := context.Background()
+	source := newBackupRepository(t)
+	target := newBackupRepository(t)
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	var messages []*domainChatStorage.Message
+	for i, id := range []string{"A", "B", "C", "D", "E"} {
+		messages = append(messages, &domainChatStorage.Message{
+			ID: id, ChatJID: "628123@s.whatsapp.net", DeviceID: "dev-1", Content: id, MediaKey: []byte{byte(i)}, Timestamp: base.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	_, err := source.StoreMessagesBatch(ctx, messages)
+	require.NoError(t, err)
+	require.NoError(t, source.StoreChat(ctx, &domainChatStorage.Chat{JID: "628123@s.whatsapp.net", DeviceID: "dev-1", Name: "Bob"}))
+
+	progress := make(map[string][]int64)
+	require.NoError(t, copyChatStorage(ctx, source, target, 2, func(table string, copied, total int64) {
+		progress[table] = append(progress[table], copied)
+	}))
+	assert.Equal(t, []int64{2, 4, 5}, progress["messages"], "messages are copied two at a time")
+	assert.Equal(t, []int64{0}, progress["calls"])
+
+	counts, err := compareTableCounts(ctx, source, target)
+	require.NoError(t, err)
+	assert.Len(t, counts, len(chatstorage.BackupTables()))
+	for _, count := range counts {
+		assert.Equal(t, count.Source, count.Target, count.Table)
+	}
+	message, err := target.GetMessageByDevice(ctx, "dev-1", "C")
+	require.NoError(t, err)
+	require.NotNil(t, message)
+	assert.Equal(t, []byte{2}, message.MediaKey)
+
+	t.Run("copying again changes nothing", func(t *testing.T) {
+		require.NoError(t, copyChatStorage(ctx, source, target, 1000, func(string, int64, int64) {}))
+		again, err := compareTableCounts(ctx, source, target)
+		require.NoError(t, err)
+		assert.Equal(t, counts, again)
+	})
+
+	t.Run("reports mismatches", func(t *testing.T) {
+		require.NoError(t, target.StoreChat(ctx, &domainChatStorage.Chat{JID: "628999@s.whatsapp.net", DeviceID: "dev-1"}))
+		counts, err := compareTableCounts(ctx, source, target)
+		require.NoError(t, err)
+		for _, count := range counts {
+			if count.Table == "chats" {
+				assert.Equal(t, tableCount{Table: "chats", Source: 1, Target: 2}, count)
+			}
+		}
+	})
+}
@@ -1,22 +1,23 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/ratelimit"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/rest"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/rest/helpers"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/rest/middleware"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/websocket"
 	"github.com/dustin/go-humanize"
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/basicauth"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/filesystem"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/template/html/v2"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -34,6 +35,11 @@ func init() {
 	rootCmd.AddCommand(restCmd)
 }
 func restServer(_ *cobra.Command, _ []string) {
+	// Jobs still queued or running were cut short when the server last stopped
+	if err := jobUsecase.InterruptJobs(context.Background()); err != nil {
+		logrus.Errorf("Failed to mark unfinished jobs: %v", err)
+	}
+
 	engine := html.NewFileSystem(http.FS(EmbedIndex), ".html")
 	engine.AddFunc("isEnableBasicAuth", func(token any) bool {
 		return token != nil
@@ -65,97 +71,135 @@ func restServer(_ *cobra.Command, _ []string) {
 		Browse:     true,
 	}))
 
+	// Requests in progress, which shutdown waits for
+	var requests utils.InFlight
+
+	app.Use(middleware.RequestID())
+	app.Use(middleware.Draining(&requests))
+	if config.AppDebug {
+		app.Use(middleware.RequestLog())
+	}
 	app.Use(middleware.Recovery())
 	app.Use(middleware.RequestTimeout(middleware.DefaultRequestTimeout))
 	app.Use(middleware.BasicAuth())
-	if config.AppDebug {
-		app.Use(logger.New())
-	}
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
-		AllowHeaders: "Origin, Content-Type, Accept",
+		AllowOrigins:  "*",
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization, " + middleware.APIKeyHeader + ", " + middleware.RequestIDHeader,
+		ExposeHeaders: middleware.RequestIDHeader,
 	}))
 
 	// Device manager - needed for chatwoot webhook
 	dm := whatsapp.GetDeviceManager()
 
-	// Chatwoot webhook - registered BEFORE basic auth middleware
-	// This allows Chatwoot to send webhooks without authentication
-	if config.ChatwootEnabled {
-		chatwootHandler := rest.NewChatwootHandler(appUsecase, sendUsecase, dm, chatStorageRepo)
-		webhookPath := "/chatwoot/webhook"
-		if config.AppBasePath != "" {
-			webhookPath = config.AppBasePath + webhookPath
-		}
-		app.Post(webhookPath, chatwootHandler.HandleWebhook)
-	}
+	// Registered BEFORE basic auth middleware
+	registerPublicRoutes(app, dm)
 
-	if len(config.AppBasicAuthCredential) > 0 {
-		account := make(map[string]string)
-		for _, basicAuth := range config.AppBasicAuthCredential {
-			ba := strings.Split(basicAuth, ":")
-			if len(ba) != 2 {
-				logrus.Fatalln("Basic auth is not valid, please this following format <user>:<secret>")
-			}
-			account[ba[0]] = ba[1]
+	account := make(map[string]string)
+	for _, basicAuth := range config.AppBasicAuthCredential {
+		ba := strings.Split(basicAuth, ":")
+		if len(ba) != 2 {
+			logrus.Fatalln("Basic auth is not valid, please this following format <user>:<secret>")
 		}
-
-		app.Use(basicauth.New(basicauth.Config{
-			Users: account,
-		}))
+		account[ba[0]] = ba[1]
 	}
+	// API keys are accepted alongside basic auth
+	app.Use(middleware.Auth(account, apiKeyUsecase))
+
+	rateLimiter := ratelimit.NewLimiter(ratelimit.Limits{
+		Send: ratelimit.Limit{PerMinute: config.AppRateLimitPerMinute, Burst: config.AppRateLimitBurst},
+		Bulk: ratelimit.Limit{PerMinute: config.AppRateLimitBulkPerMinute, Burst: config.AppRateLimitBulkBurst},
+	}, nil)
+	app.Use(middleware.RateLimit(rateLimiter))
 
 	// Create base path group or use app directly
 	var apiGroup fiber.Router = app
 	if config.AppBasePath != "" {
 		apiGroup = app.Group(config.AppBasePath)
 	}
+	registerRoutes(apiGroup, dm, rateLimiter)
 
+	apiGroup.Get("/", func(c *fiber.Ctx) error {
+		return c.Render("views/index", fiber.Map{
+			"AppHost":        fmt.Sprintf("%s://%s", c.Protocol(), c.Hostname()),
+			"AppVersion":     config.AppVersion,
+			"AppBasePath":    config.AppBasePath,
+			"BasicAuthToken": c.UserContext().Value(middleware.AuthorizationValue("BASIC_AUTH")),
+			"MaxFileSize":    humanize.Bytes(uint64(config.WhatsappSettingMaxFileSize)),
+			"MaxVideoSize":   humanize.Bytes(uint64(config.WhatsappSettingMaxVideoSize)),
+		})
+	})
+
+	go websocket.RunHub()
+
+	// Set auto reconnect to whatsapp server after booting
+	go helpers.SetAutoConnectAfterBooting(appUsecase)
+
+	// Set auto reconnect checking with a guaranteed client instance
+	startAutoReconnectCheckerIfClientAvailable()
+
+	go func() {
+		if err := app.Listen(config.AppHost + ":" + config.AppPort); err != nil {
+			logrus.Fatalln("Failed to start: ", err.Error())
+		}
+	}()
+
+	waitForShutdownSignal()
+	shutdown(requests.Wait, app.ShutdownWithContext)
+}
+
+// registerPublicRoutes registers the routes that don't need authentication
+func registerPublicRoutes(app *fiber.App, dm *whatsapp.DeviceManager) {
+	// Chatwoot webhook, so Chatwoot can send webhooks without authentication
+	if config.ChatwootEnabled {
+		chatwootHandler := rest.NewChatwootHandler(appUsecase, sendUsecase, dm, chatStorageRepo)
+		webhookPath := "/chatwoot/webhook"
+		if config.AppBasePath != "" {
+			webhookPath = config.AppBasePath + webhookPath
+		}
+		app.Post(webhookPath, chatwootHandler.HandleWebhook)
+	}
+
+	// Health check, so probes don't need credentials
+	rest.InitRestHealth(app.Group(config.AppBasePath), appUsecase)
+}
+
+// registerRoutes registers the API routes under apiGroup. Every route it
+// registers must be described in the OpenAPI spec.
+func registerRoutes(apiGroup fiber.Router, dm *whatsapp.DeviceManager, rateLimiter *ratelimit.Limiter) {
 	registerDeviceScopedRoutes := func(r fiber.Router) {
 		rest.InitRestApp(r, appUsecase)
 		rest.InitRestChat(r, chatUsecase)
+		rest.InitRestContact(r, contactUsecase)
 		rest.InitRestSend(r, sendUsecase)
 		rest.InitRestUser(r, userUsecase)
 		rest.InitRestMessage(r, messageUsecase)
 		rest.InitRestGroup(r, groupUsecase)
 		rest.InitRestNewsletter(r, newsletterUsecase)
+		rest.InitRestAutoReply(r, autoReplyUsecase)
+		rest.InitRestLabel(r, labelUsecase)
+		rest.InitRestTemplate(r, templateUsecase)
+		rest.InitRestJob(r, jobUsecase)
 		websocket.RegisterRoutes(r, appUsecase)
 	}
 
+	// API description and its Swagger UI
+	rest.InitRestOpenAPI(apiGroup)
+
 	// Device management routes (no device_id required)
 	rest.InitRestDevice(apiGroup, deviceUsecase)
+	rest.InitRestWebhook(apiGroup, appUsecase)
+	rest.InitRestAPIKey(apiGroup, apiKeyUsecase)
+	rest.InitRestRateLimit(apiGroup, rateLimiter)
+	websocket.RegisterEventRoutes(apiGroup, websocket.Events)
 
 	// Device-scoped operations (header-based)
 	headerDeviceGroup := apiGroup.Group("", middleware.DeviceMiddleware(dm))
 	registerDeviceScopedRoutes(headerDeviceGroup)
 
-	// Chatwoot sync routes - require authentication (webhook is registered earlier without auth)
+	// Chatwoot sync routes - require authentication (webhook is registered without auth)
 	if config.ChatwootEnabled {
 		chatwootHandler := rest.NewChatwootHandler(appUsecase, sendUsecase, dm, chatStorageRepo)
 		apiGroup.Post("/chatwoot/sync", chatwootHandler.SyncHistory)
 		apiGroup.Get("/chatwoot/sync/status", chatwootHandler.SyncStatus)
 	}
-
-	apiGroup.Get("/", func(c *fiber.Ctx) error {
-		return c.Render("views/index", fiber.Map{
-			"AppHost":        fmt.Sprintf("%s://%s", c.Protocol(), c.Hostname()),
-			"AppVersion":     config.AppVersion,
-			"AppBasePath":    config.AppBasePath,
-			"BasicAuthToken": c.UserContext().Value(middleware.AuthorizationValue("BASIC_AUTH")),
-			"MaxFileSize":    humanize.Bytes(uint64(config.WhatsappSettingMaxFileSize)),
-			"MaxVideoSize":   humanize.Bytes(uint64(config.WhatsappSettingMaxVideoSize)),
-		})
-	})
-
-	go websocket.RunHub()
-
-	// Set auto reconnect to whatsapp server after booting
-	go helpers.SetAutoConnectAfterBooting(appUsecase)
-
-	// Set auto reconnect checking with a guaranteed client instance
-	startAutoReconnectCheckerIfClientAvailable()
-
-	if err := app.Listen(config.AppHost + ":" + config.AppPort); err != nil {
-		logrus.Fatalln("Failed to start: ", err.Error())
-	}
 }
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/docs"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/ratelimit"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+var routeParamPattern = regexp.MustCompile(`:(\w+)\??`)
+
+// TestOpenAPICoversRoutes fails when a route is missing from docs/openapi.yaml.
+func TestOpenAPICoversRoutes(t *testing.T) {
+	chatwootEnabled := config.ChatwootEnabled
+	t.Cleanup(func() { config.ChatwootEnabled = chatwootEnabled })
+	config.ChatwootEnabled = true
+
+	app := fiber.New()
+	dm := whatsapp.NewDeviceManager(nil, nil, nil)
+	registerPublicRoutes(app, dm)
+	registerRoutes(app, dm, ratelimit.NewLimiter(ratelimit.Limits{}, nil))
+
+	var spec struct {
+		Paths map[string]map[string]any `yaml:"paths"`
+	}
+	require.NoError(t, yaml.Unmarshal(docs.OpenAPI, &spec))
+
+	for _, route := range app.GetRoutes(true) {
+		if route.Method == fiber.MethodHead || route.Path == "/docs" || route.Path == "/openapi.json" {
+			continue
+		}
+		path := routeParamPattern.ReplaceAllString(route.Path, "{$1}")
+		operations, ok := spec.Paths[path]
+		if !assert.True(t, ok, "%s %s is not in the OpenAPI spec", route.Method, path) {
+			continue
+		}
+		assert.Contains(t, operations, strings.ToLower(route.Method), "%s %s is not in the OpenAPI spec", route.Method, path)
+	}
+}
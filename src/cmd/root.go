@@ -12,14 +12,20 @@ import (
 	"go.mau.fi/whatsmeow/store/sqlstore"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainAPIKey "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/apikey"
 	domainApp "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/app"
+	domainAutoReply "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/autoreply"
 	domainChat "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chat"
 	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainContact "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/contact"
 	domainDevice "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/device"
 	domainGroup "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/group"
+	domainJob "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/job"
+	domainLabel "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/label"
 	domainMessage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/message"
 	domainNewsletter "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/newsletter"
 	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	domainTemplate "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/template"
 	domainUser "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/user"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
@@ -46,12 +52,18 @@ var (
 	// Usecase
 	appUsecase        domainApp.IAppUsecase
 	chatUsecase       domainChat.IChatUsecase
+	contactUsecase    domainContact.IContactUsecase
 	sendUsecase       domainSend.ISendUsecase
 	userUsecase       domainUser.IUserUsecase
 	messageUsecase    domainMessage.IMessageUsecase
 	groupUsecase      domainGroup.IGroupUsecase
 	newsletterUsecase domainNewsletter.INewsletterUsecase
 	deviceUsecase     domainDevice.IDeviceUsecase
+	apiKeyUsecase     domainAPIKey.IAPIKeyUsecase
+	autoReplyUsecase  domainAutoReply.IAutoReplyUsecase
+	labelUsecase      domainLabel.ILabelUsecase
+	templateUsecase   domainTemplate.ITemplateUsecase
+	jobUsecase        domainJob.IJobUsecase
 )
 
 var rootCmd = &cobra.Command{
@@ -87,6 +99,30 @@ func initEnvConfig() {
 	if v := viper.GetString("app_base_path"); v != "" {
 		config.AppBasePath = v
 	}
+	if v := viper.GetString("app_log_format"); v != "" {
+		config.AppLogFormat = v
+	}
+	if viper.IsSet("app_health_min_connected_devices") {
+		config.AppHealthMinConnectedDevices = viper.GetInt("app_health_min_connected_devices")
+	}
+	if viper.IsSet("app_shutdown_timeout") {
+		config.AppShutdownTimeout = viper.GetDuration("app_shutdown_timeout")
+	}
+	if viper.IsSet("app_job_workers") {
+		config.AppJobWorkers = viper.GetInt("app_job_workers")
+	}
+	if viper.IsSet("app_rate_limit_per_minute") {
+		config.AppRateLimitPerMinute = viper.GetInt("app_rate_limit_per_minute")
+	}
+	if viper.IsSet("app_rate_limit_burst") {
+		config.AppRateLimitBurst = viper.GetInt("app_rate_limit_burst")
+	}
+	if viper.IsSet("app_rate_limit_bulk_per_minute") {
+		config.AppRateLimitBulkPerMinute = viper.GetInt("app_rate_limit_bulk_per_minute")
+	}
+	if viper.IsSet("app_rate_limit_bulk_burst") {
+		config.AppRateLimitBulkBurst = viper.GetInt("app_rate_limit_bulk_burst")
+	}
 
 	// Database settings
 	if v := viper.GetString("db_uri"); v != "" {
@@ -95,6 +131,24 @@ func initEnvConfig() {
 	if v := viper.GetString("chat_storage_uri"); v != "" {
 		config.ChatStorageURI = v
 	}
+	if v := viper.GetInt("chat_storage_retention_days"); v > 0 {
+		config.ChatStorageRetentionDays = v
+	}
+	if viper.IsSet("chat_storage_keep_revoked") {
+		config.ChatStorageKeepRevoked = viper.GetBool("chat_storage_keep_revoked")
+	}
+	if viper.IsSet("chat_storage_auto_download_media") {
+		config.ChatStorageAutoDownloadMedia = viper.GetBool("chat_storage_auto_download_media")
+	}
+	if v := viper.GetString("chat_storage_auto_download_types"); v != "" {
+		config.ChatStorageAutoDownloadTypes = strings.Split(v, ",")
+	}
+	if v := viper.GetInt64("chat_storage_media_max_size"); v > 0 {
+		config.ChatStorageMediaMaxSize = v
+	}
+	if viper.IsSet("chat_storage_capture_view_once") {
+		config.ChatStorageCaptureViewOnce = viper.GetBool("chat_storage_capture_view_once")
+	}
 	if v := viper.GetString("db_keys_uri"); v != "" {
 		config.DBKeysURI = v
 	}
@@ -103,33 +157,148 @@ func initEnvConfig() {
 	if v := viper.GetString("whatsapp_auto_reply"); v != "" {
 		config.WhatsappAutoReplyMessage = v
 	}
+	if viper.IsSet("whatsapp_auto_reply_cooldown") {
+		config.WhatsappAutoReplyCooldown = viper.GetDuration("whatsapp_auto_reply_cooldown")
+	}
+	if viper.IsSet("whatsapp_send_workers") {
+		config.WhatsappSendWorkers = viper.GetInt("whatsapp_send_workers")
+	}
+	if viper.IsSet("whatsapp_send_rate") {
+		config.WhatsappSendRate = viper.GetFloat64("whatsapp_send_rate")
+	}
+	if viper.IsSet("whatsapp_send_recipient_gap") {
+		config.WhatsappSendRecipientGap = viper.GetDuration("whatsapp_send_recipient_gap")
+	}
+	if viper.IsSet("whatsapp_send_max_retries") {
+		config.WhatsappSendMaxRetries = viper.GetInt("whatsapp_send_max_retries")
+	}
+	if viper.IsSet("whatsapp_send_bulk_max_recipients") {
+		config.WhatsappSendBulkMaxRecipients = viper.GetInt("whatsapp_send_bulk_max_recipients")
+	}
+	if viper.IsSet("whatsapp_voice_note_max_duration") {
+		config.WhatsappVoiceNoteMaxDuration = viper.GetDuration("whatsapp_voice_note_max_duration")
+	}
+	if viper.IsSet("whatsapp_mention_all_max_participants") {
+		config.WhatsappMentionAllMaxParticipants = viper.GetInt("whatsapp_mention_all_max_participants")
+	}
+	if viper.IsSet("whatsapp_image_max_dimension") {
+		config.WhatsappImageMaxDimension = viper.GetInt("whatsapp_image_max_dimension")
+	}
+	if viper.IsSet("whatsapp_video_crf") {
+		config.WhatsappVideoCRF = viper.GetInt("whatsapp_video_crf")
+	}
+	if viper.IsSet("whatsapp_message_edit_window") {
+		config.WhatsappMessageEditWindow = viper.GetDuration("whatsapp_message_edit_window")
+	}
+	if viper.IsSet("whatsapp_typing_delay_per_char") {
+		config.WhatsappTypingDelayPerChar = viper.GetDuration("whatsapp_typing_delay_per_char")
+	}
+	if viper.IsSet("whatsapp_typing_delay_max") {
+		config.WhatsappTypingDelayMax = viper.GetDuration("whatsapp_typing_delay_max")
+	}
+	if viper.IsSet("whatsapp_location_thumbnail_url") {
+		config.WhatsappLocationThumbnailURL = viper.GetString("whatsapp_location_thumbnail_url")
+	}
+	if viper.IsSet("whatsapp_default_country_code") {
+		config.WhatsappDefaultCountryCode = viper.GetString("whatsapp_default_country_code")
+	}
+	if viper.IsSet("whatsapp_check_cache_ttl") {
+		config.WhatsappCheckCacheTTL = viper.GetDuration("whatsapp_check_cache_ttl")
+	}
 	if viper.IsSet("whatsapp_auto_mark_read") {
 		config.WhatsappAutoMarkRead = viper.GetBool("whatsapp_auto_mark_read")
 	}
+	if viper.IsSet("whatsapp_auto_view_status") {
+		config.WhatsappAutoViewStatus = viper.GetBool("whatsapp_auto_view_status")
+	}
+	if viper.IsSet("whatsapp_auto_reject_call") {
+		config.WhatsappAutoRejectCall = viper.GetBool("whatsapp_auto_reject_call")
+	}
+	if v := viper.GetString("whatsapp_auto_reject_call_message"); v != "" {
+		config.WhatsappAutoRejectCallMessage = v
+	}
 	if v := viper.GetString("whatsapp_webhook"); v != "" {
-		config.WhatsappWebhook = strings.Split(v, ",")
+		targets, err := whatsapp.ParseWebhookTargets(v)
+		if err != nil {
+			logrus.Fatalf("invalid webhook configuration: %v", err)
+		}
+		whatsapp.ConfigureWebhookTargets(targets)
 	}
 	if v := viper.GetString("whatsapp_webhook_secret"); v != "" {
 		config.WhatsappWebhookSecret = v
 	}
+	if viper.IsSet("whatsapp_webhook_enrich_payload") {
+		config.WhatsappWebhookEnrichPayload = viper.GetBool("whatsapp_webhook_enrich_payload")
+	}
+	if viper.IsSet("whatsapp_webhook_strict") {
+		config.WhatsappWebhookStrict = viper.GetBool("whatsapp_webhook_strict")
+	}
+	if err := whatsapp.CheckWebhookSecret(); err != nil {
+		logrus.Fatalf("invalid webhook configuration: %v", err)
+	}
 }
 
 func initFlags() {
 	rootCmd.PersistentFlags().StringVarP(&config.AppPort, "port", "p", config.AppPort, "port number")
 	rootCmd.PersistentFlags().StringVarP(&config.AppHost, "host", "H", config.AppHost, "host to bind")
 	rootCmd.PersistentFlags().BoolVarP(&config.AppDebug, "debug", "d", config.AppDebug, "debug mode")
+	rootCmd.PersistentFlags().StringVarP(&config.AppLogFormat, "log-format", "", config.AppLogFormat, "log line format, text or json for log aggregation")
+	rootCmd.PersistentFlags().IntVarP(&config.AppHealthMinConnectedDevices, "health-min-connected-devices", "", config.AppHealthMinConnectedDevices, "logged in devices GET /health requires before reporting healthy")
+	rootCmd.PersistentFlags().DurationVarP(&config.AppShutdownTimeout, "shutdown-timeout", "", config.AppShutdownTimeout, "how long shutdown waits for requests, queued sends and webhook deliveries in progress")
+	rootCmd.PersistentFlags().IntVarP(&config.AppJobWorkers, "job-workers", "", config.AppJobWorkers, "background jobs, such as chat exports and contact syncs, run at the same time")
+	rootCmd.PersistentFlags().IntVarP(&config.AppRateLimitPerMinute, "rate-limit-per-minute", "", config.AppRateLimitPerMinute, "sends each API client may make per minute (0 disables the limit)")
+	rootCmd.PersistentFlags().IntVarP(&config.AppRateLimitBurst, "rate-limit-burst", "", config.AppRateLimitBurst, "sends each API client may make at once")
+	rootCmd.PersistentFlags().IntVarP(&config.AppRateLimitBulkPerMinute, "rate-limit-bulk-per-minute", "", config.AppRateLimitBulkPerMinute, "POST /send/bulk requests each API client may make per minute (0 disables the limit)")
+	rootCmd.PersistentFlags().IntVarP(&config.AppRateLimitBulkBurst, "rate-limit-bulk-burst", "", config.AppRateLimitBulkBurst, "POST /send/bulk requests each API client may make at once")
 	rootCmd.PersistentFlags().StringVarP(&config.DBURI, "db-uri", "", config.DBURI, "database uri")
 	rootCmd.PersistentFlags().StringVarP(&config.ChatStorageURI, "chat-storage-uri", "", config.ChatStorageURI, "chat storage uri")
+	rootCmd.PersistentFlags().IntVarP(&config.ChatStorageRetentionDays, "chat-storage-retention-days", "", config.ChatStorageRetentionDays, "prune stored messages older than this many days daily (0 disables)")
+	rootCmd.PersistentFlags().BoolVarP(&config.ChatStorageKeepRevoked, "chat-storage-keep-revoked", "", config.ChatStorageKeepRevoked, "keep the content of messages deleted for everyone instead of blanking it")
+	rootCmd.PersistentFlags().BoolVarP(&config.ChatStorageAutoDownloadMedia, "chat-storage-auto-download-media", "", config.ChatStorageAutoDownloadMedia, "download incoming attachments to the media folder as they arrive")
+	rootCmd.PersistentFlags().StringSliceVarP(&config.ChatStorageAutoDownloadTypes, "chat-storage-auto-download-types", "", config.ChatStorageAutoDownloadTypes, "media types downloaded automatically (image,video,video_note,audio,document,sticker)")
+	rootCmd.PersistentFlags().Int64VarP(&config.ChatStorageMediaMaxSize, "chat-storage-media-max-size", "", config.ChatStorageMediaMaxSize, "largest attachment in bytes kept in the media folder")
+	rootCmd.PersistentFlags().BoolVarP(&config.ChatStorageCaptureViewOnce, "chat-storage-capture-view-once", "", config.ChatStorageCaptureViewOnce, "download view-once media to the media folder before it becomes unavailable")
+	rootCmd.PersistentFlags().DurationVarP(&config.WhatsappAutoReplyCooldown, "auto-reply-cooldown", "", config.WhatsappAutoReplyCooldown, "minimum time between auto-replies to the same chat (0 replies to every message)")
+	rootCmd.PersistentFlags().IntVarP(&config.WhatsappSendWorkers, "send-workers", "", config.WhatsappSendWorkers, "messages sent to WhatsApp at the same time")
+	rootCmd.PersistentFlags().Float64VarP(&config.WhatsappSendRate, "send-rate", "", config.WhatsappSendRate, "messages per second each device sends at most (0 disables the limit)")
+	rootCmd.PersistentFlags().DurationVarP(&config.WhatsappSendRecipientGap, "send-recipient-gap", "", config.WhatsappSendRecipientGap, "minimum time between two messages to the same chat")
+	rootCmd.PersistentFlags().IntVarP(&config.WhatsappSendMaxRetries, "send-max-retries", "", config.WhatsappSendMaxRetries, "retries of a send that failed because the device was offline or WhatsApp asked to slow down")
+	rootCmd.PersistentFlags().IntVarP(&config.WhatsappSendBulkMaxRecipients, "send-bulk-max-recipients", "", config.WhatsappSendBulkMaxRecipients, "most recipients a single POST /send/bulk may address")
+	rootCmd.PersistentFlags().DurationVarP(&config.WhatsappVoiceNoteMaxDuration, "voice-note-max-duration", "", config.WhatsappVoiceNoteMaxDuration, "longest audio accepted as a voice note with ptt=true (0 disables the limit)")
+	rootCmd.PersistentFlags().IntVarP(&config.WhatsappMentionAllMaxParticipants, "mention-all-max-participants", "", config.WhatsappMentionAllMaxParticipants, "refuse mention_all in groups with more participants than this")
+	rootCmd.PersistentFlags().IntVarP(&config.WhatsappImageMaxDimension, "image-max-dimension", "", config.WhatsappImageMaxDimension, "downscale sent images so their longest side fits, in pixels (0 keeps the original size)")
+	rootCmd.PersistentFlags().IntVarP(&config.WhatsappVideoCRF, "video-crf", "", config.WhatsappVideoCRF, "x264 CRF used when transcoding videos (0-51, lower is better quality and bigger files)")
+	rootCmd.PersistentFlags().DurationVarP(&config.WhatsappMessageEditWindow, "message-edit-window", "", config.WhatsappMessageEditWindow, "how long after sending a message it can still be edited (0 disables the check)")
+	rootCmd.PersistentFlags().DurationVarP(&config.WhatsappTypingDelayPerChar, "typing-delay-per-char", "", config.WhatsappTypingDelayPerChar, "typing indicator time per character of messages sent with simulate_typing")
+	rootCmd.PersistentFlags().DurationVarP(&config.WhatsappTypingDelayMax, "typing-delay-max", "", config.WhatsappTypingDelayMax, "longest typing indicator shown before messages sent with simulate_typing")
+	rootCmd.PersistentFlags().StringVarP(&config.WhatsappLocationThumbnailURL, "location-thumbnail-url", "", config.WhatsappLocationThumbnailURL, "static map image URL for location thumbnails, with {lat} and {lng} placeholders (empty disables thumbnails)")
+	rootCmd.PersistentFlags().StringVarP(&config.WhatsappDefaultCountryCode, "default-country-code", "", config.WhatsappDefaultCountryCode, "country code given to local numbers starting with 0 by POST /user/check, e.g. 62")
+	rootCmd.PersistentFlags().DurationVarP(&config.WhatsappCheckCacheTTL, "check-cache-ttl", "", config.WhatsappCheckCacheTTL, "how long answers of WhatsApp registration checks are reused (0 disables the cache)")
+	rootCmd.PersistentFlags().BoolVarP(&config.WhatsappAutoViewStatus, "auto-view-status", "", config.WhatsappAutoViewStatus, "mark the statuses contacts post as viewed as they arrive, which shows you among their viewers")
+	rootCmd.PersistentFlags().BoolVarP(&config.WhatsappAutoRejectCall, "auto-reject-call", "", config.WhatsappAutoRejectCall, "reject incoming calls automatically (devices can override this through their settings)")
+	rootCmd.PersistentFlags().StringVarP(&config.WhatsappAutoRejectCallMessage, "auto-reject-call-message", "", config.WhatsappAutoRejectCallMessage, "text sent to the caller after a call is auto-rejected")
+	rootCmd.PersistentFlags().BoolVarP(&config.WhatsappWebhookEnrichPayload, "webhook-enrich-payload", "", config.WhatsappWebhookEnrichPayload, "add the stored chat name, group flag, participant count and quoted message to message webhooks")
+	rootCmd.PersistentFlags().BoolVarP(&config.WhatsappWebhookStrict, "webhook-strict", "", config.WhatsappWebhookStrict, "refuse to start when webhooks are configured without a non-default secret")
+}
+
+func initChatStorage(ctx context.Context) (*sql.DB, error) {
+	return openChatStorage(ctx, config.ChatStorageURI)
 }
 
-func initChatStorage() (*sql.DB, error) {
+// openChatStorage opens the chat storage database at uri, picking the driver
+// from its scheme.
+func openChatStorage(ctx context.Context, uri string) (*sql.DB, error) {
 	var db *sql.DB
 	var err error
 
-	if strings.HasPrefix(config.ChatStorageURI, "postgres://") {
-		db, err = sql.Open("postgres", config.ChatStorageURI)
+	if strings.HasPrefix(uri, "postgres://") || strings.HasPrefix(uri, "postgresql://") {
+		db, err = sql.Open("postgres", uri)
+	} else if strings.HasPrefix(uri, "mysql://") {
+		db, err = chatstorage.OpenMySQL(ctx, uri)
 	} else {
-		return nil, fmt.Errorf("SQLite is disabled in this build. Please use a postgres:// URI")
+		// Anything else is a SQLite file: URI or plain filename, optionally
+		// prefixed with sqlite:
+		db, err = chatstorage.OpenSQLite(ctx, strings.TrimPrefix(uri, "sqlite:"))
 	}
 
 	if err != nil {
@@ -138,7 +307,7 @@ func initChatStorage() (*sql.DB, error) {
 	db.SetMaxOpenConns(25)
 	db.SetMaxIdleConns(5)
 
-	if err := db.Ping(); err != nil {
+	if err := db.PingContext(ctx); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
@@ -151,18 +320,30 @@ func initApp() {
 		config.WhatsappLogLevel = "DEBUG"
 		logrus.SetLevel(logrus.DebugLevel)
 	}
+	switch config.AppLogFormat {
+	case "json":
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+	case "text":
+	default:
+		logrus.Fatalf("invalid log format %q, use text or json", config.AppLogFormat)
+	}
+	// Lines logged with the context of an API request carry its ID
+	logrus.AddHook(utils.RequestIDHook{})
 
 	_ = utils.CreateFolder(config.PathQrCode, config.PathSendItems, config.PathStorages, config.PathMedia)
 
 	ctx := context.Background()
 	var err error
-	chatStorageDB, err = initChatStorage()
+	chatStorageDB, err = initChatStorage(ctx)
 	if err != nil {
 		logrus.Fatalf("failed to initialize chat storage: %v", err)
 	}
 
 	chatStorageRepo = chatstorage.NewStorageRepository(chatStorageDB)
-	_ = chatStorageRepo.InitializeSchema()
+	if err := chatStorageRepo.InitializeSchema(ctx); err != nil {
+		logrus.Fatalf("failed to migrate chat storage schema: %v", err)
+	}
+	chatstorage.StartRetentionJob(ctx, chatStorageRepo, config.ChatStorageRetentionDays)
 
 	whatsappDB := whatsapp.InitWaDB(ctx, config.DBURI)
 	var keysDB *sqlstore.Container
@@ -177,14 +358,20 @@ func initApp() {
 		_ = dm.LoadExistingDevices(ctx)
 	}
 
+	jobUsecase = usecase.NewJobService(chatStorageRepo, config.AppJobWorkers)
 	appUsecase = usecase.NewAppService(chatStorageRepo, dm)
-	chatUsecase = usecase.NewChatService(chatStorageRepo)
+	chatUsecase = usecase.NewChatService(chatStorageRepo, jobUsecase)
+	contactUsecase = usecase.NewContactService(chatStorageRepo, jobUsecase)
 	sendUsecase = usecase.NewSendService(appUsecase, chatStorageRepo)
-	userUsecase = usecase.NewUserService()
+	userUsecase = usecase.NewUserService(chatStorageRepo)
 	messageUsecase = usecase.NewMessageService(chatStorageRepo)
-	groupUsecase = usecase.NewGroupService()
-	newsletterUsecase = usecase.NewNewsletterService()
+	groupUsecase = usecase.NewGroupService(chatStorageRepo)
+	newsletterUsecase = usecase.NewNewsletterService(chatStorageRepo)
 	deviceUsecase = usecase.NewDeviceService(dm)
+	apiKeyUsecase = usecase.NewAPIKeyService(chatStorageRepo)
+	autoReplyUsecase = usecase.NewAutoReplyService(chatStorageRepo)
+	labelUsecase = usecase.NewLabelService(chatStorageRepo)
+	templateUsecase = usecase.NewTemplateService(chatStorageRepo)
 }
 
 func Execute(embedIndex embed.FS, embedViews embed.FS) {
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/sirupsen/logrus"
+)
+
+// waitForShutdownSignal blocks until the process gets SIGINT or SIGTERM. A
+// second signal kills the process without waiting for the shutdown.
+func waitForShutdownSignal() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	<-ctx.Done()
+	stop()
+}
+
+// shutdown drains the service before the process exits. drainRequests stops
+// or waits out the requests of the server; the queued sends, the background
+// jobs, which are canceled and end up interrupted, and the webhook deliveries
+// are then waited for, in all for at most AppShutdownTimeout, before
+// closeServer, if any, closes the listener. Clients are disconnected and the
+// databases closed last, so nothing in progress loses them.
+func shutdown(drainRequests, closeServer func(context.Context) error) {
+	logrus.Infof("Shutting down, waiting up to %s for requests, sends and webhooks in progress", config.AppShutdownTimeout)
+	whatsapp.BeginShutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.AppShutdownTimeout)
+	defer cancel()
+
+	if err := drainRequests(ctx); err != nil {
+		logrus.Warnf("Requests still in progress at shutdown: %v", err)
+	}
+	if sendUsecase != nil {
+		if err := sendUsecase.Drain(ctx); err != nil {
+			logrus.Warnf("Send queue not drained: %v", err)
+		}
+	}
+	if jobUsecase != nil {
+		if err := jobUsecase.Stop(ctx); err != nil {
+			logrus.Warnf("Background jobs not stopped: %v", err)
+		}
+	}
+	if err := whatsapp.WaitForWebhooks(ctx); err != nil {
+		logrus.Warnf("Webhook deliveries still in progress at shutdown: %v", err)
+	}
+	if closeServer != nil {
+		if err := closeServer(ctx); err != nil {
+			logrus.Warnf("Failed to close the server: %v", err)
+		}
+	}
+
+	whatsapp.DisconnectClients()
+	if chatStorageDB != nil {
+		if err := chatStorageDB.Close(); err != nil {
+			logrus.Errorf("Failed to close chat storage: %v", err)
+		}
+	}
+	if err := whatsapp.CloseStores(); err != nil {
+		logrus.Errorf("Failed to close the WhatsApp stores: %v", err)
+	}
+	logrus.Info("Shutdown complete")
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"go.mau.fi/whatsmeow/proto/waCompanionReg"
 )
 
@@ -14,6 +16,18 @@ var (
 	AppBasicAuthCredential []string
 	AppBasePath            = ""
 	AppTrustedProxies      []string // Trusted proxy IP ranges (e.g., "0.0.0.0/0" for all, or specific CIDRs)
+	AppLogFormat           = "text" // Log line format: text or json
+
+	AppHealthMinConnectedDevices = 1 // Logged in devices GET /health requires to report healthy
+
+	AppShutdownTimeout = 30 * time.Second // How long shutdown waits for requests, sends and webhooks in progress
+
+	AppJobWorkers = 2 // Background jobs, e.g. chat exports and contact syncs, run at the same time
+
+	AppRateLimitPerMinute     = 120 // Sends a client may make per minute (0 = unlimited)
+	AppRateLimitBurst         = 30  // Sends a client may make at once
+	AppRateLimitBulkPerMinute = 6   // POST /send/bulk requests a client may make per minute (0 = unlimited)
+	AppRateLimitBulkBurst     = 2   // POST /send/bulk requests a client may make at once
 
 	McpPort = "8080"
 	McpHost = "localhost"
@@ -22,18 +36,25 @@ var (
 	PathSendItems = "statics/senditems"
 	PathMedia     = "statics/media"
 	PathStorages  = "storages"
+	PathJobs      = "storages/jobs"
 
 	DBURI     = "file:storages/whatsapp.db?_foreign_keys=on"
 	DBKeysURI = ""
 
 	WhatsappAutoReplyMessage          string
-	WhatsappAutoMarkRead              = false // Auto-mark incoming messages as read
-	WhatsappAutoDownloadMedia         = true  // Auto-download media from incoming messages
+	WhatsappAutoReplyCooldown         = time.Hour // Minimum time between auto-replies to the same chat (0 = no limit)
+	WhatsappAutoMarkRead              = false     // Auto-mark incoming messages as read
+	WhatsappAutoViewStatus            = false     // Auto-view the statuses contacts post
+	WhatsappAutoDownloadMedia         = true      // Auto-download media from incoming messages
 	WhatsappWebhook                   []string
+	WhatsappWebhookTargetEvents       = map[string][]string{} // Per-URL event filters (missing = all events)
 	WhatsappWebhookSecret             = "secret"
-	WhatsappWebhookInsecureSkipVerify = false          // Skip TLS certificate verification for webhooks (insecure)
-	WhatsappWebhookEvents             []string         // Whitelist of events to forward to webhook (empty = all events)
-	WhatsappAutoRejectCall                     = false // Auto-reject incoming calls
+	WhatsappWebhookInsecureSkipVerify = false  // Skip TLS certificate verification for webhooks (insecure)
+	WhatsappWebhookStrict             = false  // Refuse to start webhooks without a non-default secret
+	WhatsappWebhookEvents             []string // Whitelist of events to forward to webhook (empty = all events)
+	WhatsappWebhookEnrichPayload      = true   // Add stored chat context to message webhook payloads
+	WhatsappAutoRejectCall            = false  // Auto-reject incoming calls
+	WhatsappAutoRejectCallMessage     string   // Text sent to callers after an auto-reject (empty = none)
 	WhatsappLogLevel                           = "ERROR"
 	WhatsappSettingMaxImageSize       int64    = 20000000  // 20MB
 	WhatsappSettingMaxFileSize        int64    = 50000000  // 50MB
@@ -43,11 +64,33 @@ var (
 	WhatsappTypeGroup                          = "@g.us"
 	WhatsappTypeLid                            = "@lid"
 	WhatsappAccountValidation                  = true
+	WhatsappDefaultCountryCode                 = ""            // Country code given to local numbers (leading 0) by POST /user/check
+	WhatsappCheckCacheTTL                      = time.Hour     // How long IsOnWhatsApp answers are reused (0 = no cache)
 	WhatsappPresenceOnConnect                  = "unavailable" // Presence to send on connect: "available", "unavailable", or "none"
 
-	ChatStorageURI               = "file:storages/chatstorage.db"
-	ChatStorageEnableForeignKeys = true
-	ChatStorageEnableWAL         = true
+	WhatsappSendWorkers                       = 4                // Messages sent at the same time
+	WhatsappSendRate                  float64 = 0                // Messages per second per device (0 = unlimited)
+	WhatsappSendRecipientGap                  = time.Duration(0) // Minimum time between messages to the same chat
+	WhatsappSendMaxRetries                    = 3                // Retries of sends that failed for a transient reason
+	WhatsappSendBulkMaxRecipients             = 200              // Most recipients of one bulk send
+	WhatsappVoiceNoteMaxDuration              = 30 * time.Minute // Longest audio accepted as a voice note (0 = unlimited)
+	WhatsappMentionAllMaxParticipants         = 1024             // Largest group mention_all may ping
+	WhatsappImageMaxDimension                 = 0                // Longest side of sent images in pixels (0 = keep the original size)
+	WhatsappVideoCRF                          = 28               // x264 quality of transcoded videos (0-51, lower = better and bigger)
+	WhatsappMessageEditWindow                 = 15 * time.Minute // How long after sending a message it can be edited (0 = no limit)
+	WhatsappTypingDelayPerChar                = time.Second / 20 // Typing time per character of simulate_typing sends
+	WhatsappTypingDelayMax                    = 5 * time.Second  // Longest typing time of simulate_typing sends
+	WhatsappLocationThumbnailURL              = ""               // Static map URL with {lat} and {lng} placeholders; empty sends locations without a thumbnail
+
+	ChatStorageURI                     = "file:storages/chatstorage.db"
+	ChatStorageEnableForeignKeys       = true
+	ChatStorageEnableWAL               = true
+	ChatStorageRetentionDays           = 0     // Prune messages older than this many days; 0 keeps everything
+	ChatStorageKeepRevoked             = false // Keep the content of messages deleted for everyone instead of blanking it
+	ChatStorageAutoDownloadMedia       = false // Keep a local copy of incoming attachments under PathMedia
+	ChatStorageAutoDownloadTypes       = []string{"image", "video", "video_note", "audio", "document", "sticker"}
+	ChatStorageMediaMaxSize      int64 = 100000000 // 100MB, larger attachments are not stored locally
+	ChatStorageCaptureViewOnce         = false     // Download view-once media before it becomes unavailable; off for privacy
 
 	ChatwootEnabled   = false
 	ChatwootURL       = ""
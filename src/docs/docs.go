@@ -0,0 +1,10 @@
+// Package docs holds the OpenAPI description of the REST API.
+package docs
+
+import _ "embed"
+
+// OpenAPI is the OpenAPI 3 spec of the REST API. Every route must be described
+// in it; the cmd tests check that none is missing.
+//
+//go:embed openapi.yaml
+var OpenAPI []byte
@@ -0,0 +1,59 @@
+package apikey
+
+import (
+	"slices"
+	"time"
+)
+
+// Scopes an API key can be granted. Each scope only covers its own routes, so
+// a key that reads and sends needs both.
+const (
+	// ScopeRead allows reading chats, messages, contacts, groups and events
+	ScopeRead = "read"
+	// ScopeSend allows sending messages and every other change made through a
+	// device, such as managing groups or chats
+	ScopeSend = "send"
+	// ScopeAdmin allows managing devices: adding, removing, logging in and
+	// out, their settings and webhooks
+	ScopeAdmin = "admin"
+)
+
+// Scopes are the scopes a key can be granted
+var Scopes = []string{ScopeRead, ScopeSend, ScopeAdmin}
+
+// APIKey describes an API key. The key itself is only returned by
+// CreateAPIKey, only its hash is stored.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Label      string     `json:"label"`
+	Scopes     []string   `json:"scopes"`
+	DeviceIDs  []string   `json:"device_ids"` // Empty allows every device
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	Revoked    bool       `json:"revoked"`
+}
+
+// HasScope reports whether the key was granted scope.
+func (key APIKey) HasScope(scope string) bool {
+	return slices.Contains(key.Scopes, scope)
+}
+
+// AllowsDevice reports whether the key may act on deviceID.
+func (key APIKey) AllowsDevice(deviceID string) bool {
+	return len(key.DeviceIDs) == 0 || slices.Contains(key.DeviceIDs, deviceID)
+}
+
+// CreateAPIKeyRequest creates a key for an integration. Empty DeviceIDs let
+// the key act on every device.
+type CreateAPIKeyRequest struct {
+	Label     string   `json:"label"`
+	Scopes    []string `json:"scopes"`
+	DeviceIDs []string `json:"device_ids"`
+}
+
+// CreateAPIKeyResponse is a new key along with the key itself, which can't be
+// retrieved again.
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,13 @@
+package apikey
+
+import "context"
+
+// IAPIKeyUsecase manages the API keys integrations authenticate with.
+type IAPIKeyUsecase interface {
+	CreateAPIKey(ctx context.Context, request CreateAPIKeyRequest) (CreateAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context) ([]APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+	// Authenticate returns the key matching key, or an AuthError when it is
+	// unknown or revoked.
+	Authenticate(ctx context.Context, key string) (*APIKey, error)
+}
@@ -13,6 +13,8 @@ type IAppUsecase interface {
 	Status(ctx context.Context, deviceID string) (isConnected bool, isLoggedIn bool, err error)
 	FirstDevice(ctx context.Context) (response DevicesResponse, err error)
 	FetchDevices(ctx context.Context) (response []DevicesResponse, err error)
+	ListWebhooks(ctx context.Context) (response []WebhookTarget, err error)
+	Health(ctx context.Context) (response HealthResponse)
 }
 
 type DevicesResponse struct {
@@ -20,8 +22,34 @@ type DevicesResponse struct {
 	Device string `json:"device"`
 }
 
+// LoginWithCodeRequest is the body of POST /app/login-with-code. DeviceID is
+// read by the device middleware when no X-Device-Id header is sent.
+type LoginWithCodeRequest struct {
+	Phone    string `json:"phone"`
+	DeviceID string `json:"device_id"`
+}
+
 type LoginResponse struct {
 	ImagePath string        `json:"image_path"`
 	Duration  time.Duration `json:"duration"`
 	Code      string        `json:"code"`
 }
+
+// HealthResponse is the aggregate state reported by GET /health. Healthy
+// requires a reachable database and at least MinConnectedDevices logged in
+// devices, and is false while shutting down.
+type HealthResponse struct {
+	Healthy             bool   `json:"healthy"`
+	ShuttingDown        bool   `json:"shutting_down"`
+	Database            string `json:"database"`
+	Devices             int    `json:"devices"`
+	ConnectedDevices    int    `json:"connected_devices"`
+	MinConnectedDevices int    `json:"min_connected_devices"`
+}
+
+// WebhookTarget is a configured webhook URL; an empty Events list receives
+// every event.
+type WebhookTarget struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
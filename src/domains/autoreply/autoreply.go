@@ -0,0 +1,59 @@
+package autoreply
+
+import "time"
+
+// Ways a rule's pattern is matched against the text of incoming messages
+const (
+	// MatchExact matches messages equal to the pattern, ignoring case and
+	// surrounding spaces
+	MatchExact = "exact"
+	// MatchContains matches messages containing the pattern, ignoring case
+	MatchContains = "contains"
+	// MatchRegex matches messages the pattern, a Go regular expression,
+	// matches anywhere in. Prefix it with (?i) to ignore case.
+	MatchRegex = "regex"
+)
+
+// MatchTypes are the match types a rule can use
+var MatchTypes = []string{MatchExact, MatchContains, MatchRegex}
+
+// Rule replies ReplyText to incoming messages its pattern matches. A device's
+// enabled rules are tried in Position order and only the first match replies;
+// when none matches, the device's auto-reply message is sent to direct chats.
+type Rule struct {
+	ID         string    `json:"id"`
+	DeviceID   string    `json:"device_id"`
+	Position   int       `json:"position"`
+	Pattern    string    `json:"pattern"`
+	MatchType  string    `json:"match_type"`
+	ReplyText  string    `json:"reply_text"`
+	OnlyGroups bool      `json:"only_groups"`
+	OnlyDM     bool      `json:"only_dm"`
+	Enabled    bool      `json:"enabled"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// CreateRuleRequest adds a rule to the device of the request. A rule limited
+// to neither groups nor direct chats replies in both.
+type CreateRuleRequest struct {
+	Pattern    string `json:"pattern"`
+	MatchType  string `json:"match_type"` // MatchContains when empty
+	ReplyText  string `json:"reply_text"`
+	OnlyGroups bool   `json:"only_groups"`
+	OnlyDM     bool   `json:"only_dm"`
+	Enabled    *bool  `json:"enabled"`  // true when omitted
+	Position   *int   `json:"position"` // after the last rule when omitted
+}
+
+// UpdateRuleRequest changes the fields of a rule that are set.
+type UpdateRuleRequest struct {
+	ID         string  `json:"-"`
+	Pattern    *string `json:"pattern"`
+	MatchType  *string `json:"match_type"`
+	ReplyText  *string `json:"reply_text"`
+	OnlyGroups *bool   `json:"only_groups"`
+	OnlyDM     *bool   `json:"only_dm"`
+	Enabled    *bool   `json:"enabled"`
+	Position   *int    `json:"position"`
+}
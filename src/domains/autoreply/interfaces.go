@@ -0,0 +1,12 @@
+package autoreply
+
+import "context"
+
+// IAutoReplyUsecase manages the auto-reply rules of the device in the context.
+type IAutoReplyUsecase interface {
+	CreateRule(ctx context.Context, request CreateRuleRequest) (Rule, error)
+	ListRules(ctx context.Context) ([]Rule, error)
+	GetRule(ctx context.Context, id string) (Rule, error)
+	UpdateRule(ctx context.Context, request UpdateRuleRequest) (Rule, error)
+	DeleteRule(ctx context.Context, id string) error
+}
@@ -1,5 +1,7 @@
 package chat
 
+import "mime/multipart"
+
 // Request and Response structures for chat operations
 
 type ListChatsRequest struct {
@@ -7,6 +9,13 @@ type ListChatsRequest struct {
 	Offset   int    `json:"offset" query:"offset"`
 	Search   string `json:"search" query:"search"`
 	HasMedia bool   `json:"has_media" query:"has_media"`
+	Archived *bool  `json:"archived" query:"archived"`
+	Pinned   *bool  `json:"pinned" query:"pinned"`
+	Blocked  *bool  `json:"blocked" query:"blocked"`
+	// ChatType is one of "user", "group" or "newsletter"; empty lists every chat
+	ChatType string `json:"chat_type" query:"chat_type"`
+	// Label is the ID or name of a label; only chats tagged with it are listed
+	Label string `json:"label" query:"label"`
 }
 
 type ListChatsResponse struct {
@@ -14,15 +23,29 @@ type ListChatsResponse struct {
 	Pagination PaginationResponse `json:"pagination"`
 }
 
+// ListUnreadChatsResponse lists every chat with unread messages. Total is the
+// sum of their unread counts.
+type ListUnreadChatsResponse struct {
+	Data  []ChatInfo `json:"data"`
+	Total int        `json:"total"`
+}
+
 type GetChatMessagesRequest struct {
-	ChatJID   string  `json:"chat_jid" uri:"chat_jid"`
-	Limit     int     `json:"limit" query:"limit"`
-	Offset    int     `json:"offset" query:"offset"`
-	StartTime *string `json:"start_time" query:"start_time"`
-	EndTime   *string `json:"end_time" query:"end_time"`
-	MediaOnly bool    `json:"media_only" query:"media_only"`
-	IsFromMe  *bool   `json:"is_from_me" query:"is_from_me"`
-	Search    string  `json:"search" query:"search"`
+	ChatJID    string   `json:"chat_jid" uri:"chat_jid"`
+	Limit      int      `json:"limit" query:"limit"`
+	Offset     int      `json:"offset" query:"offset"`
+	StartTime  *string  `json:"start_time" query:"start_time"`
+	EndTime    *string  `json:"end_time" query:"end_time"`
+	MediaOnly  bool     `json:"media_only" query:"media_only"`
+	MediaTypes []string `json:"media_type" query:"media_type"`
+	Sender     string   `json:"sender" query:"sender"`
+	IsFromMe   *bool    `json:"is_from_me" query:"is_from_me"`
+	Search     string   `json:"search" query:"search"`
+	Cursor     string   `json:"cursor" query:"cursor"`
+	// IncludeReactions adds the reactions of each message to the response
+	IncludeReactions bool `json:"include_reactions" query:"include_reactions"`
+	// IncludeQuoted adds the quoted message of each reply to the response
+	IncludeQuoted bool `json:"include_quoted" query:"include_quoted"`
 }
 
 type GetChatMessagesResponse struct {
@@ -31,6 +54,65 @@ type GetChatMessagesResponse struct {
 	ChatInfo   ChatInfo           `json:"chat_info"`
 }
 
+// Export operations
+const (
+	ExportFormatJSON = "json" // Newline-delimited JSON, one message per line
+	ExportFormatCSV  = "csv"
+)
+
+type ExportChatMessagesRequest struct {
+	ChatJID string `json:"chat_jid" uri:"chat_jid"`
+	Format  string `json:"format" query:"format"`
+}
+
+// ExportChatMessagesResponse names the chat_export job ExportChatMessages
+// started.
+type ExportChatMessagesResponse struct {
+	JobID  string `json:"job_id"`
+	Status string `json:"status"`
+}
+
+// ExportChatMessagesResult is the result of a chat_export job; the export
+// itself is the job's output.
+type ExportChatMessagesResult struct {
+	ChatJID  string `json:"chat_jid"`
+	Format   string `json:"format"`
+	FileName string `json:"file_name"`
+	Messages int    `json:"messages"`
+}
+
+// DefaultHistorySyncCount is the number of older messages SyncChatHistory
+// asks for by default.
+const DefaultHistorySyncCount = 50
+
+type SyncChatHistoryRequest struct {
+	ChatJID string `json:"chat_jid" uri:"chat_jid"`
+	Count   int    `json:"count"`
+}
+
+type SyncChatHistoryResponse struct {
+	ChatJID string `json:"chat_jid"`
+	// Synced is the number of messages stored that weren't stored before
+	Synced int `json:"synced"`
+}
+
+type ImportChatMessagesRequest struct {
+	ChatJID string                `json:"chat_jid" uri:"chat_jid"`
+	Format  string                `json:"format" form:"format"`
+	File    *multipart.FileHeader `json:"file" form:"file"`
+}
+
+type ImportChatMessagesError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type ImportChatMessagesResponse struct {
+	Imported int                       `json:"imported"`
+	Skipped  int                       `json:"skipped"`
+	Errors   []ImportChatMessagesError `json:"errors"`
+}
+
 // Pin Chat operations
 type PinChatRequest struct {
 	ChatJID string `json:"chat_jid" uri:"chat_jid"`
@@ -47,10 +129,27 @@ type PinChatResponse struct {
 type ChatInfo struct {
 	JID                 string `json:"jid"`
 	Name                string `json:"name"`
+	ChatType            string `json:"chat_type"` // user, group, newsletter, community or community_announce
 	LastMessageTime     string `json:"last_message_time"`
 	EphemeralExpiration uint32 `json:"ephemeral_expiration"`
 	CreatedAt           string `json:"created_at"`
 	UpdatedAt           string `json:"updated_at"`
+	Archived            bool   `json:"archived"`
+	Pinned              bool   `json:"pinned"`
+	MutedUntil          string `json:"muted_until,omitempty"`
+	UnreadCount         int    `json:"unread_count"`
+	LeftAt              string `json:"left_at,omitempty"` // Only set for groups the device left
+	Blocked             bool   `json:"blocked"`
+	// ParticipantCount is only set for groups
+	ParticipantCount *int `json:"participant_count,omitempty"`
+	// Labels are the local labels tagging the chat, by name
+	Labels []ChatLabel `json:"labels"`
+}
+
+type ChatLabel struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
 }
 
 type MessageInfo struct {
@@ -66,25 +165,173 @@ type MessageInfo struct {
 	FileLength uint64 `json:"file_length"`
 	CreatedAt  string `json:"created_at"`
 	UpdatedAt  string `json:"updated_at"`
+	// EditedAt is set once the sender has edited the message
+	EditedAt string `json:"edited_at,omitempty"`
+	// IsDeleted marks messages the sender deleted for everyone
+	IsDeleted bool `json:"is_deleted"`
+	// Status is sent, delivered or read for messages sent by us
+	Status string `json:"status,omitempty"`
+	// ReplyToID is the ID of the message this one replies to
+	ReplyToID string `json:"reply_to_id,omitempty"`
+	// QuotedMessage is only set when requested with include_quoted
+	QuotedMessage *QuotedMessageInfo `json:"quoted_message,omitempty"`
+	// Reactions is only set when requested with include_reactions
+	Reactions []ReactionInfo `json:"reactions,omitempty"`
+	// Location is set for location and live location messages
+	Location *LocationInfo `json:"location,omitempty"`
+	// Contacts is set for contact messages, one entry per shared contact
+	Contacts []ContactCardInfo `json:"contacts,omitempty"`
+	// IsAnimated is set for animated stickers
+	IsAnimated bool `json:"is_animated,omitempty"`
+	// IsViewOnce is set for view-once media
+	IsViewOnce bool `json:"is_view_once,omitempty"`
+	// ServerID is set for newsletter messages
+	ServerID int64 `json:"server_id,omitempty"`
+	// PinnedUntil is set while the message is pinned in its chat
+	PinnedUntil string `json:"pinned_until,omitempty"`
+	// RequestID is the X-Request-ID of the API call that sent the message
+	RequestID string `json:"request_id,omitempty"`
+	// TemplateName is the template the message was rendered from
+	TemplateName string `json:"template_name,omitempty"`
+}
+
+// ContactCardInfo is a contact shared in a message with its parsed vCard fields
+type ContactCardInfo struct {
+	DisplayName  string   `json:"display_name"`
+	PhoneNumbers []string `json:"phone_numbers,omitempty"`
+	VCard        string   `json:"vcard"`
+}
+
+// LocationInfo holds the coordinates of a location message; for live locations
+// they are the latest position received.
+type LocationInfo struct {
+	Latitude       float64 `json:"latitude"`
+	Longitude      float64 `json:"longitude"`
+	Accuracy       uint32  `json:"accuracy,omitempty"`
+	Name           string  `json:"name,omitempty"`
+	SequenceNumber int64   `json:"sequence_number,omitempty"`
+}
+
+type QuotedMessageInfo struct {
+	ID      string `json:"id"`
+	Sender  string `json:"sender"`
+	Content string `json:"content"`
+}
+
+type ReactionInfo struct {
+	Sender    string `json:"sender"`
+	Emoji     string `json:"emoji"`
+	Timestamp string `json:"timestamp"`
+}
+
+type GetMessageEditHistoryRequest struct {
+	ChatJID   string `json:"chat_jid" uri:"chat_jid"`
+	MessageID string `json:"message_id" uri:"message_id"`
+}
+
+type GetMessageEditHistoryResponse struct {
+	MessageID string            `json:"message_id"`
+	ChatJID   string            `json:"chat_jid"`
+	Edits     []MessageEditInfo `json:"edits"`
+}
+
+type GetPinnedMessagesRequest struct {
+	ChatJID string `json:"chat_jid" uri:"chat_jid"`
+}
+
+type GetPinnedMessagesResponse struct {
+	ChatJID string        `json:"chat_jid"`
+	Data    []MessageInfo `json:"data"`
+}
+
+// MessageEditInfo is a previous version of a message, replaced at EditedAt
+type MessageEditInfo struct {
+	Content  string `json:"content"`
+	EditedAt string `json:"edited_at"`
+}
+
+type SearchMessagesRequest struct {
+	Query  string `json:"q" query:"q"`
+	Limit  int    `json:"limit" query:"limit"`
+	Offset int    `json:"offset" query:"offset"`
+}
+
+type SearchMessageInfo struct {
+	MessageInfo
+	ChatName string `json:"chat_name"`
+}
+
+type SearchMessagesResponse struct {
+	Data       []SearchMessageInfo      `json:"data"`
+	Pagination SearchPaginationResponse `json:"pagination"`
+}
+
+// SearchPaginationResponse reports whether another page exists instead of a
+// total, which would cost a second full search.
+type SearchPaginationResponse struct {
+	Limit   int  `json:"limit"`
+	Offset  int  `json:"offset"`
+	HasMore bool `json:"has_more"`
 }
 
 type PaginationResponse struct {
-	Limit  int `json:"limit"`
-	Offset int `json:"offset"`
-	Total  int `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	Total      int    `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Call log operations
+type ListCallsRequest struct {
+	FromJID   string  `json:"from_jid" query:"from_jid"`
+	StartTime *string `json:"start_time" query:"start_time"`
+	EndTime   *string `json:"end_time" query:"end_time"`
+	Limit     int     `json:"limit" query:"limit"`
+	Offset    int     `json:"offset" query:"offset"`
+}
+
+// CallInfo is an incoming call. Outcome is "ringing", "missed", "rejected" or
+// "accepted"; Duration is in seconds and only set for accepted calls.
+type CallInfo struct {
+	CallID    string `json:"call_id"`
+	From      string `json:"from"`
+	Timestamp string `json:"timestamp"`
+	IsVideo   bool   `json:"is_video"`
+	Outcome   string `json:"outcome"`
+	Duration  int    `json:"duration"`
+}
+
+type ListCallsResponse struct {
+	Data []CallInfo `json:"data"`
 }
 
 // Disappearing Messages operations
 type SetDisappearingTimerRequest struct {
-	ChatJID      string `json:"chat_jid" uri:"chat_jid"`
+	ChatJID string `json:"chat_jid" uri:"chat_jid"`
+	// Duration is one of the keys of DisappearingDurations; when set it
+	// replaces TimerSeconds
+	Duration     string `json:"duration"`
 	TimerSeconds uint32 `json:"timer_seconds"`
 }
 
+// DisappearingDurations are the disappearing message timers WhatsApp offers,
+// in seconds.
+var DisappearingDurations = map[string]uint32{
+	"off": 0,
+	"24h": 86400,
+	"7d":  604800,
+	"90d": 7776000,
+}
+
 type SetDisappearingTimerResponse struct {
 	Status       string `json:"status"`
 	Message      string `json:"message"`
 	ChatJID      string `json:"chat_jid"`
 	TimerSeconds uint32 `json:"timer_seconds"`
+	Duration     string `json:"duration"`
+	// PreviousTimerSeconds is the timer stored for the chat before the change
+	PreviousTimerSeconds uint32 `json:"previous_timer_seconds"`
+	PreviousDuration     string `json:"previous_duration"`
 }
 
 // Archive Chat operations
@@ -99,3 +346,72 @@ type ArchiveChatResponse struct {
 	ChatJID  string `json:"chat_jid"`
 	Archived bool   `json:"archived"`
 }
+
+// MarkChatReadRequest marks messages of a chat read: the listed MessageIDs,
+// or every unread message sent at or before AllBefore (RFC3339).
+type MarkChatReadRequest struct {
+	ChatJID    string   `json:"chat_jid" uri:"chat_jid"`
+	MessageIDs []string `json:"message_ids"`
+	AllBefore  string   `json:"all_before"`
+}
+
+// MarkChatReadResponse reports how many messages got a read receipt. IDs that
+// were not marked, e.g. because they belong to another chat, are listed in
+// Errors.
+type MarkChatReadResponse struct {
+	ChatJID      string          `json:"chat_jid"`
+	ReceiptsSent int             `json:"receipts_sent"`
+	Errors       []MarkReadError `json:"errors,omitempty"`
+}
+
+type MarkReadError struct {
+	MessageID string `json:"message_id"`
+	Error     string `json:"error"`
+}
+
+// SetChatPresenceRequest shows or clears a typing or recording indicator in
+// a chat. State is typing, recording or paused.
+type SetChatPresenceRequest struct {
+	ChatJID string `json:"chat_jid" uri:"chat_jid"`
+	State   string `json:"state"`
+}
+
+type SetChatPresenceResponse struct {
+	ChatJID string `json:"chat_jid"`
+	State   string `json:"state"`
+}
+
+type PruneMessagesRequest struct {
+	Days     int    `json:"days"`
+	DeviceID string `json:"device_id"`
+}
+
+type PruneMessagesResponse struct {
+	DeviceID        string `json:"device_id"`
+	Cutoff          string `json:"cutoff"`
+	DeletedMessages int64  `json:"deleted_messages"`
+}
+
+// MergeChatsRequest moves the stored messages of FromJID, typically an @lid
+// chat, into ToJID, typically the same contact's phone number chat.
+type MergeChatsRequest struct {
+	FromJID string `json:"from_jid"`
+	ToJID   string `json:"to_jid"`
+}
+
+type MergeChatsResponse struct {
+	FromJID       string `json:"from_jid"`
+	ToJID         string `json:"to_jid"`
+	MovedMessages int64  `json:"moved_messages"`
+}
+
+// Storage statistics operations
+type StorageStatisticsResponse struct {
+	DeviceID        string `json:"device_id"`
+	ChatCount       int64  `json:"chat_count"`
+	MessageCount    int64  `json:"message_count"`
+	MediaBytes      int64  `json:"media_bytes"`
+	DistinctSenders int64  `json:"distinct_senders"`
+	OldestMessage   string `json:"oldest_message,omitempty"`
+	NewestMessage   string `json:"newest_message,omitempty"`
+}
@@ -1,14 +1,31 @@
 package chat
 
-import (
-	"context"
-)
+import "context"
 
 // IChatUsecase defines the interface for chat-related operations
 type IChatUsecase interface {
 	ListChats(ctx context.Context, request ListChatsRequest) (response ListChatsResponse, err error)
+	ListUnreadChats(ctx context.Context) (response ListUnreadChatsResponse, err error)
 	GetChatMessages(ctx context.Context, request GetChatMessagesRequest) (response GetChatMessagesResponse, err error)
+	SearchMessages(ctx context.Context, request SearchMessagesRequest) (response SearchMessagesResponse, err error)
+	GetMessageEditHistory(ctx context.Context, request GetMessageEditHistoryRequest) (response GetMessageEditHistoryResponse, err error)
+	GetPinnedMessages(ctx context.Context, request GetPinnedMessagesRequest) (response GetPinnedMessagesResponse, err error)
+	// ExportChatMessages writes the chat's messages to a file in a background
+	// job.
+	ExportChatMessages(ctx context.Context, request ExportChatMessagesRequest) (response ExportChatMessagesResponse, err error)
+	ImportChatMessages(ctx context.Context, request ImportChatMessagesRequest) (response ImportChatMessagesResponse, err error)
+	// SyncChatHistory asks the phone for messages older than the oldest one
+	// stored and waits until they are stored.
+	SyncChatHistory(ctx context.Context, request SyncChatHistoryRequest) (response SyncChatHistoryResponse, err error)
 	PinChat(ctx context.Context, request PinChatRequest) (response PinChatResponse, err error)
 	SetDisappearingTimer(ctx context.Context, request SetDisappearingTimerRequest) (response SetDisappearingTimerResponse, err error)
 	ArchiveChat(ctx context.Context, request ArchiveChatRequest) (response ArchiveChatResponse, err error)
+	MarkChatRead(ctx context.Context, request MarkChatReadRequest) (response MarkChatReadResponse, err error)
+	SetChatPresence(ctx context.Context, request SetChatPresenceRequest) (response SetChatPresenceResponse, err error)
+	GetStorageStatistics(ctx context.Context) (response StorageStatisticsResponse, err error)
+	PruneMessages(ctx context.Context, request PruneMessagesRequest) (response PruneMessagesResponse, err error)
+	MergeChats(ctx context.Context, request MergeChatsRequest) (response MergeChatsResponse, err error)
+	DownloadMessageMedia(ctx context.Context, deviceID, chatJID, messageID string) (data []byte, mimeType string, err error)
+	RequestMediaRetry(ctx context.Context, deviceID, chatJID, messageID string) (err error)
+	ListCalls(ctx context.Context, request ListCallsRequest) (response ListCallsResponse, err error)
 }
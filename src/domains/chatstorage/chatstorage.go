@@ -11,6 +11,36 @@ type Chat struct {
 	EphemeralExpiration uint32    `db:"ephemeral_expiration"`
 	CreatedAt           time.Time `db:"created_at"`
 	UpdatedAt           time.Time `db:"updated_at"`
+	Archived            bool      `db:"archived"`
+	Pinned              bool      `db:"pinned"`
+	// MutedUntil is nil for chats that are not muted
+	MutedUntil *time.Time `db:"muted_until"`
+	// UnreadCount counts incoming messages since the chat was last marked read
+	UnreadCount int `db:"unread_count"`
+	// LeftAt is set for groups the device left; their history is kept
+	LeftAt *time.Time `db:"left_at"`
+	// ChatType is only stored for community groups, see ChatTypeCommunity;
+	// the type of other chats follows from their JID
+	ChatType string `db:"chat_type"`
+	// Blocked mirrors the account's blocklist for user chats
+	Blocked bool `db:"blocked"`
+	// ParticipantCount is the number of cached participants of a group; only set by GetChats and GetChatByDevice
+	ParticipantCount int `db:"-"`
+}
+
+// MutedForever is stored as MutedUntil for chats muted without an end time.
+var MutedForever = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// ChatFlags changes the archived, pinned, muted, left and blocked state and the
+// stored type of a chat. Nil fields are left unchanged; a zero MutedUntil unmutes the
+// chat and a zero LeftAt marks a group as joined again.
+type ChatFlags struct {
+	Archived   *bool
+	Pinned     *bool
+	MutedUntil *time.Time
+	LeftAt     *time.Time
+	ChatType   *string
+	Blocked    *bool
 }
 
 // Message represents a WhatsApp message
@@ -31,6 +61,207 @@ type Message struct {
 	FileLength    uint64    `db:"file_length"`
 	CreatedAt     time.Time `db:"created_at"`
 	UpdatedAt     time.Time `db:"updated_at"`
+	// EditedAt is set once the sender has edited the message
+	EditedAt *time.Time `db:"edited_at"`
+	// IsDeleted is set once the sender has deleted the message for everyone
+	IsDeleted bool `db:"is_deleted"`
+	// ReplyToID and ReplyToSender identify the message this one quotes
+	ReplyToID     string `db:"reply_to_id"`
+	ReplyToSender string `db:"reply_to_sender"`
+	// Location is set for location and live location messages
+	Location *Location `db:"location"`
+	// Contacts holds the cards shared by a contact message. A contacts array
+	// message is stored as a single message with one card per contact.
+	Contacts []ContactCard `db:"contacts"`
+	// MediaPath is the local copy of the attachment, set once it was downloaded
+	MediaPath    string     `db:"media_path"`
+	DownloadedAt *time.Time `db:"downloaded_at"`
+	// IsAnimated is set for animated stickers
+	IsAnimated bool `db:"is_animated"`
+	// IsViewOnce is set for view-once images, videos and voice notes
+	IsViewOnce bool `db:"is_view_once"`
+	// ServerID is the channel-wide ID of newsletter messages, used for reactions and views
+	ServerID int64 `db:"server_id"`
+	// PinnedUntil is when the pin of a message pinned in its chat expires
+	PinnedUntil *time.Time `db:"pinned_until"`
+	// IsUnread is set for incoming messages counted in the unread count of
+	// their chat, until they are marked read
+	IsUnread bool `db:"is_unread"`
+	// RequestID is the X-Request-ID of the API call that sent the message
+	RequestID string `db:"request_id"`
+	// TemplateName is the template the message was rendered from, if any
+	TemplateName string `db:"template_name"`
+	// Quoted is only populated when requested through MessageFilter.IncludeQuoted
+	Quoted *QuotedMessage `db:"-"`
+	// Status aggregates the receipts of messages sent by us; empty for others
+	Status string `db:"-"`
+	// Reactions is only populated when requested through MessageFilter.IncludeReactions
+	Reactions []Reaction `db:"-"`
+}
+
+// Location holds the coordinates of a location or live location message.
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	// Accuracy is the radius in meters, if the sender shared it
+	Accuracy uint32 `json:"accuracy,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Address  string `json:"address,omitempty"`
+	// SequenceNumber orders the updates of a live location; zero for static ones
+	SequenceNumber int64 `json:"sequence_number,omitempty"`
+}
+
+// ContactCard is a contact shared in a message, with the fields parsed from
+// its vCard next to the raw card.
+type ContactCard struct {
+	DisplayName  string   `json:"display_name"`
+	PhoneNumbers []string `json:"phone_numbers,omitempty"`
+	VCard        string   `json:"vcard"`
+}
+
+// Receipt types stored for sent messages
+const (
+	ReceiptTypeDelivered = "delivered"
+	ReceiptTypeRead      = "read"
+	ReceiptTypePlayed    = "played"
+)
+
+// Aggregated delivery status of a sent message. A message is delivered or read
+// once any recipient has delivered or read it.
+const (
+	MessageStatusSent      = "sent"
+	MessageStatusDelivered = "delivered"
+	MessageStatusRead      = "read"
+)
+
+// Receipt records that Recipient delivered, read or played a message we sent.
+type Receipt struct {
+	MessageID string    `db:"message_id"`
+	ChatJID   string    `db:"chat_jid"`
+	DeviceID  string    `db:"device_id"`
+	Recipient string    `db:"recipient"`
+	Type      string    `db:"type"`
+	Timestamp time.Time `db:"timestamp"`
+}
+
+// Poll is the question and options of a poll message.
+type Poll struct {
+	MessageID       string   `db:"message_id"`
+	ChatJID         string   `db:"chat_jid"`
+	DeviceID        string   `db:"device_id"`
+	Question        string   `db:"question"`
+	Options         []string `db:"options"`
+	SelectableCount int      `db:"selectable_count"` // 0 allows selecting every option
+}
+
+// PollVote is the current selection of a voter in a poll, by option name. An
+// empty selection means the voter withdrew their vote.
+type PollVote struct {
+	MessageID       string    `db:"message_id"`
+	ChatJID         string    `db:"chat_jid"`
+	DeviceID        string    `db:"device_id"`
+	Voter           string    `db:"voter"`
+	SelectedOptions []string  `db:"selected_options"`
+	Timestamp       time.Time `db:"timestamp"`
+}
+
+// QuotedMessage is a compact view of the message a reply refers to. Content is
+// empty when the quoted message is not stored.
+type QuotedMessage struct {
+	ID      string
+	Sender  string
+	Content string
+}
+
+// Reaction is the emoji a sender currently has on a message; each sender has at
+// most one per message.
+type Reaction struct {
+	MessageID string    `db:"message_id"`
+	ChatJID   string    `db:"chat_jid"`
+	DeviceID  string    `db:"device_id"`
+	Sender    string    `db:"sender"`
+	Emoji     string    `db:"emoji"`
+	Timestamp time.Time `db:"timestamp"`
+}
+
+// MessageEdit is a previous version of an edited message. EditedAt is when
+// Content was replaced by the next version.
+type MessageEdit struct {
+	MessageID string    `db:"message_id"`
+	ChatJID   string    `db:"chat_jid"`
+	DeviceID  string    `db:"device_id"`
+	Content   string    `db:"content"`
+	EditedAt  time.Time `db:"edited_at"`
+}
+
+// Contact is an entry of the device's WhatsApp contact store
+type Contact struct {
+	DeviceID     string    `db:"device_id"`
+	JID          string    `db:"jid"`
+	FullName     string    `db:"full_name"`
+	FirstName    string    `db:"first_name"`
+	PushName     string    `db:"push_name"`
+	BusinessName string    `db:"business_name"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+// Call is an incoming call offer. Its Outcome starts as CallOutcomeRinging and
+// is updated on the same row as the call is accepted, rejected or ends.
+type Call struct {
+	DeviceID  string    `db:"device_id"`
+	CallID    string    `db:"call_id"`
+	FromJID   string    `db:"from_jid"`
+	Timestamp time.Time `db:"timestamp"`
+	IsVideo   bool      `db:"is_video"`
+	Outcome   string    `db:"outcome"`
+	// Duration is the length of an accepted call in seconds, known once it ends
+	Duration   int        `db:"duration"`
+	AcceptedAt *time.Time `db:"accepted_at"`
+}
+
+// Call outcomes
+const (
+	CallOutcomeRinging      = "ringing"
+	CallOutcomeMissed       = "missed"
+	CallOutcomeRejected     = "rejected"
+	CallOutcomeAutoRejected = "auto_rejected"
+	CallOutcomeAccepted     = "accepted"
+)
+
+// GroupParticipant is a cached member of a group. JoinedAt is nil when the
+// member was already in the group when it was first cached.
+type GroupParticipant struct {
+	DeviceID       string     `db:"device_id"`
+	GroupJID       string     `db:"group_jid"`
+	ParticipantJID string     `db:"participant_jid"`
+	IsAdmin        bool       `db:"is_admin"`
+	IsSuperAdmin   bool       `db:"is_superadmin"`
+	JoinedAt       *time.Time `db:"joined_at"`
+}
+
+// GroupParticipantChange is an incremental update of a group's members, as
+// carried by group info notifications.
+type GroupParticipantChange struct {
+	Join      []string
+	Leave     []string
+	Promote   []string
+	Demote    []string
+	Timestamp time.Time
+}
+
+// ChatMerge is one duplicate chat folded into its canonical JID by DedupeChats.
+type ChatMerge struct {
+	DeviceID string
+	FromJID  string
+	ToJID    string
+	Messages int64
+}
+
+// SearchResult is a message matched by a device-wide search together with the
+// name of the chat it belongs to.
+type SearchResult struct {
+	Message
+	ChatName string `db:"chat_name"`
 }
 
 // MediaInfo represents downloadable media information
@@ -44,6 +275,10 @@ type MediaInfo struct {
 	FileSHA256    []byte
 	FileEncSHA256 []byte
 	FileLength    uint64
+	Location      *Location
+	Contacts      []ContactCard
+	IsAnimated    bool
+	IsViewOnce    bool
 }
 
 // DeviceRecord tracks a registered device for persistence purposes.
@@ -53,6 +288,114 @@ type DeviceRecord struct {
 	JID         string    `db:"jid"`
 	CreatedAt   time.Time `db:"created_at"`
 	UpdatedAt   time.Time `db:"updated_at"`
+	DeviceSettings
+	DeviceWebhook
+}
+
+// DeviceSettings are per-device overrides of global options. A nil field
+// falls back to the global configuration.
+type DeviceSettings struct {
+	AutoRejectCalls       *bool   `db:"auto_reject_calls"`
+	AutoRejectCallMessage *string `db:"auto_reject_call_message"`
+	AutoReplyMessage      *string `db:"auto_reply_message"` // Empty turns auto-reply off
+	AutoMarkRead          *bool   `db:"auto_mark_read"`
+}
+
+// MaskedWebhookSecret replaces a stored webhook secret in listings.
+const MaskedWebhookSecret = "********"
+
+// DeviceWebhook routes the webhooks of a device to its own endpoint. An empty
+// URL falls back to the global webhook configuration.
+type DeviceWebhook struct {
+	WebhookURL    string `db:"webhook_url"`
+	WebhookSecret string `db:"webhook_secret"` // Empty = the global webhook secret
+	WebhookEvents string `db:"webhook_events"` // Comma-separated, empty = every event
+}
+
+// APIKey is a stored API key. Only the SHA-256 hash of the key is kept, so
+// the key itself can't be recovered from storage.
+type APIKey struct {
+	ID         string     `db:"id"`
+	KeyHash    string     `db:"key_hash"`
+	Label      string     `db:"label"`
+	Scopes     []string   `db:"scopes"`
+	DeviceIDs  []string   `db:"device_ids"` // Empty allows every device
+	CreatedAt  time.Time  `db:"created_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	Revoked    bool       `db:"revoked"`
+}
+
+// AutoReplyRule replies ReplyText to incoming messages matching Pattern. A
+// device's rules are tried in Position order and the first match replies.
+type AutoReplyRule struct {
+	ID         string    `db:"id"`
+	DeviceID   string    `db:"device_id"`
+	Position   int       `db:"position"`
+	Pattern    string    `db:"pattern"`
+	MatchType  string    `db:"match_type"`
+	ReplyText  string    `db:"reply_text"`
+	OnlyGroups bool      `db:"only_groups"`
+	OnlyDM     bool      `db:"only_dm"`
+	Enabled    bool      `db:"enabled"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+// Label tags chats of a device. Labels are local metadata and are not synced
+// with WhatsApp Business labels.
+type Label struct {
+	ID        string    `db:"id"`
+	DeviceID  string    `db:"device_id"`
+	Name      string    `db:"name"`
+	Color     string    `db:"color"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+	// ChatCount is the number of chats tagged with the label; only set by ListLabels
+	ChatCount int `db:"-"`
+}
+
+// Template is a text message body with {{.name}} placeholders, rendered with
+// variables when sending. Names are unique per device.
+type Template struct {
+	DeviceID  string    `db:"device_id"`
+	Name      string    `db:"name"`
+	Body      string    `db:"body"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Job states. Jobs still queued or running when the server starts again were
+// cut short by the restart and are marked interrupted.
+const (
+	JobStatusQueued      = "queued"
+	JobStatusRunning     = "running"
+	JobStatusSucceeded   = "succeeded"
+	JobStatusFailed      = "failed"
+	JobStatusCanceled    = "canceled"
+	JobStatusInterrupted = "interrupted"
+)
+
+// Job is a long-running operation of a device run in the background, such as
+// a chat export.
+type Job struct {
+	ID        string    `db:"id"`
+	DeviceID  string    `db:"device_id"`
+	Type      string    `db:"type"`
+	Status    string    `db:"status"`
+	Progress  int       `db:"progress"` // Percent done
+	Result    string    `db:"result"`   // JSON, set once the job succeeded
+	Error     string    `db:"error"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// JobFilter selects the jobs of a device; empty fields match every job.
+type JobFilter struct {
+	DeviceID string
+	Type     string
+	Status   string
+	Limit    int
+	Offset   int
 }
 
 // MessageFilter represents query filters for messages
@@ -64,7 +407,25 @@ type MessageFilter struct {
 	StartTime *time.Time
 	EndTime   *time.Time
 	MediaOnly bool
-	IsFromMe  *bool
+	// MediaTypes restricts results to these media_type values; an empty string
+	// entry matches text-only messages.
+	MediaTypes []string
+	// Sender matches the sender JID regardless of the device suffix it was stored with.
+	Sender   string
+	IsFromMe *bool
+	// Unread restricts results to messages that haven't been marked read
+	Unread bool
+	// ServerID matches newsletter messages by their channel-wide ID
+	ServerID int64
+	// Before/After are keyset cursors on timestamp; BeforeID breaks ties between
+	// messages sharing the Before timestamp.
+	Before   *time.Time
+	BeforeID string
+	After    *time.Time
+	// IncludeReactions loads the reactions of every returned message
+	IncludeReactions bool
+	// IncludeQuoted loads the quoted message of every returned reply
+	IncludeQuoted bool
 }
 
 // ChatFilter represents query filters for chats
@@ -74,4 +435,61 @@ type ChatFilter struct {
 	Offset     int
 	SearchName string
 	HasMedia   bool
+	Archived   *bool
+	Pinned     *bool
+	Blocked    *bool
+	// Unread restricts results to chats with unread messages
+	Unread bool
+	// ChatType restricts results to one kind of chat, see the ChatType constants
+	ChatType string
+	// LabelID restricts results to chats tagged with the label
+	LabelID string
+}
+
+// Chat types accepted by ChatFilter.ChatType
+const (
+	ChatTypeUser       = "user"
+	ChatTypeGroup      = "group"
+	ChatTypeNewsletter = "newsletter"
+	// ChatTypeStatus is the status broadcast chat, which holds the statuses
+	// posted by the account and its contacts
+	ChatTypeStatus = "status"
+)
+
+// Chat types stored for community groups. A community is the parent group of
+// its linked groups, and its announcement group is where admins post to every
+// member.
+const (
+	ChatTypeCommunity         = "community"
+	ChatTypeCommunityAnnounce = "community_announce"
+)
+
+// CallFilter represents query filters for calls
+type CallFilter struct {
+	DeviceID  string
+	FromJID   string
+	StartTime *time.Time
+	EndTime   *time.Time
+	Limit     int
+	Offset    int
+}
+
+// ContactFilter represents query filters for contacts
+type ContactFilter struct {
+	DeviceID string
+	Limit    int
+	Offset   int
+	// Search matches any of the contact's names or its JID
+	Search string
+}
+
+// StorageStatistics summarizes how much chat data is stored for a device
+type StorageStatistics struct {
+	DeviceID        string
+	ChatCount       int64
+	MessageCount    int64
+	MediaBytes      int64
+	DistinctSenders int64
+	OldestMessage   *time.Time
+	NewestMessage   *time.Time
 }
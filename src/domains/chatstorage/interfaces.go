@@ -11,43 +11,143 @@ import (
 type IChatStorageRepository interface {
 	// Chat operations
 	CreateMessage(ctx context.Context, evt *events.Message) error
-	StoreChat(chat *Chat) error
-	GetChat(jid string) (*Chat, error)
-	GetChatByDevice(deviceID, jid string) (*Chat, error)
-	GetChats(filter *ChatFilter) ([]*Chat, error)
-	DeleteChat(jid string) error
-	DeleteChatByDevice(deviceID, jid string) error
+	StoreChat(ctx context.Context, chat *Chat) error
+	GetChat(ctx context.Context, jid string) (*Chat, error)
+	GetChatByDevice(ctx context.Context, deviceID, jid string) (*Chat, error)
+	GetChats(ctx context.Context, filter *ChatFilter) ([]*Chat, error)
+	CountChats(ctx context.Context, filter *ChatFilter) (int64, error)
+	DeleteChat(ctx context.Context, jid string) error
+	DeleteChatByDevice(ctx context.Context, deviceID, jid string) error
+	UpdateChatFlags(ctx context.Context, deviceID, jid string, flags ChatFlags) error // Chats that are not stored yet are skipped
+	SetBlockedChats(ctx context.Context, deviceID string, jids []string) error        // Marks exactly the stored chats of jids as blocked
+	MarkChatRead(ctx context.Context, deviceID, jid string) error
+	MarkMessagesRead(ctx context.Context, deviceID, chatJID string, ids []string) (marked int, err error) // Lowers the unread count by the messages that were unread
+	SetEphemeralExpiration(ctx context.Context, deviceID, jid string, expiration uint32) (previous uint32, err error)
+	MergeChats(ctx context.Context, deviceID, fromJID, toJID string) (moved int64, err error) // Moves fromJID's messages into toJID and deletes fromJID
 
 	// Message operations
-	StoreMessage(message *Message) error
-	StoreMessagesBatch(messages []*Message) error
-	GetMessageByID(id string) (*Message, error) // New method for efficient ID-only search
-	GetMessages(filter *MessageFilter) ([]*Message, error)
-	SearchMessages(deviceID, chatJID, searchText string, limit int) ([]*Message, error) // Database-level search with device isolation
-	DeleteMessage(id, chatJID string) error
-	DeleteMessageByDevice(deviceID, id, chatJID string) error
-	StoreSentMessageWithContext(ctx context.Context, messageID string, senderJID string, recipientJID string, content string, timestamp time.Time) error
+	StoreMessage(ctx context.Context, message *Message) error
+	StoreMessagesBatch(ctx context.Context, messages []*Message) (stored int, err error)
+	GetMessageByID(ctx context.Context, id string) (*Message, error) // New method for efficient ID-only search
+	GetMessageByDevice(ctx context.Context, deviceID, id string) (*Message, error)
+	GetOldestMessage(ctx context.Context, deviceID, chatJID string) (*Message, error) // Nil when the chat has no messages
+	GetMessages(ctx context.Context, filter *MessageFilter) ([]*Message, error)
+	StreamMessages(ctx context.Context, filter *MessageFilter, fn func(*Message) error) error // Oldest first, without buffering the result set
+	SearchMessages(ctx context.Context, filter *MessageFilter, searchText string) ([]*Message, error)
+	SearchMessagesGlobal(ctx context.Context, deviceID, searchText string, limit, offset int) ([]*SearchResult, error) // Database-level search with device isolation
+	DeleteMessage(ctx context.Context, id, chatJID string) error
+	DeleteMessageByDevice(ctx context.Context, deviceID, id, chatJID string) error
+	StoreSentMessageWithContext(ctx context.Context, messageID string, senderJID string, recipientJID string, content string, timestamp time.Time, media *MediaInfo) error
+	StoreMessageEdit(ctx context.Context, deviceID, id, chatJID, content string, editedAt time.Time) error // Keeps the replaced content as history
+	GetMessageEditHistory(ctx context.Context, deviceID, id, chatJID string) ([]*MessageEdit, error)
+	MarkMessageRevoked(ctx context.Context, deviceID, id, chatJID string, keepContent bool) error // Blanks content and media unless keepContent
+	SetMessageMediaPath(ctx context.Context, deviceID, id, chatJID, mediaPath string, size int64, downloadedAt time.Time) error
+	SetMessageMediaURL(ctx context.Context, deviceID, id, chatJID, url string) error                       // After the sender re-uploaded expired media
+	SetMessagePinnedUntil(ctx context.Context, deviceID, id, chatJID string, pinnedUntil *time.Time) error // nil unpins the message
+	GetPinnedMessages(ctx context.Context, deviceID, chatJID string, now time.Time) ([]*Message, error)    // Pins expiring after now, newest message first
+
+	// Reaction operations
+	StoreReaction(ctx context.Context, reaction *Reaction) error // An empty Emoji removes the sender's reaction
+	GetReactionsForMessage(ctx context.Context, deviceID, chatJID, messageID string) ([]*Reaction, error)
+
+	// Receipt operations
+	StoreReceipt(ctx context.Context, receipt *Receipt) error // Repeated receipts keep the first timestamp
+	GetReceiptsForMessage(ctx context.Context, deviceID, chatJID, messageID string) ([]*Receipt, error)
+
+	// Poll operations
+	StorePoll(ctx context.Context, poll *Poll) error
+	GetPoll(ctx context.Context, deviceID, chatJID, messageID string) (*Poll, error)
+	StorePollVote(ctx context.Context, vote *PollVote) error
+	GetPollVotes(ctx context.Context, deviceID, chatJID, messageID string) ([]*PollVote, error)
+
+	// Call operations
+	StoreCall(ctx context.Context, call *Call) error                                             // Repeated offers keep the recorded outcome
+	UpdateCallOutcome(ctx context.Context, deviceID, callID, outcome string, at time.Time) error // Only applies to calls still ringing
+	EndCall(ctx context.Context, deviceID, callID string, endedAt time.Time) error               // Accepted calls get their duration, ringing ones become missed
+	GetCalls(ctx context.Context, filter *CallFilter) ([]*Call, error)
+
+	// Contact operations
+	StoreContacts(ctx context.Context, contacts []*Contact) (stored int, err error)
+	GetContacts(ctx context.Context, filter *ContactFilter) ([]*Contact, error)
+	CountContacts(ctx context.Context, filter *ContactFilter) (int64, error)
+
+	// Group participant operations
+	SyncGroupParticipants(ctx context.Context, deviceID, groupJID string, participants []*GroupParticipant) error // Replaces the cached members, keeping known join times
+	UpdateGroupParticipants(ctx context.Context, deviceID, groupJID string, change GroupParticipantChange) error
+	GetGroupParticipants(ctx context.Context, deviceID, groupJID string) ([]*GroupParticipant, error)
+
+	// LID mapping operations
+	StoreLIDMapping(ctx context.Context, deviceID, lid, pnJID string) error
+	GetPNForLID(ctx context.Context, deviceID, lid string) (string, error) // Empty when the LID was never resolved
 
 	// Statistics
-	GetChatMessageCount(chatJID string) (int64, error)
-	GetChatMessageCountByDevice(deviceID, chatJID string) (int64, error)
-	GetTotalMessageCount() (int64, error)
-	GetTotalChatCount() (int64, error)
+	GetChatMessageCount(ctx context.Context, chatJID string) (int64, error)
+	GetChatMessageCountByDevice(ctx context.Context, deviceID, chatJID string) (int64, error)
+	GetTotalMessageCount(ctx context.Context) (int64, error)
+	GetTotalMessageCountByDevice(ctx context.Context, deviceID string) (int64, error)
+	GetTotalChatCount(ctx context.Context) (int64, error)
+	GetTotalChatCountByDevice(ctx context.Context, deviceID string) (int64, error)
 	GetChatNameWithPushName(jid types.JID, chatJID string, senderUser string, pushName string) string
 	GetChatNameWithPushNameByDevice(deviceID string, jid types.JID, chatJID string, senderUser string, pushName string) string
-	GetStorageStatistics() (chatCount int64, messageCount int64, err error)
+	GetStorageStatistics(ctx context.Context) (chatCount int64, messageCount int64, err error)
+	GetStorageStatisticsByDevice(ctx context.Context, deviceID string) (*StorageStatistics, error)
 
 	// Cleanup operations
-	TruncateAllChats() error
-	TruncateAllDataWithLogging(logPrefix string) error
-	DeleteDeviceData(deviceID string) error
+	TruncateAllChats(ctx context.Context) error
+	TruncateAllDataWithLogging(ctx context.Context, logPrefix, reportPath string) error
+	DeleteDeviceData(ctx context.Context, deviceID string) error
+	DedupeChats(ctx context.Context, dryRun bool) ([]*ChatMerge, error)
+	PruneMessagesBefore(ctx context.Context, deviceID string, cutoff time.Time) (int64, error) // Empty deviceID prunes every device
 
 	// Device registry operations
-	SaveDeviceRecord(record *DeviceRecord) error
-	ListDeviceRecords() ([]*DeviceRecord, error)
-	GetDeviceRecord(deviceID string) (*DeviceRecord, error)
-	DeleteDeviceRecord(deviceID string) error
+	SaveDeviceRecord(ctx context.Context, record *DeviceRecord) error
+	ListDeviceRecords(ctx context.Context) ([]*DeviceRecord, error)
+	GetDeviceRecord(ctx context.Context, deviceID string) (*DeviceRecord, error)
+	DeleteDeviceRecord(ctx context.Context, deviceID string) error
+	SaveDeviceSettings(ctx context.Context, deviceID string, settings DeviceSettings) error
+	SaveDeviceWebhook(ctx context.Context, deviceID string, webhook DeviceWebhook) error
+
+	// API key operations
+	StoreAPIKey(ctx context.Context, key *APIKey) error
+	ListAPIKeys(ctx context.Context) ([]*APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*APIKey, error) // nil when no key has the hash
+	RevokeAPIKey(ctx context.Context, id string) (found bool, err error)
+	TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error
+
+	// Auto-reply rule operations
+	StoreAutoReplyRule(ctx context.Context, rule *AutoReplyRule) error
+	ListAutoReplyRules(ctx context.Context, deviceID string) ([]*AutoReplyRule, error) // In evaluation order
+	GetAutoReplyRule(ctx context.Context, deviceID, id string) (*AutoReplyRule, error) // nil when the device has no such rule
+	DeleteAutoReplyRule(ctx context.Context, deviceID, id string) (found bool, err error)
+
+	// Label operations
+	StoreLabel(ctx context.Context, label *Label) error
+	ListLabels(ctx context.Context, deviceID string) ([]*Label, error) // By name
+	GetLabel(ctx context.Context, deviceID, id string) (*Label, error) // nil when the device has no such label
+	DeleteLabel(ctx context.Context, deviceID, id string) (found bool, err error)
+	SetChatLabels(ctx context.Context, deviceID, chatJID string, labelIDs []string) error
+	GetChatLabels(ctx context.Context, deviceID string, chatJIDs []string) (map[string][]*Label, error) // Keyed by chat JID
+
+	// Template operations
+	StoreTemplate(ctx context.Context, tmpl *Template) error
+	ListTemplates(ctx context.Context, deviceID string) ([]*Template, error)   // By name
+	GetTemplate(ctx context.Context, deviceID, name string) (*Template, error) // nil when the device has no such template
+	DeleteTemplate(ctx context.Context, deviceID, name string) (found bool, err error)
+
+	// Job operations
+	StoreJob(ctx context.Context, job *Job) error
+	GetJob(ctx context.Context, deviceID, id string) (*Job, error)   // nil when the device has no such job
+	ListJobs(ctx context.Context, filter *JobFilter) ([]*Job, error) // Newest first
+	DeleteJob(ctx context.Context, deviceID, id string) (found bool, err error)
+	InterruptJobs(ctx context.Context, reason string) (int64, error) // Marks every queued or running job interrupted
+
+	// Backup operations. Rows are dialect-neutral JSON objects; an empty
+	// deviceID covers every device.
+	ExportTable(ctx context.Context, table, deviceID string, fn func(row []byte) error) error
+	ImportTable(ctx context.Context, table, deviceID string, next func() ([]byte, error)) (int64, error)
+	CountTable(ctx context.Context, table string) (int64, error)
 
 	// Schema operations
-	InitializeSchema() error
+	InitializeSchema(ctx context.Context) error
+	Ping(ctx context.Context) error
 }
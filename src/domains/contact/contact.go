@@ -0,0 +1,47 @@
+package contact
+
+import "context"
+
+type IContactUsecase interface {
+	ListContacts(ctx context.Context, request ListContactsRequest) (response ListContactsResponse, err error)
+	// SyncContacts copies the WhatsApp contact store into chat storage in a
+	// background job.
+	SyncContacts(ctx context.Context) (response SyncContactsResponse, err error)
+}
+
+type ListContactsRequest struct {
+	Limit  int    `json:"limit" query:"limit"`
+	Offset int    `json:"offset" query:"offset"`
+	Search string `json:"search" query:"search"`
+}
+
+type ListContactsResponse struct {
+	Data       []ContactInfo      `json:"data"`
+	Pagination PaginationResponse `json:"pagination"`
+}
+
+type ContactInfo struct {
+	JID          string `json:"jid"`
+	FullName     string `json:"full_name"`
+	FirstName    string `json:"first_name"`
+	PushName     string `json:"push_name"`
+	BusinessName string `json:"business_name"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+type PaginationResponse struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
+
+// SyncContactsResponse names the contact_sync job SyncContacts started.
+type SyncContactsResponse struct {
+	JobID  string `json:"job_id"`
+	Status string `json:"status"`
+}
+
+// SyncContactsResult is the result of a contact_sync job.
+type SyncContactsResult struct {
+	Synced int `json:"synced"`
+}
@@ -15,10 +15,48 @@ const (
 
 // Device describes a WhatsApp account/device tracked by the system.
 type Device struct {
-	ID          string      `json:"id"`
-	PhoneNumber string      `json:"phone_number,omitempty"`
-	DisplayName string      `json:"display_name,omitempty"`
-	State       DeviceState `json:"state"`
-	JID         string      `json:"jid,omitempty"`
-	CreatedAt   time.Time   `json:"created_at"`
+	ID              string      `json:"id"`
+	PhoneNumber     string      `json:"phone_number,omitempty"`
+	DisplayName     string      `json:"display_name,omitempty"`
+	State           DeviceState `json:"state"`
+	JID             string      `json:"jid,omitempty"`
+	CreatedAt       time.Time   `json:"created_at"`
+	IsConnected     bool        `json:"is_connected"`
+	IsLoggedIn      bool        `json:"is_logged_in"`
+	PushName        string      `json:"push_name,omitempty"`
+	AvatarID        string      `json:"avatar_id,omitempty"` // Changes whenever the profile picture does
+	LastSeenEventAt *time.Time  `json:"last_seen_event_at"`
+	LastConnectAt   *time.Time  `json:"last_connect_at"`
+	ReconnectCount  int         `json:"reconnect_count"`
+}
+
+// AddDeviceRequest registers a new device slot ready for pairing. An empty
+// DeviceID generates one.
+type AddDeviceRequest struct {
+	DeviceID    string `json:"device_id"`
+	DisplayName string `json:"display_name"`
+}
+
+// RenameDeviceRequest changes the display name of a device, which is also its
+// WhatsApp push name once logged in.
+type RenameDeviceRequest struct {
+	Name string `json:"name"`
+}
+
+// DeviceSettings are per-device options. A null field falls back to the
+// matching global flag.
+type DeviceSettings struct {
+	AutoRejectCalls       *bool   `json:"auto_reject_calls"`
+	AutoRejectCallMessage *string `json:"auto_reject_call_message"`
+	AutoReplyMessage      *string `json:"auto_reply_message"`
+	AutoMarkRead          *bool   `json:"auto_mark_read"`
+}
+
+// DeviceWebhook sends the events of a device to its own endpoint instead of
+// the global webhooks. An empty secret signs deliveries with the global secret
+// and empty events receive every event.
+type DeviceWebhook struct {
+	WebhookURL    string   `json:"webhook_url"`
+	WebhookSecret string   `json:"webhook_secret,omitempty"`
+	WebhookEvents []string `json:"webhook_events"`
 }
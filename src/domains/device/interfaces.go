@@ -6,11 +6,18 @@ import "context"
 type IDeviceUsecase interface {
 	ListDevices(ctx context.Context) ([]Device, error)
 	GetDevice(ctx context.Context, deviceID string) (*Device, error)
-	AddDevice(ctx context.Context, deviceID string) (*Device, error)
+	AddDevice(ctx context.Context, request AddDeviceRequest) (*Device, error)
 	RemoveDevice(ctx context.Context, deviceID string) error
+	RenameDevice(ctx context.Context, deviceID string, request RenameDeviceRequest) (*Device, error)
 	LoginDevice(ctx context.Context, deviceID string) error
 	LoginDeviceWithCode(ctx context.Context, deviceID string, phone string) (string, error)
 	LogoutDevice(ctx context.Context, deviceID string) error
 	ReconnectDevice(ctx context.Context, deviceID string) error
-	GetStatus(ctx context.Context, deviceID string) (isConnected bool, isLoggedIn bool, err error)
+	GetStatus(ctx context.Context, deviceID string) (*Device, error)
+	GetSettings(ctx context.Context, deviceID string) (*DeviceSettings, error)
+	UpdateSettings(ctx context.Context, deviceID string, settings DeviceSettings) (*DeviceSettings, error)
+	PatchSettings(ctx context.Context, deviceID string, settings DeviceSettings) (*DeviceSettings, error)
+	GetWebhook(ctx context.Context, deviceID string) (*DeviceWebhook, error)
+	UpdateWebhook(ctx context.Context, deviceID string, webhook DeviceWebhook) (*DeviceWebhook, error)
+	DeleteWebhook(ctx context.Context, deviceID string) error
 }
@@ -13,13 +13,95 @@ type JoinGroupWithLinkRequest struct {
 	Link string `json:"link" form:"link"`
 }
 
+// Join results. Groups that need admin approval only get a request to join.
+const (
+	JoinStatusJoined          = "joined"
+	JoinStatusPendingApproval = "pending_approval"
+)
+
+type JoinGroupWithLinkResponse struct {
+	GroupID string `json:"group_id"`
+	Status  string `json:"status"`
+}
+
 type LeaveGroupRequest struct {
 	GroupID string `json:"group_id" form:"group_id"`
 }
 
+type LeaveGroupResponse struct {
+	GroupID string `json:"group_id"`
+	Warning string `json:"warning,omitempty"`
+}
+
+type ListGroupsRequest struct {
+	Limit  int `json:"limit" query:"limit"`
+	Offset int `json:"offset" query:"offset"`
+}
+
+type ListGroupsResponse struct {
+	Data       []GroupSummary     `json:"data"`
+	Pagination PaginationResponse `json:"pagination"`
+}
+
+// GroupSummary is a joined group with what chat storage knows about it.
+type GroupSummary struct {
+	JID              string     `json:"jid"`
+	Name             string     `json:"name"`
+	Topic            string     `json:"topic,omitempty"`
+	ParticipantCount int        `json:"participant_count"`
+	IsAdmin          bool       `json:"is_admin"`
+	IsAnnounce       bool       `json:"is_announce"`
+	IsLocked         bool       `json:"is_locked"`
+	CreatedAt        time.Time  `json:"created_at"`
+	LastMessageTime  *time.Time `json:"last_message_time,omitempty"` // Only set for groups in chat storage
+	UnreadCount      int        `json:"unread_count"`
+}
+
+type ListCommunitiesResponse struct {
+	Data []Community `json:"data"`
+}
+
+// Community is a parent group with the groups linked to it.
+type Community struct {
+	JID       string           `json:"jid"`
+	Name      string           `json:"name"`
+	Topic     string           `json:"topic,omitempty"`
+	IsAdmin   bool             `json:"is_admin"`
+	CreatedAt time.Time        `json:"created_at"`
+	Groups    []CommunityGroup `json:"groups"`
+}
+
+type CommunityGroup struct {
+	JID            string `json:"jid"`
+	Name           string `json:"name"`
+	IsAnnouncement bool   `json:"is_announcement"` // The group admins post to every member in
+	IsMember       bool   `json:"is_member"`
+}
+
+// CommunityGroupRequest links a group to a community or unlinks it.
+type CommunityGroupRequest struct {
+	CommunityID string `json:"community_id" uri:"community_id"`
+	GroupID     string `json:"group_id" form:"group_id"`
+}
+
+type PaginationResponse struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
+
 type CreateGroupRequest struct {
 	Title        string   `json:"title" form:"title"`
 	Participants []string `json:"participants" form:"participants"`
+	Description  string   `json:"description" form:"description"`
+	Announce     bool     `json:"announce" form:"announce"` // Only admins can send messages
+	Restrict     bool     `json:"restrict" form:"restrict"` // Only admins can edit the group info
+}
+
+type CreateGroupResponse struct {
+	GroupID string `json:"group_id"`
+	// Participants holds the outcome of adding each requested participant
+	Participants []ParticipantStatus `json:"participants"`
 }
 
 type ParticipantRequest struct {
@@ -32,10 +114,18 @@ type ParticipantStatus struct {
 	Participant string `json:"participant"`
 	Status      string `json:"status"`
 	Message     string `json:"message"`
+	// Code is the error code WhatsApp returned for a participant the action failed for
+	Code int `json:"code,omitempty"`
+	// InviteCode can be sent to a participant whose privacy settings don't
+	// allow adding them, so they can join by themselves until InviteExpiration
+	InviteCode       string     `json:"invite_code,omitempty"`
+	InviteExpiration *time.Time `json:"invite_expiration,omitempty"`
 }
 
 type GetGroupParticipantsRequest struct {
 	GroupID string `json:"group_id" query:"group_id"`
+	// Refresh reloads the participant cache from WhatsApp before answering
+	Refresh bool `json:"refresh" query:"refresh"`
 }
 
 type GroupParticipant struct {
@@ -45,6 +135,7 @@ type GroupParticipant struct {
 	DisplayName  string `json:"display_name,omitempty"`
 	IsAdmin      bool   `json:"is_admin"`
 	IsSuperAdmin bool   `json:"is_super_admin"`
+	JoinedAt     string `json:"joined_at,omitempty"`
 }
 
 type GetGroupParticipantsResponse struct {
@@ -100,6 +191,16 @@ type SetGroupTopicRequest struct {
 	Topic   string `json:"topic" form:"topic"`
 }
 
+// SetGroupSettingsRequest changes the settings that are set and leaves the
+// others as they are.
+type SetGroupSettingsRequest struct {
+	GroupID           string  `json:"group_id" form:"group_id"`
+	Announce          *bool   `json:"announce" form:"announce"`                     // Only admins can send messages
+	Locked            *bool   `json:"locked" form:"locked"`                         // Only admins can edit group info
+	MemberAddMode     string  `json:"member_add_mode" form:"member_add_mode"`       // admin_add or all_member_add
+	DisappearingTimer *uint32 `json:"disappearing_timer" form:"disappearing_timer"` // Seconds, zero turns it off
+}
+
 type GetGroupInfoFromLinkRequest struct {
 	Link string `json:"link" form:"link"`
 }
@@ -114,6 +215,9 @@ type GetGroupInfoFromLinkResponse struct {
 	IsAnnounce       bool      `json:"is_announce"`
 	IsEphemeral      bool      `json:"is_ephemeral"`
 	Description      string    `json:"description"`
+	Creator          string    `json:"creator,omitempty"`
+	// RequiresApproval is set when joining only sends a request to the admins
+	RequiresApproval bool `json:"requires_approval"`
 }
 
 type GroupInfoRequest struct {
@@ -6,9 +6,10 @@ import (
 
 // IGroupManagement handles basic group management operations
 type IGroupManagement interface {
-	JoinGroupWithLink(ctx context.Context, request JoinGroupWithLinkRequest) (groupID string, err error)
-	LeaveGroup(ctx context.Context, request LeaveGroupRequest) (err error)
-	CreateGroup(ctx context.Context, request CreateGroupRequest) (groupID string, err error)
+	JoinGroupWithLink(ctx context.Context, request JoinGroupWithLinkRequest) (response JoinGroupWithLinkResponse, err error)
+	LeaveGroup(ctx context.Context, request LeaveGroupRequest) (response LeaveGroupResponse, err error)
+	ListGroups(ctx context.Context, request ListGroupsRequest) (response ListGroupsResponse, err error)
+	CreateGroup(ctx context.Context, request CreateGroupRequest) (response CreateGroupResponse, err error)
 	GetGroupInfoFromLink(ctx context.Context, request GetGroupInfoFromLinkRequest) (response GetGroupInfoFromLinkResponse, err error)
 	GetGroupInviteLink(ctx context.Context, request GetGroupInviteLinkRequest) (response GetGroupInviteLinkResponse, err error)
 	GroupInfo(ctx context.Context, request GroupInfoRequest) (response GroupInfoResponse, err error)
@@ -18,6 +19,8 @@ type IGroupManagement interface {
 type IGroupParticipants interface {
 	ManageParticipant(ctx context.Context, request ParticipantRequest) (result []ParticipantStatus, err error)
 	GetGroupParticipants(ctx context.Context, request GetGroupParticipantsRequest) (response GetGroupParticipantsResponse, err error)
+	GetCachedGroupParticipants(ctx context.Context, request GetGroupParticipantsRequest) (response GetGroupParticipantsResponse, err error)
+	RefreshGroupParticipants(ctx context.Context, request GetGroupParticipantsRequest) (response GetGroupParticipantsResponse, err error)
 	GetGroupRequestParticipants(ctx context.Context, request GetGroupRequestParticipantsRequest) (result []GetGroupRequestParticipantsResponse, err error)
 	ManageGroupRequestParticipants(ctx context.Context, request GroupRequestParticipantsRequest) (result []ParticipantStatus, err error)
 }
@@ -29,6 +32,14 @@ type IGroupSettings interface {
 	SetGroupLocked(ctx context.Context, request SetGroupLockedRequest) (err error)
 	SetGroupAnnounce(ctx context.Context, request SetGroupAnnounceRequest) (err error)
 	SetGroupTopic(ctx context.Context, request SetGroupTopicRequest) (err error)
+	SetGroupSettings(ctx context.Context, request SetGroupSettingsRequest) (err error)
+}
+
+// IGroupCommunity handles communities and the groups linked to them
+type IGroupCommunity interface {
+	ListCommunities(ctx context.Context) (response ListCommunitiesResponse, err error)
+	LinkCommunityGroup(ctx context.Context, request CommunityGroupRequest) (err error)
+	UnlinkCommunityGroup(ctx context.Context, request CommunityGroupRequest) (err error)
 }
 
 // IGroupUsecase combines all group interfaces for backward compatibility
@@ -36,4 +47,5 @@ type IGroupUsecase interface {
 	IGroupManagement
 	IGroupParticipants
 	IGroupSettings
+	IGroupCommunity
 }
@@ -0,0 +1,21 @@
+package job
+
+import "context"
+
+// IJobUsecase runs the background jobs of the device in the context.
+type IJobUsecase interface {
+	// Start queues run as a job of jobType and returns right away.
+	Start(ctx context.Context, jobType string, run Run) (Job, error)
+	ListJobs(ctx context.Context, request ListJobsRequest) ([]Job, error)
+	GetJob(ctx context.Context, id string) (Job, error)
+	GetJobOutput(ctx context.Context, id string) (Output, error)
+	// CancelJob cancels a queued or running job and waits for it to stop. A
+	// finished job is deleted with its output instead.
+	CancelJob(ctx context.Context, id string) (Job, error)
+	// InterruptJobs marks the jobs a previous run of the server left
+	// unfinished as interrupted.
+	InterruptJobs(ctx context.Context) error
+	// Stop cancels the jobs in progress at shutdown, marking them
+	// interrupted, and waits for them to stop until ctx ends.
+	Stop(ctx context.Context) error
+}
@@ -0,0 +1,57 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Job types
+const (
+	TypeChatExport  = "chat_export"
+	TypeContactSync = "contact_sync"
+)
+
+// Job is a long-running operation of a device run in the background. Status
+// is queued, running, succeeded, failed, canceled or interrupted; jobs cut
+// short by a restart of the server are interrupted.
+type Job struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Status   string `json:"status"`
+	Progress int    `json:"progress"` // Percent done
+	// Result is set once the job succeeded, its shape depends on Type
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	// HasOutput is set when the job produced a file to download
+	HasOutput bool      `json:"has_output"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ListJobsRequest struct {
+	Type   string `json:"type" query:"type"`
+	Status string `json:"status" query:"status"`
+	Limit  int    `json:"limit" query:"limit"`
+	Offset int    `json:"offset" query:"offset"`
+}
+
+// Output is the file a job produced.
+type Output struct {
+	Path string
+	Name string
+}
+
+// Run is the work of a job. It must return soon after ctx ends; the job is
+// then canceled or interrupted, whatever Run returns.
+type Run func(ctx context.Context, task Task) (result any, err error)
+
+// Task lets a running job report on itself.
+type Task interface {
+	// SetProgress reports the percent of the work done.
+	SetProgress(percent int)
+	// CreateOutput creates the file the job produces under name, which can be
+	// downloaded once the job succeeded.
+	CreateOutput(name string) (io.WriteCloser, error)
+}
@@ -0,0 +1,14 @@
+package label
+
+import "context"
+
+// ILabelUsecase manages the labels of the device in the context and the
+// chats they tag.
+type ILabelUsecase interface {
+	CreateLabel(ctx context.Context, request CreateLabelRequest) (Label, error)
+	ListLabels(ctx context.Context) ([]Label, error)
+	GetLabel(ctx context.Context, id string) (Label, error)
+	UpdateLabel(ctx context.Context, request UpdateLabelRequest) (Label, error)
+	DeleteLabel(ctx context.Context, id string) error
+	SetChatLabels(ctx context.Context, request SetChatLabelsRequest) (SetChatLabelsResponse, error)
+}
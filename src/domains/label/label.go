@@ -0,0 +1,42 @@
+package label
+
+import "time"
+
+// Label tags chats of a device, e.g. to route them through a support
+// workflow. Labels are local to this service and are not synced with
+// WhatsApp Business labels.
+type Label struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"` // #RRGGBB, empty when unset
+	// ChatCount is the number of chats tagged with the label
+	ChatCount int       `json:"chat_count"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateLabelRequest adds a label to the device of the request. Names are
+// unique per device, ignoring case.
+type CreateLabelRequest struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// UpdateLabelRequest changes the fields of a label that are set.
+type UpdateLabelRequest struct {
+	ID    string  `json:"-"`
+	Name  *string `json:"name"`
+	Color *string `json:"color"`
+}
+
+// SetChatLabelsRequest replaces the labels of a stored chat; an empty
+// LabelIDs removes them all.
+type SetChatLabelsRequest struct {
+	ChatJID  string   `json:"chat_jid" uri:"chat_jid"`
+	LabelIDs []string `json:"label_ids"`
+}
+
+type SetChatLabelsResponse struct {
+	ChatJID string  `json:"chat_jid"`
+	Labels  []Label `json:"labels"`
+}
@@ -16,7 +16,12 @@ type IMessageActions interface {
 type IMessageManagement interface {
 	DeleteMessage(ctx context.Context, request DeleteRequest) (err error)
 	StarMessage(ctx context.Context, request StarRequest) (err error)
+	PinMessage(ctx context.Context, request PinRequest) (response GenericResponse, err error)
 	DownloadMedia(ctx context.Context, request DownloadMediaRequest) (response DownloadMediaResponse, err error)
+	GetMessageMedia(ctx context.Context, request GetMessageMediaRequest) (response GetMessageMediaResponse, err error)
+	GetMessage(ctx context.Context, request GetMessageRequest) (response GetMessageResponse, err error)
+	GetReceipts(ctx context.Context, request GetReceiptsRequest) (response GetReceiptsResponse, err error)
+	GetPoll(ctx context.Context, request GetPollRequest) (response GetPollResponse, err error)
 }
 
 // IMessageUsecase combines all message interfaces
@@ -1,5 +1,7 @@
 package message
 
+import "time"
+
 type GenericResponse struct {
 	MessageID string `json:"message_id"`
 	Status    string `json:"status"`
@@ -8,6 +10,9 @@ type GenericResponse struct {
 type RevokeRequest struct {
 	MessageID string `json:"message_id" uri:"message_id"`
 	Phone     string `json:"phone" form:"phone"`
+	// Participant is the sender of a group message revoked as an admin; it
+	// defaults to the sender in chat storage
+	Participant string `json:"participant,omitempty" form:"participant"`
 }
 
 type DeleteRequest struct {
@@ -24,7 +29,9 @@ type ReactionRequest struct {
 type UpdateMessageRequest struct {
 	MessageID string `json:"message_id" uri:"message_id"`
 	Message   string `json:"message" form:"message"`
-	Phone     string `json:"phone" form:"phone"`
+	// NewMessage is the name POST /message/:message_id/edit takes the text by
+	NewMessage string `json:"new_message" form:"new_message"`
+	Phone      string `json:"phone" form:"phone"`
 }
 
 type MarkAsReadRequest struct {
@@ -38,6 +45,84 @@ type StarRequest struct {
 	IsStarred bool   `json:"is_starred"`
 }
 
+// PinRequest pins a message in its chat for Duration, or unpins it.
+type PinRequest struct {
+	MessageID string `json:"message_id" uri:"message_id"`
+	Phone     string `json:"phone" form:"phone"`
+	// Duration is one of the keys of PinDurations; only used when pinning
+	Duration string `json:"duration" form:"duration"`
+	IsPinned bool   `json:"is_pinned"`
+}
+
+// PinDurations are the durations WhatsApp lets a message be pinned for.
+var PinDurations = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+type GetMessageRequest struct {
+	MessageID string `json:"message_id" uri:"message_id"`
+}
+
+// GetMessageResponse is a stored message of the device
+type GetMessageResponse struct {
+	ID        string `json:"id"`
+	ChatJID   string `json:"chat_jid"`
+	SenderJID string `json:"sender_jid"`
+	Content   string `json:"content"`
+	Timestamp string `json:"timestamp"`
+	IsFromMe  bool   `json:"is_from_me"`
+	MediaType string `json:"media_type"`
+	Filename  string `json:"filename"`
+	EditedAt  string `json:"edited_at,omitempty"`
+	IsDeleted bool   `json:"is_deleted"`
+	ReplyToID string `json:"reply_to_id,omitempty"`
+	// RequestID is the X-Request-ID of the API call that sent the message
+	RequestID string `json:"request_id,omitempty"`
+	// TemplateName is the template the message was rendered from
+	TemplateName string `json:"template_name,omitempty"`
+}
+
+type GetReceiptsRequest struct {
+	MessageID string `json:"message_id" uri:"message_id"`
+	Phone     string `json:"phone" query:"phone"`
+}
+
+type GetReceiptsResponse struct {
+	MessageID string        `json:"message_id"`
+	ChatJID   string        `json:"chat_jid"`
+	Receipts  []ReceiptInfo `json:"receipts"`
+}
+
+// ReceiptInfo is one delivered, read or played receipt of a recipient
+type ReceiptInfo struct {
+	Recipient string `json:"recipient"`
+	Type      string `json:"type"`
+	Timestamp string `json:"timestamp"`
+}
+
+type GetPollRequest struct {
+	MessageID string `json:"message_id" uri:"message_id"`
+	Phone     string `json:"phone" query:"phone"`
+}
+
+type GetPollResponse struct {
+	MessageID       string           `json:"message_id"`
+	ChatJID         string           `json:"chat_jid"`
+	Question        string           `json:"question"`
+	SelectableCount int              `json:"selectable_count"`
+	TotalVoters     int              `json:"total_voters"`
+	Options         []PollOptionInfo `json:"options"`
+}
+
+// PollOptionInfo is one poll option with the voters currently selecting it
+type PollOptionInfo struct {
+	Name   string   `json:"name"`
+	Votes  int      `json:"votes"`
+	Voters []string `json:"voters"`
+}
+
 type DownloadMediaRequest struct {
 	MessageID string `json:"message_id" uri:"message_id"`
 	Phone     string `json:"phone" form:"phone"`
@@ -51,3 +136,17 @@ type DownloadMediaResponse struct {
 	FilePath  string `json:"file_path"`
 	FileSize  int64  `json:"file_size"`
 }
+
+type GetMessageMediaRequest struct {
+	MessageID string `json:"message_id" uri:"message_id"`
+	Phone     string `json:"phone" query:"phone"`
+}
+
+// GetMessageMediaResponse points at the local copy of a message attachment
+type GetMessageMediaResponse struct {
+	MessageID string `json:"message_id"`
+	MediaType string `json:"media_type"`
+	Filename  string `json:"filename"`
+	FilePath  string `json:"file_path"`
+	FileSize  int64  `json:"file_size"`
+}
@@ -1,11 +1,108 @@
 package newsletter
 
-import "context"
+import (
+	"context"
+	"mime/multipart"
+)
 
 type INewsletterUsecase interface {
+	CreateNewsletter(ctx context.Context, request CreateNewsletterRequest) (response Newsletter, err error)
+	ListNewsletters(ctx context.Context) (response ListNewslettersResponse, err error)
+	Follow(ctx context.Context, request FollowRequest) (response Newsletter, err error)
 	Unfollow(ctx context.Context, request UnfollowRequest) (err error)
+	Mute(ctx context.Context, request MuteRequest) (err error)
+	Send(ctx context.Context, request SendRequest) (response SendResponse, err error)
+	React(ctx context.Context, request ReactRequest) (err error)
+	GetNewsletterMessages(ctx context.Context, request GetNewsletterMessagesRequest) (response GetNewsletterMessagesResponse, err error)
+}
+
+type CreateNewsletterRequest struct {
+	Name        string                `json:"name" form:"name"`
+	Description string                `json:"description" form:"description"`
+	Picture     *multipart.FileHeader `json:"picture" form:"picture"`
+}
+
+type FollowRequest struct {
+	NewsletterID string `json:"newsletter_id" form:"newsletter_id"`
 }
 
 type UnfollowRequest struct {
 	NewsletterID string `json:"newsletter_id" form:"newsletter_id"`
 }
+
+// MuteRequest mutes a channel's notifications, or unmutes them when Mute is
+// false. A missing Mute mutes.
+type MuteRequest struct {
+	NewsletterID string `json:"newsletter_id" form:"newsletter_id"`
+	Mute         *bool  `json:"mute" form:"mute"`
+}
+
+// SendRequest publishes a post to a channel the device administers. Posts are
+// text, or an image from Image or ImageURL with Message as its caption.
+type SendRequest struct {
+	NewsletterID string                `json:"newsletter_id" form:"newsletter_id"`
+	Message      string                `json:"message" form:"message"`
+	Image        *multipart.FileHeader `json:"image" form:"image"`
+	ImageURL     *string               `json:"image_url" form:"image_url"`
+}
+
+// SendResponse identifies a published post. ServerID is what reactions and
+// views refer to.
+type SendResponse struct {
+	MessageID string `json:"message_id"`
+	ServerID  int64  `json:"server_id"`
+	Timestamp string `json:"timestamp"`
+}
+
+// ReactRequest reacts to a channel post by its server ID. An empty Emoji
+// removes the reaction.
+type ReactRequest struct {
+	NewsletterID string `json:"newsletter_id" form:"newsletter_id"`
+	ServerID     int64  `json:"server_id" form:"server_id"`
+	Emoji        string `json:"emoji" form:"emoji"`
+}
+
+// Newsletter is a WhatsApp channel as seen by the device. Role is empty for
+// channels the device doesn't follow.
+type Newsletter struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Description     string `json:"description,omitempty"`
+	InviteLink      string `json:"invite_link,omitempty"`
+	SubscriberCount int    `json:"subscriber_count"`
+	Verified        bool   `json:"verified"`
+	Role            string `json:"role,omitempty"` // owner, admin, subscriber or guest
+	Muted           bool   `json:"muted"`
+	PictureURL      string `json:"picture_url,omitempty"`
+	CreatedAt       string `json:"created_at,omitempty"`
+}
+
+type ListNewslettersResponse struct {
+	Data []Newsletter `json:"data"`
+}
+
+type GetNewsletterMessagesRequest struct {
+	NewsletterID string `json:"newsletter_id" query:"newsletter_id"`
+	Count        int    `json:"count" query:"count"`
+}
+
+// NewsletterMessage is a channel message. ViewsCount and ReactionCounts are
+// only known for messages fetched from the server.
+type NewsletterMessage struct {
+	ID             string         `json:"id"`
+	ServerID       int64          `json:"server_id"`
+	Content        string         `json:"content"`
+	MediaType      string         `json:"media_type,omitempty"`
+	Timestamp      string         `json:"timestamp"`
+	ViewsCount     int            `json:"views_count,omitempty"`
+	ReactionCounts map[string]int `json:"reaction_counts,omitempty"`
+}
+
+// GetNewsletterMessagesResponse lists channel messages, newest first. Source
+// is "storage" when they were served from chat storage and "server" when they
+// were fetched from WhatsApp.
+type GetNewsletterMessagesResponse struct {
+	NewsletterID string              `json:"newsletter_id"`
+	Source       string              `json:"source"`
+	Data         []NewsletterMessage `json:"data"`
+}
@@ -1,7 +1,17 @@
 package send
 
+import "time"
+
 type BaseRequest struct {
 	Phone       string `json:"phone" form:"phone"`
 	Duration    *int   `json:"duration,omitempty" form:"duration"`
 	IsForwarded bool   `json:"is_forwarded,omitempty" form:"is_forwarded"`
+	Async       bool   `json:"async,omitempty" form:"async"` // Queue the message and return its job instead of waiting for the send
+	// ReplyMessageID quotes a message of the chat. When it isn't in chat storage
+	// the quote only carries the ID, unless ReplyStrict refuses to send.
+	ReplyMessageID *string `json:"reply_message_id,omitempty" form:"reply_message_id"`
+	ReplyStrict    bool    `json:"reply_strict,omitempty" form:"reply_strict"`
+	// Typing is how long the typing indicator shows before the message is
+	// sent. It isn't part of the API; SendText sets it for simulate_typing.
+	Typing time.Duration `json:"-" form:"-"`
 }
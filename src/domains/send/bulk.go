@@ -0,0 +1,37 @@
+package send
+
+// Bulk recipient states. Invalid recipients failed the up-front check and
+// were never sent to; pending ones are still waiting in an async batch.
+const (
+	BulkStatusPending = "pending"
+	BulkStatusSent    = "sent"
+	BulkStatusFailed  = "failed"
+	BulkStatusInvalid = "invalid"
+)
+
+type BulkMessageRequest struct {
+	Phones     []string `json:"phones" form:"phones"`
+	Message    string   `json:"message" form:"message"`
+	DelayMsMin int      `json:"delay_ms_min" form:"delay_ms_min"` // Shortest pause between two recipients
+	DelayMsMax int      `json:"delay_ms_max" form:"delay_ms_max"` // Longest pause between two recipients
+	Async      bool     `json:"async,omitempty" form:"async"`     // Return a job right away instead of waiting for the batch
+	TemplateRequest
+	// RecipientVariables are template variables of single phones, keyed by
+	// the phone as given in phones. They override Variables.
+	RecipientVariables map[string]map[string]string `json:"recipient_variables,omitempty" form:"-"`
+}
+
+type BulkResult struct {
+	Phone     string `json:"phone"`
+	Status    string `json:"status"`
+	MessageID string `json:"message_id,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type BulkResponse struct {
+	JobID   string       `json:"job_id,omitempty"`
+	Status  string       `json:"status"`
+	Sent    int          `json:"sent"`
+	Failed  int          `json:"failed"`
+	Results []BulkResult `json:"results"`
+}
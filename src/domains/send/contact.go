@@ -2,6 +2,17 @@ package send
 
 type ContactRequest struct {
 	BaseRequest
-	ContactName  string `json:"contact_name" form:"contact_name"`
-	ContactPhone string `json:"contact_phone" form:"contact_phone"`
+	ContactName   string   `json:"contact_name" form:"contact_name"`
+	ContactPhone  string   `json:"contact_phone" form:"contact_phone"`
+	ContactPhones []string `json:"contact_phones,omitempty" form:"contact_phones"` // More numbers of the same contact
+	Organization  string   `json:"organization,omitempty" form:"organization"`
+	// Contacts sends several cards in one message instead of the contact above
+	Contacts []ContactCard `json:"contacts,omitempty" form:"contacts"`
+}
+
+type ContactCard struct {
+	ContactName   string   `json:"contact_name"`
+	ContactPhone  string   `json:"contact_phone"`
+	ContactPhones []string `json:"contact_phones,omitempty"`
+	Organization  string   `json:"organization,omitempty"`
 }
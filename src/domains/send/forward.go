@@ -0,0 +1,17 @@
+package send
+
+// ForwardRequest forwards a stored message to one or more chats.
+type ForwardRequest struct {
+	MessageID string   `json:"message_id" uri:"message_id"`
+	ToPhone   string   `json:"to_phone" form:"to_phone"`
+	ToPhones  []string `json:"to_phones" form:"to_phones"` // More recipients, each with its own result
+}
+
+// Recipients returns to_phone followed by to_phones.
+func (request ForwardRequest) Recipients() []string {
+	var recipients []string
+	if request.ToPhone != "" {
+		recipients = append(recipients, request.ToPhone)
+	}
+	return append(recipients, request.ToPhones...)
+}
@@ -7,6 +7,8 @@ import (
 // ITextSender handles text message sending operations
 type ITextSender interface {
 	SendText(ctx context.Context, request MessageRequest) (response GenericResponse, err error)
+	SendBulk(ctx context.Context, request BulkMessageRequest) (response BulkResponse, err error)
+	ForwardMessage(ctx context.Context, request ForwardRequest) (response BulkResponse, err error)
 }
 
 // IMediaSender handles media message sending operations
@@ -16,6 +18,7 @@ type IMediaSender interface {
 	SendVideo(ctx context.Context, request VideoRequest) (response GenericResponse, err error)
 	SendAudio(ctx context.Context, request AudioRequest) (response GenericResponse, err error)
 	SendSticker(ctx context.Context, request StickerRequest) (response GenericResponse, err error)
+	SendStatus(ctx context.Context, request StatusRequest) (response GenericResponse, err error)
 }
 
 // IInteractionSender handles interaction message sending operations
@@ -23,6 +26,7 @@ type IInteractionSender interface {
 	SendContact(ctx context.Context, request ContactRequest) (response GenericResponse, err error)
 	SendLink(ctx context.Context, request LinkRequest) (response GenericResponse, err error)
 	SendLocation(ctx context.Context, request LocationRequest) (response GenericResponse, err error)
+	SendLiveLocation(ctx context.Context, request LiveLocationRequest) (response GenericResponse, err error)
 	SendPoll(ctx context.Context, request PollRequest) (response GenericResponse, err error)
 }
 
@@ -32,10 +36,22 @@ type IPresenceSender interface {
 	SendChatPresence(ctx context.Context, request ChatPresenceRequest) (response GenericResponse, err error)
 }
 
+// IJobReader reports the progress of queued messages
+type IJobReader interface {
+	GetJob(ctx context.Context, jobID string) (job Job, err error)
+}
+
+// IDrainer waits for queued messages when shutting down
+type IDrainer interface {
+	Drain(ctx context.Context) error
+}
+
 // ISendUsecase combines all sender interfaces for backward compatibility
 type ISendUsecase interface {
 	ITextSender
 	IMediaSender
 	IInteractionSender
 	IPresenceSender
+	IJobReader
+	IDrainer
 }
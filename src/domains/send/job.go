@@ -0,0 +1,25 @@
+package send
+
+import "time"
+
+// Send job states reported by GET /send/jobs/:job_id.
+const (
+	JobStatusQueued = "queued"
+	JobStatusSent   = "sent"
+	JobStatusFailed = "failed"
+)
+
+// Job is a message sent through the outbound queue, or a whole bulk send.
+// MessageID is assigned when the job is queued and kept across retries.
+type Job struct {
+	ID        string     `json:"id"`
+	Status    string     `json:"status"`
+	Recipient string     `json:"recipient,omitempty"`
+	MessageID string     `json:"message_id,omitempty"`
+	Attempts  int        `json:"attempts"`
+	Error     string     `json:"error,omitempty"`
+	QueuedAt  time.Time  `json:"queued_at"`
+	SentAt    *time.Time `json:"sent_at,omitempty"`
+	// Results of a bulk send, updated as the batch goes
+	Results []BulkResult `json:"results,omitempty"`
+}
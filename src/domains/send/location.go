@@ -4,4 +4,15 @@ type LocationRequest struct {
 	BaseRequest
 	Latitude  string `json:"latitude" form:"latitude"`
 	Longitude string `json:"longitude" form:"longitude"`
+	Name      string `json:"name,omitempty" form:"name"`
+	Address   string `json:"address,omitempty" form:"address"`
+}
+
+type LiveLocationRequest struct {
+	BaseRequest
+	Latitude         string `json:"latitude" form:"latitude"`
+	Longitude        string `json:"longitude" form:"longitude"`
+	Caption          string `json:"caption,omitempty" form:"caption"`
+	AccuracyInMeters int    `json:"accuracy_in_meters,omitempty" form:"accuracy_in_meters"`
+	LiveDuration     int    `json:"live_duration" form:"live_duration"` // Seconds the location is shared for
 }
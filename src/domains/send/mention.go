@@ -0,0 +1,8 @@
+package send
+
+// MentionRequest holds who a text or caption mentions, on top of the phone
+// numbers written as @628123456789 in it.
+type MentionRequest struct {
+	Mentions   []string `json:"mentions,omitempty" form:"mentions"`       // List of phone numbers/JIDs to mention (ghost mentions); "@everyone" works like MentionAll
+	MentionAll bool     `json:"mention_all,omitempty" form:"mention_all"` // Mention every participant of the group
+}
@@ -5,4 +5,6 @@ type PollRequest struct {
 	Question  string   `json:"question" form:"question"`
 	Options   []string `json:"options" form:"options"`
 	MaxAnswer int      `json:"max_answer" form:"max_answer"`
+	// MaxAnswers is an alias of MaxAnswer, used when MaxAnswer is not set
+	MaxAnswers int `json:"max_answers,omitempty" form:"max_answers"`
 }
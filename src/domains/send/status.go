@@ -0,0 +1,33 @@
+package send
+
+import "mime/multipart"
+
+// Status types accepted by StatusRequest.Type
+const (
+	StatusTypeText  = "text"
+	StatusTypeImage = "image"
+	StatusTypeVideo = "video"
+)
+
+// StatusFonts are the fonts a text status can be written in
+var StatusFonts = []string{
+	"system", "system_text", "fb_script", "system_bold",
+	"morningbreeze_regular", "calistoga_regular", "exo2_extrabold", "courierprime_bold",
+}
+
+// StatusRequest posts a status (story). It is shown to the contacts the
+// account's status privacy settings allow.
+type StatusRequest struct {
+	Type string `json:"type" form:"type"`
+	// Text is the text of a text status
+	Text string `json:"text" form:"text"`
+	// BackgroundColor of a text status as #RRGGBB, dark green when empty
+	BackgroundColor string `json:"background_color" form:"background_color"`
+	// Font of a text status, one of StatusFonts
+	Font     string                `json:"font" form:"font"`
+	Caption  string                `json:"caption" form:"caption"`
+	Image    *multipart.FileHeader `json:"image" form:"image"`
+	ImageURL *string               `json:"image_url" form:"image_url"`
+	Video    *multipart.FileHeader `json:"video" form:"video"`
+	VideoURL *string               `json:"video_url" form:"video_url"`
+}
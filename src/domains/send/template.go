@@ -0,0 +1,11 @@
+package send
+
+// TemplateRequest renders the text of a send from a stored template instead
+// of taking it from message.
+type TemplateRequest struct {
+	TemplateName string            `json:"template_name,omitempty" form:"template_name"`
+	Variables    map[string]string `json:"variables,omitempty" form:"-"`
+	// TemplateStrict refuses to send when a placeholder has no variable,
+	// instead of rendering it empty.
+	TemplateStrict bool `json:"template_strict,omitempty" form:"template_strict"`
+}
@@ -2,7 +2,10 @@ package send
 
 type MessageRequest struct {
 	BaseRequest
-	Message        string   `json:"message" form:"message"`
-	ReplyMessageID *string  `json:"reply_message_id" form:"reply_message_id"`
-	Mentions       []string `json:"mentions,omitempty" form:"mentions"` // List of phone numbers/JIDs to mention (ghost mentions)
+	Message string `json:"message" form:"message"`
+	// SimulateTyping shows the typing indicator for a time proportional to
+	// the length of the message before sending it.
+	SimulateTyping bool `json:"simulate_typing,omitempty" form:"simulate_typing"`
+	MentionRequest
+	TemplateRequest
 }
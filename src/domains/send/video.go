@@ -9,4 +9,7 @@ type VideoRequest struct {
 	ViewOnce bool                  `json:"view_once" form:"view_once"`
 	Compress bool                  `json:"compress"`
 	VideoURL *string               `json:"video_url" form:"video_url"`
+	// GIFPlayback sends a short video as a looping, muted GIF
+	GIFPlayback bool `json:"gif_playback" form:"gif_playback"`
+	MentionRequest
 }
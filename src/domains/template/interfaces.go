@@ -0,0 +1,13 @@
+package template
+
+import "context"
+
+// ITemplateUsecase manages the message templates of the device in the
+// context.
+type ITemplateUsecase interface {
+	CreateTemplate(ctx context.Context, request CreateTemplateRequest) (Template, error)
+	ListTemplates(ctx context.Context) ([]Template, error)
+	GetTemplate(ctx context.Context, name string) (Template, error)
+	UpdateTemplate(ctx context.Context, request UpdateTemplateRequest) (Template, error)
+	DeleteTemplate(ctx context.Context, name string) error
+}
@@ -0,0 +1,28 @@
+package template
+
+import "time"
+
+// Template is a text message body with {{.name}} placeholders. Send
+// requests name it in template_name and fill it in with their variables.
+type Template struct {
+	Name string `json:"name"`
+	Body string `json:"body"`
+	// Variables are the placeholder names of Body in order of first use
+	Variables []string  `json:"variables"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateTemplateRequest adds a template to the device of the request. Names
+// are unique per device and are what sent messages record, so they can't be
+// changed later.
+type CreateTemplateRequest struct {
+	Name string `json:"name"`
+	Body string `json:"body"`
+}
+
+// UpdateTemplateRequest replaces the body of a template.
+type UpdateTemplateRequest struct {
+	Name string `json:"-"`
+	Body string `json:"body"`
+}
@@ -1,13 +1,17 @@
 package user
 
 import (
+	"io"
 	"mime/multipart"
 
 	"go.mau.fi/whatsmeow/types"
 )
 
+// InfoRequest asks for a contact's profile. Preview returns the low
+// resolution variant of their picture.
 type InfoRequest struct {
-	Phone string `json:"phone" query:"phone"`
+	Phone   string `json:"phone" query:"phone"`
+	Preview bool   `json:"preview" query:"preview"`
 }
 
 type InfoResponseDataDevice struct {
@@ -18,11 +22,26 @@ type InfoResponseDataDevice struct {
 	AD     string
 }
 
+// InfoResponseData is a contact's profile. Status is their "about" text.
+// Picture is nil when they have none or hide it from this account, and
+// Business is only set for business accounts.
 type InfoResponseData struct {
 	VerifiedName string                   `json:"verified_name"`
 	Status       string                   `json:"status"`
 	PictureID    string                   `json:"picture_id"`
+	Picture      *AvatarResponse          `json:"picture"`
+	DeviceCount  int                      `json:"device_count"`
 	Devices      []InfoResponseDataDevice `json:"devices"`
+	Business     *InfoBusiness            `json:"business,omitempty"`
+}
+
+// InfoBusiness is the public part of a business profile.
+type InfoBusiness struct {
+	Category    string   `json:"category,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Websites    []string `json:"websites,omitempty"`
+	Email       string   `json:"email,omitempty"`
+	Address     string   `json:"address,omitempty"`
 }
 
 type InfoResponse struct {
@@ -43,6 +62,14 @@ type AvatarResponse struct {
 	Type string `json:"type"`
 }
 
+// AvatarImage is a profile picture fetched from the WhatsApp CDN. The
+// caller closes Body.
+type AvatarImage struct {
+	Body        io.ReadCloser
+	ContentType string
+	Size        int64
+}
+
 type MyPrivacySettingResponse struct {
 	GroupAdd     string `json:"group_add"`
 	LastSeen     string `json:"last_seen"`
@@ -51,6 +78,24 @@ type MyPrivacySettingResponse struct {
 	ReadReceipts string `json:"read_receipts"`
 }
 
+// PrivacySettings are the privacy settings of the account. Audiences are
+// everyone, contacts, contact_blacklist (contacts except some) or none; online
+// is everyone or match_last_seen and read_receipts everyone or none.
+type PrivacySettings struct {
+	LastSeen     string `json:"last_seen" form:"last_seen"`
+	ProfilePhoto string `json:"profile_photo" form:"profile_photo"`
+	Status       string `json:"status" form:"status"`
+	ReadReceipts string `json:"read_receipts" form:"read_receipts"`
+	GroupsAdd    string `json:"groups_add" form:"groups_add"`
+	Online       string `json:"online" form:"online"`
+}
+
+// SetPrivacyRequest changes the settings that are set and leaves the empty
+// ones as they are.
+type SetPrivacyRequest struct {
+	PrivacySettings
+}
+
 type MyListGroupsResponse struct {
 	Data []types.GroupInfo `json:"data"`
 }
@@ -63,6 +108,17 @@ type ChangeAvatarRequest struct {
 	Avatar *multipart.FileHeader `json:"avatar" form:"avatar"`
 }
 
+// ChangeAvatarResponse has the ID of the new profile picture.
+type ChangeAvatarResponse struct {
+	PictureID string `json:"picture_id"`
+}
+
+// ChangeAboutRequest sets the about text of the profile, at most 139
+// characters.
+type ChangeAboutRequest struct {
+	About string `json:"about" form:"about"`
+}
+
 type MyListContactsResponse struct {
 	Data []MyListContactsResponseData `json:"data"`
 }
@@ -76,6 +132,12 @@ type ChangePushNameRequest struct {
 	PushName string `json:"push_name" form:"push_name"`
 }
 
+// ChangePresenceRequest marks the account as online (available) or offline
+// (unavailable) for all contacts.
+type ChangePresenceRequest struct {
+	State string `json:"state" form:"state"`
+}
+
 type CheckRequest struct {
 	Phone string `json:"phone" query:"phone"`
 }
@@ -84,6 +146,37 @@ type CheckResponse struct {
 	IsOnWhatsApp bool `json:"is_on_whatsapp"`
 }
 
+// CheckPhonesRequest checks up to 500 phone numbers at once. Numbers may be
+// typed with +, spaces or dashes; local ones starting with 0 get the default
+// country code.
+type CheckPhonesRequest struct {
+	Phones []string `json:"phones" form:"phones"`
+}
+
+// CheckPhoneResult is the check of one number. NormalizedJID is empty and
+// Error set when Input isn't a phone number.
+type CheckPhoneResult struct {
+	Input         string `json:"input"`
+	NormalizedJID string `json:"normalized_jid"`
+	Registered    bool   `json:"registered"`
+	Error         string `json:"error,omitempty"`
+}
+
+type CheckPhonesResponse struct {
+	Data       []CheckPhoneResult `json:"data"`
+	Registered int                `json:"registered"`
+}
+
+// BlockRequest blocks or unblocks a contact by phone number.
+type BlockRequest struct {
+	Phone string `json:"phone" form:"phone"`
+}
+
+// BlocklistResponse lists the JIDs the account has blocked.
+type BlocklistResponse struct {
+	Data []string `json:"data"`
+}
+
 type BusinessProfileRequest struct {
 	Phone string `json:"phone" query:"phone"`
 }
@@ -8,14 +8,19 @@ import (
 type IUserInfo interface {
 	Info(ctx context.Context, request InfoRequest) (response InfoResponse, err error)
 	IsOnWhatsApp(ctx context.Context, request CheckRequest) (response CheckResponse, err error)
+	CheckPhones(ctx context.Context, request CheckPhonesRequest) (response CheckPhonesResponse, err error)
 	BusinessProfile(ctx context.Context, request BusinessProfileRequest) (response BusinessProfileResponse, err error)
 }
 
 // IUserProfile handles user profile operations
 type IUserProfile interface {
 	Avatar(ctx context.Context, request AvatarRequest) (response AvatarResponse, err error)
-	ChangeAvatar(ctx context.Context, request ChangeAvatarRequest) (err error)
+	AvatarImage(ctx context.Context, request AvatarRequest) (response AvatarImage, err error)
+	ChangeAvatar(ctx context.Context, request ChangeAvatarRequest) (response ChangeAvatarResponse, err error)
+	RemoveAvatar(ctx context.Context) (err error)
+	ChangeAbout(ctx context.Context, request ChangeAboutRequest) (err error)
 	ChangePushName(ctx context.Context, request ChangePushNameRequest) (err error)
+	ChangePresence(ctx context.Context, request ChangePresenceRequest) (err error)
 }
 
 // IUserListing handles user listing operations
@@ -28,6 +33,15 @@ type IUserListing interface {
 // IUserPrivacy handles user privacy operations
 type IUserPrivacy interface {
 	MyPrivacySetting(ctx context.Context) (response MyPrivacySettingResponse, err error)
+	Privacy(ctx context.Context) (response PrivacySettings, err error)
+	SetPrivacy(ctx context.Context, request SetPrivacyRequest) (response PrivacySettings, err error)
+}
+
+// IUserBlocklist handles blocking and unblocking contacts
+type IUserBlocklist interface {
+	Block(ctx context.Context, request BlockRequest) (response BlocklistResponse, err error)
+	Unblock(ctx context.Context, request BlockRequest) (response BlocklistResponse, err error)
+	Blocklist(ctx context.Context) (response BlocklistResponse, err error)
 }
 
 // IUserUsecase combines all user interfaces for backward compatibility
@@ -36,4 +50,5 @@ type IUserUsecase interface {
 	IUserProfile
 	IUserListing
 	IUserPrivacy
+	IUserBlocklist
 }
@@ -0,0 +1,419 @@
+package chatstorage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// columnKind is how a column is scanned from and written to every dialect.
+type columnKind int
+
+const (
+	kindText columnKind = iota
+	kindInt
+	kindBool
+	kindTime
+	kindBlob
+)
+
+type backupColumn struct {
+	name string
+	kind columnKind
+}
+
+// backupTable describes a table as exported to backups. Rows are exported as
+// JSON objects keyed by column name so a backup can be restored into any
+// dialect, and into a schema that gained columns since.
+type backupTable struct {
+	name    string
+	key     string // Primary key columns, for the upsert
+	columns []backupColumn
+}
+
+// hasDevice reports whether rows belong to a device through device_id.
+func (t backupTable) hasDevice() bool {
+	for _, column := range t.columns {
+		if column.name == "device_id" {
+			return true
+		}
+	}
+	return false
+}
+
+func (t backupTable) column(name string) (backupColumn, bool) {
+	for _, column := range t.columns {
+		if column.name == name {
+			return column, true
+		}
+	}
+	return backupColumn{}, false
+}
+
+func text(name string) backupColumn    { return backupColumn{name, kindText} }
+func integer(name string) backupColumn { return backupColumn{name, kindInt} }
+func boolean(name string) backupColumn { return backupColumn{name, kindBool} }
+func stamp(name string) backupColumn   { return backupColumn{name, kindTime} }
+func blob(name string) backupColumn    { return backupColumn{name, kindBlob} }
+
+// backupTables are the tables a backup holds, in restore order. New tables
+// and columns must be added here too, which a test checks.
+var backupTables = []backupTable{
+	{name: "devices", key: "device_id", columns: []backupColumn{
+		text("device_id"), text("display_name"), text("jid"), stamp("created_at"), stamp("updated_at"),
+		boolean("auto_reject_calls"), text("auto_reject_call_message"), text("webhook_url"), text("webhook_secret"),
+		text("webhook_events"), text("auto_reply_message"), boolean("auto_mark_read"),
+	}},
+	{name: "chats", key: "jid, device_id", columns: []backupColumn{
+		text("jid"), text("device_id"), text("name"), stamp("last_message_time"), integer("ephemeral_expiration"),
+		stamp("created_at"), stamp("updated_at"), boolean("archived"), boolean("pinned"), stamp("muted_until"),
+		integer("unread_count"), stamp("left_at"), text("chat_type"), boolean("blocked"),
+	}},
+	{name: "messages", key: "id, chat_jid, device_id", columns: []backupColumn{
+		text("id"), text("chat_jid"), text("device_id"), text("sender"), text("content"), stamp("timestamp"),
+		boolean("is_from_me"), text("media_type"), text("filename"), text("url"), blob("media_key"),
+		blob("file_sha256"), blob("file_enc_sha256"), integer("file_length"), stamp("created_at"), stamp("updated_at"),
+		stamp("edited_at"), boolean("is_deleted"), text("reply_to_id"), text("reply_to_sender"), text("location"),
+		text("contacts"), text("media_path"), stamp("downloaded_at"), boolean("is_animated"), boolean("is_view_once"),
+		integer("server_id"), stamp("pinned_until"), boolean("is_unread"), text("request_id"), text("template_name"),
+	}},
+	{name: "message_edits", key: "message_id, chat_jid, device_id, edited_at", columns: []backupColumn{
+		text("message_id"), text("chat_jid"), text("device_id"), text("content"), stamp("edited_at"),
+	}},
+	{name: "reactions", key: "message_id, chat_jid, device_id, sender", columns: []backupColumn{
+		text("message_id"), text("chat_jid"), text("device_id"), text("sender"), text("emoji"), stamp("timestamp"),
+	}},
+	{name: "receipts", key: "message_id, chat_jid, device_id, recipient, type", columns: []backupColumn{
+		text("message_id"), text("chat_jid"), text("device_id"), text("recipient"), text("type"), stamp("timestamp"),
+	}},
+	{name: "polls", key: "message_id, chat_jid, device_id", columns: []backupColumn{
+		text("message_id"), text("chat_jid"), text("device_id"), text("question"), text("options"),
+		integer("selectable_count"),
+	}},
+	{name: "poll_votes", key: "message_id, chat_jid, device_id, voter", columns: []backupColumn{
+		text("message_id"), text("chat_jid"), text("device_id"), text("voter"), text("selected_options"),
+		stamp("timestamp"),
+	}},
+	{name: "calls", key: "call_id, device_id", columns: []backupColumn{
+		text("device_id"), text("call_id"), text("from_jid"), stamp("timestamp"), boolean("is_video"),
+		text("outcome"), integer("duration"), stamp("accepted_at"),
+	}},
+	{name: "contacts", key: "jid, device_id", columns: []backupColumn{
+		text("device_id"), text("jid"), text("full_name"), text("first_name"), text("push_name"),
+		text("business_name"), stamp("updated_at"),
+	}},
+	{name: "group_participants", key: "group_jid, device_id, participant_jid", columns: []backupColumn{
+		text("device_id"), text("group_jid"), text("participant_jid"), boolean("is_admin"), boolean("is_superadmin"),
+		stamp("joined_at"),
+	}},
+	{name: "lid_mappings", key: "lid, device_id", columns: []backupColumn{
+		text("device_id"), text("lid"), text("pn_jid"), stamp("updated_at"),
+	}},
+	{name: "api_keys", key: "id", columns: []backupColumn{
+		text("id"), text("key_hash"), text("label"), text("scopes"), text("device_ids"), stamp("created_at"),
+		stamp("last_used_at"), boolean("revoked"),
+	}},
+	{name: "auto_reply_rules", key: "id", columns: []backupColumn{
+		text("id"), text("device_id"), integer("position"), text("pattern"), text("match_type"), text("reply_text"),
+		boolean("only_groups"), boolean("only_dm"), boolean("enabled"), stamp("created_at"), stamp("updated_at"),
+	}},
+	{name: "labels", key: "id", columns: []backupColumn{
+		text("id"), text("device_id"), text("name"), text("color"), stamp("created_at"), stamp("updated_at"),
+	}},
+	{name: "chat_labels", key: "chat_jid, device_id, label_id", columns: []backupColumn{
+		text("device_id"), text("chat_jid"), text("label_id"), stamp("created_at"),
+	}},
+	{name: "templates", key: "name, device_id", columns: []backupColumn{
+		text("device_id"), text("name"), text("body"), stamp("created_at"), stamp("updated_at"),
+	}},
+	{name: "jobs", key: "id", columns: []backupColumn{
+		text("id"), text("device_id"), text("type"), text("status"), integer("progress"), text("result"),
+		text("error"), stamp("created_at"), stamp("updated_at"),
+	}},
+}
+
+// BackupTables lists the tables ExportTable and ImportTable accept, in the
+// order they should be restored.
+func BackupTables() []string {
+	names := make([]string, len(backupTables))
+	for i, table := range backupTables {
+		names[i] = table.name
+	}
+	return names
+}
+
+func findBackupTable(name string) (backupTable, error) {
+	for _, table := range backupTables {
+		if table.name == name {
+			return table, nil
+		}
+	}
+	return backupTable{}, fmt.Errorf("unknown backup table %q", name)
+}
+
+// IsDeviceTable reports whether the rows of a backup table belong to a device.
+// Tables that don't are left out of device backups and restores.
+func IsDeviceTable(name string) bool {
+	table, err := findBackupTable(name)
+	return err == nil && table.hasDevice()
+}
+
+// ExportTable calls fn with each row of table as a JSON object, ordered by
+// primary key. Times are written in UTC as RFC 3339 and blobs as base64. A
+// non-empty deviceID only exports that device's rows.
+func (r *SQLRepository) ExportTable(ctx context.Context, tableName, deviceID string, fn func(row []byte) error) error {
+	table, err := findBackupTable(tableName)
+	if err != nil {
+		return err
+	}
+	if deviceID != "" && !table.hasDevice() {
+		return nil
+	}
+
+	names := make([]string, len(table.columns))
+	for i, column := range table.columns {
+		names[i] = column.name
+	}
+	query := "SELECT " + strings.Join(names, ", ") + " FROM " + table.name
+	var args []any
+	if deviceID != "" {
+		query += " WHERE device_id = ?"
+		args = append(args, deviceID)
+	}
+	query += " ORDER BY " + table.key
+
+	rows, err := r.db.QueryContext(ctx, r.p(query), args...)
+	if err != nil {
+		return fmt.Errorf("failed to export %s: %w", table.name, err)
+	}
+	defer rows.Close()
+
+	dest := make([]any, len(table.columns))
+	for i, column := range table.columns {
+		dest[i] = scanTarget(column.kind)
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("failed to export %s: %w", table.name, err)
+		}
+		row := make(map[string]any, len(table.columns))
+		for i, column := range table.columns {
+			row[column.name] = exportValue(dest[i])
+		}
+		encoded, err := json.Marshal(row)
+		if err != nil {
+			return fmt.Errorf("failed to export %s: %w", table.name, err)
+		}
+		if err := fn(encoded); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// CountTable counts the rows of a backup table.
+func (r *SQLRepository) CountTable(ctx context.Context, tableName string) (int64, error) {
+	table, err := findBackupTable(tableName)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table.name).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count %s: %w", table.name, err)
+	}
+	return count, nil
+}
+
+func scanTarget(kind columnKind) any {
+	switch kind {
+	case kindInt:
+		return new(sql.NullInt64)
+	case kindBool:
+		return new(sql.NullBool)
+	case kindTime:
+		return new(sql.NullTime)
+	case kindBlob:
+		return new([]byte)
+	}
+	return new(sql.NullString)
+}
+
+func exportValue(scanned any) any {
+	switch v := scanned.(type) {
+	case *sql.NullString:
+		if v.Valid {
+			return v.String
+		}
+	case *sql.NullInt64:
+		if v.Valid {
+			return v.Int64
+		}
+	case *sql.NullBool:
+		if v.Valid {
+			return v.Bool
+		}
+	case *sql.NullTime:
+		if v.Valid {
+			return v.Time.UTC().Format(time.RFC3339Nano)
+		}
+	case *[]byte:
+		if *v != nil {
+			return *v // Marshalled as base64
+		}
+	}
+	return nil
+}
+
+// ImportTable upserts the rows next returns into table, in one transaction,
+// until next returns io.EOF. Rows are JSON objects as ExportTable writes them;
+// columns a row lacks take their default and unknown columns fail the import.
+// A non-empty deviceID only imports that device's rows. It returns the number
+// of rows imported.
+func (r *SQLRepository) ImportTable(ctx context.Context, tableName, deviceID string, next func() ([]byte, error)) (int64, error) {
+	table, err := findBackupTable(tableName)
+	if err != nil {
+		return 0, err
+	}
+	if deviceID != "" && !table.hasDevice() {
+		return 0, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var imported int64
+	for {
+		line, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return imported, err
+		}
+
+		var row map[string]json.RawMessage
+		if err := json.Unmarshal(line, &row); err != nil {
+			return imported, fmt.Errorf("invalid %s row %d: %w", table.name, imported+1, err)
+		}
+		columns, values, err := importValues(table, row)
+		if err != nil {
+			return imported, fmt.Errorf("invalid %s row %d: %w", table.name, imported+1, err)
+		}
+		if deviceID != "" && rowDeviceID(columns, values) != deviceID {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, r.p(r.upsertQuery(table, columns)), values...); err != nil {
+			return imported, fmt.Errorf("failed to import %s: %w", table.name, err)
+		}
+		imported++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit %s: %w", table.name, err)
+	}
+	return imported, nil
+}
+
+func rowDeviceID(columns []string, values []any) string {
+	for i, column := range columns {
+		if column == "device_id" {
+			deviceID, _ := values[i].(string)
+			return deviceID
+		}
+	}
+	return ""
+}
+
+// importValues decodes row into the columns it sets and their values, in
+// table order.
+func importValues(table backupTable, row map[string]json.RawMessage) ([]string, []any, error) {
+	for name := range row {
+		if _, ok := table.column(name); !ok {
+			return nil, nil, fmt.Errorf("unknown column %q", name)
+		}
+	}
+
+	var columns []string
+	var values []any
+	for _, column := range table.columns {
+		raw, ok := row[column.name]
+		if !ok {
+			continue
+		}
+		value, err := importValue(column.kind, raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("column %s: %w", column.name, err)
+		}
+		columns = append(columns, column.name)
+		values = append(values, value)
+	}
+	if len(columns) == 0 {
+		return nil, nil, errors.New("no columns")
+	}
+	return columns, values, nil
+}
+
+func importValue(kind columnKind, raw json.RawMessage) (any, error) {
+	if string(raw) == "null" {
+		return nil, nil
+	}
+	switch kind {
+	case kindInt:
+		var v int64
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	case kindBool:
+		var v bool
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	case kindTime:
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, err
+		}
+		return time.Parse(time.RFC3339Nano, v)
+	case kindBlob:
+		var v []byte
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	}
+	var v string
+	err := json.Unmarshal(raw, &v)
+	return v, err
+}
+
+// upsertQuery inserts columns into table, overwriting the row with the same
+// primary key.
+func (r *SQLRepository) upsertQuery(table backupTable, columns []string) string {
+	key := make(map[string]bool)
+	for _, column := range strings.Split(table.key, ", ") {
+		key[column] = true
+	}
+
+	var updates []string
+	for _, column := range columns {
+		if !key[column] {
+			updates = append(updates, column+" = "+r.excluded(column))
+		}
+	}
+
+	query := "INSERT INTO " + table.name + " (" + strings.Join(columns, ", ") + ") VALUES (" +
+		strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	if len(updates) == 0 {
+		// Rows holding only their key have nothing to overwrite
+		if r.dialect == dialectMySQL {
+			return "INSERT IGNORE" + strings.TrimPrefix(query, "INSERT")
+		}
+		return query + " ON CONFLICT (" + table.key + ") DO NOTHING"
+	}
+	return query + " " + r.onConflictUpdate(table.key) + " " + strings.Join(updates, ", ")
+}
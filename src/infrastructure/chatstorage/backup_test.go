@@ -0,0 +1,167 @@
+package chatstorage
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupTablesMatchSchema(t *testing.T) {
+	repo := newSQLiteRepository(t)
+
+	rows, err := repo.db.Query(`SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name != 'schema_info' ORDER BY name`)
+	require.NoError(t, err)
+	var tables []string
+	for rows.Next() {
+		var name string
+		require.NoError(t, rows.Scan(&name))
+		tables = append(tables, name)
+	}
+	require.NoError(t, rows.Close())
+	assert.ElementsMatch(t, tables, BackupTables(), "every table must be backed up")
+
+	for _, table := range backupTables {
+		rows, err := repo.db.Query(`SELECT name FROM pragma_table_info(?)`, table.name)
+		require.NoError(t, err)
+		var columns []string
+		for rows.Next() {
+			var name string
+			require.NoError(t, rows.Scan(&name))
+			columns = append(columns, name)
+		}
+		require.NoError(t, rows.Close())
+
+		var backedUp []string
+		for _, column := range table.columns {
+			backedUp = append(backedUp, column.name)
+		}
+		assert.ElementsMatch(t, columns, backedUp, "columns of %s", table.name)
+	}
+}
+
+func exportAll(t *testing.T, repo *SQLRepository, deviceID string) map[string][][]byte {
+	t.Helper()
+	exported := make(map[string][][]byte)
+	for _, table := range BackupTables() {
+		require.NoError(t, repo.ExportTable(context.Background(), table, deviceID, func(row []byte) error {
+			exported[table] = append(exported[table], row)
+			return nil
+		}))
+	}
+	return exported
+}
+
+func importAll(t *testing.T, repo *SQLRepository, deviceID string, exported map[string][][]byte) map[string]int64 {
+	t.Helper()
+	imported := make(map[string]int64)
+	for _, table := range BackupTables() {
+		rows := exported[table]
+		n, err := repo.ImportTable(context.Background(), table, deviceID, func() ([]byte, error) {
+			if len(rows) == 0 {
+				return nil, io.EOF
+			}
+			row := rows[0]
+			rows = rows[1:]
+			return row, nil
+		})
+		require.NoError(t, err)
+		imported[table] = n
+	}
+	return imported
+}
+
+func TestExportImportTable_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source := newSQLiteRepository(t)
+	base := time.Date(2024, 5, 1, 10, 0, 0, 123456000, time.UTC)
+
+	for _, device := range []string{"dev-1", "dev-2"} {
+		require.NoError(t, source.SaveDeviceRecord(ctx, &domainChatStorage.DeviceRecord{DeviceID: device, DisplayName: "Phone " + device}))
+		require.NoError(t, source.StoreChat(ctx, &domainChatStorage.Chat{JID: "628123@s.whatsapp.net", DeviceID: device, Name: "Bob", LastMessageTime: base}))
+	}
+	_, err := source.StoreMessagesBatch(ctx, []*domainChatStorage.Message{
+		{ID: "A", ChatJID: "628123@s.whatsapp.net", DeviceID: "dev-1", Content: "hi", Timestamp: base, IsFromMe: true},
+		{ID: "B", ChatJID: "628123@s.whatsapp.net", DeviceID: "dev-1", MediaType: "image", MediaKey: []byte{0, 1, 2, 255}, FileLength: 4, Timestamp: base.Add(time.Minute)},
+		{ID: "A", ChatJID: "628123@s.whatsapp.net", DeviceID: "dev-2", Content: "hi", Timestamp: base},
+	})
+	require.NoError(t, err)
+	require.NoError(t, source.StoreCall(ctx, &domainChatStorage.Call{DeviceID: "dev-2", CallID: "C1", FromJID: "628123@s.whatsapp.net", Timestamp: base, IsVideo: true}))
+	require.NoError(t, source.StoreAPIKey(ctx, &domainChatStorage.APIKey{ID: "key-1", KeyHash: "hash", Scopes: []string{"read"}, CreatedAt: base}))
+
+	exported := exportAll(t, source, "")
+	assert.Len(t, exported["messages"], 3)
+	assert.Contains(t, string(exported["messages"][2]), `"media_key":"AAEC/w=="`)
+	assert.Contains(t, string(exported["messages"][0]), `"timestamp":"2024-05-01T10:00:00.123456Z"`)
+
+	t.Run("restores every row", func(t *testing.T) {
+		target := newSQLiteRepository(t)
+		imported := importAll(t, target, "", exported)
+		assert.Equal(t, int64(2), imported["devices"])
+		assert.Equal(t, int64(3), imported["messages"])
+		assert.Equal(t, int64(1), imported["api_keys"])
+		assert.Equal(t, exported, exportAll(t, target, ""))
+
+		message, err := target.GetMessageByDevice(ctx, "dev-1", "B")
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0, 1, 2, 255}, message.MediaKey)
+	})
+
+	t.Run("restoring twice overwrites", func(t *testing.T) {
+		target := newSQLiteRepository(t)
+		importAll(t, target, "", exported)
+		importAll(t, target, "", exported)
+		assert.Equal(t, exported, exportAll(t, target, ""))
+	})
+
+	t.Run("device filter", func(t *testing.T) {
+		assert.Equal(t, exportAll(t, source, "dev-1"), func() map[string][][]byte {
+			target := newSQLiteRepository(t)
+			imported := importAll(t, target, "dev-1", exported)
+			assert.Equal(t, int64(2), imported["messages"])
+			assert.Zero(t, imported["calls"])
+			assert.Zero(t, imported["api_keys"], "API keys don't belong to a device")
+			return exportAll(t, target, "")
+		}())
+	})
+}
+
+func TestImportTable_RollsBackInvalidRows(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	rows := []string{
+		`{"jid":"1@s.whatsapp.net","device_id":"dev-1","name":"One"}`,
+		`{"jid":"2@s.whatsapp.net","device_id":"dev-1","colour":"red"}`,
+	}
+	_, err := repo.ImportTable(context.Background(), "chats", "", func() ([]byte, error) {
+		if len(rows) == 0 {
+			return nil, io.EOF
+		}
+		row := rows[0]
+		rows = rows[1:]
+		return []byte(row), nil
+	})
+	assert.ErrorContains(t, err, `unknown column "colour"`)
+
+	count, err := repo.CountChats(context.Background(), &domainChatStorage.ChatFilter{DeviceID: "dev-1"})
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestUpsertQuery(t *testing.T) {
+	table, err := findBackupTable("lid_mappings")
+	require.NoError(t, err)
+
+	mySQL := &SQLRepository{dialect: dialectMySQL}
+	assert.Equal(t, "INSERT INTO lid_mappings (device_id, lid, pn_jid) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE pn_jid = VALUES(pn_jid)",
+		mySQL.upsertQuery(table, []string{"device_id", "lid", "pn_jid"}))
+	assert.Equal(t, "INSERT IGNORE INTO lid_mappings (device_id, lid) VALUES (?, ?)",
+		mySQL.upsertQuery(table, []string{"device_id", "lid"}))
+
+	postgres := &SQLRepository{dialect: dialectPostgres}
+	assert.Equal(t, "INSERT INTO lid_mappings (device_id, lid) VALUES (?, ?) ON CONFLICT (lid, device_id) DO NOTHING",
+		postgres.upsertQuery(table, []string{"device_id", "lid"}))
+}
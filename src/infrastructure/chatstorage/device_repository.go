@@ -5,6 +5,7 @@ import (
 	"time"
 
 	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 )
@@ -38,86 +39,361 @@ func (r *DeviceRepository) CreateMessage(ctx context.Context, evt *events.Messag
 	return r.base.CreateMessage(ctx, evt)
 }
 
-func (r *DeviceRepository) StoreChat(chat *domainChatStorage.Chat) error {
-	return r.base.StoreChat(r.withDeviceChat(chat))
+func (r *DeviceRepository) StoreChat(ctx context.Context, chat *domainChatStorage.Chat) error {
+	return r.base.StoreChat(ctx, r.withDeviceChat(chat))
 }
 
-func (r *DeviceRepository) GetChat(jid string) (*domainChatStorage.Chat, error) {
-	return r.base.GetChatByDevice(r.deviceID, jid)
+func (r *DeviceRepository) GetChat(ctx context.Context, jid string) (*domainChatStorage.Chat, error) {
+	return r.base.GetChatByDevice(ctx, r.deviceID, jid)
 }
 
-func (r *DeviceRepository) GetChatByDevice(deviceID, jid string) (*domainChatStorage.Chat, error) {
-	return r.base.GetChatByDevice(deviceID, jid)
+func (r *DeviceRepository) GetChatByDevice(ctx context.Context, deviceID, jid string) (*domainChatStorage.Chat, error) {
+	return r.base.GetChatByDevice(ctx, deviceID, jid)
 }
 
-func (r *DeviceRepository) GetChats(filter *domainChatStorage.ChatFilter) ([]*domainChatStorage.Chat, error) {
+func (r *DeviceRepository) GetChats(ctx context.Context, filter *domainChatStorage.ChatFilter) ([]*domainChatStorage.Chat, error) {
 	if filter != nil && filter.DeviceID == "" {
 		filter.DeviceID = r.deviceID
 	}
-	return r.base.GetChats(filter)
+	return r.base.GetChats(ctx, filter)
 }
 
-func (r *DeviceRepository) DeleteChat(jid string) error {
-	return r.base.DeleteChatByDevice(r.deviceID, jid)
+func (r *DeviceRepository) CountChats(ctx context.Context, filter *domainChatStorage.ChatFilter) (int64, error) {
+	if filter != nil && filter.DeviceID == "" {
+		filter.DeviceID = r.deviceID
+	}
+	return r.base.CountChats(ctx, filter)
+}
+
+func (r *DeviceRepository) DeleteChat(ctx context.Context, jid string) error {
+	return r.base.DeleteChatByDevice(ctx, r.deviceID, jid)
+}
+
+func (r *DeviceRepository) DeleteChatByDevice(ctx context.Context, deviceID, jid string) error {
+	return r.base.DeleteChatByDevice(ctx, deviceID, jid)
+}
+
+func (r *DeviceRepository) UpdateChatFlags(ctx context.Context, deviceID, jid string, flags domainChatStorage.ChatFlags) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.UpdateChatFlags(ctx, deviceID, jid, flags)
+}
+
+func (r *DeviceRepository) SetBlockedChats(ctx context.Context, deviceID string, jids []string) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.SetBlockedChats(ctx, deviceID, jids)
+}
+
+func (r *DeviceRepository) MarkChatRead(ctx context.Context, deviceID, jid string) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.MarkChatRead(ctx, deviceID, jid)
+}
+
+func (r *DeviceRepository) MarkMessagesRead(ctx context.Context, deviceID, chatJID string, ids []string) (int, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.MarkMessagesRead(ctx, deviceID, chatJID, ids)
+}
+
+func (r *DeviceRepository) SetEphemeralExpiration(ctx context.Context, deviceID, jid string, expiration uint32) (uint32, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.SetEphemeralExpiration(ctx, deviceID, jid, expiration)
+}
+
+func (r *DeviceRepository) StoreMessage(ctx context.Context, message *domainChatStorage.Message) error {
+	return r.base.StoreMessage(ctx, message)
+}
+
+func (r *DeviceRepository) StoreMessagesBatch(ctx context.Context, messages []*domainChatStorage.Message) (int, error) {
+	return r.base.StoreMessagesBatch(ctx, messages)
 }
 
-func (r *DeviceRepository) DeleteChatByDevice(deviceID, jid string) error {
-	return r.base.DeleteChatByDevice(deviceID, jid)
+func (r *DeviceRepository) GetMessageByID(ctx context.Context, id string) (*domainChatStorage.Message, error) {
+	return r.base.GetMessageByID(ctx, id)
 }
 
-func (r *DeviceRepository) StoreMessage(message *domainChatStorage.Message) error {
-	return r.base.StoreMessage(message)
+func (r *DeviceRepository) GetMessageByDevice(ctx context.Context, deviceID, id string) (*domainChatStorage.Message, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.GetMessageByDevice(ctx, deviceID, id)
 }
 
-func (r *DeviceRepository) StoreMessagesBatch(messages []*domainChatStorage.Message) error {
-	return r.base.StoreMessagesBatch(messages)
+func (r *DeviceRepository) GetOldestMessage(ctx context.Context, deviceID, chatJID string) (*domainChatStorage.Message, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.GetOldestMessage(ctx, deviceID, chatJID)
 }
 
-func (r *DeviceRepository) GetMessageByID(id string) (*domainChatStorage.Message, error) {
-	return r.base.GetMessageByID(id)
+func (r *DeviceRepository) GetMessages(ctx context.Context, filter *domainChatStorage.MessageFilter) ([]*domainChatStorage.Message, error) {
+	if filter != nil && filter.DeviceID == "" {
+		filter.DeviceID = r.deviceID
+	}
+	return r.base.GetMessages(ctx, filter)
 }
 
-func (r *DeviceRepository) GetMessages(filter *domainChatStorage.MessageFilter) ([]*domainChatStorage.Message, error) {
+func (r *DeviceRepository) StreamMessages(ctx context.Context, filter *domainChatStorage.MessageFilter, fn func(*domainChatStorage.Message) error) error {
 	if filter != nil && filter.DeviceID == "" {
 		filter.DeviceID = r.deviceID
 	}
-	return r.base.GetMessages(filter)
+	return r.base.StreamMessages(ctx, filter, fn)
+}
+
+func (r *DeviceRepository) SearchMessages(ctx context.Context, filter *domainChatStorage.MessageFilter, searchText string) ([]*domainChatStorage.Message, error) {
+	if filter != nil && filter.DeviceID == "" {
+		filter.DeviceID = r.deviceID
+	}
+	return r.base.SearchMessages(ctx, filter, searchText)
+}
+
+func (r *DeviceRepository) SearchMessagesGlobal(ctx context.Context, deviceID, searchText string, limit, offset int) ([]*domainChatStorage.SearchResult, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.SearchMessagesGlobal(ctx, deviceID, searchText, limit, offset)
+}
+
+func (r *DeviceRepository) DeleteMessage(ctx context.Context, id, chatJID string) error {
+	return r.base.DeleteMessageByDevice(ctx, r.deviceID, id, chatJID)
+}
+
+func (r *DeviceRepository) DeleteMessageByDevice(ctx context.Context, deviceID, id, chatJID string) error {
+	return r.base.DeleteMessageByDevice(ctx, deviceID, id, chatJID)
+}
+
+func (r *DeviceRepository) StoreSentMessageWithContext(ctx context.Context, messageID string, senderJID string, recipientJID string, content string, timestamp time.Time, media *domainChatStorage.MediaInfo) error {
+	return r.base.StoreSentMessageWithContext(ctx, messageID, senderJID, recipientJID, content, timestamp, media)
+}
+
+func (r *DeviceRepository) StoreReaction(ctx context.Context, reaction *domainChatStorage.Reaction) error {
+	if reaction != nil && reaction.DeviceID == "" {
+		reaction.DeviceID = r.deviceID
+	}
+	return r.base.StoreReaction(ctx, reaction)
+}
+
+func (r *DeviceRepository) GetReactionsForMessage(ctx context.Context, deviceID, chatJID, messageID string) ([]*domainChatStorage.Reaction, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.GetReactionsForMessage(ctx, deviceID, chatJID, messageID)
+}
+
+func (r *DeviceRepository) StoreMessageEdit(ctx context.Context, deviceID, id, chatJID, content string, editedAt time.Time) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.StoreMessageEdit(ctx, deviceID, id, chatJID, content, editedAt)
+}
+
+func (r *DeviceRepository) GetMessageEditHistory(ctx context.Context, deviceID, id, chatJID string) ([]*domainChatStorage.MessageEdit, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.GetMessageEditHistory(ctx, deviceID, id, chatJID)
+}
+
+func (r *DeviceRepository) MarkMessageRevoked(ctx context.Context, deviceID, id, chatJID string, keepContent bool) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.MarkMessageRevoked(ctx, deviceID, id, chatJID, keepContent)
+}
+
+func (r *DeviceRepository) SetMessageMediaPath(ctx context.Context, deviceID, id, chatJID, mediaPath string, size int64, downloadedAt time.Time) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.SetMessageMediaPath(ctx, deviceID, id, chatJID, mediaPath, size, downloadedAt)
+}
+
+func (r *DeviceRepository) SetMessageMediaURL(ctx context.Context, deviceID, id, chatJID, url string) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.SetMessageMediaURL(ctx, deviceID, id, chatJID, url)
+}
+
+func (r *DeviceRepository) SetMessagePinnedUntil(ctx context.Context, deviceID, id, chatJID string, pinnedUntil *time.Time) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.SetMessagePinnedUntil(ctx, deviceID, id, chatJID, pinnedUntil)
+}
+
+func (r *DeviceRepository) GetPinnedMessages(ctx context.Context, deviceID, chatJID string, now time.Time) ([]*domainChatStorage.Message, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.GetPinnedMessages(ctx, deviceID, chatJID, now)
+}
+
+func (r *DeviceRepository) StoreReceipt(ctx context.Context, receipt *domainChatStorage.Receipt) error {
+	if receipt != nil && receipt.DeviceID == "" {
+		receipt.DeviceID = r.deviceID
+	}
+	return r.base.StoreReceipt(ctx, receipt)
 }
 
-func (r *DeviceRepository) SearchMessages(deviceID, chatJID, searchText string, limit int) ([]*domainChatStorage.Message, error) {
-	targetDeviceID := deviceID
-	if targetDeviceID == "" {
-		targetDeviceID = r.deviceID
+func (r *DeviceRepository) GetReceiptsForMessage(ctx context.Context, deviceID, chatJID, messageID string) ([]*domainChatStorage.Receipt, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
 	}
-	return r.base.SearchMessages(targetDeviceID, chatJID, searchText, limit)
+	return r.base.GetReceiptsForMessage(ctx, deviceID, chatJID, messageID)
 }
 
-func (r *DeviceRepository) DeleteMessage(id, chatJID string) error {
-	return r.base.DeleteMessageByDevice(r.deviceID, id, chatJID)
+func (r *DeviceRepository) StorePoll(ctx context.Context, poll *domainChatStorage.Poll) error {
+	if poll != nil && poll.DeviceID == "" {
+		poll.DeviceID = r.deviceID
+	}
+	return r.base.StorePoll(ctx, poll)
 }
 
-func (r *DeviceRepository) DeleteMessageByDevice(deviceID, id, chatJID string) error {
-	return r.base.DeleteMessageByDevice(deviceID, id, chatJID)
+func (r *DeviceRepository) GetPoll(ctx context.Context, deviceID, chatJID, messageID string) (*domainChatStorage.Poll, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.GetPoll(ctx, deviceID, chatJID, messageID)
 }
 
-func (r *DeviceRepository) StoreSentMessageWithContext(ctx context.Context, messageID string, senderJID string, recipientJID string, content string, timestamp time.Time) error {
-	return r.base.StoreSentMessageWithContext(ctx, messageID, senderJID, recipientJID, content, timestamp)
+func (r *DeviceRepository) StorePollVote(ctx context.Context, vote *domainChatStorage.PollVote) error {
+	if vote != nil && vote.DeviceID == "" {
+		vote.DeviceID = r.deviceID
+	}
+	return r.base.StorePollVote(ctx, vote)
 }
 
-func (r *DeviceRepository) GetChatMessageCount(chatJID string) (int64, error) {
-	return r.base.GetChatMessageCountByDevice(r.deviceID, chatJID)
+func (r *DeviceRepository) GetPollVotes(ctx context.Context, deviceID, chatJID, messageID string) ([]*domainChatStorage.PollVote, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.GetPollVotes(ctx, deviceID, chatJID, messageID)
 }
 
-func (r *DeviceRepository) GetChatMessageCountByDevice(deviceID, chatJID string) (int64, error) {
-	return r.base.GetChatMessageCountByDevice(deviceID, chatJID)
+func (r *DeviceRepository) StoreCall(ctx context.Context, call *domainChatStorage.Call) error {
+	if call != nil && call.DeviceID == "" {
+		call.DeviceID = r.deviceID
+	}
+	return r.base.StoreCall(ctx, call)
 }
 
-func (r *DeviceRepository) GetTotalMessageCount() (int64, error) {
-	return r.base.GetTotalMessageCount()
+func (r *DeviceRepository) UpdateCallOutcome(ctx context.Context, deviceID, callID, outcome string, at time.Time) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.UpdateCallOutcome(ctx, deviceID, callID, outcome, at)
 }
 
-func (r *DeviceRepository) GetTotalChatCount() (int64, error) {
-	return r.base.GetTotalChatCount()
+func (r *DeviceRepository) EndCall(ctx context.Context, deviceID, callID string, endedAt time.Time) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.EndCall(ctx, deviceID, callID, endedAt)
+}
+
+func (r *DeviceRepository) GetCalls(ctx context.Context, filter *domainChatStorage.CallFilter) ([]*domainChatStorage.Call, error) {
+	if filter != nil && filter.DeviceID == "" {
+		filter.DeviceID = r.deviceID
+	}
+	return r.base.GetCalls(ctx, filter)
+}
+
+func (r *DeviceRepository) StoreContacts(ctx context.Context, contacts []*domainChatStorage.Contact) (int, error) {
+	for _, contact := range contacts {
+		if contact != nil && contact.DeviceID == "" {
+			contact.DeviceID = r.deviceID
+		}
+	}
+	return r.base.StoreContacts(ctx, contacts)
+}
+
+func (r *DeviceRepository) GetContacts(ctx context.Context, filter *domainChatStorage.ContactFilter) ([]*domainChatStorage.Contact, error) {
+	if filter != nil && filter.DeviceID == "" {
+		filter.DeviceID = r.deviceID
+	}
+	return r.base.GetContacts(ctx, filter)
+}
+
+func (r *DeviceRepository) CountContacts(ctx context.Context, filter *domainChatStorage.ContactFilter) (int64, error) {
+	if filter != nil && filter.DeviceID == "" {
+		filter.DeviceID = r.deviceID
+	}
+	return r.base.CountContacts(ctx, filter)
+}
+
+func (r *DeviceRepository) SyncGroupParticipants(ctx context.Context, deviceID, groupJID string, participants []*domainChatStorage.GroupParticipant) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.SyncGroupParticipants(ctx, deviceID, groupJID, participants)
+}
+
+func (r *DeviceRepository) UpdateGroupParticipants(ctx context.Context, deviceID, groupJID string, change domainChatStorage.GroupParticipantChange) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.UpdateGroupParticipants(ctx, deviceID, groupJID, change)
+}
+
+func (r *DeviceRepository) GetGroupParticipants(ctx context.Context, deviceID, groupJID string) ([]*domainChatStorage.GroupParticipant, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.GetGroupParticipants(ctx, deviceID, groupJID)
+}
+
+func (r *DeviceRepository) MergeChats(ctx context.Context, deviceID, fromJID, toJID string) (int64, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.MergeChats(ctx, deviceID, fromJID, toJID)
+}
+
+func (r *DeviceRepository) StoreLIDMapping(ctx context.Context, deviceID, lid, pnJID string) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.StoreLIDMapping(ctx, deviceID, lid, pnJID)
+}
+
+func (r *DeviceRepository) GetPNForLID(ctx context.Context, deviceID, lid string) (string, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.GetPNForLID(ctx, deviceID, lid)
+}
+
+func (r *DeviceRepository) GetChatMessageCount(ctx context.Context, chatJID string) (int64, error) {
+	return r.base.GetChatMessageCountByDevice(ctx, r.deviceID, chatJID)
+}
+
+func (r *DeviceRepository) GetChatMessageCountByDevice(ctx context.Context, deviceID, chatJID string) (int64, error) {
+	return r.base.GetChatMessageCountByDevice(ctx, deviceID, chatJID)
+}
+
+func (r *DeviceRepository) GetTotalMessageCount(ctx context.Context) (int64, error) {
+	return r.base.GetTotalMessageCountByDevice(ctx, r.deviceID)
+}
+
+func (r *DeviceRepository) GetTotalMessageCountByDevice(ctx context.Context, deviceID string) (int64, error) {
+	return r.base.GetTotalMessageCountByDevice(ctx, deviceID)
+}
+
+func (r *DeviceRepository) GetTotalChatCount(ctx context.Context) (int64, error) {
+	return r.base.GetTotalChatCountByDevice(ctx, r.deviceID)
+}
+
+func (r *DeviceRepository) GetTotalChatCountByDevice(ctx context.Context, deviceID string) (int64, error) {
+	return r.base.GetTotalChatCountByDevice(ctx, deviceID)
 }
 
 func (r *DeviceRepository) GetChatNameWithPushName(jid types.JID, chatJID string, senderUser string, pushName string) string {
@@ -128,42 +404,189 @@ func (r *DeviceRepository) GetChatNameWithPushNameByDevice(deviceID string, jid
 	return r.base.GetChatNameWithPushNameByDevice(deviceID, jid, chatJID, senderUser, pushName)
 }
 
-func (r *DeviceRepository) GetStorageStatistics() (chatCount int64, messageCount int64, err error) {
-	return r.base.GetStorageStatistics()
+func (r *DeviceRepository) GetStorageStatistics(ctx context.Context) (chatCount int64, messageCount int64, err error) {
+	stats, err := r.base.GetStorageStatisticsByDevice(ctx, r.deviceID)
+	if err != nil {
+		return 0, 0, err
+	}
+	return stats.ChatCount, stats.MessageCount, nil
 }
 
-func (r *DeviceRepository) TruncateAllChats() error {
-	return r.base.TruncateAllChats()
+func (r *DeviceRepository) GetStorageStatisticsByDevice(ctx context.Context, deviceID string) (*domainChatStorage.StorageStatistics, error) {
+	return r.base.GetStorageStatisticsByDevice(ctx, deviceID)
 }
 
-func (r *DeviceRepository) TruncateAllDataWithLogging(logPrefix string) error {
-	return r.base.TruncateAllDataWithLogging(logPrefix)
+// TruncateAllChats only clears this device's chats; other devices share the base tables.
+func (r *DeviceRepository) TruncateAllChats(ctx context.Context) error {
+	return r.base.DeleteDeviceData(ctx, r.deviceID)
 }
 
-func (r *DeviceRepository) InitializeSchema() error {
-	return r.base.InitializeSchema()
+// TruncateAllDataWithLogging is scoped to this device, see TruncateAllChats.
+func (r *DeviceRepository) TruncateAllDataWithLogging(ctx context.Context, logPrefix, reportPath string) error {
+	if err := r.base.DeleteDeviceData(ctx, r.deviceID); err != nil {
+		return err
+	}
+	logrus.Infof("[%s] Chat storage cleared for device %s", logPrefix, r.deviceID)
+	return nil
 }
 
-func (r *DeviceRepository) DeleteDeviceData(deviceID string) error {
+func (r *DeviceRepository) InitializeSchema(ctx context.Context) error {
+	return r.base.InitializeSchema(ctx)
+}
+
+func (r *DeviceRepository) DeleteDeviceData(ctx context.Context, deviceID string) error {
 	target := deviceID
 	if target == "" {
 		target = r.deviceID
 	}
-	return r.base.DeleteDeviceData(target)
+	return r.base.DeleteDeviceData(ctx, target)
+}
+
+// DedupeChats is not scoped to the device, it cleans up the whole storage.
+func (r *DeviceRepository) DedupeChats(ctx context.Context, dryRun bool) ([]*domainChatStorage.ChatMerge, error) {
+	return r.base.DedupeChats(ctx, dryRun)
+}
+
+func (r *DeviceRepository) PruneMessagesBefore(ctx context.Context, deviceID string, cutoff time.Time) (int64, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.PruneMessagesBefore(ctx, deviceID, cutoff)
+}
+
+func (r *DeviceRepository) SaveDeviceRecord(ctx context.Context, record *domainChatStorage.DeviceRecord) error {
+	return r.base.SaveDeviceRecord(ctx, record)
+}
+
+func (r *DeviceRepository) ListDeviceRecords(ctx context.Context) ([]*domainChatStorage.DeviceRecord, error) {
+	return r.base.ListDeviceRecords(ctx)
+}
+
+func (r *DeviceRepository) GetDeviceRecord(ctx context.Context, deviceID string) (*domainChatStorage.DeviceRecord, error) {
+	return r.base.GetDeviceRecord(ctx, deviceID)
+}
+
+func (r *DeviceRepository) DeleteDeviceRecord(ctx context.Context, deviceID string) error {
+	return r.base.DeleteDeviceRecord(ctx, deviceID)
+}
+
+func (r *DeviceRepository) SaveDeviceSettings(ctx context.Context, deviceID string, settings domainChatStorage.DeviceSettings) error {
+	return r.base.SaveDeviceSettings(ctx, deviceID, settings)
+}
+
+func (r *DeviceRepository) SaveDeviceWebhook(ctx context.Context, deviceID string, webhook domainChatStorage.DeviceWebhook) error {
+	return r.base.SaveDeviceWebhook(ctx, deviceID, webhook)
+}
+
+// API keys are shared by every device
+func (r *DeviceRepository) StoreAPIKey(ctx context.Context, key *domainChatStorage.APIKey) error {
+	return r.base.StoreAPIKey(ctx, key)
+}
+
+func (r *DeviceRepository) ListAPIKeys(ctx context.Context) ([]*domainChatStorage.APIKey, error) {
+	return r.base.ListAPIKeys(ctx)
+}
+
+func (r *DeviceRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*domainChatStorage.APIKey, error) {
+	return r.base.GetAPIKeyByHash(ctx, keyHash)
+}
+
+func (r *DeviceRepository) RevokeAPIKey(ctx context.Context, id string) (bool, error) {
+	return r.base.RevokeAPIKey(ctx, id)
+}
+
+func (r *DeviceRepository) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	return r.base.TouchAPIKey(ctx, id, usedAt)
+}
+
+func (r *DeviceRepository) StoreAutoReplyRule(ctx context.Context, rule *domainChatStorage.AutoReplyRule) error {
+	return r.base.StoreAutoReplyRule(ctx, rule)
+}
+
+func (r *DeviceRepository) ListAutoReplyRules(ctx context.Context, deviceID string) ([]*domainChatStorage.AutoReplyRule, error) {
+	return r.base.ListAutoReplyRules(ctx, deviceID)
+}
+
+func (r *DeviceRepository) GetAutoReplyRule(ctx context.Context, deviceID, id string) (*domainChatStorage.AutoReplyRule, error) {
+	return r.base.GetAutoReplyRule(ctx, deviceID, id)
+}
+
+func (r *DeviceRepository) DeleteAutoReplyRule(ctx context.Context, deviceID, id string) (bool, error) {
+	return r.base.DeleteAutoReplyRule(ctx, deviceID, id)
+}
+
+func (r *DeviceRepository) StoreLabel(ctx context.Context, label *domainChatStorage.Label) error {
+	return r.base.StoreLabel(ctx, label)
+}
+
+func (r *DeviceRepository) ListLabels(ctx context.Context, deviceID string) ([]*domainChatStorage.Label, error) {
+	return r.base.ListLabels(ctx, deviceID)
+}
+
+func (r *DeviceRepository) GetLabel(ctx context.Context, deviceID, id string) (*domainChatStorage.Label, error) {
+	return r.base.GetLabel(ctx, deviceID, id)
+}
+
+func (r *DeviceRepository) DeleteLabel(ctx context.Context, deviceID, id string) (bool, error) {
+	return r.base.DeleteLabel(ctx, deviceID, id)
+}
+
+func (r *DeviceRepository) SetChatLabels(ctx context.Context, deviceID, chatJID string, labelIDs []string) error {
+	return r.base.SetChatLabels(ctx, deviceID, chatJID, labelIDs)
+}
+
+func (r *DeviceRepository) GetChatLabels(ctx context.Context, deviceID string, chatJIDs []string) (map[string][]*domainChatStorage.Label, error) {
+	return r.base.GetChatLabels(ctx, deviceID, chatJIDs)
+}
+
+func (r *DeviceRepository) StoreTemplate(ctx context.Context, tmpl *domainChatStorage.Template) error {
+	return r.base.StoreTemplate(ctx, tmpl)
+}
+
+func (r *DeviceRepository) ListTemplates(ctx context.Context, deviceID string) ([]*domainChatStorage.Template, error) {
+	return r.base.ListTemplates(ctx, deviceID)
+}
+
+func (r *DeviceRepository) GetTemplate(ctx context.Context, deviceID, name string) (*domainChatStorage.Template, error) {
+	return r.base.GetTemplate(ctx, deviceID, name)
+}
+
+func (r *DeviceRepository) DeleteTemplate(ctx context.Context, deviceID, name string) (bool, error) {
+	return r.base.DeleteTemplate(ctx, deviceID, name)
+}
+
+func (r *DeviceRepository) StoreJob(ctx context.Context, job *domainChatStorage.Job) error {
+	return r.base.StoreJob(ctx, job)
+}
+
+func (r *DeviceRepository) GetJob(ctx context.Context, deviceID, id string) (*domainChatStorage.Job, error) {
+	return r.base.GetJob(ctx, deviceID, id)
+}
+
+func (r *DeviceRepository) ListJobs(ctx context.Context, filter *domainChatStorage.JobFilter) ([]*domainChatStorage.Job, error) {
+	return r.base.ListJobs(ctx, filter)
+}
+
+func (r *DeviceRepository) DeleteJob(ctx context.Context, deviceID, id string) (bool, error) {
+	return r.base.DeleteJob(ctx, deviceID, id)
+}
+
+func (r *DeviceRepository) InterruptJobs(ctx context.Context, reason string) (int64, error) {
+	return r.base.InterruptJobs(ctx, reason)
 }
 
-func (r *DeviceRepository) SaveDeviceRecord(record *domainChatStorage.DeviceRecord) error {
-	return r.base.SaveDeviceRecord(record)
+func (r *DeviceRepository) ExportTable(ctx context.Context, table, deviceID string, fn func(row []byte) error) error {
+	return r.base.ExportTable(ctx, table, deviceID, fn)
 }
 
-func (r *DeviceRepository) ListDeviceRecords() ([]*domainChatStorage.DeviceRecord, error) {
-	return r.base.ListDeviceRecords()
+func (r *DeviceRepository) ImportTable(ctx context.Context, table, deviceID string, next func() ([]byte, error)) (int64, error) {
+	return r.base.ImportTable(ctx, table, deviceID, next)
 }
 
-func (r *DeviceRepository) GetDeviceRecord(deviceID string) (*domainChatStorage.DeviceRecord, error) {
-	return r.base.GetDeviceRecord(deviceID)
+func (r *DeviceRepository) CountTable(ctx context.Context, table string) (int64, error) {
+	return r.base.CountTable(ctx, table)
 }
 
-func (r *DeviceRepository) DeleteDeviceRecord(deviceID string) error {
-	return r.base.DeleteDeviceRecord(deviceID)
+func (r *DeviceRepository) Ping(ctx context.Context) error {
+	return r.base.Ping(ctx)
 }
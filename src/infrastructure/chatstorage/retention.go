@@ -0,0 +1,42 @@
+package chatstorage
+
+import (
+	"context"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"github.com/sirupsen/logrus"
+)
+
+const retentionInterval = 24 * time.Hour
+
+// StartRetentionJob prunes messages older than days across all devices once at
+// startup and then daily, until ctx is cancelled. It does nothing when days is
+// not positive.
+func StartRetentionJob(ctx context.Context, repo domainChatStorage.IChatStorageRepository, days int) {
+	if repo == nil || days <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(retentionInterval)
+		defer ticker.Stop()
+		for {
+			runRetention(ctx, repo, days)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func runRetention(ctx context.Context, repo domainChatStorage.IChatStorageRepository, days int) {
+	cutoff := time.Now().AddDate(0, 0, -days)
+	deleted, err := repo.PruneMessagesBefore(ctx, "", cutoff)
+	if err != nil {
+		logrus.Errorf("[CHAT_STORAGE] Retention pruning failed after removing %d messages: %v", deleted, err)
+		return
+	}
+	logrus.Infof("[CHAT_STORAGE] Retention pruning removed %d messages older than %s", deleted, cutoff.Format(time.RFC3339))
+}
@@ -3,133 +3,347 @@ package chatstorage
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 )
 
+// dialect is the SQL flavour of the database behind a SQLRepository.
+type dialect int
+
+const (
+	dialectSQLite dialect = iota
+	dialectPostgres
+	dialectMySQL
+)
+
 type SQLRepository struct {
-	db         *sql.DB
-	isPostgres bool
+	db      *sql.DB
+	dialect dialect
 }
 
+// NewStorageRepository detects the SQL dialect from the driver behind db. Only
+// Postgres needs its placeholders rewritten; SQLite and MySQL keep the ? form.
 func NewStorageRepository(db *sql.DB) domainChatStorage.IChatStorageRepository {
-	driverName := fmt.Sprintf("%T", db.Driver())
-	isPostgres := strings.Contains(strings.ToLower(driverName), "pq") || strings.Contains(strings.ToLower(driverName), "postgres")
 	return &SQLRepository{
-		db:         db,
-		isPostgres: isPostgres,
+		db:      db,
+		dialect: detectDialect(db.Driver()),
+	}
+}
+
+func detectDialect(driver any) dialect {
+	driverName := strings.ToLower(fmt.Sprintf("%T", driver))
+	switch {
+	case strings.Contains(driverName, "sqlite"):
+		return dialectSQLite
+	case strings.Contains(driverName, "mysql"):
+		return dialectMySQL
+	case strings.Contains(driverName, "pq"), strings.Contains(driverName, "postgres"):
+		return dialectPostgres
+	}
+	return dialectSQLite
+}
+
+// onConflictUpdate starts the upsert clause for a row colliding on conflictColumns.
+// MySQL always uses the primary key, so conflictColumns is only spelled out for
+// the other dialects.
+func (r *SQLRepository) onConflictUpdate(conflictColumns string) string {
+	if r.dialect == dialectMySQL {
+		return "ON DUPLICATE KEY UPDATE"
+	}
+	return "ON CONFLICT (" + conflictColumns + ") DO UPDATE SET"
+}
+
+// excluded refers to the value column would have had in the rejected insert.
+func (r *SQLRepository) excluded(column string) string {
+	if r.dialect == dialectMySQL {
+		return "VALUES(" + column + ")"
+	}
+	return "excluded." + column
+}
+
+// limitOffset pages a query by limit and offset, either of which may be zero.
+// SQLite and MySQL only take OFFSET after a LIMIT, so an offset without a
+// limit comes with the largest limit they accept.
+func (r *SQLRepository) limitOffset(limit, offset int) (string, []any) {
+	var clause string
+	var args []any
+	switch {
+	case limit > 0:
+		clause, args = " LIMIT ?", []any{limit}
+	case offset <= 0:
+		return "", nil
+	case r.dialect == dialectSQLite:
+		clause = " LIMIT -1"
+	case r.dialect == dialectMySQL:
+		clause = " LIMIT 18446744073709551615"
 	}
+	if offset > 0 {
+		clause += " OFFSET ?"
+		args = append(args, offset)
+	}
+	return clause, args
 }
 
 // p gestiona la compatibilidad de placeholders (? -> $n)
 func (r *SQLRepository) p(query string) string {
-	if !r.isPostgres {
+	if r.dialect != dialectPostgres || !strings.Contains(query, "?") {
 		return query
 	}
+	var b strings.Builder
+	b.Grow(len(query) + 16)
 	n := 1
-	for strings.Contains(query, "?") {
-		query = strings.Replace(query, "?", fmt.Sprintf("$%d", n), 1)
-		n++
+	for i := 0; i < len(query); i++ {
+		if query[i] == '?' {
+			b.WriteString("$" + strconv.Itoa(n))
+			n++
+			continue
+		}
+		b.WriteByte(query[i])
 	}
-	return query
+	return b.String()
 }
 
-func (r *SQLRepository) StoreChat(chat *domainChatStorage.Chat) error {
+func (r *SQLRepository) StoreChat(ctx context.Context, chat *domainChatStorage.Chat) error {
 	now := time.Now()
 	chat.UpdatedAt = now
-	qUpdate := `UPDATE chats SET name = ?, last_message_time = ?, ephemeral_expiration = ?, updated_at = ? WHERE jid = ? AND device_id = ?`
-	result, err := r.db.Exec(r.p(qUpdate), chat.Name, chat.LastMessageTime, chat.EphemeralExpiration, chat.UpdatedAt, chat.JID, chat.DeviceID)
-	if err != nil {
-		return err
-	}
+	chat.JID = canonicalChatJID(chat.JID)
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		qInsert := `INSERT INTO chats (jid, device_id, name, last_message_time, ephemeral_expiration, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
-		_, err = r.db.Exec(r.p(qInsert), chat.JID, chat.DeviceID, chat.Name, chat.LastMessageTime, chat.EphemeralExpiration, now, chat.UpdatedAt)
-	}
+	// A known name is kept when the incoming one is empty or only the phone
+	// number fallback, e.g. for messages that arrive without a push name.
+	// The same goes for the type, which callers only know for some chats, and
+	// the disappearing message timer, which SetEphemeralExpiration keeps and
+	// messages don't carry.
+	q := `INSERT INTO chats (jid, device_id, name, last_message_time, ephemeral_expiration, created_at, updated_at, chat_type) VALUES (?, ?, ?, ?, ?, ?, ?, ?) ` +
+		r.onConflictUpdate("jid, device_id") +
+		` name = CASE WHEN ` + r.excluded("name") + ` = '' OR (` + r.excluded("name") + ` = ? AND chats.name <> '') THEN chats.name ELSE ` + r.excluded("name") + ` END,` +
+		` last_message_time = ` + r.excluded("last_message_time") + `,` +
+		` ephemeral_expiration = CASE WHEN ` + r.excluded("ephemeral_expiration") + ` = 0 THEN chats.ephemeral_expiration ELSE ` + r.excluded("ephemeral_expiration") + ` END,` +
+		` updated_at = ` + r.excluded("updated_at") + `,` +
+		` chat_type = CASE WHEN ` + r.excluded("chat_type") + ` = '' THEN chats.chat_type ELSE ` + r.excluded("chat_type") + ` END`
+	_, err := r.db.ExecContext(ctx, r.p(q), chat.JID, chat.DeviceID, chat.Name, chat.LastMessageTime, chat.EphemeralExpiration, now, chat.UpdatedAt, chat.ChatType, chatNameFromJID(chat.JID))
 	return err
 }
 
-func (r *SQLRepository) GetChat(jid string) (*domainChatStorage.Chat, error) {
-	q := `SELECT device_id, jid, name, last_message_time, ephemeral_expiration, created_at, updated_at FROM chats WHERE jid = ?`
-	chat, err := r.scanChat(r.db.QueryRow(r.p(q), jid))
+const chatColumns = `device_id, jid, name, last_message_time, ephemeral_expiration, created_at, updated_at, archived, pinned, muted_until, unread_count, left_at, chat_type, blocked`
+
+func (r *SQLRepository) GetChat(ctx context.Context, jid string) (*domainChatStorage.Chat, error) {
+	q := "SELECT " + chatColumns + " FROM chats WHERE jid = ?"
+	chat, err := r.scanChat(r.db.QueryRowContext(ctx, r.p(q), jid))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	return chat, err
 }
 
-func (r *SQLRepository) GetChatByDevice(deviceID, jid string) (*domainChatStorage.Chat, error) {
-	q := `SELECT device_id, jid, name, last_message_time, ephemeral_expiration, created_at, updated_at FROM chats WHERE jid = ? AND device_id = ?`
-	chat, err := r.scanChat(r.db.QueryRow(r.p(q), jid, deviceID))
+func (r *SQLRepository) GetChatByDevice(ctx context.Context, deviceID, jid string) (*domainChatStorage.Chat, error) {
+	q := "SELECT " + chatColumns + ", (SELECT COUNT(*) FROM group_participants gp WHERE gp.group_jid = c.jid AND gp.device_id = c.device_id) FROM chats c WHERE jid = ? AND device_id = ?"
+	var participantCount int
+	chat, err := r.scanChat(r.db.QueryRowContext(ctx, r.p(q), jid, deviceID), &participantCount)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
+	chat.ParticipantCount = participantCount
 	return chat, err
 }
 
-func (r *SQLRepository) StoreMessage(message *domainChatStorage.Message) error {
+func (r *SQLRepository) StoreMessage(ctx context.Context, message *domainChatStorage.Message) error {
 	now := time.Now()
 	message.CreatedAt = now
 	message.UpdatedAt = now
 	if message.Content == "" && message.MediaType == "" {
 		return nil
 	}
+	message.ChatJID = canonicalChatJID(message.ChatJID)
+
+	query, args := r.buildMessageUpsert([]*domainChatStorage.Message{message})
+	_, err := r.db.ExecContext(ctx, r.p(query), args...)
+	return err
+}
+
+// messageBatchSize keeps each multi-row INSERT well below the bind parameter
+// limits of Postgres (65535) and SQLite (32766).
+const messageBatchSize = 200
+
+// StoreMessagesBatch upserts all messages in a single transaction, chunked into
+// multi-row INSERT ... ON CONFLICT statements. The chats touched by the batch are
+// upserted in the same transaction with their newest message time. It returns the
+// number of rows written; on error nothing is committed.
+func (r *SQLRepository) StoreMessagesBatch(ctx context.Context, messages []*domainChatStorage.Message) (int, error) {
+	now := time.Now()
+	batch := make([]*domainChatStorage.Message, 0, len(messages))
+	index := make(map[string]int, len(messages))
+	latest := make(map[[2]string]time.Time)
+	var chatKeys [][2]string
+
+	for _, m := range messages {
+		if m == nil || (m.Content == "" && m.MediaType == "") {
+			continue
+		}
+		m.CreatedAt = now
+		m.UpdatedAt = now
+		m.ChatJID = canonicalChatJID(m.ChatJID)
+
+		// A single statement cannot touch the same conflict key twice, keep the last copy.
+		key := m.ID + "\x00" + m.ChatJID + "\x00" + m.DeviceID
+		if i, ok := index[key]; ok {
+			batch[i] = m
+		} else {
+			index[key] = len(batch)
+			batch = append(batch, m)
+		}
+
+		chatKey := [2]string{m.ChatJID, m.DeviceID}
+		ts, seen := latest[chatKey]
+		if !seen {
+			chatKeys = append(chatKeys, chatKey)
+		}
+		if !seen || m.Timestamp.After(ts) {
+			latest[chatKey] = m.Timestamp
+		}
+	}
+
+	if len(batch) == 0 {
+		return 0, nil
+	}
 
-	qUpdate := `UPDATE messages SET sender = ?, content = ?, timestamp = ?, is_from_me = ?, media_type = ?, filename = ?, url = ?, media_key = ?, file_sha256 = ?, file_enc_sha256 = ?, file_length = ?, updated_at = ? WHERE id = ? AND chat_jid = ? AND device_id = ?`
-	result, err := r.db.Exec(r.p(qUpdate), message.Sender, message.Content, message.Timestamp, message.IsFromMe, message.MediaType, message.Filename, message.URL, message.MediaKey, message.FileSHA256, message.FileEncSHA256, message.FileLength, message.UpdatedAt, message.ID, message.ChatJID, message.DeviceID)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return 0, fmt.Errorf("failed to begin message batch transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		qInsert := `INSERT INTO messages (id, chat_jid, device_id, sender, content, timestamp, is_from_me, media_type, filename, url, media_key, file_sha256, file_enc_sha256, file_length, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
-		_, err = r.db.Exec(r.p(qInsert), message.ID, message.ChatJID, message.DeviceID, message.Sender, message.Content, message.Timestamp, message.IsFromMe, message.MediaType, message.Filename, message.URL, message.MediaKey, message.FileSHA256, message.FileEncSHA256, message.FileLength, message.CreatedAt, message.UpdatedAt)
+	for start := 0; start < len(batch); start += messageBatchSize {
+		end := min(start+messageBatchSize, len(batch))
+		query, args := r.buildMessageUpsert(batch[start:end])
+		if _, err := tx.ExecContext(ctx, r.p(query), args...); err != nil {
+			return 0, fmt.Errorf("failed to store messages %d-%d of %d: %w", start+1, end, len(batch), err)
+		}
 	}
-	return err
+
+	qChat := `INSERT INTO chats (jid, device_id, name, last_message_time, ephemeral_expiration, created_at, updated_at) VALUES (?, ?, ?, ?, 0, ?, ?) ` +
+		r.onConflictUpdate("jid, device_id") +
+		` last_message_time = CASE WHEN chats.last_message_time IS NULL OR ` + r.excluded("last_message_time") + ` > chats.last_message_time THEN ` + r.excluded("last_message_time") + ` ELSE chats.last_message_time END,` +
+		` updated_at = ` + r.excluded("updated_at")
+	for _, key := range chatKeys {
+		if _, err := tx.ExecContext(ctx, r.p(qChat), key[0], key[1], chatNameFromJID(key[0]), latest[key], now, now); err != nil {
+			return 0, fmt.Errorf("failed to update chat %s for message batch: %w", key[0], err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit message batch: %w", err)
+	}
+	return len(batch), nil
 }
 
-func (r *SQLRepository) GetMessageByID(id string) (*domainChatStorage.Message, error) {
-	q := `SELECT id, chat_jid, device_id, sender, content, timestamp, is_from_me, media_type, filename, url, media_key, file_sha256, file_enc_sha256, file_length, created_at, updated_at FROM messages WHERE id = ? LIMIT 1`
-	message, err := r.scanMessage(r.db.QueryRow(r.p(q), id))
-	if err == sql.ErrNoRows {
-		return nil, nil
+// messageUpsertColumns are overwritten when a stored message is received again.
+var messageUpsertColumns = []string{"sender", "content", "timestamp", "is_from_me", "media_type", "filename", "url", "media_key", "file_sha256", "file_enc_sha256", "file_length", "reply_to_id", "reply_to_sender", "location", "contacts", "is_animated", "is_view_once", "server_id", "updated_at"}
+
+func (r *SQLRepository) buildMessageUpsert(messages []*domainChatStorage.Message) (string, []any) {
+	const rowPlaceholder = "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	rows := make([]string, 0, len(messages))
+	args := make([]any, 0, len(messages)*25)
+	for _, m := range messages {
+		rows = append(rows, rowPlaceholder)
+		args = append(args, m.ID, m.ChatJID, m.DeviceID, m.Sender, m.Content, m.Timestamp, m.IsFromMe, m.MediaType, m.Filename, m.URL, m.MediaKey, m.FileSHA256, m.FileEncSHA256, m.FileLength, m.ReplyToID, m.ReplyToSender, encodeJSONColumn(m.Location), encodeJSONColumn(m.Contacts), m.IsAnimated, m.IsViewOnce, m.ServerID, m.RequestID, m.TemplateName, m.CreatedAt, m.UpdatedAt)
 	}
-	return message, err
+
+	updates := make([]string, 0, len(messageUpsertColumns)+1)
+	for _, column := range messageUpsertColumns {
+		updates = append(updates, column+" = "+r.excluded(column))
+	}
+	// Messages stored again without a request, e.g. by history sync, keep the request that sent them
+	updates = append(updates, "request_id = COALESCE(NULLIF("+r.excluded("request_id")+", ''), messages.request_id)")
+	updates = append(updates, "template_name = COALESCE(NULLIF("+r.excluded("template_name")+", ''), messages.template_name)")
+
+	query := `INSERT INTO messages (id, chat_jid, device_id, sender, content, timestamp, is_from_me, media_type, filename, url, media_key, file_sha256, file_enc_sha256, file_length, reply_to_id, reply_to_sender, location, contacts, is_animated, is_view_once, server_id, request_id, template_name, created_at, updated_at) VALUES ` +
+		strings.Join(rows, ", ") + " " + r.onConflictUpdate("id, chat_jid, device_id") + " " + strings.Join(updates, ", ")
+	return query, args
 }
 
-func (r *SQLRepository) GetChats(filter *domainChatStorage.ChatFilter) ([]*domainChatStorage.Chat, error) {
-	var conditions []string
-	var args []any
-	query := `SELECT c.device_id, c.jid, c.name, c.last_message_time, c.ephemeral_expiration, c.created_at, c.updated_at FROM chats c`
+// encodeJSONColumn returns the JSON stored in columns like location and
+// contacts, or nil (SQL NULL) when value is a nil pointer or slice.
+func encodeJSONColumn(value any) any {
+	encoded, err := json.Marshal(value)
+	if err != nil || string(encoded) == "null" {
+		return nil
+	}
+	return string(encoded)
+}
 
-	if filter.SearchName != "" {
-		conditions = append(conditions, "c.name LIKE ?")
-		args = append(args, "%"+filter.SearchName+"%")
+// canonicalChatJID strips the agent and device parts of user JIDs, so that
+// 5511999.0:12@s.whatsapp.net and 5511999@s.whatsapp.net share one chat.
+func canonicalChatJID(jid string) string {
+	parsed, err := types.ParseJID(jid)
+	if err != nil || (parsed.Server != types.DefaultUserServer && parsed.Server != types.HiddenUserServer) {
+		return jid
 	}
-	if filter.DeviceID != "" {
-		conditions = append(conditions, "c.device_id = ?")
-		args = append(args, filter.DeviceID)
+	return parsed.ToNonAD().String()
+}
+
+// chatNameFromJID returns the user part of a JID as a fallback chat name.
+func chatNameFromJID(jid string) string {
+	if user, _, found := strings.Cut(jid, "@"); found {
+		return user
 	}
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
+	return jid
+}
+
+func (r *SQLRepository) GetMessageByID(ctx context.Context, id string) (*domainChatStorage.Message, error) {
+	q := "SELECT " + messageColumns + " FROM messages WHERE id = ? LIMIT 1"
+	message, err := r.scanMessage(r.db.QueryRowContext(ctx, r.p(q), id))
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
-	query += " ORDER BY c.last_message_time DESC"
-	if filter.Limit > 0 {
-		query += " LIMIT ?"
-		args = append(args, filter.Limit)
+	return message, err
+}
+
+// GetMessageByDevice returns the message stored as id by deviceID, or nil.
+func (r *SQLRepository) GetMessageByDevice(ctx context.Context, deviceID, id string) (*domainChatStorage.Message, error) {
+	q := "SELECT " + messageColumns + " FROM messages WHERE id = ? AND device_id = ? LIMIT 1"
+	message, err := r.scanMessage(r.db.QueryRowContext(ctx, r.p(q), id, deviceID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return message, err
+}
+
+// GetOldestMessage returns the first message deviceID stored in the chat, or
+// nil.
+func (r *SQLRepository) GetOldestMessage(ctx context.Context, deviceID, chatJID string) (*domainChatStorage.Message, error) {
+	q := "SELECT " + messageColumns + " FROM messages WHERE device_id = ? AND chat_jid = ? ORDER BY timestamp ASC, id ASC LIMIT 1"
+	message, err := r.scanMessage(r.db.QueryRowContext(ctx, r.p(q), deviceID, chatJID))
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
+	return message, err
+}
 
-	rows, err := r.db.Query(r.p(query), args...)
+func (r *SQLRepository) GetChats(ctx context.Context, filter *domainChatStorage.ChatFilter) ([]*domainChatStorage.Chat, error) {
+	where, args := chatFilterWhere(filter)
+	query := "SELECT " + chatColumns + ", (SELECT COUNT(*) FROM group_participants gp WHERE gp.group_jid = c.jid AND gp.device_id = c.device_id) FROM chats c" + where
+	query += " ORDER BY c.pinned DESC, c.last_message_time DESC"
+	page, pageArgs := r.limitOffset(filter.Limit, filter.Offset)
+	query += page
+	args = append(args, pageArgs...)
+
+	rows, err := r.db.QueryContext(ctx, r.p(query), args...)
 	if err != nil {
 		return nil, err
 	}
@@ -137,222 +351,2815 @@ func (r *SQLRepository) GetChats(filter *domainChatStorage.ChatFilter) ([]*domai
 
 	var chats []*domainChatStorage.Chat
 	for rows.Next() {
-		chat, err := r.scanChat(rows)
+		var participantCount int
+		chat, err := r.scanChat(rows, &participantCount)
 		if err != nil {
 			return nil, err
 		}
+		chat.ParticipantCount = participantCount
 		chats = append(chats, chat)
 	}
-	return chats, nil
-}
-
-func (r *SQLRepository) DeleteChat(jid string) error {
-	tx, _ := r.db.Begin()
-	tx.Exec(r.p("DELETE FROM messages WHERE chat_jid = ?"), jid)
-	tx.Exec(r.p("DELETE FROM chats WHERE jid = ?"), jid)
-	return tx.Commit()
+	return chats, rows.Err()
 }
 
-func (r *SQLRepository) DeleteChatByDevice(deviceID, jid string) error {
-	tx, _ := r.db.Begin()
-	tx.Exec(r.p("DELETE FROM messages WHERE chat_jid = ? AND device_id = ?"), jid, deviceID)
-	tx.Exec(r.p("DELETE FROM chats WHERE jid = ? AND device_id = ?"), jid, deviceID)
-	return tx.Commit()
+// CountChats returns how many chats match filter, ignoring Limit and Offset.
+func (r *SQLRepository) CountChats(ctx context.Context, filter *domainChatStorage.ChatFilter) (int64, error) {
+	where, args := chatFilterWhere(filter)
+	return r.count(ctx, "SELECT COUNT(*) FROM chats c"+where, args...)
 }
 
-func (r *SQLRepository) GetMessages(filter *domainChatStorage.MessageFilter) ([]*domainChatStorage.Message, error) {
-	query := `SELECT id, chat_jid, device_id, sender, content, timestamp, is_from_me, media_type, filename, url, media_key, file_sha256, file_enc_sha256, file_length, created_at, updated_at FROM messages WHERE chat_jid = ? AND device_id = ? ORDER BY timestamp DESC`
-	if filter.Limit > 0 {
-		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+func chatFilterWhere(filter *domainChatStorage.ChatFilter) (string, []any) {
+	var conditions []string
+	var args []any
+	if filter.SearchName != "" {
+		conditions = append(conditions, "c.name LIKE ?")
+		args = append(args, "%"+filter.SearchName+"%")
 	}
-	rows, err := r.db.Query(r.p(query), filter.ChatJID, filter.DeviceID)
-	if err != nil {
-		return nil, err
+	if filter.DeviceID != "" {
+		conditions = append(conditions, "c.device_id = ?")
+		args = append(args, filter.DeviceID)
 	}
-	defer rows.Close()
-	var messages []*domainChatStorage.Message
-	for rows.Next() {
-		m, _ := r.scanMessage(rows)
-		messages = append(messages, m)
+	if filter.HasMedia {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM messages m WHERE m.chat_jid = c.jid AND m.device_id = c.device_id AND m.media_type <> '')")
 	}
-	return messages, nil
-}
-
-func (r *SQLRepository) SearchMessages(deviceID, chatJID, searchText string, limit int) ([]*domainChatStorage.Message, error) {
-	q := `SELECT id, chat_jid, device_id, sender, content, timestamp, is_from_me, media_type, filename, url, media_key, file_sha256, file_enc_sha256, file_length, created_at, updated_at FROM messages WHERE chat_jid = ? AND device_id = ? AND LOWER(content) LIKE ? ORDER BY timestamp DESC`
-	if limit > 0 {
-		q += fmt.Sprintf(" LIMIT %d", limit)
+	if filter.Archived != nil {
+		conditions = append(conditions, "c.archived = ?")
+		args = append(args, *filter.Archived)
 	}
-	rows, err := r.db.Query(r.p(q), chatJID, deviceID, "%"+strings.ToLower(searchText)+"%")
-	if err != nil {
-		return nil, err
+	if filter.Pinned != nil {
+		conditions = append(conditions, "c.pinned = ?")
+		args = append(args, *filter.Pinned)
 	}
-	defer rows.Close()
-	var messages []*domainChatStorage.Message
-	for rows.Next() {
-		m, _ := r.scanMessage(rows)
-		messages = append(messages, m)
+	if filter.Blocked != nil {
+		conditions = append(conditions, "c.blocked = ?")
+		args = append(args, *filter.Blocked)
 	}
-	return messages, nil
+	if filter.Unread {
+		conditions = append(conditions, "c.unread_count > 0")
+	}
+	if filter.LabelID != "" {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM chat_labels cl WHERE cl.chat_jid = c.jid AND cl.device_id = c.device_id AND cl.label_id = ?)")
+		args = append(args, filter.LabelID)
+	}
+	switch filter.ChatType {
+	case domainChatStorage.ChatTypeUser:
+		conditions = append(conditions, "(c.jid LIKE ? OR c.jid LIKE ?)")
+		args = append(args, "%@"+types.DefaultUserServer, "%@"+types.HiddenUserServer)
+	case domainChatStorage.ChatTypeGroup:
+		conditions = append(conditions, "c.jid LIKE ?")
+		args = append(args, "%@"+types.GroupServer)
+	case domainChatStorage.ChatTypeNewsletter:
+		conditions = append(conditions, "c.jid LIKE ?")
+		args = append(args, "%@"+types.NewsletterServer)
+	case domainChatStorage.ChatTypeStatus:
+		conditions = append(conditions, "c.jid = ?")
+		args = append(args, types.StatusBroadcastJID.String())
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
 }
 
-func (r *SQLRepository) DeleteMessage(id, chatJID string) error {
-	_, err := r.db.Exec(r.p("DELETE FROM messages WHERE id = ? AND chat_jid = ?"), id, chatJID)
-	return err
-}
+// UpdateChatFlags applies the non-nil flags to a stored chat. It is a no-op
+// for chats that are not stored yet.
+func (r *SQLRepository) UpdateChatFlags(ctx context.Context, deviceID, jid string, flags domainChatStorage.ChatFlags) error {
+	var sets []string
+	var args []any
+	if flags.Archived != nil {
+		sets = append(sets, "archived = ?")
+		args = append(args, *flags.Archived)
+	}
+	if flags.Pinned != nil {
+		sets = append(sets, "pinned = ?")
+		args = append(args, *flags.Pinned)
+	}
+	if flags.MutedUntil != nil {
+		sets = append(sets, "muted_until = ?")
+		if flags.MutedUntil.IsZero() {
+			args = append(args, nil)
+		} else {
+			args = append(args, *flags.MutedUntil)
+		}
+	}
+	if flags.LeftAt != nil {
+		sets = append(sets, "left_at = ?")
+		if flags.LeftAt.IsZero() {
+			args = append(args, nil)
+		} else {
+			args = append(args, *flags.LeftAt)
+		}
+	}
+	if flags.ChatType != nil {
+		sets = append(sets, "chat_type = ?")
+		args = append(args, *flags.ChatType)
+	}
+	if flags.Blocked != nil {
+		sets = append(sets, "blocked = ?")
+		args = append(args, *flags.Blocked)
+	}
+	if len(sets) == 0 {
+		return nil
+	}
 
-func (r *SQLRepository) DeleteMessageByDevice(deviceID, id, chatJID string) error {
-	_, err := r.db.Exec(r.p("DELETE FROM messages WHERE id = ? AND chat_jid = ? AND device_id = ?"), id, chatJID, deviceID)
+	sets = append(sets, "updated_at = ?")
+	args = append(args, time.Now(), jid, deviceID)
+	_, err := r.db.ExecContext(ctx, r.p("UPDATE chats SET "+strings.Join(sets, ", ")+" WHERE jid = ? AND device_id = ?"), args...)
 	return err
 }
 
-func (r *SQLRepository) SaveDeviceRecord(record *domainChatStorage.DeviceRecord) error {
-	now := time.Now()
-	res, _ := r.db.Exec(r.p("UPDATE devices SET display_name = ?, jid = ?, updated_at = ? WHERE device_id = ?"), record.DisplayName, record.JID, now, record.DeviceID)
-	aff, _ := res.RowsAffected()
-	if aff == 0 {
-		r.db.Exec(r.p("INSERT INTO devices (device_id, display_name, jid, created_at, updated_at) VALUES (?, ?, ?, ?, ?)"), record.DeviceID, record.DisplayName, record.JID, now, now)
+// MarkChatRead resets the unread count of a chat and marks its messages
+// read in one transaction, so the count and the messages never disagree. Like
+// the increment in CreateMessage the reset is a single UPDATE, so a message
+// arriving concurrently is either counted after the reset or not at all.
+func (r *SQLRepository) MarkChatRead(ctx context.Context, deviceID, jid string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
 	}
-	return nil
-}
+	defer tx.Rollback()
 
-func (r *SQLRepository) ListDeviceRecords() ([]*domainChatStorage.DeviceRecord, error) {
-	rows, _ := r.db.Query(r.p("SELECT device_id, display_name, jid, created_at, updated_at FROM devices ORDER BY created_at ASC"))
-	if rows == nil {
-		return nil, nil
+	if _, err := tx.ExecContext(ctx, r.p("UPDATE messages SET is_unread = ? WHERE chat_jid = ? AND device_id = ? AND is_unread = ?"), false, jid, deviceID, true); err != nil {
+		return err
 	}
-	defer rows.Close()
-	var records []*domainChatStorage.DeviceRecord
-	for rows.Next() {
-		rec := &domainChatStorage.DeviceRecord{}
-		rows.Scan(&rec.DeviceID, &rec.DisplayName, &rec.JID, &rec.CreatedAt, &rec.UpdatedAt)
-		records = append(records, rec)
+	if _, err := tx.ExecContext(ctx, r.p("UPDATE chats SET unread_count = 0 WHERE jid = ? AND device_id = ?"), jid, deviceID); err != nil {
+		return err
 	}
-	return records, nil
+	return tx.Commit()
 }
 
-func (r *SQLRepository) GetDeviceRecord(deviceID string) (*domainChatStorage.DeviceRecord, error) {
-	rec := &domainChatStorage.DeviceRecord{}
-	err := r.db.QueryRow(r.p("SELECT device_id, display_name, jid, created_at, updated_at FROM devices WHERE device_id = ? LIMIT 1"), deviceID).Scan(&rec.DeviceID, &rec.DisplayName, &rec.JID, &rec.CreatedAt, &rec.UpdatedAt)
-	if err == sql.ErrNoRows {
-		return nil, nil
+// blocklistChunkSize bounds the JIDs per statement of SetBlockedChats, keeping
+// well below the bind variable limits of the databases.
+const blocklistChunkSize = 200
+
+// SetBlockedChats marks the stored chats of jids as blocked and every other
+// chat of the device as not blocked, replacing the previous blocklist.
+func (r *SQLRepository) SetBlockedChats(ctx context.Context, deviceID string, jids []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin blocklist transaction: %w", err)
 	}
-	return rec, err
-}
+	defer tx.Rollback()
 
-func (r *SQLRepository) DeleteDeviceRecord(deviceID string) error {
-	_, err := r.db.Exec(r.p("DELETE FROM devices WHERE device_id = ?"), deviceID)
-	return err
+	if _, err := tx.ExecContext(ctx, r.p("UPDATE chats SET blocked = ? WHERE device_id = ? AND blocked = ?"), false, deviceID, true); err != nil {
+		return fmt.Errorf("failed to clear blocked chats: %w", err)
+	}
+	for start := 0; start < len(jids); start += blocklistChunkSize {
+		chunk := jids[start:min(start+blocklistChunkSize, len(jids))]
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(chunk)), ", ")
+		args := []any{true, deviceID}
+		for _, jid := range chunk {
+			args = append(args, canonicalChatJID(jid))
+		}
+		if _, err := tx.ExecContext(ctx, r.p("UPDATE chats SET blocked = ? WHERE device_id = ? AND jid IN ("+placeholders+")"), args...); err != nil {
+			return fmt.Errorf("failed to mark blocked chats: %w", err)
+		}
+	}
+	return tx.Commit()
 }
 
-func (r *SQLRepository) CreateMessage(ctx context.Context, evt *events.Message) error {
-	client := whatsapp.ClientFromContext(ctx)
-	var deviceID string
-	if inst, ok := whatsapp.DeviceFromContext(ctx); ok {
-		deviceID = inst.ID()
+// MarkMessagesRead marks messages of a chat read and lowers the unread count
+// of the chat by the ones that were still unread, which it returns.
+func (r *SQLRepository) MarkMessagesRead(ctx context.Context, deviceID, chatJID string, ids []string) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
 	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin mark read transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	normalizedChatJID := whatsapp.NormalizeJIDFromLID(ctx, evt.Info.Chat, client)
-	chatJID := normalizedChatJID.String()
-
-	chat := &domainChatStorage.Chat{
-		DeviceID:        deviceID,
-		JID:             chatJID,
-		Name:            r.GetChatNameWithPushName(normalizedChatJID, chatJID, evt.Info.Sender.User, evt.Info.PushName),
-		LastMessageTime: evt.Info.Timestamp,
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	args := []any{false, chatJID, deviceID, true}
+	for _, id := range ids {
+		args = append(args, id)
 	}
-	_ = r.StoreChat(chat)
+	result, err := tx.ExecContext(ctx, r.p("UPDATE messages SET is_unread = ? WHERE chat_jid = ? AND device_id = ? AND is_unread = ? AND id IN ("+placeholders+")"), args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark messages of %s read: %w", chatJID, err)
+	}
+	marked, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if marked > 0 {
+		if _, err := tx.ExecContext(ctx, r.p("UPDATE chats SET unread_count = CASE WHEN unread_count > ? THEN unread_count - ? ELSE 0 END WHERE jid = ? AND device_id = ?"),
+			marked, marked, chatJID, deviceID); err != nil {
+			return 0, fmt.Errorf("failed to lower unread count of %s: %w", chatJID, err)
+		}
+	}
+	return int(marked), tx.Commit()
+}
 
-	content := utils.ExtractMessageTextFromProto(evt.Message)
-	mType, fName, url, mKey, fSha, fEncSha, fLen := utils.ExtractMediaInfo(evt.Message)
+// SetEphemeralExpiration stores the disappearing message timer of a chat in
+// seconds, zero when it is off, and returns the timer it replaces. A chat that
+// is not stored yet is created.
+func (r *SQLRepository) SetEphemeralExpiration(ctx context.Context, deviceID, jid string, expiration uint32) (uint32, error) {
+	jid = canonicalChatJID(jid)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
 
-	message := &domainChatStorage.Message{
-		ID: evt.Info.ID, ChatJID: chatJID, DeviceID: deviceID, Sender: evt.Info.Sender.String(),
-		Content: content, Timestamp: evt.Info.Timestamp, IsFromMe: evt.Info.IsFromMe,
-		MediaType: mType, Filename: fName, URL: url, MediaKey: mKey, FileSHA256: fSha, FileEncSHA256: fEncSha, FileLength: fLen,
+	now := time.Now()
+	var previous uint32
+	err = tx.QueryRowContext(ctx, r.p("SELECT ephemeral_expiration FROM chats WHERE jid = ? AND device_id = ?"), jid, deviceID).Scan(&previous)
+	switch {
+	case err == sql.ErrNoRows:
+		_, err = tx.ExecContext(ctx, r.p("INSERT INTO chats (jid, device_id, name, last_message_time, ephemeral_expiration, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)"),
+			jid, deviceID, chatNameFromJID(jid), now, expiration, now, now)
+	case err == nil:
+		_, err = tx.ExecContext(ctx, r.p("UPDATE chats SET ephemeral_expiration = ?, updated_at = ? WHERE jid = ? AND device_id = ?"), expiration, now, jid, deviceID)
+	}
+	if err != nil {
+		return 0, err
 	}
-	return r.StoreMessage(message)
+	return previous, tx.Commit()
 }
 
-func (r *SQLRepository) GetChatNameWithPushName(jid types.JID, chatJID string, senderUser string, pushName string) string {
-	if pushName != "" {
-		return pushName
+// MergeChats moves the messages of fromJID, with their reactions, edits,
+// receipts and poll data, into toJID and deletes fromJID. Messages already stored under toJID
+// are kept over their copy in fromJID. When toJID is not stored yet, fromJID is
+// renamed instead. It returns the number of messages moved.
+func (r *SQLRepository) MergeChats(ctx context.Context, deviceID, fromJID, toJID string) (int64, error) {
+	if fromJID == toJID {
+		return 0, nil
 	}
-	return jid.User
-}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
 
-func (r *SQLRepository) GetChatNameWithPushNameByDevice(deviceID string, jid types.JID, chatJID string, senderUser string, pushName string) string {
-	return r.GetChatNameWithPushName(jid, chatJID, senderUser, pushName)
+	moved, err := r.mergeChats(ctx, tx, deviceID, fromJID, toJID)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return moved, nil
 }
 
-func (r *SQLRepository) InitializeSchema() error {
-	v, _ := r.getSchemaVersion()
-	migs := r.getMigrations()
-	for i := v; i < len(migs); i++ {
-		_, _ = r.db.Exec(migs[i])
-		_, _ = r.db.Exec(r.p("DELETE FROM schema_info WHERE version = ?"), i+1)
-		_, _ = r.db.Exec(r.p("INSERT INTO schema_info (version) VALUES (?)"), i+1)
+func (r *SQLRepository) mergeChats(ctx context.Context, tx *sql.Tx, deviceID, fromJID, toJID string) (int64, error) {
+	rows, err := tx.QueryContext(ctx, r.p("SELECT f.id FROM messages f JOIN messages t ON t.id = f.id AND t.device_id = f.device_id"+
+		" WHERE f.chat_jid = ? AND t.chat_jid = ? AND f.device_id = ?"), fromJID, toJID, deviceID)
+	if err != nil {
+		return 0, err
+	}
+	var duplicates []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		duplicates = append(duplicates, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	for _, id := range duplicates {
+		if _, err := tx.ExecContext(ctx, r.p("DELETE FROM messages WHERE id = ? AND chat_jid = ? AND device_id = ?"), id, fromJID, deviceID); err != nil {
+			return 0, err
+		}
+		for _, table := range []string{"reactions", "message_edits", "receipts", "polls", "poll_votes"} {
+			if _, err := tx.ExecContext(ctx, r.p("DELETE FROM "+table+" WHERE message_id = ? AND chat_jid = ? AND device_id = ?"), id, fromJID, deviceID); err != nil {
+				return 0, err
+			}
+		}
 	}
-	return nil
-}
 
-func (r *SQLRepository) getSchemaVersion() (int, error) {
-	_, _ = r.db.Exec(r.p(`CREATE TABLE IF NOT EXISTS schema_info (version INTEGER PRIMARY KEY, updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)`))
-	var v int
-	_ = r.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_info`).Scan(&v)
-	return v, nil
-}
+	res, err := tx.ExecContext(ctx, r.p("UPDATE messages SET chat_jid = ? WHERE chat_jid = ? AND device_id = ?"), toJID, fromJID, deviceID)
+	if err != nil {
+		return 0, err
+	}
+	moved, _ := res.RowsAffected()
+	for _, table := range []string{"reactions", "message_edits", "receipts", "polls", "poll_votes"} {
+		if _, err := tx.ExecContext(ctx, r.p("UPDATE "+table+" SET chat_jid = ? WHERE chat_jid = ? AND device_id = ?"), toJID, fromJID, deviceID); err != nil {
+			return 0, err
+		}
+	}
+	// toJID keeps its labels and gains those of fromJID. MySQL can't select
+	// from the table it deletes from, hence the derived table.
+	if _, err := tx.ExecContext(ctx, r.p("DELETE FROM chat_labels WHERE chat_jid = ? AND device_id = ? AND label_id IN"+
+		" (SELECT label_id FROM (SELECT label_id FROM chat_labels WHERE chat_jid = ? AND device_id = ?) AS kept)"), fromJID, deviceID, toJID, deviceID); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, r.p("UPDATE chat_labels SET chat_jid = ? WHERE chat_jid = ? AND device_id = ?"), toJID, fromJID, deviceID); err != nil {
+		return 0, err
+	}
 
-func (r *SQLRepository) getMigrations() []string {
-	blobType := "BLOB"
-	if r.isPostgres {
-		blobType = "BYTEA"
+	var lastMessage sql.NullTime
+	var unread int
+	err = tx.QueryRowContext(ctx, r.p("SELECT last_message_time, unread_count FROM chats WHERE jid = ? AND device_id = ?"), fromJID, deviceID).Scan(&lastMessage, &unread)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, err
 	}
-	return []string{
-		`CREATE TABLE IF NOT EXISTS chats (jid VARCHAR(255), device_id VARCHAR(255) DEFAULT '', name VARCHAR(255), last_message_time TIMESTAMP, ephemeral_expiration INTEGER DEFAULT 0, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (jid, device_id))`,
-		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS messages (id VARCHAR(255), chat_jid VARCHAR(255), device_id VARCHAR(255) DEFAULT '', sender VARCHAR(255), content TEXT, timestamp TIMESTAMP, is_from_me BOOLEAN DEFAULT FALSE, media_type VARCHAR(50), filename VARCHAR(255), url TEXT, media_key %s, file_sha256 %s, file_enc_sha256 %s, file_length INTEGER DEFAULT 0, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (id, chat_jid, device_id))`, blobType, blobType, blobType),
-		`CREATE TABLE IF NOT EXISTS devices (device_id VARCHAR(255) PRIMARY KEY, display_name VARCHAR(255) DEFAULT '', jid VARCHAR(255) DEFAULT '', created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)`,
+	if err == nil {
+		var exists int
+		if err := tx.QueryRowContext(ctx, r.p("SELECT COUNT(*) FROM chats WHERE jid = ? AND device_id = ?"), toJID, deviceID).Scan(&exists); err != nil {
+			return 0, err
+		}
+		now := time.Now()
+		if exists == 0 {
+			if _, err := tx.ExecContext(ctx, r.p("UPDATE chats SET jid = ?, updated_at = ? WHERE jid = ? AND device_id = ?"), toJID, now, fromJID, deviceID); err != nil {
+				return 0, err
+			}
+		} else {
+			if _, err := tx.ExecContext(ctx, r.p("UPDATE chats SET unread_count = unread_count + ?, updated_at = ? WHERE jid = ? AND device_id = ?"), unread, now, toJID, deviceID); err != nil {
+				return 0, err
+			}
+			if lastMessage.Valid {
+				if _, err := tx.ExecContext(ctx, r.p("UPDATE chats SET last_message_time = ? WHERE jid = ? AND device_id = ? AND (last_message_time IS NULL OR last_message_time < ?)"),
+					lastMessage.Time, toJID, deviceID, lastMessage.Time); err != nil {
+					return 0, err
+				}
+			}
+			if _, err := tx.ExecContext(ctx, r.p("DELETE FROM chats WHERE jid = ? AND device_id = ?"), fromJID, deviceID); err != nil {
+				return 0, err
+			}
+		}
 	}
+
+	return moved, nil
 }
 
-func (r *SQLRepository) scanChat(s interface{ Scan(...any) error }) (*domainChatStorage.Chat, error) {
-	c := &domainChatStorage.Chat{}
-	err := s.Scan(&c.DeviceID, &c.JID, &c.Name, &c.LastMessageTime, &c.EphemeralExpiration, &c.CreatedAt, &c.UpdatedAt)
-	return c, err
+func (r *SQLRepository) DeleteChat(ctx context.Context, jid string) error {
+	return r.deleteChat(ctx, "chat_jid = ?", "jid = ?", jid)
 }
 
-func (r *SQLRepository) scanMessage(s interface{ Scan(...any) error }) (*domainChatStorage.Message, error) {
-	m := &domainChatStorage.Message{}
-	err := s.Scan(&m.ID, &m.ChatJID, &m.DeviceID, &m.Sender, &m.Content, &m.Timestamp, &m.IsFromMe, &m.MediaType, &m.Filename, &m.URL, &m.MediaKey, &m.FileSHA256, &m.FileEncSHA256, &m.FileLength, &m.CreatedAt, &m.UpdatedAt)
-	return m, err
+func (r *SQLRepository) DeleteChatByDevice(ctx context.Context, deviceID, jid string) error {
+	return r.deleteChat(ctx, "chat_jid = ? AND device_id = ?", "jid = ? AND device_id = ?", jid, deviceID)
 }
 
-// Implementación del método faltante para que la interfaz se cumpla
-func (r *SQLRepository) DeleteDeviceData(deviceID string) error {
-	if deviceID == "" {
-		return fmt.Errorf("device_id is required")
-	}
-	tx, err := r.db.Begin()
+func (r *SQLRepository) deleteChat(ctx context.Context, messageCond, chatCond string, args ...any) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	_, _ = tx.Exec(r.p("DELETE FROM messages WHERE device_id = ?"), deviceID)
-	_, _ = tx.Exec(r.p("DELETE FROM chats WHERE device_id = ?"), deviceID)
-
+	if _, err := tx.ExecContext(ctx, r.p("DELETE FROM messages WHERE "+messageCond), args...); err != nil {
+		return err
+	}
+	// reactions, message_edits, receipts and the poll tables share the chat_jid and device_id columns of messages
+	for _, table := range []string{"reactions", "message_edits", "receipts", "polls", "poll_votes"} {
+		if _, err := tx.ExecContext(ctx, r.p("DELETE FROM "+table+" WHERE "+messageCond), args...); err != nil {
+			return err
+		}
+	}
+	// The labels stay, only their assignments to the chat go
+	if _, err := tx.ExecContext(ctx, r.p("DELETE FROM chat_labels WHERE "+messageCond), args...); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, r.p("DELETE FROM chats WHERE "+chatCond), args...); err != nil {
+		return err
+	}
 	return tx.Commit()
 }
 
-// Resto de métodos requeridos por la interfaz
-func (r *SQLRepository) StoreMessagesBatch(m []*domainChatStorage.Message) error { return nil }
-func (r *SQLRepository) GetChatMessageCount(jid string) (int64, error)           { return 0, nil }
-func (r *SQLRepository) GetChatMessageCountByDevice(d, j string) (int64, error)  { return 0, nil }
-func (r *SQLRepository) GetTotalMessageCount() (int64, error)                    { return 0, nil }
-func (r *SQLRepository) GetTotalChatCount() (int64, error)                       { return 0, nil }
-func (r *SQLRepository) TruncateAllChats() error                                 { return nil }
-func (r *SQLRepository) GetStorageStatistics() (int64, int64, error)             { return 0, 0, nil }
-func (r *SQLRepository) TruncateAllDataWithLogging(p string) error               { return nil }
-func (r *SQLRepository) StoreSentMessageWithContext(ctx context.Context, mID, s, rec, con string, t time.Time) error {
-	return nil
+const messageColumns = `id, chat_jid, device_id, sender, content, timestamp, is_from_me, media_type, filename, url, media_key, file_sha256, file_enc_sha256, file_length, created_at, updated_at, edited_at, is_deleted, reply_to_id, reply_to_sender, location, contacts, media_path, downloaded_at, is_animated, is_view_once, server_id, pinned_until, is_unread, request_id, template_name`
+
+// GetMessages returns messages newest first, ordered by (timestamp, id) so that
+// keyset cursors page deterministically through messages sharing a timestamp.
+func (r *SQLRepository) GetMessages(ctx context.Context, filter *domainChatStorage.MessageFilter) ([]*domainChatStorage.Message, error) {
+	conditions, args := messageFilterConditions(filter)
+
+	if filter.Before != nil {
+		if filter.BeforeID != "" {
+			conditions = append(conditions, "(timestamp < ? OR (timestamp = ? AND id < ?))")
+			args = append(args, *filter.Before, *filter.Before, filter.BeforeID)
+		} else {
+			conditions = append(conditions, "timestamp < ?")
+			args = append(args, *filter.Before)
+		}
+	}
+	if filter.After != nil {
+		conditions = append(conditions, "timestamp > ?")
+		args = append(args, *filter.After)
+	}
+
+	query := "SELECT " + messageColumns + " FROM messages WHERE " + strings.Join(conditions, " AND ") + " ORDER BY timestamp DESC, id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	return r.queryMessages(ctx, filter, query, args...)
+}
+
+// queryMessages runs a messageColumns query and attaches reactions when filter asks for them.
+func (r *SQLRepository) queryMessages(ctx context.Context, filter *domainChatStorage.MessageFilter, query string, args ...any) ([]*domainChatStorage.Message, error) {
+	rows, err := r.db.QueryContext(ctx, r.p(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := r.scanMessages(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	if filter.IncludeReactions {
+		if err := r.attachReactions(ctx, messages); err != nil {
+			return nil, err
+		}
+	}
+	if filter.IncludeQuoted {
+		if err := r.attachQuoted(ctx, messages); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.attachStatus(ctx, messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// StreamMessages calls fn for every message matching filter, oldest first,
+// reading from a single rows cursor so arbitrarily large chats never sit in
+// memory at once. Limit and Offset are ignored. Iteration stops at the first
+// error returned by fn.
+func (r *SQLRepository) StreamMessages(ctx context.Context, filter *domainChatStorage.MessageFilter, fn func(*domainChatStorage.Message) error) error {
+	conditions, args := messageFilterConditions(filter)
+	query := "SELECT " + messageColumns + " FROM messages WHERE " + strings.Join(conditions, " AND ") + " ORDER BY timestamp ASC, id ASC"
+
+	rows, err := r.db.QueryContext(ctx, r.p(query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		message, err := r.scanMessage(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(message); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// SearchMessages matches content within the chat and device of filter, honouring
+// the rest of its conditions. Every word must match and "quoted phrases" match
+// as a whole. Postgres uses the full-text index and ranks results; other drivers
+// fall back to case-insensitive LIKE matching ordered by time.
+func (r *SQLRepository) SearchMessages(ctx context.Context, filter *domainChatStorage.MessageFilter, searchText string) ([]*domainChatStorage.Message, error) {
+	conditions, args := messageFilterConditions(filter)
+	searchConditions, searchArgs := r.contentSearchConditions("content", searchText)
+	conditions = append(conditions, searchConditions...)
+	args = append(args, searchArgs...)
+
+	orderBy := "timestamp DESC, id DESC"
+	if r.dialect == dialectPostgres {
+		orderBy = "ts_rank(" + contentTSVector("content") + ", websearch_to_tsquery('simple', ?)) DESC, " + orderBy
+		args = append(args, searchText)
+	}
+
+	query := "SELECT " + messageColumns + " FROM messages WHERE " + strings.Join(conditions, " AND ") + " ORDER BY " + orderBy
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	return r.queryMessages(ctx, filter, query, args...)
+}
+
+// SearchMessagesGlobal searches every chat of deviceID with the same matching
+// rules as SearchMessages, newest first, and includes each hit's chat name.
+func (r *SQLRepository) SearchMessagesGlobal(ctx context.Context, deviceID, searchText string, limit, offset int) ([]*domainChatStorage.SearchResult, error) {
+	conditions, args := r.contentSearchConditions("m.content", searchText)
+	conditions = append([]string{"m.device_id = ?"}, conditions...)
+	args = append([]any{deviceID}, args...)
+
+	query := "SELECT m." + strings.ReplaceAll(messageColumns, ", ", ", m.") + ", COALESCE(c.name, '')" +
+		" FROM messages m LEFT JOIN chats c ON c.jid = m.chat_jid AND c.device_id = m.device_id" +
+		" WHERE " + strings.Join(conditions, " AND ") + " ORDER BY m.timestamp DESC, m.id DESC"
+	page, pageArgs := r.limitOffset(limit, offset)
+	query += page
+	args = append(args, pageArgs...)
+
+	rows, err := r.db.QueryContext(ctx, r.p(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*domainChatStorage.SearchResult
+	for rows.Next() {
+		var chatName string
+		m, err := r.scanMessage(rows, &chatName)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, &domainChatStorage.SearchResult{Message: *m, ChatName: chatName})
+	}
+	return results, rows.Err()
+}
+
+// contentSearchConditions builds the WHERE conditions matching searchText
+// against column: full-text on Postgres, one LIKE per term elsewhere.
+func (r *SQLRepository) contentSearchConditions(column, searchText string) ([]string, []any) {
+	if r.dialect == dialectPostgres {
+		return []string{contentTSVector(column) + " @@ websearch_to_tsquery('simple', ?)"}, []any{searchText}
+	}
+	var conditions []string
+	var args []any
+	for _, term := range splitSearchTerms(searchText) {
+		conditions = append(conditions, "LOWER("+column+") LIKE ?")
+		args = append(args, "%"+term+"%")
+	}
+	return conditions, args
+}
+
+// contentTSVector must match the expression of idx_messages_content_fts so
+// Postgres can use the index.
+func contentTSVector(column string) string {
+	return "to_tsvector('simple', COALESCE(" + column + ", ''))"
+}
+
+// splitSearchTerms lowercases text and splits it into words, keeping "quoted
+// phrases" together.
+func splitSearchTerms(text string) []string {
+	var terms []string
+	for i, part := range strings.Split(strings.ToLower(text), `"`) {
+		if i%2 == 1 {
+			if phrase := strings.Join(strings.Fields(part), " "); phrase != "" {
+				terms = append(terms, phrase)
+			}
+			continue
+		}
+		terms = append(terms, strings.Fields(part)...)
+	}
+	return terms
+}
+
+// messageFilterConditions returns the WHERE conditions shared by GetMessages
+// and SearchMessages. Cursors and limits are left to the caller.
+func messageFilterConditions(filter *domainChatStorage.MessageFilter) ([]string, []any) {
+	conditions := []string{"chat_jid = ?", "device_id = ?"}
+	args := []any{filter.ChatJID, filter.DeviceID}
+
+	if filter.StartTime != nil {
+		conditions = append(conditions, "timestamp >= ?")
+		args = append(args, *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		conditions = append(conditions, "timestamp <= ?")
+		args = append(args, *filter.EndTime)
+	}
+	if filter.MediaOnly {
+		conditions = append(conditions, "media_type <> ''")
+	}
+	if len(filter.MediaTypes) > 0 {
+		placeholders := make([]string, len(filter.MediaTypes))
+		withText := false
+		for i, mediaType := range filter.MediaTypes {
+			placeholders[i] = "?"
+			args = append(args, mediaType)
+			withText = withText || mediaType == ""
+		}
+		condition := "media_type IN (" + strings.Join(placeholders, ", ") + ")"
+		if withText {
+			condition = "(" + condition + " OR media_type IS NULL)"
+		}
+		conditions = append(conditions, condition)
+	}
+	if filter.Sender != "" {
+		// Senders are stored as received, e.g. 12345:12@s.whatsapp.net for a
+		// linked device, so also match any device of the given user.
+		user, server, _ := strings.Cut(filter.Sender, "@")
+		user, _, _ = strings.Cut(user, ":")
+		conditions = append(conditions, "(sender = ? OR sender LIKE ?)")
+		args = append(args, user+"@"+server, user+":%@"+server)
+	}
+	if filter.IsFromMe != nil {
+		conditions = append(conditions, "is_from_me = ?")
+		args = append(args, *filter.IsFromMe)
+	}
+	if filter.Unread {
+		conditions = append(conditions, "is_unread = ?")
+		args = append(args, true)
+	}
+	if filter.ServerID != 0 {
+		conditions = append(conditions, "server_id = ?")
+		args = append(args, filter.ServerID)
+	}
+	return conditions, args
+}
+
+func (r *SQLRepository) DeleteMessage(ctx context.Context, id, chatJID string) error {
+	return r.deleteMessage(ctx, "id = ? AND chat_jid = ?", "message_id = ? AND chat_jid = ?", id, chatJID)
+}
+
+func (r *SQLRepository) DeleteMessageByDevice(ctx context.Context, deviceID, id, chatJID string) error {
+	return r.deleteMessage(ctx, "id = ? AND chat_jid = ? AND device_id = ?", "message_id = ? AND chat_jid = ? AND device_id = ?", id, chatJID, deviceID)
+}
+
+// deleteMessage removes a message together with its reactions, edit history,
+// receipts and poll data; relatedCond selects those by message_id.
+func (r *SQLRepository) deleteMessage(ctx context.Context, messageCond, relatedCond string, args ...any) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, r.p("DELETE FROM messages WHERE "+messageCond), args...); err != nil {
+		return err
+	}
+	for _, table := range []string{"reactions", "message_edits", "receipts", "polls", "poll_votes"} {
+		if _, err := tx.ExecContext(ctx, r.p("DELETE FROM "+table+" WHERE "+relatedCond), args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *SQLRepository) GetChatMessageCount(ctx context.Context, chatJID string) (int64, error) {
+	return r.count(ctx, "SELECT COUNT(*) FROM messages WHERE chat_jid = ?", chatJID)
+}
+
+func (r *SQLRepository) GetChatMessageCountByDevice(ctx context.Context, deviceID, chatJID string) (int64, error) {
+	return r.count(ctx, "SELECT COUNT(*) FROM messages WHERE chat_jid = ? AND device_id = ?", chatJID, deviceID)
+}
+
+func (r *SQLRepository) GetTotalMessageCount(ctx context.Context) (int64, error) {
+	return r.count(ctx, "SELECT COUNT(*) FROM messages")
+}
+
+func (r *SQLRepository) GetTotalMessageCountByDevice(ctx context.Context, deviceID string) (int64, error) {
+	return r.count(ctx, "SELECT COUNT(*) FROM messages WHERE device_id = ?", deviceID)
+}
+
+func (r *SQLRepository) GetTotalChatCount(ctx context.Context) (int64, error) {
+	return r.count(ctx, "SELECT COUNT(*) FROM chats")
+}
+
+func (r *SQLRepository) GetTotalChatCountByDevice(ctx context.Context, deviceID string) (int64, error) {
+	return r.count(ctx, "SELECT COUNT(*) FROM chats WHERE device_id = ?", deviceID)
+}
+
+func (r *SQLRepository) GetStorageStatistics(ctx context.Context) (chatCount int64, messageCount int64, err error) {
+	if chatCount, err = r.GetTotalChatCount(ctx); err != nil {
+		return 0, 0, err
+	}
+	if messageCount, err = r.GetTotalMessageCount(ctx); err != nil {
+		return 0, 0, err
+	}
+	return chatCount, messageCount, nil
+}
+
+func (r *SQLRepository) GetStorageStatisticsByDevice(ctx context.Context, deviceID string) (*domainChatStorage.StorageStatistics, error) {
+	stats := &domainChatStorage.StorageStatistics{DeviceID: deviceID}
+
+	chatCount, err := r.GetTotalChatCountByDevice(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	stats.ChatCount = chatCount
+
+	// Aggregates always yield one row; MIN/MAX are NULL when the device has no messages.
+	var oldest, newest sql.NullTime
+	err = r.db.QueryRowContext(ctx, r.p(`SELECT COUNT(*), COALESCE(SUM(file_length), 0), MIN(timestamp), MAX(timestamp), COUNT(DISTINCT sender) FROM messages WHERE device_id = ?`), deviceID).
+		Scan(&stats.MessageCount, &stats.MediaBytes, &oldest, &newest, &stats.DistinctSenders)
+	if err != nil {
+		return nil, err
+	}
+	if oldest.Valid {
+		stats.OldestMessage = &oldest.Time
+	}
+	if newest.Valid {
+		stats.NewestMessage = &newest.Time
+	}
+	return stats, nil
+}
+
+func (r *SQLRepository) count(ctx context.Context, query string, args ...any) (int64, error) {
+	var n int64
+	if err := r.db.QueryRowContext(ctx, r.p(query), args...).Scan(&n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (r *SQLRepository) SaveDeviceRecord(ctx context.Context, record *domainChatStorage.DeviceRecord) error {
+	now := time.Now()
+	res, err := r.db.ExecContext(ctx, r.p("UPDATE devices SET display_name = ?, jid = ?, updated_at = ? WHERE device_id = ?"), record.DisplayName, record.JID, now, record.DeviceID)
+	if err != nil {
+		return err
+	}
+	if aff, _ := res.RowsAffected(); aff > 0 {
+		return nil
+	}
+	_, err = r.db.ExecContext(ctx, r.p("INSERT INTO devices (device_id, display_name, jid, created_at, updated_at) VALUES (?, ?, ?, ?, ?)"), record.DeviceID, record.DisplayName, record.JID, now, now)
+	return err
+}
+
+// deviceColumns are the devices columns read by scanDeviceRecord.
+const deviceColumns = "device_id, display_name, jid, created_at, updated_at, auto_reject_calls, auto_reject_call_message, auto_reply_message, auto_mark_read, COALESCE(webhook_url, ''), COALESCE(webhook_secret, ''), COALESCE(webhook_events, '')"
+
+func scanDeviceRecord(s interface{ Scan(...any) error }) (*domainChatStorage.DeviceRecord, error) {
+	rec := &domainChatStorage.DeviceRecord{}
+	err := s.Scan(&rec.DeviceID, &rec.DisplayName, &rec.JID, &rec.CreatedAt, &rec.UpdatedAt, &rec.AutoRejectCalls, &rec.AutoRejectCallMessage,
+		&rec.AutoReplyMessage, &rec.AutoMarkRead, &rec.WebhookURL, &rec.WebhookSecret, &rec.WebhookEvents)
+	return rec, err
+}
+
+// ListDeviceRecords returns every registered device. Webhook secrets are
+// masked; read a single device with GetDeviceRecord to get its secret.
+func (r *SQLRepository) ListDeviceRecords(ctx context.Context) ([]*domainChatStorage.DeviceRecord, error) {
+	rows, err := r.db.QueryContext(ctx, r.p("SELECT "+deviceColumns+" FROM devices ORDER BY created_at ASC"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var records []*domainChatStorage.DeviceRecord
+	for rows.Next() {
+		rec, err := scanDeviceRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		if rec.WebhookSecret != "" {
+			rec.WebhookSecret = domainChatStorage.MaskedWebhookSecret
+		}
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+func (r *SQLRepository) GetDeviceRecord(ctx context.Context, deviceID string) (*domainChatStorage.DeviceRecord, error) {
+	rec, err := scanDeviceRecord(r.db.QueryRowContext(ctx, r.p("SELECT "+deviceColumns+" FROM devices WHERE device_id = ? LIMIT 1"), deviceID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return rec, err
+}
+
+// SaveDeviceSettings replaces the settings of a device, registering the device
+// when it has no record yet.
+func (r *SQLRepository) SaveDeviceSettings(ctx context.Context, deviceID string, settings domainChatStorage.DeviceSettings) error {
+	now := time.Now()
+	res, err := r.db.ExecContext(ctx, r.p("UPDATE devices SET auto_reject_calls = ?, auto_reject_call_message = ?, auto_reply_message = ?, auto_mark_read = ?, updated_at = ? WHERE device_id = ?"),
+		settings.AutoRejectCalls, settings.AutoRejectCallMessage, settings.AutoReplyMessage, settings.AutoMarkRead, now, deviceID)
+	if err != nil {
+		return err
+	}
+	if aff, _ := res.RowsAffected(); aff > 0 {
+		return nil
+	}
+	_, err = r.db.ExecContext(ctx, r.p("INSERT INTO devices (device_id, display_name, jid, created_at, updated_at, auto_reject_calls, auto_reject_call_message, auto_reply_message, auto_mark_read) VALUES (?, '', '', ?, ?, ?, ?, ?, ?)"),
+		deviceID, now, now, settings.AutoRejectCalls, settings.AutoRejectCallMessage, settings.AutoReplyMessage, settings.AutoMarkRead)
+	return err
+}
+
+// SaveDeviceWebhook replaces the webhook of a device, registering the device
+// when it has no record yet. Empty fields are stored as NULL.
+func (r *SQLRepository) SaveDeviceWebhook(ctx context.Context, deviceID string, webhook domainChatStorage.DeviceWebhook) error {
+	now := time.Now()
+	webhookURL, secret, events := nullIfEmpty(webhook.WebhookURL), nullIfEmpty(webhook.WebhookSecret), nullIfEmpty(webhook.WebhookEvents)
+	res, err := r.db.ExecContext(ctx, r.p("UPDATE devices SET webhook_url = ?, webhook_secret = ?, webhook_events = ?, updated_at = ? WHERE device_id = ?"), webhookURL, secret, events, now, deviceID)
+	if err != nil {
+		return err
+	}
+	if aff, _ := res.RowsAffected(); aff > 0 {
+		return nil
+	}
+	_, err = r.db.ExecContext(ctx, r.p("INSERT INTO devices (device_id, display_name, jid, created_at, updated_at, webhook_url, webhook_secret, webhook_events) VALUES (?, '', '', ?, ?, ?, ?, ?)"), deviceID, now, now, webhookURL, secret, events)
+	return err
+}
+
+// nullIfEmpty binds an empty string as NULL.
+func nullIfEmpty(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func (r *SQLRepository) DeleteDeviceRecord(ctx context.Context, deviceID string) error {
+	_, err := r.db.ExecContext(ctx, r.p("DELETE FROM devices WHERE device_id = ?"), deviceID)
+	return err
+}
+
+const apiKeyColumns = `id, key_hash, label, scopes, device_ids, created_at, last_used_at, revoked`
+
+// StoreAPIKey records a new API key. Scopes and device IDs are kept as JSON
+// arrays.
+func (r *SQLRepository) StoreAPIKey(ctx context.Context, key *domainChatStorage.APIKey) error {
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return err
+	}
+	deviceIDs, err := json.Marshal(key.DeviceIDs)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, r.p("INSERT INTO api_keys ("+apiKeyColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?)"),
+		key.ID, key.KeyHash, key.Label, string(scopes), string(deviceIDs), key.CreatedAt, key.LastUsedAt, key.Revoked)
+	return err
+}
+
+func scanAPIKey(s interface{ Scan(...any) error }) (*domainChatStorage.APIKey, error) {
+	key := &domainChatStorage.APIKey{}
+	var scopes, deviceIDs string
+	var lastUsed sql.NullTime
+	if err := s.Scan(&key.ID, &key.KeyHash, &key.Label, &scopes, &deviceIDs, &key.CreatedAt, &lastUsed, &key.Revoked); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(scopes), &key.Scopes); err != nil {
+		return nil, fmt.Errorf("failed to decode scopes of API key %s: %w", key.ID, err)
+	}
+	if err := json.Unmarshal([]byte(deviceIDs), &key.DeviceIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode devices of API key %s: %w", key.ID, err)
+	}
+	if lastUsed.Valid {
+		key.LastUsedAt = &lastUsed.Time
+	}
+	return key, nil
+}
+
+// ListAPIKeys returns every API key, revoked ones included, oldest first.
+func (r *SQLRepository) ListAPIKeys(ctx context.Context) ([]*domainChatStorage.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, r.p("SELECT "+apiKeyColumns+" FROM api_keys ORDER BY created_at ASC"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var keys []*domainChatStorage.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (r *SQLRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*domainChatStorage.APIKey, error) {
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, r.p("SELECT "+apiKeyColumns+" FROM api_keys WHERE key_hash = ?"), keyHash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return key, err
+}
+
+// RevokeAPIKey marks a key as revoked. The key stays listed so it's clear
+// what was revoked; revoking it again is not an error.
+func (r *SQLRepository) RevokeAPIKey(ctx context.Context, id string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, r.p("UPDATE api_keys SET revoked = ? WHERE id = ?"), true, id)
+	if err != nil {
+		return false, err
+	}
+	aff, err := res.RowsAffected()
+	return aff > 0, err
+}
+
+func (r *SQLRepository) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, r.p("UPDATE api_keys SET last_used_at = ? WHERE id = ?"), usedAt, id)
+	return err
+}
+
+const autoReplyRuleColumns = `id, device_id, position, pattern, match_type, reply_text, only_groups, only_dm, enabled, created_at, updated_at`
+
+// StoreAutoReplyRule creates a rule or replaces the rule with the same ID.
+func (r *SQLRepository) StoreAutoReplyRule(ctx context.Context, rule *domainChatStorage.AutoReplyRule) error {
+	query := "INSERT INTO auto_reply_rules (" + autoReplyRuleColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) " +
+		r.onConflictUpdate("id") +
+		" position = " + r.excluded("position") +
+		", pattern = " + r.excluded("pattern") +
+		", match_type = " + r.excluded("match_type") +
+		", reply_text = " + r.excluded("reply_text") +
+		", only_groups = " + r.excluded("only_groups") +
+		", only_dm = " + r.excluded("only_dm") +
+		", enabled = " + r.excluded("enabled") +
+		", updated_at = " + r.excluded("updated_at")
+	_, err := r.db.ExecContext(ctx, r.p(query), rule.ID, rule.DeviceID, rule.Position, rule.Pattern, rule.MatchType,
+		rule.ReplyText, rule.OnlyGroups, rule.OnlyDM, rule.Enabled, rule.CreatedAt, rule.UpdatedAt)
+	return err
+}
+
+func scanAutoReplyRule(s interface{ Scan(...any) error }) (*domainChatStorage.AutoReplyRule, error) {
+	rule := &domainChatStorage.AutoReplyRule{}
+	err := s.Scan(&rule.ID, &rule.DeviceID, &rule.Position, &rule.Pattern, &rule.MatchType, &rule.ReplyText,
+		&rule.OnlyGroups, &rule.OnlyDM, &rule.Enabled, &rule.CreatedAt, &rule.UpdatedAt)
+	return rule, err
+}
+
+// ListAutoReplyRules returns the rules of a device by position, oldest first
+// among rules sharing a position.
+func (r *SQLRepository) ListAutoReplyRules(ctx context.Context, deviceID string) ([]*domainChatStorage.AutoReplyRule, error) {
+	rows, err := r.db.QueryContext(ctx, r.p("SELECT "+autoReplyRuleColumns+" FROM auto_reply_rules WHERE device_id = ? ORDER BY position ASC, created_at ASC, id ASC"), deviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var rules []*domainChatStorage.AutoReplyRule
+	for rows.Next() {
+		rule, err := scanAutoReplyRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func (r *SQLRepository) GetAutoReplyRule(ctx context.Context, deviceID, id string) (*domainChatStorage.AutoReplyRule, error) {
+	rule, err := scanAutoReplyRule(r.db.QueryRowContext(ctx, r.p("SELECT "+autoReplyRuleColumns+" FROM auto_reply_rules WHERE device_id = ? AND id = ?"), deviceID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return rule, err
+}
+
+func (r *SQLRepository) DeleteAutoReplyRule(ctx context.Context, deviceID, id string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, r.p("DELETE FROM auto_reply_rules WHERE device_id = ? AND id = ?"), deviceID, id)
+	if err != nil {
+		return false, err
+	}
+	aff, err := res.RowsAffected()
+	return aff > 0, err
+}
+
+const labelColumns = `id, device_id, name, color, created_at, updated_at`
+
+// StoreLabel creates a label or replaces the label with the same ID.
+func (r *SQLRepository) StoreLabel(ctx context.Context, label *domainChatStorage.Label) error {
+	query := "INSERT INTO labels (" + labelColumns + ") VALUES (?, ?, ?, ?, ?, ?) " +
+		r.onConflictUpdate("id") +
+		" name = " + r.excluded("name") +
+		", color = " + r.excluded("color") +
+		", updated_at = " + r.excluded("updated_at")
+	_, err := r.db.ExecContext(ctx, r.p(query), label.ID, label.DeviceID, label.Name, label.Color, label.CreatedAt, label.UpdatedAt)
+	return err
+}
+
+func scanLabel(s interface{ Scan(...any) error }, extra ...any) (*domainChatStorage.Label, error) {
+	label := &domainChatStorage.Label{}
+	dest := append([]any{&label.ID, &label.DeviceID, &label.Name, &label.Color, &label.CreatedAt, &label.UpdatedAt}, extra...)
+	return label, s.Scan(dest...)
+}
+
+// ListLabels returns the labels of a device by name, ignoring case like the
+// collation of MySQL does, with the number of chats tagged with each.
+func (r *SQLRepository) ListLabels(ctx context.Context, deviceID string) ([]*domainChatStorage.Label, error) {
+	query := "SELECT " + labelColumns + ", (SELECT COUNT(*) FROM chat_labels cl WHERE cl.label_id = l.id AND cl.device_id = l.device_id)" +
+		" FROM labels l WHERE device_id = ? ORDER BY LOWER(name) ASC, id ASC"
+	rows, err := r.db.QueryContext(ctx, r.p(query), deviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var labels []*domainChatStorage.Label
+	for rows.Next() {
+		var chatCount int
+		label, err := scanLabel(rows, &chatCount)
+		if err != nil {
+			return nil, err
+		}
+		label.ChatCount = chatCount
+		labels = append(labels, label)
+	}
+	return labels, rows.Err()
+}
+
+func (r *SQLRepository) GetLabel(ctx context.Context, deviceID, id string) (*domainChatStorage.Label, error) {
+	label, err := scanLabel(r.db.QueryRowContext(ctx, r.p("SELECT "+labelColumns+" FROM labels WHERE device_id = ? AND id = ?"), deviceID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return label, err
+}
+
+// DeleteLabel deletes a label and untags the chats tagged with it.
+func (r *SQLRepository) DeleteLabel(ctx context.Context, deviceID, id string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, r.p("DELETE FROM chat_labels WHERE label_id = ? AND device_id = ?"), id, deviceID); err != nil {
+		return false, err
+	}
+	res, err := tx.ExecContext(ctx, r.p("DELETE FROM labels WHERE id = ? AND device_id = ?"), id, deviceID)
+	if err != nil {
+		return false, err
+	}
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return aff > 0, tx.Commit()
+}
+
+// SetChatLabels replaces the labels of a chat with labelIDs, which must be
+// labels of the device.
+func (r *SQLRepository) SetChatLabels(ctx context.Context, deviceID, chatJID string, labelIDs []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, r.p("DELETE FROM chat_labels WHERE chat_jid = ? AND device_id = ?"), chatJID, deviceID); err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, labelID := range labelIDs {
+		if _, err := tx.ExecContext(ctx, r.p("INSERT INTO chat_labels (device_id, chat_jid, label_id, created_at) VALUES (?, ?, ?, ?)"), deviceID, chatJID, labelID, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// chatLabelsChunkSize bounds the chat JIDs per query of GetChatLabels.
+const chatLabelsChunkSize = 200
+
+// GetChatLabels returns the labels of each of chatJIDs by name. Chats without
+// labels are left out.
+func (r *SQLRepository) GetChatLabels(ctx context.Context, deviceID string, chatJIDs []string) (map[string][]*domainChatStorage.Label, error) {
+	labels := make(map[string][]*domainChatStorage.Label)
+	for start := 0; start < len(chatJIDs); start += chatLabelsChunkSize {
+		chunk := chatJIDs[start:min(start+chatLabelsChunkSize, len(chatJIDs))]
+		args := make([]any, 0, len(chunk)+1)
+		args = append(args, deviceID)
+		for _, jid := range chunk {
+			args = append(args, jid)
+		}
+		query := "SELECT l.id, l.device_id, l.name, l.color, l.created_at, l.updated_at, cl.chat_jid FROM chat_labels cl" +
+			" JOIN labels l ON l.id = cl.label_id AND l.device_id = cl.device_id" +
+			" WHERE cl.device_id = ? AND cl.chat_jid IN (?" + strings.Repeat(", ?", len(chunk)-1) + ") ORDER BY LOWER(l.name) ASC, l.id ASC"
+		if err := r.scanChatLabels(ctx, query, args, labels); err != nil {
+			return nil, err
+		}
+	}
+	return labels, nil
+}
+
+func (r *SQLRepository) scanChatLabels(ctx context.Context, query string, args []any, labels map[string][]*domainChatStorage.Label) error {
+	rows, err := r.db.QueryContext(ctx, r.p(query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var chatJID string
+		label, err := scanLabel(rows, &chatJID)
+		if err != nil {
+			return err
+		}
+		labels[chatJID] = append(labels[chatJID], label)
+	}
+	return rows.Err()
+}
+
+const templateColumns = `device_id, name, body, created_at, updated_at`
+
+// StoreTemplate creates a template or replaces the body of the device's
+// template with the same name.
+func (r *SQLRepository) StoreTemplate(ctx context.Context, tmpl *domainChatStorage.Template) error {
+	query := "INSERT INTO templates (" + templateColumns + ") VALUES (?, ?, ?, ?, ?) " +
+		r.onConflictUpdate("name, device_id") +
+		" body = " + r.excluded("body") +
+		", updated_at = " + r.excluded("updated_at")
+	_, err := r.db.ExecContext(ctx, r.p(query), tmpl.DeviceID, tmpl.Name, tmpl.Body, tmpl.CreatedAt, tmpl.UpdatedAt)
+	return err
+}
+
+func scanTemplate(s interface{ Scan(...any) error }) (*domainChatStorage.Template, error) {
+	tmpl := &domainChatStorage.Template{}
+	err := s.Scan(&tmpl.DeviceID, &tmpl.Name, &tmpl.Body, &tmpl.CreatedAt, &tmpl.UpdatedAt)
+	return tmpl, err
+}
+
+func (r *SQLRepository) ListTemplates(ctx context.Context, deviceID string) ([]*domainChatStorage.Template, error) {
+	rows, err := r.db.QueryContext(ctx, r.p("SELECT "+templateColumns+" FROM templates WHERE device_id = ? ORDER BY name ASC"), deviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var templates []*domainChatStorage.Template
+	for rows.Next() {
+		tmpl, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, tmpl)
+	}
+	return templates, rows.Err()
+}
+
+func (r *SQLRepository) GetTemplate(ctx context.Context, deviceID, name string) (*domainChatStorage.Template, error) {
+	tmpl, err := scanTemplate(r.db.QueryRowContext(ctx, r.p("SELECT "+templateColumns+" FROM templates WHERE device_id = ? AND name = ?"), deviceID, name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return tmpl, err
+}
+
+// DeleteTemplate deletes a template. Messages rendered from it keep its name.
+func (r *SQLRepository) DeleteTemplate(ctx context.Context, deviceID, name string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, r.p("DELETE FROM templates WHERE device_id = ? AND name = ?"), deviceID, name)
+	if err != nil {
+		return false, err
+	}
+	aff, err := res.RowsAffected()
+	return aff > 0, err
+}
+
+const jobColumns = `id, device_id, type, status, progress, result, error, created_at, updated_at`
+
+// StoreJob creates a job or updates the state of the job with the same ID.
+func (r *SQLRepository) StoreJob(ctx context.Context, job *domainChatStorage.Job) error {
+	query := "INSERT INTO jobs (" + jobColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) " +
+		r.onConflictUpdate("id") +
+		" status = " + r.excluded("status") +
+		", progress = " + r.excluded("progress") +
+		", result = " + r.excluded("result") +
+		", error = " + r.excluded("error") +
+		", updated_at = " + r.excluded("updated_at")
+	_, err := r.db.ExecContext(ctx, r.p(query), job.ID, job.DeviceID, job.Type, job.Status, job.Progress, job.Result, job.Error, job.CreatedAt, job.UpdatedAt)
+	return err
+}
+
+func scanJob(s interface{ Scan(...any) error }) (*domainChatStorage.Job, error) {
+	job := &domainChatStorage.Job{}
+	var result, jobErr sql.NullString
+	err := s.Scan(&job.ID, &job.DeviceID, &job.Type, &job.Status, &job.Progress, &result, &jobErr, &job.CreatedAt, &job.UpdatedAt)
+	job.Result, job.Error = result.String, jobErr.String
+	return job, err
+}
+
+func (r *SQLRepository) GetJob(ctx context.Context, deviceID, id string) (*domainChatStorage.Job, error) {
+	job, err := scanJob(r.db.QueryRowContext(ctx, r.p("SELECT "+jobColumns+" FROM jobs WHERE device_id = ? AND id = ?"), deviceID, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return job, err
+}
+
+func (r *SQLRepository) ListJobs(ctx context.Context, filter *domainChatStorage.JobFilter) ([]*domainChatStorage.Job, error) {
+	query := "SELECT " + jobColumns + " FROM jobs WHERE device_id = ?"
+	args := []any{filter.DeviceID}
+	if filter.Type != "" {
+		query += " AND type = ?"
+		args = append(args, filter.Type)
+	}
+	if filter.Status != "" {
+		query += " AND status = ?"
+		args = append(args, filter.Status)
+	}
+	query += " ORDER BY created_at DESC, id ASC"
+	page, pageArgs := r.limitOffset(filter.Limit, filter.Offset)
+	query += page
+	args = append(args, pageArgs...)
+
+	rows, err := r.db.QueryContext(ctx, r.p(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var jobs []*domainChatStorage.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func (r *SQLRepository) DeleteJob(ctx context.Context, deviceID, id string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, r.p("DELETE FROM jobs WHERE device_id = ? AND id = ?"), deviceID, id)
+	if err != nil {
+		return false, err
+	}
+	aff, err := res.RowsAffected()
+	return aff > 0, err
+}
+
+// InterruptJobs marks the jobs a previous run of the server left queued or
+// running as interrupted, as nothing will finish them anymore.
+func (r *SQLRepository) InterruptJobs(ctx context.Context, reason string) (int64, error) {
+	res, err := r.db.ExecContext(ctx, r.p("UPDATE jobs SET status = ?, error = ?, updated_at = ? WHERE status IN (?, ?)"),
+		domainChatStorage.JobStatusInterrupted, reason, time.Now(), domainChatStorage.JobStatusQueued, domainChatStorage.JobStatusRunning)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// defaultPinDuration is how long WhatsApp keeps a message pinned when the pin
+// doesn't say.
+const defaultPinDuration = 7 * 24 * time.Hour
+
+// pinDuration reads the duration of a pin from the context info of the
+// unwrapped message or, failing that, the message it came in.
+func pinDuration(messages ...*waE2E.Message) time.Duration {
+	for _, msg := range messages {
+		if seconds := msg.GetMessageContextInfo().GetMessageAddOnDurationInSecs(); seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return defaultPinDuration
+}
+
+func (r *SQLRepository) CreateMessage(ctx context.Context, evt *events.Message) error {
+	client := whatsapp.ClientFromContext(ctx)
+	deviceID := deviceIDFromContext(ctx)
+
+	normalizedChatJID := whatsapp.NormalizeJIDFromLID(ctx, evt.Info.Chat, client)
+	chatJID := canonicalChatJID(normalizedChatJID.String())
+
+	// Reactions, edits and revocations annotate an existing message rather than being messages themselves
+	inner := utils.UnwrapMessage(evt.Message)
+	if protocol := inner.GetProtocolMessage(); protocol != nil {
+		switch {
+		case protocol.GetType() == waE2E.ProtocolMessage_MESSAGE_EDIT && protocol.GetEditedMessage() != nil:
+			return r.StoreMessageEdit(ctx, deviceID, protocol.GetKey().GetID(), chatJID,
+				utils.ExtractMessageTextFromProto(protocol.GetEditedMessage()), evt.Info.Timestamp)
+		case protocol.GetType() == waE2E.ProtocolMessage_REVOKE:
+			return r.MarkMessageRevoked(ctx, deviceID, protocol.GetKey().GetID(), chatJID, config.ChatStorageKeepRevoked)
+		}
+	}
+	if pin := inner.GetPinInChatMessage(); pin != nil {
+		var pinnedUntil *time.Time
+		if pin.GetType() == waE2E.PinInChatMessage_PIN_FOR_ALL {
+			until := evt.Info.Timestamp.Add(pinDuration(inner, evt.Message))
+			pinnedUntil = &until
+		}
+		return r.SetMessagePinnedUntil(ctx, deviceID, pin.GetKey().GetID(), chatJID, pinnedUntil)
+	}
+	if reaction := inner.GetReactionMessage(); reaction != nil {
+		return r.StoreReaction(ctx, &domainChatStorage.Reaction{
+			MessageID: reaction.GetKey().GetID(),
+			ChatJID:   chatJID,
+			DeviceID:  deviceID,
+			Sender:    evt.Info.Sender.ToNonAD().String(),
+			Emoji:     reaction.GetText(),
+			Timestamp: evt.Info.Timestamp,
+		})
+	}
+	// Poll votes are encrypted; the event handler decrypts and stores them with StorePollVote
+	if inner.GetPollUpdateMessage() != nil {
+		return nil
+	}
+
+	chatName := r.GetChatNameWithPushNameByDevice(deviceID, normalizedChatJID, chatJID, evt.Info.Sender.User, evt.Info.PushName)
+	switch {
+	case normalizedChatJID.Server == types.NewsletterServer:
+		chatName = r.newsletterName(ctx, client, deviceID, normalizedChatJID)
+	case normalizedChatJID == types.StatusBroadcastJID:
+		// Statuses of every contact share one chat, so it isn't named after the poster
+		chatName = chatNameFromJID(chatJID)
+	}
+	chat := &domainChatStorage.Chat{
+		DeviceID:        deviceID,
+		JID:             chatJID,
+		Name:            chatName,
+		LastMessageTime: evt.Info.Timestamp,
+	}
+	if normalizedChatJID.Server == types.GroupServer {
+		chat.ChatType = r.groupChatType(ctx, client, deviceID, normalizedChatJID)
+	}
+	_ = r.StoreChat(ctx, chat)
+
+	content := utils.ExtractMessageTextFromProto(evt.Message)
+	mType, fName, url, mKey, fSha, fEncSha, fLen := utils.ExtractMediaInfo(evt.Message)
+	location := whatsapp.ExtractLocation(evt.Message)
+	contacts := whatsapp.ExtractContactCards(evt.Message)
+	replyToID, replyToSender := utils.ExtractReplyContext(evt.Message)
+	if replyToSender != "" {
+		if jid, err := types.ParseJID(replyToSender); err == nil {
+			replyToSender = whatsapp.NormalizeJIDFromLID(ctx, jid, client).ToNonAD().String()
+		}
+	}
+
+	message := &domainChatStorage.Message{
+		ID: evt.Info.ID, ChatJID: chatJID, DeviceID: deviceID, Sender: evt.Info.Sender.String(),
+		Content: content, Timestamp: evt.Info.Timestamp, IsFromMe: evt.Info.IsFromMe,
+		MediaType: mType, Filename: fName, URL: url, MediaKey: mKey, FileSHA256: fSha, FileEncSHA256: fEncSha, FileLength: fLen,
+		ReplyToID: replyToID, ReplyToSender: replyToSender, Location: location, Contacts: contacts,
+		IsAnimated: inner.GetStickerMessage().GetIsAnimated(),
+		IsViewOnce: evt.IsViewOnce || utils.IsViewOnceMessage(evt.Message),
+		ServerID:   int64(evt.Info.ServerID),
+	}
+	if mType == "live_location" {
+		updated, err := r.updateLiveLocation(ctx, message)
+		if err != nil || updated {
+			return err
+		}
+	}
+	if err := r.StoreMessage(ctx, message); err != nil {
+		return err
+	}
+	if poll := utils.ExtractPollCreation(evt.Message); poll != nil {
+		if err := r.StorePoll(ctx, &domainChatStorage.Poll{
+			MessageID: evt.Info.ID, ChatJID: chatJID, DeviceID: deviceID,
+			Question: poll.GetName(), Options: utils.PollOptionNames(poll), SelectableCount: int(poll.GetSelectableOptionsCount()),
+		}); err != nil {
+			return err
+		}
+	}
+
+	// Every incoming message is counted; whether it is marked read
+	// automatically depends on the device's settings, and handleAutoMarkRead
+	// resets the count when it is
+	if message.IsFromMe || (message.Content == "" && message.MediaType == "") {
+		return nil
+	}
+	// Every member of a community can reply in its announcement group, but
+	// only the announcements of its admins count as unread
+	if chat.ChatType == domainChatStorage.ChatTypeCommunityAnnounce &&
+		!r.isAnnouncer(ctx, deviceID, chatJID, whatsapp.NormalizeJIDFromLID(ctx, evt.Info.Sender, client).ToNonAD().String()) {
+		return nil
+	}
+	if _, err := r.db.ExecContext(ctx, r.p("UPDATE messages SET is_unread = ? WHERE id = ? AND chat_jid = ? AND device_id = ?"), true, message.ID, chatJID, deviceID); err != nil {
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, r.p("UPDATE chats SET unread_count = unread_count + 1 WHERE jid = ? AND device_id = ?"), chatJID, deviceID)
+	return err
+}
+
+// updateLiveLocation moves the latest live location the sender shared in the
+// chat to the position of message, if message continues its sequence. It
+// reports false when message starts a new live location, which is then stored
+// as a message of its own.
+func (r *SQLRepository) updateLiveLocation(ctx context.Context, message *domainChatStorage.Message) (bool, error) {
+	var id string
+	var stored sql.NullString
+	err := r.db.QueryRowContext(ctx, r.p("SELECT id, location FROM messages WHERE chat_jid = ? AND device_id = ? AND sender = ? AND media_type = 'live_location' AND id <> ? ORDER BY timestamp DESC LIMIT 1"),
+		message.ChatJID, message.DeviceID, message.Sender, message.ID).Scan(&id, &stored)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	var previous domainChatStorage.Location
+	if stored.String != "" {
+		if err := json.Unmarshal([]byte(stored.String), &previous); err != nil {
+			return false, fmt.Errorf("failed to decode location of message %s: %w", id, err)
+		}
+	}
+	if message.Location.SequenceNumber <= previous.SequenceNumber {
+		return false, nil
+	}
+
+	_, err = r.db.ExecContext(ctx, r.p("UPDATE messages SET location = ?, updated_at = ? WHERE id = ? AND chat_jid = ? AND device_id = ?"),
+		encodeJSONColumn(message.Location), time.Now(), id, message.ChatJID, message.DeviceID)
+	return err == nil, err
+}
+
+func (r *SQLRepository) GetChatNameWithPushName(jid types.JID, chatJID string, senderUser string, pushName string) string {
+	return r.GetChatNameWithPushNameByDevice("", jid, chatJID, senderUser, pushName)
+}
+
+// GetChatNameWithPushNameByDevice prefers the full name from the synced contact
+// store over the sender's push name, which anyone can set.
+func (r *SQLRepository) GetChatNameWithPushNameByDevice(deviceID string, jid types.JID, chatJID string, senderUser string, pushName string) string {
+	if name := r.contactFullName(context.Background(), deviceID, chatJID); name != "" {
+		return name
+	}
+	if pushName != "" {
+		return pushName
+	}
+	return jid.User
+}
+
+// groupChatType returns the stored type of a group chat. Groups that are not
+// stored yet are looked up once, so a community announcement group is marked
+// as one from its first message.
+func (r *SQLRepository) groupChatType(ctx context.Context, client *whatsmeow.Client, deviceID string, jid types.JID) string {
+	var chatType string
+	err := r.db.QueryRowContext(ctx, r.p("SELECT chat_type FROM chats WHERE jid = ? AND device_id = ?"), jid.String(), deviceID).Scan(&chatType)
+	if err != sql.ErrNoRows || client == nil {
+		return chatType
+	}
+	if info, err := client.GetGroupInfo(ctx, jid); err == nil {
+		return whatsapp.CommunityChatType(info)
+	}
+	return ""
+}
+
+// isAnnouncer reports whether sender is a cached admin of the group. Without
+// cached participants the admins are unknown, so everyone counts as one.
+func (r *SQLRepository) isAnnouncer(ctx context.Context, deviceID, groupJID, sender string) bool {
+	var cached, admin int
+	err := r.db.QueryRowContext(ctx, r.p("SELECT COUNT(*), COALESCE(SUM(CASE WHEN participant_jid = ? AND (is_admin OR is_superadmin) THEN 1 ELSE 0 END), 0) FROM group_participants WHERE group_jid = ? AND device_id = ?"),
+		sender, groupJID, deviceID).Scan(&cached, &admin)
+	return err != nil || cached == 0 || admin > 0
+}
+
+// newsletterName returns the name of a channel. Channel messages carry no push
+// name, so it is looked up once from the newsletter metadata and then reused
+// from the stored chat.
+func (r *SQLRepository) newsletterName(ctx context.Context, client *whatsmeow.Client, deviceID string, jid types.JID) string {
+	if chat, err := r.GetChatByDevice(ctx, deviceID, jid.String()); err == nil && chat != nil && chat.Name != "" && chat.Name != jid.User {
+		return chat.Name
+	}
+	if client != nil {
+		if metadata, err := client.GetNewsletterInfo(ctx, jid); err == nil && metadata != nil && metadata.ThreadMeta.Name.Text != "" {
+			return metadata.ThreadMeta.Name.Text
+		}
+	}
+	return jid.User
+}
+
+// Ping checks that the database is reachable.
+func (r *SQLRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+func (r *SQLRepository) InitializeSchema(ctx context.Context) error {
+	return r.migrate(ctx, r.getMigrations())
+}
+
+// migrate applies every migration newer than the recorded schema version. Each
+// one runs in its own transaction together with its schema_info row, so a
+// failure leaves the version untouched and the next start retries it.
+func (r *SQLRepository) migrate(ctx context.Context, migrations []string) error {
+	current, err := r.getSchemaVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	for i, migration := range migrations {
+		version := i + 1
+		if version <= current {
+			continue
+		}
+		if err := r.applyMigration(ctx, version, migration); err != nil {
+			return fmt.Errorf("failed to apply migration %d: %w", version, err)
+		}
+	}
+	return nil
+}
+
+func (r *SQLRepository) applyMigration(ctx context.Context, version int, migration string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, r.p("INSERT INTO schema_info (version) VALUES (?)"), version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SQLRepository) getSchemaVersion(ctx context.Context) (int, error) {
+	if _, err := r.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_info (version INTEGER PRIMARY KEY, updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)`); err != nil {
+		return 0, err
+	}
+	var v int
+	if err := r.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_info`).Scan(&v); err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+func (r *SQLRepository) getMigrations() []string {
+	if r.dialect == dialectMySQL {
+		return mysqlMigrations
+	}
+	blobType := "BLOB"
+	// Full-text search is Postgres only; other drivers keep the migration slot
+	// with a no-op so versions line up.
+	ftsIndex := "SELECT 1"
+	if r.dialect == dialectPostgres {
+		blobType = "BYTEA"
+		ftsIndex = "CREATE INDEX IF NOT EXISTS idx_messages_content_fts ON messages USING GIN (" + contentTSVector("content") + ")"
+	}
+	return []string{
+		`CREATE TABLE IF NOT EXISTS chats (jid VARCHAR(255), device_id VARCHAR(255) DEFAULT '', name VARCHAR(255), last_message_time TIMESTAMP, ephemeral_expiration INTEGER DEFAULT 0, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (jid, device_id))`,
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS messages (id VARCHAR(255), chat_jid VARCHAR(255), device_id VARCHAR(255) DEFAULT '', sender VARCHAR(255), content TEXT, timestamp TIMESTAMP, is_from_me BOOLEAN DEFAULT FALSE, media_type VARCHAR(50), filename VARCHAR(255), url TEXT, media_key %s, file_sha256 %s, file_enc_sha256 %s, file_length INTEGER DEFAULT 0, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (id, chat_jid, device_id))`, blobType, blobType, blobType),
+		`CREATE TABLE IF NOT EXISTS devices (device_id VARCHAR(255) PRIMARY KEY, display_name VARCHAR(255) DEFAULT '', jid VARCHAR(255) DEFAULT '', created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)`,
+		`CREATE INDEX IF NOT EXISTS idx_messages_chat_device_media ON messages (chat_jid, device_id, media_type)`,
+		ftsIndex,
+		`CREATE INDEX IF NOT EXISTS idx_messages_chat_device_timestamp ON messages (chat_jid, device_id, timestamp DESC)`,
+		`CREATE INDEX IF NOT EXISTS idx_messages_device_timestamp ON messages (device_id, timestamp)`,
+		`CREATE INDEX IF NOT EXISTS idx_chats_device_last_message ON chats (device_id, last_message_time DESC)`,
+		`CREATE INDEX IF NOT EXISTS idx_messages_sender ON messages (sender)`,
+		`CREATE TABLE IF NOT EXISTS reactions (message_id VARCHAR(128), chat_jid VARCHAR(255), device_id VARCHAR(255) DEFAULT '', sender VARCHAR(128), emoji VARCHAR(64), timestamp TIMESTAMP, PRIMARY KEY (message_id, chat_jid, device_id, sender))`,
+		`ALTER TABLE messages ADD COLUMN edited_at TIMESTAMP NULL`,
+		`CREATE TABLE IF NOT EXISTS message_edits (message_id VARCHAR(128), chat_jid VARCHAR(255), device_id VARCHAR(255) DEFAULT '', content TEXT, edited_at TIMESTAMP, PRIMARY KEY (message_id, chat_jid, device_id, edited_at))`,
+		`ALTER TABLE messages ADD COLUMN is_deleted BOOLEAN DEFAULT FALSE`,
+		`ALTER TABLE messages ADD COLUMN reply_to_id VARCHAR(255) DEFAULT ''`,
+		`ALTER TABLE messages ADD COLUMN reply_to_sender VARCHAR(255) DEFAULT ''`,
+		`CREATE TABLE IF NOT EXISTS receipts (message_id VARCHAR(128), chat_jid VARCHAR(255), device_id VARCHAR(255) DEFAULT '', recipient VARCHAR(112), type VARCHAR(16), timestamp TIMESTAMP, PRIMARY KEY (message_id, chat_jid, device_id, recipient, type))`,
+		`ALTER TABLE chats ADD COLUMN archived BOOLEAN DEFAULT FALSE`,
+		`ALTER TABLE chats ADD COLUMN pinned BOOLEAN DEFAULT FALSE`,
+		`ALTER TABLE chats ADD COLUMN muted_until TIMESTAMP NULL`,
+		`ALTER TABLE chats ADD COLUMN unread_count INTEGER DEFAULT 0`,
+		`CREATE TABLE IF NOT EXISTS contacts (device_id VARCHAR(255) DEFAULT '', jid VARCHAR(255), full_name VARCHAR(255) DEFAULT '', first_name VARCHAR(255) DEFAULT '', push_name VARCHAR(255) DEFAULT '', business_name VARCHAR(255) DEFAULT '', updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (jid, device_id))`,
+		`CREATE TABLE IF NOT EXISTS group_participants (device_id VARCHAR(255) DEFAULT '', group_jid VARCHAR(255), participant_jid VARCHAR(255), is_admin BOOLEAN DEFAULT FALSE, is_superadmin BOOLEAN DEFAULT FALSE, joined_at TIMESTAMP NULL, PRIMARY KEY (group_jid, device_id, participant_jid))`,
+		`CREATE TABLE IF NOT EXISTS lid_mappings (device_id VARCHAR(255) DEFAULT '', lid VARCHAR(255), pn_jid VARCHAR(255), updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (lid, device_id))`,
+		`CREATE TABLE IF NOT EXISTS polls (message_id VARCHAR(128), chat_jid VARCHAR(255), device_id VARCHAR(255) DEFAULT '', question TEXT, options TEXT, selectable_count INTEGER DEFAULT 0, PRIMARY KEY (message_id, chat_jid, device_id))`,
+		`CREATE TABLE IF NOT EXISTS poll_votes (message_id VARCHAR(128), chat_jid VARCHAR(255), device_id VARCHAR(255) DEFAULT '', voter VARCHAR(128), selected_options TEXT, timestamp TIMESTAMP, PRIMARY KEY (message_id, chat_jid, device_id, voter))`,
+		`ALTER TABLE messages ADD COLUMN location TEXT NULL`,
+		`ALTER TABLE messages ADD COLUMN contacts TEXT NULL`,
+		`ALTER TABLE messages ADD COLUMN media_path TEXT NULL`,
+		`ALTER TABLE messages ADD COLUMN downloaded_at TIMESTAMP NULL`,
+		`ALTER TABLE messages ADD COLUMN is_animated BOOLEAN DEFAULT FALSE`,
+		`ALTER TABLE messages ADD COLUMN is_view_once BOOLEAN DEFAULT FALSE`,
+		`ALTER TABLE messages ADD COLUMN server_id BIGINT DEFAULT 0`,
+		`CREATE TABLE IF NOT EXISTS calls (device_id VARCHAR(255) DEFAULT '', call_id VARCHAR(128), from_jid VARCHAR(255), timestamp TIMESTAMP, is_video BOOLEAN DEFAULT FALSE, outcome VARCHAR(16) DEFAULT 'ringing', duration INTEGER DEFAULT 0, accepted_at TIMESTAMP NULL, PRIMARY KEY (call_id, device_id))`,
+		`CREATE INDEX IF NOT EXISTS idx_calls_device_timestamp ON calls (device_id, timestamp DESC)`,
+		`ALTER TABLE devices ADD COLUMN auto_reject_calls BOOLEAN NULL`,
+		`ALTER TABLE devices ADD COLUMN auto_reject_call_message TEXT NULL`,
+		`ALTER TABLE devices ADD COLUMN webhook_url TEXT NULL`,
+		`ALTER TABLE devices ADD COLUMN webhook_secret TEXT NULL`,
+		`ALTER TABLE devices ADD COLUMN webhook_events TEXT NULL`,
+		`ALTER TABLE devices ADD COLUMN auto_reply_message TEXT NULL`,
+		`ALTER TABLE devices ADD COLUMN auto_mark_read BOOLEAN NULL`,
+		`ALTER TABLE messages ADD COLUMN pinned_until TIMESTAMP NULL`,
+		`ALTER TABLE messages ADD COLUMN is_unread BOOLEAN DEFAULT FALSE`,
+		`ALTER TABLE chats ADD COLUMN left_at TIMESTAMP NULL`,
+		`ALTER TABLE chats ADD COLUMN chat_type VARCHAR(32) DEFAULT ''`,
+		`ALTER TABLE chats ADD COLUMN blocked BOOLEAN DEFAULT FALSE`,
+		`CREATE TABLE IF NOT EXISTS api_keys (id VARCHAR(64) PRIMARY KEY, key_hash VARCHAR(64) UNIQUE, label VARCHAR(255) DEFAULT '', scopes TEXT, device_ids TEXT, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, last_used_at TIMESTAMP NULL, revoked BOOLEAN DEFAULT FALSE)`,
+		`ALTER TABLE messages ADD COLUMN request_id VARCHAR(64) DEFAULT ''`,
+		`CREATE TABLE IF NOT EXISTS auto_reply_rules (id VARCHAR(64) PRIMARY KEY, device_id VARCHAR(255) DEFAULT '', position INTEGER DEFAULT 0, pattern TEXT, match_type VARCHAR(16) DEFAULT 'contains', reply_text TEXT, only_groups BOOLEAN DEFAULT FALSE, only_dm BOOLEAN DEFAULT FALSE, enabled BOOLEAN DEFAULT TRUE, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)`,
+		`CREATE INDEX IF NOT EXISTS idx_auto_reply_rules_device_position ON auto_reply_rules (device_id, position)`,
+		`CREATE TABLE IF NOT EXISTS labels (id VARCHAR(64) PRIMARY KEY, device_id VARCHAR(255) DEFAULT '', name VARCHAR(255), color VARCHAR(16) DEFAULT '', created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)`,
+		`CREATE INDEX IF NOT EXISTS idx_labels_device ON labels (device_id)`,
+		`CREATE TABLE IF NOT EXISTS chat_labels (device_id VARCHAR(255) DEFAULT '', chat_jid VARCHAR(255), label_id VARCHAR(64), created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (chat_jid, device_id, label_id))`,
+		`CREATE INDEX IF NOT EXISTS idx_chat_labels_label ON chat_labels (label_id)`,
+		`CREATE TABLE IF NOT EXISTS templates (device_id VARCHAR(255) DEFAULT '', name VARCHAR(64), body TEXT, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (name, device_id))`,
+		`ALTER TABLE messages ADD COLUMN template_name VARCHAR(64) DEFAULT ''`,
+		`CREATE TABLE IF NOT EXISTS jobs (id VARCHAR(64) PRIMARY KEY, device_id VARCHAR(255) DEFAULT '', type VARCHAR(32), status VARCHAR(16), progress INTEGER DEFAULT 0, result TEXT, error TEXT, created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)`,
+		`CREATE INDEX IF NOT EXISTS idx_jobs_device_created ON jobs (device_id, created_at DESC)`,
+	}
+}
+
+// mysqlMigrations mirror getMigrations slot for slot. MySQL has no CREATE INDEX
+// IF NOT EXISTS, uses DATETIME to avoid TIMESTAMP's 2038 limit and implicitly
+// commits DDL, so a failed migration here may need manual cleanup before retry.
+var mysqlMigrations = []string{
+	"CREATE TABLE IF NOT EXISTS `chats` (`jid` VARCHAR(255), `device_id` VARCHAR(255) DEFAULT '', `name` VARCHAR(255), `last_message_time` DATETIME(6), `ephemeral_expiration` INTEGER DEFAULT 0, `created_at` DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6), `updated_at` DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6), PRIMARY KEY (`jid`, `device_id`)) DEFAULT CHARSET=utf8mb4",
+	"CREATE TABLE IF NOT EXISTS `messages` (`id` VARCHAR(255), `chat_jid` VARCHAR(255), `device_id` VARCHAR(255) DEFAULT '', `sender` VARCHAR(255), `content` MEDIUMTEXT, `timestamp` DATETIME(6), `is_from_me` BOOLEAN DEFAULT FALSE, `media_type` VARCHAR(50), `filename` VARCHAR(255), `url` TEXT, `media_key` BLOB, `file_sha256` BLOB, `file_enc_sha256` BLOB, `file_length` BIGINT DEFAULT 0, `created_at` DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6), `updated_at` DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6), PRIMARY KEY (`id`, `chat_jid`, `device_id`)) DEFAULT CHARSET=utf8mb4",
+	"CREATE TABLE IF NOT EXISTS `devices` (`device_id` VARCHAR(255) PRIMARY KEY, `display_name` VARCHAR(255) DEFAULT '', `jid` VARCHAR(255) DEFAULT '', `created_at` DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6), `updated_at` DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6)) DEFAULT CHARSET=utf8mb4",
+	"CREATE INDEX `idx_messages_chat_device_media` ON `messages` (`chat_jid`, `device_id`, `media_type`)",
+	"SELECT 1",
+	"CREATE INDEX `idx_messages_chat_device_timestamp` ON `messages` (`chat_jid`, `device_id`, `timestamp` DESC)",
+	"CREATE INDEX `idx_messages_device_timestamp` ON `messages` (`device_id`, `timestamp`)",
+	"CREATE INDEX `idx_chats_device_last_message` ON `chats` (`device_id`, `last_message_time` DESC)",
+	"CREATE INDEX `idx_messages_sender` ON `messages` (`sender`)",
+	// message_id and sender are narrower so the key fits InnoDB's 3072 byte limit in utf8mb4
+	"CREATE TABLE IF NOT EXISTS `reactions` (`message_id` VARCHAR(128), `chat_jid` VARCHAR(255), `device_id` VARCHAR(255) DEFAULT '', `sender` VARCHAR(128), `emoji` VARCHAR(64), `timestamp` DATETIME(6), PRIMARY KEY (`message_id`, `chat_jid`, `device_id`, `sender`)) DEFAULT CHARSET=utf8mb4",
+	"ALTER TABLE `messages` ADD COLUMN `edited_at` DATETIME(6) NULL",
+	"CREATE TABLE IF NOT EXISTS `message_edits` (`message_id` VARCHAR(128), `chat_jid` VARCHAR(255), `device_id` VARCHAR(255) DEFAULT '', `content` MEDIUMTEXT, `edited_at` DATETIME(6), PRIMARY KEY (`message_id`, `chat_jid`, `device_id`, `edited_at`)) DEFAULT CHARSET=utf8mb4",
+	"ALTER TABLE `messages` ADD COLUMN `is_deleted` BOOLEAN DEFAULT FALSE",
+	"ALTER TABLE `messages` ADD COLUMN `reply_to_id` VARCHAR(255) DEFAULT ''",
+	"ALTER TABLE `messages` ADD COLUMN `reply_to_sender` VARCHAR(255) DEFAULT ''",
+	// Like reactions, the key is sized to stay within InnoDB's 3072 byte limit
+	"CREATE TABLE IF NOT EXISTS `receipts` (`message_id` VARCHAR(128), `chat_jid` VARCHAR(255), `device_id` VARCHAR(255) DEFAULT '', `recipient` VARCHAR(112), `type` VARCHAR(16), `timestamp` DATETIME(6), PRIMARY KEY (`message_id`, `chat_jid`, `device_id`, `recipient`, `type`)) DEFAULT CHARSET=utf8mb4",
+	"ALTER TABLE `chats` ADD COLUMN `archived` BOOLEAN DEFAULT FALSE",
+	"ALTER TABLE `chats` ADD COLUMN `pinned` BOOLEAN DEFAULT FALSE",
+	"ALTER TABLE `chats` ADD COLUMN `muted_until` DATETIME(6) NULL",
+	"ALTER TABLE `chats` ADD COLUMN `unread_count` INTEGER DEFAULT 0",
+	"CREATE TABLE IF NOT EXISTS `contacts` (`device_id` VARCHAR(255) DEFAULT '', `jid` VARCHAR(255), `full_name` VARCHAR(255) DEFAULT '', `first_name` VARCHAR(255) DEFAULT '', `push_name` VARCHAR(255) DEFAULT '', `business_name` VARCHAR(255) DEFAULT '', `updated_at` DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6), PRIMARY KEY (`jid`, `device_id`)) DEFAULT CHARSET=utf8mb4",
+	"CREATE TABLE IF NOT EXISTS `group_participants` (`device_id` VARCHAR(255) DEFAULT '', `group_jid` VARCHAR(255), `participant_jid` VARCHAR(255), `is_admin` BOOLEAN DEFAULT FALSE, `is_superadmin` BOOLEAN DEFAULT FALSE, `joined_at` DATETIME(6) NULL, PRIMARY KEY (`group_jid`, `device_id`, `participant_jid`)) DEFAULT CHARSET=utf8mb4",
+	"CREATE TABLE IF NOT EXISTS `lid_mappings` (`device_id` VARCHAR(255) DEFAULT '', `lid` VARCHAR(255), `pn_jid` VARCHAR(255), `updated_at` DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6), PRIMARY KEY (`lid`, `device_id`)) DEFAULT CHARSET=utf8mb4",
+	"CREATE TABLE IF NOT EXISTS `polls` (`message_id` VARCHAR(128), `chat_jid` VARCHAR(255), `device_id` VARCHAR(255) DEFAULT '', `question` TEXT, `options` MEDIUMTEXT, `selectable_count` INTEGER DEFAULT 0, PRIMARY KEY (`message_id`, `chat_jid`, `device_id`)) DEFAULT CHARSET=utf8mb4",
+	// Like reactions, the key is sized to stay within InnoDB's 3072 byte limit
+	"CREATE TABLE IF NOT EXISTS `poll_votes` (`message_id` VARCHAR(128), `chat_jid` VARCHAR(255), `device_id` VARCHAR(255) DEFAULT '', `voter` VARCHAR(128), `selected_options` TEXT, `timestamp` DATETIME(6), PRIMARY KEY (`message_id`, `chat_jid`, `device_id`, `voter`)) DEFAULT CHARSET=utf8mb4",
+	"ALTER TABLE `messages` ADD COLUMN `location` TEXT NULL",
+	"ALTER TABLE `messages` ADD COLUMN `contacts` MEDIUMTEXT NULL",
+	"ALTER TABLE `messages` ADD COLUMN `media_path` TEXT NULL",
+	"ALTER TABLE `messages` ADD COLUMN `downloaded_at` DATETIME(6) NULL",
+	"ALTER TABLE `messages` ADD COLUMN `is_animated` BOOLEAN DEFAULT FALSE",
+	"ALTER TABLE `messages` ADD COLUMN `is_view_once` BOOLEAN DEFAULT FALSE",
+	"ALTER TABLE `messages` ADD COLUMN `server_id` BIGINT DEFAULT 0",
+	"CREATE TABLE IF NOT EXISTS `calls` (`device_id` VARCHAR(255) DEFAULT '', `call_id` VARCHAR(128), `from_jid` VARCHAR(255), `timestamp` DATETIME(6), `is_video` BOOLEAN DEFAULT FALSE, `outcome` VARCHAR(16) DEFAULT 'ringing', `duration` INTEGER DEFAULT 0, `accepted_at` DATETIME(6) NULL, PRIMARY KEY (`call_id`, `device_id`)) DEFAULT CHARSET=utf8mb4",
+	"CREATE INDEX `idx_calls_device_timestamp` ON `calls` (`device_id`, `timestamp` DESC)",
+	"ALTER TABLE `devices` ADD COLUMN `auto_reject_calls` BOOLEAN NULL",
+	"ALTER TABLE `devices` ADD COLUMN `auto_reject_call_message` TEXT NULL",
+	"ALTER TABLE `devices` ADD COLUMN `webhook_url` TEXT NULL",
+	"ALTER TABLE `devices` ADD COLUMN `webhook_secret` TEXT NULL",
+	"ALTER TABLE `devices` ADD COLUMN `webhook_events` TEXT NULL",
+	"ALTER TABLE `devices` ADD COLUMN `auto_reply_message` TEXT NULL",
+	"ALTER TABLE `devices` ADD COLUMN `auto_mark_read` BOOLEAN NULL",
+	"ALTER TABLE `messages` ADD COLUMN `pinned_until` DATETIME(6) NULL",
+	"ALTER TABLE `messages` ADD COLUMN `is_unread` BOOLEAN DEFAULT FALSE",
+	"ALTER TABLE `chats` ADD COLUMN `left_at` DATETIME(6) NULL",
+	"ALTER TABLE `chats` ADD COLUMN `chat_type` VARCHAR(32) DEFAULT ''",
+	"ALTER TABLE `chats` ADD COLUMN `blocked` BOOLEAN DEFAULT FALSE",
+	"CREATE TABLE IF NOT EXISTS `api_keys` (`id` VARCHAR(64) PRIMARY KEY, `key_hash` VARCHAR(64) UNIQUE, `label` VARCHAR(255) DEFAULT '', `scopes` TEXT, `device_ids` TEXT, `created_at` DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6), `last_used_at` DATETIME(6) NULL, `revoked` BOOLEAN DEFAULT FALSE) DEFAULT CHARSET=utf8mb4",
+	"ALTER TABLE `messages` ADD COLUMN `request_id` VARCHAR(64) DEFAULT ''",
+	"CREATE TABLE IF NOT EXISTS `auto_reply_rules` (`id` VARCHAR(64) PRIMARY KEY, `device_id` VARCHAR(255) DEFAULT '', `position` INTEGER DEFAULT 0, `pattern` TEXT, `match_type` VARCHAR(16) DEFAULT 'contains', `reply_text` TEXT, `only_groups` BOOLEAN DEFAULT FALSE, `only_dm` BOOLEAN DEFAULT FALSE, `enabled` BOOLEAN DEFAULT TRUE, `created_at` DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6), `updated_at` DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6)) DEFAULT CHARSET=utf8mb4",
+	"CREATE INDEX `idx_auto_reply_rules_device_position` ON `auto_reply_rules` (`device_id`, `position`)",
+	"CREATE TABLE IF NOT EXISTS `labels` (`id` VARCHAR(64) PRIMARY KEY, `device_id` VARCHAR(255) DEFAULT '', `name` VARCHAR(255), `color` VARCHAR(16) DEFAULT '', `created_at` DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6), `updated_at` DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6)) DEFAULT CHARSET=utf8mb4",
+	"CREATE INDEX `idx_labels_device` ON `labels` (`device_id`)",
+	"CREATE TABLE IF NOT EXISTS `chat_labels` (`device_id` VARCHAR(255) DEFAULT '', `chat_jid` VARCHAR(255), `label_id` VARCHAR(64), `created_at` DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6), PRIMARY KEY (`chat_jid`, `device_id`, `label_id`)) DEFAULT CHARSET=utf8mb4",
+	"CREATE INDEX `idx_chat_labels_label` ON `chat_labels` (`label_id`)",
+	"CREATE TABLE IF NOT EXISTS `templates` (`device_id` VARCHAR(255) DEFAULT '', `name` VARCHAR(64), `body` TEXT, `created_at` DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6), `updated_at` DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6), PRIMARY KEY (`name`, `device_id`)) DEFAULT CHARSET=utf8mb4",
+	"ALTER TABLE `messages` ADD COLUMN `template_name` VARCHAR(64) DEFAULT ''",
+	"CREATE TABLE IF NOT EXISTS `jobs` (`id` VARCHAR(64) PRIMARY KEY, `device_id` VARCHAR(255) DEFAULT '', `type` VARCHAR(32), `status` VARCHAR(16), `progress` INTEGER DEFAULT 0, `result` TEXT, `error` TEXT, `created_at` DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6), `updated_at` DATETIME(6) DEFAULT CURRENT_TIMESTAMP(6)) DEFAULT CHARSET=utf8mb4",
+	"CREATE INDEX `idx_jobs_device_created` ON `jobs` (`device_id`, `created_at` DESC)",
+}
+
+func (r *SQLRepository) scanMessages(rows *sql.Rows) ([]*domainChatStorage.Message, error) {
+	var messages []*domainChatStorage.Message
+	for rows.Next() {
+		m, err := r.scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
+
+// scanChat scans the chatColumns of a row, followed by any extra columns.
+func (r *SQLRepository) scanChat(s interface{ Scan(...any) error }, extra ...any) (*domainChatStorage.Chat, error) {
+	c := &domainChatStorage.Chat{}
+	dest := []any{&c.DeviceID, &c.JID, &c.Name, &c.LastMessageTime, &c.EphemeralExpiration, &c.CreatedAt, &c.UpdatedAt, &c.Archived, &c.Pinned, &c.MutedUntil, &c.UnreadCount, &c.LeftAt, &c.ChatType, &c.Blocked}
+	err := s.Scan(append(dest, extra...)...)
+	return c, err
+}
+
+// scanMessage scans the messageColumns of a row, followed by any extra columns.
+func (r *SQLRepository) scanMessage(s interface{ Scan(...any) error }, extra ...any) (*domainChatStorage.Message, error) {
+	m := &domainChatStorage.Message{}
+	var location, contacts, mediaPath sql.NullString
+	dest := []any{&m.ID, &m.ChatJID, &m.DeviceID, &m.Sender, &m.Content, &m.Timestamp, &m.IsFromMe, &m.MediaType, &m.Filename, &m.URL, &m.MediaKey, &m.FileSHA256, &m.FileEncSHA256, &m.FileLength, &m.CreatedAt, &m.UpdatedAt, &m.EditedAt, &m.IsDeleted, &m.ReplyToID, &m.ReplyToSender, &location, &contacts, &mediaPath, &m.DownloadedAt, &m.IsAnimated, &m.IsViewOnce, &m.ServerID, &m.PinnedUntil, &m.IsUnread, &m.RequestID, &m.TemplateName}
+	if err := s.Scan(append(dest, extra...)...); err != nil {
+		return m, err
+	}
+	m.MediaPath = mediaPath.String
+	if location.String != "" {
+		if err := json.Unmarshal([]byte(location.String), &m.Location); err != nil {
+			return m, fmt.Errorf("failed to decode location of message %s: %w", m.ID, err)
+		}
+	}
+	if contacts.String != "" {
+		if err := json.Unmarshal([]byte(contacts.String), &m.Contacts); err != nil {
+			return m, fmt.Errorf("failed to decode contacts of message %s: %w", m.ID, err)
+		}
+	}
+	return m, nil
+}
+
+// TruncateAllChats removes every message and chat in one transaction, along
+// with what is stored about their members: contacts, group participants,
+// LID mappings and calls, and the labels chats were filed under.
+func (r *SQLRepository) TruncateAllChats(ctx context.Context) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := deleteAllRows(ctx, tx, "messages", "reactions", "message_edits", "receipts", "polls", "poll_votes", "contacts", "group_participants", "lid_mappings", "calls", "chat_labels", "labels", "chats"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// truncateReport is the JSON summary written by TruncateAllDataWithLogging.
+type truncateReport struct {
+	Prefix      string           `json:"prefix"`
+	StartedAt   time.Time        `json:"started_at"`
+	DurationMs  int64            `json:"duration_ms"`
+	RowsDeleted map[string]int64 `json:"rows_deleted"`
+}
+
+// TruncateAllDataWithLogging removes messages, chats, devices and everything
+// kept per device in one transaction, along with the outputs of jobs, logs the
+// number of rows deleted per table and, when reportPath is set, writes the same
+// summary there as JSON.
+func (r *SQLRepository) TruncateAllDataWithLogging(ctx context.Context, logPrefix, reportPath string) error {
+	start := time.Now()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin truncate transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	deleted, err := deleteAllRows(ctx, tx, "messages", "reactions", "message_edits", "receipts", "polls", "poll_votes", "contacts", "group_participants", "lid_mappings", "calls", "auto_reply_rules", "chat_labels", "labels", "templates", "jobs", "chats", "devices")
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit truncate transaction: %w", err)
+	}
+	if err := os.RemoveAll(config.PathJobs); err != nil {
+		logrus.Warnf("[%s] Failed to remove job outputs: %v", logPrefix, err)
+	}
+
+	report := truncateReport{
+		Prefix:      logPrefix,
+		StartedAt:   start,
+		DurationMs:  time.Since(start).Milliseconds(),
+		RowsDeleted: deleted,
+	}
+	logrus.WithFields(logrus.Fields{
+		"messages":    deleted["messages"],
+		"chats":       deleted["chats"],
+		"devices":     deleted["devices"],
+		"duration_ms": report.DurationMs,
+	}).Infof("[%s] Chat storage truncated", logPrefix)
+
+	if reportPath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode truncate report: %w", err)
+	}
+	if err := os.WriteFile(reportPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write truncate report to %s: %w", reportPath, err)
+	}
+	return nil
+}
+
+// deleteAllRows empties the given tables in order and returns rows deleted per table.
+func deleteAllRows(ctx context.Context, tx *sql.Tx, tables ...string) (map[string]int64, error) {
+	deleted := make(map[string]int64, len(tables))
+	for _, table := range tables {
+		res, err := tx.ExecContext(ctx, "DELETE FROM "+table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete %s: %w", table, err)
+		}
+		if n, err := res.RowsAffected(); err == nil {
+			deleted[table] = n
+		}
+	}
+	return deleted, nil
+}
+
+// DedupeChats finds chats stored under agent or device variants of a user JID
+// and plans merging each into its canonical JID, with the number of messages
+// it holds. Unless dryRun, the plan is applied in one transaction and Messages
+// is the number of messages actually moved.
+func (r *SQLRepository) DedupeChats(ctx context.Context, dryRun bool) ([]*domainChatStorage.ChatMerge, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT device_id, jid FROM chats UNION SELECT device_id, chat_jid FROM messages")
+	if err != nil {
+		return nil, err
+	}
+	var plan []*domainChatStorage.ChatMerge
+	for rows.Next() {
+		var deviceID, jid string
+		if err := rows.Scan(&deviceID, &jid); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if canonical := canonicalChatJID(jid); canonical != jid {
+			plan = append(plan, &domainChatStorage.ChatMerge{DeviceID: deviceID, FromJID: jid, ToJID: canonical})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(plan, func(i, j int) bool {
+		if plan[i].DeviceID != plan[j].DeviceID {
+			return plan[i].DeviceID < plan[j].DeviceID
+		}
+		return plan[i].FromJID < plan[j].FromJID
+	})
+
+	if dryRun {
+		for _, merge := range plan {
+			if merge.Messages, err = r.count(ctx, "SELECT COUNT(*) FROM messages WHERE chat_jid = ? AND device_id = ?", merge.FromJID, merge.DeviceID); err != nil {
+				return nil, err
+			}
+		}
+		return plan, nil
+	}
+	if len(plan) == 0 {
+		return plan, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	for _, merge := range plan {
+		if merge.Messages, err = r.mergeChats(ctx, tx, merge.DeviceID, merge.FromJID, merge.ToJID); err != nil {
+			return nil, fmt.Errorf("failed to merge %s into %s: %w", merge.FromJID, merge.ToJID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (r *SQLRepository) DeleteDeviceData(ctx context.Context, deviceID string) error {
+	if deviceID == "" {
+		return fmt.Errorf("device_id is required")
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	jobIDs, err := r.jobIDs(ctx, tx, deviceID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, r.p("DELETE FROM messages WHERE device_id = ?"), deviceID); err != nil {
+		return err
+	}
+	for _, table := range []string{"reactions", "message_edits", "receipts", "polls", "poll_votes", "contacts", "group_participants", "lid_mappings", "calls", "auto_reply_rules", "labels", "chat_labels", "templates", "jobs"} {
+		if _, err := tx.ExecContext(ctx, r.p("DELETE FROM "+table+" WHERE device_id = ?"), deviceID); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, r.p("DELETE FROM chats WHERE device_id = ?"), deviceID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, id := range jobIDs {
+		if err := os.RemoveAll(filepath.Join(config.PathJobs, id)); err != nil {
+			logrus.Warnf("[CHAT_STORAGE] Failed to remove the output of job %s: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// jobIDs returns the IDs of the device's jobs, whose outputs are folders
+// named after them.
+func (r *SQLRepository) jobIDs(ctx context.Context, tx *sql.Tx, deviceID string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, r.p("SELECT id FROM jobs WHERE device_id = ?"), deviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+const reactionColumns = `message_id, chat_jid, device_id, sender, emoji, timestamp`
+
+// StoreReaction records the sender's current reaction to a message, replacing
+// any earlier one. An empty Emoji means the reaction was removed.
+func (r *SQLRepository) StoreReaction(ctx context.Context, reaction *domainChatStorage.Reaction) error {
+	if reaction.Emoji == "" {
+		_, err := r.db.ExecContext(ctx, r.p("DELETE FROM reactions WHERE message_id = ? AND chat_jid = ? AND device_id = ? AND sender = ?"),
+			reaction.MessageID, reaction.ChatJID, reaction.DeviceID, reaction.Sender)
+		return err
+	}
+
+	query := "INSERT INTO reactions (" + reactionColumns + ") VALUES (?, ?, ?, ?, ?, ?) " +
+		r.onConflictUpdate("message_id, chat_jid, device_id, sender") +
+		" emoji = " + r.excluded("emoji") + ", timestamp = " + r.excluded("timestamp")
+	_, err := r.db.ExecContext(ctx, r.p(query),
+		reaction.MessageID, reaction.ChatJID, reaction.DeviceID, reaction.Sender, reaction.Emoji, reaction.Timestamp)
+	return err
+}
+
+func (r *SQLRepository) GetReactionsForMessage(ctx context.Context, deviceID, chatJID, messageID string) ([]*domainChatStorage.Reaction, error) {
+	rows, err := r.db.QueryContext(ctx, r.p("SELECT "+reactionColumns+" FROM reactions WHERE message_id = ? AND chat_jid = ? AND device_id = ? ORDER BY timestamp ASC"),
+		messageID, chatJID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reactions []*domainChatStorage.Reaction
+	for rows.Next() {
+		reaction := &domainChatStorage.Reaction{}
+		if err := rows.Scan(&reaction.MessageID, &reaction.ChatJID, &reaction.DeviceID, &reaction.Sender, &reaction.Emoji, &reaction.Timestamp); err != nil {
+			return nil, err
+		}
+		reactions = append(reactions, reaction)
+	}
+	return reactions, rows.Err()
+}
+
+// attachReactions loads the reactions of messages with a single query.
+func (r *SQLRepository) attachReactions(ctx context.Context, messages []*domainChatStorage.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	byKey := make(map[[3]string]*domainChatStorage.Message, len(messages))
+	ids := make([]any, 0, len(messages))
+	for _, m := range messages {
+		byKey[[3]string{m.ID, m.ChatJID, m.DeviceID}] = m
+		ids = append(ids, m.ID)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	rows, err := r.db.QueryContext(ctx, r.p("SELECT "+reactionColumns+" FROM reactions WHERE message_id IN ("+placeholders+") ORDER BY timestamp ASC"), ids...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var reaction domainChatStorage.Reaction
+		if err := rows.Scan(&reaction.MessageID, &reaction.ChatJID, &reaction.DeviceID, &reaction.Sender, &reaction.Emoji, &reaction.Timestamp); err != nil {
+			return err
+		}
+		// IDs are only unique within a chat and device
+		if m, ok := byKey[[3]string{reaction.MessageID, reaction.ChatJID, reaction.DeviceID}]; ok {
+			m.Reactions = append(m.Reactions, reaction)
+		}
+	}
+	return rows.Err()
+}
+
+const receiptColumns = `message_id, chat_jid, device_id, recipient, type, timestamp`
+
+func (r *SQLRepository) StoreReceipt(ctx context.Context, receipt *domainChatStorage.Receipt) error {
+	// Assigning the stored timestamp to itself turns repeated receipts into no-ops
+	query := "INSERT INTO receipts (" + receiptColumns + ") VALUES (?, ?, ?, ?, ?, ?) " +
+		r.onConflictUpdate("message_id, chat_jid, device_id, recipient, type") + " timestamp = receipts.timestamp"
+	_, err := r.db.ExecContext(ctx, r.p(query),
+		receipt.MessageID, receipt.ChatJID, receipt.DeviceID, receipt.Recipient, receipt.Type, receipt.Timestamp)
+	return err
+}
+
+func (r *SQLRepository) GetReceiptsForMessage(ctx context.Context, deviceID, chatJID, messageID string) ([]*domainChatStorage.Receipt, error) {
+	rows, err := r.db.QueryContext(ctx, r.p("SELECT "+receiptColumns+" FROM receipts WHERE message_id = ? AND chat_jid = ? AND device_id = ? ORDER BY timestamp ASC, recipient ASC"),
+		messageID, chatJID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var receipts []*domainChatStorage.Receipt
+	for rows.Next() {
+		receipt := &domainChatStorage.Receipt{}
+		if err := rows.Scan(&receipt.MessageID, &receipt.ChatJID, &receipt.DeviceID, &receipt.Recipient, &receipt.Type, &receipt.Timestamp); err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, receipt)
+	}
+	return receipts, rows.Err()
+}
+
+// messageStatus aggregates the receipts of a sent message: read (or played)
+// by anyone wins over delivered, which wins over sent.
+func messageStatus(receipts []*domainChatStorage.Receipt) string {
+	status := domainChatStorage.MessageStatusSent
+	for _, receipt := range receipts {
+		switch receipt.Type {
+		case domainChatStorage.ReceiptTypeRead, domainChatStorage.ReceiptTypePlayed:
+			return domainChatStorage.MessageStatusRead
+		case domainChatStorage.ReceiptTypeDelivered:
+			status = domainChatStorage.MessageStatusDelivered
+		}
+	}
+	return status
+}
+
+// attachStatus sets Status on the messages we sent with a single receipts query.
+func (r *SQLRepository) attachStatus(ctx context.Context, messages []*domainChatStorage.Message) error {
+	byKey := make(map[[3]string]*domainChatStorage.Message)
+	ids := make([]any, 0, len(messages))
+	for _, m := range messages {
+		if !m.IsFromMe {
+			continue
+		}
+		m.Status = domainChatStorage.MessageStatusSent
+		byKey[[3]string{m.ID, m.ChatJID, m.DeviceID}] = m
+		ids = append(ids, m.ID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	rows, err := r.db.QueryContext(ctx, r.p("SELECT "+receiptColumns+" FROM receipts WHERE message_id IN ("+placeholders+")"), ids...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	receipts := make(map[*domainChatStorage.Message][]*domainChatStorage.Receipt)
+	for rows.Next() {
+		receipt := &domainChatStorage.Receipt{}
+		if err := rows.Scan(&receipt.MessageID, &receipt.ChatJID, &receipt.DeviceID, &receipt.Recipient, &receipt.Type, &receipt.Timestamp); err != nil {
+			return err
+		}
+		if m, ok := byKey[[3]string{receipt.MessageID, receipt.ChatJID, receipt.DeviceID}]; ok {
+			receipts[m] = append(receipts[m], receipt)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for m, list := range receipts {
+		m.Status = messageStatus(list)
+	}
+	return nil
+}
+
+const pollColumns = `message_id, chat_jid, device_id, question, options, selectable_count`
+
+// StorePoll records the question and options of a poll message. Options are
+// kept as a JSON array.
+func (r *SQLRepository) StorePoll(ctx context.Context, poll *domainChatStorage.Poll) error {
+	options, err := json.Marshal(poll.Options)
+	if err != nil {
+		return err
+	}
+	query := "INSERT INTO polls (" + pollColumns + ") VALUES (?, ?, ?, ?, ?, ?) " +
+		r.onConflictUpdate("message_id, chat_jid, device_id") +
+		" question = " + r.excluded("question") + ", options = " + r.excluded("options") + ", selectable_count = " + r.excluded("selectable_count")
+	_, err = r.db.ExecContext(ctx, r.p(query), poll.MessageID, canonicalChatJID(poll.ChatJID), poll.DeviceID, poll.Question, string(options), poll.SelectableCount)
+	return err
+}
+
+func (r *SQLRepository) GetPoll(ctx context.Context, deviceID, chatJID, messageID string) (*domainChatStorage.Poll, error) {
+	poll := &domainChatStorage.Poll{}
+	var options string
+	err := r.db.QueryRowContext(ctx, r.p("SELECT "+pollColumns+" FROM polls WHERE message_id = ? AND chat_jid = ? AND device_id = ?"), messageID, chatJID, deviceID).
+		Scan(&poll.MessageID, &poll.ChatJID, &poll.DeviceID, &poll.Question, &options, &poll.SelectableCount)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(options), &poll.Options); err != nil {
+		return nil, fmt.Errorf("failed to decode options of poll %s: %w", messageID, err)
+	}
+	return poll, nil
+}
+
+const pollVoteColumns = `message_id, chat_jid, device_id, voter, selected_options, timestamp`
+
+// StorePollVote replaces the voter's selection in a poll, as WhatsApp sends the
+// full selection with every vote. Selected options are kept as a JSON array.
+func (r *SQLRepository) StorePollVote(ctx context.Context, vote *domainChatStorage.PollVote) error {
+	chatJID := canonicalChatJID(vote.ChatJID)
+	if len(vote.SelectedOptions) == 0 {
+		_, err := r.db.ExecContext(ctx, r.p("DELETE FROM poll_votes WHERE message_id = ? AND chat_jid = ? AND device_id = ? AND voter = ?"),
+			vote.MessageID, chatJID, vote.DeviceID, vote.Voter)
+		return err
+	}
+
+	selected, err := json.Marshal(vote.SelectedOptions)
+	if err != nil {
+		return err
+	}
+	query := "INSERT INTO poll_votes (" + pollVoteColumns + ") VALUES (?, ?, ?, ?, ?, ?) " +
+		r.onConflictUpdate("message_id, chat_jid, device_id, voter") +
+		" selected_options = " + r.excluded("selected_options") + ", timestamp = " + r.excluded("timestamp")
+	_, err = r.db.ExecContext(ctx, r.p(query), vote.MessageID, chatJID, vote.DeviceID, vote.Voter, string(selected), vote.Timestamp)
+	return err
+}
+
+func (r *SQLRepository) GetPollVotes(ctx context.Context, deviceID, chatJID, messageID string) ([]*domainChatStorage.PollVote, error) {
+	rows, err := r.db.QueryContext(ctx, r.p("SELECT "+pollVoteColumns+" FROM poll_votes WHERE message_id = ? AND chat_jid = ? AND device_id = ? ORDER BY timestamp ASC, voter ASC"),
+		messageID, chatJID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var votes []*domainChatStorage.PollVote
+	for rows.Next() {
+		vote := &domainChatStorage.PollVote{}
+		var selected string
+		if err := rows.Scan(&vote.MessageID, &vote.ChatJID, &vote.DeviceID, &vote.Voter, &selected, &vote.Timestamp); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(selected), &vote.SelectedOptions); err != nil {
+			return nil, fmt.Errorf("failed to decode vote of %s: %w", vote.Voter, err)
+		}
+		votes = append(votes, vote)
+	}
+	return votes, rows.Err()
+}
+
+const callColumns = `device_id, call_id, from_jid, timestamp, is_video, outcome, duration, accepted_at`
+
+// StoreCall records an incoming call offer. An offer delivered again keeps the
+// outcome already recorded for the call.
+func (r *SQLRepository) StoreCall(ctx context.Context, call *domainChatStorage.Call) error {
+	outcome := call.Outcome
+	if outcome == "" {
+		outcome = domainChatStorage.CallOutcomeRinging
+	}
+	query := "INSERT INTO calls (" + callColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?) " +
+		r.onConflictUpdate("call_id, device_id") +
+		" from_jid = " + r.excluded("from_jid") + ", is_video = " + r.excluded("is_video")
+	_, err := r.db.ExecContext(ctx, r.p(query), call.DeviceID, call.CallID, call.FromJID, call.Timestamp, call.IsVideo, outcome, call.Duration, call.AcceptedAt)
+	return err
+}
+
+// UpdateCallOutcome marks a ringing call as accepted or rejected. Calls that
+// already have an outcome are left alone, so late events cannot overwrite it.
+func (r *SQLRepository) UpdateCallOutcome(ctx context.Context, deviceID, callID, outcome string, at time.Time) error {
+	query := "UPDATE calls SET outcome = ? WHERE call_id = ? AND device_id = ? AND outcome = ?"
+	args := []any{outcome, callID, deviceID, domainChatStorage.CallOutcomeRinging}
+	if outcome == domainChatStorage.CallOutcomeAccepted {
+		query = "UPDATE calls SET outcome = ?, accepted_at = ? WHERE call_id = ? AND device_id = ? AND outcome = ?"
+		args = []any{outcome, at, callID, deviceID, domainChatStorage.CallOutcomeRinging}
+	}
+	_, err := r.db.ExecContext(ctx, r.p(query), args...)
+	return err
+}
+
+// EndCall records the end of a call: an accepted call gets its duration and a
+// call that was still ringing becomes missed.
+func (r *SQLRepository) EndCall(ctx context.Context, deviceID, callID string, endedAt time.Time) error {
+	var outcome string
+	var acceptedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, r.p("SELECT outcome, accepted_at FROM calls WHERE call_id = ? AND device_id = ?"), callID, deviceID).Scan(&outcome, &acceptedAt)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	switch outcome {
+	case domainChatStorage.CallOutcomeRinging:
+		_, err = r.db.ExecContext(ctx, r.p("UPDATE calls SET outcome = ? WHERE call_id = ? AND device_id = ?"),
+			domainChatStorage.CallOutcomeMissed, callID, deviceID)
+	case domainChatStorage.CallOutcomeAccepted:
+		if !acceptedAt.Valid {
+			return nil
+		}
+		duration := max(int(endedAt.Sub(acceptedAt.Time).Seconds()), 0)
+		_, err = r.db.ExecContext(ctx, r.p("UPDATE calls SET duration = ? WHERE call_id = ? AND device_id = ?"), duration, callID, deviceID)
+	}
+	return err
+}
+
+// GetCalls returns the calls matching filter, newest first.
+func (r *SQLRepository) GetCalls(ctx context.Context, filter *domainChatStorage.CallFilter) ([]*domainChatStorage.Call, error) {
+	var conditions []string
+	var args []any
+	if filter.DeviceID != "" {
+		conditions = append(conditions, "device_id = ?")
+		args = append(args, filter.DeviceID)
+	}
+	if filter.FromJID != "" {
+		conditions = append(conditions, "from_jid = ?")
+		args = append(args, filter.FromJID)
+	}
+	if filter.StartTime != nil {
+		conditions = append(conditions, "timestamp >= ?")
+		args = append(args, *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		conditions = append(conditions, "timestamp <= ?")
+		args = append(args, *filter.EndTime)
+	}
+
+	query := "SELECT " + callColumns + " FROM calls"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY timestamp DESC, call_id ASC"
+	page, pageArgs := r.limitOffset(filter.Limit, filter.Offset)
+	query += page
+	args = append(args, pageArgs...)
+
+	rows, err := r.db.QueryContext(ctx, r.p(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var calls []*domainChatStorage.Call
+	for rows.Next() {
+		call := &domainChatStorage.Call{}
+		if err := rows.Scan(&call.DeviceID, &call.CallID, &call.FromJID, &call.Timestamp, &call.IsVideo, &call.Outcome, &call.Duration, &call.AcceptedAt); err != nil {
+			return nil, err
+		}
+		calls = append(calls, call)
+	}
+	return calls, rows.Err()
+}
+
+const groupParticipantColumns = `device_id, group_jid, participant_jid, is_admin, is_superadmin, joined_at`
+
+// SyncGroupParticipants replaces the cached members of a group with
+// participants. Join times already known for a member are kept.
+func (r *SQLRepository) SyncGroupParticipants(ctx context.Context, deviceID, groupJID string, participants []*domainChatStorage.GroupParticipant) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, r.p("SELECT participant_jid, joined_at FROM group_participants WHERE group_jid = ? AND device_id = ? AND joined_at IS NOT NULL"), groupJID, deviceID)
+	if err != nil {
+		return err
+	}
+	joinedAt := make(map[string]time.Time)
+	for rows.Next() {
+		var jid string
+		var ts time.Time
+		if err := rows.Scan(&jid, &ts); err != nil {
+			rows.Close()
+			return err
+		}
+		joinedAt[jid] = ts
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, r.p("DELETE FROM group_participants WHERE group_jid = ? AND device_id = ?"), groupJID, deviceID); err != nil {
+		return err
+	}
+	insert := r.p("INSERT INTO group_participants (" + groupParticipantColumns + ") VALUES (?, ?, ?, ?, ?, ?)")
+	seen := make(map[string]bool, len(participants))
+	for _, p := range participants {
+		if p == nil || p.ParticipantJID == "" || seen[p.ParticipantJID] {
+			continue
+		}
+		seen[p.ParticipantJID] = true
+		joined := p.JoinedAt
+		if ts, ok := joinedAt[p.ParticipantJID]; ok {
+			joined = &ts
+		}
+		if _, err := tx.ExecContext(ctx, insert, deviceID, groupJID, p.ParticipantJID, p.IsAdmin, p.IsSuperAdmin, joined); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// UpdateGroupParticipants applies a join/leave/promote/demote notification to
+// the cached members of a group in one transaction.
+func (r *SQLRepository) UpdateGroupParticipants(ctx context.Context, deviceID, groupJID string, change domainChatStorage.GroupParticipantChange) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Members who rejoin start over as regular members
+	join := r.p("INSERT INTO group_participants (" + groupParticipantColumns + ") VALUES (?, ?, ?, FALSE, FALSE, ?) " +
+		r.onConflictUpdate("group_jid, device_id, participant_jid") +
+		" is_admin = FALSE, is_superadmin = FALSE, joined_at = " + r.excluded("joined_at"))
+	for _, jid := range change.Join {
+		if _, err := tx.ExecContext(ctx, join, deviceID, groupJID, jid, change.Timestamp); err != nil {
+			return err
+		}
+	}
+	for _, jid := range change.Leave {
+		if _, err := tx.ExecContext(ctx, r.p("DELETE FROM group_participants WHERE group_jid = ? AND device_id = ? AND participant_jid = ?"), groupJID, deviceID, jid); err != nil {
+			return err
+		}
+	}
+	// A promoted member may not be cached yet
+	promote := r.p("INSERT INTO group_participants (" + groupParticipantColumns + ") VALUES (?, ?, ?, TRUE, FALSE, NULL) " +
+		r.onConflictUpdate("group_jid, device_id, participant_jid") + " is_admin = TRUE")
+	for _, jid := range change.Promote {
+		if _, err := tx.ExecContext(ctx, promote, deviceID, groupJID, jid); err != nil {
+			return err
+		}
+	}
+	for _, jid := range change.Demote {
+		if _, err := tx.ExecContext(ctx, r.p("UPDATE group_participants SET is_admin = FALSE, is_superadmin = FALSE WHERE group_jid = ? AND device_id = ? AND participant_jid = ?"), groupJID, deviceID, jid); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetGroupParticipants returns the cached members of a group, super admins and
+// admins first.
+func (r *SQLRepository) GetGroupParticipants(ctx context.Context, deviceID, groupJID string) ([]*domainChatStorage.GroupParticipant, error) {
+	rows, err := r.db.QueryContext(ctx, r.p("SELECT "+groupParticipantColumns+" FROM group_participants WHERE group_jid = ? AND device_id = ? ORDER BY is_superadmin DESC, is_admin DESC, participant_jid ASC"), groupJID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var participants []*domainChatStorage.GroupParticipant
+	for rows.Next() {
+		p := &domainChatStorage.GroupParticipant{}
+		if err := rows.Scan(&p.DeviceID, &p.GroupJID, &p.ParticipantJID, &p.IsAdmin, &p.IsSuperAdmin, &p.JoinedAt); err != nil {
+			return nil, err
+		}
+		participants = append(participants, p)
+	}
+	return participants, rows.Err()
+}
+
+// StoreLIDMapping records that lid resolves to the phone number JID pnJID, so
+// the mapping survives restarts and LIDs the client can no longer resolve.
+func (r *SQLRepository) StoreLIDMapping(ctx context.Context, deviceID, lid, pnJID string) error {
+	_, err := r.db.ExecContext(ctx, r.p("INSERT INTO lid_mappings (device_id, lid, pn_jid, updated_at) VALUES (?, ?, ?, ?) "+
+		r.onConflictUpdate("lid, device_id")+" pn_jid = "+r.excluded("pn_jid")+", updated_at = "+r.excluded("updated_at")),
+		deviceID, lid, pnJID, time.Now())
+	return err
+}
+
+// GetPNForLID returns the stored phone number JID for lid, or an empty string
+// when the LID was never resolved.
+func (r *SQLRepository) GetPNForLID(ctx context.Context, deviceID, lid string) (string, error) {
+	var pnJID string
+	err := r.db.QueryRowContext(ctx, r.p("SELECT pn_jid FROM lid_mappings WHERE lid = ? AND device_id = ?"), lid, deviceID).Scan(&pnJID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return pnJID, err
+}
+
+const contactColumns = `device_id, jid, full_name, first_name, push_name, business_name, updated_at`
+
+// contactBatchSize keeps each multi-row contact upsert within the bind parameter
+// limits, like messageBatchSize.
+const contactBatchSize = 500
+
+// StoreContacts upserts contacts in a single transaction and returns the number
+// of rows written.
+func (r *SQLRepository) StoreContacts(ctx context.Context, contacts []*domainChatStorage.Contact) (int, error) {
+	now := time.Now()
+	batch := make([]*domainChatStorage.Contact, 0, len(contacts))
+	for _, contact := range contacts {
+		if contact == nil || contact.JID == "" {
+			continue
+		}
+		contact.UpdatedAt = now
+		batch = append(batch, contact)
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin contact transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	updates := make([]string, 0, 5)
+	for _, column := range []string{"full_name", "first_name", "push_name", "business_name", "updated_at"} {
+		updates = append(updates, column+" = "+r.excluded(column))
+	}
+	for start := 0; start < len(batch); start += contactBatchSize {
+		end := min(start+contactBatchSize, len(batch))
+		rows := make([]string, 0, end-start)
+		args := make([]any, 0, (end-start)*7)
+		for _, c := range batch[start:end] {
+			rows = append(rows, "(?, ?, ?, ?, ?, ?, ?)")
+			args = append(args, c.DeviceID, c.JID, c.FullName, c.FirstName, c.PushName, c.BusinessName, c.UpdatedAt)
+		}
+		query := "INSERT INTO contacts (" + contactColumns + ") VALUES " + strings.Join(rows, ", ") + " " +
+			r.onConflictUpdate("jid, device_id") + " " + strings.Join(updates, ", ")
+		if _, err := tx.ExecContext(ctx, r.p(query), args...); err != nil {
+			return 0, fmt.Errorf("failed to store contacts %d-%d of %d: %w", start+1, end, len(batch), err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit contacts: %w", err)
+	}
+	return len(batch), nil
+}
+
+// GetContacts returns contacts with a full name first, ordered by name.
+func (r *SQLRepository) GetContacts(ctx context.Context, filter *domainChatStorage.ContactFilter) ([]*domainChatStorage.Contact, error) {
+	where, args := contactFilterWhere(filter)
+	query := "SELECT " + contactColumns + " FROM contacts" + where +
+		" ORDER BY CASE WHEN full_name = '' THEN 1 ELSE 0 END, full_name, push_name, jid"
+	page, pageArgs := r.limitOffset(filter.Limit, filter.Offset)
+	query += page
+	args = append(args, pageArgs...)
+
+	rows, err := r.db.QueryContext(ctx, r.p(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contacts []*domainChatStorage.Contact
+	for rows.Next() {
+		c := &domainChatStorage.Contact{}
+		if err := rows.Scan(&c.DeviceID, &c.JID, &c.FullName, &c.FirstName, &c.PushName, &c.BusinessName, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, c)
+	}
+	return contacts, rows.Err()
+}
+
+// CountContacts returns how many contacts match filter, ignoring Limit and Offset.
+func (r *SQLRepository) CountContacts(ctx context.Context, filter *domainChatStorage.ContactFilter) (int64, error) {
+	where, args := contactFilterWhere(filter)
+	return r.count(ctx, "SELECT COUNT(*) FROM contacts"+where, args...)
+}
+
+func contactFilterWhere(filter *domainChatStorage.ContactFilter) (string, []any) {
+	var conditions []string
+	var args []any
+	if filter.DeviceID != "" {
+		conditions = append(conditions, "device_id = ?")
+		args = append(args, filter.DeviceID)
+	}
+	if filter.Search != "" {
+		pattern := "%" + filter.Search + "%"
+		conditions = append(conditions, "(full_name LIKE ? OR first_name LIKE ? OR push_name LIKE ? OR business_name LIKE ? OR jid LIKE ?)")
+		args = append(args, pattern, pattern, pattern, pattern, pattern)
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// contactFullName returns the stored full name of jid, or an empty string.
+func (r *SQLRepository) contactFullName(ctx context.Context, deviceID, jid string) string {
+	query := "SELECT full_name FROM contacts WHERE jid = ? AND full_name <> ''"
+	args := []any{jid}
+	if deviceID != "" {
+		query += " AND device_id = ?"
+		args = append(args, deviceID)
+	}
+	var name string
+	if err := r.db.QueryRowContext(ctx, r.p(query+" LIMIT 1"), args...).Scan(&name); err != nil {
+		return ""
+	}
+	return name
+}
+
+// attachQuoted sets Quoted on every reply with a single query for the quoted
+// messages. Replies to messages that are not stored keep the quoted ID and
+// sender with empty content.
+func (r *SQLRepository) attachQuoted(ctx context.Context, messages []*domainChatStorage.Message) error {
+	var replies []*domainChatStorage.Message
+	ids := make([]any, 0, len(messages))
+	for _, m := range messages {
+		if m.ReplyToID == "" {
+			continue
+		}
+		m.Quoted = &domainChatStorage.QuotedMessage{ID: m.ReplyToID, Sender: m.ReplyToSender}
+		replies = append(replies, m)
+		ids = append(ids, m.ReplyToID)
+	}
+	if len(replies) == 0 {
+		return nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	rows, err := r.db.QueryContext(ctx, r.p("SELECT id, chat_jid, device_id, sender, COALESCE(content, '') FROM messages WHERE id IN ("+placeholders+")"), ids...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	quoted := make(map[[3]string]domainChatStorage.QuotedMessage)
+	for rows.Next() {
+		var id, chatJID, deviceID string
+		var q domainChatStorage.QuotedMessage
+		if err := rows.Scan(&id, &chatJID, &deviceID, &q.Sender, &q.Content); err != nil {
+			return err
+		}
+		q.ID = id
+		quoted[[3]string{id, chatJID, deviceID}] = q
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Replies quote messages of their own chat, so match on chat and device too
+	for _, m := range replies {
+		if q, ok := quoted[[3]string{m.ReplyToID, m.ChatJID, m.DeviceID}]; ok {
+			m.Quoted = &q
+		}
+	}
+	return nil
+}
+
+// StoreMessageEdit replaces the content of a stored message and records the
+// previous content in message_edits. Edits for unknown messages, repeated
+// deliveries and edits older than the current version are ignored.
+func (r *SQLRepository) StoreMessageEdit(ctx context.Context, deviceID, id, chatJID, content string, editedAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var previous string
+	var lastEdit *time.Time
+	err = tx.QueryRowContext(ctx, r.p("SELECT COALESCE(content, ''), edited_at FROM messages WHERE id = ? AND chat_jid = ? AND device_id = ?"),
+		id, chatJID, deviceID).Scan(&previous, &lastEdit)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if previous == content || (lastEdit != nil && !editedAt.After(*lastEdit)) {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, r.p("INSERT INTO message_edits (message_id, chat_jid, device_id, content, edited_at) VALUES (?, ?, ?, ?, ?)"),
+		id, chatJID, deviceID, previous, editedAt); err != nil {
+		return fmt.Errorf("failed to record edit history: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, r.p("UPDATE messages SET content = ?, edited_at = ?, updated_at = ? WHERE id = ? AND chat_jid = ? AND device_id = ?"),
+		content, editedAt, time.Now(), id, chatJID, deviceID); err != nil {
+		return fmt.Errorf("failed to update edited message: %w", err)
+	}
+	return tx.Commit()
+}
+
+// GetMessageEditHistory returns the previous versions of a message, oldest first.
+func (r *SQLRepository) GetMessageEditHistory(ctx context.Context, deviceID, id, chatJID string) ([]*domainChatStorage.MessageEdit, error) {
+	rows, err := r.db.QueryContext(ctx, r.p("SELECT message_id, chat_jid, device_id, COALESCE(content, ''), edited_at FROM message_edits WHERE message_id = ? AND chat_jid = ? AND device_id = ? ORDER BY edited_at ASC"),
+		id, chatJID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var edits []*domainChatStorage.MessageEdit
+	for rows.Next() {
+		edit := &domainChatStorage.MessageEdit{}
+		if err := rows.Scan(&edit.MessageID, &edit.ChatJID, &edit.DeviceID, &edit.Content, &edit.EditedAt); err != nil {
+			return nil, err
+		}
+		edits = append(edits, edit)
+	}
+	return edits, rows.Err()
+}
+
+// MarkMessageRevoked flags a message as deleted for everyone. Unless keepContent
+// is set, its text, media references and edit history are discarded as well.
+func (r *SQLRepository) MarkMessageRevoked(ctx context.Context, deviceID, id, chatJID string, keepContent bool) error {
+	if keepContent {
+		_, err := r.db.ExecContext(ctx, r.p("UPDATE messages SET is_deleted = ?, updated_at = ? WHERE id = ? AND chat_jid = ? AND device_id = ?"),
+			true, time.Now(), id, chatJID, deviceID)
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, r.p("UPDATE messages SET is_deleted = ?, content = '', url = '', media_key = NULL, file_sha256 = NULL, file_enc_sha256 = NULL, updated_at = ?"+
+		" WHERE id = ? AND chat_jid = ? AND device_id = ?"), true, time.Now(), id, chatJID, deviceID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, r.p("DELETE FROM message_edits WHERE message_id = ? AND chat_jid = ? AND device_id = ?"), id, chatJID, deviceID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetMessageMediaPath records where the attachment of a message was downloaded
+// to, replacing the announced file length with the size actually written.
+func (r *SQLRepository) SetMessageMediaPath(ctx context.Context, deviceID, id, chatJID, mediaPath string, size int64, downloadedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, r.p("UPDATE messages SET media_path = ?, downloaded_at = ?, file_length = ?, updated_at = ? WHERE id = ? AND chat_jid = ? AND device_id = ?"),
+		mediaPath, downloadedAt, size, time.Now(), id, chatJID, deviceID)
+	if err != nil {
+		return fmt.Errorf("failed to set media path of message %s: %w", id, err)
+	}
+	return nil
+}
+
+// SetMessageMediaURL replaces the download URL of the attachment of a message.
+func (r *SQLRepository) SetMessageMediaURL(ctx context.Context, deviceID, id, chatJID, url string) error {
+	_, err := r.db.ExecContext(ctx, r.p("UPDATE messages SET url = ?, updated_at = ? WHERE id = ? AND chat_jid = ? AND device_id = ?"),
+		url, time.Now(), id, chatJID, deviceID)
+	if err != nil {
+		return fmt.Errorf("failed to set media url of message %s: %w", id, err)
+	}
+	return nil
+}
+
+// SetMessagePinnedUntil pins a message in its chat until pinnedUntil, or
+// unpins it when pinnedUntil is nil.
+func (r *SQLRepository) SetMessagePinnedUntil(ctx context.Context, deviceID, id, chatJID string, pinnedUntil *time.Time) error {
+	_, err := r.db.ExecContext(ctx, r.p("UPDATE messages SET pinned_until = ?, updated_at = ? WHERE id = ? AND chat_jid = ? AND device_id = ?"),
+		pinnedUntil, time.Now(), id, chatJID, deviceID)
+	if err != nil {
+		return fmt.Errorf("failed to set pin of message %s: %w", id, err)
+	}
+	return nil
+}
+
+// GetPinnedMessages returns the messages of a chat whose pin hasn't expired
+// at now, newest first.
+func (r *SQLRepository) GetPinnedMessages(ctx context.Context, deviceID, chatJID string, now time.Time) ([]*domainChatStorage.Message, error) {
+	rows, err := r.db.QueryContext(ctx, r.p("SELECT "+messageColumns+" FROM messages WHERE chat_jid = ? AND device_id = ? AND pinned_until > ? ORDER BY timestamp DESC, id DESC"),
+		chatJID, deviceID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanMessages(rows)
+}
+
+// pruneBatchSize bounds how many messages a single DELETE removes so that
+// pruning a large backlog never holds row locks for long.
+const pruneBatchSize = 5000
+
+// PruneMessagesBefore deletes messages older than cutoff for deviceID, or for
+// every device when deviceID is empty, and then drops chats left without any
+// messages. Each batch commits on its own, so a cancelled ctx keeps the rows
+// already removed. It returns the number of messages deleted.
+func (r *SQLRepository) PruneMessagesBefore(ctx context.Context, deviceID string, cutoff time.Time) (int64, error) {
+	conds := []string{"timestamp < ?"}
+	args := []any{cutoff}
+	if deviceID != "" {
+		conds = append(conds, "device_id = ?")
+		args = append(args, deviceID)
+	}
+	where := strings.Join(conds, " AND ")
+
+	var query string
+	switch r.dialect {
+	case dialectMySQL:
+		query = "DELETE FROM messages WHERE " + where + " LIMIT ?"
+	case dialectPostgres:
+		query = "DELETE FROM messages WHERE ctid IN (SELECT ctid FROM messages WHERE " + where + " LIMIT ?)"
+	default:
+		query = "DELETE FROM messages WHERE rowid IN (SELECT rowid FROM messages WHERE " + where + " LIMIT ?)"
+	}
+	query = r.p(query)
+	batchArgs := append(append([]any{}, args...), pruneBatchSize)
+
+	// Downloaded attachments go with their messages. A prune that stops early
+	// still removes them all; the survivors are downloaded again on demand.
+	mediaPaths, err := r.mediaPathsWhere(ctx, where, args)
+	if err != nil {
+		return 0, err
+	}
+	defer removeMediaFiles(mediaPaths)
+
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		result, err := r.db.ExecContext(ctx, query, batchArgs...)
+		if err != nil {
+			return total, fmt.Errorf("failed to prune messages: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += affected
+		if affected < pruneBatchSize {
+			break
+		}
+	}
+
+	if _, err := r.db.ExecContext(ctx, r.p("DELETE FROM reactions WHERE "+where), args...); err != nil {
+		return total, fmt.Errorf("failed to prune reactions: %w", err)
+	}
+	// Edits, receipts and polls may be newer than the message they belong to, so drop them by their message
+	for _, table := range []string{"message_edits", "receipts", "polls", "poll_votes"} {
+		orphanQuery := "DELETE FROM " + table + " WHERE NOT EXISTS (SELECT 1 FROM messages m" +
+			" WHERE m.id = " + table + ".message_id AND m.chat_jid = " + table + ".chat_jid AND m.device_id = " + table + ".device_id)"
+		var orphanArgs []any
+		if deviceID != "" {
+			orphanQuery += " AND device_id = ?"
+			orphanArgs = append(orphanArgs, deviceID)
+		}
+		if _, err := r.db.ExecContext(ctx, r.p(orphanQuery), orphanArgs...); err != nil {
+			return total, fmt.Errorf("failed to prune %s: %w", table, err)
+		}
+	}
+
+	// Chats with recent activity survive even when their messages were never stored
+	orphanChat := "chats.last_message_time < ?" +
+		" AND NOT EXISTS (SELECT 1 FROM messages m WHERE m.chat_jid = chats.jid AND m.device_id = chats.device_id)"
+	if deviceID != "" {
+		orphanChat += " AND chats.device_id = ?"
+	}
+	// Their labels go first, while the chats still say which are orphaned
+	labelQuery := "DELETE FROM chat_labels WHERE EXISTS (SELECT 1 FROM chats WHERE chats.jid = chat_labels.chat_jid" +
+		" AND chats.device_id = chat_labels.device_id AND " + orphanChat + ")"
+	if _, err := r.db.ExecContext(ctx, r.p(labelQuery), args...); err != nil {
+		return total, fmt.Errorf("failed to prune labels of orphaned chats: %w", err)
+	}
+	if _, err := r.db.ExecContext(ctx, r.p("DELETE FROM chats WHERE "+orphanChat), args...); err != nil {
+		return total, fmt.Errorf("failed to prune orphaned chats: %w", err)
+	}
+
+	return total, nil
+}
+
+// mediaPathsWhere returns the local attachment paths of the messages matching where.
+func (r *SQLRepository) mediaPathsWhere(ctx context.Context, where string, args []any) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, r.p("SELECT media_path FROM messages WHERE "+where+" AND media_path IS NOT NULL AND media_path <> ''"), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list media of pruned messages: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
+
+// removeMediaFiles deletes downloaded attachments, ignoring those already gone.
+func removeMediaFiles(paths []string) {
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			logrus.Warnf("[CHAT_STORAGE] Failed to remove media file %s: %v", path, err)
+		}
+	}
+}
+
+// StoreSentMessageWithContext persists an outbound message for the device carried by ctx,
+// along with the ID of the API request ctx serves. media may be nil for plain text sends.
+func (r *SQLRepository) StoreSentMessageWithContext(ctx context.Context, messageID string, senderJID string, recipientJID string, content string, timestamp time.Time, media *domainChatStorage.MediaInfo) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	deviceID := deviceIDFromContext(ctx)
+	recipientJID = canonicalChatJID(recipientJID)
+
+	chat, err := r.GetChatByDevice(ctx, deviceID, recipientJID)
+	if err != nil {
+		return err
+	}
+	if chat == nil {
+		chat = &domainChatStorage.Chat{DeviceID: deviceID, JID: recipientJID, Name: chatNameFromJID(recipientJID)}
+	}
+	if timestamp.After(chat.LastMessageTime) {
+		chat.LastMessageTime = timestamp
+	}
+	if err := r.StoreChat(ctx, chat); err != nil {
+		return fmt.Errorf("failed to store chat for sent message: %w", err)
+	}
+
+	message := &domainChatStorage.Message{
+		ID:           messageID,
+		ChatJID:      recipientJID,
+		DeviceID:     deviceID,
+		Sender:       senderJID,
+		Content:      content,
+		Timestamp:    timestamp,
+		IsFromMe:     true,
+		RequestID:    utils.RequestIDFromContext(ctx),
+		TemplateName: utils.TemplateNameFromContext(ctx),
+	}
+	if media != nil {
+		message.MediaType = media.MediaType
+		message.Filename = media.Filename
+		message.URL = media.URL
+		message.MediaKey = media.MediaKey
+		message.FileSHA256 = media.FileSHA256
+		message.FileEncSHA256 = media.FileEncSHA256
+		message.FileLength = media.FileLength
+		message.Location = media.Location
+		message.Contacts = media.Contacts
+		message.IsAnimated = media.IsAnimated
+		message.IsViewOnce = media.IsViewOnce
+	}
+	return r.StoreMessage(ctx, message)
+}
+
+// deviceIDFromContext returns the storage key of the device in ctx, which is its JID
+// once paired and its alias before that.
+func deviceIDFromContext(ctx context.Context) string {
+	if inst, ok := whatsapp.DeviceFromContext(ctx); ok && inst != nil {
+		if jid := inst.JID(); jid != "" {
+			return jid
+		}
+		return inst.ID()
+	}
+	return ""
 }
@@ -0,0 +1,43 @@
+package chatstorage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqlitePragmas are applied by the driver to every new connection. WAL lets
+// readers proceed while the event handler writes, and busy_timeout makes
+// concurrent writers wait instead of failing with SQLITE_BUSY.
+var sqlitePragmas = []string{
+	"_pragma=journal_mode(WAL)",
+	"_pragma=busy_timeout(5000)",
+}
+
+// OpenSQLite opens the chat storage database at uri, which may be a file: URI
+// or a plain filename, using the pure-Go SQLite driver.
+func OpenSQLite(ctx context.Context, uri string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", sqliteDSN(uri))
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	return db, nil
+}
+
+func sqliteDSN(uri string) string {
+	if !strings.HasPrefix(uri, "file:") {
+		uri = "file:" + uri
+	}
+	separator := "?"
+	if strings.Contains(uri, "?") {
+		separator = "&"
+	}
+	return uri + separator + strings.Join(sqlitePragmas, "&")
+}
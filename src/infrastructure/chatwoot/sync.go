@@ -83,7 +83,7 @@ func (s *SyncService) SyncHistory(ctx context.Context, deviceID string, waClient
 		deviceID, opts.DaysLimit, opts.IncludeMedia, opts.IncludeGroups)
 
 	// 1. Get all chats for this device
-	chats, err := s.chatStorageRepo.GetChats(&domainChatStorage.ChatFilter{
+	chats, err := s.chatStorageRepo.GetChats(ctx, &domainChatStorage.ChatFilter{
 		DeviceID: deviceID,
 	})
 	if err != nil {
@@ -163,7 +163,7 @@ func (s *SyncService) syncChat(
 	logrus.Debugf("Chatwoot Sync: Conversation ID: %d", conversation.ID)
 
 	// 3. Get messages since time boundary
-	messages, err := s.chatStorageRepo.GetMessages(&domainChatStorage.MessageFilter{
+	messages, err := s.chatStorageRepo.GetMessages(ctx, &domainChatStorage.MessageFilter{
 		DeviceID:  deviceID,
 		ChatJID:   chat.JID,
 		StartTime: &sinceTime,
@@ -3,6 +3,8 @@ package whatsapp
 import (
 	"context"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
@@ -14,22 +16,161 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-func handleAutoReply(ctx context.Context, evt *events.Message, chatStorageRepo domainChatStorage.IChatStorageRepository, client *whatsmeow.Client) {
-	if config.WhatsappAutoReplyMessage == "" {
-		return
+const (
+	// autoReplyCooldownPruneSize is how many chats the cooldown tracks before it
+	// drops the ones whose cooldown has passed.
+	autoReplyCooldownPruneSize = 1024
+
+	// autoReplySentSize and autoReplySentTTL bound the auto-replies
+	// remembered as sent.
+	autoReplySentSize = 4096
+	autoReplySentTTL  = 24 * time.Hour
+)
+
+// autoReplies remembers when each chat last got an auto-reply.
+var autoReplies = newReplyCooldown()
+
+// sentAutoReplies remembers the IDs of the auto-replies sent lately. Each
+// auto-reply quotes the message it answers, so a device running this service
+// doesn't answer the auto-replies of another that quote its own, and two of
+// them don't answer each other forever.
+var sentAutoReplies = newSentReplies(autoReplySentSize, autoReplySentTTL)
+
+// messageAutomation is the effective auto-reply and auto-mark-read
+// configuration of a device.
+type messageAutomation struct {
+	ReplyMessage string
+	MarkRead     bool
+}
+
+// resolveMessageAutomation applies the settings of a device over the global
+// auto-reply and auto-mark-read flags.
+func resolveMessageAutomation(settings domainChatStorage.DeviceSettings) messageAutomation {
+	automation := messageAutomation{
+		ReplyMessage: config.WhatsappAutoReplyMessage,
+		MarkRead:     config.WhatsappAutoMarkRead,
+	}
+	if settings.AutoReplyMessage != nil {
+		automation.ReplyMessage = *settings.AutoReplyMessage
+	}
+	if settings.AutoMarkRead != nil {
+		automation.MarkRead = *settings.AutoMarkRead
+	}
+	return automation
+}
+
+// deviceMessageAutomation looks up the message automation of a registered device.
+func deviceMessageAutomation(ctx context.Context, chatStorageRepo domainChatStorage.IChatStorageRepository, deviceID string) messageAutomation {
+	return resolveMessageAutomation(deviceSettings.get(ctx, chatStorageRepo, deviceID))
+}
+
+// replyCooldown limits auto-replies to one per chat per cooldown.
+type replyCooldown struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+func newReplyCooldown() *replyCooldown {
+	return &replyCooldown{last: make(map[string]time.Time)}
+}
+
+// reserve reports whether key may get a reply at now and, if so, starts its
+// cooldown. A cooldown of zero or less never holds a reply back.
+func (c *replyCooldown) reserve(key string, now time.Time, cooldown time.Duration) bool {
+	if cooldown <= 0 {
+		return true
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if last, ok := c.last[key]; ok && now.Sub(last) < cooldown {
+		return false
+	}
+	if len(c.last) >= autoReplyCooldownPruneSize {
+		for k, last := range c.last {
+			if now.Sub(last) >= cooldown {
+				delete(c.last, k)
+			}
+		}
+	}
+	c.last[key] = now
+	return true
+}
+
+// release ends the cooldown of key, so a reply that failed to send can be
+// retried on the next message.
+func (c *replyCooldown) release(key string) {
+	c.mu.Lock()
+	delete(c.last, key)
+	c.mu.Unlock()
+}
+
+// sentReplies is a set of message IDs, each kept for ttl. Once it holds size
+// IDs, adding one drops the expired IDs or, failing that, the oldest.
+type sentReplies struct {
+	mu   sync.Mutex
+	size int
+	ttl  time.Duration
+	sent map[string]time.Time
+}
+
+func newSentReplies(size int, ttl time.Duration) *sentReplies {
+	return &sentReplies{size: size, ttl: ttl, sent: make(map[string]time.Time)}
+}
+
+func (s *sentReplies) add(id string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.sent) >= s.size {
+		oldestID, oldest := "", now
+		for sentID, at := range s.sent {
+			if now.Sub(at) >= s.ttl {
+				delete(s.sent, sentID)
+			} else if at.Before(oldest) {
+				oldestID, oldest = sentID, at
+			}
+		}
+		if len(s.sent) >= s.size {
+			delete(s.sent, oldestID)
+		}
+	}
+	s.sent[id] = now
+}
+
+// has reports whether id was added less than ttl before now.
+func (s *sentReplies) has(id string, now time.Time) bool {
+	if id == "" {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	at, ok := s.sent[id]
+	return ok && now.Sub(at) < s.ttl
+}
+
+// isAutoReply reports whether evt is one of our auto-replies or answers one
+// by quoting it.
+func isAutoReply(evt *events.Message, now time.Time) bool {
+	quotedID, _ := utils.ExtractReplyContext(evt.Message)
+	return sentAutoReplies.has(evt.Info.ID, now) || sentAutoReplies.has(quotedID, now)
+}
+
+// handleAutoReply answers typed text with the first of the device's rules
+// that matches it or, in direct chats, with replyMessage. It never answers our
+// own messages or other auto-replies.
+func handleAutoReply(ctx context.Context, evt *events.Message, chatStorageRepo domainChatStorage.IChatStorageRepository, deviceID string, client *whatsmeow.Client, replyMessage string) {
 	if client == nil {
 		return
 	}
 
-	// Skip groups, broadcasts, and self messages
-	if utils.IsGroupJID(evt.Info.Chat.String()) || evt.Info.IsIncomingBroadcast() || evt.Info.IsFromMe {
+	// Skip broadcasts and self messages
+	if evt.Info.IsIncomingBroadcast() || evt.Info.IsFromMe {
 		return
 	}
 
-	// Only reply to direct 1:1 chats (e.g., *@s.whatsapp.net)
-	if evt.Info.Chat.Server != types.DefaultUserServer {
+	// Only reply in groups and direct 1:1 chats (e.g., *@s.whatsapp.net)
+	isGroup := utils.IsGroupJID(evt.Info.Chat.String())
+	if !isGroup && evt.Info.Chat.Server != types.DefaultUserServer {
 		return
 	}
 
@@ -41,43 +182,65 @@ func handleAutoReply(ctx context.Context, evt *events.Message, chatStorageRepo d
 		return
 	}
 
-	// Require actual typed text (not captions or synthetic labels)
-	hasText := false
+	// Require actual typed text (not captions or synthetic labels), and never
+	// answer another auto-reply
+	text := typedText(evt.Message)
+	if text == "" || isAutoReply(evt, time.Now()) {
+		return
+	}
 
-	innerMsg := utils.UnwrapMessage(evt.Message)
+	chatKey := deviceID + "|" + evt.Info.Chat.ToNonAD().String()
+	cooldownKey := chatKey
+	if rule := matchAutoReplyRule(autoReplyRules.get(ctx, chatStorageRepo, deviceID), text, isGroup); rule != nil {
+		replyMessage = rule.ReplyText
+		// Each rule has its own cooldown, so different questions all get answers
+		cooldownKey = chatKey + "|" + rule.ID
+	} else if isGroup {
+		// The auto-reply message is only for direct chats
+		return
+	}
+	if replyMessage == "" {
+		return
+	}
 
-	// Check for genuine typed text on the unwrapped content
-	if conv := innerMsg.GetConversation(); conv != "" {
-		hasText = true
-	} else if ext := innerMsg.GetExtendedTextMessage(); ext != nil && ext.GetText() != "" {
-		hasText = true
-	} else if protoMsg := innerMsg.GetProtocolMessage(); protoMsg != nil {
-		if edited := protoMsg.GetEditedMessage(); edited != nil {
-			if ext := edited.GetExtendedTextMessage(); ext != nil && ext.GetText() != "" {
-				hasText = true
-			} else if conv := edited.GetConversation(); conv != "" {
-				hasText = true
-			}
-		}
+	// Never reply to blocked contacts
+	if isBlockedChat(ctx, chatStorageRepo, evt.Info.Chat) {
+		log.Debugf("Skipping auto-reply to %s, contact is blocked", evt.Info.Chat)
+		return
 	}
-	if !hasText {
+
+	// Reply at most once per chat per cooldown
+	if !autoReplies.reserve(cooldownKey, time.Now(), config.WhatsappAutoReplyCooldown) {
+		log.Debugf("Skipping auto-reply to %s, chat is in cooldown", evt.Info.Chat)
 		return
 	}
 
 	// Format recipient JID
 	recipientJID := utils.FormatJID(evt.Info.Sender.String())
+	if isGroup {
+		recipientJID = evt.Info.Chat
+	}
 
-	// Send the auto-reply message
+	// Send the auto-reply message, quoting the message it answers
 	response, err := client.SendMessage(
 		ctx,
 		recipientJID,
-		&waE2E.Message{Conversation: proto.String(config.WhatsappAutoReplyMessage)},
+		&waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+			Text: proto.String(replyMessage),
+			ContextInfo: &waE2E.ContextInfo{
+				StanzaID:      proto.String(evt.Info.ID),
+				Participant:   proto.String(evt.Info.Sender.ToNonAD().String()),
+				QuotedMessage: evt.Message,
+			},
+		}},
 	)
 
 	if err != nil {
+		autoReplies.release(cooldownKey)
 		log.Errorf("Failed to send auto-reply message: %v", err)
 		return
 	}
+	sentAutoReplies.add(response.ID, time.Now())
 
 	// Store the auto-reply message in chat storage if send was successful
 	if chatStorageRepo != nil {
@@ -90,11 +253,12 @@ func handleAutoReply(ctx context.Context, evt *events.Message, chatStorageRepo d
 		// Store the sent auto-reply message
 		if err := chatStorageRepo.StoreSentMessageWithContext(
 			ctx,
-			response.ID,                     // Message ID from WhatsApp response
-			senderJID,                       // Our JID as sender
-			recipientJID.String(),           // Recipient JID
-			config.WhatsappAutoReplyMessage, // Auto-reply content
-			response.Timestamp,              // Timestamp from response
+			response.ID,           // Message ID from WhatsApp response
+			senderJID,             // Our JID as sender
+			recipientJID.String(), // Recipient JID
+			replyMessage,          // Auto-reply content
+			response.Timestamp,    // Timestamp from response
+			nil,                   // Text only, no media
 		); err != nil {
 			// Log storage error but don't fail the auto-reply
 			log.Errorf("Failed to store auto-reply message in chat storage: %v", err)
@@ -103,3 +267,22 @@ func handleAutoReply(ctx context.Context, evt *events.Message, chatStorageRepo d
 		}
 	}
 }
+
+// typedText is the text of a typed message, or of the edit of one. Captions
+// and other synthetic content don't count.
+func typedText(msg *waE2E.Message) string {
+	inner := utils.UnwrapMessage(msg)
+	if conv := inner.GetConversation(); conv != "" {
+		return conv
+	}
+	if text := inner.GetExtendedTextMessage().GetText(); text != "" {
+		return text
+	}
+	if edited := inner.GetProtocolMessage().GetEditedMessage(); edited != nil {
+		if text := edited.GetExtendedTextMessage().GetText(); text != "" {
+			return text
+		}
+		return edited.GetConversation()
+	}
+	return ""
+}
@@ -0,0 +1,138 @@
+package whatsapp
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+
+	domainAutoReply "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/autoreply"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+)
+
+// autoReplyRules caches the enabled rules of each device, with their regular
+// expressions compiled, so messages don't read the rules table or compile a
+// pattern. Changing a device's rules drops its entry.
+var autoReplyRules = newAutoReplyRuleCache()
+
+// InvalidateAutoReplyRules makes the next message of deviceID load its
+// auto-reply rules again.
+func InvalidateAutoReplyRules(deviceID string) {
+	autoReplyRules.invalidate(deviceID)
+}
+
+// autoReplyRule is a stored rule ready to be matched.
+type autoReplyRule struct {
+	ID         string
+	Pattern    string
+	MatchType  string
+	ReplyText  string
+	OnlyGroups bool
+	OnlyDM     bool
+	regex      *regexp.Regexp
+}
+
+// matches reports whether the rule replies to text sent in a group or a
+// direct chat.
+func (rule autoReplyRule) matches(text string, isGroup bool) bool {
+	if (rule.OnlyGroups && !isGroup) || (rule.OnlyDM && isGroup) {
+		return false
+	}
+	switch rule.MatchType {
+	case domainAutoReply.MatchExact:
+		return strings.EqualFold(strings.TrimSpace(text), strings.TrimSpace(rule.Pattern))
+	case domainAutoReply.MatchRegex:
+		return rule.regex != nil && rule.regex.MatchString(text)
+	}
+	return strings.Contains(strings.ToLower(text), strings.ToLower(rule.Pattern))
+}
+
+// matchAutoReplyRule returns the first of rules that replies to text, or nil.
+func matchAutoReplyRule(rules []autoReplyRule, text string, isGroup bool) *autoReplyRule {
+	for i := range rules {
+		if rules[i].matches(text, isGroup) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+type autoReplyRuleCache struct {
+	mu      sync.RWMutex
+	entries map[string][]autoReplyRule
+	// generation changes on every invalidation, so a lookup that raced an
+	// update doesn't cache the rules it read before the update
+	generation uint64
+}
+
+func newAutoReplyRuleCache() *autoReplyRuleCache {
+	return &autoReplyRuleCache{entries: make(map[string][]autoReplyRule)}
+}
+
+// get returns the enabled rules of deviceID in evaluation order, loading them
+// on first use. Lookup failures are not cached, so only the fallback message
+// replies until storage recovers.
+func (c *autoReplyRuleCache) get(ctx context.Context, chatStorageRepo domainChatStorage.IChatStorageRepository, deviceID string) []autoReplyRule {
+	if chatStorageRepo == nil || deviceID == "" {
+		return nil
+	}
+
+	c.mu.RLock()
+	rules, ok := c.entries[deviceID]
+	generation := c.generation
+	c.mu.RUnlock()
+	if ok {
+		return rules
+	}
+
+	records, err := chatStorageRepo.ListAutoReplyRules(ctx, deviceID)
+	if err != nil {
+		log.Warnf("Failed to load auto-reply rules of device %s: %v", deviceID, err)
+		return nil
+	}
+	rules = compileAutoReplyRules(deviceID, records)
+
+	c.mu.Lock()
+	if c.generation == generation {
+		c.entries[deviceID] = rules
+	}
+	c.mu.Unlock()
+	return rules
+}
+
+func (c *autoReplyRuleCache) invalidate(deviceID string) {
+	c.mu.Lock()
+	delete(c.entries, deviceID)
+	c.generation++
+	c.mu.Unlock()
+}
+
+// compileAutoReplyRules keeps the enabled records and compiles their regular
+// expressions. Patterns are validated when rules are saved, so one failing
+// here was stored some other way and is skipped.
+func compileAutoReplyRules(deviceID string, records []*domainChatStorage.AutoReplyRule) []autoReplyRule {
+	rules := make([]autoReplyRule, 0, len(records))
+	for _, record := range records {
+		if !record.Enabled {
+			continue
+		}
+		rule := autoReplyRule{
+			ID:         record.ID,
+			Pattern:    record.Pattern,
+			MatchType:  record.MatchType,
+			ReplyText:  record.ReplyText,
+			OnlyGroups: record.OnlyGroups,
+			OnlyDM:     record.OnlyDM,
+		}
+		if rule.MatchType == domainAutoReply.MatchRegex {
+			regex, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				log.Warnf("Skipping auto-reply rule %s of device %s, invalid pattern: %v", rule.ID, deviceID, err)
+				continue
+			}
+			rule.regex = regex
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
@@ -0,0 +1,93 @@
+package whatsapp
+
+import (
+	"context"
+	"testing"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+)
+
+type autoReplyRuleRepo struct {
+	domainChatStorage.IChatStorageRepository
+	rules map[string][]*domainChatStorage.AutoReplyRule
+	reads int
+}
+
+func (r *autoReplyRuleRepo) ListAutoReplyRules(_ context.Context, deviceID string) ([]*domainChatStorage.AutoReplyRule, error) {
+	r.reads++
+	return r.rules[deviceID], nil
+}
+
+func TestMatchAutoReplyRule(t *testing.T) {
+	rules := compileAutoReplyRules("dev-1", []*domainChatStorage.AutoReplyRule{
+		{ID: "off", Pattern: "price", MatchType: "contains", ReplyText: "disabled"},
+		{ID: "hours", Pattern: " Hours ", MatchType: "exact", ReplyText: "9-5", Enabled: true},
+		{ID: "order", Pattern: `^order #?\d+$`, MatchType: "regex", ReplyText: "on its way", Enabled: true},
+		{ID: "broken", Pattern: `(`, MatchType: "regex", ReplyText: "never", Enabled: true},
+		{ID: "group-price", Pattern: "price", MatchType: "contains", ReplyText: "see the pinned list", OnlyGroups: true, Enabled: true},
+		{ID: "dm-price", Pattern: "PRICE", MatchType: "contains", ReplyText: "sent you the list", OnlyDM: true, Enabled: true},
+		{ID: "any-price", Pattern: "price", MatchType: "contains", ReplyText: "shadowed", Enabled: true},
+	})
+	if len(rules) != 5 {
+		t.Fatalf("expected disabled and invalid rules to be skipped, got %d rules", len(rules))
+	}
+
+	tests := []struct {
+		text    string
+		isGroup bool
+		want    string
+	}{
+		{"hours", false, "hours"},
+		{"opening hours?", false, ""},
+		{"order #42", true, "order"},
+		{"my order #42", false, ""},
+		{"What's the price?", true, "group-price"},
+		{"What's the price?", false, "dm-price"},
+		{"hello", false, ""},
+	}
+	for _, tt := range tests {
+		got := matchAutoReplyRule(rules, tt.text, tt.isGroup)
+		gotID := ""
+		if got != nil {
+			gotID = got.ID
+		}
+		if gotID != tt.want {
+			t.Fatalf("%q (group %v): expected rule %q, got %q", tt.text, tt.isGroup, tt.want, gotID)
+		}
+	}
+}
+
+func TestAutoReplyRuleCache(t *testing.T) {
+	repo := &autoReplyRuleRepo{rules: map[string][]*domainChatStorage.AutoReplyRule{
+		"dev-1": {{ID: "r1", Pattern: "hi", MatchType: "contains", ReplyText: "hello", Enabled: true}},
+	}}
+	cache := newAutoReplyRuleCache()
+	ctx := context.Background()
+
+	if rules := cache.get(ctx, repo, "dev-1"); len(rules) != 1 {
+		t.Fatalf("expected one rule, got %d", len(rules))
+	}
+	cache.get(ctx, repo, "dev-1")
+	if repo.reads != 1 {
+		t.Fatalf("expected the rules to be cached, got %d reads", repo.reads)
+	}
+
+	repo.rules["dev-1"] = nil
+	cache.invalidate("dev-1")
+	if rules := cache.get(ctx, repo, "dev-1"); len(rules) != 0 || repo.reads != 2 {
+		t.Fatalf("expected invalidation to reload the rules, got %d rules after %d reads", len(rules), repo.reads)
+	}
+}
+
+func TestAutoReplyText(t *testing.T) {
+	extended := &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{Text: proto.String("order 7")}}
+	if got := typedText(extended); got != "order 7" {
+		t.Fatalf("expected extended text to be read, got %q", got)
+	}
+	image := &waE2E.Message{ImageMessage: &waE2E.ImageMessage{Caption: proto.String("price")}}
+	if got := typedText(image); got != "" {
+		t.Fatalf("expected captions not to trigger rules, got %q", got)
+	}
+}
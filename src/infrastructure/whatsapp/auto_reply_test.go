@@ -0,0 +1,177 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+)
+
+type deviceRecordRepo struct {
+	domainChatStorage.IChatStorageRepository
+	records map[string]*domainChatStorage.DeviceRecord
+	reads   int
+}
+
+func (r *deviceRecordRepo) GetDeviceRecord(_ context.Context, deviceID string) (*domainChatStorage.DeviceRecord, error) {
+	r.reads++
+	return r.records[deviceID], nil
+}
+
+func TestResolveMessageAutomation(t *testing.T) {
+	originalReply, originalMarkRead := config.WhatsappAutoReplyMessage, config.WhatsappAutoMarkRead
+	t.Cleanup(func() {
+		config.WhatsappAutoReplyMessage, config.WhatsappAutoMarkRead = originalReply, originalMarkRead
+	})
+	config.WhatsappAutoReplyMessage, config.WhatsappAutoMarkRead = "Back soon", true
+
+	if got := resolveMessageAutomation(domainChatStorage.DeviceSettings{}); got.ReplyMessage != "Back soon" || !got.MarkRead {
+		t.Fatalf("expected the global automation without device settings, got %+v", got)
+	}
+
+	off, silent := false, ""
+	got := resolveMessageAutomation(domainChatStorage.DeviceSettings{AutoReplyMessage: &silent, AutoMarkRead: &off})
+	if got.ReplyMessage != "" || got.MarkRead {
+		t.Fatalf("expected the device to turn both off, got %+v", got)
+	}
+}
+
+func TestReplyCooldown(t *testing.T) {
+	cooldown := newReplyCooldown()
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	if !cooldown.reserve("dev|628123@s.whatsapp.net", now, time.Hour) {
+		t.Fatal("expected the first reply to be allowed")
+	}
+	if cooldown.reserve("dev|628123@s.whatsapp.net", now.Add(30*time.Minute), time.Hour) {
+		t.Fatal("expected a second reply within the cooldown to be held back")
+	}
+	if !cooldown.reserve("dev|628456@s.whatsapp.net", now.Add(30*time.Minute), time.Hour) {
+		t.Fatal("expected other chats to keep their own cooldown")
+	}
+	if !cooldown.reserve("dev|628123@s.whatsapp.net", now.Add(time.Hour), time.Hour) {
+		t.Fatal("expected a reply once the cooldown passed")
+	}
+
+	cooldown.release("dev|628123@s.whatsapp.net")
+	if !cooldown.reserve("dev|628123@s.whatsapp.net", now.Add(time.Hour), time.Hour) {
+		t.Fatal("expected a released chat to get a reply again")
+	}
+	if !cooldown.reserve("dev|628123@s.whatsapp.net", now.Add(time.Hour), 0) {
+		t.Fatal("expected a zero cooldown to allow every reply")
+	}
+}
+
+func TestSentReplies(t *testing.T) {
+	sent := newSentReplies(2, time.Hour)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	sent.add("3EB0A", now)
+	if !sent.has("3EB0A", now.Add(59*time.Minute)) || sent.has("3EB0A", now.Add(time.Hour)) {
+		t.Fatal("expected a sent reply to be remembered for the TTL only")
+	}
+	if sent.has("", now) {
+		t.Fatal("expected an empty ID never to match")
+	}
+
+	sent.add("3EB0B", now.Add(time.Minute))
+	sent.add("3EB0C", now.Add(2*time.Minute))
+	if sent.has("3EB0A", now.Add(2*time.Minute)) || !sent.has("3EB0B", now.Add(2*time.Minute)) || !sent.has("3EB0C", now.Add(2*time.Minute)) {
+		t.Fatal("expected the oldest reply to be dropped once full")
+	}
+}
+
+func TestIsAutoReply(t *testing.T) {
+	now := time.Now()
+	sentAutoReplies.add("3EB0AUTO", now)
+
+	message := func(id, quotedID string) *events.Message {
+		evt := &events.Message{Info: types.MessageInfo{ID: id}, Message: &waE2E.Message{Conversation: proto.String("Back soon")}}
+		if quotedID != "" {
+			evt.Message = &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+				Text:        proto.String("Back soon"),
+				ContextInfo: &waE2E.ContextInfo{StanzaID: proto.String(quotedID)},
+			}}
+		}
+		return evt
+	}
+	if !isAutoReply(message("3EB0AUTO", ""), now) {
+		t.Fatal("expected our own auto-reply to be recognized")
+	}
+	if !isAutoReply(message("3EB0OTHER", "3EB0AUTO"), now) {
+		t.Fatal("expected a message quoting our auto-reply to be recognized")
+	}
+	if isAutoReply(message("3EB0OTHER", "3EB0TYPED"), now) || isAutoReply(message("3EB0OTHER", ""), now) {
+		t.Fatal("expected other messages not to be auto-replies")
+	}
+}
+
+func TestDeviceSettingsCache(t *testing.T) {
+	ctx := context.Background()
+	reply := "Out of office"
+	repo := &deviceRecordRepo{records: map[string]*domainChatStorage.DeviceRecord{
+		"dev-1": {DeviceID: "dev-1", DeviceSettings: domainChatStorage.DeviceSettings{AutoReplyMessage: &reply}},
+	}}
+	cache := newDeviceSettingsCache()
+
+	for range 2 {
+		settings := cache.get(ctx, repo, "dev-1")
+		if settings.AutoReplyMessage == nil || *settings.AutoReplyMessage != reply {
+			t.Fatalf("unexpected settings: %+v", settings)
+		}
+	}
+	if repo.reads != 1 {
+		t.Fatalf("expected one repository read, got %d", repo.reads)
+	}
+
+	updated := "Closed today"
+	repo.records["dev-1"].AutoReplyMessage = &updated
+	cache.invalidate("dev-1")
+	if settings := cache.get(ctx, repo, "dev-1"); settings.AutoReplyMessage == nil || *settings.AutoReplyMessage != updated {
+		t.Fatalf("expected the updated settings after invalidation, got %+v", settings)
+	}
+
+	if settings := cache.get(ctx, repo, "unknown"); settings.AutoReplyMessage != nil {
+		t.Fatalf("expected empty settings for an unregistered device, got %+v", settings)
+	}
+}
+
+type blockedChatRepo struct {
+	domainChatStorage.IChatStorageRepository
+	chats map[string]*domainChatStorage.Chat
+	err   error
+}
+
+func (r *blockedChatRepo) GetChat(_ context.Context, jid string) (*domainChatStorage.Chat, error) {
+	return r.chats[jid], r.err
+}
+
+func TestIsBlockedChat(t *testing.T) {
+	ctx := context.Background()
+	repo := &blockedChatRepo{chats: map[string]*domainChatStorage.Chat{
+		"628111@s.whatsapp.net": {JID: "628111@s.whatsapp.net", Blocked: true},
+		"628222@s.whatsapp.net": {JID: "628222@s.whatsapp.net"},
+	}}
+
+	if !isBlockedChat(ctx, repo, types.NewADJID("628111", 0, 3)) {
+		t.Fatal("expected the blocked contact to be skipped whatever device it wrote from")
+	}
+	if isBlockedChat(ctx, repo, types.NewJID("628222", types.DefaultUserServer)) {
+		t.Fatal("expected other contacts to get replies")
+	}
+	if isBlockedChat(ctx, repo, types.NewJID("628333", types.DefaultUserServer)) {
+		t.Fatal("expected chats that are not stored to get replies")
+	}
+
+	repo.err = errors.New("database is locked")
+	if !isBlockedChat(ctx, repo, types.NewJID("628222", types.DefaultUserServer)) {
+		t.Fatal("expected a failed lookup to hold the reply back")
+	}
+}
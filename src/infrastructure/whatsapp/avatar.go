@@ -0,0 +1,63 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// FetchAvatarID returns the ID of the profile picture of the logged in
+// account, or an empty string when it has none.
+func FetchAvatarID(ctx context.Context, client *whatsmeow.Client) (string, error) {
+	if client == nil || client.Store == nil || client.Store.ID == nil {
+		return "", nil
+	}
+	info, err := client.GetProfilePictureInfo(ctx, client.Store.ID.ToNonAD(), &whatsmeow.GetProfilePictureParams{Preview: true})
+	if errors.Is(err, whatsmeow.ErrProfilePictureNotSet) {
+		return "", nil
+	}
+	if err != nil || info == nil {
+		return "", err
+	}
+	return info.ID, nil
+}
+
+// refreshAvatarID looks up the profile picture of instance in the background
+// after connecting, so the device list can show it.
+func refreshAvatarID(instance *DeviceInstance) {
+	client := instance.GetClient()
+	if client == nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		id, err := FetchAvatarID(ctx, client)
+		if err != nil {
+			log.Warnf("Failed to fetch profile picture of device %s: %v", instance.ID(), err)
+			return
+		}
+		instance.SetAvatarID(id)
+	}()
+}
+
+// handleOwnPicture tracks profile picture changes of the account itself, e.g.
+// made on the phone.
+func handleOwnPicture(evt *events.Picture, instance *DeviceInstance) {
+	client := instance.GetClient()
+	if client == nil || client.Store == nil || client.Store.ID == nil {
+		return
+	}
+	if evt.JID.User != client.Store.ID.User && evt.JID.User != client.Store.LID.User {
+		return
+	}
+	if evt.Remove {
+		instance.SetAvatarID("")
+		return
+	}
+	instance.SetAvatarID(evt.PictureID)
+}
@@ -0,0 +1,83 @@
+package whatsapp
+
+import (
+	"context"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// StoreBlocklist marks the stored chats of the blocked users and clears the
+// flag of every other chat. Blocked LIDs are resolved to the phone number
+// chats are stored under when the mapping is known.
+func StoreBlocklist(ctx context.Context, repo domainChatStorage.IChatStorageRepository, deviceID string, client *whatsmeow.Client, blocklist *types.Blocklist) error {
+	if repo == nil || blocklist == nil {
+		return nil
+	}
+	jids := make([]string, 0, len(blocklist.JIDs))
+	for _, jid := range blocklist.JIDs {
+		jids = append(jids, NormalizeJIDFromLID(ctx, jid, client).String())
+	}
+	return repo.SetBlockedChats(ctx, deviceID, jids)
+}
+
+// SyncBlocklist fetches the blocklist of client and stores it.
+func SyncBlocklist(ctx context.Context, client *whatsmeow.Client, repo domainChatStorage.IChatStorageRepository, deviceID string) (*types.Blocklist, error) {
+	if client == nil || client.Store == nil || client.Store.ID == nil {
+		return nil, pkgError.ErrWaCLI
+	}
+	blocklist, err := client.GetBlocklist(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return blocklist, StoreBlocklist(ctx, repo, deviceID, client, blocklist)
+}
+
+// refreshBlocklist syncs the blocklist of instance in the background, e.g.
+// after connecting or when WhatsApp asks for it to be fetched again.
+func refreshBlocklist(instance *DeviceInstance) {
+	client := instance.GetClient()
+	repo := instance.GetChatStorage()
+	if client == nil || repo == nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(ContextWithDevice(context.Background(), instance), 2*time.Minute)
+		defer cancel()
+		if _, err := SyncBlocklist(ctx, client, repo, ""); err != nil {
+			log.Warnf("Failed to sync blocklist for device %s: %v", instance.ID(), err)
+		}
+	}()
+}
+
+// handleBlocklist mirrors blocks and unblocks made on other devices into chat
+// storage. A modify action carries no changes, so the list is fetched again.
+func handleBlocklist(ctx context.Context, evt *events.Blocklist, instance *DeviceInstance) {
+	if evt.Action == events.BlocklistActionModify {
+		refreshBlocklist(instance)
+		return
+	}
+	for _, change := range evt.Changes {
+		blocked := change.Action == events.BlocklistChangeActionBlock
+		updateChatFlags(ctx, change.JID, domainChatStorage.ChatFlags{Blocked: &blocked}, instance.GetChatStorage(), instance.GetClient())
+	}
+}
+
+// isBlockedChat reports whether the stored chat of jid is blocked. Lookup
+// failures count as blocked, so automated replies err on the side of silence.
+func isBlockedChat(ctx context.Context, chatStorageRepo domainChatStorage.IChatStorageRepository, jid types.JID) bool {
+	if chatStorageRepo == nil {
+		return false
+	}
+	chat, err := chatStorageRepo.GetChat(ctx, jid.ToNonAD().String())
+	if err != nil {
+		log.Errorf("Failed to look up chat %s: %v", jid, err)
+		return true
+	}
+	return chat != nil && chat.Blocked
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 )
@@ -40,86 +41,361 @@ func (r *deviceChatStorage) CreateMessage(ctx context.Context, evt *events.Messa
 	return r.base.CreateMessage(ctx, evt)
 }
 
-func (r *deviceChatStorage) StoreChat(chat *domainChatStorage.Chat) error {
-	return r.base.StoreChat(r.withDeviceChat(chat))
+func (r *deviceChatStorage) StoreChat(ctx context.Context, chat *domainChatStorage.Chat) error {
+	return r.base.StoreChat(ctx, r.withDeviceChat(chat))
 }
 
-func (r *deviceChatStorage) GetChat(jid string) (*domainChatStorage.Chat, error) {
-	return r.base.GetChatByDevice(r.deviceID, jid)
+func (r *deviceChatStorage) GetChat(ctx context.Context, jid string) (*domainChatStorage.Chat, error) {
+	return r.base.GetChatByDevice(ctx, r.deviceID, jid)
 }
 
-func (r *deviceChatStorage) GetChatByDevice(deviceID, jid string) (*domainChatStorage.Chat, error) {
-	return r.base.GetChatByDevice(deviceID, jid)
+func (r *deviceChatStorage) GetChatByDevice(ctx context.Context, deviceID, jid string) (*domainChatStorage.Chat, error) {
+	return r.base.GetChatByDevice(ctx, deviceID, jid)
 }
 
-func (r *deviceChatStorage) GetChats(filter *domainChatStorage.ChatFilter) ([]*domainChatStorage.Chat, error) {
+func (r *deviceChatStorage) GetChats(ctx context.Context, filter *domainChatStorage.ChatFilter) ([]*domainChatStorage.Chat, error) {
 	if filter != nil && filter.DeviceID == "" {
 		filter.DeviceID = r.deviceID
 	}
-	return r.base.GetChats(filter)
+	return r.base.GetChats(ctx, filter)
 }
 
-func (r *deviceChatStorage) DeleteChat(jid string) error {
-	return r.base.DeleteChatByDevice(r.deviceID, jid)
+func (r *deviceChatStorage) CountChats(ctx context.Context, filter *domainChatStorage.ChatFilter) (int64, error) {
+	if filter != nil && filter.DeviceID == "" {
+		filter.DeviceID = r.deviceID
+	}
+	return r.base.CountChats(ctx, filter)
+}
+
+func (r *deviceChatStorage) DeleteChat(ctx context.Context, jid string) error {
+	return r.base.DeleteChatByDevice(ctx, r.deviceID, jid)
+}
+
+func (r *deviceChatStorage) DeleteChatByDevice(ctx context.Context, deviceID, jid string) error {
+	return r.base.DeleteChatByDevice(ctx, deviceID, jid)
+}
+
+func (r *deviceChatStorage) UpdateChatFlags(ctx context.Context, deviceID, jid string, flags domainChatStorage.ChatFlags) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.UpdateChatFlags(ctx, deviceID, jid, flags)
+}
+
+func (r *deviceChatStorage) SetBlockedChats(ctx context.Context, deviceID string, jids []string) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.SetBlockedChats(ctx, deviceID, jids)
+}
+
+func (r *deviceChatStorage) MarkChatRead(ctx context.Context, deviceID, jid string) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.MarkChatRead(ctx, deviceID, jid)
+}
+
+func (r *deviceChatStorage) MarkMessagesRead(ctx context.Context, deviceID, chatJID string, ids []string) (int, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.MarkMessagesRead(ctx, deviceID, chatJID, ids)
+}
+
+func (r *deviceChatStorage) SetEphemeralExpiration(ctx context.Context, deviceID, jid string, expiration uint32) (uint32, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.SetEphemeralExpiration(ctx, deviceID, jid, expiration)
+}
+
+func (r *deviceChatStorage) StoreMessage(ctx context.Context, message *domainChatStorage.Message) error {
+	return r.base.StoreMessage(ctx, message)
+}
+
+func (r *deviceChatStorage) StoreMessagesBatch(ctx context.Context, messages []*domainChatStorage.Message) (int, error) {
+	return r.base.StoreMessagesBatch(ctx, messages)
 }
 
-func (r *deviceChatStorage) DeleteChatByDevice(deviceID, jid string) error {
-	return r.base.DeleteChatByDevice(deviceID, jid)
+func (r *deviceChatStorage) GetMessageByID(ctx context.Context, id string) (*domainChatStorage.Message, error) {
+	return r.base.GetMessageByID(ctx, id)
 }
 
-func (r *deviceChatStorage) StoreMessage(message *domainChatStorage.Message) error {
-	return r.base.StoreMessage(message)
+func (r *deviceChatStorage) GetMessageByDevice(ctx context.Context, deviceID, id string) (*domainChatStorage.Message, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.GetMessageByDevice(ctx, deviceID, id)
 }
 
-func (r *deviceChatStorage) StoreMessagesBatch(messages []*domainChatStorage.Message) error {
-	return r.base.StoreMessagesBatch(messages)
+func (r *deviceChatStorage) GetOldestMessage(ctx context.Context, deviceID, chatJID string) (*domainChatStorage.Message, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.GetOldestMessage(ctx, deviceID, chatJID)
 }
 
-func (r *deviceChatStorage) GetMessageByID(id string) (*domainChatStorage.Message, error) {
-	return r.base.GetMessageByID(id)
+func (r *deviceChatStorage) GetMessages(ctx context.Context, filter *domainChatStorage.MessageFilter) ([]*domainChatStorage.Message, error) {
+	if filter != nil && filter.DeviceID == "" {
+		filter.DeviceID = r.deviceID
+	}
+	return r.base.GetMessages(ctx, filter)
 }
 
-func (r *deviceChatStorage) GetMessages(filter *domainChatStorage.MessageFilter) ([]*domainChatStorage.Message, error) {
+func (r *deviceChatStorage) StreamMessages(ctx context.Context, filter *domainChatStorage.MessageFilter, fn func(*domainChatStorage.Message) error) error {
 	if filter != nil && filter.DeviceID == "" {
 		filter.DeviceID = r.deviceID
 	}
-	return r.base.GetMessages(filter)
+	return r.base.StreamMessages(ctx, filter, fn)
+}
+
+func (r *deviceChatStorage) SearchMessages(ctx context.Context, filter *domainChatStorage.MessageFilter, searchText string) ([]*domainChatStorage.Message, error) {
+	if filter != nil && filter.DeviceID == "" {
+		filter.DeviceID = r.deviceID
+	}
+	return r.base.SearchMessages(ctx, filter, searchText)
+}
+
+func (r *deviceChatStorage) SearchMessagesGlobal(ctx context.Context, deviceID, searchText string, limit, offset int) ([]*domainChatStorage.SearchResult, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.SearchMessagesGlobal(ctx, deviceID, searchText, limit, offset)
+}
+
+func (r *deviceChatStorage) DeleteMessage(ctx context.Context, id, chatJID string) error {
+	return r.base.DeleteMessageByDevice(ctx, r.deviceID, id, chatJID)
+}
+
+func (r *deviceChatStorage) DeleteMessageByDevice(ctx context.Context, deviceID, id, chatJID string) error {
+	return r.base.DeleteMessageByDevice(ctx, deviceID, id, chatJID)
+}
+
+func (r *deviceChatStorage) StoreSentMessageWithContext(ctx context.Context, messageID string, senderJID string, recipientJID string, content string, timestamp time.Time, media *domainChatStorage.MediaInfo) error {
+	return r.base.StoreSentMessageWithContext(ctx, messageID, senderJID, recipientJID, content, timestamp, media)
+}
+
+func (r *deviceChatStorage) StoreReaction(ctx context.Context, reaction *domainChatStorage.Reaction) error {
+	if reaction != nil && reaction.DeviceID == "" {
+		reaction.DeviceID = r.deviceID
+	}
+	return r.base.StoreReaction(ctx, reaction)
+}
+
+func (r *deviceChatStorage) GetReactionsForMessage(ctx context.Context, deviceID, chatJID, messageID string) ([]*domainChatStorage.Reaction, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.GetReactionsForMessage(ctx, deviceID, chatJID, messageID)
+}
+
+func (r *deviceChatStorage) StoreMessageEdit(ctx context.Context, deviceID, id, chatJID, content string, editedAt time.Time) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.StoreMessageEdit(ctx, deviceID, id, chatJID, content, editedAt)
+}
+
+func (r *deviceChatStorage) GetMessageEditHistory(ctx context.Context, deviceID, id, chatJID string) ([]*domainChatStorage.MessageEdit, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.GetMessageEditHistory(ctx, deviceID, id, chatJID)
+}
+
+func (r *deviceChatStorage) MarkMessageRevoked(ctx context.Context, deviceID, id, chatJID string, keepContent bool) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.MarkMessageRevoked(ctx, deviceID, id, chatJID, keepContent)
+}
+
+func (r *deviceChatStorage) SetMessageMediaPath(ctx context.Context, deviceID, id, chatJID, mediaPath string, size int64, downloadedAt time.Time) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.SetMessageMediaPath(ctx, deviceID, id, chatJID, mediaPath, size, downloadedAt)
+}
+
+func (r *deviceChatStorage) SetMessageMediaURL(ctx context.Context, deviceID, id, chatJID, url string) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.SetMessageMediaURL(ctx, deviceID, id, chatJID, url)
+}
+
+func (r *deviceChatStorage) SetMessagePinnedUntil(ctx context.Context, deviceID, id, chatJID string, pinnedUntil *time.Time) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.SetMessagePinnedUntil(ctx, deviceID, id, chatJID, pinnedUntil)
+}
+
+func (r *deviceChatStorage) GetPinnedMessages(ctx context.Context, deviceID, chatJID string, now time.Time) ([]*domainChatStorage.Message, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.GetPinnedMessages(ctx, deviceID, chatJID, now)
+}
+
+func (r *deviceChatStorage) StoreReceipt(ctx context.Context, receipt *domainChatStorage.Receipt) error {
+	if receipt != nil && receipt.DeviceID == "" {
+		receipt.DeviceID = r.deviceID
+	}
+	return r.base.StoreReceipt(ctx, receipt)
 }
 
-func (r *deviceChatStorage) SearchMessages(deviceID, chatJID, searchText string, limit int) ([]*domainChatStorage.Message, error) {
-	targetDeviceID := deviceID
-	if targetDeviceID == "" {
-		targetDeviceID = r.deviceID
+func (r *deviceChatStorage) GetReceiptsForMessage(ctx context.Context, deviceID, chatJID, messageID string) ([]*domainChatStorage.Receipt, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
 	}
-	return r.base.SearchMessages(targetDeviceID, chatJID, searchText, limit)
+	return r.base.GetReceiptsForMessage(ctx, deviceID, chatJID, messageID)
 }
 
-func (r *deviceChatStorage) DeleteMessage(id, chatJID string) error {
-	return r.base.DeleteMessageByDevice(r.deviceID, id, chatJID)
+func (r *deviceChatStorage) StorePoll(ctx context.Context, poll *domainChatStorage.Poll) error {
+	if poll != nil && poll.DeviceID == "" {
+		poll.DeviceID = r.deviceID
+	}
+	return r.base.StorePoll(ctx, poll)
 }
 
-func (r *deviceChatStorage) DeleteMessageByDevice(deviceID, id, chatJID string) error {
-	return r.base.DeleteMessageByDevice(deviceID, id, chatJID)
+func (r *deviceChatStorage) GetPoll(ctx context.Context, deviceID, chatJID, messageID string) (*domainChatStorage.Poll, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.GetPoll(ctx, deviceID, chatJID, messageID)
 }
 
-func (r *deviceChatStorage) StoreSentMessageWithContext(ctx context.Context, messageID string, senderJID string, recipientJID string, content string, timestamp time.Time) error {
-	return r.base.StoreSentMessageWithContext(ctx, messageID, senderJID, recipientJID, content, timestamp)
+func (r *deviceChatStorage) StorePollVote(ctx context.Context, vote *domainChatStorage.PollVote) error {
+	if vote != nil && vote.DeviceID == "" {
+		vote.DeviceID = r.deviceID
+	}
+	return r.base.StorePollVote(ctx, vote)
 }
 
-func (r *deviceChatStorage) GetChatMessageCount(chatJID string) (int64, error) {
-	return r.base.GetChatMessageCountByDevice(r.deviceID, chatJID)
+func (r *deviceChatStorage) GetPollVotes(ctx context.Context, deviceID, chatJID, messageID string) ([]*domainChatStorage.PollVote, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.GetPollVotes(ctx, deviceID, chatJID, messageID)
 }
 
-func (r *deviceChatStorage) GetChatMessageCountByDevice(deviceID, chatJID string) (int64, error) {
-	return r.base.GetChatMessageCountByDevice(deviceID, chatJID)
+func (r *deviceChatStorage) StoreCall(ctx context.Context, call *domainChatStorage.Call) error {
+	if call != nil && call.DeviceID == "" {
+		call.DeviceID = r.deviceID
+	}
+	return r.base.StoreCall(ctx, call)
 }
 
-func (r *deviceChatStorage) GetTotalMessageCount() (int64, error) {
-	return r.base.GetTotalMessageCount()
+func (r *deviceChatStorage) UpdateCallOutcome(ctx context.Context, deviceID, callID, outcome string, at time.Time) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.UpdateCallOutcome(ctx, deviceID, callID, outcome, at)
 }
 
-func (r *deviceChatStorage) GetTotalChatCount() (int64, error) {
-	return r.base.GetTotalChatCount()
+func (r *deviceChatStorage) EndCall(ctx context.Context, deviceID, callID string, endedAt time.Time) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.EndCall(ctx, deviceID, callID, endedAt)
+}
+
+func (r *deviceChatStorage) GetCalls(ctx context.Context, filter *domainChatStorage.CallFilter) ([]*domainChatStorage.Call, error) {
+	if filter != nil && filter.DeviceID == "" {
+		filter.DeviceID = r.deviceID
+	}
+	return r.base.GetCalls(ctx, filter)
+}
+
+func (r *deviceChatStorage) StoreContacts(ctx context.Context, contacts []*domainChatStorage.Contact) (int, error) {
+	for _, contact := range contacts {
+		if contact != nil && contact.DeviceID == "" {
+			contact.DeviceID = r.deviceID
+		}
+	}
+	return r.base.StoreContacts(ctx, contacts)
+}
+
+func (r *deviceChatStorage) GetContacts(ctx context.Context, filter *domainChatStorage.ContactFilter) ([]*domainChatStorage.Contact, error) {
+	if filter != nil && filter.DeviceID == "" {
+		filter.DeviceID = r.deviceID
+	}
+	return r.base.GetContacts(ctx, filter)
+}
+
+func (r *deviceChatStorage) CountContacts(ctx context.Context, filter *domainChatStorage.ContactFilter) (int64, error) {
+	if filter != nil && filter.DeviceID == "" {
+		filter.DeviceID = r.deviceID
+	}
+	return r.base.CountContacts(ctx, filter)
+}
+
+func (r *deviceChatStorage) SyncGroupParticipants(ctx context.Context, deviceID, groupJID string, participants []*domainChatStorage.GroupParticipant) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.SyncGroupParticipants(ctx, deviceID, groupJID, participants)
+}
+
+func (r *deviceChatStorage) UpdateGroupParticipants(ctx context.Context, deviceID, groupJID string, change domainChatStorage.GroupParticipantChange) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.UpdateGroupParticipants(ctx, deviceID, groupJID, change)
+}
+
+func (r *deviceChatStorage) GetGroupParticipants(ctx context.Context, deviceID, groupJID string) ([]*domainChatStorage.GroupParticipant, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.GetGroupParticipants(ctx, deviceID, groupJID)
+}
+
+func (r *deviceChatStorage) MergeChats(ctx context.Context, deviceID, fromJID, toJID string) (int64, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.MergeChats(ctx, deviceID, fromJID, toJID)
+}
+
+func (r *deviceChatStorage) StoreLIDMapping(ctx context.Context, deviceID, lid, pnJID string) error {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.StoreLIDMapping(ctx, deviceID, lid, pnJID)
+}
+
+func (r *deviceChatStorage) GetPNForLID(ctx context.Context, deviceID, lid string) (string, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.GetPNForLID(ctx, deviceID, lid)
+}
+
+func (r *deviceChatStorage) GetChatMessageCount(ctx context.Context, chatJID string) (int64, error) {
+	return r.base.GetChatMessageCountByDevice(ctx, r.deviceID, chatJID)
+}
+
+func (r *deviceChatStorage) GetChatMessageCountByDevice(ctx context.Context, deviceID, chatJID string) (int64, error) {
+	return r.base.GetChatMessageCountByDevice(ctx, deviceID, chatJID)
+}
+
+func (r *deviceChatStorage) GetTotalMessageCount(ctx context.Context) (int64, error) {
+	return r.base.GetTotalMessageCountByDevice(ctx, r.deviceID)
+}
+
+func (r *deviceChatStorage) GetTotalMessageCountByDevice(ctx context.Context, deviceID string) (int64, error) {
+	return r.base.GetTotalMessageCountByDevice(ctx, deviceID)
+}
+
+func (r *deviceChatStorage) GetTotalChatCount(ctx context.Context) (int64, error) {
+	return r.base.GetTotalChatCountByDevice(ctx, r.deviceID)
+}
+
+func (r *deviceChatStorage) GetTotalChatCountByDevice(ctx context.Context, deviceID string) (int64, error) {
+	return r.base.GetTotalChatCountByDevice(ctx, deviceID)
 }
 
 func (r *deviceChatStorage) GetChatNameWithPushName(jid types.JID, chatJID string, senderUser string, pushName string) string {
@@ -130,23 +406,37 @@ func (r *deviceChatStorage) GetChatNameWithPushNameByDevice(deviceID string, jid
 	return r.base.GetChatNameWithPushNameByDevice(deviceID, jid, chatJID, senderUser, pushName)
 }
 
-func (r *deviceChatStorage) GetStorageStatistics() (chatCount int64, messageCount int64, err error) {
-	return r.base.GetStorageStatistics()
+func (r *deviceChatStorage) GetStorageStatistics(ctx context.Context) (chatCount int64, messageCount int64, err error) {
+	stats, err := r.base.GetStorageStatisticsByDevice(ctx, r.deviceID)
+	if err != nil {
+		return 0, 0, err
+	}
+	return stats.ChatCount, stats.MessageCount, nil
 }
 
-func (r *deviceChatStorage) TruncateAllChats() error {
-	return r.base.TruncateAllChats()
+func (r *deviceChatStorage) GetStorageStatisticsByDevice(ctx context.Context, deviceID string) (*domainChatStorage.StorageStatistics, error) {
+	return r.base.GetStorageStatisticsByDevice(ctx, deviceID)
 }
 
-func (r *deviceChatStorage) TruncateAllDataWithLogging(logPrefix string) error {
-	return r.base.TruncateAllDataWithLogging(logPrefix)
+// TruncateAllChats only clears this device's chats; other devices share the base tables.
+func (r *deviceChatStorage) TruncateAllChats(ctx context.Context) error {
+	return r.base.DeleteDeviceData(ctx, r.deviceID)
 }
 
-func (r *deviceChatStorage) InitializeSchema() error {
-	return r.base.InitializeSchema()
+// TruncateAllDataWithLogging is scoped to this device, see TruncateAllChats.
+func (r *deviceChatStorage) TruncateAllDataWithLogging(ctx context.Context, logPrefix, reportPath string) error {
+	if err := r.base.DeleteDeviceData(ctx, r.deviceID); err != nil {
+		return err
+	}
+	logrus.Infof("[%s] Chat storage cleared for device %s", logPrefix, r.deviceID)
+	return nil
 }
 
-func (r *deviceChatStorage) DeleteDeviceData(deviceID string) error {
+func (r *deviceChatStorage) InitializeSchema(ctx context.Context) error {
+	return r.base.InitializeSchema(ctx)
+}
+
+func (r *deviceChatStorage) DeleteDeviceData(ctx context.Context, deviceID string) error {
 	if r.base == nil {
 		return nil
 	}
@@ -154,21 +444,154 @@ func (r *deviceChatStorage) DeleteDeviceData(deviceID string) error {
 	if target == "" {
 		target = r.deviceID
 	}
-	return r.base.DeleteDeviceData(target)
+	return r.base.DeleteDeviceData(ctx, target)
+}
+
+// DedupeChats is not scoped to the device, it cleans up the whole storage.
+func (r *deviceChatStorage) DedupeChats(ctx context.Context, dryRun bool) ([]*domainChatStorage.ChatMerge, error) {
+	return r.base.DedupeChats(ctx, dryRun)
+}
+
+func (r *deviceChatStorage) PruneMessagesBefore(ctx context.Context, deviceID string, cutoff time.Time) (int64, error) {
+	if deviceID == "" {
+		deviceID = r.deviceID
+	}
+	return r.base.PruneMessagesBefore(ctx, deviceID, cutoff)
+}
+
+func (r *deviceChatStorage) SaveDeviceRecord(ctx context.Context, record *domainChatStorage.DeviceRecord) error {
+	return r.base.SaveDeviceRecord(ctx, record)
+}
+
+func (r *deviceChatStorage) ListDeviceRecords(ctx context.Context) ([]*domainChatStorage.DeviceRecord, error) {
+	return r.base.ListDeviceRecords(ctx)
+}
+
+func (r *deviceChatStorage) GetDeviceRecord(ctx context.Context, deviceID string) (*domainChatStorage.DeviceRecord, error) {
+	return r.base.GetDeviceRecord(ctx, deviceID)
+}
+
+func (r *deviceChatStorage) DeleteDeviceRecord(ctx context.Context, deviceID string) error {
+	return r.base.DeleteDeviceRecord(ctx, deviceID)
+}
+
+func (r *deviceChatStorage) SaveDeviceSettings(ctx context.Context, deviceID string, settings domainChatStorage.DeviceSettings) error {
+	return r.base.SaveDeviceSettings(ctx, deviceID, settings)
+}
+
+func (r *deviceChatStorage) SaveDeviceWebhook(ctx context.Context, deviceID string, webhook domainChatStorage.DeviceWebhook) error {
+	return r.base.SaveDeviceWebhook(ctx, deviceID, webhook)
+}
+
+// API keys are shared by every device
+func (r *deviceChatStorage) StoreAPIKey(ctx context.Context, key *domainChatStorage.APIKey) error {
+	return r.base.StoreAPIKey(ctx, key)
+}
+
+func (r *deviceChatStorage) ListAPIKeys(ctx context.Context) ([]*domainChatStorage.APIKey, error) {
+	return r.base.ListAPIKeys(ctx)
+}
+
+func (r *deviceChatStorage) GetAPIKeyByHash(ctx context.Context, keyHash string) (*domainChatStorage.APIKey, error) {
+	return r.base.GetAPIKeyByHash(ctx, keyHash)
+}
+
+func (r *deviceChatStorage) RevokeAPIKey(ctx context.Context, id string) (bool, error) {
+	return r.base.RevokeAPIKey(ctx, id)
+}
+
+func (r *deviceChatStorage) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	return r.base.TouchAPIKey(ctx, id, usedAt)
+}
+
+func (r *deviceChatStorage) StoreAutoReplyRule(ctx context.Context, rule *domainChatStorage.AutoReplyRule) error {
+	return r.base.StoreAutoReplyRule(ctx, rule)
+}
+
+func (r *deviceChatStorage) ListAutoReplyRules(ctx context.Context, deviceID string) ([]*domainChatStorage.AutoReplyRule, error) {
+	return r.base.ListAutoReplyRules(ctx, deviceID)
+}
+
+func (r *deviceChatStorage) GetAutoReplyRule(ctx context.Context, deviceID, id string) (*domainChatStorage.AutoReplyRule, error) {
+	return r.base.GetAutoReplyRule(ctx, deviceID, id)
+}
+
+func (r *deviceChatStorage) DeleteAutoReplyRule(ctx context.Context, deviceID, id string) (bool, error) {
+	return r.base.DeleteAutoReplyRule(ctx, deviceID, id)
+}
+
+func (r *deviceChatStorage) StoreLabel(ctx context.Context, label *domainChatStorage.Label) error {
+	return r.base.StoreLabel(ctx, label)
+}
+
+func (r *deviceChatStorage) ListLabels(ctx context.Context, deviceID string) ([]*domainChatStorage.Label, error) {
+	return r.base.ListLabels(ctx, deviceID)
+}
+
+func (r *deviceChatStorage) GetLabel(ctx context.Context, deviceID, id string) (*domainChatStorage.Label, error) {
+	return r.base.GetLabel(ctx, deviceID, id)
+}
+
+func (r *deviceChatStorage) DeleteLabel(ctx context.Context, deviceID, id string) (bool, error) {
+	return r.base.DeleteLabel(ctx, deviceID, id)
+}
+
+func (r *deviceChatStorage) SetChatLabels(ctx context.Context, deviceID, chatJID string, labelIDs []string) error {
+	return r.base.SetChatLabels(ctx, deviceID, chatJID, labelIDs)
+}
+
+func (r *deviceChatStorage) GetChatLabels(ctx context.Context, deviceID string, chatJIDs []string) (map[string][]*domainChatStorage.Label, error) {
+	return r.base.GetChatLabels(ctx, deviceID, chatJIDs)
+}
+
+func (r *deviceChatStorage) StoreTemplate(ctx context.Context, tmpl *domainChatStorage.Template) error {
+	return r.base.StoreTemplate(ctx, tmpl)
+}
+
+func (r *deviceChatStorage) ListTemplates(ctx context.Context, deviceID string) ([]*domainChatStorage.Template, error) {
+	return r.base.ListTemplates(ctx, deviceID)
+}
+
+func (r *deviceChatStorage) GetTemplate(ctx context.Context, deviceID, name string) (*domainChatStorage.Template, error) {
+	return r.base.GetTemplate(ctx, deviceID, name)
+}
+
+func (r *deviceChatStorage) DeleteTemplate(ctx context.Context, deviceID, name string) (bool, error) {
+	return r.base.DeleteTemplate(ctx, deviceID, name)
+}
+
+func (r *deviceChatStorage) StoreJob(ctx context.Context, job *domainChatStorage.Job) error {
+	return r.base.StoreJob(ctx, job)
+}
+
+func (r *deviceChatStorage) GetJob(ctx context.Context, deviceID, id string) (*domainChatStorage.Job, error) {
+	return r.base.GetJob(ctx, deviceID, id)
+}
+
+func (r *deviceChatStorage) ListJobs(ctx context.Context, filter *domainChatStorage.JobFilter) ([]*domainChatStorage.Job, error) {
+	return r.base.ListJobs(ctx, filter)
+}
+
+func (r *deviceChatStorage) DeleteJob(ctx context.Context, deviceID, id string) (bool, error) {
+	return r.base.DeleteJob(ctx, deviceID, id)
+}
+
+func (r *deviceChatStorage) InterruptJobs(ctx context.Context, reason string) (int64, error) {
+	return r.base.InterruptJobs(ctx, reason)
 }
 
-func (r *deviceChatStorage) SaveDeviceRecord(record *domainChatStorage.DeviceRecord) error {
-	return r.base.SaveDeviceRecord(record)
+func (r *deviceChatStorage) ExportTable(ctx context.Context, table, deviceID string, fn func(row []byte) error) error {
+	return r.base.ExportTable(ctx, table, deviceID, fn)
 }
 
-func (r *deviceChatStorage) ListDeviceRecords() ([]*domainChatStorage.DeviceRecord, error) {
-	return r.base.ListDeviceRecords()
+func (r *deviceChatStorage) ImportTable(ctx context.Context, table, deviceID string, next func() ([]byte, error)) (int64, error) {
+	return r.base.ImportTable(ctx, table, deviceID, next)
 }
 
-func (r *deviceChatStorage) GetDeviceRecord(deviceID string) (*domainChatStorage.DeviceRecord, error) {
-	return r.base.GetDeviceRecord(deviceID)
+func (r *deviceChatStorage) CountTable(ctx context.Context, table string) (int64, error) {
+	return r.base.CountTable(ctx, table)
 }
 
-func (r *deviceChatStorage) DeleteDeviceRecord(deviceID string) error {
-	return r.base.DeleteDeviceRecord(deviceID)
+func (r *deviceChatStorage) Ping(ctx context.Context) error {
+	return r.base.Ping(ctx)
 }
@@ -217,7 +217,7 @@ func PerformCompleteCleanup(ctx context.Context, logPrefix string, chatStorageRe
 	// Truncate all chatstorage data before other cleanup
 	if chatStorageRepo != nil {
 		logrus.Infof("[%s] Truncating chatstorage data...", logPrefix)
-		if err := chatStorageRepo.TruncateAllDataWithLogging(logPrefix); err != nil {
+		if err := chatStorageRepo.TruncateAllDataWithLogging(ctx, logPrefix, filepath.Join(config.PathStorages, "chatstorage_truncate_report.json")); err != nil {
 			logrus.Errorf("[%s] Failed to truncate chatstorage data: %v", logPrefix, err)
 			// Continue with cleanup even if chatstorage truncation fails
 		}
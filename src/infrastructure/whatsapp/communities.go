@@ -0,0 +1,74 @@
+package whatsapp
+
+import (
+	"context"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// CommunityChatType returns the chat type stored for a community or its
+// announcement group, or an empty string for other groups.
+func CommunityChatType(info *types.GroupInfo) string {
+	switch {
+	case info == nil:
+		return ""
+	case info.IsParent:
+		return domainChatStorage.ChatTypeCommunity
+	case info.IsDefaultSubGroup:
+		return domainChatStorage.ChatTypeCommunityAnnounce
+	}
+	return ""
+}
+
+// StoreCommunityChatTypes marks the stored chats of the communities and
+// announcement groups among groups. Chats that are not stored are skipped.
+func StoreCommunityChatTypes(ctx context.Context, repo domainChatStorage.IChatStorageRepository, deviceID string, groups []*types.GroupInfo) {
+	if repo == nil {
+		return
+	}
+	for _, group := range groups {
+		chatType := CommunityChatType(group)
+		if chatType == "" {
+			continue
+		}
+		if err := repo.UpdateChatFlags(ctx, deviceID, group.JID.String(), domainChatStorage.ChatFlags{ChatType: &chatType}); err != nil {
+			log.Warnf("Failed to store chat type of group %s: %v", group.JID, err)
+		}
+	}
+}
+
+// SyncCommunityChatTypes marks the stored community chats of the groups the
+// client participates in, e.g. chats stored before their type was known.
+func SyncCommunityChatTypes(ctx context.Context, client *whatsmeow.Client, repo domainChatStorage.IChatStorageRepository, deviceID string) error {
+	if client == nil || client.Store == nil || client.Store.ID == nil {
+		return pkgError.ErrWaCLI
+	}
+	groups, err := client.GetJoinedGroups(ctx)
+	if err != nil {
+		return err
+	}
+	StoreCommunityChatTypes(ctx, repo, deviceID, groups)
+	return nil
+}
+
+// refreshCommunityChatTypes syncs the community chat types of instance in the
+// background after connecting.
+func refreshCommunityChatTypes(instance *DeviceInstance) {
+	client := instance.GetClient()
+	repo := instance.GetChatStorage()
+	if client == nil || repo == nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(ContextWithDevice(context.Background(), instance), 2*time.Minute)
+		defer cancel()
+		if err := SyncCommunityChatTypes(ctx, client, repo, ""); err != nil {
+			log.Warnf("Failed to sync community chats for device %s: %v", instance.ID(), err)
+		}
+	}()
+}
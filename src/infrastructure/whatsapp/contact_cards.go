@@ -0,0 +1,35 @@
+package whatsapp
+
+import (
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+)
+
+// ExtractContactCards returns the contacts shared by a contact or contacts
+// array message, or nil for other messages. The display name set on the
+// message wins over the name in the vCard.
+func ExtractContactCards(msg *waE2E.Message) []domainChatStorage.ContactCard {
+	var contacts []*waE2E.ContactMessage
+	if contact := msg.GetContactMessage(); contact != nil {
+		contacts = append(contacts, contact)
+	}
+	if array := msg.GetContactsArrayMessage(); array != nil {
+		contacts = append(contacts, array.GetContacts()...)
+	}
+
+	var cards []domainChatStorage.ContactCard
+	for _, contact := range contacts {
+		parsed := utils.ParseVCard(contact.GetVcard())
+		card := domainChatStorage.ContactCard{
+			DisplayName:  contact.GetDisplayName(),
+			PhoneNumbers: parsed.PhoneNumbers,
+			VCard:        contact.GetVcard(),
+		}
+		if card.DisplayName == "" {
+			card.DisplayName = parsed.DisplayName
+		}
+		cards = append(cards, card)
+	}
+	return cards
+}
@@ -0,0 +1,60 @@
+package whatsapp
+
+import (
+	"context"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"go.mau.fi/whatsmeow"
+)
+
+// SyncContacts copies the client's contact store into chat storage for
+// deviceID and returns the number of contacts written.
+func SyncContacts(ctx context.Context, client *whatsmeow.Client, repo domainChatStorage.IChatStorageRepository, deviceID string) (int, error) {
+	if client == nil || client.Store == nil || client.Store.Contacts == nil {
+		return 0, pkgError.ErrWaCLI
+	}
+	if repo == nil {
+		return 0, nil
+	}
+
+	contacts, err := client.Store.Contacts.GetAllContacts(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	batch := make([]*domainChatStorage.Contact, 0, len(contacts))
+	for jid, info := range contacts {
+		batch = append(batch, &domainChatStorage.Contact{
+			DeviceID:     deviceID,
+			JID:          jid.ToNonAD().String(),
+			FullName:     info.FullName,
+			FirstName:    info.FirstName,
+			PushName:     info.PushName,
+			BusinessName: info.BusinessName,
+		})
+	}
+	return repo.StoreContacts(ctx, batch)
+}
+
+// refreshContacts syncs the contacts of instance in the background, e.g. after
+// connecting or once the contact app state has been synced.
+func refreshContacts(instance *DeviceInstance) {
+	client := instance.GetClient()
+	repo := instance.GetChatStorage()
+	if client == nil || repo == nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(ContextWithDevice(context.Background(), instance), 2*time.Minute)
+		defer cancel()
+		synced, err := SyncContacts(ctx, client, repo, "")
+		if err != nil {
+			log.Warnf("Failed to sync contacts for device %s: %v", instance.ID(), err)
+			return
+		}
+		log.Debugf("Synced %d contacts for device %s", synced, instance.ID())
+	}()
+}
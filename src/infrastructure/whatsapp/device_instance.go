@@ -7,6 +7,7 @@ import (
 	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
 	domainDevice "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/device"
 	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types/events"
 )
 
 // DeviceInstance bundles a WhatsApp client with device metadata and scoped storage.
@@ -21,6 +22,18 @@ type DeviceInstance struct {
 	jid             string
 	createdAt       time.Time
 	onLoggedOut     func(deviceID string) // Callback for remote logout cleanup
+	lastEventAt     time.Time
+	lastConnectAt   time.Time
+	reconnectCount  int
+	avatarID        string
+}
+
+// DeviceActivity is when a device last received an event and connected, and
+// how often it reconnected since the server started.
+type DeviceActivity struct {
+	LastEventAt    time.Time
+	LastConnectAt  time.Time
+	ReconnectCount int
 }
 
 func NewDeviceInstance(deviceID string, client *whatsmeow.Client, chatStorageRepo domainChatStorage.IChatStorageRepository) *DeviceInstance {
@@ -76,6 +89,15 @@ func (d *DeviceInstance) DisplayName() string {
 	return d.displayName
 }
 
+// setDisplayName replaces the display name and returns the previous one.
+func (d *DeviceInstance) setDisplayName(name string) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	previous := d.displayName
+	d.displayName = name
+	return previous
+}
+
 func (d *DeviceInstance) PhoneNumber() string {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
@@ -108,6 +130,64 @@ func (d *DeviceInstance) SetChatStorage(repo domainChatStorage.IChatStorageRepos
 	d.chatStorageRepo = repo
 }
 
+// recordEvent tracks the activity of the device from its WhatsApp events.
+func (d *DeviceInstance) recordEvent(evt any) {
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lastEventAt = now
+	if _, ok := evt.(*events.Connected); ok {
+		if !d.lastConnectAt.IsZero() {
+			d.reconnectCount++
+		}
+		d.lastConnectAt = now
+	}
+}
+
+// Activity returns when the device last received an event and connected.
+func (d *DeviceInstance) Activity() DeviceActivity {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return DeviceActivity{LastEventAt: d.lastEventAt, LastConnectAt: d.lastConnectAt, ReconnectCount: d.reconnectCount}
+}
+
+// AvatarID returns the ID of the profile picture of the logged in account,
+// empty when it has none or it isn't known yet.
+func (d *DeviceInstance) AvatarID() string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.avatarID
+}
+
+// SetAvatarID records the ID of the current profile picture.
+func (d *DeviceInstance) SetAvatarID(id string) {
+	d.mu.Lock()
+	d.avatarID = id
+	d.mu.Unlock()
+}
+
+// PushName returns the WhatsApp profile name of the logged in account.
+func (d *DeviceInstance) PushName() string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.client == nil || d.client.Store == nil {
+		return ""
+	}
+	return d.client.Store.PushName
+}
+
+// ResetClient detaches and returns the WhatsApp client after a logout, leaving
+// the device ready to pair again, possibly with another number.
+func (d *DeviceInstance) ResetClient() *whatsmeow.Client {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	client := d.client
+	d.client = nil
+	d.jid = ""
+	d.state = domainDevice.DeviceStateDisconnected
+	return client
+}
+
 // IsConnected returns the live connection flag if a client exists.
 func (d *DeviceInstance) IsConnected() bool {
 	d.mu.RLock()
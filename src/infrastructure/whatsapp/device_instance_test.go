@@ -0,0 +1,27 @@
+package whatsapp
+
+import (
+	"testing"
+
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+func TestDeviceInstanceActivity(t *testing.T) {
+	instance := NewDeviceInstance("sales", nil, nil)
+	if activity := instance.Activity(); !activity.LastEventAt.IsZero() || !activity.LastConnectAt.IsZero() {
+		t.Fatalf("expected no activity for a new device, got %+v", activity)
+	}
+
+	instance.recordEvent(&events.Connected{})
+	first := instance.Activity()
+	if first.LastEventAt.IsZero() || first.LastConnectAt.IsZero() || first.ReconnectCount != 0 {
+		t.Fatalf("expected the first connection to be recorded without a reconnect, got %+v", first)
+	}
+
+	instance.recordEvent(&events.Message{})
+	instance.recordEvent(&events.Connected{})
+	instance.recordEvent(&events.Connected{})
+	if got := instance.Activity(); got.ReconnectCount != 2 || got.LastConnectAt.Before(first.LastConnectAt) {
+		t.Fatalf("expected two reconnects, got %+v", got)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	fiberUtils "github.com/gofiber/fiber/v2/utils"
 	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/appstate"
 	"go.mau.fi/whatsmeow/store"
 	"go.mau.fi/whatsmeow/store/sqlstore"
 	"go.mau.fi/whatsmeow/types"
@@ -52,7 +53,7 @@ func (m *DeviceManager) AddDevice(instance *DeviceInstance) {
 
 	// Persist registry entry if available
 	if m.storage != nil {
-		_ = m.storage.SaveDeviceRecord(&domainChatStorage.DeviceRecord{
+		_ = m.storage.SaveDeviceRecord(context.Background(), &domainChatStorage.DeviceRecord{
 			DeviceID:    instance.ID(),
 			DisplayName: instance.DisplayName(),
 			JID:         instance.JID(),
@@ -115,9 +116,10 @@ func (m *DeviceManager) RemoveDevice(id string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	delete(m.devices, id)
+	deviceWebhooks.remove(id)
 
 	if m.storage != nil && strings.TrimSpace(id) != "" {
-		_ = m.storage.DeleteDeviceRecord(id)
+		_ = m.storage.DeleteDeviceRecord(context.Background(), id)
 	}
 }
 
@@ -136,21 +138,23 @@ func (m *DeviceManager) PurgeDevice(ctx context.Context, deviceID string) error
 	}
 
 	// Attempt logout/disconnect if a client exists
+	storeIDs := []string{deviceID}
 	if inst, ok := m.GetDevice(deviceID); ok && inst != nil {
+		if jid := inst.JID(); jid != "" && jid != deviceID {
+			storeIDs = append(storeIDs, jid)
+		}
 		if cli := inst.GetClient(); cli != nil {
-			if err := cli.Logout(ctx); err != nil {
-				logrus.WithError(err).Warnf("[DEVICE_MANAGER] logout failed for device %s", deviceID)
-				recordErr(err)
-			}
-			cli.Disconnect()
+			recordErr(logoutClient(ctx, deviceID, cli))
 		}
 	}
 
-	// Delete chatstorage data for this device
+	// Delete chatstorage data for this device, stored under its ID or JID
 	if m.storage != nil {
-		if err := m.storage.DeleteDeviceData(deviceID); err != nil {
-			logrus.WithError(err).Warnf("[DEVICE_MANAGER] failed to delete chatstorage for device %s", deviceID)
-			recordErr(err)
+		for _, id := range storeIDs {
+			if err := m.storage.DeleteDeviceData(ctx, id); err != nil {
+				logrus.WithError(err).Warnf("[DEVICE_MANAGER] failed to delete chatstorage for device %s", id)
+				recordErr(err)
+			}
 		}
 	}
 
@@ -161,7 +165,7 @@ func (m *DeviceManager) PurgeDevice(ctx context.Context, deviceID string) error
 			recordErr(err)
 		} else {
 			for _, dev := range devices {
-				if dev != nil && dev.ID != nil && dev.ID.String() == deviceID {
+				if matchesStoreDevice(dev, storeIDs) {
 					if err := m.store.DeleteDevice(ctx, dev); err != nil {
 						logrus.WithError(err).Warnf("[DEVICE_MANAGER] failed to delete device %s from store", deviceID)
 						recordErr(err)
@@ -179,7 +183,7 @@ func (m *DeviceManager) PurgeDevice(ctx context.Context, deviceID string) error
 			recordErr(err)
 		} else {
 			for _, dev := range devices {
-				if dev != nil && dev.ID != nil && dev.ID.String() == deviceID {
+				if matchesStoreDevice(dev, storeIDs) {
 					if err := m.keys.DeleteDevice(ctx, dev); err != nil {
 						logrus.WithError(err).Warnf("[DEVICE_MANAGER] failed to delete device %s from keys store", deviceID)
 						recordErr(err)
@@ -191,12 +195,108 @@ func (m *DeviceManager) PurgeDevice(ctx context.Context, deviceID string) error
 	}
 
 	// Remove from registry last
+	deviceSettings.invalidate(deviceID)
+	autoReplyRules.invalidate(deviceID)
 	m.RemoveDevice(deviceID)
 	return firstErr
 }
 
+// LogoutDevice unlinks a device from WhatsApp but keeps it registered, with its
+// settings, webhook and stored chats, so it can be paired again.
+func (m *DeviceManager) LogoutDevice(ctx context.Context, deviceID string) error {
+	inst, ok := m.GetDevice(deviceID)
+	if !ok || inst == nil {
+		return fmt.Errorf("device %s not found", deviceID)
+	}
+
+	var err error
+	if cli := inst.GetClient(); cli != nil {
+		err = logoutClient(ctx, deviceID, cli)
+	}
+	inst.ResetClient()
+
+	if m.storage != nil {
+		if saveErr := m.storage.SaveDeviceRecord(ctx, &domainChatStorage.DeviceRecord{
+			DeviceID:    deviceID,
+			DisplayName: inst.DisplayName(),
+			CreatedAt:   inst.CreatedAt(),
+			UpdatedAt:   time.Now(),
+		}); saveErr != nil {
+			logrus.WithError(saveErr).Warnf("[DEVICE_MANAGER] failed to clear JID of device %s", deviceID)
+		}
+	}
+	return err
+}
+
+// RenameDevice changes the display name of a device. A logged in device also
+// sets it as its WhatsApp push name, so contacts see the new name.
+func (m *DeviceManager) RenameDevice(ctx context.Context, deviceID, name string) error {
+	inst, ok := m.GetDevice(deviceID)
+	if !ok || inst == nil {
+		return fmt.Errorf("device %s not found", deviceID)
+	}
+
+	if cli := inst.GetClient(); cli != nil && cli.Store != nil && cli.Store.ID != nil {
+		if err := cli.SendAppState(ctx, appstate.BuildSettingPushName(name)); err != nil {
+			return fmt.Errorf("failed to set push name of device %s: %w", deviceID, err)
+		}
+		// The app state resync stores the name as well, but the device list
+		// reads it from the store right away
+		cli.Store.PushName = name
+	}
+	previous := inst.setDisplayName(name)
+
+	if m.storage != nil {
+		if err := m.storage.SaveDeviceRecord(ctx, &domainChatStorage.DeviceRecord{
+			DeviceID:    deviceID,
+			DisplayName: name,
+			JID:         inst.JID(),
+			CreatedAt:   inst.CreatedAt(),
+			UpdatedAt:   time.Now(),
+		}); err != nil {
+			return err
+		}
+	}
+
+	logrus.WithContext(ctx).Infof("[DEVICE_MANAGER] renamed device %s to %q", deviceID, name)
+	publishDeviceRenamed(inst, previous)
+	return nil
+}
+
+// logoutClient stops the event handlers of cli, waiting for any running one
+// to finish, then unlinks it from WhatsApp and deletes its session. When
+// WhatsApp can't be told, e.g. while offline, the session is still deleted
+// locally and the phone drops the link on its own.
+func logoutClient(ctx context.Context, deviceID string, cli *whatsmeow.Client) error {
+	cli.RemoveEventHandlers()
+
+	if cli.Store == nil || cli.Store.ID == nil {
+		cli.Disconnect()
+		return nil
+	}
+	if err := cli.Logout(ctx); err != nil {
+		logrus.WithError(err).Warnf("[DEVICE_MANAGER] logout failed for device %s, deleting its session locally", deviceID)
+		cli.Disconnect()
+		if cli.Store.ID != nil {
+			if err := cli.Store.Delete(ctx); err != nil {
+				return fmt.Errorf("failed to delete session of device %s: %w", deviceID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// matchesStoreDevice reports whether a whatsmeow store device belongs to one of
+// ids, which may be full or device-less JIDs.
+func matchesStoreDevice(dev *store.Device, ids []string) bool {
+	if dev == nil || dev.ID == nil {
+		return false
+	}
+	return slices.Contains(ids, dev.ID.String()) || slices.Contains(ids, dev.ID.ToNonAD().String())
+}
+
 // CreateDevice registers a new device placeholder so routes can be scoped strictly by device_id.
-func (m *DeviceManager) CreateDevice(ctx context.Context, requestedID string) (*DeviceInstance, error) {
+func (m *DeviceManager) CreateDevice(ctx context.Context, requestedID, displayName string) (*DeviceInstance, error) {
 	if m == nil {
 		return nil, fmt.Errorf("device manager not initialized")
 	}
@@ -214,10 +314,11 @@ func (m *DeviceManager) CreateDevice(ctx context.Context, requestedID string) (*
 	}
 
 	instance := NewDeviceInstance(id, nil, newDeviceChatStorage(id, m.storage))
+	instance.displayName = displayName
 	m.devices[id] = instance
 
 	if m.storage != nil {
-		if err := m.storage.SaveDeviceRecord(&domainChatStorage.DeviceRecord{
+		if err := m.storage.SaveDeviceRecord(ctx, &domainChatStorage.DeviceRecord{
 			DeviceID:    id,
 			DisplayName: instance.DisplayName(),
 			JID:         instance.JID(),
@@ -232,6 +333,73 @@ func (m *DeviceManager) CreateDevice(ctx context.Context, requestedID string) (*
 	return instance, nil
 }
 
+// DeviceSettings returns the persisted settings of a device. Fields left nil
+// use the global configuration.
+func (m *DeviceManager) DeviceSettings(ctx context.Context, deviceID string) (domainChatStorage.DeviceSettings, error) {
+	if m == nil || m.storage == nil {
+		return domainChatStorage.DeviceSettings{}, fmt.Errorf("device storage not initialized")
+	}
+	record, err := m.storage.GetDeviceRecord(ctx, deviceID)
+	if err != nil || record == nil {
+		return domainChatStorage.DeviceSettings{}, err
+	}
+	return record.DeviceSettings, nil
+}
+
+// SaveDeviceSettings persists the settings of a device.
+func (m *DeviceManager) SaveDeviceSettings(ctx context.Context, deviceID string, settings domainChatStorage.DeviceSettings) error {
+	if m == nil || m.storage == nil {
+		return fmt.Errorf("device storage not initialized")
+	}
+	if err := m.storage.SaveDeviceSettings(ctx, deviceID, settings); err != nil {
+		return err
+	}
+	deviceSettings.invalidate(deviceID)
+	return nil
+}
+
+// DeviceWebhook returns the webhook a device delivers its events to. An empty
+// URL means the device uses the global webhooks.
+func (m *DeviceManager) DeviceWebhook(ctx context.Context, deviceID string) (domainChatStorage.DeviceWebhook, error) {
+	if m == nil || m.storage == nil {
+		return domainChatStorage.DeviceWebhook{}, fmt.Errorf("device storage not initialized")
+	}
+	record, err := m.storage.GetDeviceRecord(ctx, deviceID)
+	if err != nil || record == nil {
+		return domainChatStorage.DeviceWebhook{}, err
+	}
+	return record.DeviceWebhook, nil
+}
+
+// SaveDeviceWebhook persists the webhook of a device and applies it to the
+// next event without a restart.
+func (m *DeviceManager) SaveDeviceWebhook(ctx context.Context, deviceID string, webhook domainChatStorage.DeviceWebhook) error {
+	if m == nil || m.storage == nil {
+		return fmt.Errorf("device storage not initialized")
+	}
+	if err := m.storage.SaveDeviceWebhook(ctx, deviceID, webhook); err != nil {
+		return err
+	}
+	deviceWebhooks.set(deviceID, webhook)
+	return nil
+}
+
+// loadDeviceWebhooks registers the webhooks of registry records. Listed
+// records have their secret masked, so each one is read again in full.
+func (m *DeviceManager) loadDeviceWebhooks(ctx context.Context, records []*domainChatStorage.DeviceRecord) {
+	for _, rec := range records {
+		if rec == nil || rec.WebhookURL == "" {
+			continue
+		}
+		webhook, err := m.DeviceWebhook(ctx, rec.DeviceID)
+		if err != nil {
+			logrus.WithError(err).Warnf("[DEVICE_MANAGER] failed to load webhook of device %s", rec.DeviceID)
+			continue
+		}
+		deviceWebhooks.set(rec.DeviceID, webhook)
+	}
+}
+
 func (m *DeviceManager) ListDevices() []*DeviceInstance {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -266,12 +434,13 @@ func (m *DeviceManager) LoadExistingDevices(ctx context.Context) error {
 
 	// Load from persisted registry
 	if m.storage != nil {
-		records, err := m.storage.ListDeviceRecords()
+		records, err := m.storage.ListDeviceRecords(ctx)
 		if err != nil {
 			logrus.WithError(err).Warn("[DEVICE_MANAGER] failed to load device registry")
 		} else {
 			logrus.Infof("[DEVICE_MANAGER] discovered %d device records in registry", len(records))
-			m.loadFromRegistry(records)
+			m.loadFromRegistry(ctx, records)
+			m.loadDeviceWebhooks(ctx, records)
 		}
 	}
 
@@ -317,7 +486,7 @@ func (m *DeviceManager) LoadExistingDevices(ctx context.Context) error {
 			orphanDevice.jid = jid
 			orphanDevice.mu.Unlock()
 			if m.storage != nil {
-				_ = m.storage.SaveDeviceRecord(&domainChatStorage.DeviceRecord{
+				_ = m.storage.SaveDeviceRecord(ctx, &domainChatStorage.DeviceRecord{
 					DeviceID: orphanDevice.ID(),
 					JID:      jid,
 				})
@@ -335,7 +504,7 @@ func (m *DeviceManager) LoadExistingDevices(ctx context.Context) error {
 }
 
 // loadFromRegistry loads devices from the registry, handling deduplication.
-func (m *DeviceManager) loadFromRegistry(records []*domainChatStorage.DeviceRecord) {
+func (m *DeviceManager) loadFromRegistry(ctx context.Context, records []*domainChatStorage.DeviceRecord) {
 	// Collect JIDs from manual devices (device_id doesn't contain @)
 	manualDeviceJIDs := make(map[string]bool)
 	for _, rec := range records {
@@ -358,7 +527,7 @@ func (m *DeviceManager) loadFromRegistry(records []*domainChatStorage.DeviceReco
 		isAutoCreated := strings.Contains(rec.DeviceID, "@")
 		if isAutoCreated && manualDeviceJIDs[rec.DeviceID] {
 			logrus.Warnf("[DEVICE_MANAGER] removing auto-created device %s", rec.DeviceID)
-			_ = m.storage.DeleteDeviceRecord(rec.DeviceID)
+			_ = m.storage.DeleteDeviceRecord(ctx, rec.DeviceID)
 			continue
 		}
 
@@ -366,7 +535,7 @@ func (m *DeviceManager) loadFromRegistry(records []*domainChatStorage.DeviceReco
 		if rec.JID != "" {
 			if seenJIDs[rec.JID] {
 				logrus.Warnf("[DEVICE_MANAGER] removing duplicate JID device %s", rec.DeviceID)
-				_ = m.storage.DeleteDeviceRecord(rec.DeviceID)
+				_ = m.storage.DeleteDeviceRecord(ctx, rec.DeviceID)
 				continue
 			}
 			seenJIDs[rec.JID] = true
@@ -1,8 +1,13 @@
 package whatsapp
 
 import (
+	"context"
 	"testing"
 	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/store"
+	"go.mau.fi/whatsmeow/types"
 )
 
 func TestListDevices_SortsByCreatedAtAscending(t *testing.T) {
@@ -109,3 +114,85 @@ func TestListDevices_SameCreatedAt(t *testing.T) {
 		}
 	}
 }
+
+func TestLogoutDevice_KeepsDeviceRegistered(t *testing.T) {
+	manager := &DeviceManager{
+		devices: make(map[string]*DeviceInstance),
+	}
+	instance, err := manager.CreateDevice(context.Background(), "sales", "Sales team")
+	if err != nil {
+		t.Fatalf("CreateDevice: %v", err)
+	}
+	client := whatsmeow.NewClient(&store.Device{}, nil)
+	client.AddEventHandler(func(any) {})
+	instance.SetClient(client)
+	instance.jid = "628123@s.whatsapp.net"
+
+	if err := manager.LogoutDevice(context.Background(), "sales"); err != nil {
+		t.Fatalf("LogoutDevice: %v", err)
+	}
+
+	got, ok := manager.GetDevice("sales")
+	if !ok {
+		t.Fatal("expected the device to stay registered after logout")
+	}
+	if got.GetClient() != nil || got.JID() != "" || got.DisplayName() != "Sales team" {
+		t.Fatalf("expected a detached client, no JID and the display name kept, got client=%v jid=%q name=%q", got.GetClient(), got.JID(), got.DisplayName())
+	}
+	if err := manager.LogoutDevice(context.Background(), "missing"); err == nil {
+		t.Fatal("expected an error for an unknown device")
+	}
+}
+
+func TestRenameDevice_UpdatesDisplayName(t *testing.T) {
+	manager := &DeviceManager{
+		devices: make(map[string]*DeviceInstance),
+	}
+	if _, err := manager.CreateDevice(context.Background(), "sales", "Sales team"); err != nil {
+		t.Fatalf("CreateDevice: %v", err)
+	}
+
+	if err := manager.RenameDevice(context.Background(), "sales", "Front desk"); err != nil {
+		t.Fatalf("RenameDevice: %v", err)
+	}
+	devices := manager.ListDevices()
+	if len(devices) != 1 || devices[0].DisplayName() != "Front desk" {
+		t.Fatalf("expected the device list to show the new name, got %+v", devices)
+	}
+	if err := manager.RenameDevice(context.Background(), "missing", "Other"); err == nil {
+		t.Fatal("expected an error for an unknown device")
+	}
+}
+
+func TestPurgeDevice_RemovesDevice(t *testing.T) {
+	manager := &DeviceManager{
+		devices: make(map[string]*DeviceInstance),
+	}
+	instance, err := manager.CreateDevice(context.Background(), "sales", "")
+	if err != nil {
+		t.Fatalf("CreateDevice: %v", err)
+	}
+	instance.SetClient(whatsmeow.NewClient(&store.Device{}, nil))
+
+	if err := manager.PurgeDevice(context.Background(), "sales"); err != nil {
+		t.Fatalf("PurgeDevice: %v", err)
+	}
+	if _, ok := manager.GetDevice("sales"); ok {
+		t.Fatal("expected the device to be removed")
+	}
+}
+
+func TestMatchesStoreDevice(t *testing.T) {
+	id := types.NewADJID("628123", 0, 12)
+	dev := &store.Device{ID: &id}
+
+	if !matchesStoreDevice(dev, []string{"sales", "628123@s.whatsapp.net"}) {
+		t.Fatal("expected a match on the device-less JID")
+	}
+	if !matchesStoreDevice(dev, []string{id.String()}) {
+		t.Fatal("expected a match on the full JID")
+	}
+	if matchesStoreDevice(dev, []string{"sales"}) || matchesStoreDevice(&store.Device{}, []string{"sales"}) {
+		t.Fatal("expected no match")
+	}
+}
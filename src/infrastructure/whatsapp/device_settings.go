@@ -0,0 +1,67 @@
+package whatsapp
+
+import (
+	"context"
+	"sync"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"github.com/sirupsen/logrus"
+)
+
+// deviceSettings caches the settings of each device so event handlers don't
+// read the devices table for every message or call. DeviceManager drops an
+// entry whenever the settings of its device change.
+var deviceSettings = newDeviceSettingsCache()
+
+type deviceSettingsCache struct {
+	mu      sync.RWMutex
+	entries map[string]domainChatStorage.DeviceSettings
+	// generation changes on every invalidation, so a lookup that raced an
+	// update doesn't cache the settings it read before the update
+	generation uint64
+}
+
+func newDeviceSettingsCache() *deviceSettingsCache {
+	return &deviceSettingsCache{entries: make(map[string]domainChatStorage.DeviceSettings)}
+}
+
+// get returns the settings of deviceID, loading them on first use. Lookup
+// failures are not cached, so the global configuration only applies until
+// storage recovers.
+func (c *deviceSettingsCache) get(ctx context.Context, chatStorageRepo domainChatStorage.IChatStorageRepository, deviceID string) domainChatStorage.DeviceSettings {
+	if chatStorageRepo == nil || deviceID == "" {
+		return domainChatStorage.DeviceSettings{}
+	}
+
+	c.mu.RLock()
+	settings, ok := c.entries[deviceID]
+	generation := c.generation
+	c.mu.RUnlock()
+	if ok {
+		return settings
+	}
+
+	record, err := chatStorageRepo.GetDeviceRecord(ctx, deviceID)
+	if err != nil {
+		logrus.Warnf("Failed to load settings of device %s, using global settings: %v", deviceID, err)
+		return domainChatStorage.DeviceSettings{}
+	}
+	if record != nil {
+		settings = record.DeviceSettings
+	}
+
+	c.mu.Lock()
+	if c.generation == generation {
+		c.entries[deviceID] = settings
+	}
+	c.mu.Unlock()
+	return settings
+}
+
+// invalidate makes the next lookup of deviceID read its settings again.
+func (c *deviceSettingsCache) invalidate(deviceID string) {
+	c.mu.Lock()
+	delete(c.entries, deviceID)
+	c.generation++
+	c.mu.Unlock()
+}
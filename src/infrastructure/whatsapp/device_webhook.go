@@ -0,0 +1,118 @@
+package whatsapp
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/websocket"
+)
+
+// deviceWebhooks holds the webhooks of devices that override the global
+// configuration, keyed by device ID. The dispatcher reads it for every event,
+// so saving a device webhook takes effect immediately.
+var deviceWebhooks = newDeviceWebhookRegistry()
+
+// deviceWebhook is the webhook a single device delivers its events to.
+type deviceWebhook struct {
+	WebhookTarget
+	Secret string
+}
+
+// newDeviceWebhook converts a stored device webhook, reporting false when the
+// device has none.
+func newDeviceWebhook(stored domainChatStorage.DeviceWebhook) (deviceWebhook, bool) {
+	if stored.WebhookURL == "" {
+		return deviceWebhook{}, false
+	}
+	webhook := deviceWebhook{WebhookTarget: WebhookTarget{URL: stored.WebhookURL}, Secret: stored.WebhookSecret}
+	for _, event := range strings.Split(stored.WebhookEvents, ",") {
+		if event = strings.TrimSpace(event); event != "" {
+			webhook.Events = append(webhook.Events, event)
+		}
+	}
+	return webhook, true
+}
+
+// secret returns the key deliveries are signed with.
+func (w deviceWebhook) secret() string {
+	if w.Secret == "" {
+		return config.WhatsappWebhookSecret
+	}
+	return w.Secret
+}
+
+type deviceWebhookRegistry struct {
+	mu       sync.RWMutex
+	byDevice map[string]deviceWebhook
+}
+
+func newDeviceWebhookRegistry() *deviceWebhookRegistry {
+	return &deviceWebhookRegistry{byDevice: make(map[string]deviceWebhook)}
+}
+
+// set replaces the webhook of a device; a stored webhook without URL removes it.
+func (r *deviceWebhookRegistry) set(deviceID string, stored domainChatStorage.DeviceWebhook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if webhook, ok := newDeviceWebhook(stored); ok {
+		r.byDevice[deviceID] = webhook
+	} else {
+		delete(r.byDevice, deviceID)
+	}
+}
+
+func (r *deviceWebhookRegistry) remove(deviceID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.byDevice, deviceID)
+}
+
+func (r *deviceWebhookRegistry) get(deviceID string) (deviceWebhook, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	webhook, ok := r.byDevice[deviceID]
+	return webhook, ok
+}
+
+func (r *deviceWebhookRegistry) empty() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.byDevice) == 0
+}
+
+// eventDeliveryEnabled reports whether any event can be delivered: to the
+// global webhooks, to a device's own webhook or to an event stream subscriber.
+func eventDeliveryEnabled() bool {
+	return len(config.WhatsappWebhook) > 0 || !deviceWebhooks.empty() || websocket.Events.HasSubscribers()
+}
+
+// deviceWebhookForPayload finds the webhook of the device that produced a
+// payload. Payloads carry the device JID, which is also the ID of devices
+// registered from the store.
+func deviceWebhookForPayload(payload map[string]any) (deviceWebhook, bool) {
+	deviceJID, _ := payload["device_id"].(string)
+	if deviceJID == "" || deviceWebhooks.empty() {
+		return deviceWebhook{}, false
+	}
+	if webhook, ok := deviceWebhooks.get(deviceJID); ok {
+		return webhook, true
+	}
+	if deviceID := deviceIDForJID(deviceJID); deviceID != "" {
+		return deviceWebhooks.get(deviceID)
+	}
+	return deviceWebhook{}, false
+}
+
+// deviceIDForJID returns the ID of the registered device logged in as jid.
+func deviceIDForJID(jid string) string {
+	if dm := GetDeviceManager(); dm != nil {
+		for _, inst := range dm.ListDevices() {
+			if inst.JID() == jid {
+				return inst.ID()
+			}
+		}
+	}
+	return ""
+}
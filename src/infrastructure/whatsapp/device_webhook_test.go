@@ -0,0 +1,96 @@
+package whatsapp
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+)
+
+func TestForwardPayloadToConfiguredWebhooks_DeviceWebhook(t *testing.T) {
+	originalWebhooks, originalFilters, originalSecret := config.WhatsappWebhook, config.WhatsappWebhookTargetEvents, config.WhatsappWebhookSecret
+	t.Cleanup(func() {
+		config.WhatsappWebhook, config.WhatsappWebhookTargetEvents, config.WhatsappWebhookSecret = originalWebhooks, originalFilters, originalSecret
+	})
+	ConfigureWebhookTargets([]WebhookTarget{{URL: "https://global"}})
+	config.WhatsappWebhookSecret = "global-secret"
+
+	originalRegistry := deviceWebhooks
+	deviceWebhooks = newDeviceWebhookRegistry()
+	t.Cleanup(func() { deviceWebhooks = originalRegistry })
+	deviceWebhooks.set("628111@s.whatsapp.net", domainChatStorage.DeviceWebhook{
+		WebhookURL:    "https://customer-a",
+		WebhookSecret: "customer-a-secret",
+		WebhookEvents: "message, message.ack",
+	})
+	deviceWebhooks.set("628222@s.whatsapp.net", domainChatStorage.DeviceWebhook{WebhookURL: "https://customer-b"})
+
+	originalSubmit := submitWebhookFn
+	t.Cleanup(func() { submitWebhookFn = originalSubmit })
+	var attempts []string
+	submitWebhookFn = func(_ context.Context, _ map[string]any, url, secret string) error {
+		attempts = append(attempts, url+" "+secret)
+		return nil
+	}
+
+	tests := []struct {
+		name     string
+		deviceID string
+		event    string
+		want     []string
+	}{
+		{name: "device webhook with its own secret", deviceID: "628111@s.whatsapp.net", event: "message", want: []string{"https://customer-a customer-a-secret"}},
+		{name: "device webhook filters its events", deviceID: "628111@s.whatsapp.net", event: "call.offer"},
+		{name: "device webhook without secret", deviceID: "628222@s.whatsapp.net", event: "call.offer", want: []string{"https://customer-b global-secret"}},
+		{name: "device without webhook", deviceID: "628333@s.whatsapp.net", event: "message", want: []string{"https://global global-secret"}},
+		{name: "payload without device", event: "message", want: []string{"https://global global-secret"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts = nil
+			payload := map[string]any{}
+			if tt.deviceID != "" {
+				payload["device_id"] = tt.deviceID
+			}
+			if err := forwardPayloadToConfiguredWebhooks(context.Background(), payload, tt.event); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(attempts, tt.want) {
+				t.Fatalf("delivered to %v, want %v", attempts, tt.want)
+			}
+		})
+	}
+
+	// Clearing a device webhook takes effect on the next event
+	deviceWebhooks.set("628111@s.whatsapp.net", domainChatStorage.DeviceWebhook{})
+	attempts = nil
+	if err := forwardPayloadToConfiguredWebhooks(context.Background(), map[string]any{"device_id": "628111@s.whatsapp.net"}, "message"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if want := []string{"https://global global-secret"}; !reflect.DeepEqual(attempts, want) {
+		t.Fatalf("delivered to %v, want %v", attempts, want)
+	}
+}
+
+func TestEventDeliveryEnabled(t *testing.T) {
+	originalWebhooks := config.WhatsappWebhook
+	t.Cleanup(func() { config.WhatsappWebhook = originalWebhooks })
+	originalRegistry := deviceWebhooks
+	deviceWebhooks = newDeviceWebhookRegistry()
+	t.Cleanup(func() { deviceWebhooks = originalRegistry })
+
+	config.WhatsappWebhook = nil
+	if eventDeliveryEnabled() {
+		t.Fatal("expected webhooks to be disabled without any webhook")
+	}
+	deviceWebhooks.set("dev-1", domainChatStorage.DeviceWebhook{WebhookURL: "https://customer-a"})
+	if !eventDeliveryEnabled() {
+		t.Fatal("expected a device webhook to enable webhooks")
+	}
+	deviceWebhooks.remove("dev-1")
+	if eventDeliveryEnabled() {
+		t.Fatal("expected removing the device webhook to disable webhooks")
+	}
+}
@@ -5,41 +5,183 @@ import (
 	"time"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
 	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow"
+	waBinary "go.mau.fi/whatsmeow/binary"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
 )
 
-// handleCallOffer handles incoming call events and optionally auto-rejects them
-func handleCallOffer(ctx context.Context, evt *events.CallOffer, deviceID string, client *whatsmeow.Client) {
+// handleCallOffer records incoming calls and optionally auto-rejects them
+func handleCallOffer(ctx context.Context, evt *events.CallOffer, chatStorageRepo domainChatStorage.IChatStorageRepository, deviceID string, client *whatsmeow.Client, policy autoRejectPolicy) {
 	logrus.Infof("Incoming call from %s (CallID: %s)", evt.CallCreator.String(), evt.CallID)
 
-	// Auto-reject call if configured
+	from := NormalizeJIDFromLID(ctx, evt.CallCreator, client).ToNonAD()
+	isVideo := callOfferIsVideo(evt.Data)
+	recordCall(ctx, chatStorageRepo, evt.CallID, from, evt.Timestamp, isVideo)
+
 	autoRejected := false
-	if config.WhatsappAutoRejectCall {
-		rejectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-		defer cancel()
-
-		if err := client.RejectCall(rejectCtx, evt.CallCreator, evt.CallID); err != nil {
-			logrus.Errorf("Failed to reject call from %s: %v", evt.CallCreator.String(), err)
-		} else {
-			autoRejected = true
-			logrus.Infof("Auto-rejected call from %s (CallID: %s)", evt.CallCreator.String(), evt.CallID)
-		}
+	if policy.Enabled {
+		autoRejected = autoRejectCall(ctx, client, chatStorageRepo, evt.CallID, callRejectTarget(evt.CallCreator, evt.From), from, policy.Message)
 	}
 
 	// Forward call event to webhook if configured
-	if len(config.WhatsappWebhook) > 0 {
+	if eventDeliveryEnabled() {
 		go func(e *events.CallOffer, c *whatsmeow.Client, rejected bool) {
 			webhookCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancel()
 			if err := forwardCallOfferToWebhook(webhookCtx, e, deviceID, c, rejected); err != nil {
 				logrus.Errorf("Failed to forward call event to webhook: %v", err)
 			}
+			received := createCallReceivedPayload(e.CallID, from, e.Timestamp, isVideo, deviceID)
+			if err := forwardPayloadToConfiguredWebhooks(webhookCtx, received, "call.received"); err != nil {
+				logrus.Errorf("Failed to forward call.received event to webhook: %v", err)
+			}
 		}(evt, client, autoRejected)
 	}
 }
 
+// handleCallOfferNotice records group call offers, which arrive as notices
+// instead of offers.
+func handleCallOfferNotice(ctx context.Context, evt *events.CallOfferNotice, chatStorageRepo domainChatStorage.IChatStorageRepository, client *whatsmeow.Client, policy autoRejectPolicy) {
+	logrus.Infof("Incoming %s call notice from %s (CallID: %s)", evt.Media, evt.CallCreator.String(), evt.CallID)
+	from := NormalizeJIDFromLID(ctx, evt.CallCreator, client).ToNonAD()
+	recordCall(ctx, chatStorageRepo, evt.CallID, from, evt.Timestamp, evt.Media == "video")
+	if policy.Enabled {
+		autoRejectCall(ctx, client, chatStorageRepo, evt.CallID, callRejectTarget(evt.CallCreator, evt.From), from, policy.Message)
+	}
+}
+
+func handleCallAccept(ctx context.Context, evt *events.CallAccept, chatStorageRepo domainChatStorage.IChatStorageRepository) {
+	updateCallOutcome(ctx, chatStorageRepo, evt.CallID, domainChatStorage.CallOutcomeAccepted, evt.Timestamp)
+}
+
+func handleCallReject(ctx context.Context, evt *events.CallReject, chatStorageRepo domainChatStorage.IChatStorageRepository) {
+	updateCallOutcome(ctx, chatStorageRepo, evt.CallID, domainChatStorage.CallOutcomeRejected, evt.Timestamp)
+}
+
+// handleCallTerminate closes the row of the call: accepted calls get their
+// duration and calls nobody answered become missed.
+func handleCallTerminate(ctx context.Context, evt *events.CallTerminate, chatStorageRepo domainChatStorage.IChatStorageRepository) {
+	logrus.Infof("Call %s terminated (reason: %s)", evt.CallID, evt.Reason)
+	if chatStorageRepo == nil {
+		return
+	}
+	if err := chatStorageRepo.EndCall(ctx, "", evt.CallID, evt.Timestamp); err != nil {
+		logrus.Warnf("Failed to record end of call %s: %v", evt.CallID, err)
+	}
+}
+
+// autoRejectPolicy is the effective auto-reject configuration of a device.
+type autoRejectPolicy struct {
+	Enabled bool
+	Message string
+}
+
+// resolveAutoRejectPolicy applies the settings of a device over the global
+// auto-reject flags.
+func resolveAutoRejectPolicy(settings domainChatStorage.DeviceSettings) autoRejectPolicy {
+	policy := autoRejectPolicy{
+		Enabled: config.WhatsappAutoRejectCall,
+		Message: config.WhatsappAutoRejectCallMessage,
+	}
+	if settings.AutoRejectCalls != nil {
+		policy.Enabled = *settings.AutoRejectCalls
+	}
+	if settings.AutoRejectCallMessage != nil {
+		policy.Message = *settings.AutoRejectCallMessage
+	}
+	return policy
+}
+
+// deviceAutoRejectPolicy looks up the auto-reject policy of a registered device.
+func deviceAutoRejectPolicy(ctx context.Context, chatStorageRepo domainChatStorage.IChatStorageRepository, deviceID string) autoRejectPolicy {
+	return resolveAutoRejectPolicy(deviceSettings.get(ctx, chatStorageRepo, deviceID))
+}
+
+// callRejectTarget returns the JID a call is rejected with. RejectCall sends
+// it as the call creator, so group calls must be rejected with the member who
+// started them rather than the sender of the offer.
+func callRejectTarget(creator, from types.JID) types.JID {
+	if !creator.IsEmpty() {
+		return creator
+	}
+	return from
+}
+
+// autoRejectCall rejects a call, records it as auto-rejected and sends message
+// to the caller when one is set. It reports whether the call was rejected.
+func autoRejectCall(ctx context.Context, client *whatsmeow.Client, chatStorageRepo domainChatStorage.IChatStorageRepository, callID string, creator, caller types.JID, message string) bool {
+	if client == nil {
+		return false
+	}
+	rejectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := client.RejectCall(rejectCtx, creator, callID); err != nil {
+		logrus.Errorf("Failed to reject call from %s: %v", creator.String(), err)
+		return false
+	}
+	logrus.Infof("Auto-rejected call from %s (CallID: %s)", creator.String(), callID)
+	updateCallOutcome(ctx, chatStorageRepo, callID, domainChatStorage.CallOutcomeAutoRejected, time.Now())
+
+	if message == "" {
+		return true
+	}
+	response, err := client.SendMessage(rejectCtx, caller, &waE2E.Message{Conversation: proto.String(message)})
+	if err != nil {
+		logrus.Errorf("Failed to send auto-reject message to %s: %v", caller.String(), err)
+		return true
+	}
+	if chatStorageRepo != nil {
+		senderJID := ""
+		if client.Store.ID != nil {
+			senderJID = client.Store.ID.String()
+		}
+		if err := chatStorageRepo.StoreSentMessageWithContext(ctx, response.ID, senderJID, caller.String(), message, response.Timestamp, nil); err != nil {
+			logrus.Errorf("Failed to store auto-reject message in chat storage: %v", err)
+		}
+	}
+	return true
+}
+
+func recordCall(ctx context.Context, chatStorageRepo domainChatStorage.IChatStorageRepository, callID string, from types.JID, timestamp time.Time, isVideo bool) {
+	if chatStorageRepo == nil {
+		return
+	}
+	call := &domainChatStorage.Call{
+		CallID:    callID,
+		FromJID:   from.String(),
+		Timestamp: timestamp,
+		IsVideo:   isVideo,
+		Outcome:   domainChatStorage.CallOutcomeRinging,
+	}
+	if err := chatStorageRepo.StoreCall(ctx, call); err != nil {
+		logrus.Warnf("Failed to store call %s: %v", callID, err)
+	}
+}
+
+func updateCallOutcome(ctx context.Context, chatStorageRepo domainChatStorage.IChatStorageRepository, callID, outcome string, at time.Time) {
+	if chatStorageRepo == nil {
+		return
+	}
+	if err := chatStorageRepo.UpdateCallOutcome(ctx, "", callID, outcome, at); err != nil {
+		logrus.Warnf("Failed to mark call %s as %s: %v", callID, outcome, err)
+	}
+}
+
+// callOfferIsVideo reports whether a call offer includes a video stream.
+func callOfferIsVideo(data *waBinary.Node) bool {
+	if data == nil {
+		return false
+	}
+	_, ok := data.GetOptionalChildByTag("video")
+	return ok
+}
+
 // createCallOfferPayload creates a webhook payload for incoming call events
 func createCallOfferPayload(ctx context.Context, evt *events.CallOffer, deviceID string, client *whatsmeow.Client, autoRejected bool) map[string]any {
 	body := make(map[string]any)
@@ -74,6 +216,24 @@ func createCallOfferPayload(ctx context.Context, evt *events.CallOffer, deviceID
 	return body
 }
 
+// createCallReceivedPayload creates the call.received webhook payload, which
+// names the caller by phone number JID when their LID can be resolved.
+func createCallReceivedPayload(callID string, from types.JID, timestamp time.Time, isVideo bool, deviceID string) map[string]any {
+	body := map[string]any{
+		"event":     "call.received",
+		"timestamp": timestamp.Format(time.RFC3339),
+		"payload": map[string]any{
+			"call_id":  callID,
+			"from":     from.String(),
+			"is_video": isVideo,
+		},
+	}
+	if deviceID != "" {
+		body["device_id"] = deviceID
+	}
+	return body
+}
+
 // forwardCallOfferToWebhook forwards incoming call events to the configured webhook URLs
 func forwardCallOfferToWebhook(ctx context.Context, evt *events.CallOffer, deviceID string, client *whatsmeow.Client, autoRejected bool) error {
 	payload := createCallOfferPayload(ctx, evt, deviceID, client, autoRejected)
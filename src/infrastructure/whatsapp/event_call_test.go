@@ -0,0 +1,78 @@
+package whatsapp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	waBinary "go.mau.fi/whatsmeow/binary"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestCallOfferIsVideo(t *testing.T) {
+	audio := &waBinary.Node{Tag: "offer", Content: []waBinary.Node{{Tag: "audio"}, {Tag: "net"}}}
+	video := &waBinary.Node{Tag: "offer", Content: []waBinary.Node{{Tag: "audio"}, {Tag: "video"}}}
+
+	if callOfferIsVideo(audio) {
+		t.Error("expected a voice call offer")
+	}
+	if !callOfferIsVideo(video) {
+		t.Error("expected a video call offer")
+	}
+	if callOfferIsVideo(nil) {
+		t.Error("expected no video without offer data")
+	}
+}
+
+func TestCreateCallReceivedPayload(t *testing.T) {
+	from := types.NewJID("628123", types.DefaultUserServer)
+	body := createCallReceivedPayload("CALL1", from, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), true, "dev-1")
+
+	if body["event"] != "call.received" || body["device_id"] != "dev-1" || body["timestamp"] != "2024-05-01T10:00:00Z" {
+		t.Fatalf("unexpected envelope: %v", body)
+	}
+	payload, ok := body["payload"].(map[string]any)
+	if !ok {
+		t.Fatalf("payload has type %T", body["payload"])
+	}
+	if payload["call_id"] != "CALL1" || payload["from"] != "628123@s.whatsapp.net" || payload["is_video"] != true {
+		t.Errorf("unexpected payload: %v", payload)
+	}
+}
+
+func TestResolveAutoRejectPolicy(t *testing.T) {
+	originalEnabled, originalMessage := config.WhatsappAutoRejectCall, config.WhatsappAutoRejectCallMessage
+	t.Cleanup(func() {
+		config.WhatsappAutoRejectCall, config.WhatsappAutoRejectCallMessage = originalEnabled, originalMessage
+	})
+	config.WhatsappAutoRejectCall, config.WhatsappAutoRejectCallMessage = true, "I'm unavailable"
+
+	if got := resolveAutoRejectPolicy(domainChatStorage.DeviceSettings{}); !got.Enabled || got.Message != "I'm unavailable" {
+		t.Fatalf("expected the global policy without device settings, got %+v", got)
+	}
+
+	disabled, silent := false, ""
+	settings := domainChatStorage.DeviceSettings{AutoRejectCalls: &disabled}
+	if got := resolveAutoRejectPolicy(settings); got.Enabled || got.Message != "I'm unavailable" {
+		t.Fatalf("expected the device to turn auto-reject off, got %+v", got)
+	}
+
+	settings.AutoRejectCalls, settings.AutoRejectCallMessage = nil, &silent
+	if got := resolveAutoRejectPolicy(settings); !got.Enabled || got.Message != "" {
+		t.Fatalf("expected the device to clear the reply text, got %+v", got)
+	}
+}
+
+func TestCallRejectTarget(t *testing.T) {
+	creator := types.NewJID("628123", types.DefaultUserServer)
+	from := types.NewJID("628456", types.DefaultUserServer)
+
+	// Group calls are rejected with their creator, not the member relaying the offer
+	if got := callRejectTarget(creator, from); got != creator {
+		t.Errorf("callRejectTarget() = %s, want %s", got, creator)
+	}
+	if got := callRejectTarget(types.EmptyJID, from); got != from {
+		t.Errorf("callRejectTarget() = %s, want %s", got, from)
+	}
+}
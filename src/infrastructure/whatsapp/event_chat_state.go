@@ -0,0 +1,51 @@
+package whatsapp
+
+import (
+	"context"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waSyncAction"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// handleArchive, handlePin and handleMute mirror the chat state changed on
+// other devices (or replayed by an app state full sync) into chat storage.
+func handleArchive(ctx context.Context, evt *events.Archive, chatStorageRepo domainChatStorage.IChatStorageRepository, client *whatsmeow.Client) {
+	archived := evt.Action.GetArchived()
+	updateChatFlags(ctx, evt.JID, domainChatStorage.ChatFlags{Archived: &archived}, chatStorageRepo, client)
+}
+
+func handlePin(ctx context.Context, evt *events.Pin, chatStorageRepo domainChatStorage.IChatStorageRepository, client *whatsmeow.Client) {
+	pinned := evt.Action.GetPinned()
+	updateChatFlags(ctx, evt.JID, domainChatStorage.ChatFlags{Pinned: &pinned}, chatStorageRepo, client)
+}
+
+func handleMute(ctx context.Context, evt *events.Mute, chatStorageRepo domainChatStorage.IChatStorageRepository, client *whatsmeow.Client) {
+	mutedUntil := muteEndTime(evt.Action)
+	updateChatFlags(ctx, evt.JID, domainChatStorage.ChatFlags{MutedUntil: &mutedUntil}, chatStorageRepo, client)
+}
+
+// muteEndTime converts a mute action to the stored MutedUntil value: the zero
+// time when unmuted and MutedForever when muted without an end timestamp.
+func muteEndTime(action *waSyncAction.MuteAction) time.Time {
+	if !action.GetMuted() {
+		return time.Time{}
+	}
+	if end := action.GetMuteEndTimestamp(); end > 0 {
+		return time.UnixMilli(end).UTC()
+	}
+	return domainChatStorage.MutedForever
+}
+
+func updateChatFlags(ctx context.Context, jid types.JID, flags domainChatStorage.ChatFlags, chatStorageRepo domainChatStorage.IChatStorageRepository, client *whatsmeow.Client) {
+	if chatStorageRepo == nil {
+		return
+	}
+	chatJID := NormalizeJIDFromLID(ctx, jid, client).String()
+	if err := chatStorageRepo.UpdateChatFlags(ctx, "", chatJID, flags); err != nil {
+		log.Errorf("Failed to update chat state for %s: %v", chatJID, err)
+	}
+}
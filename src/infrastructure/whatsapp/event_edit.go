@@ -0,0 +1,58 @@
+package whatsapp
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// ForwardSentEditToWebhook sends the message.edited event of an edit made
+// through the API. WhatsApp doesn't echo our own edits back as events, so
+// this is the only way webhooks learn about them. It returns immediately.
+func ForwardSentEditToWebhook(client *whatsmeow.Client, chat types.JID, editID, originalID, body string, timestamp time.Time) {
+	if !eventDeliveryEnabled() {
+		return
+	}
+	go func() {
+		webhookCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		payload := createSentEditPayload(webhookCtx, client, chat, editID, originalID, body, timestamp)
+		if err := forwardPayloadToConfiguredWebhooks(webhookCtx, payload, EventTypeMessageEdited); err != nil {
+			logrus.Errorf("Failed to forward edit of message %s to webhook: %v", originalID, err)
+		}
+	}()
+}
+
+// createSentEditPayload builds the same payload as an incoming MESSAGE_EDIT,
+// sent by this device.
+func createSentEditPayload(ctx context.Context, client *whatsmeow.Client, chat types.JID, editID, originalID, body string, timestamp time.Time) map[string]any {
+	payload := map[string]any{
+		"id":                  editID,
+		"timestamp":           timestamp.Format(time.RFC3339),
+		"is_from_me":          true,
+		"original_message_id": originalID,
+		"body":                body,
+	}
+
+	chatJID := chat.ToNonAD()
+	if chatJID.Server == types.HiddenUserServer {
+		payload["chat_lid"] = chatJID.String()
+		chatJID = NormalizeJIDFromLID(ctx, chatJID, client).ToNonAD()
+	}
+	payload["chat_id"] = chatJID.String()
+
+	var deviceID string
+	if client != nil && client.Store != nil && client.Store.ID != nil {
+		deviceID = NormalizeJIDFromLID(ctx, client.Store.ID.ToNonAD(), client).ToNonAD().String()
+		payload["from"] = deviceID
+	}
+
+	return map[string]any{
+		"event":     EventTypeMessageEdited,
+		"device_id": deviceID,
+		"payload":   payload,
+	}
+}
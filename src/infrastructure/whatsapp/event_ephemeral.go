@@ -0,0 +1,82 @@
+package whatsapp
+
+import (
+	"context"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// handleEphemeralSetting handles the protocol message sent when someone
+// changes the disappearing message timer of a chat.
+func handleEphemeralSetting(ctx context.Context, evt *events.Message, chatStorageRepo domainChatStorage.IChatStorageRepository, deviceID string, client *whatsmeow.Client) {
+	protocol := evt.Message.GetProtocolMessage()
+	if protocol.GetType() != waE2E.ProtocolMessage_EPHEMERAL_SETTING {
+		return
+	}
+	sender := evt.Info.Sender
+	handleEphemeralChange(ctx, chatStorageRepo, deviceID, client, evt.Info.Chat, &sender, protocol.GetEphemeralExpiration(), evt.Info.Timestamp)
+}
+
+// handleEphemeralChange stores the new disappearing message timer of a chat
+// and forwards a chat.ephemeral_changed event when it differs from the stored
+// one. Groups report changes both as group info and as a protocol message, so
+// the second notification is not forwarded again.
+func handleEphemeralChange(ctx context.Context, chatStorageRepo domainChatStorage.IChatStorageRepository, deviceID string, client *whatsmeow.Client, chat types.JID, sender *types.JID, expiration uint32, timestamp time.Time) {
+	chatJID := NormalizeJIDFromLID(ctx, chat, client).ToNonAD().String()
+	var previous uint32
+	if chatStorageRepo != nil {
+		var err error
+		if previous, err = chatStorageRepo.SetEphemeralExpiration(ctx, "", chatJID, expiration); err != nil {
+			log.Warnf("Failed to store disappearing message timer of %s: %v", chatJID, err)
+			return
+		}
+		if previous == expiration {
+			return
+		}
+	}
+	log.Infof("Disappearing message timer of %s changed from %ds to %ds", chatJID, previous, expiration)
+
+	from := ""
+	if sender != nil && !sender.IsEmpty() {
+		from = NormalizeJIDFromLID(ctx, *sender, client).ToNonAD().String()
+	}
+	if eventDeliveryEnabled() {
+		payload := createEphemeralChangedPayload(chatJID, from, previous, expiration, timestamp, deviceID)
+		go func() {
+			webhookCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := forwardPayloadToConfiguredWebhooks(webhookCtx, payload, "chat.ephemeral_changed"); err != nil {
+				logrus.Errorf("Failed to forward disappearing message timer change to webhook: %v", err)
+			}
+		}()
+	}
+}
+
+// createEphemeralChangedPayload creates a webhook payload for a changed
+// disappearing message timer. Durations are in seconds, zero meaning off.
+func createEphemeralChangedPayload(chatJID, from string, previous, expiration uint32, timestamp time.Time, deviceID string) map[string]any {
+	payload := map[string]any{
+		"chat_id":      chatJID,
+		"old_duration": previous,
+		"new_duration": expiration,
+	}
+	if from != "" {
+		payload["from"] = from
+	}
+
+	body := map[string]any{
+		"event":     "chat.ephemeral_changed",
+		"timestamp": timestamp.Format(time.RFC3339),
+		"payload":   payload,
+	}
+	if deviceID != "" {
+		body["device_id"] = deviceID
+	}
+	return body
+}
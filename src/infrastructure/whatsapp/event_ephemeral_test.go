@@ -0,0 +1,39 @@
+package whatsapp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCreateEphemeralChangedPayload(t *testing.T) {
+	changedAt := time.Date(2026, time.February, 8, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		previous   uint32
+		expiration uint32
+	}{
+		{name: "24 hours", previous: 0, expiration: 86400},
+		{name: "7 days", previous: 86400, expiration: 604800},
+		{name: "90 days", previous: 604800, expiration: 7776000},
+		{name: "off", previous: 7776000, expiration: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := createEphemeralChangedPayload("120363024512399999@g.us", "628123@s.whatsapp.net", tt.previous, tt.expiration, changedAt, "dev-1")
+			if body["event"] != "chat.ephemeral_changed" {
+				t.Fatalf("expected event chat.ephemeral_changed, got %v", body["event"])
+			}
+			if body["device_id"] != "dev-1" || body["timestamp"] != "2026-02-08T10:00:00Z" {
+				t.Fatalf("unexpected metadata: %v", body)
+			}
+			payload := body["payload"].(map[string]any)
+			if payload["old_duration"] != tt.previous || payload["new_duration"] != tt.expiration {
+				t.Fatalf("expected %d -> %d, got %v -> %v", tt.previous, tt.expiration, payload["old_duration"], payload["new_duration"])
+			}
+			if payload["chat_id"] != "120363024512399999@g.us" || payload["from"] != "628123@s.whatsapp.net" {
+				t.Fatalf("unexpected chat or sender: %v", payload)
+			}
+		})
+	}
+}
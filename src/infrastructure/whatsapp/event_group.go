@@ -3,11 +3,14 @@ package whatsapp
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
-	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/webhooks"
 	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow"
+	waBinary "go.mau.fi/whatsmeow/binary"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 )
@@ -77,14 +80,247 @@ func forwardGroupInfoToWebhook(ctx context.Context, evt *events.GroupInfo, devic
 		}
 	}
 
+	if payload := createGroupUpdatedPayload(ctx, evt, deviceID, client); payload != nil {
+		if err := forwardPayloadToConfiguredWebhooks(ctx, payload, webhooks.EventGroupUpdated); err != nil {
+			logrus.Warnf("Failed to forward group update event to webhook: %v", err)
+		}
+	}
+
+	for _, payload := range createGroupJoinRequestPayloads(ctx, evt, deviceID, client) {
+		if err := forwardPayloadToConfiguredWebhooks(ctx, payload, webhooks.EventGroupJoinRequest); err != nil {
+			logrus.Warnf("Failed to forward group join request event to webhook: %v", err)
+		}
+	}
+
 	return nil
 }
 
+// createGroupUpdatedPayload creates a webhook payload for changes to the group
+// subject, description and settings. It returns nil when the event changes
+// none of them.
+func createGroupUpdatedPayload(ctx context.Context, evt *events.GroupInfo, deviceID string, client *whatsmeow.Client) map[string]any {
+	memberAddMode := groupMemberAddModeChange(evt)
+	if evt.Name == nil && evt.Topic == nil && evt.Locked == nil && evt.Announce == nil && evt.Ephemeral == nil && memberAddMode == nil {
+		return nil
+	}
+
+	payload := webhooks.GroupUpdatedPayload{ChatID: evt.JID.ToNonAD().String()}
+	if evt.SenderPN != nil && !evt.SenderPN.IsEmpty() {
+		payload.Sender = evt.SenderPN.ToNonAD().String()
+	} else if evt.Sender != nil && !evt.Sender.IsEmpty() {
+		payload.Sender = NormalizeJIDFromLID(ctx, *evt.Sender, client).ToNonAD().String()
+	}
+	if evt.Name != nil {
+		payload.Name = &evt.Name.Name
+	}
+	if evt.Topic != nil {
+		payload.Topic = &evt.Topic.Topic
+	}
+	if evt.Locked != nil {
+		payload.Locked = &evt.Locked.IsLocked
+	}
+	if evt.Announce != nil {
+		payload.Announce = &evt.Announce.IsAnnounce
+	}
+	if evt.Ephemeral != nil {
+		var timer uint32
+		if evt.Ephemeral.IsEphemeral {
+			timer = evt.Ephemeral.DisappearingTimer
+		}
+		payload.DisappearingTimer = &timer
+	}
+	payload.MemberAddMode = memberAddMode
+	return newWebhookBody(webhooks.EventGroupUpdated, deviceID, evt.Timestamp, payload)
+}
+
+// groupMemberAddModeChange returns the new member add mode of a group info
+// notification, if it changed it. whatsmeow doesn't parse this change, so it
+// is read from the unknown ones.
+func groupMemberAddModeChange(evt *events.GroupInfo) *string {
+	for _, change := range evt.UnknownChanges {
+		if change.Tag != "member_add_mode" {
+			continue
+		}
+		if mode, ok := change.Content.([]byte); ok {
+			value := string(mode)
+			return &value
+		}
+	}
+	return nil
+}
+
+// groupJoinRequestChanges returns the join requests created or withdrawn by a
+// group info notification. whatsmeow doesn't parse these either.
+func groupJoinRequestChanges(evt *events.GroupInfo) []*waBinary.Node {
+	var changes []*waBinary.Node
+	for _, change := range evt.UnknownChanges {
+		if change.Tag == "created_membership_requests" || change.Tag == "revoked_membership_requests" {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// createGroupJoinRequestPayloads creates a group.join_request webhook payload
+// for each join request change of a group info notification. A withdrawn
+// request is cancelled when the requester withdrew it and rejected when an
+// admin did.
+func createGroupJoinRequestPayloads(ctx context.Context, evt *events.GroupInfo, deviceID string, client *whatsmeow.Client) []map[string]any {
+	var sender types.JID
+	if evt.SenderPN != nil && !evt.SenderPN.IsEmpty() {
+		sender = evt.SenderPN.ToNonAD()
+	} else if evt.Sender != nil {
+		sender = NormalizeJIDFromLID(ctx, *evt.Sender, client).ToNonAD()
+	}
+
+	var bodies []map[string]any
+	for _, change := range groupJoinRequestChanges(evt) {
+		payload := webhooks.GroupJoinRequestPayload{
+			ChatID: evt.JID.ToNonAD().String(),
+			Action: "requested",
+			JIDs:   []string{},
+			Method: change.AttrGetter().OptionalString("request_method"),
+		}
+		for _, participant := range change.GetChildrenByTag("participant") {
+			attrs := participant.AttrGetter()
+			jid := attrs.OptionalJIDOrEmpty("phone_number")
+			if jid.IsEmpty() {
+				jid = NormalizeJIDFromLID(ctx, attrs.OptionalJIDOrEmpty("jid"), client)
+			}
+			if !jid.IsEmpty() {
+				payload.JIDs = append(payload.JIDs, jid.ToNonAD().String())
+			}
+		}
+		// Requests made by the sender itself don't list the requester
+		if len(payload.JIDs) == 0 && !sender.IsEmpty() {
+			payload.JIDs = append(payload.JIDs, sender.String())
+		}
+		if change.Tag == "revoked_membership_requests" {
+			payload.Action = "rejected"
+			if slices.Contains(payload.JIDs, sender.String()) {
+				payload.Action = "cancelled"
+			}
+		}
+		bodies = append(bodies, newWebhookBody(webhooks.EventGroupJoinRequest, deviceID, evt.Timestamp, payload))
+	}
+	return bodies
+}
+
+// handleGroupPicture forwards a group.updated event when the photo of a group
+// changes. Profile pictures of contacts are reported by the same event and
+// are ignored.
+func handleGroupPicture(ctx context.Context, evt *events.Picture, deviceID string, client *whatsmeow.Client) {
+	if evt.JID.Server != types.GroupServer || !eventDeliveryEnabled() {
+		return
+	}
+	forwardWebhookEventAsync(createGroupPicturePayload(ctx, evt, deviceID, client), webhooks.EventGroupUpdated)
+}
+
+// createGroupPicturePayload creates a group.updated webhook payload for a
+// changed group photo.
+func createGroupPicturePayload(ctx context.Context, evt *events.Picture, deviceID string, client *whatsmeow.Client) map[string]any {
+	payload := webhooks.GroupUpdatedPayload{ChatID: evt.JID.ToNonAD().String(), PictureID: &evt.PictureID}
+	if evt.Remove {
+		payload.PictureID = new(string)
+	}
+	if !evt.Author.IsEmpty() {
+		payload.Sender = NormalizeJIDFromLID(ctx, evt.Author, client).ToNonAD().String()
+	}
+	return newWebhookBody(webhooks.EventGroupUpdated, deviceID, evt.Timestamp, payload)
+}
+
+// storeGroupName keeps the stored chat name of a group in step with renames.
+func storeGroupName(ctx context.Context, evt *events.GroupInfo, chatStorageRepo domainChatStorage.IChatStorageRepository) {
+	if chatStorageRepo == nil || evt.Name == nil || evt.Name.Name == "" {
+		return
+	}
+	chatJID := evt.JID.ToNonAD().String()
+	chat, err := chatStorageRepo.GetChat(ctx, chatJID)
+	if err != nil || chat == nil {
+		return
+	}
+	chat.Name = evt.Name.Name
+	if err := chatStorageRepo.StoreChat(ctx, chat); err != nil {
+		log.Warnf("Failed to store new name of group %s: %v", chatJID, err)
+	}
+}
+
+// storeGroupLeft marks the chat of a group as left when the device is among
+// the members that left, whether it left from another device or was removed.
+func storeGroupLeft(ctx context.Context, evt *events.GroupInfo, chatStorageRepo domainChatStorage.IChatStorageRepository, client *whatsmeow.Client) {
+	if chatStorageRepo == nil || client == nil || client.Store == nil || client.Store.ID == nil {
+		return
+	}
+	own, ownLID := client.Store.GetJID().ToNonAD(), client.Store.GetLID().ToNonAD()
+	left := slices.ContainsFunc(evt.Leave, func(jid types.JID) bool {
+		jid = jid.ToNonAD()
+		return jid == own || (!ownLID.IsEmpty() && jid == ownLID)
+	})
+	if !left {
+		return
+	}
+	leftAt := evt.Timestamp
+	if leftAt.IsZero() {
+		leftAt = time.Now()
+	}
+	if err := chatStorageRepo.UpdateChatFlags(ctx, "", evt.JID.ToNonAD().String(), domainChatStorage.ChatFlags{LeftAt: &leftAt}); err != nil {
+		log.Warnf("Failed to mark group %s as left: %v", evt.JID, err)
+	}
+}
+
+// storeGroupParticipantChanges applies the member changes of a group info
+// notification to the participant cache.
+func storeGroupParticipantChanges(ctx context.Context, evt *events.GroupInfo, chatStorageRepo domainChatStorage.IChatStorageRepository, client *whatsmeow.Client) {
+	if chatStorageRepo == nil || len(evt.Join)+len(evt.Leave)+len(evt.Promote)+len(evt.Demote) == 0 {
+		return
+	}
+	change := domainChatStorage.GroupParticipantChange{
+		Join:      jidsToStrings(ctx, evt.Join, client),
+		Leave:     jidsToStrings(ctx, evt.Leave, client),
+		Promote:   jidsToStrings(ctx, evt.Promote, client),
+		Demote:    jidsToStrings(ctx, evt.Demote, client),
+		Timestamp: evt.Timestamp,
+	}
+	if err := chatStorageRepo.UpdateGroupParticipants(ctx, "", evt.JID.ToNonAD().String(), change); err != nil {
+		log.Warnf("Failed to update participant cache of group %s: %v", evt.JID, err)
+	}
+}
+
+// GroupParticipantsFromInfo converts the members of a group to cached
+// participants, preferring phone number JIDs over LIDs.
+func GroupParticipantsFromInfo(ctx context.Context, info *types.GroupInfo, client *whatsmeow.Client) []*domainChatStorage.GroupParticipant {
+	participants := make([]*domainChatStorage.GroupParticipant, 0, len(info.Participants))
+	for _, p := range info.Participants {
+		jid := p.JID
+		if jid.Server == types.HiddenUserServer && !p.PhoneNumber.IsEmpty() {
+			jid = p.PhoneNumber
+		}
+		participants = append(participants, &domainChatStorage.GroupParticipant{
+			ParticipantJID: NormalizeJIDFromLID(ctx, jid, client).ToNonAD().String(),
+			IsAdmin:        p.IsAdmin,
+			IsSuperAdmin:   p.IsSuperAdmin,
+		})
+	}
+	return participants
+}
+
 // handleJoinedGroup handles the event when the connected device is added to a new group
-func handleJoinedGroup(ctx context.Context, evt *events.JoinedGroup, deviceID string, client *whatsmeow.Client) {
+func handleJoinedGroup(ctx context.Context, evt *events.JoinedGroup, chatStorageRepo domainChatStorage.IChatStorageRepository, deviceID string, client *whatsmeow.Client) {
 	log.Infof("Joined group %s (reason: %s, type: %s)", evt.JID, evt.Reason, evt.Type)
 
-	if len(config.WhatsappWebhook) > 0 {
+	// The notification carries the full member list, so seed the participant cache
+	if chatStorageRepo != nil {
+		participants := GroupParticipantsFromInfo(ctx, &evt.GroupInfo, client)
+		if err := chatStorageRepo.SyncGroupParticipants(ctx, "", evt.JID.String(), participants); err != nil {
+			log.Warnf("Failed to cache participants of group %s: %v", evt.JID, err)
+		}
+		// Rejoining a group we left picks up its kept history again
+		if err := chatStorageRepo.UpdateChatFlags(ctx, "", evt.JID.ToNonAD().String(), domainChatStorage.ChatFlags{LeftAt: &time.Time{}}); err != nil {
+			log.Warnf("Failed to mark group %s as joined: %v", evt.JID, err)
+		}
+	}
+
+	if eventDeliveryEnabled() {
 		go func(e *events.JoinedGroup, c *whatsmeow.Client) {
 			webhookCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancel()
@@ -10,6 +10,7 @@ import (
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
 	domainDevice "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/device"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/webhooks"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/websocket"
 	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow"
@@ -24,6 +25,8 @@ func handler(ctx context.Context, instance *DeviceInstance, rawEvt any) {
 		return
 	}
 
+	instance.recordEvent(rawEvt)
+
 	// Ensure downstream handlers see the device context (used for device-scoped storage).
 	ctx = ContextWithDevice(ctx, instance)
 
@@ -34,29 +37,55 @@ func handler(ctx context.Context, instance *DeviceInstance, rawEvt any) {
 	case *events.DeleteForMe:
 		handleDeleteForMe(ctx, evt, chatStorageRepo, instance.JID(), client)
 	case *events.AppStateSyncComplete:
-		handleAppStateSyncComplete(ctx, client, evt)
+		handleAppStateSyncComplete(ctx, instance, evt)
 	case *events.PairSuccess:
-		handlePairSuccess(ctx, evt)
+		handlePairSuccess(ctx, instance, evt)
+	case *events.PairError:
+		handlePairError(ctx, instance, evt)
 	case *events.LoggedOut:
-		handleLoggedOut(ctx, instance, chatStorageRepo)
+		handleLoggedOut(ctx, instance, evt, chatStorageRepo)
 	case *events.Connected, *events.PushNameSetting:
 		handleConnectionEvents(ctx, client, instance)
+		if _, connected := evt.(*events.Connected); connected {
+			PairingEvents.Publish(instance.ID(), PairingEventConnected, map[string]any{"jid": instance.JID()})
+			refreshContacts(instance)
+			refreshCommunityChatTypes(instance)
+			refreshBlocklist(instance)
+			refreshAvatarID(instance)
+		}
+	case *events.Disconnected:
+		handleDisconnected(ctx, instance)
 	case *events.StreamReplaced:
 		handleStreamReplaced(ctx)
 	case *events.Message:
-		handleMessage(ctx, evt, chatStorageRepo, client)
+		handleMessage(ctx, evt, chatStorageRepo, instance.JID(), client, deviceMessageAutomation(ctx, chatStorageRepo, instance.ID()))
+	case *events.MediaRetry:
+		handleMediaRetry(ctx, evt, chatStorageRepo)
 	case *events.Receipt:
-		handleReceipt(ctx, evt, instance.JID(), client)
+		handleReceipt(ctx, evt, chatStorageRepo, instance.JID(), client)
 	case *events.Presence:
-		handlePresence(ctx, evt)
+		handlePresence(ctx, evt, instance.JID(), client)
+	case *events.ChatPresence:
+		handleChatPresence(ctx, evt, instance.JID(), client)
 	case *events.HistorySync:
 		handleHistorySync(ctx, evt, chatStorageRepo, client)
 	case *events.AppState:
 		handleAppState(ctx, evt)
+	case *events.Archive:
+		handleArchive(ctx, evt, chatStorageRepo, client)
+	case *events.Pin:
+		handlePin(ctx, evt, chatStorageRepo, client)
+	case *events.Mute:
+		handleMute(ctx, evt, chatStorageRepo, client)
+	case *events.Blocklist:
+		handleBlocklist(ctx, evt, instance)
 	case *events.GroupInfo:
-		handleGroupInfo(ctx, evt, instance.JID(), client)
+		handleGroupInfo(ctx, evt, chatStorageRepo, instance.JID(), client)
+	case *events.Picture:
+		handleOwnPicture(evt, instance)
+		handleGroupPicture(ctx, evt, instance.JID(), client)
 	case *events.JoinedGroup:
-		handleJoinedGroup(ctx, evt, instance.JID(), client)
+		handleJoinedGroup(ctx, evt, chatStorageRepo, instance.JID(), client)
 	case *events.NewsletterJoin:
 		handleNewsletterJoin(ctx, evt, instance.JID(), client)
 	case *events.NewsletterLeave:
@@ -66,7 +95,15 @@ func handler(ctx context.Context, instance *DeviceInstance, rawEvt any) {
 	case *events.NewsletterMuteChange:
 		handleNewsletterMuteChange(ctx, evt, instance.JID(), client)
 	case *events.CallOffer:
-		handleCallOffer(ctx, evt, instance.JID(), client)
+		handleCallOffer(ctx, evt, chatStorageRepo, instance.JID(), client, deviceAutoRejectPolicy(ctx, chatStorageRepo, instance.ID()))
+	case *events.CallOfferNotice:
+		handleCallOfferNotice(ctx, evt, chatStorageRepo, client, deviceAutoRejectPolicy(ctx, chatStorageRepo, instance.ID()))
+	case *events.CallAccept:
+		handleCallAccept(ctx, evt, chatStorageRepo)
+	case *events.CallReject:
+		handleCallReject(ctx, evt, chatStorageRepo)
+	case *events.CallTerminate:
+		handleCallTerminate(ctx, evt, chatStorageRepo)
 	}
 
 	instance.UpdateStateFromClient()
@@ -76,7 +113,7 @@ func handleDeleteForMe(ctx context.Context, evt *events.DeleteForMe, chatStorage
 	log.Infof("Deleted message %s for %s", evt.MessageID, evt.SenderJID.String())
 
 	// Find the message to get its chat JID
-	message, err := chatStorageRepo.GetMessageByID(evt.MessageID)
+	message, err := chatStorageRepo.GetMessageByID(ctx, evt.MessageID)
 	if err != nil {
 		log.Errorf("Failed to find message %s for deletion: %v", evt.MessageID, err)
 		return
@@ -88,14 +125,14 @@ func handleDeleteForMe(ctx context.Context, evt *events.DeleteForMe, chatStorage
 	}
 
 	// Delete the message from database
-	if err := chatStorageRepo.DeleteMessage(evt.MessageID, message.ChatJID); err != nil {
+	if err := chatStorageRepo.DeleteMessage(ctx, evt.MessageID, message.ChatJID); err != nil {
 		log.Errorf("Failed to delete message %s from database: %v", evt.MessageID, err)
 	} else {
 		log.Infof("Successfully deleted message %s from database", evt.MessageID)
 	}
 
 	// Send webhook notification for delete event
-	if len(config.WhatsappWebhook) > 0 {
+	if eventDeliveryEnabled() {
 		go func(c *whatsmeow.Client) {
 			webhookCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancel()
@@ -130,25 +167,71 @@ func sendConfiguredPresence(ctx context.Context, client *whatsmeow.Client) {
 	}
 }
 
-func handleAppStateSyncComplete(_ context.Context, client *whatsmeow.Client, evt *events.AppStateSyncComplete) {
+func handleAppStateSyncComplete(_ context.Context, instance *DeviceInstance, evt *events.AppStateSyncComplete) {
+	client := instance.GetClient()
 	if client == nil {
 		return
 	}
+	// Contact names arrive in the critical_unblock_low patch
+	if evt.Name == appstate.WAPatchCriticalUnblockLow {
+		refreshContacts(instance)
+	}
 	if len(client.Store.PushName) > 0 && evt.Name == appstate.WAPatchCriticalBlock {
 		sendConfiguredPresence(context.Background(), client)
 	}
 }
 
-func handlePairSuccess(ctx context.Context, evt *events.PairSuccess) {
+func handlePairSuccess(ctx context.Context, instance *DeviceInstance, evt *events.PairSuccess) {
 	websocket.Broadcast <- websocket.BroadcastMessage{
 		Code:    "LOGIN_SUCCESS",
 		Message: fmt.Sprintf("Successfully pair with %s", evt.ID.String()),
 	}
+
+	payload := createDevicePairingPayload(evt.ID, evt.LID, evt.BusinessName, evt.Platform)
+	publishDevicePairing(instance, webhooks.EventDevicePaired, PairingEventPaired, payload)
+
 	primaryDB, secondaryDB := getStoreContainers()
 	syncKeysDevice(ctx, primaryDB, secondaryDB)
 }
 
-func handleLoggedOut(ctx context.Context, instance *DeviceInstance, chatStorageRepo domainChatStorage.IChatStorageRepository) {
+func handlePairError(_ context.Context, instance *DeviceInstance, evt *events.PairError) {
+	logrus.Errorf("Pairing device %s with %s failed: %v", instance.ID(), evt.ID.String(), evt.Error)
+
+	payload := createDevicePairingPayload(evt.ID, evt.LID, evt.BusinessName, evt.Platform)
+	if evt.Error != nil {
+		payload.Error = evt.Error.Error()
+	}
+	publishDevicePairing(instance, webhooks.EventDevicePairFailed, PairingEventPairFailed, payload)
+}
+
+func createDevicePairingPayload(id, lid types.JID, businessName, platform string) webhooks.DevicePairingPayload {
+	payload := webhooks.DevicePairingPayload{
+		JID:          id.ToNonAD().String(),
+		BusinessName: businessName,
+		Platform:     platform,
+	}
+	if !lid.IsEmpty() {
+		payload.LID = lid.ToNonAD().String()
+	}
+	return payload
+}
+
+// publishDevicePairing reports a pairing outcome to /app/events subscribers
+// and, when enabled, to webhooks. The device's own JID isn't known until
+// pairing succeeds, so the envelope uses the JID being paired.
+func publishDevicePairing(instance *DeviceInstance, eventName, pairingEventName string, payload webhooks.DevicePairingPayload) {
+	data := map[string]any{"jid": payload.JID}
+	if payload.Error != "" {
+		data["error"] = payload.Error
+	}
+	PairingEvents.Publish(instance.ID(), pairingEventName, data)
+
+	if eventDeliveryEnabled() {
+		forwardWebhookEventAsync(newWebhookBody(eventName, payload.JID, time.Now(), payload), eventName)
+	}
+}
+
+func handleLoggedOut(ctx context.Context, instance *DeviceInstance, evt *events.LoggedOut, chatStorageRepo domainChatStorage.IChatStorageRepository) {
 	logrus.Warnf("[REMOTE_LOGOUT] Received LoggedOut event for device %s - user logged out from phone", instance.ID())
 
 	if client := instance.GetClient(); client != nil {
@@ -157,12 +240,17 @@ func handleLoggedOut(ctx context.Context, instance *DeviceInstance, chatStorageR
 	instance.SetState(domainDevice.DeviceStateDisconnected)
 
 	if chatStorageRepo != nil {
-		if err := chatStorageRepo.TruncateAllDataWithLogging("REMOTE_LOGOUT"); err != nil {
+		if err := chatStorageRepo.TruncateAllDataWithLogging(ctx, "REMOTE_LOGOUT", ""); err != nil {
 			logrus.Errorf("[REMOTE_LOGOUT] Failed to truncate chat storage: %v", err)
 		}
 	}
 
 	deviceID := instance.ID()
+	PairingEvents.Publish(deviceID, PairingEventLoggedOut, map[string]any{"reason": loggedOutReason(evt)})
+
+	if eventDeliveryEnabled() {
+		forwardWebhookEventAsync(createDeviceStatusPayload(webhooks.EventDeviceLoggedOut, instance.JID(), loggedOutReason(evt)), webhooks.EventDeviceLoggedOut)
+	}
 
 	instance.TriggerLoggedOut()
 
@@ -173,7 +261,43 @@ func handleLoggedOut(ctx context.Context, instance *DeviceInstance, chatStorageR
 	}
 }
 
-func handleConnectionEvents(_ context.Context, client *whatsmeow.Client, instance *DeviceInstance) {
+func handleDisconnected(_ context.Context, instance *DeviceInstance) {
+	logrus.Warnf("Device %s disconnected from WhatsApp", instance.ID())
+	PairingEvents.Publish(instance.ID(), PairingEventDisconnected, nil)
+
+	if eventDeliveryEnabled() {
+		forwardWebhookEventAsync(createDeviceStatusPayload(webhooks.EventDeviceDisconnected, instance.JID(), ""), webhooks.EventDeviceDisconnected)
+	}
+}
+
+// createDeviceStatusPayload creates a webhook payload for the device losing its session or connection
+func createDeviceStatusPayload(eventName, deviceID, reason string) map[string]any {
+	return newWebhookBody(eventName, deviceID, time.Now(), webhooks.DeviceStatusPayload{Reason: reason})
+}
+
+// publishDeviceRenamed reports a new display name of a device to webhooks.
+func publishDeviceRenamed(instance *DeviceInstance, previousName string) {
+	if !eventDeliveryEnabled() {
+		return
+	}
+	payload := webhooks.DeviceRenamedPayload{
+		DeviceID:     instance.ID(),
+		Name:         instance.DisplayName(),
+		PreviousName: previousName,
+	}
+	forwardWebhookEventAsync(newWebhookBody(webhooks.EventDeviceRenamed, instance.JID(), time.Now(), payload), webhooks.EventDeviceRenamed)
+}
+
+// loggedOutReason describes why the device was logged out. Only logouts
+// rejected on connect carry a reason code.
+func loggedOutReason(evt *events.LoggedOut) string {
+	if evt == nil || !evt.OnConnect {
+		return ""
+	}
+	return evt.Reason.String()
+}
+
+func handleConnectionEvents(ctx context.Context, client *whatsmeow.Client, instance *DeviceInstance) {
 	if client == nil {
 		return
 	}
@@ -186,7 +310,7 @@ func handleConnectionEvents(_ context.Context, client *whatsmeow.Client, instanc
 			jid := instance.JID()
 			displayName := instance.DisplayName()
 			if jid != "" {
-				if err := repo.SaveDeviceRecord(&domainChatStorage.DeviceRecord{
+				if err := repo.SaveDeviceRecord(ctx, &domainChatStorage.DeviceRecord{
 					DeviceID:    instance.ID(),
 					DisplayName: displayName,
 					JID:         jid,
@@ -210,7 +334,9 @@ func handleStreamReplaced(_ context.Context) {
 	os.Exit(0)
 }
 
-func handleReceipt(ctx context.Context, evt *events.Receipt, deviceID string, client *whatsmeow.Client) {
+func handleReceipt(ctx context.Context, evt *events.Receipt, chatStorageRepo domainChatStorage.IChatStorageRepository, deviceID string, client *whatsmeow.Client) {
+	storeReceipts(ctx, evt, chatStorageRepo, client)
+
 	sendReceipt := false
 	switch evt.Type {
 	case types.ReceiptTypeRead, types.ReceiptTypeReadSelf:
@@ -223,7 +349,7 @@ func handleReceipt(ctx context.Context, evt *events.Receipt, deviceID string, cl
 
 	// Forward receipt (ack) event to webhook if configured
 	// Note: Receipt events are not rate limited as they are critical for message delivery status
-	if len(config.WhatsappWebhook) > 0 && sendReceipt {
+	if eventDeliveryEnabled() && sendReceipt {
 		go func(e *events.Receipt, c *whatsmeow.Client) {
 			webhookCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancel()
@@ -234,31 +360,28 @@ func handleReceipt(ctx context.Context, evt *events.Receipt, deviceID string, cl
 	}
 }
 
-func handlePresence(_ context.Context, evt *events.Presence) {
-	if evt.Unavailable {
-		if evt.LastSeen.IsZero() {
-			log.Infof("%s is now offline", evt.From)
-		} else {
-			log.Infof("%s is now offline (last seen: %s)", evt.From, evt.LastSeen)
-		}
-	} else {
-		log.Infof("%s is now online", evt.From)
-	}
-}
-
 func handleAppState(_ context.Context, evt *events.AppState) {
 	log.Debugf("App state event: %+v / %+v", evt.Index, evt.SyncActionValue)
 }
 
-func handleGroupInfo(ctx context.Context, evt *events.GroupInfo, deviceID string, client *whatsmeow.Client) {
+func handleGroupInfo(ctx context.Context, evt *events.GroupInfo, chatStorageRepo domainChatStorage.IChatStorageRepository, deviceID string, client *whatsmeow.Client) {
 	// Only process events that have actual changes
 	hasChanges := len(evt.Join) > 0 || len(evt.Leave) > 0 || len(evt.Promote) > 0 || len(evt.Demote) > 0 ||
-		evt.Name != nil || evt.Topic != nil || evt.Locked != nil || evt.Announce != nil
+		evt.Name != nil || evt.Topic != nil || evt.Locked != nil || evt.Announce != nil || evt.Ephemeral != nil ||
+		groupMemberAddModeChange(evt) != nil || len(groupJoinRequestChanges(evt)) > 0
 
 	if !hasChanges {
 		return
 	}
 
+	if evt.Ephemeral != nil {
+		var expiration uint32
+		if evt.Ephemeral.IsEphemeral {
+			expiration = evt.Ephemeral.DisappearingTimer
+		}
+		handleEphemeralChange(ctx, chatStorageRepo, deviceID, client, evt.JID, evt.Sender, expiration, evt.Timestamp)
+	}
+
 	// Log group events for debugging
 	if len(evt.Join) > 0 {
 		log.Infof("Group %s: %d users joined at %s", evt.JID, len(evt.Join), evt.Timestamp)
@@ -273,8 +396,12 @@ func handleGroupInfo(ctx context.Context, evt *events.GroupInfo, deviceID string
 		log.Infof("Group %s: %d users demoted at %s", evt.JID, len(evt.Demote), evt.Timestamp)
 	}
 
+	storeGroupParticipantChanges(ctx, evt, chatStorageRepo, client)
+	storeGroupName(ctx, evt, chatStorageRepo)
+	storeGroupLeft(ctx, evt, chatStorageRepo, client)
+
 	// Forward group info event to webhook if configured
-	if len(config.WhatsappWebhook) > 0 {
+	if eventDeliveryEnabled() {
 		go func(e *events.GroupInfo, c *whatsmeow.Client) {
 			webhookCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancel()
@@ -2,9 +2,13 @@ package whatsapp
 
 import (
 	"testing"
+	"time"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"go.mau.fi/whatsmeow/proto/waSyncAction"
 	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestResolvePresenceOnConnect(t *testing.T) {
@@ -62,3 +66,26 @@ func TestResolvePresenceOnConnect(t *testing.T) {
 		})
 	}
 }
+
+func TestMuteEndTime(t *testing.T) {
+	end := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		action *waSyncAction.MuteAction
+		want   time.Time
+	}{
+		{name: "unmuted", action: &waSyncAction.MuteAction{Muted: proto.Bool(false)}, want: time.Time{}},
+		{name: "missing action", action: nil, want: time.Time{}},
+		{name: "muted until", action: &waSyncAction.MuteAction{Muted: proto.Bool(true), MuteEndTimestamp: proto.Int64(end.UnixMilli())}, want: end},
+		{name: "muted forever", action: &waSyncAction.MuteAction{Muted: proto.Bool(true), MuteEndTimestamp: proto.Int64(-1)}, want: domainChatStorage.MutedForever},
+		{name: "muted without end", action: &waSyncAction.MuteAction{Muted: proto.Bool(true)}, want: domainChatStorage.MutedForever},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := muteEndTime(tt.action); !got.Equal(tt.want) {
+				t.Errorf("muteEndTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
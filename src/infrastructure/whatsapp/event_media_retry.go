@@ -0,0 +1,80 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waMmsRetry"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// mediaHost serves attachments by their direct path.
+const mediaHost = "https://mmg.whatsapp.net"
+
+// RequestMediaRetry asks the sender of a stored message to upload its
+// attachment again. The new location arrives later as an events.MediaRetry.
+func RequestMediaRetry(ctx context.Context, client *whatsmeow.Client, message *domainChatStorage.Message) error {
+	info, err := storedMessageInfo(message)
+	if err != nil {
+		return err
+	}
+	return client.SendMediaRetryReceipt(ctx, info, message.MediaKey)
+}
+
+// storedMessageInfo rebuilds the message info a media retry receipt needs from
+// a stored message.
+func storedMessageInfo(message *domainChatStorage.Message) (*types.MessageInfo, error) {
+	if len(message.MediaKey) == 0 {
+		return nil, fmt.Errorf("message %s does not contain downloadable media", message.ID)
+	}
+	chat, err := types.ParseJID(message.ChatJID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chat of message %s: %w", message.ID, err)
+	}
+	info := &types.MessageInfo{
+		ID: message.ID,
+		MessageSource: types.MessageSource{
+			Chat:     chat,
+			IsFromMe: message.IsFromMe,
+			IsGroup:  chat.Server == types.GroupServer,
+		},
+	}
+	if info.IsGroup {
+		if info.Sender, err = types.ParseJID(message.Sender); err != nil {
+			return nil, fmt.Errorf("invalid sender of message %s: %w", message.ID, err)
+		}
+	}
+	return info, nil
+}
+
+// handleMediaRetry stores the new URL of an attachment the sender re-uploaded
+// after RequestMediaRetry, so that the next download uses it.
+func handleMediaRetry(ctx context.Context, evt *events.MediaRetry, chatStorageRepo domainChatStorage.IChatStorageRepository) {
+	if chatStorageRepo == nil {
+		return
+	}
+	message, err := chatStorageRepo.GetMessageByDevice(ctx, "", evt.MessageID)
+	if err != nil || message == nil || len(message.MediaKey) == 0 {
+		log.Debugf("Ignoring media retry for unknown message %s", evt.MessageID)
+		return
+	}
+
+	retryData, err := whatsmeow.DecryptMediaRetryNotification(evt, message.MediaKey)
+	if err != nil {
+		log.Warnf("Media retry for message %s failed: %v", evt.MessageID, err)
+		return
+	}
+	if retryData.GetResult() != waMmsRetry.MediaRetryNotification_SUCCESS || retryData.GetDirectPath() == "" {
+		log.Warnf("Media retry for message %s failed: %s", evt.MessageID, retryData.GetResult())
+		return
+	}
+
+	if err := chatStorageRepo.SetMessageMediaURL(ctx, message.DeviceID, message.ID, message.ChatJID, mediaHost+retryData.GetDirectPath()); err != nil {
+		log.Errorf("Failed to store new media URL of message %s: %v", evt.MessageID, err)
+		return
+	}
+	log.Infof("Media of message %s is available for download again", evt.MessageID)
+}
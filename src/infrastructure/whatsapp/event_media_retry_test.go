@@ -0,0 +1,33 @@
+package whatsapp
+
+import (
+	"testing"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+)
+
+func TestStoredMessageInfo(t *testing.T) {
+	info, err := storedMessageInfo(&domainChatStorage.Message{
+		ID: "3EB0A", ChatJID: "120363@g.us", Sender: "628111:3@s.whatsapp.net", MediaKey: []byte("key"),
+	})
+	if err != nil {
+		t.Fatalf("storedMessageInfo() error = %v", err)
+	}
+	if !info.IsGroup || info.Chat.String() != "120363@g.us" || info.Sender.String() != "628111:3@s.whatsapp.net" {
+		t.Fatalf("unexpected group message info %+v", info.MessageSource)
+	}
+
+	info, err = storedMessageInfo(&domainChatStorage.Message{
+		ID: "3EB0B", ChatJID: "628111@s.whatsapp.net", Sender: "628222@s.whatsapp.net", IsFromMe: true, MediaKey: []byte("key"),
+	})
+	if err != nil {
+		t.Fatalf("storedMessageInfo() error = %v", err)
+	}
+	if info.IsGroup || !info.IsFromMe || !info.Sender.IsEmpty() {
+		t.Fatalf("unexpected direct message info %+v", info.MessageSource)
+	}
+
+	if _, err := storedMessageInfo(&domainChatStorage.Message{ID: "3EB0C", ChatJID: "628111@s.whatsapp.net"}); err == nil {
+		t.Fatal("expected an error for a message without media key")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"go.mau.fi/whatsmeow/types"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
 	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/sirupsen/logrus"
@@ -24,6 +25,7 @@ const (
 	EventTypeMessageReaction = "message.reaction"
 	EventTypeMessageRevoked  = "message.revoked"
 	EventTypeMessageEdited   = "message.edited"
+	EventTypeMessagePollVote = "message.poll_vote"
 )
 
 // WebhookEvent is the top-level structure for webhook payloads
@@ -33,12 +35,15 @@ type WebhookEvent struct {
 	Payload  map[string]any `json:"payload"`
 }
 
-// forwardMessageToWebhook is a helper function to forward message event to webhook url
-func forwardMessageToWebhook(ctx context.Context, client *whatsmeow.Client, evt *events.Message) error {
-	webhookEvent, err := createWebhookEvent(ctx, client, evt)
+// forwardMessageToWebhook is a helper function to forward message event to webhook url.
+// revoked is the stored copy of the message a REVOKE event deletes, if known, and
+// pollVote the decrypted vote of a poll update.
+func forwardMessageToWebhook(ctx context.Context, client *whatsmeow.Client, chatStorageRepo domainChatStorage.IChatStorageRepository, evt *events.Message, revoked *domainChatStorage.Message, pollVote *domainChatStorage.PollVote) error {
+	webhookEvent, err := createWebhookEvent(ctx, client, evt, revoked, pollVote)
 	if err != nil {
 		return err
 	}
+	enrichMessagePayload(ctx, chatStorageRepo, webhookEvent.DeviceID, evt, webhookEvent.Payload)
 
 	payload := map[string]any{
 		"event":     webhookEvent.Event,
@@ -49,7 +54,7 @@ func forwardMessageToWebhook(ctx context.Context, client *whatsmeow.Client, evt
 	return forwardPayloadToConfiguredWebhooks(ctx, payload, webhookEvent.Event)
 }
 
-func createWebhookEvent(ctx context.Context, client *whatsmeow.Client, evt *events.Message) (*WebhookEvent, error) {
+func createWebhookEvent(ctx context.Context, client *whatsmeow.Client, evt *events.Message, revoked *domainChatStorage.Message, pollVote *domainChatStorage.PollVote) (*WebhookEvent, error) {
 	webhookEvent := &WebhookEvent{
 		Event:   EventTypeMessage,
 		Payload: make(map[string]any),
@@ -67,6 +72,21 @@ func createWebhookEvent(ctx context.Context, client *whatsmeow.Client, evt *even
 		return nil, err
 	}
 
+	// Storage may blank the deleted message, so its original content is only known here
+	if eventType == EventTypeMessageRevoked && revoked != nil {
+		payload["original_content"] = revoked.Content
+		if revoked.MediaType != "" {
+			payload["original_media_type"] = revoked.MediaType
+		}
+	}
+
+	// The event only carries the encrypted vote, so the selection comes from the decoded copy
+	if pollVote != nil {
+		eventType = EventTypeMessagePollVote
+		payload["poll_message_id"] = pollVote.MessageID
+		payload["selected_options"] = pollVote.SelectedOptions
+	}
+
 	webhookEvent.Event = eventType
 	webhookEvent.Payload = payload
 
@@ -201,7 +221,7 @@ func buildMessageBody(ctx context.Context, client *whatsmeow.Client, evt *events
 }
 
 func buildOptionalFields(ctx context.Context, client *whatsmeow.Client, evt *events.Message, msg *waE2E.Message, payload map[string]any) error {
-	if evt.IsViewOnce {
+	if evt.IsViewOnce || utils.IsViewOnceMessage(evt.Message) {
 		payload["view_once"] = true
 	}
 
@@ -279,6 +299,7 @@ func buildMediaFields(ctx context.Context, client *whatsmeow.Client, msg *waE2E.
 				"url": stickerMedia.GetURL(),
 			}
 		}
+		payload["is_animated"] = stickerMedia.GetIsAnimated()
 	}
 
 	if videoMedia := msg.GetVideoMessage(); videoMedia != nil {
@@ -336,4 +357,12 @@ func buildOtherMessageTypes(msg *waE2E.Message, payload map[string]any) {
 	if orderMessage := msg.GetOrderMessage(); orderMessage != nil {
 		payload["order"] = orderMessage
 	}
+
+	// Flattened next to the raw messages above, so consumers do not depend on their proto field names
+	if location := ExtractLocation(msg); location != nil {
+		payload["coordinates"] = location
+	}
+	if cards := ExtractContactCards(msg); cards != nil {
+		payload["contact_cards"] = cards
+	}
 }
@@ -11,11 +11,12 @@ import (
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 )
 
-func handleMessage(ctx context.Context, evt *events.Message, chatStorageRepo domainChatStorage.IChatStorageRepository, client *whatsmeow.Client) {
+func handleMessage(ctx context.Context, evt *events.Message, chatStorageRepo domainChatStorage.IChatStorageRepository, deviceID string, client *whatsmeow.Client, automation messageAutomation) {
 	// Log message metadata
 	metaParts := buildMessageMetaParts(evt)
 	log.Infof("Received message %s from %s (%s): %+v",
@@ -25,22 +26,56 @@ func handleMessage(ctx context.Context, evt *events.Message, chatStorageRepo dom
 		evt.Message,
 	)
 
+	// Look up a revoked message before storage blanks it, so the webhook can carry it
+	revoked := lookupRevokedMessage(ctx, evt, chatStorageRepo)
+
 	if err := chatStorageRepo.CreateMessage(ctx, evt); err != nil {
 		// Log storage errors to avoid silent failures that could lead to data loss
 		log.Errorf("Failed to store incoming message %s: %v", evt.Info.ID, err)
 	}
 
+	// Poll votes are encrypted, so decode them once for both storage and the webhook
+	pollVote := decodePollVote(ctx, evt, chatStorageRepo, client)
+	if pollVote != nil {
+		if err := chatStorageRepo.StorePollVote(ctx, pollVote); err != nil {
+			log.Errorf("Failed to store poll vote %s: %v", evt.Info.ID, err)
+		}
+	}
+
+	// Keep the chat's disappearing message timer in sync
+	handleEphemeralSetting(ctx, evt, chatStorageRepo, deviceID, client)
+
+	// Keep a local copy of allowed attachments before their URL expires
+	handleMediaAutoDownload(ctx, evt, chatStorageRepo, client)
+
 	// Handle image message if present
 	handleImageMessage(ctx, evt, client)
 
 	// Auto-mark message as read if configured
-	handleAutoMarkRead(ctx, evt, client)
+	handleAutoMarkRead(ctx, evt, chatStorageRepo, client, automation.MarkRead)
+
+	// Auto-view statuses if configured
+	handleAutoViewStatus(ctx, evt, client)
 
 	// Handle auto-reply if configured
-	handleAutoReply(ctx, evt, chatStorageRepo, client)
+	handleAutoReply(ctx, evt, chatStorageRepo, deviceID, client, automation.ReplyMessage)
 
 	// Forward to webhook if configured
-	handleWebhookForward(ctx, evt, client)
+	handleWebhookForward(ctx, evt, chatStorageRepo, client, revoked, pollVote)
+}
+
+// lookupRevokedMessage returns the stored message a REVOKE event deletes, or nil.
+func lookupRevokedMessage(ctx context.Context, evt *events.Message, chatStorageRepo domainChatStorage.IChatStorageRepository) *domainChatStorage.Message {
+	protocol := utils.UnwrapMessage(evt.Message).GetProtocolMessage()
+	if protocol.GetType() != waE2E.ProtocolMessage_REVOKE || protocol.GetKey().GetID() == "" {
+		return nil
+	}
+	message, err := chatStorageRepo.GetMessageByID(ctx, protocol.GetKey().GetID())
+	if err != nil {
+		log.Warnf("Failed to look up revoked message %s: %v", protocol.GetKey().GetID(), err)
+		return nil
+	}
+	return message
 }
 
 func buildMessageMetaParts(evt *events.Message) []string {
@@ -76,9 +111,10 @@ func handleImageMessage(ctx context.Context, evt *events.Message, client *whatsm
 	}
 }
 
-func handleAutoMarkRead(ctx context.Context, evt *events.Message, client *whatsmeow.Client) {
-	// Only mark read if auto-mark read is enabled and message is incoming
-	if !config.WhatsappAutoMarkRead || evt.Info.IsFromMe {
+func handleAutoMarkRead(ctx context.Context, evt *events.Message, chatStorageRepo domainChatStorage.IChatStorageRepository, client *whatsmeow.Client, enabled bool) {
+	// Only mark read if auto-mark read is enabled and message is incoming.
+	// Viewing statuses shows up to their poster, so it has its own flag.
+	if !enabled || evt.Info.IsFromMe || evt.Info.Chat == types.StatusBroadcastJID {
 		return
 	}
 
@@ -94,12 +130,35 @@ func handleAutoMarkRead(ctx context.Context, evt *events.Message, client *whatsm
 
 	if err := client.MarkRead(ctx, messageIDs, timestamp, chat, sender); err != nil {
 		log.Warnf("Failed to mark message %s as read: %v", evt.Info.ID, err)
-	} else {
-		log.Debugf("Marked message %s as read", evt.Info.ID)
+		return
+	}
+	log.Debugf("Marked message %s as read", evt.Info.ID)
+
+	chatJID := NormalizeJIDFromLID(ctx, chat, client).String()
+	if err := chatStorageRepo.MarkChatRead(ctx, "", chatJID); err != nil {
+		log.Warnf("Failed to reset unread count of %s: %v", chatJID, err)
+	}
+}
+
+// handleAutoViewStatus marks a status posted by a contact as viewed when
+// config.WhatsappAutoViewStatus is enabled.
+func handleAutoViewStatus(ctx context.Context, evt *events.Message, client *whatsmeow.Client) {
+	if !config.WhatsappAutoViewStatus || evt.Info.IsFromMe || evt.Info.Chat != types.StatusBroadcastJID || client == nil {
+		return
+	}
+	// Revoked statuses and other protocol messages have nothing to view
+	if evt.Message.GetProtocolMessage() != nil {
+		return
+	}
+
+	if err := client.MarkRead(ctx, []types.MessageID{evt.Info.ID}, time.Now(), evt.Info.Chat, evt.Info.Sender); err != nil {
+		log.Warnf("Failed to view status %s of %s: %v", evt.Info.ID, evt.Info.Sender, err)
+		return
 	}
+	log.Debugf("Viewed status %s of %s", evt.Info.ID, evt.Info.Sender)
 }
 
-func handleWebhookForward(ctx context.Context, evt *events.Message, client *whatsmeow.Client) {
+func handleWebhookForward(ctx context.Context, evt *events.Message, chatStorageRepo domainChatStorage.IChatStorageRepository, client *whatsmeow.Client, revoked *domainChatStorage.Message, pollVote *domainChatStorage.PollVote) {
 	// Skip webhook for protocol messages that are internal sync messages
 	if protocolMessage := evt.Message.GetProtocolMessage(); protocolMessage != nil {
 		protocolType := protocolMessage.GetType().String()
@@ -114,12 +173,12 @@ func handleWebhookForward(ctx context.Context, evt *events.Message, client *what
 		}
 	}
 
-	if (len(config.WhatsappWebhook) > 0 || config.ChatwootEnabled) &&
+	if (eventDeliveryEnabled() || config.ChatwootEnabled) &&
 		!strings.Contains(evt.Info.SourceString(), "broadcast") {
 		go func(e *events.Message, c *whatsmeow.Client) {
 			webhookCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancel()
-			if err := forwardMessageToWebhook(webhookCtx, c, e); err != nil {
+			if err := forwardMessageToWebhook(webhookCtx, c, chatStorageRepo, e, revoked, pollVote); err != nil {
 				logrus.Error("Failed forward to webhook: ", err)
 			}
 		}(evt, client)
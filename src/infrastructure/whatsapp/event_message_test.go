@@ -2,11 +2,15 @@ package whatsapp
 
 import (
 	"context"
+	"reflect"
 	"testing"
 	"time"
 
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/proto/waCommon"
 	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/store"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 )
@@ -79,6 +83,44 @@ func TestBuildEventPayloadRevokedIncludesIsFromMe(t *testing.T) {
 	}
 }
 
+func TestCreateSentEditPayloadMatchesIncomingEdit(t *testing.T) {
+	own := types.NewADJID("628111", 0, 3)
+	chat := types.NewJID("628222", types.DefaultUserServer)
+	timestamp := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	evt := &events.Message{
+		Info: types.MessageInfo{
+			MessageSource: types.MessageSource{
+				Chat:     chat,
+				Sender:   own,
+				IsFromMe: true,
+			},
+			ID:        "EDIT1",
+			Timestamp: timestamp,
+		},
+		Message: &waE2E.Message{
+			ProtocolMessage: &waE2E.ProtocolMessage{
+				Type:          protoProtocolMessageType(waE2E.ProtocolMessage_MESSAGE_EDIT),
+				Key:           &waCommon.MessageKey{RemoteJID: protoString(chat.String()), FromMe: protoBool(true), ID: protoString("ORIG1")},
+				EditedMessage: &waE2E.Message{Conversation: protoString("fixed typo")},
+			},
+		},
+	}
+	eventType, incoming, err := buildEventPayload(context.Background(), nil, evt)
+	if err != nil || eventType != EventTypeMessageEdited {
+		t.Fatalf("unexpected incoming edit: %s, %v", eventType, err)
+	}
+
+	client := &whatsmeow.Client{Store: &store.Device{ID: &own}}
+	body := createSentEditPayload(context.Background(), client, chat, "EDIT1", "ORIG1", "fixed typo", timestamp)
+	if body["event"] != EventTypeMessageEdited || body["device_id"] != "628111@s.whatsapp.net" {
+		t.Fatalf("unexpected envelope: %v", body)
+	}
+	sent := body["payload"].(map[string]any)
+	if !reflect.DeepEqual(sent, incoming) {
+		t.Fatalf("sent edit payload %v doesn't match incoming %v", sent, incoming)
+	}
+}
+
 func protoString(value string) *string {
 	return &value
 }
@@ -87,6 +129,181 @@ func protoBool(value bool) *bool {
 	return &value
 }
 
+func protoFloat64(value float64) *float64 {
+	return &value
+}
+
+func protoUint32(value uint32) *uint32 {
+	return &value
+}
+
+func protoInt64(value int64) *int64 {
+	return &value
+}
+
 func protoProtocolMessageType(value waE2E.ProtocolMessage_Type) *waE2E.ProtocolMessage_Type {
 	return &value
 }
+
+func TestCreateWebhookEventRevokedIncludesOriginalContent(t *testing.T) {
+	evt := &events.Message{
+		Info: types.MessageInfo{
+			MessageSource: types.MessageSource{
+				Chat:   types.NewJID("123", types.DefaultUserServer),
+				Sender: types.NewJID("123", types.DefaultUserServer),
+			},
+			ID:        "MSG125",
+			Timestamp: time.Date(2026, time.February, 8, 10, 0, 0, 0, time.UTC),
+		},
+		Message: &waE2E.Message{
+			ProtocolMessage: &waE2E.ProtocolMessage{
+				Type: protoProtocolMessageType(waE2E.ProtocolMessage_REVOKE),
+				Key:  &waCommon.MessageKey{ID: protoString("REV125")},
+			},
+		},
+	}
+
+	webhookEvent, err := createWebhookEvent(context.Background(), nil, evt, &domainChatStorage.Message{Content: "see attached", MediaType: "image"}, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := webhookEvent.Payload["original_content"]; got != "see attached" {
+		t.Fatalf("expected original_content=see attached, got %v", got)
+	}
+	if got := webhookEvent.Payload["original_media_type"]; got != "image" {
+		t.Fatalf("expected original_media_type=image, got %v", got)
+	}
+
+	webhookEvent, err = createWebhookEvent(context.Background(), nil, evt, nil, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, ok := webhookEvent.Payload["original_content"]; ok {
+		t.Fatalf("expected no original_content without a stored message")
+	}
+}
+
+func TestCreateWebhookEventPollVoteIncludesSelectedOptions(t *testing.T) {
+	evt := &events.Message{
+		Info: types.MessageInfo{
+			MessageSource: types.MessageSource{
+				Chat:   types.NewJID("123", types.DefaultUserServer),
+				Sender: types.NewJID("456", types.DefaultUserServer),
+			},
+			ID:        "MSG126",
+			Timestamp: time.Date(2026, time.February, 8, 10, 0, 0, 0, time.UTC),
+		},
+		Message: &waE2E.Message{
+			PollUpdateMessage: &waE2E.PollUpdateMessage{
+				PollCreationMessageKey: &waCommon.MessageKey{ID: protoString("POLL126")},
+			},
+		},
+	}
+
+	webhookEvent, err := createWebhookEvent(context.Background(), nil, evt, nil, &domainChatStorage.PollVote{
+		MessageID:       "POLL126",
+		SelectedOptions: []string{"Pizza"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if webhookEvent.Event != EventTypeMessagePollVote {
+		t.Fatalf("expected event type %s, got %s", EventTypeMessagePollVote, webhookEvent.Event)
+	}
+	if got := webhookEvent.Payload["poll_message_id"]; got != "POLL126" {
+		t.Fatalf("expected poll_message_id=POLL126, got %v", got)
+	}
+	if got, ok := webhookEvent.Payload["selected_options"].([]string); !ok || len(got) != 1 || got[0] != "Pizza" {
+		t.Fatalf("expected selected_options=[Pizza], got %v", webhookEvent.Payload["selected_options"])
+	}
+}
+
+func TestBuildEventPayloadIncludesCoordinates(t *testing.T) {
+	tests := []struct {
+		name    string
+		message *waE2E.Message
+		want    domainChatStorage.Location
+	}{
+		{
+			name: "location",
+			message: &waE2E.Message{LocationMessage: &waE2E.LocationMessage{
+				DegreesLatitude:  protoFloat64(-6.2),
+				DegreesLongitude: protoFloat64(106.816666),
+				Name:             protoString("Monas"),
+			}},
+			want: domainChatStorage.Location{Latitude: -6.2, Longitude: 106.816666, Name: "Monas"},
+		},
+		{
+			name: "live location",
+			message: &waE2E.Message{LiveLocationMessage: &waE2E.LiveLocationMessage{
+				DegreesLatitude:  protoFloat64(-6.18),
+				DegreesLongitude: protoFloat64(106.8),
+				AccuracyInMeters: protoUint32(15),
+				SequenceNumber:   protoInt64(3),
+			}},
+			want: domainChatStorage.Location{Latitude: -6.18, Longitude: 106.8, Accuracy: 15, SequenceNumber: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evt := &events.Message{
+				Info: types.MessageInfo{
+					MessageSource: types.MessageSource{
+						Chat:   types.NewJID("123", types.DefaultUserServer),
+						Sender: types.NewJID("123", types.DefaultUserServer),
+					},
+					ID:        "MSG127",
+					Timestamp: time.Date(2026, time.February, 8, 10, 0, 0, 0, time.UTC),
+				},
+				Message: tt.message,
+			}
+
+			_, payload, err := buildEventPayload(context.Background(), nil, evt)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			got, ok := payload["coordinates"].(*domainChatStorage.Location)
+			if !ok {
+				t.Fatalf("expected coordinates in payload, got %v", payload["coordinates"])
+			}
+			if *got != tt.want {
+				t.Fatalf("expected coordinates %+v, got %+v", tt.want, *got)
+			}
+		})
+	}
+}
+
+func TestBuildEventPayloadIncludesContactCards(t *testing.T) {
+	evt := &events.Message{
+		Info: types.MessageInfo{
+			MessageSource: types.MessageSource{
+				Chat:   types.NewJID("123", types.DefaultUserServer),
+				Sender: types.NewJID("123", types.DefaultUserServer),
+			},
+			ID:        "MSG128",
+			Timestamp: time.Date(2026, time.February, 8, 10, 0, 0, 0, time.UTC),
+		},
+		Message: &waE2E.Message{ContactsArrayMessage: &waE2E.ContactsArrayMessage{
+			Contacts: []*waE2E.ContactMessage{
+				{Vcard: protoString("BEGIN:VCARD\nVERSION:3.0\nFN:3Care\nTEL;type=Mobile:+62 132\nEND:VCARD")},
+				{DisplayName: protoString("Jane"), Vcard: protoString("BEGIN:VCARD\nVERSION:3.0\nFN:Jane Doe\nEND:VCARD")},
+			},
+		}},
+	}
+
+	_, payload, err := buildEventPayload(context.Background(), nil, evt)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	cards, ok := payload["contact_cards"].([]domainChatStorage.ContactCard)
+	if !ok || len(cards) != 2 {
+		t.Fatalf("expected 2 contact_cards in payload, got %v", payload["contact_cards"])
+	}
+	if cards[0].DisplayName != "3Care" || len(cards[0].PhoneNumbers) != 1 || cards[0].PhoneNumbers[0] != "+62 132" {
+		t.Fatalf("expected 3Care with +62 132, got %+v", cards[0])
+	}
+	if cards[1].DisplayName != "Jane" || len(cards[1].PhoneNumbers) != 0 {
+		t.Fatalf("expected Jane without phone numbers, got %+v", cards[1])
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"time"
 
-	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/types/events"
@@ -14,7 +13,7 @@ import (
 func handleNewsletterJoin(ctx context.Context, evt *events.NewsletterJoin, deviceID string, client *whatsmeow.Client) {
 	log.Infof("Joined newsletter %s", evt.ID)
 
-	if len(config.WhatsappWebhook) > 0 {
+	if eventDeliveryEnabled() {
 		go func(e *events.NewsletterJoin) {
 			webhookCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancel()
@@ -29,7 +28,7 @@ func handleNewsletterJoin(ctx context.Context, evt *events.NewsletterJoin, devic
 func handleNewsletterLeave(ctx context.Context, evt *events.NewsletterLeave, deviceID string, client *whatsmeow.Client) {
 	log.Infof("Left newsletter %s (role: %s)", evt.ID, evt.Role)
 
-	if len(config.WhatsappWebhook) > 0 {
+	if eventDeliveryEnabled() {
 		go func(e *events.NewsletterLeave) {
 			webhookCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancel()
@@ -44,7 +43,7 @@ func handleNewsletterLeave(ctx context.Context, evt *events.NewsletterLeave, dev
 func handleNewsletterLiveUpdate(ctx context.Context, evt *events.NewsletterLiveUpdate, deviceID string, client *whatsmeow.Client) {
 	log.Infof("Newsletter %s: %d new message(s)", evt.JID, len(evt.Messages))
 
-	if len(config.WhatsappWebhook) > 0 {
+	if eventDeliveryEnabled() {
 		go func(e *events.NewsletterLiveUpdate) {
 			webhookCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancel()
@@ -59,7 +58,7 @@ func handleNewsletterLiveUpdate(ctx context.Context, evt *events.NewsletterLiveU
 func handleNewsletterMuteChange(ctx context.Context, evt *events.NewsletterMuteChange, deviceID string, client *whatsmeow.Client) {
 	log.Infof("Newsletter %s mute changed to: %s", evt.ID, evt.Mute)
 
-	if len(config.WhatsappWebhook) > 0 {
+	if eventDeliveryEnabled() {
 		go func(e *events.NewsletterMuteChange) {
 			webhookCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancel()
@@ -0,0 +1,56 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/hex"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// decodePollVote decrypts a poll vote and maps the selected option hashes back
+// to the option names of the stored poll. Hashes of options that are not known,
+// e.g. for polls created before chat storage was enabled, are kept hex encoded.
+// It returns nil for other messages and votes that cannot be decrypted.
+func decodePollVote(ctx context.Context, evt *events.Message, chatStorageRepo domainChatStorage.IChatStorageRepository, client *whatsmeow.Client) *domainChatStorage.PollVote {
+	update := evt.Message.GetPollUpdateMessage()
+	if update == nil || client == nil {
+		return nil
+	}
+	decrypted, err := client.DecryptPollVote(ctx, evt)
+	if err != nil {
+		log.Warnf("Failed to decrypt poll vote %s: %v", evt.Info.ID, err)
+		return nil
+	}
+
+	chatJID := NormalizeJIDFromLID(ctx, evt.Info.Chat, client).ToNonAD().String()
+	pollID := update.GetPollCreationMessageKey().GetID()
+	names := make(map[string]string)
+	if chatStorageRepo != nil {
+		poll, err := chatStorageRepo.GetPoll(ctx, "", chatJID, pollID)
+		if err != nil {
+			log.Warnf("Failed to look up poll %s: %v", pollID, err)
+		} else if poll != nil {
+			for i, hash := range whatsmeow.HashPollOptions(poll.Options) {
+				names[string(hash)] = poll.Options[i]
+			}
+		}
+	}
+
+	selected := make([]string, 0, len(decrypted.GetSelectedOptions()))
+	for _, hash := range decrypted.GetSelectedOptions() {
+		if name, ok := names[string(hash)]; ok {
+			selected = append(selected, name)
+		} else {
+			selected = append(selected, hex.EncodeToString(hash))
+		}
+	}
+	return &domainChatStorage.PollVote{
+		MessageID:       pollID,
+		ChatJID:         chatJID,
+		Voter:           NormalizeJIDFromLID(ctx, evt.Info.Sender, client).ToNonAD().String(),
+		SelectedOptions: selected,
+		Timestamp:       evt.Info.Timestamp,
+	}
+}
@@ -0,0 +1,87 @@
+package whatsapp
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/webhooks"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+func handlePresence(ctx context.Context, evt *events.Presence, deviceID string, client *whatsmeow.Client) {
+	if evt.Unavailable {
+		if evt.LastSeen.IsZero() {
+			log.Infof("%s is now offline", evt.From)
+		} else {
+			log.Infof("%s is now offline (last seen: %s)", evt.From, evt.LastSeen)
+		}
+	} else {
+		log.Infof("%s is now online", evt.From)
+	}
+
+	if eventDeliveryEnabled() {
+		forwardWebhookEventAsync(createPresencePayload(ctx, evt, deviceID, client), webhooks.EventPresence)
+	}
+}
+
+func handleChatPresence(ctx context.Context, evt *events.ChatPresence, deviceID string, client *whatsmeow.Client) {
+	log.Debugf("%s is %s in %s", evt.Sender, evt.State, evt.Chat)
+
+	if eventDeliveryEnabled() {
+		forwardWebhookEventAsync(createChatPresencePayload(ctx, evt, deviceID, client), webhooks.EventChatPresence)
+	}
+}
+
+// createPresencePayload creates a webhook payload for a contact going online or offline
+func createPresencePayload(ctx context.Context, evt *events.Presence, deviceID string, client *whatsmeow.Client) map[string]any {
+	payload := webhooks.PresencePayload{
+		From:      NormalizeJIDFromLID(ctx, evt.From, client).ToNonAD().String(),
+		Available: !evt.Unavailable,
+	}
+	if !evt.LastSeen.IsZero() {
+		lastSeen := evt.LastSeen
+		payload.LastSeen = &lastSeen
+	}
+	return newWebhookBody(webhooks.EventPresence, deviceID, time.Now(), payload)
+}
+
+// createChatPresencePayload creates a webhook payload for typing and recording indicators
+func createChatPresencePayload(ctx context.Context, evt *events.ChatPresence, deviceID string, client *whatsmeow.Client) map[string]any {
+	payload := webhooks.ChatPresencePayload{
+		ChatID: NormalizeJIDFromLID(ctx, evt.Chat, client).ToNonAD().String(),
+		From:   NormalizeJIDFromLID(ctx, evt.Sender, client).ToNonAD().String(),
+		State:  string(evt.State),
+	}
+	if evt.State == types.ChatPresenceComposing {
+		payload.Media = string(evt.Media)
+	}
+	return newWebhookBody(webhooks.EventChatPresence, deviceID, time.Now(), payload)
+}
+
+// newWebhookBody wraps payload in the envelope every webhook event shares.
+func newWebhookBody(eventName, deviceID string, timestamp time.Time, payload any) map[string]any {
+	body := map[string]any{
+		"event":     eventName,
+		"timestamp": timestamp.Format(time.RFC3339),
+		"payload":   payload,
+	}
+	if deviceID != "" {
+		body["device_id"] = deviceID
+	}
+	return body
+}
+
+// forwardWebhookEventAsync delivers body in the background so slow webhooks
+// never hold up the event handler.
+func forwardWebhookEventAsync(body map[string]any, eventName string) {
+	go func() {
+		webhookCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := forwardPayloadToConfiguredWebhooks(webhookCtx, body, eventName); err != nil {
+			logrus.Errorf("Failed to forward %s event to webhook: %v", eventName, err)
+		}
+	}()
+}
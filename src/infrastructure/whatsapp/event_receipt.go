@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
 	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/types"
@@ -34,6 +35,37 @@ func getReceiptTypeDescription(evt types.ReceiptType) string {
 	}
 }
 
+// storedReceiptTypes maps the receipt types kept in chat storage to their stored names
+var storedReceiptTypes = map[types.ReceiptType]string{
+	types.ReceiptTypeDelivered: domainChatStorage.ReceiptTypeDelivered,
+	types.ReceiptTypeRead:      domainChatStorage.ReceiptTypeRead,
+	types.ReceiptTypePlayed:    domainChatStorage.ReceiptTypePlayed,
+}
+
+// storeReceipts records delivery, read and played receipts other users send
+// for our messages. Receipts from our own devices are ignored.
+func storeReceipts(ctx context.Context, evt *events.Receipt, chatStorageRepo domainChatStorage.IChatStorageRepository, client *whatsmeow.Client) {
+	receiptType, ok := storedReceiptTypes[evt.Type]
+	if !ok || evt.IsFromMe || chatStorageRepo == nil {
+		return
+	}
+
+	chatJID := NormalizeJIDFromLID(ctx, evt.Chat, client).String()
+	recipient := NormalizeJIDFromLID(ctx, evt.Sender, client).ToNonAD().String()
+	for _, messageID := range evt.MessageIDs {
+		receipt := &domainChatStorage.Receipt{
+			MessageID: messageID,
+			ChatJID:   chatJID,
+			Recipient: recipient,
+			Type:      receiptType,
+			Timestamp: evt.Timestamp,
+		}
+		if err := chatStorageRepo.StoreReceipt(ctx, receipt); err != nil {
+			logrus.Errorf("Failed to store %s receipt for message %s: %v", receiptType, messageID, err)
+		}
+	}
+}
+
 // createReceiptPayload creates a webhook payload for message acknowledgement (receipt) events
 func createReceiptPayload(ctx context.Context, evt *events.Receipt, deviceID string, client *whatsmeow.Client) map[string]any {
 	body := make(map[string]any)
@@ -0,0 +1,31 @@
+package whatsapp
+
+import (
+	"encoding/json"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+// publishToEventStream sends a webhook envelope to the /ws/events
+// subscribers. Subscribers filter by device ID or JID, so both are passed on.
+func publishToEventStream(payload map[string]any, eventName string) {
+	if !websocket.Events.HasSubscribers() {
+		return
+	}
+
+	frame, err := json.Marshal(payload)
+	if err != nil {
+		logrus.Warnf("Failed to encode %s for the event stream: %v", eventName, err)
+		return
+	}
+
+	var deviceIDs []string
+	if deviceJID, _ := payload["device_id"].(string); deviceJID != "" {
+		deviceIDs = append(deviceIDs, deviceJID)
+		if deviceID := deviceIDForJID(deviceJID); deviceID != "" && deviceID != deviceJID {
+			deviceIDs = append(deviceIDs, deviceID)
+		}
+	}
+	websocket.Events.Publish(eventName, deviceIDs, frame)
+}
@@ -0,0 +1,38 @@
+package whatsapp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/websocket"
+)
+
+func TestForwardPayloadToConfiguredWebhooks_PublishesToEventStream(t *testing.T) {
+	originalWebhooks := config.WhatsappWebhook
+	config.WhatsappWebhook = nil
+	t.Cleanup(func() { config.WhatsappWebhook = originalWebhooks })
+
+	if eventDeliveryEnabled() {
+		t.Fatal("expected event delivery to be disabled without webhooks or subscribers")
+	}
+	sub := websocket.Events.Subscribe("628111@s.whatsapp.net", []string{"message"}, 4)
+	if !eventDeliveryEnabled() {
+		t.Fatal("expected an event stream subscriber to enable event delivery")
+	}
+
+	payload := map[string]any{"event": "message", "device_id": "628111@s.whatsapp.net", "payload": map[string]any{"id": "3EB0A1"}}
+	if err := forwardPayloadToConfiguredWebhooks(context.Background(), payload, "message"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	websocket.Events.Unsubscribe(sub)
+
+	var frames []string
+	for frame := range sub.Frames() {
+		frames = append(frames, string(frame))
+	}
+	want := `{"device_id":"628111@s.whatsapp.net","event":"message","payload":{"id":"3EB0A1"}}`
+	if len(frames) != 1 || frames[0] != want {
+		t.Fatalf("expected frame %s, got %v", want, frames)
+	}
+}
@@ -67,8 +67,8 @@ func processHistorySync(ctx context.Context, data *waHistorySync.HistorySync, ch
 	log.Infof("Processing history sync type: %s", syncType.String())
 
 	switch syncType {
-	case waHistorySync.HistorySync_INITIAL_BOOTSTRAP, waHistorySync.HistorySync_RECENT:
-		// Process conversation messages
+	case waHistorySync.HistorySync_INITIAL_BOOTSTRAP, waHistorySync.HistorySync_RECENT, waHistorySync.HistorySync_ON_DEMAND:
+		// Process conversation messages; on-demand syncs answer RequestChatHistory
 		return processConversationMessages(ctx, data, chatStorageRepo, client)
 	case waHistorySync.HistorySync_PUSH_NAME:
 		// Process push names to update chat names
@@ -114,6 +114,10 @@ func processConversationMessages(ctx context.Context, data *waHistorySync.Histor
 		// Normalize JID (convert @lid to @s.whatsapp.net if possible)
 		jid = NormalizeJIDFromLID(ctx, jid, client)
 		chatJID := jid.String()
+		if data.GetSyncType() == waHistorySync.HistorySync_ON_DEMAND {
+			// Wake RequestChatHistory once every conversation is stored
+			defer onDemandHistory.stored(deviceID, chatJID)
+		}
 
 		displayName := conv.GetDisplayName()
 
@@ -217,6 +221,10 @@ func processConversationMessages(ctx context.Context, data *waHistorySync.Histor
 				FileSHA256:    fileSHA256,
 				FileEncSHA256: fileEncSHA256,
 				FileLength:    fileLength,
+				Location:      ExtractLocation(msg.GetMessage()),
+				Contacts:      ExtractContactCards(msg.GetMessage()),
+				IsAnimated:    utils.UnwrapMessage(msg.GetMessage()).GetStickerMessage().GetIsAnimated(),
+				IsViewOnce:    utils.IsViewOnceMessage(msg.GetMessage()),
 			}
 
 			messageBatch = append(messageBatch, message)
@@ -233,16 +241,16 @@ func processConversationMessages(ctx context.Context, data *waHistorySync.Histor
 			}
 
 			// Store or update the chat
-			if err := chatStorageRepo.StoreChat(chat); err != nil {
+			if err := chatStorageRepo.StoreChat(ctx, chat); err != nil {
 				log.Warnf("Failed to store chat %s: %v", chatJID, err)
 				continue
 			}
 
 			// Store messages in batch
-			if err := chatStorageRepo.StoreMessagesBatch(messageBatch); err != nil {
+			if stored, err := chatStorageRepo.StoreMessagesBatch(ctx, messageBatch); err != nil {
 				log.Warnf("Failed to store messages batch for chat %s: %v", chatJID, err)
 			} else {
-				log.Debugf("Stored %d messages for chat %s", len(messageBatch), chatJID)
+				log.Debugf("Stored %d messages for chat %s", stored, chatJID)
 			}
 		}
 	}
@@ -285,7 +293,7 @@ func processPushNames(ctx context.Context, data *waHistorySync.HistorySync, chat
 		jidStr := jid.String()
 
 		// Check if chat exists (device-scoped to avoid cross-device data leak)
-		existingChat, err := chatStorageRepo.GetChatByDevice(deviceID, jidStr)
+		existingChat, err := chatStorageRepo.GetChatByDevice(ctx, deviceID, jidStr)
 		if err != nil || existingChat == nil {
 			// Chat doesn't exist yet, skip
 			continue
@@ -294,7 +302,7 @@ func processPushNames(ctx context.Context, data *waHistorySync.HistorySync, chat
 		// Update chat name if it's different
 		if existingChat.Name != name {
 			existingChat.Name = name
-			if err := chatStorageRepo.StoreChat(existingChat); err != nil {
+			if err := chatStorageRepo.StoreChat(ctx, existingChat); err != nil {
 				log.Warnf("Failed to update chat name for %s: %v", jidStr, err)
 			} else {
 				log.Debugf("Updated chat name for %s to %s", jidStr, name)
@@ -0,0 +1,97 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// ErrHistoryNotRequested is wrapped by the errors of RequestChatHistory when
+// the phone wasn't asked for the history at all.
+var ErrHistoryNotRequested = errors.New("history not requested")
+
+// onDemandHistory wakes RequestChatHistory callers once the history sync
+// answering them has been stored.
+var onDemandHistory = &historyWaiters{waiters: make(map[string]map[chan struct{}]struct{})}
+
+type historyWaiters struct {
+	mu      sync.Mutex
+	waiters map[string]map[chan struct{}]struct{} // By device and chat
+}
+
+func historyWaiterKey(deviceID, chatJID string) string {
+	return deviceID + "|" + chatJID
+}
+
+// wait returns a channel closed once the next on-demand history sync of the
+// chat is stored, and a function to stop waiting.
+func (w *historyWaiters) wait(deviceID, chatJID string) (<-chan struct{}, func()) {
+	key := historyWaiterKey(deviceID, chatJID)
+	ch := make(chan struct{})
+
+	w.mu.Lock()
+	if w.waiters[key] == nil {
+		w.waiters[key] = make(map[chan struct{}]struct{})
+	}
+	w.waiters[key][ch] = struct{}{}
+	w.mu.Unlock()
+
+	return ch, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.waiters[key], ch)
+		if len(w.waiters[key]) == 0 {
+			delete(w.waiters, key)
+		}
+	}
+}
+
+// stored wakes everyone waiting for the chat's history.
+func (w *historyWaiters) stored(deviceID, chatJID string) {
+	key := historyWaiterKey(deviceID, chatJID)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for ch := range w.waiters[key] {
+		close(ch)
+	}
+	delete(w.waiters, key)
+}
+
+// RequestChatHistory asks the phone of deviceID for up to count messages of
+// the chat sent before oldest, and waits until the history sync that answers
+// has been stored or ctx ends. Answers arriving after ctx ended are still
+// stored by the history sync handler.
+func RequestChatHistory(ctx context.Context, client *whatsmeow.Client, deviceID string, oldest *domainChatStorage.Message, count int) error {
+	chat, err := types.ParseJID(oldest.ChatJID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid chat of message %s: %w", ErrHistoryNotRequested, oldest.ID, err)
+	}
+	info := &types.MessageInfo{
+		ID:        oldest.ID,
+		Timestamp: oldest.Timestamp,
+		MessageSource: types.MessageSource{
+			Chat:     chat,
+			IsFromMe: oldest.IsFromMe,
+			IsGroup:  chat.Server == types.GroupServer,
+		},
+	}
+
+	done, stop := onDemandHistory.wait(deviceID, oldest.ChatJID)
+	defer stop()
+	if _, err := client.SendPeerMessage(ctx, client.BuildHistorySyncRequest(info, count)); err != nil {
+		return fmt.Errorf("%w: %w", ErrHistoryNotRequested, err)
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
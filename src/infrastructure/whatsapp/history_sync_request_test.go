@@ -0,0 +1,74 @@
+package whatsapp
+
+import (
+	"context"
+	"testing"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"go.mau.fi/whatsmeow/proto/waCommon"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/proto/waHistorySync"
+	"go.mau.fi/whatsmeow/proto/waWeb"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+type historySyncRepo struct {
+	domainChatStorage.IChatStorageRepository
+	messages []*domainChatStorage.Message
+}
+
+func (r *historySyncRepo) GetChatNameWithPushName(_ types.JID, chatJID, _, _ string) string {
+	return chatJID
+}
+
+func (r *historySyncRepo) StoreChat(context.Context, *domainChatStorage.Chat) error {
+	return nil
+}
+
+func (r *historySyncRepo) StoreMessagesBatch(_ context.Context, messages []*domainChatStorage.Message) (int, error) {
+	r.messages = append(r.messages, messages...)
+	return len(messages), nil
+}
+
+func TestOnDemandHistorySyncWakesWaiters(t *testing.T) {
+	ctx := ContextWithDevice(context.Background(), NewDeviceInstance("dev-1", nil, nil))
+	chat := "628111@s.whatsapp.net"
+	repo := &historySyncRepo{}
+
+	requested, stopRequested := onDemandHistory.wait("dev-1", chat)
+	defer stopRequested()
+	other, stopOther := onDemandHistory.wait("dev-1", "628222@s.whatsapp.net")
+	defer stopOther()
+
+	err := processHistorySync(ctx, &waHistorySync.HistorySync{
+		SyncType: waHistorySync.HistorySync_ON_DEMAND.Enum(),
+		Conversations: []*waHistorySync.Conversation{{
+			ID: proto.String(chat),
+			Messages: []*waHistorySync.HistorySyncMsg{{
+				Message: &waWeb.WebMessageInfo{
+					Key:              &waCommon.MessageKey{ID: proto.String("3EB0OLD"), RemoteJID: proto.String(chat)},
+					Message:          &waE2E.Message{Conversation: proto.String("from last year")},
+					MessageTimestamp: proto.Uint64(1700000000),
+				},
+			}},
+		}},
+	}, repo, nil)
+	if err != nil {
+		t.Fatalf("processHistorySync() error = %v", err)
+	}
+
+	if len(repo.messages) != 1 || repo.messages[0].ID != "3EB0OLD" || repo.messages[0].DeviceID != "dev-1" {
+		t.Fatalf("expected the on-demand message to be stored for dev-1, got %+v", repo.messages)
+	}
+	select {
+	case <-requested:
+	default:
+		t.Fatal("expected the waiter of the synced chat to be woken")
+	}
+	select {
+	case <-other:
+		t.Fatal("expected waiters of other chats to keep waiting")
+	default:
+	}
+}
@@ -28,7 +28,7 @@ var (
 	db            *sqlstore.Container // Add global database reference for cleanup
 	keysDB        *sqlstore.Container
 	deviceManager *DeviceManager
-	log           waLog.Logger
+	log           = waLog.Noop // Replaced by InitWaDB
 	startupTime   = time.Now().Unix()
 )
 
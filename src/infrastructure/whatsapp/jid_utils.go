@@ -2,13 +2,23 @@ package whatsapp
 
 import (
 	"context"
+	"sync"
 
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/types"
 )
 
+// storedLIDMappings remembers which LID mappings were already written to chat
+// storage in this process, keyed by device ID and LID, so resolving the same
+// LID for every message doesn't write the same row over and over.
+var storedLIDMappings sync.Map
+
 // NormalizeJIDFromLID converts @lid JIDs to their corresponding @s.whatsapp.net JIDs
 // Returns the original JID if it's not an @lid or if LID lookup fails
+//
+// Successful lookups are persisted in the chat storage of the device in ctx,
+// which is consulted when the client cannot resolve the LID (e.g. right after a
+// restart), so a conversation isn't split between its @lid and phone number JIDs.
 func NormalizeJIDFromLID(ctx context.Context, jid types.JID, client *whatsmeow.Client) types.JID {
 	// Only process @lid JIDs
 	if jid.Server != "lid" {
@@ -18,22 +28,66 @@ func NormalizeJIDFromLID(ctx context.Context, jid types.JID, client *whatsmeow.C
 	// Safety check
 	if client == nil || client.Store == nil || client.Store.LIDs == nil {
 		log.Warnf("Cannot resolve LID %s: client not available", jid.String())
-		return jid
+		return storedPNForLID(ctx, jid)
 	}
 
 	// Attempt to get the phone number for this LID
 	pn, err := client.Store.LIDs.GetPNForLID(ctx, jid)
 	if err != nil {
 		log.Debugf("Failed to resolve LID %s to phone number: %v", jid.String(), err)
-		return jid
+		return storedPNForLID(ctx, jid)
 	}
 
 	// If we got a valid phone number, use it
 	if !pn.IsEmpty() {
 		log.Debugf("Resolved LID %s to phone number %s", jid.String(), pn.String())
+		storeLIDMapping(ctx, jid, pn)
 		return pn
 	}
 
-	// Fallback to original JID
-	return jid
+	// Fallback to the stored mapping, then the original JID
+	return storedPNForLID(ctx, jid)
+}
+
+// storeLIDMapping persists a resolved LID for the device in ctx.
+func storeLIDMapping(ctx context.Context, lid, pn types.JID) {
+	instance, ok := DeviceFromContext(ctx)
+	if !ok || instance == nil || instance.GetChatStorage() == nil {
+		return
+	}
+	lidKey := lid.ToNonAD().String()
+	pnJID := pn.ToNonAD().String()
+	cacheKey := instance.ID() + "|" + lidKey
+	if stored, ok := storedLIDMappings.Load(cacheKey); ok && stored == pnJID {
+		return
+	}
+	if err := instance.GetChatStorage().StoreLIDMapping(ctx, "", lidKey, pnJID); err != nil {
+		log.Warnf("Failed to store LID mapping %s -> %s: %v", lidKey, pnJID, err)
+		return
+	}
+	storedLIDMappings.Store(cacheKey, pnJID)
+}
+
+// storedPNForLID returns the phone number JID stored for lid by the device in
+// ctx, keeping the device part of lid, or lid itself when none is stored.
+func storedPNForLID(ctx context.Context, lid types.JID) types.JID {
+	instance, ok := DeviceFromContext(ctx)
+	if !ok || instance == nil || instance.GetChatStorage() == nil {
+		return lid
+	}
+	pnJID, err := instance.GetChatStorage().GetPNForLID(ctx, "", lid.ToNonAD().String())
+	if err != nil {
+		log.Debugf("Failed to look up stored mapping for LID %s: %v", lid.String(), err)
+		return lid
+	}
+	if pnJID == "" {
+		return lid
+	}
+	pn, err := types.ParseJID(pnJID)
+	if err != nil {
+		return lid
+	}
+	pn.Device = lid.Device
+	log.Debugf("Resolved LID %s to phone number %s from chat storage", lid.String(), pn.String())
+	return pn
 }
@@ -0,0 +1,31 @@
+package whatsapp
+
+import (
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+)
+
+// ExtractLocation returns the coordinates of a location or live location
+// message, or nil for other messages. Only live locations carry a sequence
+// number, which increases with every update of the shared position.
+func ExtractLocation(msg *waE2E.Message) *domainChatStorage.Location {
+	if live := msg.GetLiveLocationMessage(); live != nil {
+		return &domainChatStorage.Location{
+			Latitude:       live.GetDegreesLatitude(),
+			Longitude:      live.GetDegreesLongitude(),
+			Accuracy:       live.GetAccuracyInMeters(),
+			Name:           live.GetCaption(),
+			SequenceNumber: live.GetSequenceNumber(),
+		}
+	}
+	if location := msg.GetLocationMessage(); location != nil {
+		return &domainChatStorage.Location{
+			Latitude:  location.GetDegreesLatitude(),
+			Longitude: location.GetDegreesLongitude(),
+			Accuracy:  location.GetAccuracyInMeters(),
+			Name:      location.GetName(),
+			Address:   location.GetAddress(),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+)
+
+// MediaFilePath returns where the local copy of a message attachment is kept,
+// {PathMedia}/{device}/{chat}/{messageID}{ext}.
+func MediaFilePath(deviceID, chatJID, messageID, ext string) string {
+	return filepath.Join(config.PathMedia, mediaPathSegment(deviceID), mediaPathSegment(chatJID), mediaPathSegment(messageID)+ext)
+}
+
+// mediaPathSegment makes an ID safe to use as a single path element.
+func mediaPathSegment(id string) string {
+	segment := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune("@.+-_", r):
+			return r
+		}
+		return '_'
+	}, id)
+	if strings.Trim(segment, ".") == "" {
+		return "_"
+	}
+	return segment
+}
+
+// autoDownloadAllowed reports whether attachments of mediaType are downloaded
+// as soon as they arrive.
+func autoDownloadAllowed(mediaType string) bool {
+	if !config.ChatStorageAutoDownloadMedia || mediaType == "" {
+		return false
+	}
+	for _, allowed := range config.ChatStorageAutoDownloadTypes {
+		if strings.EqualFold(strings.TrimSpace(allowed), mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// mediaDownloadAllowed reports whether an incoming attachment is downloaded as
+// it arrives. View-once media is only captured in the explicit capture mode,
+// whatever the auto-download settings are.
+func mediaDownloadAllowed(mediaType string, viewOnce bool) bool {
+	if viewOnce {
+		return config.ChatStorageCaptureViewOnce && mediaType != ""
+	}
+	return autoDownloadAllowed(mediaType)
+}
+
+// handleMediaAutoDownload keeps a local copy of an incoming attachment of an
+// allowed type, before the URL stored with the message expires.
+func handleMediaAutoDownload(ctx context.Context, evt *events.Message, chatStorageRepo domainChatStorage.IChatStorageRepository, client *whatsmeow.Client) {
+	if client == nil || chatStorageRepo == nil {
+		return
+	}
+	msg := utils.UnwrapMessage(evt.Message)
+	mediaType, _, _, _, _, _, fileLength := utils.ExtractMediaInfo(msg)
+	if !mediaDownloadAllowed(mediaType, evt.IsViewOnce || utils.IsViewOnceMessage(evt.Message)) {
+		return
+	}
+	if int64(fileLength) > config.ChatStorageMediaMaxSize {
+		log.Debugf("Skipping download of %s attachment of message %s: %d bytes exceeds the limit", mediaType, evt.Info.ID, fileLength)
+		return
+	}
+
+	// The stored row knows the normalized chat and device the file belongs to
+	message, err := chatStorageRepo.GetMessageByDevice(ctx, "", evt.Info.ID)
+	if err != nil || message == nil || message.MediaPath != "" {
+		return
+	}
+
+	go func() {
+		downloadCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		var data []byte
+		var err error
+		if ptv := msg.GetPtvMessage(); ptv != nil {
+			data, err = client.Download(downloadCtx, ptv)
+		} else {
+			data, err = client.DownloadAny(downloadCtx, msg)
+		}
+		if err != nil {
+			log.Warnf("Failed to download attachment of message %s: %v", message.ID, err)
+			return
+		}
+		if _, err := saveMessageMedia(downloadCtx, chatStorageRepo, message, data); err != nil {
+			log.Warnf("Failed to store attachment of message %s: %v", message.ID, err)
+		}
+	}()
+}
+
+// DownloadStoredMedia downloads the attachment of a stored message using the
+// media keys kept with it, saves it under MediaFilePath and records the path.
+func DownloadStoredMedia(ctx context.Context, client *whatsmeow.Client, chatStorageRepo domainChatStorage.IChatStorageRepository, message *domainChatStorage.Message) (string, error) {
+	if int64(message.FileLength) > config.ChatStorageMediaMaxSize {
+		return "", fmt.Errorf("attachment of message %s exceeds the maximum size of %d bytes", message.ID, config.ChatStorageMediaMaxSize)
+	}
+	downloadable, err := StoredMediaDownloadable(message)
+	if err != nil {
+		return "", err
+	}
+	data, err := client.Download(ctx, downloadable)
+	if err != nil {
+		return "", fmt.Errorf("failed to download media: %w", err)
+	}
+	return saveMessageMedia(ctx, chatStorageRepo, message, data)
+}
+
+// StoredMediaDownloadable rebuilds a downloadable attachment from the media
+// fields of a stored message.
+func StoredMediaDownloadable(message *domainChatStorage.Message) (whatsmeow.DownloadableMessage, error) {
+	if message.MediaType == "" || message.URL == "" {
+		return nil, fmt.Errorf("message %s does not contain downloadable media", message.ID)
+	}
+
+	switch message.MediaType {
+	case "image":
+		return &waE2E.ImageMessage{
+			URL:           proto.String(message.URL),
+			MediaKey:      message.MediaKey,
+			FileSHA256:    message.FileSHA256,
+			FileEncSHA256: message.FileEncSHA256,
+			FileLength:    proto.Uint64(message.FileLength),
+		}, nil
+	case "video", "video_note":
+		return &waE2E.VideoMessage{
+			URL:           proto.String(message.URL),
+			MediaKey:      message.MediaKey,
+			FileSHA256:    message.FileSHA256,
+			FileEncSHA256: message.FileEncSHA256,
+			FileLength:    proto.Uint64(message.FileLength),
+		}, nil
+	case "audio":
+		return &waE2E.AudioMessage{
+			URL:           proto.String(message.URL),
+			MediaKey:      message.MediaKey,
+			FileSHA256:    message.FileSHA256,
+			FileEncSHA256: message.FileEncSHA256,
+			FileLength:    proto.Uint64(message.FileLength),
+		}, nil
+	case "document":
+		return &waE2E.DocumentMessage{
+			URL:           proto.String(message.URL),
+			MediaKey:      message.MediaKey,
+			FileSHA256:    message.FileSHA256,
+			FileEncSHA256: message.FileEncSHA256,
+			FileLength:    proto.Uint64(message.FileLength),
+			FileName:      proto.String(message.Filename),
+		}, nil
+	case "sticker":
+		return &waE2E.StickerMessage{
+			URL:           proto.String(message.URL),
+			MediaKey:      message.MediaKey,
+			FileSHA256:    message.FileSHA256,
+			FileEncSHA256: message.FileEncSHA256,
+			FileLength:    proto.Uint64(message.FileLength),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported media type: %s", message.MediaType)
+	}
+}
+
+// saveMessageMedia writes data as the local copy of the attachment of message
+// and records its path and size in chat storage.
+func saveMessageMedia(ctx context.Context, chatStorageRepo domainChatStorage.IChatStorageRepository, message *domainChatStorage.Message, data []byte) (string, error) {
+	if int64(len(data)) > config.ChatStorageMediaMaxSize {
+		return "", fmt.Errorf("attachment of message %s exceeds the maximum size of %d bytes", message.ID, config.ChatStorageMediaMaxSize)
+	}
+
+	path := MediaFilePath(message.DeviceID, message.ChatJID, message.ID, utils.MediaFileExtension(message.MediaType, message.Filename, ""))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write media file: %w", err)
+	}
+
+	downloadedAt := time.Now()
+	if err := chatStorageRepo.SetMessageMediaPath(ctx, message.DeviceID, message.ID, message.ChatJID, path, int64(len(data)), downloadedAt); err != nil {
+		return "", err
+	}
+	message.MediaPath = path
+	message.DownloadedAt = &downloadedAt
+	message.FileLength = uint64(len(data))
+	return path, nil
+}
@@ -0,0 +1,94 @@
+package whatsapp
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+)
+
+func TestMediaFilePath(t *testing.T) {
+	original := config.PathMedia
+	config.PathMedia = "statics/media"
+	t.Cleanup(func() { config.PathMedia = original })
+
+	tests := []struct {
+		name      string
+		deviceID  string
+		chatJID   string
+		messageID string
+		ext       string
+		want      string
+	}{
+		{
+			name:      "Group",
+			deviceID:  "628111:12@s.whatsapp.net",
+			chatJID:   "120363@g.us",
+			messageID: "3EB0ABC",
+			ext:       ".jpg",
+			want:      filepath.Join("statics/media", "628111_12@s.whatsapp.net", "120363@g.us", "3EB0ABC.jpg"),
+		},
+		{
+			name:      "IDsNeverEscapeTheirDirectory",
+			deviceID:  "..",
+			chatJID:   "a/b",
+			messageID: "",
+			ext:       ".ogg",
+			want:      filepath.Join("statics/media", "_", "a_b", "_.ogg"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MediaFilePath(tt.deviceID, tt.chatJID, tt.messageID, tt.ext); got != tt.want {
+				t.Fatalf("MediaFilePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoDownloadAllowed(t *testing.T) {
+	originalEnabled, originalTypes := config.ChatStorageAutoDownloadMedia, config.ChatStorageAutoDownloadTypes
+	t.Cleanup(func() {
+		config.ChatStorageAutoDownloadMedia, config.ChatStorageAutoDownloadTypes = originalEnabled, originalTypes
+	})
+
+	config.ChatStorageAutoDownloadTypes = []string{"image", " Document"}
+	config.ChatStorageAutoDownloadMedia = false
+	if autoDownloadAllowed("image") {
+		t.Fatal("expected no downloads while auto-download is disabled")
+	}
+
+	config.ChatStorageAutoDownloadMedia = true
+	for mediaType, want := range map[string]bool{"image": true, "document": true, "video": false, "": false} {
+		if got := autoDownloadAllowed(mediaType); got != want {
+			t.Errorf("autoDownloadAllowed(%q) = %v, want %v", mediaType, got, want)
+		}
+	}
+}
+
+func TestMediaDownloadAllowed_ViewOnce(t *testing.T) {
+	originalEnabled, originalTypes, originalCapture := config.ChatStorageAutoDownloadMedia, config.ChatStorageAutoDownloadTypes, config.ChatStorageCaptureViewOnce
+	t.Cleanup(func() {
+		config.ChatStorageAutoDownloadMedia, config.ChatStorageAutoDownloadTypes, config.ChatStorageCaptureViewOnce = originalEnabled, originalTypes, originalCapture
+	})
+
+	config.ChatStorageAutoDownloadMedia = true
+	config.ChatStorageAutoDownloadTypes = []string{"image"}
+	config.ChatStorageCaptureViewOnce = false
+	if mediaDownloadAllowed("image", true) {
+		t.Fatal("expected view-once media to be skipped outside capture mode")
+	}
+	if !mediaDownloadAllowed("image", false) {
+		t.Fatal("expected regular images to follow the auto-download settings")
+	}
+
+	config.ChatStorageAutoDownloadMedia = false
+	config.ChatStorageCaptureViewOnce = true
+	if !mediaDownloadAllowed("video", true) {
+		t.Fatal("expected view-once media to be captured in capture mode")
+	}
+	if mediaDownloadAllowed("video", false) {
+		t.Fatal("expected capture mode to leave regular media alone")
+	}
+}
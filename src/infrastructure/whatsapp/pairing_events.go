@@ -0,0 +1,82 @@
+package whatsapp
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Pairing events streamed by GET /app/events.
+const (
+	PairingEventQR           = "qr"
+	PairingEventPaired       = "paired"
+	PairingEventPairFailed   = "pair_failed"
+	PairingEventConnected    = "connected"
+	PairingEventDisconnected = "disconnected"
+	PairingEventLoggedOut    = "logged_out"
+)
+
+const pairingEventBufferSize = 16
+
+// PairingEvents fans out QR codes and connection changes to the clients
+// watching a device pair.
+var PairingEvents = newPairingEventHub()
+
+// PairingEvent is a QR rotation or connection change of a device. Data always
+// carries the device_id.
+type PairingEvent struct {
+	Name string
+	Data map[string]any
+}
+
+type pairingEventHub struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan PairingEvent]struct{}
+}
+
+func newPairingEventHub() *pairingEventHub {
+	return &pairingEventHub{subscribers: make(map[string]map[chan PairingEvent]struct{})}
+}
+
+// Subscribe returns the events of deviceID and a function that stops the
+// subscription. The channel is never closed; callers stop reading once they
+// unsubscribe.
+func (h *pairingEventHub) Subscribe(deviceID string) (<-chan PairingEvent, func()) {
+	ch := make(chan PairingEvent, pairingEventBufferSize)
+
+	h.mu.Lock()
+	if h.subscribers[deviceID] == nil {
+		h.subscribers[deviceID] = make(map[chan PairingEvent]struct{})
+	}
+	h.subscribers[deviceID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subscribers[deviceID], ch)
+		if len(h.subscribers[deviceID]) == 0 {
+			delete(h.subscribers, deviceID)
+		}
+	}
+}
+
+// Publish sends an event to the subscribers of deviceID. Subscribers that
+// aren't keeping up miss the event rather than blocking the caller.
+func (h *pairingEventHub) Publish(deviceID, name string, data map[string]any) {
+	payload := map[string]any{"device_id": deviceID}
+	for key, value := range data {
+		payload[key] = value
+	}
+	evt := PairingEvent{Name: name, Data: payload}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers[deviceID] {
+		select {
+		case ch <- evt:
+		default:
+			logrus.Debugf("[PAIRING_EVENTS] Dropped %s event for slow subscriber of device %s", name, deviceID)
+		}
+	}
+}
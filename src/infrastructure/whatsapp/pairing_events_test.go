@@ -0,0 +1,50 @@
+package whatsapp
+
+import "testing"
+
+func TestPairingEventsScopedByDevice(t *testing.T) {
+	hub := newPairingEventHub()
+	first, unsubscribeFirst := hub.Subscribe("device-a")
+	second, unsubscribeSecond := hub.Subscribe("device-b")
+	defer unsubscribeSecond()
+
+	hub.Publish("device-a", PairingEventQR, map[string]any{"code": "2@abc"})
+
+	select {
+	case evt := <-first:
+		if evt.Name != PairingEventQR || evt.Data["code"] != "2@abc" || evt.Data["device_id"] != "device-a" {
+			t.Fatalf("unexpected event %+v", evt)
+		}
+	default:
+		t.Fatal("expected device-a subscriber to receive the event")
+	}
+	select {
+	case evt := <-second:
+		t.Fatalf("expected device-b subscriber to receive nothing, got %+v", evt)
+	default:
+	}
+
+	unsubscribeFirst()
+	hub.Publish("device-a", PairingEventConnected, nil)
+	select {
+	case evt := <-first:
+		t.Fatalf("expected no event after unsubscribe, got %+v", evt)
+	default:
+	}
+	if _, ok := hub.subscribers["device-a"]; ok {
+		t.Fatal("expected device-a to be removed once its last subscriber left")
+	}
+}
+
+func TestPairingEventsDropsForSlowSubscriber(t *testing.T) {
+	hub := newPairingEventHub()
+	events, unsubscribe := hub.Subscribe("device-a")
+	defer unsubscribe()
+
+	for i := 0; i < pairingEventBufferSize+5; i++ {
+		hub.Publish("device-a", PairingEventQR, nil)
+	}
+	if len(events) != pairingEventBufferSize {
+		t.Fatalf("expected %d buffered events, got %d", pairingEventBufferSize, len(events))
+	}
+}
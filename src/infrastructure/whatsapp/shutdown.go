@@ -0,0 +1,61 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+)
+
+var (
+	shuttingDown atomic.Bool
+	// webhookDeliveries counts webhook and Chatwoot deliveries in progress
+	webhookDeliveries utils.InFlight
+)
+
+// BeginShutdown marks the service as shutting down: GET /health reports it
+// unhealthy so load balancers stop routing to it, and clients are no longer
+// reconnected.
+func BeginShutdown() {
+	shuttingDown.Store(true)
+}
+
+// IsShuttingDown reports whether BeginShutdown was called.
+func IsShuttingDown() bool {
+	return shuttingDown.Load()
+}
+
+// WaitForWebhooks returns once the webhook deliveries in progress are done,
+// or with the error of ctx if it ends first.
+func WaitForWebhooks(ctx context.Context) error {
+	return webhookDeliveries.Wait(ctx)
+}
+
+// DisconnectClients disconnects the client of every device from WhatsApp,
+// keeping their sessions.
+func DisconnectClients() {
+	if dm := GetDeviceManager(); dm != nil {
+		for _, instance := range dm.ListDevices() {
+			if client := instance.GetClient(); client != nil {
+				client.Disconnect()
+			}
+		}
+	}
+	if client := GetClient(); client != nil {
+		client.Disconnect()
+	}
+}
+
+// CloseStores closes the databases of the whatsmeow session and key stores.
+func CloseStores() error {
+	storeDB, keysStoreDB := getStoreContainers()
+	var errs []error
+	if storeDB != nil {
+		errs = append(errs, storeDB.Close())
+	}
+	if keysStoreDB != nil {
+		errs = append(errs, keysStoreDB.Close())
+	}
+	return errors.Join(errs...)
+}
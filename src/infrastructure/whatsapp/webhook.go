@@ -8,15 +8,20 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
-func submitWebhook(ctx context.Context, payload map[string]any, url string) error {
+// defaultWebhookSecret is the secret used when none is configured.
+const defaultWebhookSecret = "secret"
+
+func submitWebhook(ctx context.Context, payload map[string]any, url, secret string) error {
 	// Configure HTTP client with optional TLS skip verification
 	transport := &http.Transport{
 		TLSClientConfig: &tls.Config{
@@ -38,20 +43,24 @@ func submitWebhook(ctx context.Context, payload map[string]any, url string) erro
 		return pkgError.WebhookError(fmt.Sprintf("error when create http object %v", err))
 	}
 
-	secretKey := []byte(config.WhatsappWebhookSecret)
-	signature, err := utils.GetMessageDigestOrSignature(postBody, secretKey)
-	if err != nil {
-		return pkgError.WebhookError(fmt.Sprintf("error when create signature %v", err))
-	}
-
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Hub-Signature-256", fmt.Sprintf("sha256=%s", signature))
+	// The delivery ID stays the same across retries so consumers can deduplicate
+	req.Header.Set("X-Webhook-Id", uuid.NewString())
 
 	var attempt int
 	var maxAttempts = 5
 	var sleepDuration = 1 * time.Second
 
 	for attempt = 0; attempt < maxAttempts; attempt++ {
+		// Sign each attempt with a fresh timestamp so retries stay within the receiver's replay window
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		signature, err := utils.SignWebhookPayload(secret, timestamp, postBody)
+		if err != nil {
+			return pkgError.WebhookError(fmt.Sprintf("error when create signature %v", err))
+		}
+		req.Header.Set("X-Webhook-Timestamp", timestamp)
+		req.Header.Set("X-Hub-Signature-256", fmt.Sprintf("sha256=%s", signature))
+
 		// Create new request body for each attempt
 		req.Body = io.NopCloser(bytes.NewBuffer(postBody))
 		resp, err := client.Do(req)
@@ -72,3 +81,15 @@ func submitWebhook(ctx context.Context, payload map[string]any, url string) erro
 
 	return pkgError.WebhookError(fmt.Sprintf("error when submit webhook after %d attempts: %v", attempt, err))
 }
+
+// CheckWebhookSecret refuses webhook deliveries signed with no secret or the
+// built-in default one when strict mode is enabled.
+func CheckWebhookSecret() error {
+	if !config.WhatsappWebhookStrict || len(config.WhatsappWebhook) == 0 {
+		return nil
+	}
+	if config.WhatsappWebhookSecret == "" || config.WhatsappWebhookSecret == defaultWebhookSecret {
+		return fmt.Errorf("webhook strict mode requires WHATSAPP_WEBHOOK_SECRET to be set to a non-default value")
+	}
+	return nil
+}
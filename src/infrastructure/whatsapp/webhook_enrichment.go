@@ -0,0 +1,161 @@
+package whatsapp
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+const (
+	webhookChatCacheSize = 1024
+	webhookChatCacheTTL  = time.Minute
+)
+
+// webhookChats caches the stored chats looked up while enriching webhook
+// payloads, so a busy chat costs one repository read per TTL.
+var webhookChats = newChatLRU(webhookChatCacheSize, webhookChatCacheTTL)
+
+type chatLRUEntry struct {
+	key       string
+	chat      domainChatStorage.Chat
+	expiresAt time.Time
+}
+
+// chatLRU is a size-bounded cache of chats that evicts the least recently
+// used entry once full. Entries also expire after ttl so renames and
+// membership changes show up.
+type chatLRU struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func newChatLRU(size int, ttl time.Duration) *chatLRU {
+	return &chatLRU{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *chatLRU) get(key string) (domainChatStorage.Chat, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return domainChatStorage.Chat{}, false
+	}
+	entry := elem.Value.(*chatLRUEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return domainChatStorage.Chat{}, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.chat, true
+}
+
+func (c *chatLRU) set(key string, chat domainChatStorage.Chat) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(c.ttl)
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*chatLRUEntry)
+		entry.chat, entry.expiresAt = chat, expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&chatLRUEntry{key: key, chat: chat, expiresAt: expiresAt})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*chatLRUEntry).key)
+	}
+}
+
+// enrichMessagePayload adds the stored context of the chat a message belongs
+// to: chat_name, is_group, participant_count for groups and quoted_message for
+// replies. deviceID only scopes the cache; chatStorageRepo is expected to be
+// scoped to the device already.
+func enrichMessagePayload(ctx context.Context, chatStorageRepo domainChatStorage.IChatStorageRepository, deviceID string, evt *events.Message, payload map[string]any) {
+	if !config.WhatsappWebhookEnrichPayload {
+		return
+	}
+
+	chatJID, _ := payload["chat_id"].(string)
+	isGroup := utils.IsGroupJID(chatJID)
+	payload["is_group"] = isGroup
+
+	if chat, ok := lookupWebhookChat(ctx, chatStorageRepo, deviceID, chatJID); ok {
+		if chat.Name != "" {
+			payload["chat_name"] = chat.Name
+		}
+		if isGroup {
+			payload["participant_count"] = chat.ParticipantCount
+		}
+	}
+
+	if quoted := quotedMessagePayload(evt); quoted != nil {
+		payload["quoted_message"] = quoted
+	}
+}
+
+func lookupWebhookChat(ctx context.Context, chatStorageRepo domainChatStorage.IChatStorageRepository, deviceID, chatJID string) (domainChatStorage.Chat, bool) {
+	if chatStorageRepo == nil || chatJID == "" {
+		return domainChatStorage.Chat{}, false
+	}
+	key := deviceID + "|" + chatJID
+	if chat, ok := webhookChats.get(key); ok {
+		return chat, true
+	}
+
+	chat, err := chatStorageRepo.GetChatByDevice(ctx, "", chatJID)
+	if err != nil {
+		logrus.Debugf("Failed to load chat %s for webhook enrichment: %v", chatJID, err)
+		return domainChatStorage.Chat{}, false
+	}
+	if chat == nil {
+		return domainChatStorage.Chat{}, false
+	}
+	webhookChats.set(key, *chat)
+	return *chat, true
+}
+
+// quotedMessagePayload describes the message a reply quotes, or returns nil
+// when the event is not a reply.
+func quotedMessagePayload(evt *events.Message) map[string]any {
+	msg := utils.UnwrapMessage(evt.Message)
+	if protocolMessage := msg.GetProtocolMessage(); protocolMessage != nil && protocolMessage.GetEditedMessage() != nil {
+		msg = protocolMessage.GetEditedMessage()
+	}
+	contextInfo := utils.ExtractContextInfo(msg)
+	if contextInfo.GetStanzaID() == "" {
+		return nil
+	}
+
+	quoted := map[string]any{"id": contextInfo.GetStanzaID()}
+	if participant := contextInfo.GetParticipant(); participant != "" {
+		quoted["sender"] = participant
+	}
+	if quotedMessage := contextInfo.GetQuotedMessage(); quotedMessage != nil {
+		if body := utils.ExtractMessageTextFromProto(quotedMessage); body != "" {
+			quoted["body"] = body
+		}
+		if mediaType, _, _, _, _, _, _ := utils.ExtractMediaInfo(quotedMessage); mediaType != "" {
+			quoted["media_type"] = mediaType
+		}
+	}
+	return quoted
+}
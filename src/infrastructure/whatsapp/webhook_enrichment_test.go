@@ -0,0 +1,77 @@
+package whatsapp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+func TestChatLRU(t *testing.T) {
+	cache := newChatLRU(2, time.Minute)
+	cache.set("a", domainChatStorage.Chat{Name: "A"})
+	cache.set("b", domainChatStorage.Chat{Name: "B"})
+	if _, ok := cache.get("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+
+	// b is now the least recently used entry and makes room for c
+	cache.set("c", domainChatStorage.Chat{Name: "C"})
+	if _, ok := cache.get("b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	if chat, ok := cache.get("a"); !ok || chat.Name != "A" {
+		t.Fatalf("expected a to survive the eviction, got %+v", chat)
+	}
+
+	expired := newChatLRU(2, -time.Second)
+	expired.set("a", domainChatStorage.Chat{Name: "A"})
+	if _, ok := expired.get("a"); ok {
+		t.Fatal("expected expired entries to be dropped")
+	}
+}
+
+func TestEnrichMessagePayload(t *testing.T) {
+	original := config.WhatsappWebhookEnrichPayload
+	t.Cleanup(func() { config.WhatsappWebhookEnrichPayload = original })
+
+	evt := &events.Message{
+		Info: types.MessageInfo{ID: "MSG2"},
+		Message: &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+			Text: protoString("agreed"),
+			ContextInfo: &waE2E.ContextInfo{
+				StanzaID:    protoString("MSG1"),
+				Participant: protoString("628123@s.whatsapp.net"),
+				QuotedMessage: &waE2E.Message{ImageMessage: &waE2E.ImageMessage{
+					Caption: protoString("new logo"),
+				}},
+			},
+		}},
+	}
+
+	config.WhatsappWebhookEnrichPayload = true
+	payload := map[string]any{"chat_id": "120363@g.us"}
+	enrichMessagePayload(context.Background(), nil, "dev-1", evt, payload)
+	if payload["is_group"] != true {
+		t.Fatalf("expected is_group for a group chat, got %v", payload["is_group"])
+	}
+	quoted, ok := payload["quoted_message"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected quoted_message for a reply, got %v", payload["quoted_message"])
+	}
+	if quoted["id"] != "MSG1" || quoted["sender"] != "628123@s.whatsapp.net" || quoted["body"] != "new logo" || quoted["media_type"] != "image" {
+		t.Fatalf("unexpected quoted_message %v", quoted)
+	}
+
+	config.WhatsappWebhookEnrichPayload = false
+	payload = map[string]any{"chat_id": "120363@g.us"}
+	enrichMessagePayload(context.Background(), nil, "dev-1", evt, payload)
+	if len(payload) != 1 {
+		t.Fatalf("expected no enrichment when disabled, got %v", payload)
+	}
+}
@@ -0,0 +1,247 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/webhooks"
+	waBinary "go.mau.fi/whatsmeow/binary"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// decodeWebhookBody round-trips body through JSON the way a consumer of the
+// webhooks package would read it.
+func decodeWebhookBody[T any](t *testing.T, body map[string]any) webhooks.Event[T] {
+	t.Helper()
+	raw, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("marshal body: %v", err)
+	}
+	var evt webhooks.Event[T]
+	if err := json.Unmarshal(raw, &evt); err != nil {
+		t.Fatalf("unmarshal %s into %T: %v", raw, evt, err)
+	}
+	return evt
+}
+
+func TestCreatePresencePayload(t *testing.T) {
+	ctx := context.Background()
+	from := types.NewJID("628123", types.DefaultUserServer)
+	lastSeen := time.Date(2026, time.March, 1, 9, 30, 0, 0, time.UTC)
+
+	online := decodeWebhookBody[webhooks.PresencePayload](t, createPresencePayload(ctx, &events.Presence{From: from}, "dev-1", nil))
+	if online.Event != webhooks.EventPresence || online.DeviceID != "dev-1" {
+		t.Fatalf("unexpected envelope: %+v", online)
+	}
+	if !online.Payload.Available || online.Payload.LastSeen != nil || online.Payload.From != "628123@s.whatsapp.net" {
+		t.Fatalf("unexpected online payload: %+v", online.Payload)
+	}
+
+	offline := decodeWebhookBody[webhooks.PresencePayload](t, createPresencePayload(ctx, &events.Presence{From: from, Unavailable: true, LastSeen: lastSeen}, "", nil))
+	if offline.DeviceID != "" || offline.Payload.Available {
+		t.Fatalf("unexpected offline event: %+v", offline)
+	}
+	if offline.Payload.LastSeen == nil || !offline.Payload.LastSeen.Equal(lastSeen) {
+		t.Fatalf("expected last seen %s, got %v", lastSeen, offline.Payload.LastSeen)
+	}
+}
+
+func TestCreateChatPresencePayload(t *testing.T) {
+	source := types.MessageSource{
+		Chat:   types.NewJID("120363024512399999", types.GroupServer),
+		Sender: types.NewJID("628123", types.DefaultUserServer),
+	}
+	tests := []struct {
+		name      string
+		state     types.ChatPresence
+		media     types.ChatPresenceMedia
+		wantMedia string
+	}{
+		{name: "typing", state: types.ChatPresenceComposing, media: types.ChatPresenceMediaText},
+		{name: "recording", state: types.ChatPresenceComposing, media: types.ChatPresenceMediaAudio, wantMedia: "audio"},
+		{name: "paused", state: types.ChatPresencePaused, media: types.ChatPresenceMediaAudio},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := createChatPresencePayload(context.Background(), &events.ChatPresence{MessageSource: source, State: tt.state, Media: tt.media}, "dev-1", nil)
+			evt := decodeWebhookBody[webhooks.ChatPresencePayload](t, body)
+			if evt.Event != webhooks.EventChatPresence {
+				t.Fatalf("expected event %s, got %s", webhooks.EventChatPresence, evt.Event)
+			}
+			want := webhooks.ChatPresencePayload{ChatID: "120363024512399999@g.us", From: "628123@s.whatsapp.net", State: string(tt.state), Media: tt.wantMedia}
+			if evt.Payload != want {
+				t.Fatalf("expected %+v, got %+v", want, evt.Payload)
+			}
+		})
+	}
+}
+
+func TestCreateGroupUpdatedPayload(t *testing.T) {
+	ctx := context.Background()
+	groupJID := types.NewJID("120363024512399999", types.GroupServer)
+	sender := types.NewJID("628123", types.DefaultUserServer)
+	changedAt := time.Date(2026, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	if body := createGroupUpdatedPayload(ctx, &events.GroupInfo{JID: groupJID, Join: []types.JID{sender}}, "dev-1", nil); body != nil {
+		t.Fatalf("expected no group.updated payload for member changes, got %v", body)
+	}
+
+	evt := &events.GroupInfo{
+		JID:       groupJID,
+		Sender:    &sender,
+		Timestamp: changedAt,
+		Name:      &types.GroupName{Name: "Weekend plans"},
+		Announce:  &types.GroupAnnounce{IsAnnounce: true},
+	}
+	updated := decodeWebhookBody[webhooks.GroupUpdatedPayload](t, createGroupUpdatedPayload(ctx, evt, "dev-1", nil))
+	if updated.Event != webhooks.EventGroupUpdated || !updated.Timestamp.Equal(changedAt) {
+		t.Fatalf("unexpected envelope: %+v", updated)
+	}
+	payload := updated.Payload
+	if payload.ChatID != "120363024512399999@g.us" || payload.Sender != "628123@s.whatsapp.net" {
+		t.Fatalf("unexpected chat or sender: %+v", payload)
+	}
+	if payload.Name == nil || *payload.Name != "Weekend plans" || payload.Announce == nil || !*payload.Announce {
+		t.Fatalf("expected name and announce changes, got %+v", payload)
+	}
+	if payload.Topic != nil || payload.Locked != nil {
+		t.Fatalf("expected unchanged settings to be omitted, got %+v", payload)
+	}
+}
+
+func TestCreateGroupUpdatedPayloadSettings(t *testing.T) {
+	ctx := context.Background()
+	groupJID := types.NewJID("120363024512399999", types.GroupServer)
+
+	evt := &events.GroupInfo{
+		JID:            groupJID,
+		Ephemeral:      &types.GroupEphemeral{IsEphemeral: false},
+		UnknownChanges: []*waBinary.Node{{Tag: "member_add_mode", Content: []byte("admin_add")}},
+	}
+	payload := decodeWebhookBody[webhooks.GroupUpdatedPayload](t, createGroupUpdatedPayload(ctx, evt, "dev-1", nil)).Payload
+	if payload.MemberAddMode == nil || *payload.MemberAddMode != "admin_add" {
+		t.Fatalf("expected member add mode admin_add, got %+v", payload)
+	}
+	if payload.DisappearingTimer == nil || *payload.DisappearingTimer != 0 {
+		t.Fatalf("expected the disappearing timer to be turned off, got %+v", payload)
+	}
+
+	author := types.NewJID("628123", types.DefaultUserServer)
+	changedAt := time.Date(2026, time.March, 1, 10, 0, 0, 0, time.UTC)
+	photo := decodeWebhookBody[webhooks.GroupUpdatedPayload](t, createGroupPicturePayload(ctx, &events.Picture{JID: groupJID, Author: author, Timestamp: changedAt, PictureID: "1712345678"}, "dev-1", nil))
+	if photo.Event != webhooks.EventGroupUpdated || photo.Payload.PictureID == nil || *photo.Payload.PictureID != "1712345678" || photo.Payload.Sender != "628123@s.whatsapp.net" {
+		t.Fatalf("unexpected photo event: %+v", photo)
+	}
+	removed := decodeWebhookBody[webhooks.GroupUpdatedPayload](t, createGroupPicturePayload(ctx, &events.Picture{JID: groupJID, Remove: true}, "dev-1", nil)).Payload
+	if removed.PictureID == nil || *removed.PictureID != "" {
+		t.Fatalf("expected an empty picture id for a removed photo, got %+v", removed)
+	}
+}
+
+func TestCreateGroupJoinRequestPayloads(t *testing.T) {
+	ctx := context.Background()
+	groupJID := types.NewJID("120363024512399999", types.GroupServer)
+	requester := types.NewJID("628123", types.DefaultUserServer)
+	admin := types.NewJID("628999", types.DefaultUserServer)
+	requested := &waBinary.Node{
+		Tag:     "created_membership_requests",
+		Attrs:   waBinary.Attrs{"request_method": "invite_link"},
+		Content: []waBinary.Node{{Tag: "participant", Attrs: waBinary.Attrs{"jid": types.NewJID("11111", types.HiddenUserServer), "phone_number": requester}}},
+	}
+	revoked := &waBinary.Node{
+		Tag:     "revoked_membership_requests",
+		Content: []waBinary.Node{{Tag: "participant", Attrs: waBinary.Attrs{"jid": requester}}},
+	}
+
+	if bodies := createGroupJoinRequestPayloads(ctx, &events.GroupInfo{JID: groupJID, Sender: &requester}, "dev-1", nil); len(bodies) != 0 {
+		t.Fatalf("expected no join request events, got %v", bodies)
+	}
+
+	bodies := createGroupJoinRequestPayloads(ctx, &events.GroupInfo{JID: groupJID, Sender: &requester, UnknownChanges: []*waBinary.Node{requested, revoked}}, "dev-1", nil)
+	if len(bodies) != 2 {
+		t.Fatalf("expected two join request events, got %d", len(bodies))
+	}
+	created := decodeWebhookBody[webhooks.GroupJoinRequestPayload](t, bodies[0])
+	if created.Event != webhooks.EventGroupJoinRequest || created.Payload.Action != "requested" || created.Payload.Method != "invite_link" {
+		t.Fatalf("unexpected join request event: %+v", created)
+	}
+	if len(created.Payload.JIDs) != 1 || created.Payload.JIDs[0] != "628123@s.whatsapp.net" {
+		t.Fatalf("expected the requester's phone number, got %v", created.Payload.JIDs)
+	}
+	if cancelled := decodeWebhookBody[webhooks.GroupJoinRequestPayload](t, bodies[1]).Payload; cancelled.Action != "cancelled" {
+		t.Fatalf("expected the requester to have cancelled, got %+v", cancelled)
+	}
+
+	bodies = createGroupJoinRequestPayloads(ctx, &events.GroupInfo{JID: groupJID, Sender: &admin, UnknownChanges: []*waBinary.Node{revoked}}, "dev-1", nil)
+	if rejected := decodeWebhookBody[webhooks.GroupJoinRequestPayload](t, bodies[0]).Payload; rejected.Action != "rejected" {
+		t.Fatalf("expected an admin to have rejected, got %+v", rejected)
+	}
+}
+
+func TestCreateDeviceStatusPayload(t *testing.T) {
+	if reason := loggedOutReason(&events.LoggedOut{Reason: events.ConnectFailureLoggedOut}); reason != "" {
+		t.Fatalf("expected no reason for a stream error logout, got %q", reason)
+	}
+	reason := loggedOutReason(&events.LoggedOut{OnConnect: true, Reason: events.ConnectFailureLoggedOut})
+	if reason == "" {
+		t.Fatal("expected a reason for a logout on connect")
+	}
+
+	evt := decodeWebhookBody[webhooks.DeviceStatusPayload](t, createDeviceStatusPayload(webhooks.EventDeviceLoggedOut, "628123@s.whatsapp.net", reason))
+	if evt.Event != webhooks.EventDeviceLoggedOut || evt.DeviceID != "628123@s.whatsapp.net" || evt.Payload.Reason != reason {
+		t.Fatalf("unexpected logged out event: %+v", evt)
+	}
+}
+
+func TestHandlePairErrorPublishesPairingEvent(t *testing.T) {
+	instance := NewDeviceInstance("dev-pair", nil, nil)
+	pairing, unsubscribe := PairingEvents.Subscribe(instance.ID())
+	defer unsubscribe()
+
+	handlePairError(context.Background(), instance, &events.PairError{
+		ID:       types.NewADJID("628123", 0, 12),
+		LID:      types.NewJID("1234567", types.HiddenUserServer),
+		Platform: "android",
+		Error:    errors.New("failed to verify device identity"),
+	})
+
+	select {
+	case evt := <-pairing:
+		if evt.Name != PairingEventPairFailed || evt.Data["jid"] != "628123@s.whatsapp.net" || evt.Data["error"] != "failed to verify device identity" {
+			t.Fatalf("unexpected pairing event: %+v", evt)
+		}
+	default:
+		t.Fatal("expected a pair_failed event")
+	}
+
+	payload := createDevicePairingPayload(types.NewADJID("628123", 0, 12), types.NewJID("1234567", types.HiddenUserServer), "", "android")
+	if payload.JID != "628123@s.whatsapp.net" || payload.LID != "1234567@lid" || payload.Platform != "android" {
+		t.Fatalf("unexpected pairing payload: %+v", payload)
+	}
+}
+
+func TestExistingPayloadsMatchWebhookStructs(t *testing.T) {
+	ctx := context.Background()
+	chat := types.NewJID("628123", types.DefaultUserServer)
+	receipt := &events.Receipt{
+		MessageSource: types.MessageSource{Chat: chat, Sender: chat},
+		MessageIDs:    []types.MessageID{"3EB0A1", "3EB0A2"},
+		Type:          types.ReceiptTypeRead,
+		Timestamp:     time.Date(2026, time.March, 1, 11, 0, 0, 0, time.UTC),
+	}
+	ack := decodeWebhookBody[webhooks.ReceiptPayload](t, createReceiptPayload(ctx, receipt, "dev-1", nil))
+	if ack.Event != webhooks.EventMessageAck || len(ack.Payload.IDs) != 2 || ack.Payload.ReceiptType != "read" || ack.Payload.From != "628123@s.whatsapp.net" {
+		t.Fatalf("unexpected message.ack event: %+v", ack)
+	}
+
+	groupInfo := &events.GroupInfo{JID: types.NewJID("120363024512399999", types.GroupServer), Timestamp: receipt.Timestamp}
+	participants := decodeWebhookBody[webhooks.GroupParticipantsPayload](t, createGroupInfoPayload(ctx, groupInfo, "promote", []types.JID{chat}, "dev-1", nil))
+	if participants.Event != webhooks.EventGroupParticipants || participants.Payload.Type != "promote" || len(participants.Payload.JIDs) != 1 {
+		t.Fatalf("unexpected group.participants event: %+v", participants)
+	}
+}
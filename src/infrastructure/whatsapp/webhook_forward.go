@@ -69,29 +69,51 @@ func getContactMutex(phone string) *sync.Mutex {
 }
 
 // forwardPayloadToConfiguredWebhooks attempts to deliver the provided payload to every configured webhook URL.
-// It only returns an error when all webhook deliveries fail. Partial failures are logged and suppressed so
-// successful targets still receive the event.
+// Payloads of a device with its own webhook go only to that webhook. It only returns an error when all webhook
+// deliveries fail. Partial failures are logged and suppressed so successful targets still receive the event.
 func forwardPayloadToConfiguredWebhooks(ctx context.Context, payload map[string]any, eventName string) error {
-	// Check if event is whitelisted (if whitelist is configured)
-	if len(config.WhatsappWebhookEvents) > 0 {
+	webhookDeliveries.Add()
+	defer webhookDeliveries.Done()
+
+	publishToEventStream(payload, eventName)
+
+	deviceHook, hasDeviceHook := deviceWebhookForPayload(payload)
+
+	// Check if event is whitelisted (if whitelist is configured). Device webhooks filter by their own events.
+	if !hasDeviceHook && len(config.WhatsappWebhookEvents) > 0 {
 		if !isEventWhitelisted(eventName) {
 			logrus.Debugf("Skipping event %s - not in webhook events whitelist", eventName)
 			return nil
 		}
 	}
 
-	err := forwardToWebhooks(ctx, payload, eventName)
+	var err error
+	if hasDeviceHook {
+		err = forwardToWebhooks(ctx, payload, eventName, []WebhookTarget{deviceHook.WebhookTarget}, deviceHook.secret())
+	} else {
+		err = forwardToWebhooks(ctx, payload, eventName, WebhookTargets(), config.WhatsappWebhookSecret)
+	}
 
 	if eventName == "message" && config.ChatwootEnabled {
-		go forwardToChatwoot(ctx, payload)
+		webhookDeliveries.Add()
+		go func() {
+			defer webhookDeliveries.Done()
+			forwardToChatwoot(ctx, payload)
+		}()
 	}
 
 	return err
 }
 
-func forwardToWebhooks(ctx context.Context, payload map[string]any, eventName string) error {
-	total := len(config.WhatsappWebhook)
-	logrus.Infof("Forwarding %s to %d configured webhook(s)", eventName, total)
+func forwardToWebhooks(ctx context.Context, payload map[string]any, eventName string, targets []WebhookTarget, secret string) error {
+	var urls []string
+	for _, target := range targets {
+		if target.Accepts(eventName) {
+			urls = append(urls, target.URL)
+		}
+	}
+	total := len(urls)
+	logrus.Infof("Forwarding %s to %d of %d configured webhook(s)", eventName, total, len(targets))
 
 	if total == 0 {
 		return nil
@@ -101,8 +123,8 @@ func forwardToWebhooks(ctx context.Context, payload map[string]any, eventName st
 		failed    []string
 		successes int
 	)
-	for _, url := range config.WhatsappWebhook {
-		if err := submitWebhookFn(ctx, payload, url); err != nil {
+	for _, url := range urls {
+		if err := submitWebhookFn(ctx, payload, url, secret); err != nil {
 			failed = append(failed, fmt.Sprintf("%s: %v", url, err))
 			logrus.Warnf("Failed forwarding %s to %s: %v", eventName, url, err)
 			continue
@@ -229,7 +251,10 @@ func extractStructuredMessageContent(data map[string]interface{}) string {
 			GetVcard() string
 		}); ok {
 			name := cm.GetDisplayName()
-			phone := extractPhoneFromVCard(cm.GetVcard())
+			var phone string
+			if phones := utils.ParseVCard(cm.GetVcard()).PhoneNumbers; len(phones) > 0 {
+				phone = phones[0]
+			}
 			switch {
 			case name != "" && phone != "":
 				return fmt.Sprintf("Contact: %s (%s)", name, phone)
@@ -290,18 +315,6 @@ func extractStructuredMessageContent(data map[string]interface{}) string {
 	return ""
 }
 
-func extractPhoneFromVCard(vcard string) string {
-	for _, line := range strings.Split(vcard, "\n") {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(strings.ToUpper(line), "TEL") {
-			if idx := strings.LastIndex(line, ":"); idx >= 0 {
-				return strings.TrimSpace(line[idx+1:])
-			}
-		}
-	}
-	return ""
-}
-
 // syncMessageToChatwoot creates or finds contact/conversation and sends the message.
 func syncMessageToChatwoot(cw *chatwoot.Client, info *chatwootContactInfo, content string, attachments []string) error {
 	// Lock per-identifier mutex to prevent duplicate contact/conversation creation
@@ -18,7 +18,7 @@ func TestForwardPayloadToConfiguredWebhooks_NoWebhooksConfigured(t *testing.T) {
 	defer func() { config.WhatsappWebhook = originalWebhooks }()
 
 	originalSubmit := submitWebhookFn
-	submitWebhookFn = func(context.Context, map[string]any, string) error {
+	submitWebhookFn = func(context.Context, map[string]any, string, string) error {
 		t.Fatal("submitWebhookFn should not be invoked when no webhooks are configured")
 		return nil
 	}
@@ -39,7 +39,7 @@ func TestForwardPayloadToConfiguredWebhooks_PartialFailure(t *testing.T) {
 
 	originalSubmit := submitWebhookFn
 	var attempts []string
-	submitWebhookFn = func(_ context.Context, _ map[string]any, url string) error {
+	submitWebhookFn = func(_ context.Context, _ map[string]any, url, _ string) error {
 		attempts = append(attempts, url)
 		if strings.Contains(url, "fail") {
 			return errors.New("boom")
@@ -66,7 +66,7 @@ func TestForwardPayloadToConfiguredWebhooks_AllFail(t *testing.T) {
 	defer func() { config.WhatsappWebhook = originalWebhooks }()
 
 	originalSubmit := submitWebhookFn
-	submitWebhookFn = func(_ context.Context, _ map[string]any, url string) error {
+	submitWebhookFn = func(_ context.Context, _ map[string]any, url, _ string) error {
 		return errors.New("failure for " + url)
 	}
 	defer func() { submitWebhookFn = originalSubmit }()
@@ -91,7 +91,7 @@ func TestForwardPayloadToConfiguredWebhooks_EventWhitelist_FilteredOut(t *testin
 
 	called := false
 	originalSubmit := submitWebhookFn
-	submitWebhookFn = func(context.Context, map[string]any, string) error {
+	submitWebhookFn = func(context.Context, map[string]any, string, string) error {
 		called = true
 		return nil
 	}
@@ -120,7 +120,7 @@ func TestForwardPayloadToConfiguredWebhooks_EventWhitelist_Allowed(t *testing.T)
 
 	called := false
 	originalSubmit := submitWebhookFn
-	submitWebhookFn = func(context.Context, map[string]any, string) error {
+	submitWebhookFn = func(context.Context, map[string]any, string, string) error {
 		called = true
 		return nil
 	}
@@ -149,7 +149,7 @@ func TestForwardPayloadToConfiguredWebhooks_EmptyWhitelist_AllowsAll(t *testing.
 
 	called := false
 	originalSubmit := submitWebhookFn
-	submitWebhookFn = func(context.Context, map[string]any, string) error {
+	submitWebhookFn = func(context.Context, map[string]any, string, string) error {
 		called = true
 		return nil
 	}
@@ -178,7 +178,7 @@ func TestForwardPayloadToConfiguredWebhooks_WhitelistCaseInsensitive(t *testing.
 
 	called := 0
 	originalSubmit := submitWebhookFn
-	submitWebhookFn = func(context.Context, map[string]any, string) error {
+	submitWebhookFn = func(context.Context, map[string]any, string, string) error {
 		called++
 		return nil
 	}
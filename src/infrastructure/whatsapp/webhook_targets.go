@@ -0,0 +1,103 @@
+package whatsapp
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+)
+
+// WebhookTarget is a configured webhook URL and the events it receives. An
+// empty Events list receives every event.
+type WebhookTarget struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+// Accepts reports whether the target receives events named eventName.
+func (t WebhookTarget) Accepts(eventName string) bool {
+	if len(t.Events) == 0 {
+		return true
+	}
+	for _, event := range t.Events {
+		if strings.EqualFold(event, eventName) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseWebhookTargets parses the WHATSAPP_WEBHOOK setting. Targets are
+// separated by ";" or ",", and a target may end with "#" followed by the
+// comma-separated events it receives:
+//
+//	https://a.example/hook#message,message.ack;https://b.example/hook#group.participants
+//
+// Plain comma-separated URLs keep receiving every event.
+func ParseWebhookTargets(spec string) ([]WebhookTarget, error) {
+	var targets []WebhookTarget
+	for _, token := range strings.FieldsFunc(spec, func(r rune) bool { return r == ';' || r == ',' }) {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		// Event names never contain a scheme, so any token that does starts a new target
+		if !strings.Contains(token, "://") {
+			if len(targets) == 0 || strings.HasPrefix(token, "#") {
+				return nil, fmt.Errorf("webhook entry %q has no URL", token)
+			}
+			last := &targets[len(targets)-1]
+			if len(last.Events) == 0 {
+				return nil, fmt.Errorf("webhook entry %q is not a URL", token)
+			}
+			last.Events = append(last.Events, token)
+			continue
+		}
+
+		rawURL, events, hasFilter := strings.Cut(token, "#")
+		parsed, err := url.Parse(rawURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("webhook URL %q is not a valid http(s) URL", rawURL)
+		}
+		for _, existing := range targets {
+			if existing.URL == rawURL {
+				return nil, fmt.Errorf("webhook URL %q is listed more than once", rawURL)
+			}
+		}
+		target := WebhookTarget{URL: rawURL}
+		if hasFilter {
+			if event := strings.TrimSpace(events); event != "" {
+				target.Events = append(target.Events, event)
+			} else {
+				return nil, fmt.Errorf("webhook URL %q has an empty event filter", rawURL)
+			}
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// ConfigureWebhookTargets makes targets the webhooks events are forwarded to.
+func ConfigureWebhookTargets(targets []WebhookTarget) {
+	urls := make([]string, 0, len(targets))
+	filters := make(map[string][]string)
+	for _, target := range targets {
+		urls = append(urls, target.URL)
+		if len(target.Events) > 0 {
+			filters[target.URL] = target.Events
+		}
+	}
+	config.WhatsappWebhook = urls
+	config.WhatsappWebhookTargetEvents = filters
+}
+
+// WebhookTargets returns the configured webhooks with their event filters.
+func WebhookTargets() []WebhookTarget {
+	targets := make([]WebhookTarget, 0, len(config.WhatsappWebhook))
+	for _, webhookURL := range config.WhatsappWebhook {
+		targets = append(targets, WebhookTarget{URL: webhookURL, Events: config.WhatsappWebhookTargetEvents[webhookURL]})
+	}
+	return targets
+}
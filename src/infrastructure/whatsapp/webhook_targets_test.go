@@ -0,0 +1,99 @@
+package whatsapp
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+)
+
+func TestParseWebhookTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []WebhookTarget
+		wantErr bool
+	}{
+		{
+			name: "PlainURLs",
+			spec: "https://a.example/hook, https://b.example/hook",
+			want: []WebhookTarget{{URL: "https://a.example/hook"}, {URL: "https://b.example/hook"}},
+		},
+		{
+			name: "PerURLFilters",
+			spec: "https://a.example/hook#message,message.ack;https://b.example/hook#group.participants",
+			want: []WebhookTarget{
+				{URL: "https://a.example/hook", Events: []string{"message", "message.ack"}},
+				{URL: "https://b.example/hook", Events: []string{"group.participants"}},
+			},
+		},
+		{
+			name: "FilteredAndUnfilteredMixed",
+			spec: "https://a.example/hook#call.offer,https://b.example/hook;",
+			want: []WebhookTarget{
+				{URL: "https://a.example/hook", Events: []string{"call.offer"}},
+				{URL: "https://b.example/hook"},
+			},
+		},
+		{name: "Empty", spec: " ; ", want: nil},
+		{name: "FilterWithoutURL", spec: "#message", wantErr: true},
+		{name: "EventBeforeURL", spec: "message;https://a.example/hook", wantErr: true},
+		{name: "EventAfterUnfilteredURL", spec: "https://a.example/hook,message", wantErr: true},
+		{name: "EmptyFilter", spec: "https://a.example/hook#", wantErr: true},
+		{name: "UnsupportedScheme", spec: "ftp://a.example/hook", wantErr: true},
+		{name: "MissingHost", spec: "https:///hook", wantErr: true},
+		{name: "DuplicateURL", spec: "https://a.example/hook#message;https://a.example/hook#group.joined", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWebhookTargets(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseWebhookTargets() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForwardToWebhooks_PerURLFilters(t *testing.T) {
+	originalWebhooks, originalFilters := config.WhatsappWebhook, config.WhatsappWebhookTargetEvents
+	t.Cleanup(func() { config.WhatsappWebhook, config.WhatsappWebhookTargetEvents = originalWebhooks, originalFilters })
+	ConfigureWebhookTargets([]WebhookTarget{
+		{URL: "https://messages", Events: []string{"Message"}},
+		{URL: "https://groups", Events: []string{"group.participants"}},
+		{URL: "https://all"},
+	})
+
+	originalSubmit := submitWebhookFn
+	t.Cleanup(func() { submitWebhookFn = originalSubmit })
+	var attempts []string
+	submitWebhookFn = func(_ context.Context, _ map[string]any, url, _ string) error {
+		attempts = append(attempts, url)
+		return nil
+	}
+
+	if err := forwardPayloadToConfiguredWebhooks(context.Background(), map[string]any{}, "message"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if want := []string{"https://messages", "https://all"}; !reflect.DeepEqual(attempts, want) {
+		t.Fatalf("message delivered to %v, want %v", attempts, want)
+	}
+
+	attempts = nil
+	if err := forwardPayloadToConfiguredWebhooks(context.Background(), map[string]any{}, "call.offer"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if want := []string{"https://all"}; !reflect.DeepEqual(attempts, want) {
+		t.Fatalf("call.offer delivered to %v, want %v", attempts, want)
+	}
+}
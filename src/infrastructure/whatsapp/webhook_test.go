@@ -0,0 +1,75 @@
+package whatsapp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+)
+
+func TestSubmitWebhook_SignsTimestampAndKeepsDeliveryID(t *testing.T) {
+	var mu sync.Mutex
+	var deliveryIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := utils.VerifyWebhookSignature("super-secret-key", r.Header.Get("X-Webhook-Timestamp"), body, r.Header.Get("X-Hub-Signature-256")); err != nil {
+			t.Errorf("signature did not verify: %v", err)
+		}
+
+		mu.Lock()
+		deliveryIDs = append(deliveryIDs, r.Header.Get("X-Webhook-Id"))
+		attempt := len(deliveryIDs)
+		mu.Unlock()
+		if attempt == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	if err := submitWebhook(context.Background(), map[string]any{"event": "message"}, server.URL, "super-secret-key"); err != nil {
+		t.Fatalf("expected delivery to succeed on retry, got %v", err)
+	}
+	if len(deliveryIDs) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(deliveryIDs))
+	}
+	if deliveryIDs[0] == "" || deliveryIDs[0] != deliveryIDs[1] {
+		t.Fatalf("expected a stable delivery ID across retries, got %v", deliveryIDs)
+	}
+}
+
+func TestCheckWebhookSecret(t *testing.T) {
+	originalStrict, originalSecret, originalWebhooks := config.WhatsappWebhookStrict, config.WhatsappWebhookSecret, config.WhatsappWebhook
+	defer func() {
+		config.WhatsappWebhookStrict, config.WhatsappWebhookSecret, config.WhatsappWebhook = originalStrict, originalSecret, originalWebhooks
+	}()
+
+	tests := []struct {
+		name     string
+		strict   bool
+		secret   string
+		webhooks []string
+		wantErr  bool
+	}{
+		{name: "default secret without strict mode", secret: "secret", webhooks: []string{"https://a.example/hook"}},
+		{name: "strict without webhooks", strict: true, secret: ""},
+		{name: "strict with empty secret", strict: true, secret: "", webhooks: []string{"https://a.example/hook"}, wantErr: true},
+		{name: "strict with default secret", strict: true, secret: "secret", webhooks: []string{"https://a.example/hook"}, wantErr: true},
+		{name: "strict with own secret", strict: true, secret: "super-secret-key", webhooks: []string{"https://a.example/hook"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.WhatsappWebhookStrict, config.WhatsappWebhookSecret, config.WhatsappWebhook = tt.strict, tt.secret, tt.webhooks
+			if err := CheckWebhookSecret(); (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -16,7 +16,7 @@ func (e LoginError) ErrCode() string {
 
 // StatusCode will return the HTTP status code based on the error data type
 func (e LoginError) StatusCode() int {
-	return http.StatusBadRequest
+	return http.StatusConflict
 }
 
 type ReconnectError string
@@ -63,6 +63,43 @@ func RequestTimeout(text string) GenericError {
 	return TimeoutError(text)
 }
 
+// NotFoundError is returned when something the request refers to doesn't exist
+type NotFoundError string
+
+// Error for complying the error interface
+func (e NotFoundError) Error() string {
+	return string(e)
+}
+
+// ErrCode will return the error code based on the error data type
+func (e NotFoundError) ErrCode() string {
+	return "NOT_FOUND"
+}
+
+// StatusCode will return the HTTP status code based on the error data type
+func (e NotFoundError) StatusCode() int {
+	return http.StatusNotFound
+}
+
+// RateLimitError is returned when a request is refused for coming too soon
+// after the last one
+type RateLimitError string
+
+// Error for complying the error interface
+func (e RateLimitError) Error() string {
+	return string(e)
+}
+
+// ErrCode will return the error code based on the error data type
+func (e RateLimitError) ErrCode() string {
+	return "RATE_LIMITED"
+}
+
+// StatusCode will return the HTTP status code based on the error data type
+func (e RateLimitError) StatusCode() int {
+	return http.StatusTooManyRequests
+}
+
 var (
 	ErrInternalServerError = InternalServerError("internal server error")
 	ErrRequestTimeout      = TimeoutError("request timed out waiting for WhatsApp server response")
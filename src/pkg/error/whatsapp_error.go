@@ -70,6 +70,119 @@ func (e WaUploadMediaError) StatusCode() int {
 	return http.StatusInternalServerError
 }
 
+// MediaExpiredError is returned when WhatsApp servers no longer have the
+// attachment of a message; the sender can be asked to upload it again.
+type MediaExpiredError string
+
+// Error for complying the error interface
+func (e MediaExpiredError) Error() string {
+	return string(e)
+}
+
+// ErrCode will return the error code based on the error data type
+func (e MediaExpiredError) ErrCode() string {
+	return "MEDIA_EXPIRED"
+}
+
+// StatusCode will return the HTTP status code based on the error data type
+func (e MediaExpiredError) StatusCode() int {
+	return http.StatusGone
+}
+
+// InvalidMediaError is returned when an uploaded file can't be sent as the
+// requested media type, e.g. a "video" ffprobe finds no video stream in.
+type InvalidMediaError string
+
+// Error for complying the error interface
+func (e InvalidMediaError) Error() string {
+	return string(e)
+}
+
+// ErrCode will return the error code based on the error data type
+func (e InvalidMediaError) ErrCode() string {
+	return "INVALID_MEDIA"
+}
+
+// StatusCode will return the HTTP status code based on the error data type
+func (e InvalidMediaError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+// EditWindowExpiredError is returned when a message is too old to be edited.
+type EditWindowExpiredError string
+
+// Error for complying the error interface
+func (e EditWindowExpiredError) Error() string {
+	return string(e)
+}
+
+// ErrCode will return the error code based on the error data type
+func (e EditWindowExpiredError) ErrCode() string {
+	return "EDIT_WINDOW_EXPIRED"
+}
+
+// StatusCode will return the HTTP status code based on the error data type
+func (e EditWindowExpiredError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+// RevokeWindowExpiredError is returned when a message is too old to be
+// deleted for everyone.
+type RevokeWindowExpiredError string
+
+// Error for complying the error interface
+func (e RevokeWindowExpiredError) Error() string {
+	return string(e)
+}
+
+// ErrCode will return the error code based on the error data type
+func (e RevokeWindowExpiredError) ErrCode() string {
+	return "REVOKE_WINDOW_EXPIRED"
+}
+
+// StatusCode will return the HTTP status code based on the error data type
+func (e RevokeWindowExpiredError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+// NotGroupAdminError is returned when a group action needs admin rights the
+// device doesn't have in that group.
+type NotGroupAdminError string
+
+// Error for complying the error interface
+func (e NotGroupAdminError) Error() string {
+	return string(e)
+}
+
+// ErrCode will return the error code based on the error data type
+func (e NotGroupAdminError) ErrCode() string {
+	return "NOT_GROUP_ADMIN"
+}
+
+// StatusCode will return the HTTP status code based on the error data type
+func (e NotGroupAdminError) StatusCode() int {
+	return http.StatusForbidden
+}
+
+// NotNewsletterAdminError is returned when posting to a channel the device
+// neither owns nor administers.
+type NotNewsletterAdminError string
+
+// Error for complying the error interface
+func (e NotNewsletterAdminError) Error() string {
+	return string(e)
+}
+
+// ErrCode will return the error code based on the error data type
+func (e NotNewsletterAdminError) ErrCode() string {
+	return "NOT_NEWSLETTER_ADMIN"
+}
+
+// StatusCode will return the HTTP status code based on the error data type
+func (e NotNewsletterAdminError) StatusCode() int {
+	return http.StatusForbidden
+}
+
 const (
 	ErrInvalidJID        = InvalidJID("your JID is invalid")
 	ErrUserNotRegistered = InvalidJID("user is not registered")
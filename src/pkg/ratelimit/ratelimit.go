@@ -0,0 +1,216 @@
+package ratelimit
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Buckets a client's requests are counted in
+const (
+	BucketSend = "send"
+	BucketBulk = "bulk"
+)
+
+// Limit lets a client make PerMinute requests a minute on average and up to
+// Burst at once. A PerMinute of zero or less doesn't limit.
+type Limit struct {
+	PerMinute int `json:"per_minute"`
+	Burst     int `json:"burst"`
+}
+
+func (limit Limit) enabled() bool {
+	return limit.PerMinute > 0
+}
+
+// capacity is how many tokens a full bucket holds. A burst below one still
+// lets single requests through.
+func (limit Limit) capacity() float64 {
+	return float64(max(limit.Burst, 1))
+}
+
+// refill is how many tokens a bucket gains in d.
+func (limit Limit) refill(d time.Duration) float64 {
+	return d.Minutes() * float64(limit.PerMinute)
+}
+
+// Limits are the limits of each bucket.
+type Limits struct {
+	Send Limit `json:"send"`
+	Bulk Limit `json:"bulk"`
+}
+
+func (limits Limits) of(bucket string) Limit {
+	if bucket == BucketBulk {
+		return limits.Bulk
+	}
+	return limits.Send
+}
+
+// Usage is the state of a client's bucket.
+type Usage struct {
+	Client        string    `json:"client"`
+	Bucket        string    `json:"bucket"`
+	Remaining     int       `json:"remaining"` // Requests the client can make right away
+	Allowed       int64     `json:"allowed"`
+	Rejected      int64     `json:"rejected"`
+	LastRequestAt time.Time `json:"last_request_at"`
+}
+
+// Store keeps the token buckets. The in-memory store limits every instance on
+// its own; instances behind a load balancer can share one store so a client
+// is limited across all of them.
+type Store interface {
+	// Take spends a token of the client's bucket, refilled at limit. When
+	// none is left it returns false and how long until the next token.
+	Take(client, bucket string, limit Limit, now time.Time) (bool, time.Duration)
+	// Refund gives back a token Take spent on a request that didn't go through.
+	Refund(client, bucket string, now time.Time)
+	// Usage lists the buckets requests were counted in, refilled up to now.
+	Usage(now time.Time) []Usage
+}
+
+// idleBucketSweepSize is the number of buckets above which full buckets are
+// dropped, as they are the same as no bucket.
+const idleBucketSweepSize = 10000
+
+type bucket struct {
+	limit    Limit
+	tokens   float64
+	updated  time.Time
+	allowed  int64
+	rejected int64
+}
+
+// refilled is the bucket's tokens at now.
+func (b *bucket) refilled(now time.Time) float64 {
+	return math.Min(b.limit.capacity(), b.tokens+b.limit.refill(now.Sub(b.updated)))
+}
+
+type memoryStore struct {
+	mu      sync.Mutex
+	buckets map[[2]string]*bucket
+}
+
+// NewMemoryStore keeps buckets in this process.
+func NewMemoryStore() Store {
+	return &memoryStore{buckets: make(map[[2]string]*bucket)}
+}
+
+func (s *memoryStore) Take(client, bucketName string, limit Limit, now time.Time) (bool, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := [2]string{client, bucketName}
+	b, ok := s.buckets[key]
+	if !ok {
+		if len(s.buckets) > idleBucketSweepSize {
+			s.sweep(now)
+		}
+		b = &bucket{limit: limit, tokens: limit.capacity(), updated: now}
+		s.buckets[key] = b
+	}
+	// Limits may have changed since the last request
+	b.tokens = math.Min(b.refilled(now), limit.capacity())
+	b.limit, b.updated = limit, now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		b.allowed++
+		return true, 0
+	}
+	b.rejected++
+	wait := time.Duration((1 - b.tokens) / float64(limit.PerMinute) * float64(time.Minute))
+	return false, wait
+}
+
+func (s *memoryStore) Refund(client, bucketName string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[[2]string{client, bucketName}]
+	if !ok || b.allowed == 0 {
+		return
+	}
+	b.tokens = math.Min(b.refilled(now)+1, b.limit.capacity())
+	b.updated = now
+	b.allowed--
+}
+
+func (s *memoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if b.refilled(now) >= b.limit.capacity() {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func (s *memoryStore) Usage(now time.Time) []Usage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usage := make([]Usage, 0, len(s.buckets))
+	for key, b := range s.buckets {
+		usage = append(usage, Usage{
+			Client:        key[0],
+			Bucket:        key[1],
+			Remaining:     int(b.refilled(now)),
+			Allowed:       b.allowed,
+			Rejected:      b.rejected,
+			LastRequestAt: b.updated,
+		})
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Client != usage[j].Client {
+			return usage[i].Client < usage[j].Client
+		}
+		return usage[i].Bucket < usage[j].Bucket
+	})
+	return usage
+}
+
+// Limiter limits the requests of each client with limits that can be changed
+// while running.
+type Limiter struct {
+	mu     sync.RWMutex
+	limits Limits
+	store  Store
+}
+
+// NewLimiter limits clients with limits, keeping buckets in store or, when
+// store is nil, in memory.
+func NewLimiter(limits Limits, store Store) *Limiter {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Limiter{limits: limits, store: store}
+}
+
+func (l *Limiter) Limits() Limits {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.limits
+}
+
+// SetLimits replaces the limits. Buckets keep their tokens, capped at the new
+// burst.
+func (l *Limiter) SetLimits(limits Limits) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limits = limits
+}
+
+// Allow counts a request of client in bucket. When the client is over its
+// limit it returns false and how long until it may retry.
+func (l *Limiter) Allow(client, bucket string) (bool, time.Duration) {
+	limit := l.Limits().of(bucket)
+	if !limit.enabled() {
+		return true, 0
+	}
+	return l.store.Take(client, bucket, limit, time.Now())
+}
+
+func (l *Limiter) Usage() []Usage {
+	return l.store.Usage(time.Now())
+}
@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{PerMinute: 6, Burst: 2}
+	now := time.Date(2026, time.March, 9, 9, 0, 0, 0, time.UTC)
+
+	ok, _ := store.Take("key:a", BucketSend, limit, now)
+	assert.True(t, ok)
+	ok, _ = store.Take("key:a", BucketSend, limit, now)
+	assert.True(t, ok, "the burst goes through at once")
+	ok, wait := store.Take("key:a", BucketSend, limit, now)
+	assert.False(t, ok)
+	assert.Equal(t, 10*time.Second, wait, "a token comes back every 10 seconds")
+
+	ok, _ = store.Take("key:b", BucketSend, limit, now)
+	assert.True(t, ok, "clients have their own buckets")
+	ok, _ = store.Take("key:a", BucketBulk, limit, now)
+	assert.True(t, ok, "buckets are counted apart")
+
+	ok, _ = store.Take("key:a", BucketSend, limit, now.Add(10*time.Second))
+	assert.True(t, ok)
+
+	usage := store.Usage(now.Add(10 * time.Second))
+	assert.Equal(t, []Usage{
+		{Client: "key:a", Bucket: BucketBulk, Remaining: 2, Allowed: 1, LastRequestAt: now},
+		{Client: "key:a", Bucket: BucketSend, Remaining: 0, Allowed: 3, Rejected: 1, LastRequestAt: now.Add(10 * time.Second)},
+		{Client: "key:b", Bucket: BucketSend, Remaining: 2, Allowed: 1, LastRequestAt: now},
+	}, usage)
+}
+
+func TestMemoryStoreRefund(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{PerMinute: 1, Burst: 1}
+	now := time.Date(2026, time.March, 9, 9, 0, 0, 0, time.UTC)
+
+	ok, _ := store.Take("key:a", BucketSend, limit, now)
+	assert.True(t, ok)
+	store.Refund("key:a", BucketSend, now)
+	ok, _ = store.Take("key:a", BucketSend, limit, now)
+	assert.True(t, ok, "a refunded token can be taken again")
+	ok, _ = store.Take("key:a", BucketSend, limit, now)
+	assert.False(t, ok)
+
+	store.Refund("key:a", BucketSend, now)
+	store.Refund("key:a", BucketSend, now)
+	store.Refund("key:b", BucketSend, now)
+	assert.Equal(t, []Usage{
+		{Client: "key:a", Bucket: BucketSend, Remaining: 1, Rejected: 1, LastRequestAt: now},
+	}, store.Usage(now), "refunds never go past the burst or the tokens spent")
+}
+
+func TestMemoryStoreLimitChange(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2026, time.March, 9, 9, 0, 0, 0, time.UTC)
+
+	ok, _ := store.Take("key:a", BucketSend, Limit{PerMinute: 60, Burst: 10}, now)
+	assert.True(t, ok)
+
+	strict := Limit{PerMinute: 1, Burst: 1}
+	ok, _ = store.Take("key:a", BucketSend, strict, now)
+	assert.True(t, ok, "tokens are capped at the new burst")
+	ok, wait := store.Take("key:a", BucketSend, strict, now)
+	assert.False(t, ok)
+	assert.Equal(t, time.Minute, wait)
+}
+
+func TestLimiter(t *testing.T) {
+	limiter := NewLimiter(Limits{Send: Limit{PerMinute: 60, Burst: 1}}, nil)
+
+	ok, _ := limiter.Allow("key:a", BucketBulk)
+	assert.True(t, ok, "a zero limit doesn't limit")
+	ok, _ = limiter.Allow("key:a", BucketSend)
+	assert.True(t, ok)
+	ok, _ = limiter.Allow("key:a", BucketSend)
+	assert.False(t, ok)
+
+	limiter.SetLimits(Limits{})
+	ok, _ = limiter.Allow("key:a", BucketSend)
+	assert.True(t, ok, "limits apply at once")
+	assert.Equal(t, Limits{}, limiter.Limits())
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"context"
+	"sync"
+)
+
+// InFlight counts operations in progress, so shutdown can wait for them. The
+// zero value is ready to use.
+type InFlight struct {
+	mu    sync.Mutex
+	count int
+	idle  chan struct{} // Closed while count is zero, nil before the first Add
+}
+
+// Add counts an operation that started.
+func (f *InFlight) Add() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.count == 0 {
+		f.idle = make(chan struct{})
+	}
+	f.count++
+}
+
+// Done counts an operation that finished.
+func (f *InFlight) Done() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.count == 0 {
+		panic("utils: InFlight.Done without Add")
+	}
+	f.count--
+	if f.count == 0 {
+		close(f.idle)
+	}
+}
+
+// Count is the number of operations in progress.
+func (f *InFlight) Count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.count
+}
+
+// Wait returns once no operation is in progress, or with the error of ctx if
+// it ends first. Operations started while waiting are waited for too.
+func (f *InFlight) Wait(ctx context.Context) error {
+	for {
+		f.mu.Lock()
+		if f.count == 0 {
+			f.mu.Unlock()
+			return nil
+		}
+		idle := f.idle
+		f.mu.Unlock()
+
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInFlight(t *testing.T) {
+	var inFlight InFlight
+	assert.NoError(t, inFlight.Wait(context.Background()), "nothing to wait for")
+
+	inFlight.Add()
+	inFlight.Add()
+	assert.Equal(t, 2, inFlight.Count())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, inFlight.Wait(ctx), context.DeadlineExceeded)
+
+	go func() {
+		inFlight.Done()
+		time.Sleep(10 * time.Millisecond)
+		inFlight.Done()
+	}()
+	assert.NoError(t, inFlight.Wait(context.Background()))
+	assert.Equal(t, 0, inFlight.Count())
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// MessageTemplate is a message body with {{.name}} placeholders. Only
+// placeholders are allowed, no other template actions, so a body can't call
+// functions, loop or branch.
+type MessageTemplate struct {
+	tmpl *template.Template
+	// Variables are the placeholder names in order of first use
+	Variables []string
+}
+
+// ParseMessageTemplate parses body and rejects every template action other
+// than a {{.name}} placeholder.
+func ParseMessageTemplate(name, body string) (*MessageTemplate, error) {
+	// Missing keys of the variables map render empty instead of "<no value>"
+	tmpl, err := template.New(name).Option("missingkey=zero").Parse(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(tmpl.Templates()) > 1 {
+		return nil, fmt.Errorf("%s: only {{.name}} placeholders are allowed, not {{define}}", name)
+	}
+	parsed := &MessageTemplate{tmpl: tmpl}
+	if tmpl.Tree == nil {
+		return parsed, nil
+	}
+	seen := make(map[string]bool)
+	for _, node := range tmpl.Tree.Root.Nodes {
+		switch node := node.(type) {
+		case *parse.TextNode, *parse.CommentNode:
+		case *parse.ActionNode:
+			variable, ok := placeholderName(node)
+			if !ok {
+				return nil, fmt.Errorf("%s: only {{.name}} placeholders are allowed, not %s", name, node)
+			}
+			if !seen[variable] {
+				seen[variable] = true
+				parsed.Variables = append(parsed.Variables, variable)
+			}
+		default:
+			return nil, fmt.Errorf("%s: only {{.name}} placeholders are allowed, not %s", name, node)
+		}
+	}
+	return parsed, nil
+}
+
+// placeholderName returns name for an action that is exactly {{.name}}.
+func placeholderName(action *parse.ActionNode) (string, bool) {
+	pipe := action.Pipe
+	if pipe == nil || len(pipe.Decl) > 0 || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return "", false
+	}
+	field, ok := pipe.Cmds[0].Args[0].(*parse.FieldNode)
+	if !ok || len(field.Ident) != 1 {
+		return "", false
+	}
+	return field.Ident[0], true
+}
+
+// Render fills in the placeholders with variables. Missing variables render
+// empty, unless strict makes them an error. Values are inserted as they are,
+// nothing is escaped.
+func (t *MessageTemplate) Render(variables map[string]string, strict bool) (string, error) {
+	if strict {
+		var missing []string
+		for _, variable := range t.Variables {
+			if _, ok := variables[variable]; !ok {
+				missing = append(missing, variable)
+			}
+		}
+		if len(missing) > 0 {
+			return "", errors.New("missing variables: " + strings.Join(missing, ", "))
+		}
+	}
+	if variables == nil {
+		variables = map[string]string{}
+	}
+	var rendered strings.Builder
+	if err := t.tmpl.Execute(&rendered, variables); err != nil {
+		return "", err
+	}
+	return rendered.String(), nil
+}
+
+type templateNameKey struct{}
+
+// ContextWithTemplateName returns ctx carrying the name of the template the
+// messages sent with it were rendered from.
+func ContextWithTemplateName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, templateNameKey{}, name)
+}
+
+// TemplateNameFromContext returns the template name ctx carries, or "".
+func TemplateNameFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	name, _ := ctx.Value(templateNameKey{}).(string)
+	return name
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMessageTemplate(t *testing.T) {
+	tmpl, err := ParseMessageTemplate("greeting", "Hi {{.name}}, your order {{ .order }} ships today. {{/* signature */}}Bye {{.name}}")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name", "order"}, tmpl.Variables)
+
+	for _, body := range []string{
+		`{{if .vip}}VIP{{end}}`,
+		`{{range .items}}x{{end}}`,
+		`{{.name | printf "%q"}}`,
+		`{{printf "%s" .name}}`,
+		`{{$x := .name}}`,
+		`{{.customer.name}}`,
+		`{{template "other"}}`,
+		`{{define "other"}}x{{end}}Hi`,
+		`{{index . "name"}}`,
+	} {
+		_, err := ParseMessageTemplate("t", body)
+		assert.Error(t, err, body)
+	}
+
+	_, err = ParseMessageTemplate("t", "Hi {{name}}")
+	assert.ErrorContains(t, err, `function "name" not defined`)
+}
+
+func TestMessageTemplateRender(t *testing.T) {
+	tmpl, err := ParseMessageTemplate("greeting", "Hi {{.name}} <{{.email}}>")
+	require.NoError(t, err)
+
+	rendered, err := tmpl.Render(map[string]string{"name": "Tom & Jerry", "email": "t@example.com"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, "Hi Tom & Jerry <t@example.com>", rendered, "values are not escaped")
+
+	rendered, err = tmpl.Render(map[string]string{"name": "Ann"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, "Hi Ann <>", rendered)
+
+	_, err = tmpl.Render(map[string]string{"name": "Ann"}, true)
+	assert.EqualError(t, err, "missing variables: email")
+	_, err = tmpl.Render(nil, true)
+	assert.EqualError(t, err, "missing variables: name, email")
+}
+
+func TestTemplateNameFromContext(t *testing.T) {
+	assert.Equal(t, "greeting", TemplateNameFromContext(ContextWithTemplateName(context.Background(), "greeting")))
+	assert.Empty(t, TemplateNameFromContext(context.Background()))
+}
@@ -1,6 +1,17 @@
 package utils
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Shortest and longest phone numbers accepted by NormalizePhoneNumber,
+// country code included.
+const (
+	minPhoneNumberDigits = 7
+	maxPhoneNumberDigits = 15
+)
 
 // NormalizePhoneE164 ensures phone has + prefix for E.164 format.
 // Strips WhatsApp JID suffixes (@s.whatsapp.net, @lid, etc.) before formatting.
@@ -17,6 +28,47 @@ func NormalizePhoneE164(phone string) string {
 	return phone
 }
 
+// NormalizePhoneDigits removes the + prefix and the spaces, dashes, dots and
+// parentheses people type in phone numbers, e.g. "+62 812-3456 (789)" becomes
+// "628123456789". Other characters are kept so validation can reject them.
+func NormalizePhoneDigits(phone string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '+', '-', '.', '(', ')':
+			return -1
+		}
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, phone)
+}
+
+// NormalizePhoneNumber turns a phone number as people type it into digits
+// with the country code, e.g. "+62 812-3456-789" becomes "628123456789".
+// Numbers starting with + or 00 are international; numbers starting with 0
+// are local, and get defaultCountryCode in place of their leading zeros when
+// it is set.
+func NormalizePhoneNumber(phone, defaultCountryCode string) (string, error) {
+	trimmed := strings.TrimSpace(phone)
+	digits := NormalizePhoneDigits(trimmed)
+	if strings.HasPrefix(digits, "00") {
+		digits = digits[2:]
+	} else if !strings.HasPrefix(trimmed, "+") && strings.HasPrefix(digits, "0") {
+		digits = NormalizePhoneDigits(defaultCountryCode) + strings.TrimLeft(digits, "0")
+	}
+
+	if len(digits) < minPhoneNumberDigits || len(digits) > maxPhoneNumberDigits {
+		return "", fmt.Errorf("%q is not a phone number: it must have %d to %d digits with the country code", phone, minPhoneNumberDigits, maxPhoneNumberDigits)
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("%q is not a phone number: it must only contain digits", phone)
+		}
+	}
+	return digits, nil
+}
+
 // StripPhonePrefix removes + prefix from phone number.
 func StripPhonePrefix(phone string) string {
 	return strings.TrimPrefix(strings.TrimSpace(phone), "+")
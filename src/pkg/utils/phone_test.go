@@ -0,0 +1,42 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePhoneDigits(t *testing.T) {
+	assert.Equal(t, "628123456789", utils.NormalizePhoneDigits("+62 812-3456 (789)"))
+	assert.Equal(t, "628123456789", utils.NormalizePhoneDigits("62.812.3456.789"))
+	assert.Equal(t, "62812a", utils.NormalizePhoneDigits("+62 812a"))
+	assert.Equal(t, "", utils.NormalizePhoneDigits(" + "))
+}
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		phone, countryCode, want string
+	}{
+		{"+62 812-3456-789", "", "628123456789"},
+		{"628123456789", "", "628123456789"},
+		{"0062 812 3456 789", "44", "628123456789"},
+		{"0812-3456-789", "62", "628123456789"},
+		{"00812", "+62", ""},
+		{"0812-3456-789", "", "8123456789"},
+	}
+	for _, tt := range tests {
+		got, err := utils.NormalizePhoneNumber(tt.phone, tt.countryCode)
+		if tt.want == "" {
+			assert.Error(t, err, tt.phone)
+			continue
+		}
+		assert.NoError(t, err, tt.phone)
+		assert.Equal(t, tt.want, got, tt.phone)
+	}
+
+	_, err := utils.NormalizePhoneNumber("62812abc789", "")
+	assert.EqualError(t, err, `"62812abc789" is not a phone number: it must only contain digits`)
+	_, err = utils.NormalizePhoneNumber("1234567890123456", "")
+	assert.EqualError(t, err, `"1234567890123456" is not a phone number: it must have 7 to 15 digits with the country code`)
+}
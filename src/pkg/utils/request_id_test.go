@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIDHook(t *testing.T) {
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&out)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(RequestIDHook{})
+
+	ctx := ContextWithRequestID(context.Background(), "req-1")
+	assert.Equal(t, "req-1", RequestIDFromContext(ctx))
+	assert.Empty(t, RequestIDFromContext(context.Background()))
+
+	logger.WithContext(ctx).Warn("sent")
+	assert.Contains(t, out.String(), `"request_id":"req-1"`)
+
+	out.Reset()
+	logger.WithContext(context.Background()).Warn("sent")
+	assert.NotContains(t, out.String(), "request_id")
+}
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VCard holds the fields of a vCard that are useful without a full parser.
+type VCard struct {
+	// DisplayName is the formatted name (FN), or the structured name (N) when
+	// the card has no formatted name
+	DisplayName string
+	// PhoneNumbers are the TEL values in the order of the card, as written
+	PhoneNumbers []string
+	// Organization is the company (first ORG component), if any
+	Organization string
+}
+
+// ParseVCard extracts the display name and phone numbers of a vCard as shared
+// in WhatsApp contact messages. Unknown properties are ignored.
+func ParseVCard(vcard string) VCard {
+	var card VCard
+	var structuredName string
+
+	for _, line := range unfoldVCardLines(vcard) {
+		property, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		// Drop parameters (TEL;type=CELL;waid=...) and groups (item1.TEL)
+		name, _, _ := strings.Cut(property, ";")
+		if _, after, grouped := strings.Cut(name, "."); grouped {
+			name = after
+		}
+
+		switch strings.ToUpper(strings.TrimSpace(name)) {
+		case "FN":
+			card.DisplayName = unescapeVCardValue(strings.TrimSpace(value))
+		case "N":
+			// Family;Given;Additional;Prefix;Suffix
+			parts := strings.Split(value, ";")
+			ordered := make([]string, 0, len(parts))
+			for _, i := range []int{3, 1, 2, 0, 4} {
+				if i < len(parts) && strings.TrimSpace(parts[i]) != "" {
+					ordered = append(ordered, unescapeVCardValue(strings.TrimSpace(parts[i])))
+				}
+			}
+			structuredName = strings.Join(ordered, " ")
+		case "ORG":
+			card.Organization = unescapeVCardValue(strings.TrimSpace(firstVCardComponent(value)))
+		case "TEL":
+			phone := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), "tel:"))
+			if phone != "" {
+				card.PhoneNumbers = append(card.PhoneNumbers, phone)
+			}
+		}
+	}
+
+	if card.DisplayName == "" {
+		card.DisplayName = structuredName
+	}
+	return card
+}
+
+// unfoldVCardLines splits a vCard into lines, joining folded continuation
+// lines, which start with a space or tab, to the line before.
+func unfoldVCardLines(vcard string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(vcard, "\r\n", "\n"), "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, "\r"))
+	}
+	return lines
+}
+
+var vCardValueReplacer = strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ", `\\`, `\`)
+
+func unescapeVCardValue(value string) string {
+	return vCardValueReplacer.Replace(value)
+}
+
+// firstVCardComponent returns value up to its first unescaped semicolon.
+func firstVCardComponent(value string) string {
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case ';':
+			return value[:i]
+		}
+	}
+	return value
+}
+
+var vCardEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`)
+
+// BuildVCard renders card as the vCard 3.0 WhatsApp puts in contact messages.
+// Phone numbers are expected as international digits; each gets a waid
+// parameter so the recipient can message the contact from the card.
+func BuildVCard(card VCard) string {
+	name := vCardEscaper.Replace(card.DisplayName)
+
+	var b strings.Builder
+	b.WriteString("BEGIN:VCARD\nVERSION:3.0\n")
+	fmt.Fprintf(&b, "N:;%s;;;\nFN:%s\n", name, name)
+	if card.Organization != "" {
+		fmt.Fprintf(&b, "ORG:%s;\n", vCardEscaper.Replace(card.Organization))
+	}
+	for _, phone := range card.PhoneNumbers {
+		fmt.Fprintf(&b, "TEL;type=CELL;waid=%s:+%s\n", phone, phone)
+	}
+	b.WriteString("END:VCARD")
+	return b.String()
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVCard(t *testing.T) {
+	tests := []struct {
+		name  string
+		vcard string
+		want  VCard
+	}{
+		{
+			name:  "WhatsAppCard",
+			vcard: "BEGIN:VCARD\nVERSION:3.0\nN:;3Care;;;\nFN:3Care\nTEL;type=CELL;waid=62132:+62 132\nEND:VCARD",
+			want:  VCard{DisplayName: "3Care", PhoneNumbers: []string{"+62 132"}},
+		},
+		{
+			name: "GroupedPhonesAndCRLF",
+			vcard: "BEGIN:VCARD\r\nVERSION:3.0\r\nFN:Doe\\, John\r\n" +
+				"item1.TEL;waid=6281234567890:+62 812-3456-7890\r\nitem1.X-ABLabel:Mobile\r\n" +
+				"TEL;type=WORK:+62 21 555 0100\r\nEND:VCARD",
+			want: VCard{DisplayName: "Doe, John", PhoneNumbers: []string{"+62 812-3456-7890", "+62 21 555 0100"}},
+		},
+		{
+			name:  "StructuredNameFallbackAndFolding",
+			vcard: "BEGIN:VCARD\nVERSION:4.0\nN:Doe;Jane;;Dr.;\nTEL;VALUE=uri:tel:+1-555\n -0100\nEND:VCARD",
+			want:  VCard{DisplayName: "Dr. Jane Doe", PhoneNumbers: []string{"+1-555-0100"}},
+		},
+		{
+			name:  "Empty",
+			vcard: "",
+			want:  VCard{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseVCard(tt.vcard))
+		})
+	}
+}
+
+func TestBuildVCard(t *testing.T) {
+	card := VCard{
+		DisplayName:  "Doe, John; Jr",
+		PhoneNumbers: []string{"6281234567890", "14155550100"},
+		Organization: "Acme; Inc",
+	}
+
+	vcard := BuildVCard(card)
+	assert.Equal(t, "BEGIN:VCARD\nVERSION:3.0\n"+
+		"N:;Doe\\, John\\; Jr;;;\nFN:Doe\\, John\\; Jr\n"+
+		"ORG:Acme\\; Inc;\n"+
+		"TEL;type=CELL;waid=6281234567890:+6281234567890\n"+
+		"TEL;type=CELL;waid=14155550100:+14155550100\n"+
+		"END:VCARD", vcard)
+
+	assert.Equal(t, VCard{
+		DisplayName:  "Doe, John; Jr",
+		PhoneNumbers: []string{"+6281234567890", "+14155550100"},
+		Organization: "Acme; Inc",
+	}, ParseVCard(vcard))
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhookSignatureTolerance is how far the X-Webhook-Timestamp of a delivery
+// may drift from the receiver's clock before VerifyWebhookSignature rejects it.
+const WebhookSignatureTolerance = 5 * time.Minute
+
+var (
+	ErrWebhookSignatureMismatch = errors.New("webhook signature does not match")
+	ErrWebhookTimestampInvalid  = errors.New("webhook timestamp is not a unix time in seconds")
+	ErrWebhookTimestampExpired  = errors.New("webhook timestamp is outside the allowed clock skew")
+)
+
+// SignWebhookPayload returns the hex HMAC-SHA256 of timestamp + "." + body,
+// the value sent in X-Hub-Signature-256 after the "sha256=" prefix.
+func SignWebhookPayload(secret, timestamp string, body []byte) (string, error) {
+	signed := make([]byte, 0, len(timestamp)+1+len(body))
+	signed = append(signed, timestamp...)
+	signed = append(signed, '.')
+	signed = append(signed, body...)
+	return GetMessageDigestOrSignature(signed, []byte(secret))
+}
+
+// VerifyWebhookSignature checks a webhook delivery against the shared secret.
+// timestamp and signature are the X-Webhook-Timestamp and X-Hub-Signature-256
+// headers, with or without the "sha256=" prefix, and body is the raw request
+// body. Deliveries older or newer than WebhookSignatureTolerance are rejected
+// so a captured request cannot be replayed later.
+func VerifyWebhookSignature(secret, timestamp string, body []byte, signature string) error {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrWebhookTimestampInvalid
+	}
+	if skew := time.Since(time.Unix(unix, 0)); skew > WebhookSignatureTolerance || skew < -WebhookSignatureTolerance {
+		return ErrWebhookTimestampExpired
+	}
+
+	expected, err := SignWebhookPayload(secret, timestamp, body)
+	if err != nil {
+		return err
+	}
+	received, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return ErrWebhookSignatureMismatch
+	}
+	expectedBytes, _ := hex.DecodeString(expected)
+	if !hmac.Equal(expectedBytes, received) {
+		return ErrWebhookSignatureMismatch
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package utils_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	// HMAC-SHA256("secret", "1700000000.{}")
+	signature, err := utils.SignWebhookPayload("secret", "1700000000", []byte("{}"))
+	require.NoError(t, err)
+	plain, err := utils.GetMessageDigestOrSignature([]byte("1700000000.{}"), []byte("secret"))
+	require.NoError(t, err)
+	assert.Equal(t, plain, signature)
+
+	bodyOnly, err := utils.GetMessageDigestOrSignature([]byte("{}"), []byte("secret"))
+	require.NoError(t, err)
+	assert.NotEqual(t, bodyOnly, signature, "the timestamp must be part of the signed content")
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"event":"message","payload":{"id":"3EB0A1"}}`)
+	unixAt := func(offset time.Duration) string {
+		return strconv.FormatInt(time.Now().Add(offset).Unix(), 10)
+	}
+	sign := func(secret, timestamp string) string {
+		signature, err := utils.SignWebhookPayload(secret, timestamp, body)
+		require.NoError(t, err)
+		return signature
+	}
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature func(timestamp string) string
+		body      []byte
+		wantErr   error
+	}{
+		{name: "fresh", timestamp: unixAt(0), signature: func(ts string) string { return "sha256=" + sign("secret", ts) }},
+		{name: "without prefix", timestamp: unixAt(0), signature: func(ts string) string { return sign("secret", ts) }},
+		{name: "within past skew", timestamp: unixAt(-4 * time.Minute), signature: func(ts string) string { return sign("secret", ts) }},
+		{name: "within future skew", timestamp: unixAt(4 * time.Minute), signature: func(ts string) string { return sign("secret", ts) }},
+		{name: "too old", timestamp: unixAt(-6 * time.Minute), signature: func(ts string) string { return sign("secret", ts) }, wantErr: utils.ErrWebhookTimestampExpired},
+		{name: "too far ahead", timestamp: unixAt(6 * time.Minute), signature: func(ts string) string { return sign("secret", ts) }, wantErr: utils.ErrWebhookTimestampExpired},
+		{name: "not a unix time", timestamp: "yesterday", signature: func(ts string) string { return sign("secret", ts) }, wantErr: utils.ErrWebhookTimestampInvalid},
+		{name: "wrong secret", timestamp: unixAt(0), signature: func(ts string) string { return sign("other", ts) }, wantErr: utils.ErrWebhookSignatureMismatch},
+		{name: "tampered body", timestamp: unixAt(0), signature: func(ts string) string { return sign("secret", ts) }, body: []byte(`{"event":"message"}`), wantErr: utils.ErrWebhookSignatureMismatch},
+		{name: "replayed with new timestamp", timestamp: unixAt(0), signature: func(string) string { return sign("secret", unixAt(-10*time.Minute)) }, wantErr: utils.ErrWebhookSignatureMismatch},
+		{name: "not hex", timestamp: unixAt(0), signature: func(string) string { return "sha256=zz" }, wantErr: utils.ErrWebhookSignatureMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received := body
+			if tt.body != nil {
+				received = tt.body
+			}
+			err := utils.VerifyWebhookSignature("secret", tt.timestamp, received, tt.signature(tt.timestamp))
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -66,6 +66,32 @@ func KnownDocumentExtensionByMIME(mimeType string) (string, bool) {
 	return resolveKnownDocumentExtension(mimeType)
 }
 
+// defaultMediaExtensions are used when neither the filename nor the MIME type
+// of an attachment yields a usable extension.
+var defaultMediaExtensions = map[string]string{
+	"image":      ".jpg",
+	"video":      ".mp4",
+	"video_note": ".mp4",
+	"audio":      ".ogg",
+	"sticker":    ".webp",
+}
+
+// MediaFileExtension returns the extension, with its dot, of the local copy of
+// an attachment of mediaType. Extensions that are not plain alphanumerics, like
+// the ".ogg; codecs=opus" derived from some MIME types, are not trusted.
+func MediaFileExtension(mediaType, filename, mimeType string) string {
+	ext := determineMediaExtension(filename, mimeType)
+	if len(ext) > 1 && strings.IndexFunc(ext[1:], func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9')
+	}) == -1 {
+		return strings.ToLower(ext)
+	}
+	if ext, ok := defaultMediaExtensions[mediaType]; ok {
+		return ext
+	}
+	return ".bin"
+}
+
 func determineMediaExtension(originalFilename, mimeType string) string {
 	if originalFilename != "" {
 		if ext := filepath.Ext(originalFilename); ext != "" {
@@ -90,6 +116,7 @@ func determineMediaExtension(originalFilename, mimeType string) string {
 
 // ExtractMessageTextFromProto extracts text content from a WhatsApp proto message
 func ExtractMessageTextFromProto(msg *waE2E.Message) string {
+	msg = UnwrapMessage(msg)
 	if msg == nil {
 		return ""
 	}
@@ -134,9 +161,77 @@ func ExtractMessageTextFromProto(msg *waE2E.Message) string {
 		return templateButtonReply.GetSelectedDisplayText()
 	}
 
+	// Polls are stored under their question; see ExtractPollCreation for the options
+	if poll := ExtractPollCreation(msg); poll != nil {
+		return poll.GetName()
+	}
+
 	return ""
 }
 
+// ExtractPollCreation returns the poll a message creates, whichever version of
+// the poll creation message carries it, or nil.
+func ExtractPollCreation(msg *waE2E.Message) *waE2E.PollCreationMessage {
+	msg = UnwrapMessage(msg)
+	if msg == nil {
+		return nil
+	}
+	for _, poll := range []*waE2E.PollCreationMessage{
+		msg.GetPollCreationMessage(),
+		msg.GetPollCreationMessageV2(),
+		msg.GetPollCreationMessageV3(),
+		msg.GetPollCreationMessageV5(),
+	} {
+		if poll != nil {
+			return poll
+		}
+	}
+	return nil
+}
+
+// PollOptionNames returns the option names of a poll in order.
+func PollOptionNames(poll *waE2E.PollCreationMessage) []string {
+	names := make([]string, 0, len(poll.GetOptions()))
+	for _, option := range poll.GetOptions() {
+		names = append(names, option.GetOptionName())
+	}
+	return names
+}
+
+// ExtractContextInfo returns the ContextInfo carried by the content of a
+// message, such as the quoted message of a reply, or nil if it has none.
+func ExtractContextInfo(msg *waE2E.Message) *waE2E.ContextInfo {
+	msg = UnwrapMessage(msg)
+	if msg == nil {
+		return nil
+	}
+
+	candidates := []interface{ GetContextInfo() *waE2E.ContextInfo }{
+		msg.GetExtendedTextMessage(),
+		msg.GetImageMessage(),
+		msg.GetVideoMessage(),
+		msg.GetAudioMessage(),
+		msg.GetDocumentMessage(),
+		msg.GetStickerMessage(),
+		msg.GetPtvMessage(),
+		msg.GetContactMessage(),
+		msg.GetLocationMessage(),
+	}
+	for _, candidate := range candidates {
+		// Typed nil pointers are still non-nil interfaces; the getter handles them
+		if contextInfo := candidate.GetContextInfo(); contextInfo != nil {
+			return contextInfo
+		}
+	}
+	return nil
+}
+
+// ExtractReplyContext returns the ID and sender JID of the message a reply quotes.
+func ExtractReplyContext(msg *waE2E.Message) (quotedID string, quotedSender string) {
+	contextInfo := ExtractContextInfo(msg)
+	return contextInfo.GetStanzaID(), contextInfo.GetParticipant()
+}
+
 // ExtractMessageTextFromEvent extracts text content from a WhatsApp event message with emojis
 func ExtractMessageTextFromEvent(evt *events.Message) string {
 	messageText := evt.Message.GetConversation()
@@ -253,6 +348,7 @@ func ExtractMessageTextFromEvent(evt *events.Message) string {
 
 // ExtractMediaInfo extracts media information from a WhatsApp message
 func ExtractMediaInfo(msg *waE2E.Message) (mediaType string, filename string, url string, mediaKey []byte, fileSHA256 []byte, fileEncSHA256 []byte, fileLength uint64) {
+	msg = UnwrapMessage(msg)
 	if msg == nil {
 		return "", "", "", nil, nil, nil, 0
 	}
@@ -312,6 +408,19 @@ func ExtractMediaInfo(msg *waE2E.Message) (mediaType string, filename string, ur
 			sticker.GetFileEncSHA256(), sticker.GetFileLength()
 	}
 
+	// Locations have no file to download, their coordinates are extracted separately
+	if msg.GetLiveLocationMessage() != nil {
+		return "live_location", "", "", nil, nil, nil, 0
+	}
+	if msg.GetLocationMessage() != nil {
+		return "location", "", "", nil, nil, nil, 0
+	}
+
+	// Contacts are stored as parsed cards, a contacts array as one message
+	if msg.GetContactMessage() != nil || msg.GetContactsArrayMessage() != nil {
+		return "contact", "", "", nil, nil, nil, 0
+	}
+
 	return "", "", "", nil, nil, nil, 0
 }
 
@@ -611,40 +720,23 @@ func SanitizePhone(phone *string) {
 func IsOnWhatsapp(client *whatsmeow.Client, jid string) bool {
 	// only check if the jid is a user with @s.whatsapp.net
 	if strings.Contains(jid, "@s.whatsapp.net") {
-		// Extract phone number from JID and add + prefix for international format
-		phone := strings.TrimSuffix(jid, "@s.whatsapp.net")
+		// Extract phone number from JID, without the + prefix CheckOnWhatsApp adds
+		phone := StripPhonePrefix(strings.TrimSuffix(jid, "@s.whatsapp.net"))
 		if phone == "" {
 			return false
 		}
 
-		// whatsmeow expects international format with + prefix
-		if !strings.HasPrefix(phone, "+") {
-			phone = "+" + phone
-		}
-
 		// Add timeout to prevent indefinite blocking
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		data, err := client.IsOnWhatsApp(ctx, []string{phone})
+		results, err := CheckOnWhatsApp(ctx, client, []string{phone})
 		if err != nil {
 			logrus.Error("Failed to check if user is on whatsapp: ", err)
 			return false
 		}
 
-		// Empty response means number not found/invalid
-		if len(data) == 0 {
-			return false
-		}
-
-		// Check if any result indicates the number is NOT on WhatsApp
-		for _, v := range data {
-			if !v.IsIn {
-				return false
-			}
-		}
-
-		return true
+		return results[phone].Registered
 	}
 
 	// For non-user JIDs (groups, newsletters), skip validation
@@ -804,6 +896,23 @@ func UnwrapMessage(msg *waE2E.Message) *waE2E.Message {
 	return inner
 }
 
+// IsViewOnceMessage reports whether msg is a view-once message, either wrapped
+// in one of the view-once containers or flagged on the media itself.
+func IsViewOnceMessage(msg *waE2E.Message) bool {
+	inner := msg
+	for i := 0; i < 3 && inner != nil; i++ {
+		if inner.GetViewOnceMessage() != nil || inner.GetViewOnceMessageV2() != nil || inner.GetViewOnceMessageV2Extension() != nil {
+			return true
+		}
+		if em := inner.GetEphemeralMessage(); em != nil && em.GetMessage() != nil {
+			inner = em.GetMessage()
+			continue
+		}
+		break
+	}
+	return inner.GetImageMessage().GetViewOnce() || inner.GetVideoMessage().GetViewOnce() || inner.GetAudioMessage().GetViewOnce()
+}
+
 // BuildEventMessage builds event message structure
 func BuildEventMessage(evt *events.Message) (message EvtMessage) {
 	msg := UnwrapMessage(evt.Message)
@@ -850,3 +959,27 @@ func BuildForwarded(evt *events.Message) bool {
 	}
 	return false
 }
+
+// groupInviteCode matches the code of a group invite link.
+var groupInviteCode = regexp.MustCompile(`^[A-Za-z0-9]{6,32}$`)
+
+// GroupInviteCode extracts the code from a group invite link, given as a
+// chat.whatsapp.com URL with or without scheme, or as the bare code.
+func GroupInviteCode(link string) (string, bool) {
+	code := strings.TrimSpace(link)
+	for _, prefix := range []string{"https://", "http://"} {
+		code = strings.TrimPrefix(code, prefix)
+	}
+	if host, rest, found := strings.Cut(code, "/"); found {
+		if !strings.EqualFold(host, "chat.whatsapp.com") {
+			return "", false
+		}
+		code = strings.TrimPrefix(rest, "invite/")
+	}
+	code, _, _ = strings.Cut(code, "?")
+	code = strings.TrimSuffix(code, "/")
+	if !groupInviteCode.MatchString(code) {
+		return "", false
+	}
+	return code, true
+}
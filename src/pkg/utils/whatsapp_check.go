@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// onWhatsAppChunkSize is how many phones one IsOnWhatsApp query asks about.
+const onWhatsAppChunkSize = 50
+
+// onWhatsAppCacheSweepSize is the cache size above which expired entries are
+// dropped when new ones are stored.
+const onWhatsAppCacheSweepSize = 10000
+
+// isOnWhatsAppFn is swapped in tests to avoid a live WhatsApp connection.
+var isOnWhatsAppFn = func(ctx context.Context, client *whatsmeow.Client, phones []string) ([]types.IsOnWhatsAppResponse, error) {
+	return client.IsOnWhatsApp(ctx, phones)
+}
+
+// OnWhatsApp is whether a phone is registered on WhatsApp and, when it is,
+// the canonical JID WhatsApp returned for it.
+type OnWhatsApp struct {
+	Registered bool
+	JID        types.JID
+}
+
+type onWhatsAppEntry struct {
+	OnWhatsApp
+	expires time.Time
+}
+
+var onWhatsAppCache = struct {
+	sync.Mutex
+	entries map[string]onWhatsAppEntry
+}{entries: make(map[string]onWhatsAppEntry)}
+
+// CheckOnWhatsApp looks up whether phones, given as digits with their country
+// code, are registered on WhatsApp. Answers are cached for
+// config.WhatsappCheckCacheTTL, so repeated checks of a campaign's numbers
+// only query WhatsApp once; the rest are asked about in chunks. Phones missing
+// from the result could not be checked because a query failed, whose error is
+// returned along with whatever was answered.
+func CheckOnWhatsApp(ctx context.Context, client *whatsmeow.Client, phones []string) (map[string]OnWhatsApp, error) {
+	results := make(map[string]OnWhatsApp, len(phones))
+	now := time.Now()
+
+	var missing []string
+	onWhatsAppCache.Lock()
+	for _, phone := range phones {
+		if entry, ok := onWhatsAppCache.entries[phone]; ok && now.Before(entry.expires) {
+			results[phone] = entry.OnWhatsApp
+			continue
+		}
+		if !slices.Contains(missing, phone) {
+			missing = append(missing, phone)
+		}
+	}
+	onWhatsAppCache.Unlock()
+
+	for start := 0; start < len(missing); start += onWhatsAppChunkSize {
+		chunk := missing[start:min(start+onWhatsAppChunkSize, len(missing))]
+		queries := make([]string, len(chunk))
+		for i, phone := range chunk {
+			queries[i] = "+" + phone
+		}
+
+		answers, err := isOnWhatsAppFn(ctx, client, queries)
+		if err != nil {
+			return results, err
+		}
+
+		// Numbers WhatsApp doesn't answer about are not registered
+		checked := make(map[string]OnWhatsApp, len(chunk))
+		for _, phone := range chunk {
+			checked[phone] = OnWhatsApp{}
+		}
+		for _, answer := range answers {
+			phone := StripPhonePrefix(answer.Query)
+			if _, asked := checked[phone]; asked && answer.IsIn {
+				checked[phone] = OnWhatsApp{Registered: true, JID: answer.JID.ToNonAD()}
+			}
+		}
+		storeOnWhatsApp(checked, time.Now())
+		for phone, result := range checked {
+			results[phone] = result
+		}
+	}
+	return results, nil
+}
+
+func storeOnWhatsApp(checked map[string]OnWhatsApp, now time.Time) {
+	if config.WhatsappCheckCacheTTL <= 0 {
+		return
+	}
+	expires := now.Add(config.WhatsappCheckCacheTTL)
+
+	onWhatsAppCache.Lock()
+	defer onWhatsAppCache.Unlock()
+	if len(onWhatsAppCache.entries) > onWhatsAppCacheSweepSize {
+		for phone, entry := range onWhatsAppCache.entries {
+			if !now.Before(entry.expires) {
+				delete(onWhatsAppCache.entries, phone)
+			}
+		}
+	}
+	for phone, result := range checked {
+		onWhatsAppCache.entries[phone] = onWhatsAppEntry{OnWhatsApp: result, expires: expires}
+	}
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// stubIsOnWhatsApp answers that phones ending in an even digit are
+// registered, and records the size of every query.
+func stubIsOnWhatsApp(t *testing.T) *[]int {
+	var queries []int
+	original := isOnWhatsAppFn
+	t.Cleanup(func() {
+		isOnWhatsAppFn = original
+		onWhatsAppCache.entries = make(map[string]onWhatsAppEntry)
+	})
+	isOnWhatsAppFn = func(_ context.Context, _ *whatsmeow.Client, phones []string) ([]types.IsOnWhatsAppResponse, error) {
+		queries = append(queries, len(phones))
+		answers := make([]types.IsOnWhatsAppResponse, 0, len(phones))
+		for _, phone := range phones {
+			if (phone[len(phone)-1]-'0')%2 == 0 {
+				answers = append(answers, types.IsOnWhatsAppResponse{Query: phone, JID: types.NewJID(phone[1:], types.DefaultUserServer), IsIn: true})
+			}
+		}
+		return answers, nil
+	}
+	return &queries
+}
+
+func TestCheckOnWhatsApp(t *testing.T) {
+	queries := stubIsOnWhatsApp(t)
+
+	phones := make([]string, 0, 120)
+	for i := range 120 {
+		phones = append(phones, fmt.Sprintf("62812000%04d", i))
+	}
+	results, err := CheckOnWhatsApp(context.Background(), nil, append(phones, phones[0]))
+	require.NoError(t, err)
+	assert.Equal(t, []int{50, 50, 20}, *queries, "asked in chunks, repeated phones once")
+	assert.Len(t, results, 120)
+	assert.Equal(t, OnWhatsApp{Registered: true, JID: types.NewJID("628120000000", types.DefaultUserServer)}, results["628120000000"])
+	assert.False(t, results["628120000001"].Registered, "not answered about")
+
+	results, err = CheckOnWhatsApp(context.Background(), nil, []string{"628120000002", "628120000999"})
+	require.NoError(t, err)
+	assert.Equal(t, []int{50, 50, 20, 1}, *queries, "cached phones aren't asked about again")
+	assert.True(t, results["628120000002"].Registered)
+}
+
+func TestCheckOnWhatsApp_CacheExpiry(t *testing.T) {
+	queries := stubIsOnWhatsApp(t)
+	defaultTTL := config.WhatsappCheckCacheTTL
+	t.Cleanup(func() { config.WhatsappCheckCacheTTL = defaultTTL })
+
+	config.WhatsappCheckCacheTTL = 0
+	_, err := CheckOnWhatsApp(context.Background(), nil, []string{"628120000002"})
+	require.NoError(t, err)
+	_, err = CheckOnWhatsApp(context.Background(), nil, []string{"628120000002"})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 1}, *queries, "a zero TTL disables the cache")
+
+	config.WhatsappCheckCacheTTL = time.Hour
+	storeOnWhatsApp(map[string]OnWhatsApp{"628120000004": {Registered: true}}, time.Now().Add(-2*time.Hour))
+	_, err = CheckOnWhatsApp(context.Background(), nil, []string{"628120000004"})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 1, 1}, *queries, "expired answers are asked again")
+}
+
+func TestCheckOnWhatsApp_Error(t *testing.T) {
+	stubIsOnWhatsApp(t)
+	isOnWhatsAppFn = func(context.Context, *whatsmeow.Client, []string) ([]types.IsOnWhatsAppResponse, error) {
+		return nil, errors.New("timed out")
+	}
+
+	storeOnWhatsApp(map[string]OnWhatsApp{"628120000002": {Registered: true}}, time.Now())
+	results, err := CheckOnWhatsApp(context.Background(), nil, []string{"628120000002", "628120000004"})
+	assert.EqualError(t, err, "timed out")
+	assert.Equal(t, map[string]OnWhatsApp{"628120000002": {Registered: true}}, results, "cached answers are still returned")
+	assert.NotContains(t, onWhatsAppCache.entries, "628120000004", "failures aren't cached")
+}
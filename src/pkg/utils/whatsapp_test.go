@@ -1,6 +1,11 @@
 package utils
 
-import "testing"
+import (
+	"testing"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+)
 
 func TestDetermineMediaExtension(t *testing.T) {
 	tests := []struct {
@@ -50,3 +55,203 @@ func TestDetermineMediaExtension(t *testing.T) {
 		})
 	}
 }
+
+func TestMediaFileExtension(t *testing.T) {
+	tests := []struct {
+		name      string
+		mediaType string
+		filename  string
+		mimeType  string
+		want      string
+	}{
+		{name: "FromFilename", mediaType: "document", filename: "Report.PDF", mimeType: "application/pdf", want: ".pdf"},
+		{name: "FromMime", mediaType: "image", mimeType: "image/png", want: ".png"},
+		{name: "MimeParametersFallBackToType", mediaType: "audio", mimeType: "audio/x-custom; codecs=opus", want: ".ogg"},
+		{name: "TypeDefault", mediaType: "sticker", want: ".webp"},
+		{name: "Unknown", mediaType: "document", want: ".bin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MediaFileExtension(tt.mediaType, tt.filename, tt.mimeType); got != tt.want {
+				t.Fatalf("MediaFileExtension() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractReplyContext(t *testing.T) {
+	contextInfo := &waE2E.ContextInfo{
+		StanzaID:    proto.String("3EB0QUOTED"),
+		Participant: proto.String("628123@s.whatsapp.net"),
+	}
+	tests := []struct {
+		name       string
+		msg        *waE2E.Message
+		wantID     string
+		wantSender string
+	}{
+		{
+			name:       "ExtendedText",
+			msg:        &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{Text: proto.String("yes"), ContextInfo: contextInfo}},
+			wantID:     "3EB0QUOTED",
+			wantSender: "628123@s.whatsapp.net",
+		},
+		{
+			name: "EphemeralImage",
+			msg: &waE2E.Message{EphemeralMessage: &waE2E.FutureProofMessage{Message: &waE2E.Message{
+				ImageMessage: &waE2E.ImageMessage{ContextInfo: contextInfo},
+			}}},
+			wantID:     "3EB0QUOTED",
+			wantSender: "628123@s.whatsapp.net",
+		},
+		{
+			name: "NotAReply",
+			msg:  &waE2E.Message{Conversation: proto.String("hello")},
+		},
+		{
+			name: "Nil",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, sender := ExtractReplyContext(tt.msg)
+			if id != tt.wantID || sender != tt.wantSender {
+				t.Fatalf("expected (%q, %q), got (%q, %q)", tt.wantID, tt.wantSender, id, sender)
+			}
+		})
+	}
+}
+
+func TestExtractPollCreation(t *testing.T) {
+	poll := &waE2E.PollCreationMessage{
+		Name: proto.String("Lunch?"),
+		Options: []*waE2E.PollCreationMessage_Option{
+			{OptionName: proto.String("Pizza")},
+			{OptionName: proto.String("Sushi")},
+		},
+		SelectableOptionsCount: proto.Uint32(1),
+	}
+
+	for name, msg := range map[string]*waE2E.Message{
+		"V1":        {PollCreationMessage: poll},
+		"V3":        {PollCreationMessageV3: poll},
+		"Ephemeral": {EphemeralMessage: &waE2E.FutureProofMessage{Message: &waE2E.Message{PollCreationMessageV3: poll}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := ExtractPollCreation(msg); got != poll {
+				t.Fatalf("ExtractPollCreation() = %v, want the poll", got)
+			}
+			if got := ExtractMessageTextFromProto(msg); got != "Lunch?" {
+				t.Errorf("ExtractMessageTextFromProto() = %q, want the question", got)
+			}
+		})
+	}
+
+	names := PollOptionNames(poll)
+	if len(names) != 2 || names[0] != "Pizza" || names[1] != "Sushi" {
+		t.Errorf("PollOptionNames() = %v", names)
+	}
+	if ExtractPollCreation(&waE2E.Message{Conversation: proto.String("hi")}) != nil {
+		t.Error("ExtractPollCreation() returned a poll for a text message")
+	}
+}
+
+func TestViewOnceMessages(t *testing.T) {
+	image := &waE2E.ImageMessage{
+		URL:        proto.String("https://mmg.whatsapp.net/v/t62/image"),
+		Caption:    proto.String("once"),
+		MediaKey:   []byte("key"),
+		FileLength: proto.Uint64(1024),
+		ViewOnce:   proto.Bool(true),
+	}
+	video := &waE2E.VideoMessage{
+		URL:        proto.String("https://mmg.whatsapp.net/v/t62/video"),
+		Caption:    proto.String("clip"),
+		FileLength: proto.Uint64(2048),
+	}
+
+	tests := []struct {
+		name        string
+		msg         *waE2E.Message
+		wantType    string
+		wantCaption string
+		wantURL     string
+	}{
+		{
+			name:        "V1",
+			msg:         &waE2E.Message{ViewOnceMessage: &waE2E.FutureProofMessage{Message: &waE2E.Message{ImageMessage: image}}},
+			wantType:    "image",
+			wantCaption: "once",
+			wantURL:     image.GetURL(),
+		},
+		{
+			name:        "V2",
+			msg:         &waE2E.Message{ViewOnceMessageV2: &waE2E.FutureProofMessage{Message: &waE2E.Message{VideoMessage: video}}},
+			wantType:    "video",
+			wantCaption: "clip",
+			wantURL:     video.GetURL(),
+		},
+		{
+			name: "V2InsideEphemeral",
+			msg: &waE2E.Message{EphemeralMessage: &waE2E.FutureProofMessage{Message: &waE2E.Message{
+				ViewOnceMessageV2: &waE2E.FutureProofMessage{Message: &waE2E.Message{VideoMessage: video}},
+			}}},
+			wantType:    "video",
+			wantCaption: "clip",
+			wantURL:     video.GetURL(),
+		},
+		{
+			name:        "FlaggedMedia",
+			msg:         &waE2E.Message{ImageMessage: image},
+			wantType:    "image",
+			wantCaption: "once",
+			wantURL:     image.GetURL(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !IsViewOnceMessage(tt.msg) {
+				t.Error("IsViewOnceMessage() = false, want true")
+			}
+			if got := ExtractMessageTextFromProto(tt.msg); got != tt.wantCaption {
+				t.Errorf("ExtractMessageTextFromProto() = %q, want %q", got, tt.wantCaption)
+			}
+			mediaType, _, url, _, _, _, _ := ExtractMediaInfo(tt.msg)
+			if mediaType != tt.wantType || url != tt.wantURL {
+				t.Errorf("ExtractMediaInfo() = (%q, %q), want (%q, %q)", mediaType, url, tt.wantType, tt.wantURL)
+			}
+		})
+	}
+
+	if IsViewOnceMessage(&waE2E.Message{VideoMessage: video}) || IsViewOnceMessage(nil) {
+		t.Error("IsViewOnceMessage() = true for a regular message")
+	}
+}
+
+func TestGroupInviteCode(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{link: "https://chat.whatsapp.com/FxKz3pQw7VbL9mN2cR4tYh", want: "FxKz3pQw7VbL9mN2cR4tYh"},
+		{link: "chat.whatsapp.com/FxKz3pQw7VbL9mN2cR4tYh/", want: "FxKz3pQw7VbL9mN2cR4tYh"},
+		{link: " http://chat.whatsapp.com/invite/FxKz3pQw7VbL9mN2cR4tYh?mode=r_c ", want: "FxKz3pQw7VbL9mN2cR4tYh"},
+		{link: "FxKz3pQw7VbL9mN2cR4tYh", want: "FxKz3pQw7VbL9mN2cR4tYh"},
+		{link: "https://example.com/FxKz3pQw7VbL9mN2cR4tYh"},
+		{link: "https://chat.whatsapp.com/"},
+		{link: "https://chat.whatsapp.com/not a code"},
+		{link: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			got, ok := GroupInviteCode(tt.link)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("GroupInviteCode(%q) = (%q, %v), want %q", tt.link, got, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Package webhooks describes the JSON bodies posted to the configured webhook
+// URLs, so consumers written in Go can unmarshal them directly:
+//
+//	var envelope webhooks.Envelope
+//	_ = json.Unmarshal(body, &envelope)
+//	if envelope.Event == webhooks.EventChatPresence {
+//		var evt webhooks.Event[webhooks.ChatPresencePayload]
+//		_ = json.Unmarshal(body, &evt)
+//	}
+package webhooks
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event names as sent in the envelope's event field. Each can be selected
+// with WHATSAPP_WEBHOOK_EVENTS or a per-URL filter.
+const (
+	EventMessageAck         = "message.ack"
+	EventGroupParticipants  = "group.participants"
+	EventGroupUpdated       = "group.updated"
+	EventGroupJoinRequest   = "group.join_request"
+	EventPresence           = "presence"
+	EventChatPresence       = "chat.presence"
+	EventDeviceLoggedOut    = "device.logged_out"
+	EventDeviceDisconnected = "device.disconnected"
+	EventDevicePaired       = "device.paired"
+	EventDevicePairFailed   = "device.pair_failed"
+	EventDeviceRenamed      = "device.renamed"
+)
+
+// Event is the envelope every webhook body shares.
+type Event[T any] struct {
+	Event     string    `json:"event"`
+	DeviceID  string    `json:"device_id,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Payload   T         `json:"payload"`
+}
+
+// Envelope leaves the payload undecoded, for reading the event name first.
+type Envelope = Event[json.RawMessage]
+
+// ReceiptPayload is the payload of message.ack events.
+type ReceiptPayload struct {
+	IDs                    []string `json:"ids,omitempty"`
+	ChatID                 string   `json:"chat_id"`
+	From                   string   `json:"from"`
+	FromLID                string   `json:"from_lid,omitempty"`
+	ReceiptType            string   `json:"receipt_type"` // delivered, read, played, ...
+	ReceiptTypeDescription string   `json:"receipt_type_description"`
+}
+
+// GroupParticipantsPayload is the payload of group.participants events.
+type GroupParticipantsPayload struct {
+	ChatID string   `json:"chat_id"`
+	Type   string   `json:"type"` // join, leave, promote or demote
+	JIDs   []string `json:"jids"`
+}
+
+// GroupUpdatedPayload is the payload of group.updated events. Only the
+// settings that changed are set.
+type GroupUpdatedPayload struct {
+	ChatID            string  `json:"chat_id"`
+	Sender            string  `json:"sender,omitempty"`
+	Name              *string `json:"name,omitempty"`
+	Topic             *string `json:"topic,omitempty"`
+	Locked            *bool   `json:"locked,omitempty"`
+	Announce          *bool   `json:"announce,omitempty"`
+	MemberAddMode     *string `json:"member_add_mode,omitempty"`    // admin_add or all_member_add
+	DisappearingTimer *uint32 `json:"disappearing_timer,omitempty"` // seconds, zero meaning off
+	PictureID         *string `json:"picture_id,omitempty"`         // empty when the photo was removed
+}
+
+// GroupJoinRequestPayload is the payload of group.join_request events, sent
+// to admins of groups that need new members to be approved.
+type GroupJoinRequestPayload struct {
+	ChatID string   `json:"chat_id"`
+	Action string   `json:"action"` // requested, cancelled or rejected
+	JIDs   []string `json:"jids"`
+	Method string   `json:"method,omitempty"` // invite_link, linked_group_join or non_admin_add
+}
+
+// PresencePayload is the payload of presence events, sent for contacts the
+// device has subscribed to.
+type PresencePayload struct {
+	From      string     `json:"from"`
+	Available bool       `json:"available"`
+	LastSeen  *time.Time `json:"last_seen,omitempty"`
+}
+
+// ChatPresencePayload is the payload of chat.presence events.
+type ChatPresencePayload struct {
+	ChatID string `json:"chat_id"`
+	From   string `json:"from"`
+	State  string `json:"state"`           // composing or paused
+	Media  string `json:"media,omitempty"` // audio while recording a voice note
+}
+
+// DeviceStatusPayload is the payload of device.logged_out and
+// device.disconnected events.
+type DeviceStatusPayload struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// DevicePairingPayload is the payload of device.paired and device.pair_failed
+// events. Error is only set when pairing failed.
+type DevicePairingPayload struct {
+	JID          string `json:"jid"`
+	LID          string `json:"lid,omitempty"`
+	BusinessName string `json:"business_name,omitempty"`
+	Platform     string `json:"platform,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// DeviceRenamedPayload is the payload of device.renamed events. DeviceID is
+// the registry ID, which devices that aren't paired yet are only known by.
+type DeviceRenamedPayload struct {
+	DeviceID     string `json:"device_id"`
+	Name         string `json:"name"`
+	PreviousName string `json:"previous_name"`
+}
@@ -39,7 +39,7 @@ func (h *GroupHandler) AddGroupTools(mcpServer *server.MCPServer) {
 func (h *GroupHandler) toolCreateGroup() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_group_create",
-		mcp.WithDescription("Create a new WhatsApp group with an optional participant list."),
+		mcp.WithDescription("Create a new WhatsApp group with its participants, optionally with a description and admin-only settings."),
 		mcp.WithTitleAnnotation("Create Group"),
 		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithDestructiveHintAnnotation(true),
@@ -51,6 +51,16 @@ func (h *GroupHandler) toolCreateGroup() mcp.Tool {
 		mcp.WithArray("participants",
 			mcp.Description("Phone numbers to add during creation (without @s.whatsapp.net suffix)."),
 			mcp.WithStringItems(),
+			mcp.Required(),
+		),
+		mcp.WithString("description",
+			mcp.Description("Group description."),
+		),
+		mcp.WithBoolean("announce",
+			mcp.Description("Only admins can send messages."),
+		),
+		mcp.WithBoolean("restrict",
+			mcp.Description("Only admins can edit the group info."),
 		),
 	)
 }
@@ -62,6 +72,7 @@ func (h *GroupHandler) handleCreateGroup(ctx context.Context, request mcp.CallTo
 	}
 
 	var participants []string
+	var announce, restrict bool
 	if args := request.GetArguments(); args != nil {
 		if raw, ok := args["participants"]; ok {
 			participants, err = toStringSlice(raw)
@@ -69,23 +80,37 @@ func (h *GroupHandler) handleCreateGroup(ctx context.Context, request mcp.CallTo
 				return nil, err
 			}
 		}
+		if val, ok := args["announce"]; ok {
+			if announce, err = toBool(val); err != nil {
+				return nil, err
+			}
+		}
+		if val, ok := args["restrict"]; ok {
+			if restrict, err = toBool(val); err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	groupID, err := h.groupService.CreateGroup(ctx, domainGroup.CreateGroupRequest{
+	response, err := h.groupService.CreateGroup(ctx, domainGroup.CreateGroupRequest{
 		Title:        strings.TrimSpace(title),
 		Participants: participants,
+		Description:  request.GetString("description", ""),
+		Announce:     announce,
+		Restrict:     restrict,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	structured := map[string]any{
-		"group_id": groupID,
-		"title":    strings.TrimSpace(title),
-		"members":  len(participants),
+		"group_id":     response.GroupID,
+		"title":        strings.TrimSpace(title),
+		"members":      len(participants),
+		"participants": response.Participants,
 	}
 
-	fallback := fmt.Sprintf("Created group %s with %d members", groupID, len(participants))
+	fallback := fmt.Sprintf("Created group %s with %d members", response.GroupID, len(participants))
 	return mcp.NewToolResultStructured(structured, fallback), nil
 }
 
@@ -110,17 +135,21 @@ func (h *GroupHandler) handleJoinGroup(ctx context.Context, request mcp.CallTool
 		return nil, err
 	}
 
-	groupID, err := h.groupService.JoinGroupWithLink(ctx, domainGroup.JoinGroupWithLinkRequest{Link: strings.TrimSpace(link)})
+	response, err := h.groupService.JoinGroupWithLink(ctx, domainGroup.JoinGroupWithLinkRequest{Link: strings.TrimSpace(link)})
 	if err != nil {
 		return nil, err
 	}
 
 	structured := map[string]any{
-		"group_id":    groupID,
+		"group_id":    response.GroupID,
+		"status":      response.Status,
 		"invite_link": link,
 	}
 
-	fallback := fmt.Sprintf("Joined group %s", groupID)
+	fallback := fmt.Sprintf("Joined group %s", response.GroupID)
+	if response.Status == domainGroup.JoinStatusPendingApproval {
+		fallback = fmt.Sprintf("Requested to join group %s, waiting for admin approval", response.GroupID)
+	}
 	return mcp.NewToolResultStructured(structured, fallback), nil
 }
 
@@ -148,10 +177,14 @@ func (h *GroupHandler) handleLeaveGroup(ctx context.Context, request mcp.CallToo
 	trimmed := strings.TrimSpace(groupID)
 	utils.SanitizePhone(&trimmed)
 
-	if err := h.groupService.LeaveGroup(ctx, domainGroup.LeaveGroupRequest{GroupID: trimmed}); err != nil {
+	response, err := h.groupService.LeaveGroup(ctx, domainGroup.LeaveGroupRequest{GroupID: trimmed})
+	if err != nil {
 		return nil, err
 	}
 
+	if response.Warning != "" {
+		return mcp.NewToolResultText(fmt.Sprintf("Left group %s. %s", trimmed, response.Warning)), nil
+	}
 	return mcp.NewToolResultText(fmt.Sprintf("Left group %s", trimmed)), nil
 }
 
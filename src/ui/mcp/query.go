@@ -32,6 +32,7 @@ func (h *QueryHandler) AddQueryTools(mcpServer *server.MCPServer) {
 	mcpServer.AddTool(h.toolListContacts(), h.handleListContacts)
 	mcpServer.AddTool(h.toolListChats(), h.handleListChats)
 	mcpServer.AddTool(h.toolGetChatMessages(), h.handleGetChatMessages)
+	mcpServer.AddTool(h.toolSearchMessages(), h.handleSearchMessages)
 	mcpServer.AddTool(h.toolDownloadMedia(), h.handleDownloadMedia)
 	mcpServer.AddTool(h.toolArchiveChat(), h.handleArchiveChat)
 }
@@ -80,11 +81,25 @@ func (h *QueryHandler) toolListChats() mcp.Tool {
 			mcp.Description("If true, return only chats that contain media messages."),
 			mcp.DefaultBool(false),
 		),
+		mcp.WithBoolean("archived",
+			mcp.Description("If set, return only archived (true) or unarchived (false) chats."),
+		),
+		mcp.WithBoolean("pinned",
+			mcp.Description("If set, return only pinned (true) or unpinned (false) chats."),
+		),
+		mcp.WithBoolean("blocked",
+			mcp.Description("If set, return only chats of blocked (true) or not blocked (false) contacts."),
+		),
+		mcp.WithString("chat_type",
+			mcp.Description("If set, return only chats of this type."),
+			mcp.Enum("user", "group", "newsletter", "status"),
+		),
 	)
 }
 
 func (h *QueryHandler) handleListChats(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var hasMedia bool
+	var archivedPtr, pinnedPtr, blockedPtr *bool
 	args := request.GetArguments()
 	if args != nil {
 		if value, ok := args["has_media"]; ok {
@@ -94,6 +109,27 @@ func (h *QueryHandler) handleListChats(ctx context.Context, request mcp.CallTool
 			}
 			hasMedia = parsed
 		}
+		if value, ok := args["archived"]; ok {
+			parsed, err := toBool(value)
+			if err != nil {
+				return nil, err
+			}
+			archivedPtr = &parsed
+		}
+		if value, ok := args["pinned"]; ok {
+			parsed, err := toBool(value)
+			if err != nil {
+				return nil, err
+			}
+			pinnedPtr = &parsed
+		}
+		if value, ok := args["blocked"]; ok {
+			parsed, err := toBool(value)
+			if err != nil {
+				return nil, err
+			}
+			blockedPtr = &parsed
+		}
 	}
 
 	req := domainChat.ListChatsRequest{
@@ -101,6 +137,10 @@ func (h *QueryHandler) handleListChats(ctx context.Context, request mcp.CallTool
 		Offset:   request.GetInt("offset", 0),
 		Search:   request.GetString("search", ""),
 		HasMedia: hasMedia,
+		Archived: archivedPtr,
+		Pinned:   pinnedPtr,
+		Blocked:  blockedPtr,
+		ChatType: request.GetString("chat_type", ""),
 	}
 
 	resp, err := h.chatService.ListChats(ctx, req)
@@ -147,11 +187,28 @@ func (h *QueryHandler) toolGetChatMessages() mcp.Tool {
 			mcp.Description("If true, return only messages containing media."),
 			mcp.DefaultBool(false),
 		),
+		mcp.WithString("media_type",
+			mcp.Description("Comma-separated media types to include: image, video, video_note, audio, document, sticker, or text for messages without media."),
+		),
+		mcp.WithString("sender",
+			mcp.Description("Only return messages sent by this JID (e.g., 628123456789@s.whatsapp.net), useful in group chats."),
+		),
 		mcp.WithBoolean("is_from_me",
 			mcp.Description("If provided, filter messages sent by you (true) or others (false)."),
 		),
 		mcp.WithString("search",
-			mcp.Description("Full-text search within the chat history (case-insensitive)."),
+			mcp.Description("Full-text search within the chat history (case-insensitive). All words must match; use double quotes for phrases."),
+		),
+		mcp.WithString("cursor",
+			mcp.Description("Opaque pagination cursor from pagination.next_cursor of a previous call; returns older messages."),
+		),
+		mcp.WithBoolean("include_reactions",
+			mcp.Description("If true, include the emoji reactions on each message."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean("include_quoted",
+			mcp.Description("If true, include the ID, sender and content of the message each reply quotes."),
+			mcp.DefaultBool(false),
 		),
 	)
 }
@@ -187,6 +244,13 @@ func (h *QueryHandler) handleGetChatMessages(ctx context.Context, request mcp.Ca
 		}
 	}
 
+	var mediaTypes []string
+	for _, mediaType := range strings.Split(request.GetString("media_type", ""), ",") {
+		if mediaType = strings.TrimSpace(mediaType); mediaType != "" {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+
 	var isFromMePtr *bool
 	if args != nil {
 		if value, ok := args["is_from_me"]; ok {
@@ -199,14 +263,19 @@ func (h *QueryHandler) handleGetChatMessages(ctx context.Context, request mcp.Ca
 	}
 
 	req := domainChat.GetChatMessagesRequest{
-		ChatJID:   chatJID,
-		Limit:     request.GetInt("limit", 50),
-		Offset:    request.GetInt("offset", 0),
-		StartTime: startTimePtr,
-		EndTime:   endTimePtr,
-		MediaOnly: mediaOnly,
-		IsFromMe:  isFromMePtr,
-		Search:    request.GetString("search", ""),
+		ChatJID:          chatJID,
+		Limit:            request.GetInt("limit", 50),
+		Offset:           request.GetInt("offset", 0),
+		StartTime:        startTimePtr,
+		EndTime:          endTimePtr,
+		MediaOnly:        mediaOnly,
+		MediaTypes:       mediaTypes,
+		Sender:           strings.TrimSpace(request.GetString("sender", "")),
+		IsFromMe:         isFromMePtr,
+		Search:           request.GetString("search", ""),
+		Cursor:           strings.TrimSpace(request.GetString("cursor", "")),
+		IncludeReactions: request.GetBool("include_reactions", false),
+		IncludeQuoted:    request.GetBool("include_quoted", false),
 	}
 
 	resp, err := h.chatService.GetChatMessages(ctx, req)
@@ -222,6 +291,48 @@ func (h *QueryHandler) handleGetChatMessages(ctx context.Context, request mcp.Ca
 	return mcp.NewToolResultStructured(resp, fallback), nil
 }
 
+func (h *QueryHandler) toolSearchMessages() mcp.Tool {
+	return mcp.NewTool(
+		"whatsapp_search_messages",
+		mcp.WithDescription("Search message content across every chat of the connected device, newest first. Each hit includes the chat name."),
+		mcp.WithTitleAnnotation("Search Messages"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+		mcp.WithString("query",
+			mcp.Description("Text to search for. All words must match; use double quotes for phrases."),
+			mcp.Required(),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of messages to return (default 25, max 100)."),
+			mcp.DefaultNumber(25),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Number of matches to skip (default 0)."),
+			mcp.DefaultNumber(0),
+		),
+	)
+}
+
+func (h *QueryHandler) handleSearchMessages(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query, err := request.RequireString("query")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := h.chatService.SearchMessages(ctx, domainChat.SearchMessagesRequest{
+		Query:  query,
+		Limit:  request.GetInt("limit", 25),
+		Offset: request.GetInt("offset", 0),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fallback := fmt.Sprintf("Found %d messages matching %q", len(resp.Data), query)
+	return mcp.NewToolResultStructured(resp, fallback), nil
+}
+
 func (h *QueryHandler) toolDownloadMedia() mcp.Tool {
 	return mcp.NewTool(
 		"whatsapp_download_message_media",
@@ -46,6 +46,9 @@ func (s *SendHandler) toolSendText() mcp.Tool {
 		mcp.WithString("reply_message_id",
 			mcp.Description("Message ID to reply to (optional)"),
 		),
+		mcp.WithBoolean("simulate_typing",
+			mcp.Description("Show the typing indicator for a time proportional to the message length before sending (default: false)"),
+		),
 		mcp.WithArray("mentions",
 			mcp.Description("List of phone numbers or JIDs to mention (ghost mentions - users will be notified but @phone won't appear in message text). Use \"@everyone\" to mention all group participants. Example: [\"628123456789\", \"@everyone\"]"),
 		),
@@ -75,6 +78,8 @@ func (s *SendHandler) handleSendText(ctx context.Context, request mcp.CallToolRe
 		replyMessageId = ""
 	}
 
+	simulateTyping, _ := request.GetArguments()["simulate_typing"].(bool)
+
 	// Parse mentions array (ghost mentions)
 	var mentions []string
 	if mentionsRaw, ok := request.GetArguments()["mentions"].([]interface{}); ok {
@@ -87,12 +92,13 @@ func (s *SendHandler) handleSendText(ctx context.Context, request mcp.CallToolRe
 
 	res, err := s.sendService.SendText(ctx, domainSend.MessageRequest{
 		BaseRequest: domainSend.BaseRequest{
-			Phone:       phone,
-			IsForwarded: isForwarded,
+			Phone:          phone,
+			IsForwarded:    isForwarded,
+			ReplyMessageID: &replyMessageId,
 		},
 		Message:        message,
-		ReplyMessageID: &replyMessageId,
-		Mentions:       mentions,
+		SimulateTyping: simulateTyping,
+		MentionRequest: domainSend.MentionRequest{Mentions: mentions},
 	})
 
 	if err != nil {
@@ -237,6 +243,12 @@ func (s *SendHandler) toolSendLocation() mcp.Tool {
 			mcp.Required(),
 			mcp.Description("Longitude coordinate (as string)"),
 		),
+		mcp.WithString("name",
+			mcp.Description("Name of the place (optional)"),
+		),
+		mcp.WithString("address",
+			mcp.Description("Address of the place (optional)"),
+		),
 		mcp.WithBoolean("is_forwarded",
 			mcp.Description("Whether this message is being forwarded (default: false)"),
 		),
@@ -266,6 +278,9 @@ func (s *SendHandler) handleSendLocation(ctx context.Context, request mcp.CallTo
 		isForwarded = false
 	}
 
+	name, _ := request.GetArguments()["name"].(string)
+	address, _ := request.GetArguments()["address"].(string)
+
 	res, err := s.sendService.SendLocation(ctx, domainSend.LocationRequest{
 		BaseRequest: domainSend.BaseRequest{
 			Phone:       phone,
@@ -273,6 +288,8 @@ func (s *SendHandler) handleSendLocation(ctx context.Context, request mcp.CallTo
 		},
 		Latitude:  latitude,
 		Longitude: longitude,
+		Name:      name,
+		Address:   address,
 	})
 
 	if err != nil {
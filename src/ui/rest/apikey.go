@@ -0,0 +1,61 @@
+package rest
+
+import (
+	domainAPIKey "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/apikey"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type APIKey struct {
+	Service domainAPIKey.IAPIKeyUsecase
+}
+
+// InitRestAPIKey registers the API key management routes. middleware.Auth
+// only lets basic auth reach them.
+func InitRestAPIKey(app fiber.Router, service domainAPIKey.IAPIKeyUsecase) APIKey {
+	rest := APIKey{Service: service}
+	app.Post("/admin/api-keys", rest.CreateAPIKey)
+	app.Get("/admin/api-keys", rest.ListAPIKeys)
+	app.Delete("/admin/api-keys/:id", rest.RevokeAPIKey)
+	return rest
+}
+
+func (handler *APIKey) CreateAPIKey(c *fiber.Ctx) error {
+	var request domainAPIKey.CreateAPIKeyRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	response, err := handler.Service.CreateAPIKey(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "API key created, store the key now as it won't be shown again",
+		Results: response,
+	})
+}
+
+func (handler *APIKey) ListAPIKeys(c *fiber.Ctx) error {
+	keys, err := handler.Service.ListAPIKeys(c.UserContext())
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "List API keys",
+		Results: keys,
+	})
+}
+
+func (handler *APIKey) RevokeAPIKey(c *fiber.Ctx) error {
+	err := handler.Service.RevokeAPIKey(c.UserContext(), c.Params("id"))
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "API key revoked",
+		Results: nil,
+	})
+}
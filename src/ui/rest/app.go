@@ -1,13 +1,17 @@
 package rest
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	domainApp "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/app"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
 )
 
 type App struct {
@@ -18,10 +22,12 @@ func InitRestApp(app fiber.Router, service domainApp.IAppUsecase) App {
 	rest := App{Service: service}
 	app.Get("/app/login", rest.Login)
 	app.Get("/app/login-with-code", rest.LoginWithCode)
+	app.Post("/app/login-with-code", rest.PostLoginWithCode)
 	app.Get("/app/logout", rest.Logout)
 	app.Get("/app/reconnect", rest.Reconnect)
 	app.Get("/app/devices", rest.Devices)
 	app.Get("/app/status", rest.ConnectionStatus)
+	app.Get("/app/events", rest.Events)
 
 	return App{Service: service}
 }
@@ -53,7 +59,24 @@ func (handler *App) LoginWithCode(c *fiber.Ctx) error {
 		return err
 	}
 
-	pairCode, err := handler.Service.LoginWithCode(c.UserContext(), device.ID(), c.Query("phone"))
+	return handler.loginWithCode(c, device, c.Query("phone"))
+}
+
+func (handler *App) PostLoginWithCode(c *fiber.Ctx) error {
+	device, err := getDeviceInstance(c)
+	if err != nil {
+		return err
+	}
+
+	var request domainApp.LoginWithCodeRequest
+	err = c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	return handler.loginWithCode(c, device, request.Phone)
+}
+
+func (handler *App) loginWithCode(c *fiber.Ctx, device *whatsapp.DeviceInstance, phone string) error {
+	pairCode, err := handler.Service.LoginWithCode(c.UserContext(), device.ID(), phone)
 	utils.PanicIfNeeded(err)
 
 	return c.JSON(utils.ResponseData{
@@ -134,6 +157,63 @@ func (handler *App) ConnectionStatus(c *fiber.Ctx) error {
 	})
 }
 
+// pairingEventKeepAlive is how often an idle event stream sends a comment, which
+// also detects clients that went away.
+const pairingEventKeepAlive = 15 * time.Second
+
+// Events streams the QR codes and connection changes of a device as
+// Server-Sent Events until the client disconnects.
+func (handler *App) Events(c *fiber.Ctx) error {
+	device, err := getDeviceInstance(c)
+	if err != nil {
+		return err
+	}
+
+	events, unsubscribe := whatsapp.PairingEvents.Subscribe(device.ID())
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+		writePairingEvents(w, events, pairingEventKeepAlive)
+	})
+	return nil
+}
+
+// writePairingEvents writes events to w until a write fails, which is how a
+// disconnected client shows up.
+func writePairingEvents(w *bufio.Writer, events <-chan whatsapp.PairingEvent, keepAlive time.Duration) {
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+
+	if _, err := w.WriteString(": connected\n\n"); err != nil || w.Flush() != nil {
+		return
+	}
+	for {
+		select {
+		case evt := <-events:
+			data, err := json.Marshal(evt.Data)
+			if err != nil {
+				logrus.Errorf("[PAIRING_EVENTS] Failed to marshal %s event: %v", evt.Name, err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", evt.Name, data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := w.WriteString(": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
 func getDeviceInstance(c *fiber.Ctx) (*whatsapp.DeviceInstance, error) {
 	value := c.Locals("device")
 	if value == nil {
@@ -0,0 +1,49 @@
+package rest
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+)
+
+// disconnectingWriter fails every write after the first limit, like a client
+// that goes away mid-stream.
+type disconnectingWriter struct {
+	strings.Builder
+	writes int
+	limit  int
+}
+
+func (w *disconnectingWriter) Write(p []byte) (int, error) {
+	if w.writes >= w.limit {
+		return 0, errors.New("connection closed")
+	}
+	w.writes++
+	return w.Builder.Write(p)
+}
+
+func TestWritePairingEventsStopsWhenClientDisconnects(t *testing.T) {
+	events := make(chan whatsapp.PairingEvent, 1)
+	events <- whatsapp.PairingEvent{Name: whatsapp.PairingEventQR, Data: map[string]any{"device_id": "device-a", "code": "2@abc"}}
+	out := &disconnectingWriter{limit: 2}
+
+	done := make(chan struct{})
+	go func() {
+		writePairingEvents(bufio.NewWriter(out), events, 10*time.Millisecond)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the stream to stop after the client disconnected")
+	}
+	want := ": connected\n\nevent: qr\ndata: {\"code\":\"2@abc\",\"device_id\":\"device-a\"}\n\n"
+	if out.String() != want {
+		t.Fatalf("expected %q, got %q", want, out.String())
+	}
+}
@@ -0,0 +1,92 @@
+package rest
+
+import (
+	domainAutoReply "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/autoreply"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AutoReply struct {
+	Service domainAutoReply.IAutoReplyUsecase
+}
+
+// InitRestAutoReply registers the auto-reply rule routes of the device in the
+// request.
+func InitRestAutoReply(app fiber.Router, service domainAutoReply.IAutoReplyUsecase) AutoReply {
+	rest := AutoReply{Service: service}
+	app.Post("/autoreply", rest.CreateRule)
+	app.Get("/autoreply", rest.ListRules)
+	app.Get("/autoreply/:id", rest.GetRule)
+	app.Patch("/autoreply/:id", rest.UpdateRule)
+	app.Delete("/autoreply/:id", rest.DeleteRule)
+	return rest
+}
+
+func (handler *AutoReply) CreateRule(c *fiber.Ctx) error {
+	var request domainAutoReply.CreateRuleRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	rule, err := handler.Service.CreateRule(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Auto-reply rule created",
+		Results: rule,
+	})
+}
+
+func (handler *AutoReply) ListRules(c *fiber.Ctx) error {
+	rules, err := handler.Service.ListRules(c.UserContext())
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "List auto-reply rules",
+		Results: rules,
+	})
+}
+
+func (handler *AutoReply) GetRule(c *fiber.Ctx) error {
+	rule, err := handler.Service.GetRule(c.UserContext(), c.Params("id"))
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Auto-reply rule",
+		Results: rule,
+	})
+}
+
+func (handler *AutoReply) UpdateRule(c *fiber.Ctx) error {
+	var request domainAutoReply.UpdateRuleRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+	request.ID = c.Params("id")
+
+	rule, err := handler.Service.UpdateRule(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Auto-reply rule updated",
+		Results: rule,
+	})
+}
+
+func (handler *AutoReply) DeleteRule(c *fiber.Ctx) error {
+	err := handler.Service.DeleteRule(c.UserContext(), c.Params("id"))
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Auto-reply rule deleted",
+		Results: nil,
+	})
+}
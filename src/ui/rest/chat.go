@@ -1,8 +1,13 @@
 package rest
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
 	domainChat "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chat"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/gofiber/fiber/v2"
 )
@@ -16,10 +21,27 @@ func InitRestChat(app fiber.Router, service domainChat.IChatUsecase) Chat {
 
 	// Chat endpoints
 	app.Get("/chats", rest.ListChats)
+	app.Get("/chats/unread", rest.ListUnreadChats)
+	app.Get("/chats/statistics", rest.GetStorageStatistics)
+	app.Get("/chats/search", rest.SearchMessages)
+	app.Post("/chats/prune", rest.PruneMessages)
+	app.Post("/chats/merge", rest.MergeChats)
 	app.Get("/chat/:chat_jid/messages", rest.GetChatMessages)
+	app.Get("/chat/:chat_jid/messages/:message_id/edits", rest.GetMessageEditHistory)
+	app.Get("/chat/:chat_jid/pinned", rest.GetPinnedMessages)
+	app.Get("/chat/:chat_jid/message/:message_id/download", rest.DownloadMessageMedia)
+	app.Get("/chat/:chat_jid/export", rest.ExportChatMessages)
+	app.Post("/chat/:chat_jid/import", rest.ImportChatMessages)
+	app.Post("/chat/:chat_jid/sync-history", rest.SyncChatHistory)
 	app.Post("/chat/:chat_jid/pin", rest.PinChat)
 	app.Post("/chat/:chat_jid/disappearing", rest.SetDisappearingTimer)
+	app.Put("/chat/:chat_jid/disappearing", rest.SetDisappearingTimer)
 	app.Post("/chat/:chat_jid/archive", rest.ArchiveChat)
+	app.Post("/chat/:chat_jid/read", rest.MarkChatRead)
+	app.Post("/chat/:chat_jid/presence", rest.SetChatPresence)
+
+	// Call log endpoints
+	app.Get("/calls", rest.ListCalls)
 
 	return rest
 }
@@ -32,6 +54,25 @@ func (controller *Chat) ListChats(c *fiber.Ctx) error {
 	request.Offset = c.QueryInt("offset", 0)
 	request.Search = c.Query("search", "")
 	request.HasMedia = c.QueryBool("has_media", false)
+	if archived := c.Query("archived"); archived != "" {
+		value := c.QueryBool("archived")
+		request.Archived = &value
+	}
+	if pinned := c.Query("pinned"); pinned != "" {
+		value := c.QueryBool("pinned")
+		request.Pinned = &value
+	}
+	if blocked := c.Query("blocked"); blocked != "" {
+		value := c.QueryBool("blocked")
+		request.Blocked = &value
+	}
+	request.ChatType = c.Query("chat_type", "")
+	request.Label = c.Query("label", "")
+
+	// page is 1-based and, when given, takes precedence over offset
+	if page := c.QueryInt("page", 0); page > 0 {
+		request.Offset = (page - 1) * request.Limit
+	}
 
 	response, err := controller.Service.ListChats(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
 	utils.PanicIfNeeded(err)
@@ -44,6 +85,18 @@ func (controller *Chat) ListChats(c *fiber.Ctx) error {
 	})
 }
 
+func (controller *Chat) ListUnreadChats(c *fiber.Ctx) error {
+	response, err := controller.Service.ListUnreadChats(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)))
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get unread chats",
+		Results: response,
+	})
+}
+
 func (controller *Chat) GetChatMessages(c *fiber.Ctx) error {
 	var request domainChat.GetChatMessagesRequest
 
@@ -55,12 +108,21 @@ func (controller *Chat) GetChatMessages(c *fiber.Ctx) error {
 	request.Offset = c.QueryInt("offset", 0)
 	request.MediaOnly = c.QueryBool("media_only", false)
 	request.Search = c.Query("search", "")
+	request.Cursor = c.Query("cursor", "")
+	request.Sender = c.Query("sender", "")
+	request.IncludeReactions = c.QueryBool("include_reactions", false)
+	request.IncludeQuoted = c.QueryBool("include_quoted", false)
+	for _, mediaType := range strings.Split(c.Query("media_type"), ",") {
+		if mediaType = strings.TrimSpace(mediaType); mediaType != "" {
+			request.MediaTypes = append(request.MediaTypes, mediaType)
+		}
+	}
 
-	// Parse time filters
-	if startTime := c.Query("start_time"); startTime != "" {
+	// Parse time filters; from/to are preferred, start_time/end_time kept for compatibility
+	if startTime := c.Query("from", c.Query("start_time")); startTime != "" {
 		request.StartTime = &startTime
 	}
-	if endTime := c.Query("end_time"); endTime != "" {
+	if endTime := c.Query("to", c.Query("end_time")); endTime != "" {
 		request.EndTime = &endTime
 	}
 
@@ -81,6 +143,132 @@ func (controller *Chat) GetChatMessages(c *fiber.Ctx) error {
 	})
 }
 
+func (controller *Chat) GetMessageEditHistory(c *fiber.Ctx) error {
+	var request domainChat.GetMessageEditHistoryRequest
+
+	// Parse path parameters
+	request.ChatJID = c.Params("chat_jid")
+	request.MessageID = c.Params("message_id")
+
+	response, err := controller.Service.GetMessageEditHistory(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get message edit history",
+		Results: response,
+	})
+}
+
+func (controller *Chat) GetPinnedMessages(c *fiber.Ctx) error {
+	var request domainChat.GetPinnedMessagesRequest
+	request.ChatJID = c.Params("chat_jid")
+
+	response, err := controller.Service.GetPinnedMessages(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get pinned messages",
+		Results: response,
+	})
+}
+
+func (controller *Chat) DownloadMessageMedia(c *fiber.Ctx) error {
+	ctx := whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c))
+	chatJID := c.Params("chat_jid")
+	messageID := c.Params("message_id")
+
+	data, mimeType, err := controller.Service.DownloadMessageMedia(ctx, "", chatJID, messageID)
+	var expired pkgError.MediaExpiredError
+	if errors.As(err, &expired) && c.QueryBool("retry", false) {
+		// The sender re-uploads the media in the background; download again afterwards
+		utils.PanicIfNeeded(controller.Service.RequestMediaRetry(ctx, "", chatJID, messageID))
+		return c.Status(fiber.StatusAccepted).JSON(utils.ResponseData{
+			Status:  fiber.StatusAccepted,
+			Code:    "MEDIA_RETRY_REQUESTED",
+			Message: "Media retry requested from the sender, download again in a few seconds",
+		})
+	}
+	utils.PanicIfNeeded(err)
+
+	c.Set(fiber.HeaderContentType, mimeType)
+	return c.Send(data)
+}
+
+func (controller *Chat) ExportChatMessages(c *fiber.Ctx) error {
+	var request domainChat.ExportChatMessagesRequest
+
+	// Parse path and query parameters
+	request.ChatJID = c.Params("chat_jid")
+	request.Format = strings.ToLower(c.Query("format", domainChat.ExportFormatJSON))
+
+	// The export runs as a job; its file is downloaded from /jobs/{id}/download
+	response, err := controller.Service.ExportChatMessages(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusAccepted).JSON(utils.ResponseData{
+		Status:  fiber.StatusAccepted,
+		Code:    "SUCCESS",
+		Message: response.Status,
+		Results: response,
+	})
+}
+
+func (controller *Chat) ImportChatMessages(c *fiber.Ctx) error {
+	var request domainChat.ImportChatMessagesRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	// Parse path parameter
+	request.ChatJID = c.Params("chat_jid")
+	request.Format = strings.ToLower(request.Format)
+
+	// Missing files are reported by validation
+	if file, errFile := c.FormFile("file"); errFile == nil {
+		request.File = file
+	}
+
+	response, err := controller.Service.ImportChatMessages(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success import chat messages",
+		Results: response,
+	})
+}
+
+func (controller *Chat) SyncChatHistory(c *fiber.Ctx) error {
+	var request domainChat.SyncChatHistoryRequest
+
+	// The body is optional, count has a default
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(400).JSON(utils.ResponseData{
+				Status:  400,
+				Code:    "BAD_REQUEST",
+				Message: "Invalid request body",
+				Results: nil,
+			})
+		}
+	}
+	request.ChatJID = c.Params("chat_jid")
+
+	response, err := controller.Service.SyncChatHistory(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Synced %d older messages", response.Synced),
+		Results: response,
+	})
+}
+
 func (controller *Chat) PinChat(c *fiber.Ctx) error {
 	var request domainChat.PinChatRequest
 
@@ -161,3 +349,140 @@ func (controller *Chat) ArchiveChat(c *fiber.Ctx) error {
 		Results: response,
 	})
 }
+
+func (controller *Chat) MarkChatRead(c *fiber.Ctx) error {
+	var request domainChat.MarkChatReadRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+	request.ChatJID = c.Params("chat_jid")
+
+	response, err := controller.Service.MarkChatRead(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Sent read receipts for %d messages", response.ReceiptsSent),
+		Results: response,
+	})
+}
+
+func (controller *Chat) SetChatPresence(c *fiber.Ctx) error {
+	var request domainChat.SetChatPresenceRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+	request.ChatJID = c.Params("chat_jid")
+
+	response, err := controller.Service.SetChatPresence(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Success send %s presence", response.State),
+		Results: response,
+	})
+}
+
+func (controller *Chat) SearchMessages(c *fiber.Ctx) error {
+	var request domainChat.SearchMessagesRequest
+
+	// Parse query parameters
+	request.Query = c.Query("q", "")
+	request.Limit = c.QueryInt("limit", 25)
+	request.Offset = c.QueryInt("offset", 0)
+
+	response, err := controller.Service.SearchMessages(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success search messages",
+		Results: response,
+	})
+}
+
+func (controller *Chat) GetStorageStatistics(c *fiber.Ctx) error {
+	response, err := controller.Service.GetStorageStatistics(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)))
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get storage statistics",
+		Results: response,
+	})
+}
+
+func (controller *Chat) PruneMessages(c *fiber.Ctx) error {
+	var request domainChat.PruneMessagesRequest
+
+	// Parse JSON body
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid request body",
+			Results: nil,
+		})
+	}
+
+	response, err := controller.Service.PruneMessages(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success prune messages",
+		Results: response,
+	})
+}
+
+func (controller *Chat) MergeChats(c *fiber.Ctx) error {
+	var request domainChat.MergeChatsRequest
+
+	// Parse JSON body
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(400).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid request body",
+			Results: nil,
+		})
+	}
+
+	response, err := controller.Service.MergeChats(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success merge chats",
+		Results: response,
+	})
+}
+
+func (controller *Chat) ListCalls(c *fiber.Ctx) error {
+	var request domainChat.ListCallsRequest
+
+	request.Limit = c.QueryInt("limit", 50)
+	request.Offset = c.QueryInt("offset", 0)
+	request.FromJID = c.Query("from_jid", "")
+	if startTime := c.Query("start_time"); startTime != "" {
+		request.StartTime = &startTime
+	}
+	if endTime := c.Query("end_time"); endTime != "" {
+		request.EndTime = &endTime
+	}
+
+	response, err := controller.Service.ListCalls(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get call log",
+		Results: response,
+	})
+}
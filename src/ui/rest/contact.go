@@ -0,0 +1,54 @@
+package rest
+
+import (
+	domainContact "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/contact"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type Contact struct {
+	Service domainContact.IContactUsecase
+}
+
+func InitRestContact(app fiber.Router, service domainContact.IContactUsecase) Contact {
+	rest := Contact{Service: service}
+	app.Get("/contacts", rest.ListContacts)
+	app.Post("/contacts/sync", rest.SyncContacts)
+	return rest
+}
+
+func (controller *Contact) ListContacts(c *fiber.Ctx) error {
+	var request domainContact.ListContactsRequest
+
+	request.Limit = c.QueryInt("limit", 50)
+	request.Offset = c.QueryInt("offset", 0)
+	request.Search = c.Query("search", "")
+
+	// page is 1-based and, when given, takes precedence over offset
+	if page := c.QueryInt("page", 0); page > 0 {
+		request.Offset = (page - 1) * request.Limit
+	}
+
+	response, err := controller.Service.ListContacts(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get contacts",
+		Results: response,
+	})
+}
+
+func (controller *Contact) SyncContacts(c *fiber.Ctx) error {
+	response, err := controller.Service.SyncContacts(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)))
+	utils.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusAccepted).JSON(utils.ResponseData{
+		Status:  fiber.StatusAccepted,
+		Code:    "SUCCESS",
+		Message: response.Status,
+		Results: response,
+	})
+}
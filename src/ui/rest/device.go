@@ -18,12 +18,19 @@ func InitRestDevice(app fiber.Router, service device.IDeviceUsecase) Device {
 
 	app.Get("/devices/:device_id", rest.GetDevice)
 	app.Delete("/devices/:device_id", rest.RemoveDevice)
+	app.Put("/devices/:device_id/name", rest.RenameDevice)
 
 	app.Get("/devices/:device_id/login", rest.LoginDevice)
 	app.Post("/devices/:device_id/login/code", rest.LoginDeviceWithCode)
 	app.Post("/devices/:device_id/logout", rest.LogoutDevice)
 	app.Post("/devices/:device_id/reconnect", rest.ReconnectDevice)
 	app.Get("/devices/:device_id/status", rest.Status)
+	app.Get("/devices/:device_id/settings", rest.GetSettings)
+	app.Put("/devices/:device_id/settings", rest.UpdateSettings)
+	app.Patch("/devices/:device_id/settings", rest.PatchSettings)
+	app.Get("/devices/:device_id/webhook", rest.GetWebhook)
+	app.Put("/devices/:device_id/webhook", rest.UpdateWebhook)
+	app.Delete("/devices/:device_id/webhook", rest.DeleteWebhook)
 
 	return rest
 }
@@ -54,9 +61,7 @@ func (handler *Device) GetDevice(c *fiber.Ctx) error {
 }
 
 func (handler *Device) AddDevice(c *fiber.Ctx) error {
-	var req struct {
-		DeviceID string `json:"device_id"`
-	}
+	var req device.AddDeviceRequest
 
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ResponseData{
@@ -67,15 +72,15 @@ func (handler *Device) AddDevice(c *fiber.Ctx) error {
 		})
 	}
 
-	device, err := handler.Service.AddDevice(c.UserContext(), req.DeviceID)
+	added, err := handler.Service.AddDevice(c.UserContext(), req)
 	utils.PanicIfNeeded(err)
 
 	result := map[string]any{
-		"id":           device.ID,
-		"display_name": device.DisplayName,
-		"jid":          device.JID,
-		"state":        device.State,
-		"created_at":   device.CreatedAt,
+		"id":           added.ID,
+		"display_name": added.DisplayName,
+		"jid":          added.JID,
+		"state":        added.State,
+		"created_at":   added.CreatedAt,
 	}
 
 	return c.JSON(utils.ResponseData{
@@ -156,7 +161,7 @@ func (handler *Device) ReconnectDevice(c *fiber.Ctx) error {
 
 func (handler *Device) Status(c *fiber.Ctx) error {
 	deviceID := c.Params("device_id")
-	isConnected, isLoggedIn, err := handler.Service.GetStatus(c.UserContext(), deviceID)
+	status, err := handler.Service.GetStatus(c.UserContext(), deviceID)
 	utils.PanicIfNeeded(err)
 
 	return c.JSON(utils.ResponseData{
@@ -164,9 +169,151 @@ func (handler *Device) Status(c *fiber.Ctx) error {
 		Code:    "SUCCESS",
 		Message: "Device status",
 		Results: map[string]any{
-			"device_id":    deviceID,
-			"is_connected": isConnected,
-			"is_logged_in": isLoggedIn,
+			"device_id":          deviceID,
+			"is_connected":       status.IsConnected,
+			"is_logged_in":       status.IsLoggedIn,
+			"jid":                status.JID,
+			"push_name":          status.PushName,
+			"last_seen_event_at": status.LastSeenEventAt,
+			"last_connect_at":    status.LastConnectAt,
+			"reconnect_count":    status.ReconnectCount,
 		},
 	})
 }
+
+func (handler *Device) RenameDevice(c *fiber.Ctx) error {
+	deviceID := c.Params("device_id")
+	var request device.RenameDeviceRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid request body",
+			Results: nil,
+		})
+	}
+
+	renamed, err := handler.Service.RenameDevice(c.UserContext(), deviceID, request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Device renamed",
+		Results: map[string]any{
+			"id":           renamed.ID,
+			"display_name": renamed.DisplayName,
+			"jid":          renamed.JID,
+			"state":        renamed.State,
+			"created_at":   renamed.CreatedAt,
+		},
+	})
+}
+
+func (handler *Device) GetSettings(c *fiber.Ctx) error {
+	deviceID := c.Params("device_id")
+	settings, err := handler.Service.GetSettings(c.UserContext(), deviceID)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Device settings",
+		Results: settings,
+	})
+}
+
+func (handler *Device) UpdateSettings(c *fiber.Ctx) error {
+	deviceID := c.Params("device_id")
+	var request device.DeviceSettings
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid request body",
+			Results: nil,
+		})
+	}
+
+	settings, err := handler.Service.UpdateSettings(c.UserContext(), deviceID, request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Device settings updated",
+		Results: settings,
+	})
+}
+
+func (handler *Device) PatchSettings(c *fiber.Ctx) error {
+	deviceID := c.Params("device_id")
+	var request device.DeviceSettings
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid request body",
+			Results: nil,
+		})
+	}
+
+	settings, err := handler.Service.PatchSettings(c.UserContext(), deviceID, request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Device settings updated",
+		Results: settings,
+	})
+}
+
+func (handler *Device) GetWebhook(c *fiber.Ctx) error {
+	deviceID := c.Params("device_id")
+	webhook, err := handler.Service.GetWebhook(c.UserContext(), deviceID)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Device webhook",
+		Results: webhook,
+	})
+}
+
+func (handler *Device) UpdateWebhook(c *fiber.Ctx) error {
+	deviceID := c.Params("device_id")
+	var request device.DeviceWebhook
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ResponseData{
+			Status:  400,
+			Code:    "BAD_REQUEST",
+			Message: "Invalid request body",
+			Results: nil,
+		})
+	}
+
+	webhook, err := handler.Service.UpdateWebhook(c.UserContext(), deviceID, request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Device webhook updated",
+		Results: webhook,
+	})
+}
+
+func (handler *Device) DeleteWebhook(c *fiber.Ctx) error {
+	deviceID := c.Params("device_id")
+	err := handler.Service.DeleteWebhook(c.UserContext(), deviceID)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Device webhook removed, the device uses the global webhooks",
+		Results: nil,
+	})
+}
@@ -23,11 +23,17 @@ func InitRestGroup(app fiber.Router, service domainGroup.IGroupUsecase) Group {
 	rest := Group{Service: service}
 	app.Post("/group", rest.CreateGroup)
 	app.Post("/group/join-with-link", rest.JoinGroupWithLink)
+	app.Post("/group/join", rest.JoinGroupWithLink)
 	app.Get("/group/info-from-link", rest.GetGroupInfoFromLink)
+	app.Get("/group/invite-info", rest.GetGroupInfoFromLink)
 	app.Get("/group/info", rest.GroupInfo)
 	app.Post("/group/leave", rest.LeaveGroup)
+	app.Post("/group/:group_id/leave", rest.LeaveGroup)
+	app.Get("/groups", rest.ListGroups)
 	app.Get("/group/participants", rest.ListParticipants)
 	app.Get("/group/participants/export", rest.ExportParticipants)
+	app.Get("/group/:group_id/participants", rest.ListCachedParticipants)
+	app.Post("/group/:group_id/participants", rest.UpdateParticipants)
 	app.Post("/group/participants", rest.AddParticipants)
 	app.Post("/group/participants/remove", rest.DeleteParticipants)
 	app.Post("/group/participants/promote", rest.PromoteParticipants)
@@ -35,12 +41,23 @@ func InitRestGroup(app fiber.Router, service domainGroup.IGroupUsecase) Group {
 	app.Get("/group/participant-requests", rest.ListParticipantRequests)
 	app.Post("/group/participant-requests/approve", rest.ApproveParticipantRequests)
 	app.Post("/group/participant-requests/reject", rest.RejectParticipantRequests)
+	app.Get("/group/:group_id/join-requests", rest.ListParticipantRequests)
+	app.Post("/group/:group_id/join-requests", rest.ManageJoinRequests)
 	app.Post("/group/photo", rest.SetGroupPhoto)
 	app.Post("/group/name", rest.SetGroupName)
 	app.Post("/group/locked", rest.SetGroupLocked)
 	app.Post("/group/announce", rest.SetGroupAnnounce)
 	app.Post("/group/topic", rest.SetGroupTopic)
+	app.Put("/group/:group_id/name", rest.SetGroupName)
+	app.Put("/group/:group_id/topic", rest.SetGroupTopic)
+	app.Put("/group/:group_id/photo", rest.SetGroupPhoto)
+	app.Delete("/group/:group_id/photo", rest.DeleteGroupPhoto)
+	app.Put("/group/:group_id/settings", rest.SetGroupSettings)
 	app.Get("/group/invite-link", rest.GetGroupInviteLink)
+	app.Get("/group/:group_id/invite-link", rest.GetGroupInviteLink)
+	app.Get("/communities", rest.ListCommunities)
+	app.Post("/community/:community_id/link", rest.LinkCommunityGroup)
+	app.Post("/community/:community_id/unlink", rest.UnlinkCommunityGroup)
 	return rest
 }
 
@@ -52,13 +69,15 @@ func (controller *Group) JoinGroupWithLink(c *fiber.Ctx) error {
 	response, err := controller.Service.JoinGroupWithLink(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
 	utils.PanicIfNeeded(err)
 
+	message := "Success joined group"
+	if response.Status == domainGroup.JoinStatusPendingApproval {
+		message = "Requested to join group, waiting for admin approval"
+	}
 	return c.JSON(utils.ResponseData{
 		Status:  200,
 		Code:    "SUCCESS",
-		Message: "Success joined group",
-		Results: map[string]string{
-			"group_id": response,
-		},
+		Message: message,
+		Results: response,
 	})
 }
 
@@ -80,18 +99,42 @@ func (controller *Group) GetGroupInfoFromLink(c *fiber.Ctx) error {
 
 func (controller *Group) LeaveGroup(c *fiber.Ctx) error {
 	var request domainGroup.LeaveGroupRequest
-	err := c.BodyParser(&request)
-	utils.PanicIfNeeded(err)
+	// The group is in the path of /group/:group_id/leave, which needs no body
+	if len(c.Body()) > 0 {
+		err := c.BodyParser(&request)
+		utils.PanicIfNeeded(err)
+	}
+	if groupID := c.Params("group_id"); groupID != "" {
+		request.GroupID = groupID
+	}
 
 	utils.SanitizePhone(&request.GroupID)
 
-	err = controller.Service.LeaveGroup(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	response, err := controller.Service.LeaveGroup(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
 	utils.PanicIfNeeded(err)
 
 	return c.JSON(utils.ResponseData{
 		Status:  200,
 		Code:    "SUCCESS",
 		Message: "Success leave group",
+		Results: response,
+	})
+}
+
+// ListGroups lists the groups the device participates in, a page at a time.
+func (controller *Group) ListGroups(c *fiber.Ctx) error {
+	var request domainGroup.ListGroupsRequest
+	err := c.QueryParser(&request)
+	utils.PanicIfNeeded(err)
+
+	response, err := controller.Service.ListGroups(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get list groups",
+		Results: response,
 	})
 }
 
@@ -100,16 +143,14 @@ func (controller *Group) CreateGroup(c *fiber.Ctx) error {
 	err := c.BodyParser(&request)
 	utils.PanicIfNeeded(err)
 
-	groupID, err := controller.Service.CreateGroup(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	response, err := controller.Service.CreateGroup(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
 	utils.PanicIfNeeded(err)
 
 	return c.JSON(utils.ResponseData{
 		Status:  200,
 		Code:    "SUCCESS",
-		Message: fmt.Sprintf("Success created group with id %s", groupID),
-		Results: map[string]string{
-			"group_id": groupID,
-		},
+		Message: fmt.Sprintf("Success created group with id %s", response.GroupID),
+		Results: response,
 	})
 }
 
@@ -139,6 +180,25 @@ func (controller *Group) ListParticipants(c *fiber.Ctx) error {
 	})
 }
 
+// ListCachedParticipants serves group members from the participant cache;
+// ?refresh=true reloads the cache from WhatsApp first.
+func (controller *Group) ListCachedParticipants(c *fiber.Ctx) error {
+	var request domainGroup.GetGroupParticipantsRequest
+	request.GroupID = c.Params("group_id")
+	request.Refresh = c.QueryBool("refresh", false)
+	utils.SanitizePhone(&request.GroupID)
+
+	result, err := controller.Service.GetCachedGroupParticipants(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success getting group participants",
+		Results: result,
+	})
+}
+
 func (controller *Group) ExportParticipants(c *fiber.Ctx) error {
 	var request domainGroup.GetGroupParticipantsRequest
 	err := c.QueryParser(&request)
@@ -208,10 +268,33 @@ func (controller *Group) DemoteParticipants(c *fiber.Ctx) error {
 	return controller.manageParticipants(c, whatsmeow.ParticipantChangeDemote, "Success demote participants")
 }
 
+// UpdateParticipants applies the action given in the body, one of add,
+// remove, promote or demote, to the participants of the group in the path.
+func (controller *Group) UpdateParticipants(c *fiber.Ctx) error {
+	var request domainGroup.ParticipantRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+	request.GroupID = c.Params("group_id")
+	utils.SanitizePhone(&request.GroupID)
+
+	result, err := controller.Service.ManageParticipant(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Success %s participants", request.Action),
+		Results: result,
+	})
+}
+
 func (controller *Group) ListParticipantRequests(c *fiber.Ctx) error {
 	var request domainGroup.GetGroupRequestParticipantsRequest
 	err := c.QueryParser(&request)
 	utils.PanicIfNeeded(err)
+	if groupID := c.Params("group_id"); groupID != "" {
+		request.GroupID = groupID
+	}
 
 	if request.GroupID == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ResponseData{
@@ -234,6 +317,26 @@ func (controller *Group) ListParticipantRequests(c *fiber.Ctx) error {
 	})
 }
 
+// ManageJoinRequests approves or rejects, as the action in the body says, the
+// requests to join the group in the path.
+func (controller *Group) ManageJoinRequests(c *fiber.Ctx) error {
+	var request domainGroup.GroupRequestParticipantsRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+	request.GroupID = c.Params("group_id")
+	utils.SanitizePhone(&request.GroupID)
+
+	result, err := controller.Service.ManageGroupRequestParticipants(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Success %s join requests", request.Action),
+		Results: result,
+	})
+}
+
 func (controller *Group) ApproveParticipantRequests(c *fiber.Ctx) error {
 	return controller.handleRequestedParticipants(c, whatsmeow.ParticipantChangeApprove, "Success approve requested participants")
 }
@@ -280,6 +383,9 @@ func (controller *Group) SetGroupPhoto(c *fiber.Ctx) error {
 	var request domainGroup.SetGroupPhotoRequest
 	err := c.BodyParser(&request)
 	utils.PanicIfNeeded(err)
+	if groupID := c.Params("group_id"); groupID != "" {
+		request.GroupID = groupID
+	}
 
 	utils.SanitizePhone(&request.GroupID)
 
@@ -325,10 +431,28 @@ func (controller *Group) SetGroupPhoto(c *fiber.Ctx) error {
 	})
 }
 
+// DeleteGroupPhoto removes the photo of the group in the path.
+func (controller *Group) DeleteGroupPhoto(c *fiber.Ctx) error {
+	request := domainGroup.SetGroupPhotoRequest{GroupID: c.Params("group_id")}
+	utils.SanitizePhone(&request.GroupID)
+
+	_, err := controller.Service.SetGroupPhoto(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success remove group photo",
+	})
+}
+
 func (controller *Group) SetGroupName(c *fiber.Ctx) error {
 	var request domainGroup.SetGroupNameRequest
 	err := c.BodyParser(&request)
 	utils.PanicIfNeeded(err)
+	if groupID := c.Params("group_id"); groupID != "" {
+		request.GroupID = groupID
+	}
 
 	utils.SanitizePhone(&request.GroupID)
 
@@ -390,6 +514,9 @@ func (controller *Group) SetGroupTopic(c *fiber.Ctx) error {
 	var request domainGroup.SetGroupTopicRequest
 	err := c.BodyParser(&request)
 	utils.PanicIfNeeded(err)
+	if groupID := c.Params("group_id"); groupID != "" {
+		request.GroupID = groupID
+	}
 
 	utils.SanitizePhone(&request.GroupID)
 
@@ -408,6 +535,25 @@ func (controller *Group) SetGroupTopic(c *fiber.Ctx) error {
 	})
 }
 
+// SetGroupSettings changes the settings given in the body of the group in
+// the path.
+func (controller *Group) SetGroupSettings(c *fiber.Ctx) error {
+	var request domainGroup.SetGroupSettingsRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+	request.GroupID = c.Params("group_id")
+	utils.SanitizePhone(&request.GroupID)
+
+	err = controller.Service.SetGroupSettings(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success update group settings",
+	})
+}
+
 // GroupInfo handles the /group/info endpoint to fetch group information
 func (controller *Group) GroupInfo(c *fiber.Ctx) error {
 	var request domainGroup.GroupInfoRequest
@@ -431,6 +577,9 @@ func (controller *Group) GetGroupInviteLink(c *fiber.Ctx) error {
 	var request domainGroup.GetGroupInviteLinkRequest
 	err := c.QueryParser(&request)
 	utils.PanicIfNeeded(err)
+	if groupID := c.Params("group_id"); groupID != "" {
+		request.GroupID = groupID
+	}
 
 	utils.SanitizePhone(&request.GroupID)
 
@@ -444,3 +593,48 @@ func (controller *Group) GetGroupInviteLink(c *fiber.Ctx) error {
 		Results: response,
 	})
 }
+
+// ListCommunities lists the communities of the device with their linked groups.
+func (controller *Group) ListCommunities(c *fiber.Ctx) error {
+	response, err := controller.Service.ListCommunities(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)))
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get list communities",
+		Results: response,
+	})
+}
+
+func (controller *Group) LinkCommunityGroup(c *fiber.Ctx) error {
+	var request domainGroup.CommunityGroupRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+	request.CommunityID = c.Params("community_id")
+
+	err = controller.Service.LinkCommunityGroup(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Success link group %s to community", request.GroupID),
+	})
+}
+
+func (controller *Group) UnlinkCommunityGroup(c *fiber.Ctx) error {
+	var request domainGroup.CommunityGroupRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+	request.CommunityID = c.Params("community_id")
+
+	err = controller.Service.UnlinkCommunityGroup(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Success unlink group %s from community", request.GroupID),
+	})
+}
@@ -0,0 +1,38 @@
+package rest
+
+import (
+	domainApp "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/app"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type Health struct {
+	Service domainApp.IAppUsecase
+}
+
+func InitRestHealth(app fiber.Router, service domainApp.IAppUsecase) Health {
+	rest := Health{Service: service}
+	app.Get("/health", rest.Health)
+	return rest
+}
+
+// Health answers 503 until chat storage is reachable and enough devices are
+// logged in, so it can back liveness and readiness probes.
+func (handler *Health) Health(c *fiber.Ctx) error {
+	health := handler.Service.Health(c.UserContext())
+	if !health.Healthy {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(utils.ResponseData{
+			Status:  fiber.StatusServiceUnavailable,
+			Code:    "UNHEALTHY",
+			Message: "Service unhealthy",
+			Results: health,
+		})
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Service healthy",
+		Results: health,
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	domainApp "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/app"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
 	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow"
 )
@@ -35,6 +36,9 @@ func SetAutoReconnectChecking(cli *whatsmeow.Client) {
 	go func() {
 		for {
 			time.Sleep(5 * time.Minute)
+			if whatsapp.IsShuttingDown() {
+				return
+			}
 			if !cli.IsConnected() {
 				_ = cli.Connect()
 			}
@@ -0,0 +1,80 @@
+package rest
+
+import (
+	"fmt"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainJob "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/job"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type Job struct {
+	Service domainJob.IJobUsecase
+}
+
+// InitRestJob registers the background job routes of the device in the
+// request.
+func InitRestJob(app fiber.Router, service domainJob.IJobUsecase) Job {
+	rest := Job{Service: service}
+	app.Get("/jobs", rest.ListJobs)
+	app.Get("/jobs/:id", rest.GetJob)
+	app.Get("/jobs/:id/download", rest.DownloadJobOutput)
+	app.Delete("/jobs/:id", rest.CancelJob)
+	return rest
+}
+
+func (handler *Job) ListJobs(c *fiber.Ctx) error {
+	request := domainJob.ListJobsRequest{
+		Type:   c.Query("type"),
+		Status: c.Query("status"),
+		Limit:  c.QueryInt("limit", 50),
+		Offset: c.QueryInt("offset", 0),
+	}
+
+	jobs, err := handler.Service.ListJobs(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "List jobs",
+		Results: jobs,
+	})
+}
+
+func (handler *Job) GetJob(c *fiber.Ctx) error {
+	job, err := handler.Service.GetJob(c.UserContext(), c.Params("id"))
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("Job %s", job.Status),
+		Results: job,
+	})
+}
+
+func (handler *Job) DownloadJobOutput(c *fiber.Ctx) error {
+	output, err := handler.Service.GetJobOutput(c.UserContext(), c.Params("id"))
+	utils.PanicIfNeeded(err)
+
+	return c.Download(output.Path, output.Name)
+}
+
+// CancelJob cancels a queued or running job, or deletes a finished one.
+func (handler *Job) CancelJob(c *fiber.Ctx) error {
+	job, err := handler.Service.CancelJob(c.UserContext(), c.Params("id"))
+	utils.PanicIfNeeded(err)
+
+	message := "Job deleted"
+	if job.Status == domainChatStorage.JobStatusCanceled {
+		message = "Job canceled"
+	}
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: message,
+		Results: job,
+	})
+}
@@ -0,0 +1,109 @@
+package rest
+
+import (
+	domainLabel "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/label"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type Label struct {
+	Service domainLabel.ILabelUsecase
+}
+
+// InitRestLabel registers the label routes of the device in the request.
+func InitRestLabel(app fiber.Router, service domainLabel.ILabelUsecase) Label {
+	rest := Label{Service: service}
+	app.Post("/labels", rest.CreateLabel)
+	app.Get("/labels", rest.ListLabels)
+	app.Get("/labels/:id", rest.GetLabel)
+	app.Patch("/labels/:id", rest.UpdateLabel)
+	app.Delete("/labels/:id", rest.DeleteLabel)
+	app.Put("/chat/:chat_jid/labels", rest.SetChatLabels)
+	return rest
+}
+
+func (handler *Label) CreateLabel(c *fiber.Ctx) error {
+	var request domainLabel.CreateLabelRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	label, err := handler.Service.CreateLabel(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Label created",
+		Results: label,
+	})
+}
+
+func (handler *Label) ListLabels(c *fiber.Ctx) error {
+	labels, err := handler.Service.ListLabels(c.UserContext())
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "List labels",
+		Results: labels,
+	})
+}
+
+func (handler *Label) GetLabel(c *fiber.Ctx) error {
+	label, err := handler.Service.GetLabel(c.UserContext(), c.Params("id"))
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Label",
+		Results: label,
+	})
+}
+
+func (handler *Label) UpdateLabel(c *fiber.Ctx) error {
+	var request domainLabel.UpdateLabelRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+	request.ID = c.Params("id")
+
+	label, err := handler.Service.UpdateLabel(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Label updated",
+		Results: label,
+	})
+}
+
+func (handler *Label) DeleteLabel(c *fiber.Ctx) error {
+	err := handler.Service.DeleteLabel(c.UserContext(), c.Params("id"))
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Label deleted",
+		Results: nil,
+	})
+}
+
+func (handler *Label) SetChatLabels(c *fiber.Ctx) error {
+	var request domainLabel.SetChatLabelsRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+	request.ChatJID = c.Params("chat_jid")
+
+	response, err := handler.Service.SetChatLabels(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Chat labels updated",
+		Results: response,
+	})
+}
@@ -1,6 +1,8 @@
 package rest
 
 import (
+	"mime"
+
 	domainMessage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/message"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
@@ -16,13 +18,21 @@ func InitRestMessage(app fiber.Router, service domainMessage.IMessageUsecase) Me
 
 	// Message action endpoints
 	app.Post("/message/:message_id/reaction", rest.ReactMessage)
+	app.Post("/message/:message_id/react", rest.ReactMessage)
 	app.Post("/message/:message_id/revoke", rest.RevokeMessage)
 	app.Post("/message/:message_id/delete", rest.DeleteMessage)
 	app.Post("/message/:message_id/update", rest.UpdateMessage)
+	app.Post("/message/:message_id/edit", rest.UpdateMessage)
 	app.Post("/message/:message_id/read", rest.MarkAsRead)
 	app.Post("/message/:message_id/star", rest.StarMessage)
 	app.Post("/message/:message_id/unstar", rest.UnstarMessage)
+	app.Post("/message/:message_id/pin", rest.PinMessage)
+	app.Post("/message/:message_id/unpin", rest.UnpinMessage)
+	app.Get("/message/:message_id", rest.GetMessage)
 	app.Get("/message/:message_id/download", rest.DownloadMedia)
+	app.Get("/message/:message_id/media", rest.GetMessageMedia)
+	app.Get("/message/:message_id/receipts", rest.GetReceipts)
+	app.Get("/message/:message_id/poll", rest.GetPoll)
 	return rest
 }
 
@@ -33,6 +43,7 @@ func (controller *Message) RevokeMessage(c *fiber.Ctx) error {
 
 	request.MessageID = c.Params("message_id")
 	utils.SanitizePhone(&request.Phone)
+	utils.SanitizePhone(&request.Participant)
 
 	response, err := controller.Service.RevokeMessage(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
 	utils.PanicIfNeeded(err)
@@ -70,6 +81,9 @@ func (controller *Message) UpdateMessage(c *fiber.Ctx) error {
 	utils.PanicIfNeeded(err)
 
 	request.MessageID = c.Params("message_id")
+	if request.Message == "" {
+		request.Message = request.NewMessage
+	}
 	utils.SanitizePhone(&request.Phone)
 
 	response, err := controller.Service.UpdateMessage(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
@@ -160,6 +174,111 @@ func (controller *Message) UnstarMessage(c *fiber.Ctx) error {
 	})
 }
 
+func (controller *Message) PinMessage(c *fiber.Ctx) error {
+	var request domainMessage.PinRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	request.MessageID = c.Params("message_id")
+	utils.SanitizePhone(&request.Phone)
+	request.IsPinned = true
+
+	response, err := controller.Service.PinMessage(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: response.Status,
+		Results: response,
+	})
+}
+
+func (controller *Message) UnpinMessage(c *fiber.Ctx) error {
+	var request domainMessage.PinRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	request.MessageID = c.Params("message_id")
+	utils.SanitizePhone(&request.Phone)
+	request.IsPinned = false
+
+	response, err := controller.Service.PinMessage(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: response.Status,
+		Results: response,
+	})
+}
+
+func (controller *Message) GetMessage(c *fiber.Ctx) error {
+	var request domainMessage.GetMessageRequest
+	request.MessageID = c.Params("message_id")
+
+	response, err := controller.Service.GetMessage(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get message",
+		Results: response,
+	})
+}
+
+func (controller *Message) GetReceipts(c *fiber.Ctx) error {
+	var request domainMessage.GetReceiptsRequest
+
+	request.MessageID = c.Params("message_id")
+	request.Phone = c.Query("phone")
+	utils.SanitizePhone(&request.Phone)
+
+	response, err := controller.Service.GetReceipts(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get message receipts",
+		Results: response,
+	})
+}
+
+func (controller *Message) GetPoll(c *fiber.Ctx) error {
+	var request domainMessage.GetPollRequest
+
+	request.MessageID = c.Params("message_id")
+	request.Phone = c.Query("phone")
+	utils.SanitizePhone(&request.Phone)
+
+	response, err := controller.Service.GetPoll(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get poll results",
+		Results: response,
+	})
+}
+
+func (controller *Message) GetMessageMedia(c *fiber.Ctx) error {
+	var request domainMessage.GetMessageMediaRequest
+
+	request.MessageID = c.Params("message_id")
+	request.Phone = c.Query("phone")
+	utils.SanitizePhone(&request.Phone)
+
+	response, err := controller.Service.GetMessageMedia(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("inline", map[string]string{"filename": response.Filename}))
+	return c.SendFile(response.FilePath)
+}
+
 func (controller *Message) DownloadMedia(c *fiber.Ctx) error {
 	var request domainMessage.DownloadMediaRequest
 
@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainAPIKey "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/apikey"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/basicauth"
+)
+
+const APIKeyHeader = "X-Api-Key"
+
+// APIKeyLocal is the fiber local holding the *apikey.APIKey a request was
+// authenticated with.
+const APIKeyLocal = "api_key"
+
+// apiKeyAdminPrefix is where API keys are managed, which only basic auth may do
+const apiKeyAdminPrefix = "/admin/"
+
+// Auth authenticates requests with an API key, sent as a bearer token or in
+// X-Api-Key, or else with basic auth when users are configured. Key requests
+// must have the scope the route needs and, for keys bound to devices, target
+// one of their devices; DeviceMiddleware checks the device of device-scoped
+// routes.
+func Auth(users map[string]string, service domainAPIKey.IAPIKeyUsecase) fiber.Handler {
+	var basicAuth fiber.Handler
+	if len(users) > 0 {
+		basicAuth = basicauth.New(basicauth.Config{Users: users})
+	}
+
+	return func(c *fiber.Ctx) error {
+		path := routePath(c.Path())
+		key := apiKeyFromRequest(c)
+
+		if strings.HasPrefix(path, apiKeyAdminPrefix) {
+			if key != "" {
+				return authFailure(c, fiber.StatusForbidden, "API_KEY_NOT_ALLOWED", "API keys can't manage API keys, use basic auth")
+			}
+			if basicAuth == nil {
+				return authFailure(c, fiber.StatusForbidden, "BASIC_AUTH_REQUIRED", "API keys can only be managed when basic auth is enabled")
+			}
+		}
+
+		if key == "" {
+			if basicAuth != nil {
+				return basicAuth(c)
+			}
+			return c.Next()
+		}
+
+		apiKey, err := service.Authenticate(c.UserContext(), key)
+		if err != nil {
+			if genericErr, ok := err.(pkgError.GenericError); ok {
+				return authFailure(c, genericErr.StatusCode(), genericErr.ErrCode(), genericErr.Error())
+			}
+			return authFailure(c, fiber.StatusInternalServerError, "INTERNAL_SERVER_ERROR", err.Error())
+		}
+
+		scope := RequiredScope(c.Method(), path)
+		if !apiKey.HasScope(scope) {
+			return authFailure(c, fiber.StatusForbidden, "API_KEY_SCOPE_MISSING", "this API key doesn't have the "+scope+" scope")
+		}
+		if len(apiKey.DeviceIDs) > 0 {
+			if deviceID, ok := targetDeviceID(c, path); ok && !apiKey.AllowsDevice(deviceID) {
+				return deviceNotAllowed(c, deviceID)
+			}
+		}
+
+		c.Locals(APIKeyLocal, apiKey)
+		return c.Next()
+	}
+}
+
+// routePath is path as the router matches it, for checks on which route a
+// request goes to: without the base path, lowercase and without trailing
+// slashes, as routes match regardless of case and a trailing slash.
+func routePath(path string) string {
+	return normalizeRoute(trimBasePath(path))
+}
+
+// normalizeRoute lowercases path and drops its trailing slashes.
+func normalizeRoute(path string) string {
+	if trimmed := strings.TrimRight(strings.ToLower(path), "/"); trimmed != "" {
+		return trimmed
+	}
+	return "/"
+}
+
+// trimBasePath removes the base path, in any case, from the front of path.
+func trimBasePath(path string) string {
+	base := config.AppBasePath
+	if len(path) >= len(base) && strings.EqualFold(path[:len(base)], base) {
+		return path[len(base):]
+	}
+	return path
+}
+
+// RequiredScope is the scope an API key needs for a request. path excludes
+// the base path; its case and trailing slashes don't matter. Pairing, which streams QR and pairing codes through
+// /app/events, and maintenance that rewrites or drops stored data need admin.
+func RequiredScope(method, path string) string {
+	path = normalizeRoute(path)
+	switch {
+	case path == "/devices" || strings.HasPrefix(path, "/devices/"),
+		path == "/webhooks" || strings.HasPrefix(path, "/webhooks/"),
+		path == "/app/login" || path == "/app/login-with-code" || path == "/app/logout" || path == "/app/reconnect",
+		path == "/app/events",
+		path == "/chatwoot/sync",
+		path == "/autoreply" || strings.HasPrefix(path, "/autoreply/"),
+		path == "/chats/prune" || path == "/chats/merge" || path == "/contacts/sync",
+		strings.HasPrefix(path, "/chat/") && strings.HasSuffix(path, "/import"):
+		return domainAPIKey.ScopeAdmin
+	case method == fiber.MethodGet || method == fiber.MethodHead:
+		return domainAPIKey.ScopeRead
+	default:
+		return domainAPIKey.ScopeSend
+	}
+}
+
+// targetDeviceID is the device a request outside the device-scoped routes
+// acts on. Routes that act on every device target no device, which only keys
+// not bound to devices may use. ok is false for device-scoped routes, whose
+// device DeviceMiddleware resolves. path is a routePath; device IDs are read
+// from the request path, which keeps their case.
+func targetDeviceID(c *fiber.Ctx, path string) (deviceID string, ok bool) {
+	switch {
+	case strings.HasPrefix(path, "/devices/"):
+		_, rest, _ := strings.Cut(strings.TrimLeft(trimBasePath(c.Path()), "/"), "/")
+		deviceID, _, _ = strings.Cut(strings.TrimLeft(rest, "/"), "/")
+		if decoded, err := url.PathUnescape(deviceID); err == nil {
+			deviceID = decoded
+		}
+		return deviceID, true
+	case path == "/ws/events":
+		return strings.TrimSpace(c.Query("device_id")), true
+	case path == "/devices", path == "/webhooks" || strings.HasPrefix(path, "/webhooks/"), path == "/chatwoot/sync":
+		return "", true
+	}
+	return "", false
+}
+
+func deviceNotAllowed(c *fiber.Ctx, deviceID string) error {
+	message := "this API key isn't allowed to act on every device"
+	if deviceID != "" {
+		message = "this API key isn't allowed to act on device " + deviceID
+	}
+	return c.Status(fiber.StatusForbidden).JSON(utils.ResponseData{
+		Status:  fiber.StatusForbidden,
+		Code:    "API_KEY_DEVICE_NOT_ALLOWED",
+		Message: message,
+		Results: map[string]string{"device_id": deviceID},
+	})
+}
+
+// apiKeyFromRequest reads the key from a bearer Authorization header or,
+// failing that, X-Api-Key.
+func apiKeyFromRequest(c *fiber.Ctx) string {
+	authorization := c.Get(fiber.HeaderAuthorization)
+	if scheme, token, found := strings.Cut(authorization, " "); found && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return strings.TrimSpace(c.Get(APIKeyHeader))
+}
+
+func authFailure(c *fiber.Ctx, status int, code, message string) error {
+	return c.Status(status).JSON(utils.ResponseData{
+		Status:  status,
+		Code:    code,
+		Message: message,
+		Results: nil,
+	})
+}
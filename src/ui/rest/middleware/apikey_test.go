@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainAPIKey "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/apikey"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAPIKeys struct {
+	domainAPIKey.IAPIKeyUsecase
+	keys map[string]domainAPIKey.APIKey
+}
+
+func (f fakeAPIKeys) Authenticate(_ context.Context, key string) (*domainAPIKey.APIKey, error) {
+	apiKey, ok := f.keys[key]
+	if !ok {
+		return nil, pkgError.AuthError("invalid or revoked API key")
+	}
+	return &apiKey, nil
+}
+
+func TestRequiredScope(t *testing.T) {
+	tests := []struct {
+		method, path, scope string
+	}{
+		{"GET", "/chats", domainAPIKey.ScopeRead},
+		{"GET", "/chat/628111@s.whatsapp.net/messages", domainAPIKey.ScopeRead},
+		{"GET", "/ws/events", domainAPIKey.ScopeRead},
+		{"POST", "/send/message", domainAPIKey.ScopeSend},
+		{"POST", "/group/leave", domainAPIKey.ScopeSend},
+		{"GET", "/devices", domainAPIKey.ScopeAdmin},
+		{"DELETE", "/devices/sales", domainAPIKey.ScopeAdmin},
+		{"GET", "/app/login", domainAPIKey.ScopeAdmin},
+		{"GET", "/app/logout", domainAPIKey.ScopeAdmin},
+		{"GET", "/webhooks", domainAPIKey.ScopeAdmin},
+		{"POST", "/autoreply", domainAPIKey.ScopeAdmin},
+		{"GET", "/autoreply/rule-1", domainAPIKey.ScopeAdmin},
+		{"GET", "/devicesx", domainAPIKey.ScopeRead},
+		{"GET", "/app/events", domainAPIKey.ScopeAdmin},
+		{"GET", "/app/devices", domainAPIKey.ScopeRead},
+		{"POST", "/chats/prune", domainAPIKey.ScopeAdmin},
+		{"POST", "/chats/merge", domainAPIKey.ScopeAdmin},
+		{"POST", "/chat/628111@s.whatsapp.net/import", domainAPIKey.ScopeAdmin},
+		{"POST", "/contacts/sync", domainAPIKey.ScopeAdmin},
+		{"GET", "/contacts", domainAPIKey.ScopeRead},
+		{"GET", "/chat/628111@s.whatsapp.net/export", domainAPIKey.ScopeRead},
+		{"POST", "/chat/628111@s.whatsapp.net/pin", domainAPIKey.ScopeSend},
+		{"GET", "/Devices", domainAPIKey.ScopeAdmin},
+		{"GET", "/devices/", domainAPIKey.ScopeAdmin},
+		{"POST", "/WEBHOOKS", domainAPIKey.ScopeAdmin},
+		{"GET", "/App/Events/", domainAPIKey.ScopeAdmin},
+		{"POST", "/chats/prune/", domainAPIKey.ScopeAdmin},
+		{"POST", "/Chat/628111@s.whatsapp.net/Import", domainAPIKey.ScopeAdmin},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.scope, RequiredScope(tt.method, tt.path), tt.method+" "+tt.path)
+	}
+}
+
+func TestRoutePath(t *testing.T) {
+	original := config.AppBasePath
+	t.Cleanup(func() { config.AppBasePath = original })
+	config.AppBasePath = "/api"
+
+	for path, want := range map[string]string{
+		"/api/send/bulk":       "/send/bulk",
+		"/API/Send/Bulk/":      "/send/bulk",
+		"/api/admin/api-keys/": "/admin/api-keys",
+		"/api":                 "/",
+		"/api/":                "/",
+		"/other/devices":       "/other/devices",
+	} {
+		assert.Equal(t, want, routePath(path), path)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	service := fakeAPIKeys{keys: map[string]domainAPIKey.APIKey{
+		"reader": {Scopes: []string{domainAPIKey.ScopeRead}},
+		"sender": {Scopes: []string{domainAPIKey.ScopeRead, domainAPIKey.ScopeSend}},
+		"sales":  {Scopes: []string{domainAPIKey.ScopeRead, domainAPIKey.ScopeAdmin}, DeviceIDs: []string{"sales"}},
+	}}
+	app := fiber.New()
+	app.Use(Auth(map[string]string{"admin": "secret"}, service))
+	app.Use(func(c *fiber.Ctx) error {
+		if _, ok := c.Locals(APIKeyLocal).(*domainAPIKey.APIKey); ok {
+			return c.SendString("key")
+		}
+		return c.SendString("basic")
+	})
+
+	request := func(method, target string, header ...string) (int, string) {
+		req := httptest.NewRequest(method, target, nil)
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	status, body := request("GET", "/chats", "Authorization", "Bearer reader")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "key", body)
+	status, _ = request("GET", "/chats", APIKeyHeader, "reader")
+	assert.Equal(t, fiber.StatusOK, status)
+
+	status, _ = request("POST", "/send/message", APIKeyHeader, "reader")
+	assert.Equal(t, fiber.StatusForbidden, status, "reading doesn't allow sending")
+	status, _ = request("GET", "/chats", APIKeyHeader, "unknown")
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+
+	status, _ = request("GET", "/devices/sales/status", APIKeyHeader, "sales")
+	assert.Equal(t, fiber.StatusOK, status)
+	status, _ = request("GET", "/devices/support/status", APIKeyHeader, "sales")
+	assert.Equal(t, fiber.StatusForbidden, status, "bound keys only reach their devices")
+	status, _ = request("GET", "/devices", APIKeyHeader, "sales")
+	assert.Equal(t, fiber.StatusForbidden, status, "bound keys can't list every device")
+	status, _ = request("GET", "/ws/events?device_id=support", APIKeyHeader, "sales")
+	assert.Equal(t, fiber.StatusForbidden, status)
+
+	status, _ = request("GET", "/admin/api-keys", APIKeyHeader, "sales")
+	assert.Equal(t, fiber.StatusForbidden, status, "keys can't manage keys")
+	status, _ = request("GET", "/admin/api-keys")
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	status, body = request("GET", "/admin/api-keys", "Authorization", "Basic YWRtaW46c2VjcmV0")
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "basic", body)
+
+	// Routes match regardless of case and a trailing slash, and so do the checks
+	for _, target := range []string{"/Admin/api-keys", "/admin/api-keys/", "/ADMIN/API-KEYS"} {
+		status, _ = request("POST", target, APIKeyHeader, "sender")
+		assert.Equal(t, fiber.StatusForbidden, status, "keys can't manage keys at "+target)
+	}
+	for _, target := range []string{"/Devices/sales/logout", "/devices/sales/logout/", "/WEBHOOKS", "/webhooks/", "/Chats/Prune"} {
+		status, _ = request("POST", target, APIKeyHeader, "sender")
+		assert.Equal(t, fiber.StatusForbidden, status, target+" needs the admin scope")
+	}
+	status, _ = request("GET", "/Devices/sales/status", APIKeyHeader, "sales")
+	assert.Equal(t, fiber.StatusOK, status)
+	status, _ = request("GET", "/DEVICES/support/status/", APIKeyHeader, "sales")
+	assert.Equal(t, fiber.StatusForbidden, status)
+	status, _ = request("GET", "/devices/Sales/status", APIKeyHeader, "sales")
+	assert.Equal(t, fiber.StatusForbidden, status, "device IDs keep their case")
+	status, _ = request("GET", "/devices/", APIKeyHeader, "sales")
+	assert.Equal(t, fiber.StatusForbidden, status, "bound keys can't list every device")
+}
+
+func TestAuthWithoutBasicAuth(t *testing.T) {
+	app := fiber.New()
+	app.Use(Auth(nil, fakeAPIKeys{}))
+	app.Use(func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) })
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/chats", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNoContent, resp.StatusCode, "requests without a key stay open")
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/admin/api-keys", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode, "keys can't be managed without basic auth")
+}
@@ -1,10 +1,12 @@
 package middleware
 
 import (
+	"encoding/json"
 	"net/url"
 	"strings"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainAPIKey "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/apikey"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/gofiber/fiber/v2"
@@ -12,8 +14,8 @@ import (
 
 const DeviceIDHeader = "X-Device-Id"
 
-// DeviceMiddleware fetches a device instance by header (preferred), path param, or query param
-// and injects it into the context. It falls back to the default/only device for single-device mode.
+// DeviceMiddleware fetches a device instance by header (preferred), path param, query param,
+// or the device_id field of a JSON body and injects it into the context. It falls back to the default/only device for single-device mode.
 func DeviceMiddleware(dm *whatsapp.DeviceManager) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Allow non-device-scoped public endpoints (e.g., landing page) to pass through.
@@ -39,6 +41,9 @@ func DeviceMiddleware(dm *whatsapp.DeviceManager) fiber.Handler {
 		if deviceID == "" {
 			deviceID = strings.TrimSpace(c.Query("device_id"))
 		}
+		if deviceID == "" {
+			deviceID = deviceIDFromBody(c)
+		}
 
 		instance, resolvedID, err := dm.ResolveDevice(deviceID)
 		if err != nil {
@@ -60,9 +65,28 @@ func DeviceMiddleware(dm *whatsapp.DeviceManager) fiber.Handler {
 			})
 		}
 
+		if apiKey, ok := c.Locals(APIKeyLocal).(*domainAPIKey.APIKey); ok && !apiKey.AllowsDevice(resolvedID) {
+			return deviceNotAllowed(c, resolvedID)
+		}
+
 		c.Locals("device_id", resolvedID)
 		c.Locals("device", instance)
 		c.SetUserContext(whatsapp.ContextWithDevice(c.UserContext(), instance))
 		return c.Next()
 	}
 }
+
+// deviceIDFromBody reads device_id from a JSON request body, leaving the body
+// for the handler to parse.
+func deviceIDFromBody(c *fiber.Ctx) string {
+	if !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON) || len(c.Body()) == 0 {
+		return ""
+	}
+	var body struct {
+		DeviceID string `json:"device_id"`
+	}
+	if err := json.Unmarshal(c.Body(), &body); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(body.DeviceID)
+}
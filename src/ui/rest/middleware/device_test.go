@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceIDFromBody(t *testing.T) {
+	app := fiber.New()
+	var deviceID, body string
+	app.Post("/test", func(c *fiber.Ctx) error {
+		deviceID = deviceIDFromBody(c)
+		body = string(c.Body())
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	payload := `{"phone":"628123456789","device_id":" device-a "}`
+	req := httptest.NewRequest("POST", "/test", strings.NewReader(payload))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	_, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, "device-a", deviceID)
+	assert.Equal(t, payload, body, "body must stay readable by the handler")
+
+	req = httptest.NewRequest("POST", "/test", strings.NewReader("device_id=device-a"))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationForm)
+	_, err = app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Empty(t, deviceID)
+}
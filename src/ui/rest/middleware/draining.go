@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+)
+
+// Draining counts the requests in progress in inFlight, so shutdown can wait
+// for them, and answers 503 to new requests once shutdown began. Health checks
+// still go through to report the service going away. WebSocket connections
+// aren't counted as they stay open until closed.
+func Draining(inFlight *utils.InFlight) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if websocket.IsWebSocketUpgrade(c) {
+			return c.Next()
+		}
+
+		// Counted before checking, so shutdown either waits for the request or
+		// the request sees the shutdown
+		inFlight.Add()
+		defer inFlight.Done()
+
+		if whatsapp.IsShuttingDown() && strings.TrimPrefix(c.Path(), config.AppBasePath) != "/health" {
+			c.Set(fiber.HeaderConnection, "close")
+			return c.Status(fiber.StatusServiceUnavailable).JSON(utils.ResponseData{
+				Status:  fiber.StatusServiceUnavailable,
+				Code:    "SHUTTING_DOWN",
+				Message: "server is shutting down",
+				Results: nil,
+			})
+		}
+		return c.Next()
+	}
+}
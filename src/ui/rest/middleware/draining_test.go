@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDraining(t *testing.T) {
+	var requests utils.InFlight
+	started, release := make(chan struct{}), make(chan struct{})
+	app := fiber.New()
+	app.Use(Draining(&requests))
+	app.Get("/health", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) })
+	app.Post("/send/message", func(c *fiber.Ctx) error {
+		close(started)
+		<-release
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	done := make(chan int)
+	go func() {
+		resp, err := app.Test(httptest.NewRequest("POST", "/send/message", nil), -1)
+		require.NoError(t, err)
+		done <- resp.StatusCode
+	}()
+	<-started
+
+	// Shutdown can't be undone, so no other test of this package may rely on it
+	whatsapp.BeginShutdown()
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/send/message", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode, "new requests are refused")
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/health", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNoContent, resp.StatusCode, "health checks still reach the handler")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, requests.Wait(ctx), context.DeadlineExceeded, "the request in progress is waited for")
+
+	close(release)
+	assert.Equal(t, fiber.StatusOK, <-done, "the request in progress completes")
+	assert.NoError(t, requests.Wait(context.Background()))
+}
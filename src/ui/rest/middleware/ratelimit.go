@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	domainAPIKey "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/apikey"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/ratelimit"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RateLimit limits the sends of each client, answering 429 with Retry-After
+// once a client is over its limit. Clients are told apart by API key, then
+// basic auth user, then IP address, so Auth must run first.
+func RateLimit(limiter *ratelimit.Limiter) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		bucket, ok := rateLimitBucket(c.Method(), routePath(c.Path()))
+		if !ok {
+			return c.Next()
+		}
+
+		allowed, retryAfter := limiter.Allow(rateLimitClient(c), bucket)
+		if !allowed {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+			return c.Status(fiber.StatusTooManyRequests).JSON(utils.ResponseData{
+				Status:  fiber.StatusTooManyRequests,
+				Code:    "RATE_LIMITED",
+				Message: "too many requests, retry in " + strconv.Itoa(seconds) + "s",
+				Results: map[string]any{"retry_after": seconds},
+			})
+		}
+		return c.Next()
+	}
+}
+
+// rateLimitBucket is the bucket a request is counted in. path excludes the
+// base path; its case and trailing slashes don't matter. ok is false for
+// requests that aren't limited. Every request that changes something is
+// counted as a send, since most of them reach WhatsApp, except those in
+// localRoute.
+func rateLimitBucket(method, path string) (bucket string, ok bool) {
+	path = normalizeRoute(path)
+	switch {
+	case method == fiber.MethodGet || method == fiber.MethodHead || method == fiber.MethodOptions:
+		return "", false
+	case path == "/send/bulk":
+		return ratelimit.BucketBulk, true
+	case localRoute(path):
+		return "", false
+	}
+	return ratelimit.BucketSend, true
+}
+
+// localRoute reports whether requests to path stay on this server, changing
+// only its settings or stored data, so they don't count against send limits.
+// On-demand history syncs have their own per-chat limit.
+func localRoute(path string) bool {
+	for _, prefix := range []string{"/admin", "/app", "/devices", "/webhooks", "/autoreply", "/labels", "/templates", "/jobs"} {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	switch {
+	case path == "/chats/prune", path == "/chats/merge", path == "/chatwoot/sync":
+		return true
+	case strings.HasPrefix(path, "/chat/"):
+		return strings.HasSuffix(path, "/labels") || strings.HasSuffix(path, "/import") || strings.HasSuffix(path, "/sync-history")
+	}
+	return false
+}
+
+// rateLimitClient names the client of a request in rate limit usage.
+func rateLimitClient(c *fiber.Ctx) string {
+	if apiKey, ok := c.Locals(APIKeyLocal).(*domainAPIKey.APIKey); ok {
+		return "key:" + apiKey.ID
+	}
+	if user, ok := c.Locals("username").(string); ok && user != "" {
+		return "user:" + user
+	}
+	return "ip:" + c.IP()
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	domainAPIKey "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/apikey"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/ratelimit"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitBucket(t *testing.T) {
+	tests := []struct {
+		method, path, bucket string
+		limited              bool
+	}{
+		{"POST", "/send/message", ratelimit.BucketSend, true},
+		{"POST", "/send/image", ratelimit.BucketSend, true},
+		{"POST", "/send/bulk", ratelimit.BucketBulk, true},
+		{"POST", "/message/3EB0ABC/forward", ratelimit.BucketSend, true},
+		{"POST", "/message/3EB0ABC/react", ratelimit.BucketSend, true},
+		{"POST", "/message/3EB0ABC/revoke", ratelimit.BucketSend, true},
+		{"POST", "/newsletter/120363@newsletter/send", ratelimit.BucketSend, true},
+		{"POST", "/newsletter/120363@newsletter/message/42/react", ratelimit.BucketSend, true},
+		{"POST", "/chat/628111@s.whatsapp.net/presence", ratelimit.BucketSend, true},
+		{"POST", "/group/participants", ratelimit.BucketSend, true},
+		{"PUT", "/group/120363@g.us/settings", ratelimit.BucketSend, true},
+		{"GET", "/send/jobs/42", "", false},
+		{"GET", "/chats", "", false},
+		{"PUT", "/chat/628111@s.whatsapp.net/labels", "", false},
+		{"POST", "/chat/628111@s.whatsapp.net/sync-history", "", false},
+		{"POST", "/chats/prune", "", false},
+		{"POST", "/templates", "", false},
+		{"DELETE", "/jobs/42", "", false},
+		{"PUT", "/admin/rate-limits", "", false},
+		{"POST", "/app/login-with-code", "", false},
+		{"POST", "/send/bulk/", ratelimit.BucketBulk, true},
+		{"POST", "/SEND/BULK", ratelimit.BucketBulk, true},
+		{"POST", "/Send/Message/", ratelimit.BucketSend, true},
+		{"POST", "/Templates/", "", false},
+		{"PUT", "/ADMIN/rate-limits", "", false},
+		{"POST", "/Chat/628111@s.whatsapp.net/Sync-History/", "", false},
+	}
+	for _, tt := range tests {
+		bucket, limited := rateLimitBucket(tt.method, tt.path)
+		assert.Equal(t, tt.limited, limited, tt.method+" "+tt.path)
+		assert.Equal(t, tt.bucket, bucket, tt.method+" "+tt.path)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.Limits{
+		Send: ratelimit.Limit{PerMinute: 1, Burst: 2},
+		Bulk: ratelimit.Limit{PerMinute: 1, Burst: 1},
+	}, nil)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if id := c.Get(APIKeyHeader); id != "" {
+			c.Locals(APIKeyLocal, &domainAPIKey.APIKey{ID: id})
+		}
+		return c.Next()
+	})
+	app.Use(RateLimit(limiter))
+	app.Use(func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) })
+
+	send := func(path, key string) (int, string) {
+		req := httptest.NewRequest("POST", path, nil)
+		req.Header.Set(APIKeyHeader, key)
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		return resp.StatusCode, resp.Header.Get(fiber.HeaderRetryAfter)
+	}
+
+	status, _ := send("/send/message", "a")
+	assert.Equal(t, fiber.StatusNoContent, status)
+	status, _ = send("/send/message", "a")
+	assert.Equal(t, fiber.StatusNoContent, status)
+	status, retryAfter := send("/send/message", "a")
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+	assert.Equal(t, "60", retryAfter)
+
+	status, _ = send("/send/message", "b")
+	assert.Equal(t, fiber.StatusNoContent, status, "keys are limited apart")
+	status, _ = send("/send/bulk", "a")
+	assert.Equal(t, fiber.StatusNoContent, status, "bulk sends have their own bucket")
+	status, _ = send("/SEND/BULK/", "a")
+	assert.Equal(t, fiber.StatusTooManyRequests, status, "bulk sends are counted whatever the path's case")
+
+	assert.Equal(t, "key:a", limiter.Usage()[0].Client)
+}
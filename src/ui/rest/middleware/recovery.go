@@ -21,7 +21,7 @@ func Recovery() fiber.Handler {
 				res.Message = fmt.Sprintf("%v", err)
 
 				// Log the panic using logrus
-				logrus.Errorf("Panic recovered in middleware: %v", err)
+				logrus.WithContext(ctx.UserContext()).Errorf("Panic recovered in middleware: %v", err)
 
 				// Check for context deadline exceeded (timeout)
 				if ctxErr, ok := err.(error); ok && ctxErr == context.DeadlineExceeded {
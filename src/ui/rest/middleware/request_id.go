@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDLocal is the fiber local holding the ID of the request.
+const RequestIDLocal = "request_id"
+
+// requestIDPattern is what a request ID sent by the client must look like to
+// be kept, as it ends up in logs and chat storage
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// RequestID keeps the X-Request-ID of a request, or generates one, and puts it
+// in the request context so log lines and stored messages can be traced back
+// to the request. The ID is echoed in the response.
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Header values are only valid during the request, and the ID can
+		// outlive it in queued sends
+		requestID := strings.Clone(c.Get(RequestIDHeader))
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Locals(RequestIDLocal, requestID)
+		c.Set(RequestIDHeader, requestID)
+		c.SetUserContext(utils.ContextWithRequestID(c.UserContext(), requestID))
+		return c.Next()
+	}
+}
+
+// RequestLog logs every request with its method, path, status and duration.
+// It must run after RequestID for lines to carry the request ID.
+func RequestLog() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if fiberErr, ok := err.(*fiber.Error); ok {
+			status = fiberErr.Code
+		} else if err != nil {
+			status = fiber.StatusInternalServerError
+		}
+		logrus.WithContext(c.UserContext()).WithFields(logrus.Fields{
+			"method":      c.Method(),
+			"path":        c.Path(),
+			"status":      status,
+			"duration_ms": time.Since(start).Milliseconds(),
+			"ip":          c.IP(),
+		}).Info("HTTP request")
+		return err
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestID(t *testing.T) {
+	app := fiber.New()
+	app.Use(RequestID())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(utils.RequestIDFromContext(c.UserContext()))
+	})
+
+	request := func(header string) (body, echoed string) {
+		req := httptest.NewRequest("GET", "/", nil)
+		if header != "" {
+			req.Header.Set(RequestIDHeader, header)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		raw, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(raw), resp.Header.Get(RequestIDHeader)
+	}
+
+	body, echoed := request("trace-42:a.b_c")
+	assert.Equal(t, "trace-42:a.b_c", body, "a valid client ID is kept")
+	assert.Equal(t, "trace-42:a.b_c", echoed)
+
+	for _, header := range []string{"", "has spaces", "badé", strings.Repeat("a", 65)} {
+		body, echoed = request(header)
+		_, err := uuid.Parse(body)
+		assert.NoError(t, err, "%q is replaced with a generated ID", header)
+		assert.Equal(t, body, echoed)
+	}
+}
@@ -1,8 +1,11 @@
 package rest
 
 import (
+	"strconv"
+
 	domainNewsletter "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/newsletter"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/gofiber/fiber/v2"
 )
@@ -13,16 +16,149 @@ type Newsletter struct {
 
 func InitRestNewsletter(app fiber.Router, service domainNewsletter.INewsletterUsecase) Newsletter {
 	rest := Newsletter{Service: service}
+	app.Post("/newsletter", rest.CreateNewsletter)
+	app.Get("/newsletters", rest.ListNewsletters)
 	app.Post("/newsletter/unfollow", rest.Unfollow)
+	app.Get("/newsletter/messages", rest.GetNewsletterMessages)
+	app.Post("/newsletter/:newsletter_id/follow", rest.Follow)
+	app.Post("/newsletter/:newsletter_id/unfollow", rest.Unfollow)
+	app.Post("/newsletter/:newsletter_id/mute", rest.Mute)
+	app.Post("/newsletter/:newsletter_id/send", rest.Send)
+	app.Post("/newsletter/:newsletter_id/message/:server_id/react", rest.React)
 	return rest
 }
 
-func (controller *Newsletter) Unfollow(c *fiber.Ctx) error {
-	var request domainNewsletter.UnfollowRequest
+func (controller *Newsletter) CreateNewsletter(c *fiber.Ctx) error {
+	var request domainNewsletter.CreateNewsletterRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	if picture, err := c.FormFile("picture"); err == nil {
+		request.Picture = picture
+	}
+
+	response, err := controller.Service.CreateNewsletter(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success create newsletter",
+		Results: response,
+	})
+}
+
+func (controller *Newsletter) ListNewsletters(c *fiber.Ctx) error {
+	response, err := controller.Service.ListNewsletters(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)))
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get list newsletter",
+		Results: response,
+	})
+}
+
+func (controller *Newsletter) Follow(c *fiber.Ctx) error {
+	var request domainNewsletter.FollowRequest
+	request.NewsletterID = c.Params("newsletter_id")
+
+	response, err := controller.Service.Follow(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success follow newsletter",
+		Results: response,
+	})
+}
+
+func (controller *Newsletter) Mute(c *fiber.Ctx) error {
+	var request domainNewsletter.MuteRequest
+	// mute defaults to true, so muting needs no body
+	if len(c.Body()) > 0 {
+		err := c.BodyParser(&request)
+		utils.PanicIfNeeded(err)
+	}
+	request.NewsletterID = c.Params("newsletter_id")
+
+	err := controller.Service.Mute(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	message := "Success mute newsletter"
+	if request.Mute != nil && !*request.Mute {
+		message = "Success unmute newsletter"
+	}
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: message,
+	})
+}
+
+func (controller *Newsletter) Send(c *fiber.Ctx) error {
+	var request domainNewsletter.SendRequest
 	err := c.BodyParser(&request)
 	utils.PanicIfNeeded(err)
+	request.NewsletterID = c.Params("newsletter_id")
+
+	if image, err := c.FormFile("image"); err == nil {
+		request.Image = image
+	}
+
+	response, err := controller.Service.Send(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success send newsletter message",
+		Results: response,
+	})
+}
+
+func (controller *Newsletter) React(c *fiber.Ctx) error {
+	var request domainNewsletter.ReactRequest
+	// An empty body removes the reaction
+	if len(c.Body()) > 0 {
+		err := c.BodyParser(&request)
+		utils.PanicIfNeeded(err)
+	}
+	request.NewsletterID = c.Params("newsletter_id")
+	serverID, err := strconv.ParseInt(c.Params("server_id"), 10, 64)
+	if err != nil {
+		utils.PanicIfNeeded(pkgError.ValidationError("server_id: must be a number"))
+	}
+	request.ServerID = serverID
+
+	err = controller.Service.React(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	message := "Success react to newsletter message"
+	if request.Emoji == "" {
+		message = "Success remove reaction from newsletter message"
+	}
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: message,
+	})
+}
 
-	err = controller.Service.Unfollow(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+func (controller *Newsletter) Unfollow(c *fiber.Ctx) error {
+	var request domainNewsletter.UnfollowRequest
+	// The channel is in the path of /newsletter/:newsletter_id/unfollow, which needs no body
+	if len(c.Body()) > 0 {
+		err := c.BodyParser(&request)
+		utils.PanicIfNeeded(err)
+	}
+	if newsletterID := c.Params("newsletter_id"); newsletterID != "" {
+		request.NewsletterID = newsletterID
+	}
+
+	err := controller.Service.Unfollow(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
 	utils.PanicIfNeeded(err)
 
 	return c.JSON(utils.ResponseData{
@@ -31,3 +167,19 @@ func (controller *Newsletter) Unfollow(c *fiber.Ctx) error {
 		Message: "Success unfollow newsletter",
 	})
 }
+
+func (controller *Newsletter) GetNewsletterMessages(c *fiber.Ctx) error {
+	var request domainNewsletter.GetNewsletterMessagesRequest
+	err := c.QueryParser(&request)
+	utils.PanicIfNeeded(err)
+
+	response, err := controller.Service.GetNewsletterMessages(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get newsletter messages",
+		Results: response,
+	})
+}
@@ -0,0 +1,97 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/docs"
+	"github.com/gofiber/fiber/v2"
+	"gopkg.in/yaml.v3"
+)
+
+var swaggerUITemplate = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>WhatsApp API MultiDevice</title>
+    <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+<script>
+    window.ui = SwaggerUIBundle({url: {{.SpecURL}}, dom_id: "#swagger-ui"});
+</script>
+</body>
+</html>
+`))
+
+// InitRestOpenAPI serves the OpenAPI spec at /openapi.json and a Swagger UI
+// for it at /docs.
+func InitRestOpenAPI(app fiber.Router) {
+	spec, err := OpenAPIJSON(docs.OpenAPI, config.AppBasePath, config.AppVersion)
+	if err != nil {
+		panic(fmt.Sprintf("invalid OpenAPI spec: %v", err))
+	}
+	var page strings.Builder
+	if err := swaggerUITemplate.Execute(&page, map[string]string{"SpecURL": config.AppBasePath + "/openapi.json"}); err != nil {
+		panic(fmt.Sprintf("failed to render Swagger UI: %v", err))
+	}
+
+	app.Get("/openapi.json", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+		return c.Send(spec)
+	})
+	app.Get("/docs", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return c.SendString(page.String())
+	})
+}
+
+// OpenAPIJSON converts the YAML spec to JSON, pointing its server at basePath
+// so clients reach the API when it is mounted under a sub-path, and stamping
+// it with the app version.
+func OpenAPIJSON(spec []byte, basePath, version string) ([]byte, error) {
+	var document map[string]any
+	if err := yaml.Unmarshal(spec, &document); err != nil {
+		return nil, err
+	}
+
+	if basePath == "" {
+		basePath = "/"
+	}
+	document["servers"] = []any{map[string]any{"url": basePath}}
+	if info, ok := document["info"].(map[string]any); ok {
+		info["version"] = strings.TrimPrefix(version, "v")
+	}
+
+	return json.Marshal(jsonCompatible(document))
+}
+
+// jsonCompatible turns the maps YAML decodes with non-string keys, such as
+// unquoted response codes, into maps JSON can encode.
+func jsonCompatible(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, item := range value {
+			value[key] = jsonCompatible(item)
+		}
+		return value
+	case map[any]any:
+		converted := make(map[string]any, len(value))
+		for key, item := range value {
+			converted[fmt.Sprint(key)] = jsonCompatible(item)
+		}
+		return converted
+	case []any:
+		for i, item := range value {
+			value[i] = jsonCompatible(item)
+		}
+		return value
+	}
+	return value
+}
@@ -0,0 +1,73 @@
+package rest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/docs"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIJSON(t *testing.T) {
+	spec := []byte(`
+openapi: "3.0.0"
+info:
+  version: 1.0.0
+servers:
+  - url: http://localhost:3000
+paths:
+  /health:
+    get:
+      responses:
+        200:
+          description: OK
+`)
+	raw, err := OpenAPIJSON(spec, "/gowa", "v8.3.0")
+	require.NoError(t, err)
+
+	var document struct {
+		Info    struct{ Version string }
+		Servers []struct{ URL string }
+		Paths   map[string]map[string]struct {
+			Responses map[string]any
+		}
+	}
+	require.NoError(t, json.Unmarshal(raw, &document))
+	assert.Equal(t, "8.3.0", document.Info.Version)
+	require.Len(t, document.Servers, 1)
+	assert.Equal(t, "/gowa", document.Servers[0].URL, "the server follows the base path")
+	assert.Contains(t, document.Paths["/health"]["get"].Responses, "200", "unquoted response codes are kept")
+
+	raw, err = OpenAPIJSON(spec, "", "v8.3.0")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(raw, &document))
+	assert.Equal(t, "/", document.Servers[0].URL)
+
+	_, err = OpenAPIJSON(docs.OpenAPI, "", config.AppVersion)
+	assert.NoError(t, err, "the bundled spec converts")
+}
+
+func TestInitRestOpenAPI(t *testing.T) {
+	basePath := config.AppBasePath
+	t.Cleanup(func() { config.AppBasePath = basePath })
+	config.AppBasePath = "/gowa"
+
+	app := fiber.New()
+	InitRestOpenAPI(app.Group(config.AppBasePath))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/gowa/openapi.json", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, fiber.MIMEApplicationJSONCharsetUTF8, resp.Header.Get(fiber.HeaderContentType))
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/gowa/docs", nil))
+	require.NoError(t, err)
+	page, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(page), `url: "/gowa/openapi.json"`, "Swagger UI loads the spec under the base path")
+}
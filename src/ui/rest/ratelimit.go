@@ -0,0 +1,72 @@
+package rest
+
+import (
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/ratelimit"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
+	"github.com/gofiber/fiber/v2"
+)
+
+type RateLimit struct {
+	Limiter *ratelimit.Limiter
+}
+
+// InitRestRateLimit registers the rate limit admin routes. Changed limits
+// last until the next restart.
+func InitRestRateLimit(app fiber.Router, limiter *ratelimit.Limiter) RateLimit {
+	rest := RateLimit{Limiter: limiter}
+	app.Get("/admin/rate-limits", rest.GetLimits)
+	app.Put("/admin/rate-limits", rest.UpdateLimits)
+	app.Get("/admin/rate-limits/usage", rest.Usage)
+	return rest
+}
+
+func (handler *RateLimit) GetLimits(c *fiber.Ctx) error {
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Rate limits",
+		Results: handler.Limiter.Limits(),
+	})
+}
+
+// UpdateLimits changes the limits. Buckets missing from the body keep their
+// limits.
+func (handler *RateLimit) UpdateLimits(c *fiber.Ctx) error {
+	limits := handler.Limiter.Limits()
+	err := c.BodyParser(&limits)
+	utils.PanicIfNeeded(err)
+
+	err = validations.ValidateRateLimits(c.UserContext(), limits)
+	utils.PanicIfNeeded(err)
+	handler.Limiter.SetLimits(limits)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Rate limits updated",
+		Results: limits,
+	})
+}
+
+// Usage lists the buckets of every client that sent since its bucket was
+// last full, or of the client query parameter only.
+func (handler *RateLimit) Usage(c *fiber.Ctx) error {
+	usage := handler.Limiter.Usage()
+	if client := c.Query("client"); client != "" {
+		filtered := usage[:0]
+		for _, u := range usage {
+			if u.Client == client {
+				filtered = append(filtered, u)
+			}
+		}
+		usage = filtered
+	}
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Rate limit usage",
+		Results: usage,
+	})
+}
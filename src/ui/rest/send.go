@@ -14,20 +14,40 @@ type Send struct {
 func InitRestSend(app fiber.Router, service domainSend.ISendUsecase) Send {
 	rest := Send{Service: service}
 	app.Post("/send/message", rest.SendText)
+	app.Post("/send/bulk", rest.SendBulk)
 	app.Post("/send/image", rest.SendImage)
 	app.Post("/send/file", rest.SendFile)
 	app.Post("/send/video", rest.SendVideo)
 	app.Post("/send/sticker", rest.SendSticker)
+	app.Post("/send/status", rest.SendStatus)
 	app.Post("/send/contact", rest.SendContact)
 	app.Post("/send/link", rest.SendLink)
 	app.Post("/send/location", rest.SendLocation)
+	app.Post("/send/live-location", rest.SendLiveLocation)
 	app.Post("/send/audio", rest.SendAudio)
 	app.Post("/send/poll", rest.SendPoll)
 	app.Post("/send/presence", rest.SendPresence)
 	app.Post("/send/chat-presence", rest.SendChatPresence)
+	app.Get("/send/jobs/:job_id", rest.GetJob)
+	app.Post("/message/:message_id/forward", rest.ForwardMessage)
 	return rest
 }
 
+// sendResponse answers a send request, with 202 Accepted when the message was
+// only queued.
+func sendResponse(c *fiber.Ctx, response domainSend.GenericResponse) error {
+	status := fiber.StatusOK
+	if response.JobID != "" {
+		status = fiber.StatusAccepted
+	}
+	return c.Status(status).JSON(utils.ResponseData{
+		Status:  status,
+		Code:    "SUCCESS",
+		Message: response.Status,
+		Results: response,
+	})
+}
+
 func (controller *Send) SendText(c *fiber.Ctx) error {
 	var request domainSend.MessageRequest
 	err := c.BodyParser(&request)
@@ -38,6 +58,38 @@ func (controller *Send) SendText(c *fiber.Ctx) error {
 	response, err := controller.Service.SendText(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
 	utils.PanicIfNeeded(err)
 
+	return sendResponse(c, response)
+}
+
+func (controller *Send) SendBulk(c *fiber.Ctx) error {
+	var request domainSend.BulkMessageRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	response, err := controller.Service.SendBulk(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	status := fiber.StatusOK
+	if response.JobID != "" {
+		status = fiber.StatusAccepted
+	}
+	return c.Status(status).JSON(utils.ResponseData{
+		Status:  status,
+		Code:    "SUCCESS",
+		Message: response.Status,
+		Results: response,
+	})
+}
+
+func (controller *Send) ForwardMessage(c *fiber.Ctx) error {
+	var request domainSend.ForwardRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+	request.MessageID = c.Params("message_id")
+
+	response, err := controller.Service.ForwardMessage(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
 	return c.JSON(utils.ResponseData{
 		Status:  200,
 		Code:    "SUCCESS",
@@ -63,12 +115,7 @@ func (controller *Send) SendImage(c *fiber.Ctx) error {
 	response, err := controller.Service.SendImage(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
 	utils.PanicIfNeeded(err)
 
-	return c.JSON(utils.ResponseData{
-		Status:  200,
-		Code:    "SUCCESS",
-		Message: response.Status,
-		Results: response,
-	})
+	return sendResponse(c, response)
 }
 
 func (controller *Send) SendFile(c *fiber.Ctx) error {
@@ -85,12 +132,7 @@ func (controller *Send) SendFile(c *fiber.Ctx) error {
 	response, err := controller.Service.SendFile(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
 	utils.PanicIfNeeded(err)
 
-	return c.JSON(utils.ResponseData{
-		Status:  200,
-		Code:    "SUCCESS",
-		Message: response.Status,
-		Results: response,
-	})
+	return sendResponse(c, response)
 }
 
 func (controller *Send) SendVideo(c *fiber.Ctx) error {
@@ -108,12 +150,7 @@ func (controller *Send) SendVideo(c *fiber.Ctx) error {
 	response, err := controller.Service.SendVideo(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
 	utils.PanicIfNeeded(err)
 
-	return c.JSON(utils.ResponseData{
-		Status:  200,
-		Code:    "SUCCESS",
-		Message: response.Status,
-		Results: response,
-	})
+	return sendResponse(c, response)
 }
 
 func (controller *Send) SendSticker(c *fiber.Ctx) error {
@@ -131,12 +168,26 @@ func (controller *Send) SendSticker(c *fiber.Ctx) error {
 	response, err := controller.Service.SendSticker(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
 	utils.PanicIfNeeded(err)
 
-	return c.JSON(utils.ResponseData{
-		Status:  200,
-		Code:    "SUCCESS",
-		Message: response.Status,
-		Results: response,
-	})
+	return sendResponse(c, response)
+}
+
+func (controller *Send) SendStatus(c *fiber.Ctx) error {
+	var request domainSend.StatusRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	// Try to get the media but ignore errors if not provided
+	if imageFile, errFile := c.FormFile("image"); errFile == nil {
+		request.Image = imageFile
+	}
+	if videoFile, errFile := c.FormFile("video"); errFile == nil {
+		request.Video = videoFile
+	}
+
+	response, err := controller.Service.SendStatus(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return sendResponse(c, response)
 }
 
 func (controller *Send) SendContact(c *fiber.Ctx) error {
@@ -149,12 +200,7 @@ func (controller *Send) SendContact(c *fiber.Ctx) error {
 	response, err := controller.Service.SendContact(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
 	utils.PanicIfNeeded(err)
 
-	return c.JSON(utils.ResponseData{
-		Status:  200,
-		Code:    "SUCCESS",
-		Message: response.Status,
-		Results: response,
-	})
+	return sendResponse(c, response)
 }
 
 func (controller *Send) SendLink(c *fiber.Ctx) error {
@@ -167,12 +213,7 @@ func (controller *Send) SendLink(c *fiber.Ctx) error {
 	response, err := controller.Service.SendLink(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
 	utils.PanicIfNeeded(err)
 
-	return c.JSON(utils.ResponseData{
-		Status:  200,
-		Code:    "SUCCESS",
-		Message: response.Status,
-		Results: response,
-	})
+	return sendResponse(c, response)
 }
 
 func (controller *Send) SendLocation(c *fiber.Ctx) error {
@@ -185,12 +226,20 @@ func (controller *Send) SendLocation(c *fiber.Ctx) error {
 	response, err := controller.Service.SendLocation(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
 	utils.PanicIfNeeded(err)
 
-	return c.JSON(utils.ResponseData{
-		Status:  200,
-		Code:    "SUCCESS",
-		Message: response.Status,
-		Results: response,
-	})
+	return sendResponse(c, response)
+}
+
+func (controller *Send) SendLiveLocation(c *fiber.Ctx) error {
+	var request domainSend.LiveLocationRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	utils.SanitizePhone(&request.Phone)
+
+	response, err := controller.Service.SendLiveLocation(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return sendResponse(c, response)
 }
 
 func (controller *Send) SendAudio(c *fiber.Ctx) error {
@@ -208,12 +257,7 @@ func (controller *Send) SendAudio(c *fiber.Ctx) error {
 	response, err := controller.Service.SendAudio(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
 	utils.PanicIfNeeded(err)
 
-	return c.JSON(utils.ResponseData{
-		Status:  200,
-		Code:    "SUCCESS",
-		Message: response.Status,
-		Results: response,
-	})
+	return sendResponse(c, response)
 }
 
 func (controller *Send) SendPoll(c *fiber.Ctx) error {
@@ -226,12 +270,7 @@ func (controller *Send) SendPoll(c *fiber.Ctx) error {
 	response, err := controller.Service.SendPoll(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
 	utils.PanicIfNeeded(err)
 
-	return c.JSON(utils.ResponseData{
-		Status:  200,
-		Code:    "SUCCESS",
-		Message: response.Status,
-		Results: response,
-	})
+	return sendResponse(c, response)
 }
 
 func (controller *Send) SendPresence(c *fiber.Ctx) error {
@@ -267,3 +306,15 @@ func (controller *Send) SendChatPresence(c *fiber.Ctx) error {
 		Results: response,
 	})
 }
+
+func (controller *Send) GetJob(c *fiber.Ctx) error {
+	job, err := controller.Service.GetJob(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), c.Params("job_id"))
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Send job " + job.Status,
+		Results: job,
+	})
+}
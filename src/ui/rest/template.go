@@ -0,0 +1,92 @@
+package rest
+
+import (
+	domainTemplate "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/template"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type Template struct {
+	Service domainTemplate.ITemplateUsecase
+}
+
+// InitRestTemplate registers the message template routes of the device in
+// the request.
+func InitRestTemplate(app fiber.Router, service domainTemplate.ITemplateUsecase) Template {
+	rest := Template{Service: service}
+	app.Post("/templates", rest.CreateTemplate)
+	app.Get("/templates", rest.ListTemplates)
+	app.Get("/templates/:name", rest.GetTemplate)
+	app.Put("/templates/:name", rest.UpdateTemplate)
+	app.Delete("/templates/:name", rest.DeleteTemplate)
+	return rest
+}
+
+func (handler *Template) CreateTemplate(c *fiber.Ctx) error {
+	var request domainTemplate.CreateTemplateRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	tmpl, err := handler.Service.CreateTemplate(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Template created",
+		Results: tmpl,
+	})
+}
+
+func (handler *Template) ListTemplates(c *fiber.Ctx) error {
+	templates, err := handler.Service.ListTemplates(c.UserContext())
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "List templates",
+		Results: templates,
+	})
+}
+
+func (handler *Template) GetTemplate(c *fiber.Ctx) error {
+	tmpl, err := handler.Service.GetTemplate(c.UserContext(), c.Params("name"))
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Template",
+		Results: tmpl,
+	})
+}
+
+func (handler *Template) UpdateTemplate(c *fiber.Ctx) error {
+	var request domainTemplate.UpdateTemplateRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+	request.Name = c.Params("name")
+
+	tmpl, err := handler.Service.UpdateTemplate(c.UserContext(), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Template updated",
+		Results: tmpl,
+	})
+}
+
+func (handler *Template) DeleteTemplate(c *fiber.Ctx) error {
+	err := handler.Service.DeleteTemplate(c.UserContext(), c.Params("name"))
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Template deleted",
+		Results: nil,
+	})
+}
@@ -1,6 +1,8 @@
 package rest
 
 import (
+	"fmt"
+
 	domainUser "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/user"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
@@ -16,13 +18,23 @@ func InitRestUser(app fiber.Router, service domainUser.IUserUsecase) User {
 	app.Get("/user/info", rest.UserInfo)
 	app.Get("/user/avatar", rest.UserAvatar)
 	app.Post("/user/avatar", rest.UserChangeAvatar)
+	app.Put("/user/avatar", rest.UserChangeAvatar)
+	app.Delete("/user/avatar", rest.UserRemoveAvatar)
+	app.Put("/user/about", rest.UserChangeAbout)
 	app.Post("/user/pushname", rest.UserChangePushName)
+	app.Post("/user/presence", rest.UserChangePresence)
 	app.Get("/user/my/privacy", rest.UserMyPrivacySetting)
+	app.Get("/user/privacy", rest.UserPrivacy)
+	app.Put("/user/privacy", rest.UserSetPrivacy)
 	app.Get("/user/my/groups", rest.UserMyListGroups)
 	app.Get("/user/my/newsletters", rest.UserMyListNewsletter)
 	app.Get("/user/my/contacts", rest.UserMyListContacts)
 	app.Get("/user/check", rest.UserCheck)
+	app.Post("/user/check", rest.UserCheckPhones)
 	app.Get("/user/business-profile", rest.UserBusinessProfile)
+	app.Post("/user/block", rest.UserBlock)
+	app.Post("/user/unblock", rest.UserUnblock)
+	app.Get("/user/blocklist", rest.UserBlocklist)
 
 	return rest
 }
@@ -56,6 +68,16 @@ func (controller *User) UserAvatar(c *fiber.Ctx) error {
 
 	ctx := whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c))
 
+	// stream=true sends the picture itself instead of its expiring CDN URL
+	if c.QueryBool("stream", false) {
+		image, err := controller.Service.AvatarImage(ctx, request)
+		utils.PanicIfNeeded(err)
+
+		c.Set(fiber.HeaderContentType, image.ContentType)
+		c.Set(fiber.HeaderCacheControl, "private, max-age=300")
+		return c.SendStream(image.Body, int(image.Size))
+	}
+
 	response, err := controller.Service.Avatar(ctx, request)
 	utils.PanicIfNeeded(err)
 
@@ -72,18 +94,46 @@ func (controller *User) UserChangeAvatar(c *fiber.Ctx) error {
 	err := c.BodyParser(&request)
 	utils.PanicIfNeeded(err)
 
-	request.Avatar, err = c.FormFile("avatar")
-	utils.PanicIfNeeded(err)
+	if avatar, err := c.FormFile("avatar"); err == nil {
+		request.Avatar = avatar
+	}
 
 	ctx := whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c))
 
-	err = controller.Service.ChangeAvatar(ctx, request)
+	response, err := controller.Service.ChangeAvatar(ctx, request)
 	utils.PanicIfNeeded(err)
 
 	return c.JSON(utils.ResponseData{
 		Status:  200,
 		Code:    "SUCCESS",
 		Message: "Success change avatar",
+		Results: response,
+	})
+}
+
+func (controller *User) UserRemoveAvatar(c *fiber.Ctx) error {
+	err := controller.Service.RemoveAvatar(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)))
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success remove avatar",
+	})
+}
+
+func (controller *User) UserChangeAbout(c *fiber.Ctx) error {
+	var request domainUser.ChangeAboutRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	err = controller.Service.ChangeAbout(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success change about",
 	})
 }
 
@@ -100,6 +150,34 @@ func (controller *User) UserMyPrivacySetting(c *fiber.Ctx) error {
 	})
 }
 
+func (controller *User) UserPrivacy(c *fiber.Ctx) error {
+	response, err := controller.Service.Privacy(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)))
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get privacy",
+		Results: response,
+	})
+}
+
+func (controller *User) UserSetPrivacy(c *fiber.Ctx) error {
+	var request domainUser.SetPrivacyRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	response, err := controller.Service.SetPrivacy(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success update privacy",
+		Results: response,
+	})
+}
+
 func (controller *User) UserMyListGroups(c *fiber.Ctx) error {
 	deviceVal := c.Locals("device")
 	ctx := c.UserContext()
@@ -159,6 +237,21 @@ func (controller *User) UserChangePushName(c *fiber.Ctx) error {
 	})
 }
 
+func (controller *User) UserChangePresence(c *fiber.Ctx) error {
+	var request domainUser.ChangePresenceRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	err = controller.Service.ChangePresence(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success change presence to " + request.State,
+	})
+}
+
 func (controller *User) UserCheck(c *fiber.Ctx) error {
 	var request domainUser.CheckRequest
 	err := c.QueryParser(&request)
@@ -175,6 +268,22 @@ func (controller *User) UserCheck(c *fiber.Ctx) error {
 	})
 }
 
+func (controller *User) UserCheckPhones(c *fiber.Ctx) error {
+	var request domainUser.CheckPhonesRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	response, err := controller.Service.CheckPhones(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: fmt.Sprintf("%d of %d numbers are on WhatsApp", response.Registered, len(response.Data)),
+		Results: response,
+	})
+}
+
 func (controller *User) UserBusinessProfile(c *fiber.Ctx) error {
 	var request domainUser.BusinessProfileRequest
 	err := c.QueryParser(&request)
@@ -204,3 +313,47 @@ func getDeviceFromCtx(c *fiber.Ctx) *whatsapp.DeviceInstance {
 	}
 	return nil
 }
+
+func (controller *User) UserBlock(c *fiber.Ctx) error {
+	var request domainUser.BlockRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	response, err := controller.Service.Block(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success block " + request.Phone,
+		Results: response,
+	})
+}
+
+func (controller *User) UserUnblock(c *fiber.Ctx) error {
+	var request domainUser.BlockRequest
+	err := c.BodyParser(&request)
+	utils.PanicIfNeeded(err)
+
+	response, err := controller.Service.Unblock(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)), request)
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success unblock " + request.Phone,
+		Results: response,
+	})
+}
+
+func (controller *User) UserBlocklist(c *fiber.Ctx) error {
+	response, err := controller.Service.Blocklist(whatsapp.ContextWithDevice(c.UserContext(), getDeviceFromCtx(c)))
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "Success get blocklist",
+		Results: response,
+	})
+}
@@ -0,0 +1,29 @@
+package rest
+
+import (
+	domainApp "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/app"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type Webhook struct {
+	Service domainApp.IAppUsecase
+}
+
+func InitRestWebhook(app fiber.Router, service domainApp.IAppUsecase) Webhook {
+	rest := Webhook{Service: service}
+	app.Get("/webhooks", rest.ListWebhooks)
+	return rest
+}
+
+func (handler *Webhook) ListWebhooks(c *fiber.Ctx) error {
+	webhooks, err := handler.Service.ListWebhooks(c.UserContext())
+	utils.PanicIfNeeded(err)
+
+	return c.JSON(utils.ResponseData{
+		Status:  200,
+		Code:    "SUCCESS",
+		Message: "List webhooks",
+		Results: webhooks,
+	})
+}
@@ -0,0 +1,182 @@
+package websocket
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// eventBufferSize is how many frames a subscriber may fall behind before
+	// it is disconnected.
+	eventBufferSize = 256
+	eventPingPeriod = 30 * time.Second
+	eventPongWait   = 2 * eventPingPeriod
+	eventWriteWait  = 10 * time.Second
+)
+
+// Events fans webhook event envelopes out to the /ws/events subscribers.
+var Events = NewEventHub()
+
+// EventHub delivers event frames to subscribers without ever blocking the
+// publisher: a subscriber whose buffer is full is dropped instead.
+type EventHub struct {
+	mu          sync.Mutex
+	subscribers map[*EventSubscription]struct{}
+}
+
+// EventSubscription receives the frames matching its device and events.
+type EventSubscription struct {
+	deviceID string
+	events   map[string]struct{}
+	frames   chan []byte
+}
+
+func NewEventHub() *EventHub {
+	return &EventHub{subscribers: make(map[*EventSubscription]struct{})}
+}
+
+// Subscribe registers a subscriber for the events of deviceID (empty = every
+// device) named in events (empty = every event).
+func (h *EventHub) Subscribe(deviceID string, events []string, buffer int) *EventSubscription {
+	sub := &EventSubscription{deviceID: deviceID, events: make(map[string]struct{}), frames: make(chan []byte, buffer)}
+	for _, event := range events {
+		if event = strings.ToLower(strings.TrimSpace(event)); event != "" {
+			sub.events[event] = struct{}{}
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribe removes a subscriber and closes its frames channel.
+func (h *EventHub) Unsubscribe(sub *EventSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(sub)
+}
+
+func (h *EventHub) removeLocked(sub *EventSubscription) {
+	if _, ok := h.subscribers[sub]; ok {
+		delete(h.subscribers, sub)
+		close(sub.frames)
+	}
+}
+
+// HasSubscribers reports whether publishing would reach anyone.
+func (h *EventHub) HasSubscribers() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers) > 0
+}
+
+// Publish queues frame for every subscriber accepting eventName from a device
+// known by any of deviceIDs (its ID and JID).
+func (h *EventHub) Publish(eventName string, deviceIDs []string, frame []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subscribers {
+		if !sub.accepts(eventName, deviceIDs) {
+			continue
+		}
+		select {
+		case sub.frames <- frame:
+		default:
+			logrus.Warnf("Dropping /ws/events subscriber: %d frames behind", cap(sub.frames))
+			h.removeLocked(sub)
+		}
+	}
+}
+
+// Frames yields the subscribed frames. It is closed when the subscriber is
+// removed, including when it is dropped for falling behind.
+func (s *EventSubscription) Frames() <-chan []byte {
+	return s.frames
+}
+
+func (s *EventSubscription) accepts(eventName string, deviceIDs []string) bool {
+	if len(s.events) > 0 {
+		if _, ok := s.events[strings.ToLower(eventName)]; !ok {
+			return false
+		}
+	}
+	if s.deviceID == "" {
+		return true
+	}
+	for _, id := range deviceIDs {
+		if id == s.deviceID {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterEventRoutes serves GET /ws/events, streaming the webhook event
+// envelopes as JSON text frames. The device_id and events query parameters
+// (comma-separated) narrow the stream.
+func RegisterEventRoutes(app fiber.Router, hub *EventHub) {
+	app.Use("/ws/events", func(c *fiber.Ctx) error {
+		if websocket.IsWebSocketUpgrade(c) {
+			return c.Next()
+		}
+		return c.SendStatus(fiber.StatusUpgradeRequired)
+	})
+
+	app.Get("/ws/events", websocket.New(func(conn *websocket.Conn) {
+		var events []string
+		if v := conn.Query("events"); v != "" {
+			events = strings.Split(v, ",")
+		}
+		sub := hub.Subscribe(conn.Query("device_id"), events, eventBufferSize)
+		defer hub.Unsubscribe(sub)
+		streamEvents(conn, sub)
+	}))
+}
+
+func streamEvents(conn *websocket.Conn, sub *EventSubscription) {
+	defer func() { _ = conn.Close() }()
+
+	// Reading handles pongs and close frames; clients have nothing else to send
+	_ = conn.SetReadDeadline(time.Now().Add(eventPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(eventPongWait))
+	})
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(eventPingPeriod)
+	defer ping.Stop()
+	for {
+		select {
+		case frame, ok := <-sub.Frames():
+			_ = conn.SetWriteDeadline(time.Now().Add(eventWriteWait))
+			if !ok {
+				_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "too slow"))
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, frame); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(eventWriteWait)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
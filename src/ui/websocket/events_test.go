@@ -0,0 +1,94 @@
+package websocket
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	fasthttpWebsocket "github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventHubFilters(t *testing.T) {
+	hub := NewEventHub()
+	all := hub.Subscribe("", nil, 4)
+	device := hub.Subscribe("dev-1", []string{"Message", " message.ack "}, 4)
+
+	hub.Publish("message", []string{"628111@s.whatsapp.net", "dev-1"}, []byte("1"))
+	hub.Publish("call.offer", []string{"628111@s.whatsapp.net", "dev-1"}, []byte("2"))
+	hub.Publish("message.ack", []string{"628222@s.whatsapp.net", "dev-2"}, []byte("3"))
+	hub.Unsubscribe(all)
+	hub.Unsubscribe(device)
+
+	assert.Equal(t, []string{"1", "2", "3"}, drain(all))
+	assert.Equal(t, []string{"1"}, drain(device))
+	assert.False(t, hub.HasSubscribers())
+}
+
+func TestEventHubDropsSlowSubscriber(t *testing.T) {
+	hub := NewEventHub()
+	slow := hub.Subscribe("", nil, 2)
+	fast := hub.Subscribe("", nil, 8)
+
+	for _, frame := range []string{"1", "2", "3", "4"} {
+		hub.Publish("message", nil, []byte(frame))
+	}
+
+	// The slow subscriber keeps what it buffered and is then closed, while the
+	// publisher never blocked and the other subscriber got every frame
+	assert.Equal(t, []string{"1", "2"}, drain(slow))
+	hub.Unsubscribe(slow) // already dropped, must not panic
+	hub.Unsubscribe(fast)
+	assert.Equal(t, []string{"1", "2", "3", "4"}, drain(fast))
+}
+
+func TestEventStream(t *testing.T) {
+	hub := NewEventHub()
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	RegisterEventRoutes(app, hub)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = app.Listener(ln) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+
+	conn, _, err := fasthttpWebsocket.DefaultDialer.Dial("ws://"+ln.Addr().String()+"/ws/events?device_id=dev-1&events=message", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Eventually(t, hub.HasSubscribers, time.Second, 10*time.Millisecond)
+
+	hub.Publish("call.offer", []string{"dev-1"}, []byte(`{"event":"call.offer"}`))
+	hub.Publish("message", []string{"dev-2"}, []byte(`{"event":"message","device_id":"dev-2"}`))
+	hub.Publish("message", []string{"628111@s.whatsapp.net", "dev-1"}, []byte(`{"event":"message","device_id":"628111@s.whatsapp.net"}`))
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	messageType, frame, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, fasthttpWebsocket.TextMessage, messageType)
+	assert.JSONEq(t, `{"event":"message","device_id":"628111@s.whatsapp.net"}`, string(frame))
+
+	// Closing the client unsubscribes it
+	require.NoError(t, conn.Close())
+	assert.Eventually(t, func() bool { return !hub.HasSubscribers() }, time.Second, 10*time.Millisecond)
+}
+
+func TestEventStreamRequiresUpgrade(t *testing.T) {
+	app := fiber.New()
+	RegisterEventRoutes(app, NewEventHub())
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/ws/events", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUpgradeRequired, resp.StatusCode)
+}
+
+func drain(sub *EventSubscription) []string {
+	var frames []string
+	for frame := range sub.Frames() {
+		frames = append(frames, string(frame))
+	}
+	return frames
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	domainAPIKey "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/apikey"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// apiKeyPrefix starts every generated key, so leaked keys are easy to spot
+const apiKeyPrefix = "gowa_"
+
+// apiKeyTouchInterval is how stale last_used_at may get before a request
+// updates it, so busy keys don't write on every request
+const apiKeyTouchInterval = time.Minute
+
+type serviceAPIKey struct {
+	chatStorageRepo domainChatStorage.IChatStorageRepository
+}
+
+func NewAPIKeyService(chatStorageRepo domainChatStorage.IChatStorageRepository) domainAPIKey.IAPIKeyUsecase {
+	return &serviceAPIKey{
+		chatStorageRepo: chatStorageRepo,
+	}
+}
+
+// CreateAPIKey generates a key and stores its hash. The key is only returned
+// here.
+func (service serviceAPIKey) CreateAPIKey(ctx context.Context, request domainAPIKey.CreateAPIKeyRequest) (response domainAPIKey.CreateAPIKeyResponse, err error) {
+	request.Label = strings.TrimSpace(request.Label)
+	if err = validations.ValidateCreateAPIKey(ctx, request); err != nil {
+		return response, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err = rand.Read(secret); err != nil {
+		return response, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	record := &domainChatStorage.APIKey{
+		ID:        uuid.NewString(),
+		KeyHash:   hashAPIKey(key),
+		Label:     request.Label,
+		Scopes:    uniqueStrings(request.Scopes),
+		DeviceIDs: uniqueStrings(request.DeviceIDs),
+		CreatedAt: time.Now(),
+	}
+	if err = service.chatStorageRepo.StoreAPIKey(ctx, record); err != nil {
+		return response, fmt.Errorf("failed to store API key: %w", err)
+	}
+	return domainAPIKey.CreateAPIKeyResponse{APIKey: apiKeyFromRecord(record), Key: key}, nil
+}
+
+func (service serviceAPIKey) ListAPIKeys(ctx context.Context) ([]domainAPIKey.APIKey, error) {
+	records, err := service.chatStorageRepo.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	keys := make([]domainAPIKey.APIKey, 0, len(records))
+	for _, record := range records {
+		keys = append(keys, apiKeyFromRecord(record))
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey stops a key from authenticating. Revoked keys stay listed.
+func (service serviceAPIKey) RevokeAPIKey(ctx context.Context, id string) error {
+	found, err := service.chatStorageRepo.RevokeAPIKey(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if !found {
+		return pkgError.NotFoundError(fmt.Sprintf("API key %s not found", id))
+	}
+	return nil
+}
+
+func (service serviceAPIKey) Authenticate(ctx context.Context, key string) (*domainAPIKey.APIKey, error) {
+	record, err := service.chatStorageRepo.GetAPIKeyByHash(ctx, hashAPIKey(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up API key: %w", err)
+	}
+	if record == nil || record.Revoked {
+		return nil, pkgError.AuthError("invalid or revoked API key")
+	}
+
+	now := time.Now()
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) >= apiKeyTouchInterval {
+		if err := service.chatStorageRepo.TouchAPIKey(ctx, record.ID, now); err != nil {
+			logrus.WithContext(ctx).Warnf("Failed to update last use of API key %s: %v", record.ID, err)
+		} else {
+			record.LastUsedAt = &now
+		}
+	}
+
+	apiKey := apiKeyFromRecord(record)
+	return &apiKey, nil
+}
+
+// hashAPIKey is the hash keys are stored and looked up by. Keys are random, so
+// a plain SHA-256 is enough.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func apiKeyFromRecord(record *domainChatStorage.APIKey) domainAPIKey.APIKey {
+	key := domainAPIKey.APIKey{
+		ID:         record.ID,
+		Label:      record.Label,
+		Scopes:     record.Scopes,
+		DeviceIDs:  record.DeviceIDs,
+		CreatedAt:  record.CreatedAt,
+		LastUsedAt: record.LastUsedAt,
+		Revoked:    record.Revoked,
+	}
+	if key.Scopes == nil {
+		key.Scopes = []string{}
+	}
+	if key.DeviceIDs == nil {
+		key.DeviceIDs = []string{}
+	}
+	return key
+}
+
+// uniqueStrings drops repeated values, keeping the first of each.
+func uniqueStrings(values []string) []string {
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !slices.Contains(unique, value) {
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	domainAPIKey "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/apikey"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	repo := chatstorage.NewStorageRepository(db)
+	ctx := context.Background()
+	require.NoError(t, repo.InitializeSchema(ctx))
+	service := serviceAPIKey{chatStorageRepo: repo}
+
+	created, err := service.CreateAPIKey(ctx, domainAPIKey.CreateAPIKeyRequest{
+		Label: " crm ", Scopes: []string{"read", "send", "read"}, DeviceIDs: []string{"sales"},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, apiKeyPrefix))
+	assert.Equal(t, "crm", created.Label)
+	assert.Equal(t, []string{"read", "send"}, created.Scopes)
+	assert.Nil(t, created.LastUsedAt)
+
+	keys, err := service.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, created.ID, keys[0].ID)
+	assert.Equal(t, []string{"sales"}, keys[0].DeviceIDs)
+
+	key, err := service.Authenticate(ctx, created.Key)
+	require.NoError(t, err)
+	assert.True(t, key.HasScope(domainAPIKey.ScopeSend))
+	assert.False(t, key.HasScope(domainAPIKey.ScopeAdmin))
+	assert.True(t, key.AllowsDevice("sales"))
+	assert.False(t, key.AllowsDevice("support"))
+	require.NotNil(t, key.LastUsedAt, "the first use is recorded")
+
+	_, err = service.Authenticate(ctx, created.Key+"x")
+	assert.IsType(t, pkgError.AuthError(""), err)
+
+	require.NoError(t, service.RevokeAPIKey(ctx, created.ID))
+	_, err = service.Authenticate(ctx, created.Key)
+	assert.IsType(t, pkgError.AuthError(""), err, "revoked keys no longer authenticate")
+
+	keys, err = service.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	assert.True(t, keys[0].Revoked, "revoked keys stay listed")
+
+	assert.IsType(t, pkgError.NotFoundError(""), service.RevokeAPIKey(ctx, "missing"))
+}
+
+func TestAPIKeyLastUsedThrottle(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	repo := chatstorage.NewStorageRepository(db)
+	ctx := context.Background()
+	require.NoError(t, repo.InitializeSchema(ctx))
+	service := serviceAPIKey{chatStorageRepo: repo}
+
+	created, err := service.CreateAPIKey(ctx, domainAPIKey.CreateAPIKeyRequest{Label: "crm", Scopes: []string{"read"}})
+	require.NoError(t, err)
+
+	recent := time.Now().Add(-apiKeyTouchInterval / 2).UTC().Truncate(time.Second)
+	require.NoError(t, repo.TouchAPIKey(ctx, created.ID, recent))
+	key, err := service.Authenticate(ctx, created.Key)
+	require.NoError(t, err)
+	assert.True(t, recent.Equal(*key.LastUsedAt), "recent uses aren't recorded again")
+
+	stale := recent.Add(-apiKeyTouchInterval)
+	require.NoError(t, repo.TouchAPIKey(ctx, created.ID, stale))
+	key, err = service.Authenticate(ctx, created.Key)
+	require.NoError(t, err)
+	assert.True(t, key.LastUsedAt.After(recent))
+}
@@ -2,8 +2,10 @@ package usecase
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
 	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/websocket"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
 	fiberUtils "github.com/gofiber/fiber/v2/utils"
@@ -21,6 +24,8 @@ import (
 	"go.mau.fi/whatsmeow"
 )
 
+const healthPingTimeout = 5 * time.Second
+
 type serviceApp struct {
 	chatStorageRepo domainChatStorage.IChatStorageRepository
 	deviceManager   *whatsapp.DeviceManager
@@ -52,7 +57,7 @@ func (service *serviceApp) Login(ctx context.Context, deviceID string) (response
 	ch, err := client.GetQRChannel(qrCtx)
 	if err != nil {
 		qrCancel()
-		logrus.Errorf("[LOGIN][%s] GetQRChannel failed: %v", deviceID, err)
+		logrus.WithContext(ctx).Errorf("[LOGIN][%s] GetQRChannel failed: %v", deviceID, err)
 		if errors.Is(err, whatsmeow.ErrQRStoreContainsID) {
 			_ = client.Connect()
 			instance.UpdateStateFromClient()
@@ -71,24 +76,35 @@ func (service *serviceApp) Login(ctx context.Context, deviceID string) (response
 			response.Code = evt.Code
 			response.Duration = evt.Timeout / time.Second / 2
 			if evt.Event == "code" {
+				qrImage, err := qrcode.Encode(evt.Code, qrcode.Medium, 512)
+				if err != nil {
+					logrus.WithContext(ctx).Errorf("[LOGIN][%s] Error when encode qr code: %v", deviceID, err)
+					continue
+				}
+				whatsapp.PairingEvents.Publish(deviceID, whatsapp.PairingEventQR, map[string]any{
+					"code":     evt.Code,
+					"image":    "data:image/png;base64," + base64.StdEncoding.EncodeToString(qrImage),
+					"duration": int(evt.Timeout / time.Second),
+				})
+
 				qrPath := fmt.Sprintf("%s/scan-qr-%s.png", config.PathQrCode, fiberUtils.UUIDv4())
-				if err := qrcode.WriteFile(evt.Code, qrcode.Medium, 512, qrPath); err != nil {
-					logrus.Errorf("[LOGIN][%s] Error when write qr code to file: %v", deviceID, err)
+				if err := os.WriteFile(qrPath, qrImage, 0644); err != nil {
+					logrus.WithContext(ctx).Errorf("[LOGIN][%s] Error when write qr code to file: %v", deviceID, err)
 					continue
 				}
 				go func(path string, duration time.Duration) {
 					time.Sleep(duration * time.Second)
 					if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
-						logrus.Errorf("[LOGIN][%s] error when remove qrImage file: %v", deviceID, err)
+						logrus.WithContext(ctx).Errorf("[LOGIN][%s] error when remove qrImage file: %v", deviceID, err)
 					}
 				}(qrPath, response.Duration)
+				// Login only waits for the first code; later rotations reach clients through PairingEvents
 				select {
 				case chImage <- qrPath:
-				case <-qrCtx.Done():
-					return
+				default:
 				}
 			} else {
-				logrus.Errorf("[LOGIN][%s] error when get qrCode %s %v", deviceID, evt.Event, evt.Error)
+				logrus.WithContext(ctx).Errorf("[LOGIN][%s] error when get qrCode %s %v", deviceID, evt.Event, evt.Error)
 			}
 		}
 	}()
@@ -117,8 +133,9 @@ func (service *serviceApp) Login(ctx context.Context, deviceID string) (response
 }
 
 func (service *serviceApp) LoginWithCode(ctx context.Context, deviceID string, phoneNumber string) (loginCode string, err error) {
+	phoneNumber = utils.NormalizePhoneDigits(phoneNumber)
 	if err = validations.ValidateLoginWithCode(ctx, phoneNumber); err != nil {
-		logrus.Errorf("Error when validate login with code: %s", err.Error())
+		logrus.WithContext(ctx).Errorf("Error when validate login with code: %s", err.Error())
 		return loginCode, err
 	}
 
@@ -138,10 +155,13 @@ func (service *serviceApp) LoginWithCode(ctx context.Context, deviceID string, p
 		}
 	}
 
-	logrus.Infof("[LOGIN_CODE][%s] Starting phone pairing for number: %s", deviceID, phoneNumber)
+	logrus.WithContext(ctx).Infof("[LOGIN_CODE][%s] Starting phone pairing for number: %s", deviceID, phoneNumber)
 	loginCode, err = client.PairPhone(ctx, phoneNumber, true, whatsmeow.PairClientChrome, "Chrome (Linux)")
 	if err != nil {
-		logrus.Errorf("Error when pairing phone: %s", err.Error())
+		logrus.WithContext(ctx).Errorf("Error when pairing phone: %s", err.Error())
+		if errors.Is(err, whatsmeow.ErrPhoneNumberTooShort) || errors.Is(err, whatsmeow.ErrPhoneNumberIsNotInternational) {
+			return loginCode, pkgError.ValidationError(fmt.Sprintf("phone_number(%s): %s", phoneNumber, err.Error()))
+		}
 		return loginCode, err
 	}
 
@@ -238,6 +258,24 @@ func (service *serviceApp) FetchDevices(_ context.Context) (response []domainApp
 	return response, nil
 }
 
+// ListWebhooks returns the configured webhook targets. Passwords embedded in
+// their URLs are masked.
+func (service *serviceApp) ListWebhooks(_ context.Context) (response []domainApp.WebhookTarget, err error) {
+	response = []domainApp.WebhookTarget{}
+	for _, target := range whatsapp.WebhookTargets() {
+		webhookURL := target.URL
+		if parsed, err := url.Parse(target.URL); err == nil {
+			webhookURL = parsed.Redacted()
+		}
+		events := target.Events
+		if events == nil {
+			events = []string{}
+		}
+		response = append(response, domainApp.WebhookTarget{URL: webhookURL, Events: events})
+	}
+	return response, nil
+}
+
 func (service *serviceApp) ensureClient(ctx context.Context, deviceID string) (*whatsapp.DeviceInstance, *whatsmeow.Client, error) {
 	if deviceID == "" {
 		return nil, nil, fmt.Errorf("device id is required")
@@ -259,3 +297,30 @@ func (service *serviceApp) ensureClient(ctx context.Context, deviceID string) (*
 
 	return instance, client, nil
 }
+
+// Health checks that chat storage answers and counts the logged in devices.
+func (service *serviceApp) Health(ctx context.Context) (response domainApp.HealthResponse) {
+	response = domainApp.HealthResponse{Database: "ok", MinConnectedDevices: config.AppHealthMinConnectedDevices}
+
+	pingCtx, cancel := context.WithTimeout(ctx, healthPingTimeout)
+	defer cancel()
+	if service.chatStorageRepo == nil {
+		response.Database = "unavailable"
+	} else if err := service.chatStorageRepo.Ping(pingCtx); err != nil {
+		logrus.WithContext(ctx).Warnf("[HEALTH] Chat storage ping failed: %v", err)
+		response.Database = "unavailable"
+	}
+
+	if service.deviceManager != nil {
+		for _, inst := range service.deviceManager.ListDevices() {
+			response.Devices++
+			if inst.IsLoggedIn() {
+				response.ConnectedDevices++
+			}
+		}
+	}
+
+	response.ShuttingDown = whatsapp.IsShuttingDown()
+	response.Healthy = response.Database == "ok" && response.ConnectedDevices >= response.MinConnectedDevices && !response.ShuttingDown
+	return response
+}
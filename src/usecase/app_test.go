@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	repo := chatstorage.NewStorageRepository(db)
+	manager := whatsapp.NewDeviceManager(nil, nil, nil)
+	_, err = manager.CreateDevice(context.Background(), "sales", "")
+	require.NoError(t, err)
+	service := serviceApp{chatStorageRepo: repo, deviceManager: manager}
+
+	original := config.AppHealthMinConnectedDevices
+	t.Cleanup(func() { config.AppHealthMinConnectedDevices = original })
+
+	config.AppHealthMinConnectedDevices = 1
+	health := service.Health(context.Background())
+	assert.False(t, health.Healthy, "a device that isn't logged in must not count")
+	assert.Equal(t, "ok", health.Database)
+	assert.Equal(t, 1, health.Devices)
+	assert.Equal(t, 0, health.ConnectedDevices)
+
+	config.AppHealthMinConnectedDevices = 0
+	assert.True(t, service.Health(context.Background()).Healthy)
+
+	require.NoError(t, db.Close())
+	health = service.Health(context.Background())
+	assert.False(t, health.Healthy)
+	assert.Equal(t, "unavailable", health.Database)
+}
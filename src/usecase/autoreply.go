@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domainAutoReply "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/autoreply"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
+	"github.com/google/uuid"
+)
+
+type serviceAutoReply struct {
+	chatStorageRepo domainChatStorage.IChatStorageRepository
+}
+
+func NewAutoReplyService(chatStorageRepo domainChatStorage.IChatStorageRepository) domainAutoReply.IAutoReplyUsecase {
+	return &serviceAutoReply{
+		chatStorageRepo: chatStorageRepo,
+	}
+}
+
+func (service serviceAutoReply) CreateRule(ctx context.Context, request domainAutoReply.CreateRuleRequest) (domainAutoReply.Rule, error) {
+	deviceID := deviceIDFromContext(ctx)
+	now := time.Now()
+	rule := domainAutoReply.Rule{
+		ID:         uuid.NewString(),
+		DeviceID:   deviceID,
+		Pattern:    request.Pattern,
+		MatchType:  request.MatchType,
+		ReplyText:  request.ReplyText,
+		OnlyGroups: request.OnlyGroups,
+		OnlyDM:     request.OnlyDM,
+		Enabled:    request.Enabled == nil || *request.Enabled,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if rule.MatchType == "" {
+		rule.MatchType = domainAutoReply.MatchContains
+	}
+
+	if request.Position != nil {
+		rule.Position = *request.Position
+	} else {
+		existing, err := service.chatStorageRepo.ListAutoReplyRules(ctx, deviceID)
+		if err != nil {
+			return rule, fmt.Errorf("failed to list auto-reply rules: %w", err)
+		}
+		for _, other := range existing {
+			rule.Position = max(rule.Position, other.Position+1)
+		}
+	}
+
+	if err := validations.ValidateAutoReplyRule(ctx, rule); err != nil {
+		return rule, err
+	}
+	if err := service.store(ctx, rule); err != nil {
+		return rule, err
+	}
+	return rule, nil
+}
+
+// ListRules returns the rules of the device in the order they are tried.
+func (service serviceAutoReply) ListRules(ctx context.Context) ([]domainAutoReply.Rule, error) {
+	records, err := service.chatStorageRepo.ListAutoReplyRules(ctx, deviceIDFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list auto-reply rules: %w", err)
+	}
+	rules := make([]domainAutoReply.Rule, 0, len(records))
+	for _, record := range records {
+		rules = append(rules, autoReplyRuleFromRecord(record))
+	}
+	return rules, nil
+}
+
+func (service serviceAutoReply) GetRule(ctx context.Context, id string) (domainAutoReply.Rule, error) {
+	record, err := service.chatStorageRepo.GetAutoReplyRule(ctx, deviceIDFromContext(ctx), id)
+	if err != nil {
+		return domainAutoReply.Rule{}, fmt.Errorf("failed to get auto-reply rule: %w", err)
+	}
+	if record == nil {
+		return domainAutoReply.Rule{}, pkgError.NotFoundError(fmt.Sprintf("auto-reply rule %s not found", id))
+	}
+	return autoReplyRuleFromRecord(record), nil
+}
+
+func (service serviceAutoReply) UpdateRule(ctx context.Context, request domainAutoReply.UpdateRuleRequest) (domainAutoReply.Rule, error) {
+	rule, err := service.GetRule(ctx, request.ID)
+	if err != nil {
+		return rule, err
+	}
+	if request.Pattern != nil {
+		rule.Pattern = *request.Pattern
+	}
+	if request.MatchType != nil {
+		rule.MatchType = *request.MatchType
+	}
+	if request.ReplyText != nil {
+		rule.ReplyText = *request.ReplyText
+	}
+	if request.OnlyGroups != nil {
+		rule.OnlyGroups = *request.OnlyGroups
+	}
+	if request.OnlyDM != nil {
+		rule.OnlyDM = *request.OnlyDM
+	}
+	if request.Enabled != nil {
+		rule.Enabled = *request.Enabled
+	}
+	if request.Position != nil {
+		rule.Position = *request.Position
+	}
+	rule.UpdatedAt = time.Now()
+
+	if err := validations.ValidateAutoReplyRule(ctx, rule); err != nil {
+		return rule, err
+	}
+	if err := service.store(ctx, rule); err != nil {
+		return rule, err
+	}
+	return rule, nil
+}
+
+func (service serviceAutoReply) DeleteRule(ctx context.Context, id string) error {
+	deviceID := deviceIDFromContext(ctx)
+	found, err := service.chatStorageRepo.DeleteAutoReplyRule(ctx, deviceID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete auto-reply rule: %w", err)
+	}
+	if !found {
+		return pkgError.NotFoundError(fmt.Sprintf("auto-reply rule %s not found", id))
+	}
+	whatsapp.InvalidateAutoReplyRules(deviceID)
+	return nil
+}
+
+// store saves rule and makes the event handler load the device's rules again.
+func (service serviceAutoReply) store(ctx context.Context, rule domainAutoReply.Rule) error {
+	err := service.chatStorageRepo.StoreAutoReplyRule(ctx, &domainChatStorage.AutoReplyRule{
+		ID:         rule.ID,
+		DeviceID:   rule.DeviceID,
+		Position:   rule.Position,
+		Pattern:    rule.Pattern,
+		MatchType:  rule.MatchType,
+		ReplyText:  rule.ReplyText,
+		OnlyGroups: rule.OnlyGroups,
+		OnlyDM:     rule.OnlyDM,
+		Enabled:    rule.Enabled,
+		CreatedAt:  rule.CreatedAt,
+		UpdatedAt:  rule.UpdatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store auto-reply rule: %w", err)
+	}
+	whatsapp.InvalidateAutoReplyRules(rule.DeviceID)
+	return nil
+}
+
+func autoReplyRuleFromRecord(record *domainChatStorage.AutoReplyRule) domainAutoReply.Rule {
+	return domainAutoReply.Rule{
+		ID:         record.ID,
+		DeviceID:   record.DeviceID,
+		Position:   record.Position,
+		Pattern:    record.Pattern,
+		MatchType:  record.MatchType,
+		ReplyText:  record.ReplyText,
+		OnlyGroups: record.OnlyGroups,
+		OnlyDM:     record.OnlyDM,
+		Enabled:    record.Enabled,
+		CreatedAt:  record.CreatedAt,
+		UpdatedAt:  record.UpdatedAt,
+	}
+}
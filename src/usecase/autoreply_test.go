@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	domainAutoReply "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/autoreply"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoReplyRuleLifecycle(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+	service := serviceAutoReply{chatStorageRepo: repo}
+	ctx := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-1", nil, nil))
+
+	first, err := service.CreateRule(ctx, domainAutoReply.CreateRuleRequest{Pattern: "price", ReplyText: "See the list"})
+	require.NoError(t, err)
+	assert.Equal(t, domainAutoReply.MatchContains, first.MatchType, "match_type defaults to contains")
+	assert.True(t, first.Enabled)
+	assert.Equal(t, "dev-1", first.DeviceID)
+
+	second, err := service.CreateRule(ctx, domainAutoReply.CreateRuleRequest{Pattern: `^order \d+$`, MatchType: domainAutoReply.MatchRegex, ReplyText: "On its way"})
+	require.NoError(t, err)
+	assert.Equal(t, first.Position+1, second.Position, "new rules go last")
+
+	_, err = service.CreateRule(ctx, domainAutoReply.CreateRuleRequest{Pattern: "(", MatchType: domainAutoReply.MatchRegex, ReplyText: "never"})
+	assert.IsType(t, pkgError.ValidationError(""), err)
+
+	position, disabled := second.Position+1, false
+	updated, err := service.UpdateRule(ctx, domainAutoReply.UpdateRuleRequest{ID: first.ID, Position: &position, Enabled: &disabled})
+	require.NoError(t, err)
+	assert.False(t, updated.Enabled)
+	assert.Equal(t, first.Pattern, updated.Pattern, "unset fields are kept")
+
+	rules, err := service.ListRules(ctx)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, second.ID, rules[0].ID, "rules are listed by position")
+
+	other := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-2", nil, nil))
+	_, err = service.GetRule(other, first.ID)
+	assert.IsType(t, pkgError.NotFoundError(""), err, "rules belong to one device")
+
+	require.NoError(t, service.DeleteRule(ctx, first.ID))
+	assert.IsType(t, pkgError.NotFoundError(""), service.DeleteRule(ctx, first.ID))
+}
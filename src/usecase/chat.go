@@ -1,27 +1,50 @@
 package usecase
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	domainChat "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chat"
 	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainJob "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/job"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
 	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/ratelimit"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
 	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/appstate"
+	"go.mau.fi/whatsmeow/types"
 )
 
 type serviceChat struct {
 	chatStorageRepo domainChatStorage.IChatStorageRepository
+	jobs            domainJob.IJobUsecase
+	// historySyncs limits the history requests of each chat
+	historySyncs ratelimit.Store
 }
 
-func NewChatService(chatStorageRepo domainChatStorage.IChatStorageRepository) domainChat.IChatUsecase {
+func NewChatService(chatStorageRepo domainChatStorage.IChatStorageRepository, jobs domainJob.IJobUsecase) domainChat.IChatUsecase {
 	return &serviceChat{
 		chatStorageRepo: chatStorageRepo,
+		jobs:            jobs,
+		historySyncs:    ratelimit.NewMemoryStore(),
 	}
 }
 
@@ -37,17 +60,26 @@ func (service serviceChat) ListChats(ctx context.Context, request domainChat.Lis
 		Offset:     request.Offset,
 		SearchName: request.Search,
 		HasMedia:   request.HasMedia,
+		Archived:   request.Archived,
+		Pinned:     request.Pinned,
+		Blocked:    request.Blocked,
+		ChatType:   request.ChatType,
+	}
+	if request.Label != "" {
+		if filter.LabelID, err = service.resolveLabel(ctx, filter.DeviceID, request.Label); err != nil {
+			return response, err
+		}
 	}
 
 	// Get chats from storage
-	chats, err := service.chatStorageRepo.GetChats(filter)
+	chats, err := service.chatStorageRepo.GetChats(ctx, filter)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to get chats from storage")
 		return response, err
 	}
 
-	// Get total count for pagination
-	totalCount, err := service.chatStorageRepo.GetTotalChatCount()
+	// Get total count of chats matching the filter for pagination
+	totalCount, err := service.chatStorageRepo.CountChats(ctx, filter)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to get total chat count")
 		// Continue with partial data
@@ -57,16 +89,14 @@ func (service serviceChat) ListChats(ctx context.Context, request domainChat.Lis
 	// Convert entities to domain objects
 	chatInfos := make([]domainChat.ChatInfo, 0, len(chats))
 	for _, chat := range chats {
-		chatInfo := domainChat.ChatInfo{
-			JID:                 chat.JID,
-			Name:                chat.Name,
-			LastMessageTime:     chat.LastMessageTime.Format(time.RFC3339),
-			EphemeralExpiration: chat.EphemeralExpiration,
-			CreatedAt:           chat.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:           chat.UpdatedAt.Format(time.RFC3339),
+		chatInfo := toChatInfo(chat)
+		if strings.HasSuffix(chat.JID, "@"+types.GroupServer) {
+			participantCount := chat.ParticipantCount
+			chatInfo.ParticipantCount = &participantCount
 		}
 		chatInfos = append(chatInfos, chatInfo)
 	}
+	service.attachChatLabels(ctx, filter.DeviceID, chatInfos)
 
 	// Create pagination response
 	pagination := domainChat.PaginationResponse{
@@ -87,6 +117,27 @@ func (service serviceChat) ListChats(ctx context.Context, request domainChat.Lis
 	return response, nil
 }
 
+func (service serviceChat) ListUnreadChats(ctx context.Context) (response domainChat.ListUnreadChatsResponse, err error) {
+	deviceID := deviceIDFromContext(ctx)
+	if deviceID == "" {
+		return response, fmt.Errorf("device identification required")
+	}
+
+	chats, err := service.chatStorageRepo.GetChats(ctx, &domainChatStorage.ChatFilter{DeviceID: deviceID, Unread: true})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get unread chats from storage")
+		return response, err
+	}
+
+	response.Data = make([]domainChat.ChatInfo, 0, len(chats))
+	for _, chat := range chats {
+		response.Data = append(response.Data, toChatInfo(chat))
+		response.Total += chat.UnreadCount
+	}
+	service.attachChatLabels(ctx, deviceID, response.Data)
+	return response, nil
+}
+
 func (service serviceChat) GetChatMessages(ctx context.Context, request domainChat.GetChatMessagesRequest) (response domainChat.GetChatMessagesResponse, err error) {
 	if err = validations.ValidateGetChatMessages(ctx, &request); err != nil {
 		return response, err
@@ -97,7 +148,7 @@ func (service serviceChat) GetChatMessages(ctx context.Context, request domainCh
 		return response, fmt.Errorf("device identification required")
 	}
 
-	chat, err := service.chatStorageRepo.GetChat(request.ChatJID)
+	chat, err := service.chatStorageRepo.GetChat(ctx, request.ChatJID)
 	if err != nil {
 		logrus.WithError(err).WithField("chat_jid", request.ChatJID).Error("Failed to get chat info")
 		return response, err
@@ -106,45 +157,58 @@ func (service serviceChat) GetChatMessages(ctx context.Context, request domainCh
 		return response, fmt.Errorf("chat with JID %s not found", request.ChatJID)
 	}
 
-	// Create message filter from request
+	// Create message filter from request, scoped to the device for data isolation
 	filter := &domainChatStorage.MessageFilter{
-		ChatJID:   request.ChatJID,
-		Limit:     request.Limit,
-		Offset:    request.Offset,
-		MediaOnly: request.MediaOnly,
-		IsFromMe:  request.IsFromMe,
+		DeviceID:         deviceID,
+		ChatJID:          request.ChatJID,
+		Limit:            request.Limit,
+		Offset:           request.Offset,
+		MediaOnly:        request.MediaOnly,
+		IsFromMe:         request.IsFromMe,
+		IncludeReactions: request.IncludeReactions,
+		IncludeQuoted:    request.IncludeQuoted,
+	}
+	if request.Sender != "" {
+		sender, _ := utils.ParseJID(request.Sender)
+		filter.Sender = sender.ToNonAD().String()
+	}
+	for _, mediaType := range request.MediaTypes {
+		if mediaType == "text" {
+			mediaType = ""
+		}
+		filter.MediaTypes = append(filter.MediaTypes, mediaType)
 	}
 
-	// Parse time filters if provided
+	// Time range was validated above, so parsing cannot fail here
 	if request.StartTime != nil && *request.StartTime != "" {
-		startTime, err := time.Parse(time.RFC3339, *request.StartTime)
-		if err != nil {
-			return response, fmt.Errorf("invalid start_time format: %v", err)
-		}
+		startTime, _ := time.Parse(time.RFC3339, *request.StartTime)
 		filter.StartTime = &startTime
 	}
-
 	if request.EndTime != nil && *request.EndTime != "" {
-		endTime, err := time.Parse(time.RFC3339, *request.EndTime)
+		endTime, _ := time.Parse(time.RFC3339, *request.EndTime)
+		filter.EndTime = &endTime
+	}
+
+	if request.Cursor != "" {
+		before, beforeID, err := decodeMessageCursor(request.Cursor)
 		if err != nil {
-			return response, fmt.Errorf("invalid end_time format: %v", err)
+			return response, err
 		}
-		filter.EndTime = &endTime
+		filter.Before = &before
+		filter.BeforeID = beforeID
 	}
 
 	// Get messages from storage
 	var messages []*domainChatStorage.Message
 	if request.Search != "" {
 		// Use search functionality if search query is provided
-		messages, err = service.chatStorageRepo.SearchMessages(deviceID, request.ChatJID, request.Search, request.Limit)
+		messages, err = service.chatStorageRepo.SearchMessages(ctx, filter, request.Search)
 		if err != nil {
 			logrus.WithError(err).WithField("chat_jid", request.ChatJID).Error("Failed to search messages")
 			return response, err
 		}
 	} else {
-		// Use regular filter with device_id for data isolation
-		filter.DeviceID = deviceID
-		messages, err = service.chatStorageRepo.GetMessages(filter)
+		messages, err = service.chatStorageRepo.GetMessages(ctx, filter)
 		if err != nil {
 			logrus.WithError(err).WithField("chat_jid", request.ChatJID).Error("Failed to get messages")
 			return response, err
@@ -152,7 +216,7 @@ func (service serviceChat) GetChatMessages(ctx context.Context, request domainCh
 	}
 
 	// Get total message count for pagination
-	totalCount, err := service.chatStorageRepo.GetChatMessageCount(request.ChatJID)
+	totalCount, err := service.chatStorageRepo.GetChatMessageCountByDevice(ctx, deviceID, request.ChatJID)
 	if err != nil {
 		logrus.WithError(err).WithField("chat_jid", request.ChatJID).Error("Failed to get message count")
 		// Continue with partial data
@@ -162,32 +226,13 @@ func (service serviceChat) GetChatMessages(ctx context.Context, request domainCh
 	// Convert entities to domain objects
 	messageInfos := make([]domainChat.MessageInfo, 0, len(messages))
 	for _, message := range messages {
-		messageInfo := domainChat.MessageInfo{
-			ID:         message.ID,
-			ChatJID:    message.ChatJID,
-			SenderJID:  message.Sender,
-			Content:    message.Content,
-			Timestamp:  message.Timestamp.Format(time.RFC3339),
-			IsFromMe:   message.IsFromMe,
-			MediaType:  message.MediaType,
-			Filename:   message.Filename,
-			URL:        message.URL,
-			FileLength: message.FileLength,
-			CreatedAt:  message.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:  message.UpdatedAt.Format(time.RFC3339),
-		}
-		messageInfos = append(messageInfos, messageInfo)
+		messageInfos = append(messageInfos, toMessageInfo(message))
 	}
 
 	// Create chat info for response
-	chatInfo := domainChat.ChatInfo{
-		JID:                 chat.JID,
-		Name:                chat.Name,
-		LastMessageTime:     chat.LastMessageTime.Format(time.RFC3339),
-		EphemeralExpiration: chat.EphemeralExpiration,
-		CreatedAt:           chat.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:           chat.UpdatedAt.Format(time.RFC3339),
-	}
+	chatInfos := []domainChat.ChatInfo{toChatInfo(chat)}
+	service.attachChatLabels(ctx, deviceID, chatInfos)
+	chatInfo := chatInfos[0]
 
 	// Create pagination response
 	pagination := domainChat.PaginationResponse{
@@ -195,6 +240,10 @@ func (service serviceChat) GetChatMessages(ctx context.Context, request domainCh
 		Offset: request.Offset,
 		Total:  int(totalCount),
 	}
+	if request.Search == "" && len(messages) == request.Limit {
+		last := messages[len(messages)-1]
+		pagination.NextCursor = encodeMessageCursor(last.Timestamp, last.ID)
+	}
 
 	response.Data = messageInfos
 	response.Pagination = pagination
@@ -210,6 +259,107 @@ func (service serviceChat) GetChatMessages(ctx context.Context, request domainCh
 	return response, nil
 }
 
+// encodeMessageCursor builds the opaque cursor pointing just past the given message.
+func encodeMessageCursor(timestamp time.Time, messageID string) string {
+	raw := strconv.FormatInt(timestamp.UnixNano(), 10) + "|" + messageID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeMessageCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", pkgError.ValidationError("cursor: invalid format")
+	}
+	nanos, messageID, found := strings.Cut(string(raw), "|")
+	ts, err := strconv.ParseInt(nanos, 10, 64)
+	if !found || err != nil {
+		return time.Time{}, "", pkgError.ValidationError("cursor: invalid format")
+	}
+	return time.Unix(0, ts).UTC(), messageID, nil
+}
+
+func toChatInfo(chat *domainChatStorage.Chat) domainChat.ChatInfo {
+	chatInfo := domainChat.ChatInfo{
+		JID:                 chat.JID,
+		Name:                chat.Name,
+		ChatType:            chatType(chat),
+		LastMessageTime:     chat.LastMessageTime.Format(time.RFC3339),
+		EphemeralExpiration: chat.EphemeralExpiration,
+		CreatedAt:           chat.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:           chat.UpdatedAt.Format(time.RFC3339),
+		Archived:            chat.Archived,
+		Pinned:              chat.Pinned,
+		UnreadCount:         chat.UnreadCount,
+		Blocked:             chat.Blocked,
+		Labels:              []domainChat.ChatLabel{},
+	}
+	if chat.MutedUntil != nil {
+		chatInfo.MutedUntil = chat.MutedUntil.Format(time.RFC3339)
+	}
+	if chat.LeftAt != nil {
+		chatInfo.LeftAt = chat.LeftAt.Format(time.RFC3339)
+	}
+	return chatInfo
+}
+
+// resolveLabel returns the ID of the label of deviceID whose ID or name, ignoring
+// case, is label. Unknown labels are returned as they are and tag no chat.
+func (service serviceChat) resolveLabel(ctx context.Context, deviceID, label string) (string, error) {
+	labels, err := service.chatStorageRepo.ListLabels(ctx, deviceID)
+	if err != nil {
+		return "", fmt.Errorf("failed to list labels: %w", err)
+	}
+	for _, candidate := range labels {
+		if candidate.ID == label {
+			return candidate.ID, nil
+		}
+	}
+	for _, candidate := range labels {
+		if strings.EqualFold(candidate.Name, label) {
+			return candidate.ID, nil
+		}
+	}
+	return label, nil
+}
+
+// attachChatLabels sets the labels of chats. Failing to load them is logged
+// and leaves the chats unlabeled rather than failing the listing.
+func (service serviceChat) attachChatLabels(ctx context.Context, deviceID string, chats []domainChat.ChatInfo) {
+	if len(chats) == 0 {
+		return
+	}
+	jids := make([]string, 0, len(chats))
+	for _, chat := range chats {
+		jids = append(jids, chat.JID)
+	}
+	labels, err := service.chatStorageRepo.GetChatLabels(ctx, deviceID, jids)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to get chat labels")
+		return
+	}
+	for i := range chats {
+		for _, label := range labels[chats[i].JID] {
+			chats[i].Labels = append(chats[i].Labels, domainChat.ChatLabel{ID: label.ID, Name: label.Name, Color: label.Color})
+		}
+	}
+}
+
+// chatType returns the stored type of community chats and otherwise the type
+// the JID of the chat implies.
+func chatType(chat *domainChatStorage.Chat) string {
+	switch {
+	case chat.ChatType != "":
+		return chat.ChatType
+	case strings.HasSuffix(chat.JID, "@"+types.GroupServer):
+		return domainChatStorage.ChatTypeGroup
+	case strings.HasSuffix(chat.JID, "@"+types.NewsletterServer):
+		return domainChatStorage.ChatTypeNewsletter
+	case chat.JID == types.StatusBroadcastJID.String():
+		return domainChatStorage.ChatTypeStatus
+	}
+	return domainChatStorage.ChatTypeUser
+}
+
 func deviceIDFromContext(ctx context.Context) string {
 	if inst, ok := whatsapp.DeviceFromContext(ctx); ok && inst != nil {
 		if jid := inst.JID(); jid != "" {
@@ -248,6 +398,11 @@ func (service serviceChat) PinChat(ctx context.Context, request domainChat.PinCh
 		return response, err
 	}
 
+	// Update local storage immediately; the app state echo may never arrive
+	if err := service.chatStorageRepo.UpdateChatFlags(ctx, deviceIDFromContext(ctx), targetJID.String(), domainChatStorage.ChatFlags{Pinned: &request.Pinned}); err != nil {
+		logrus.WithError(err).WithField("chat_jid", request.ChatJID).Warn("Failed to store pinned state")
+	}
+
 	// Build response
 	response.Status = "success"
 	response.ChatJID = request.ChatJID
@@ -282,6 +437,12 @@ func (service serviceChat) SetDisappearingTimer(ctx context.Context, request dom
 	if err != nil {
 		return response, err
 	}
+	// Members may change the timer of unlocked groups, as in SetGroupSettings
+	if targetJID.Server == types.GroupServer {
+		if err = requireGroupAdmin(ctx, client, targetJID, false, "change its disappearing messages"); err != nil {
+			return response, err
+		}
+	}
 
 	// Set disappearing timer using whatsmeow
 	if err = client.SetDisappearingTimer(ctx, targetJID, time.Duration(request.TimerSeconds)*time.Second, time.Now()); err != nil {
@@ -292,16 +453,19 @@ func (service serviceChat) SetDisappearingTimer(ctx context.Context, request dom
 		return response, err
 	}
 
-	// Update local storage immediately for consistency
-	if existingChat, _ := service.chatStorageRepo.GetChat(request.ChatJID); existingChat != nil {
-		existingChat.EphemeralExpiration = request.TimerSeconds
-		_ = service.chatStorageRepo.StoreChat(existingChat)
+	// Update local storage immediately, so messages sent right away use the new timer
+	previous, err := service.chatStorageRepo.SetEphemeralExpiration(ctx, deviceIDFromContext(ctx), targetJID.ToNonAD().String(), request.TimerSeconds)
+	if err != nil {
+		logrus.WithError(err).WithField("chat_jid", request.ChatJID).Warn("Failed to store disappearing timer")
 	}
 
 	// Build response
 	response.Status = "success"
 	response.ChatJID = request.ChatJID
 	response.TimerSeconds = request.TimerSeconds
+	response.Duration = disappearingDuration(request.TimerSeconds)
+	response.PreviousTimerSeconds = previous
+	response.PreviousDuration = disappearingDuration(previous)
 
 	if request.TimerSeconds == 0 {
 		response.Message = "Disappearing messages disabled"
@@ -317,6 +481,18 @@ func (service serviceChat) SetDisappearingTimer(ctx context.Context, request dom
 	return response, nil
 }
 
+// disappearingDuration names a disappearing message timer the way
+// SetDisappearingTimerRequest.Duration does. Timers WhatsApp doesn't offer,
+// which older chats may still have, are given in seconds.
+func disappearingDuration(seconds uint32) string {
+	for duration, timer := range domainChat.DisappearingDurations {
+		if timer == seconds {
+			return duration
+		}
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
+
 func (service serviceChat) ArchiveChat(ctx context.Context, request domainChat.ArchiveChatRequest) (response domainChat.ArchiveChatResponse, err error) {
 	if err = validations.ValidateArchiveChat(ctx, &request); err != nil {
 		return response, err
@@ -345,6 +521,11 @@ func (service serviceChat) ArchiveChat(ctx context.Context, request domainChat.A
 		return response, err
 	}
 
+	// Update local storage immediately; the app state echo may never arrive
+	if err := service.chatStorageRepo.UpdateChatFlags(ctx, deviceIDFromContext(ctx), targetJID.String(), domainChatStorage.ChatFlags{Archived: &request.Archived}); err != nil {
+		logrus.WithError(err).WithField("chat_jid", request.ChatJID).Warn("Failed to store archived state")
+	}
+
 	// Build response
 	response.Status = "success"
 	response.ChatJID = request.ChatJID
@@ -363,3 +544,969 @@ func (service serviceChat) ArchiveChat(ctx context.Context, request domainChat.A
 
 	return response, nil
 }
+
+// markReadFn is swapped in tests to avoid a live WhatsApp connection.
+var markReadFn = func(ctx context.Context, client *whatsmeow.Client, ids []types.MessageID, chat, sender types.JID) error {
+	return client.MarkRead(ctx, ids, time.Now(), chat, sender)
+}
+
+// MarkChatRead sends read receipts for messages of a chat and clears their
+// unread state. IDs that can't be marked are reported per ID instead of
+// failing the others.
+func (service serviceChat) MarkChatRead(ctx context.Context, request domainChat.MarkChatReadRequest) (response domainChat.MarkChatReadResponse, err error) {
+	if err = validations.ValidateMarkChatRead(ctx, &request); err != nil {
+		return response, err
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+
+	chat, err := utils.ValidateJidWithLogin(client, request.ChatJID)
+	if err != nil {
+		return response, err
+	}
+	return service.markRead(ctx, client, chat.ToNonAD(), request)
+}
+
+// markRead sends the read receipts of MarkChatRead once chat is resolved.
+func (service serviceChat) markRead(ctx context.Context, client *whatsmeow.Client, chat types.JID, request domainChat.MarkChatReadRequest) (response domainChat.MarkChatReadResponse, err error) {
+	response.ChatJID = chat.String()
+
+	var messages []*domainChatStorage.Message
+	messages, response.Errors, err = service.messagesToMarkRead(ctx, chat, request)
+	if err != nil {
+		return response, err
+	}
+
+	var read []string
+	for sender, ids := range readReceiptsBySender(chat, messages) {
+		if err := markReadFn(ctx, client, ids, chat, sender); err != nil {
+			logrus.WithError(err).WithField("chat_jid", response.ChatJID).Warn("Failed to send read receipts")
+			for _, id := range ids {
+				response.Errors = append(response.Errors, domainChat.MarkReadError{MessageID: id, Error: fmt.Sprintf("failed to send read receipt: %v", err)})
+			}
+			continue
+		}
+		read = append(read, ids...)
+	}
+	response.ReceiptsSent = len(read)
+
+	if _, err := service.chatStorageRepo.MarkMessagesRead(ctx, deviceIDFromContext(ctx), response.ChatJID, read); err != nil {
+		logrus.WithError(err).WithField("chat_jid", response.ChatJID).Warn("Failed to store read state")
+	}
+	return response, nil
+}
+
+// messagesToMarkRead looks up the messages request marks read in chat
+// storage. Listed IDs that are unknown, belong to another chat or were sent
+// by us are returned as errors.
+func (service serviceChat) messagesToMarkRead(ctx context.Context, chat types.JID, request domainChat.MarkChatReadRequest) ([]*domainChatStorage.Message, []domainChat.MarkReadError, error) {
+	deviceID := deviceIDFromContext(ctx)
+	if request.AllBefore != "" {
+		// Validated above, so parsing cannot fail here
+		before, _ := time.Parse(time.RFC3339, request.AllBefore)
+		isFromMe := false
+		messages, err := service.chatStorageRepo.GetMessages(ctx, &domainChatStorage.MessageFilter{
+			DeviceID: deviceID, ChatJID: chat.String(), EndTime: &before, IsFromMe: &isFromMe, Unread: true,
+		})
+		return messages, nil, err
+	}
+
+	var (
+		messages []*domainChatStorage.Message
+		failed   []domainChat.MarkReadError
+	)
+	for _, id := range utils.UniqueStrings(request.MessageIDs) {
+		message, err := service.chatStorageRepo.GetMessageByDevice(ctx, deviceID, id)
+		switch {
+		case err != nil:
+			return nil, nil, fmt.Errorf("failed to look up message %s: %w", id, err)
+		case message == nil:
+			failed = append(failed, domainChat.MarkReadError{MessageID: id, Error: "message not found"})
+		case message.ChatJID != chat.String():
+			failed = append(failed, domainChat.MarkReadError{MessageID: id, Error: fmt.Sprintf("message belongs to chat %s", message.ChatJID)})
+		case message.IsFromMe:
+			failed = append(failed, domainChat.MarkReadError{MessageID: id, Error: "message was sent by you"})
+		default:
+			messages = append(messages, message)
+		}
+	}
+	return messages, failed, nil
+}
+
+// readReceiptsBySender groups the IDs of messages by who sent them, since a
+// read receipt covers the messages of one sender. Direct chats only have one.
+func readReceiptsBySender(chat types.JID, messages []*domainChatStorage.Message) map[types.JID][]types.MessageID {
+	receipts := make(map[types.JID][]types.MessageID)
+	for _, message := range messages {
+		var sender types.JID
+		if chat.Server == types.GroupServer {
+			sender, _ = types.ParseJID(message.Sender)
+			sender = sender.ToNonAD()
+		}
+		receipts[sender] = append(receipts[sender], message.ID)
+	}
+	return receipts
+}
+
+// chatPresenceStates maps the states of SetChatPresence to what WhatsApp
+// sends: recording is composing with audio media.
+var chatPresenceStates = map[string]struct {
+	state types.ChatPresence
+	media types.ChatPresenceMedia
+}{
+	"typing":    {types.ChatPresenceComposing, types.ChatPresenceMediaText},
+	"recording": {types.ChatPresenceComposing, types.ChatPresenceMediaAudio},
+	"paused":    {types.ChatPresencePaused, types.ChatPresenceMediaText},
+}
+
+func (service serviceChat) SetChatPresence(ctx context.Context, request domainChat.SetChatPresenceRequest) (response domainChat.SetChatPresenceResponse, err error) {
+	if err = validations.ValidateSetChatPresence(ctx, &request); err != nil {
+		return response, err
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+
+	targetJID, err := utils.ValidateJidWithLogin(client, request.ChatJID)
+	if err != nil {
+		return response, err
+	}
+
+	presence := chatPresenceStates[request.State]
+	if err = client.SendChatPresence(ctx, targetJID, presence.state, presence.media); err != nil {
+		return response, fmt.Errorf("failed to send %s presence to %s: %w", request.State, targetJID, err)
+	}
+
+	response.ChatJID = targetJID.String()
+	response.State = request.State
+	return response, nil
+}
+
+func (service serviceChat) SearchMessages(ctx context.Context, request domainChat.SearchMessagesRequest) (response domainChat.SearchMessagesResponse, err error) {
+	request.Query = strings.TrimSpace(request.Query)
+	if err = validations.ValidateSearchMessages(ctx, &request); err != nil {
+		return response, err
+	}
+
+	deviceID := deviceIDFromContext(ctx)
+	if deviceID == "" {
+		return response, fmt.Errorf("device identification required")
+	}
+
+	// Fetch one extra row to know whether another page exists
+	results, err := service.chatStorageRepo.SearchMessagesGlobal(ctx, deviceID, request.Query, request.Limit+1, request.Offset)
+	if err != nil {
+		logrus.WithError(err).WithField("device_id", deviceID).Error("Failed to search messages")
+		return response, err
+	}
+
+	hasMore := len(results) > request.Limit
+	if hasMore {
+		results = results[:request.Limit]
+	}
+
+	response.Data = make([]domainChat.SearchMessageInfo, 0, len(results))
+	for _, result := range results {
+		response.Data = append(response.Data, domainChat.SearchMessageInfo{
+			MessageInfo: domainChat.MessageInfo{
+				ID:         result.ID,
+				ChatJID:    result.ChatJID,
+				SenderJID:  result.Sender,
+				Content:    result.Content,
+				Timestamp:  result.Timestamp.Format(time.RFC3339),
+				IsFromMe:   result.IsFromMe,
+				MediaType:  result.MediaType,
+				Filename:   result.Filename,
+				URL:        result.URL,
+				FileLength: result.FileLength,
+				CreatedAt:  result.CreatedAt.Format(time.RFC3339),
+				UpdatedAt:  result.UpdatedAt.Format(time.RFC3339),
+				EditedAt:   formatEditedAt(result.EditedAt),
+				IsDeleted:  result.IsDeleted,
+				ReplyToID:  result.ReplyToID,
+				Location:   toLocationInfo(result.Location),
+				Contacts:   toContactCardInfos(result.Contacts),
+				IsAnimated: result.IsAnimated,
+				IsViewOnce: result.IsViewOnce,
+				ServerID:   result.ServerID,
+			},
+			ChatName: result.ChatName,
+		})
+	}
+	response.Pagination = domainChat.SearchPaginationResponse{
+		Limit:   request.Limit,
+		Offset:  request.Offset,
+		HasMore: hasMore,
+	}
+
+	return response, nil
+}
+
+// exportedMessage is the per-message record written by ExportChatMessages; the
+// CSV columns follow the same order.
+type exportedMessage struct {
+	ID        string `json:"id"`
+	Sender    string `json:"sender"`
+	Timestamp string `json:"timestamp"`
+	Content   string `json:"content"`
+	MediaType string `json:"media_type"`
+	Filename  string `json:"filename"`
+}
+
+func (service serviceChat) GetMessageEditHistory(ctx context.Context, request domainChat.GetMessageEditHistoryRequest) (response domainChat.GetMessageEditHistoryResponse, err error) {
+	if err = validations.ValidateGetMessageEditHistory(ctx, &request); err != nil {
+		return response, err
+	}
+
+	deviceID := deviceIDFromContext(ctx)
+	if deviceID == "" {
+		return response, fmt.Errorf("device identification required")
+	}
+
+	edits, err := service.chatStorageRepo.GetMessageEditHistory(ctx, deviceID, request.MessageID, request.ChatJID)
+	if err != nil {
+		logrus.WithError(err).WithField("message_id", request.MessageID).Error("Failed to get message edit history")
+		return response, err
+	}
+
+	response.MessageID = request.MessageID
+	response.ChatJID = request.ChatJID
+	response.Edits = make([]domainChat.MessageEditInfo, 0, len(edits))
+	for _, edit := range edits {
+		response.Edits = append(response.Edits, domainChat.MessageEditInfo{
+			Content:  edit.Content,
+			EditedAt: edit.EditedAt.Format(time.RFC3339),
+		})
+	}
+	return response, nil
+}
+
+// GetPinnedMessages lists the messages currently pinned in a chat.
+func (service serviceChat) GetPinnedMessages(ctx context.Context, request domainChat.GetPinnedMessagesRequest) (response domainChat.GetPinnedMessagesResponse, err error) {
+	if err = validations.ValidateGetPinnedMessages(ctx, &request); err != nil {
+		return response, err
+	}
+
+	deviceID := deviceIDFromContext(ctx)
+	if deviceID == "" {
+		return response, fmt.Errorf("device identification required")
+	}
+
+	messages, err := service.chatStorageRepo.GetPinnedMessages(ctx, deviceID, request.ChatJID, time.Now())
+	if err != nil {
+		logrus.WithError(err).WithField("chat_jid", request.ChatJID).Error("Failed to get pinned messages")
+		return response, err
+	}
+
+	response.ChatJID = request.ChatJID
+	response.Data = make([]domainChat.MessageInfo, 0, len(messages))
+	for _, message := range messages {
+		response.Data = append(response.Data, toMessageInfo(message))
+	}
+	return response, nil
+}
+
+// DownloadMessageMedia fetches the attachment of a stored message from WhatsApp
+// with the media keys kept in chat storage. An empty deviceID means the device
+// in ctx. Media no longer on WhatsApp servers yields a pkgError.MediaExpiredError.
+func (service serviceChat) DownloadMessageMedia(ctx context.Context, deviceID, chatJID, messageID string) (data []byte, mimeType string, err error) {
+	message, err := service.storedMediaMessage(ctx, deviceID, chatJID, messageID)
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(message.FileLength) > config.WhatsappSettingMaxDownloadSize {
+		return nil, "", fmt.Errorf("file size exceeds the maximum limit of %d bytes", config.WhatsappSettingMaxDownloadSize)
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return nil, "", pkgError.ErrWaCLI
+	}
+	downloadable, err := whatsapp.StoredMediaDownloadable(message)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err = client.Download(ctx, downloadable)
+	if errors.Is(err, whatsmeow.ErrMediaDownloadFailedWith404) || errors.Is(err, whatsmeow.ErrMediaDownloadFailedWith410) {
+		return nil, "", pkgError.MediaExpiredError(fmt.Sprintf("media of message %s is no longer available on WhatsApp servers, request it again with retry=true", messageID))
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download media: %w", err)
+	}
+
+	mimeType = mime.TypeByExtension(filepath.Ext(message.Filename))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	return data, mimeType, nil
+}
+
+// RequestMediaRetry asks the sender of a stored message to upload its expired
+// attachment again. Once they do, DownloadMessageMedia works again.
+func (service serviceChat) RequestMediaRetry(ctx context.Context, deviceID, chatJID, messageID string) (err error) {
+	message, err := service.storedMediaMessage(ctx, deviceID, chatJID, messageID)
+	if err != nil {
+		return err
+	}
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return pkgError.ErrWaCLI
+	}
+	if err = whatsapp.RequestMediaRetry(ctx, client, message); err != nil {
+		return fmt.Errorf("failed to request media retry: %w", err)
+	}
+	return nil
+}
+
+// storedMediaMessage looks up a message of chatJID that has an attachment.
+func (service serviceChat) storedMediaMessage(ctx context.Context, deviceID, chatJID, messageID string) (*domainChatStorage.Message, error) {
+	if deviceID == "" {
+		deviceID = deviceIDFromContext(ctx)
+	}
+	if deviceID == "" {
+		return nil, fmt.Errorf("device identification required")
+	}
+	if messageID == "" {
+		return nil, pkgError.ValidationError("message_id: cannot be blank.")
+	}
+	jid, err := utils.ParseJID(chatJID)
+	if err != nil {
+		return nil, pkgError.ValidationError(err.Error())
+	}
+
+	message, err := service.chatStorageRepo.GetMessageByDevice(ctx, deviceID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message == nil || message.ChatJID != jid.ToNonAD().String() {
+		return nil, fmt.Errorf("message %s not found in chat %s", messageID, jid.ToNonAD().String())
+	}
+	if message.MediaType == "" || message.URL == "" || len(message.MediaKey) == 0 {
+		return nil, fmt.Errorf("message %s does not contain downloadable media", messageID)
+	}
+	return message, nil
+}
+
+// toMessageInfo converts a stored message for the API.
+func toMessageInfo(message *domainChatStorage.Message) domainChat.MessageInfo {
+	messageInfo := domainChat.MessageInfo{
+		ID:           message.ID,
+		ChatJID:      message.ChatJID,
+		SenderJID:    message.Sender,
+		Content:      message.Content,
+		Timestamp:    message.Timestamp.Format(time.RFC3339),
+		IsFromMe:     message.IsFromMe,
+		MediaType:    message.MediaType,
+		Filename:     message.Filename,
+		URL:          message.URL,
+		FileLength:   message.FileLength,
+		CreatedAt:    message.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    message.UpdatedAt.Format(time.RFC3339),
+		EditedAt:     formatEditedAt(message.EditedAt),
+		IsDeleted:    message.IsDeleted,
+		Status:       message.Status,
+		ReplyToID:    message.ReplyToID,
+		Location:     toLocationInfo(message.Location),
+		Contacts:     toContactCardInfos(message.Contacts),
+		IsAnimated:   message.IsAnimated,
+		IsViewOnce:   message.IsViewOnce,
+		ServerID:     message.ServerID,
+		RequestID:    message.RequestID,
+		TemplateName: message.TemplateName,
+	}
+	if message.Quoted != nil {
+		messageInfo.QuotedMessage = &domainChat.QuotedMessageInfo{
+			ID:      message.Quoted.ID,
+			Sender:  message.Quoted.Sender,
+			Content: message.Quoted.Content,
+		}
+	}
+	for _, reaction := range message.Reactions {
+		messageInfo.Reactions = append(messageInfo.Reactions, domainChat.ReactionInfo{
+			Sender:    reaction.Sender,
+			Emoji:     reaction.Emoji,
+			Timestamp: reaction.Timestamp.Format(time.RFC3339),
+		})
+	}
+	if message.PinnedUntil != nil && message.PinnedUntil.After(time.Now()) {
+		messageInfo.PinnedUntil = message.PinnedUntil.Format(time.RFC3339)
+	}
+	return messageInfo
+}
+
+// formatEditedAt renders the edit time of a message, or "" if it was never edited.
+func formatEditedAt(editedAt *time.Time) string {
+	if editedAt == nil {
+		return ""
+	}
+	return editedAt.Format(time.RFC3339)
+}
+
+// toLocationInfo converts stored coordinates, or returns nil for messages without any.
+func toLocationInfo(location *domainChatStorage.Location) *domainChat.LocationInfo {
+	if location == nil {
+		return nil
+	}
+	return &domainChat.LocationInfo{
+		Latitude:       location.Latitude,
+		Longitude:      location.Longitude,
+		Accuracy:       location.Accuracy,
+		Name:           location.Name,
+		SequenceNumber: location.SequenceNumber,
+	}
+}
+
+// toContactCardInfos converts the shared contacts of a message, or returns nil for other messages.
+func toContactCardInfos(cards []domainChatStorage.ContactCard) []domainChat.ContactCardInfo {
+	if len(cards) == 0 {
+		return nil
+	}
+	infos := make([]domainChat.ContactCardInfo, 0, len(cards))
+	for _, card := range cards {
+		infos = append(infos, domainChat.ContactCardInfo{
+			DisplayName:  card.DisplayName,
+			PhoneNumbers: card.PhoneNumbers,
+			VCard:        card.VCard,
+		})
+	}
+	return infos
+}
+
+// ExportChatMessages starts a job writing every stored message of a chat,
+// oldest first, to a newline-delimited JSON or CSV file. The request is
+// validated and the chat looked up before the job starts, so those errors
+// are still reported to the caller.
+func (service serviceChat) ExportChatMessages(ctx context.Context, request domainChat.ExportChatMessagesRequest) (response domainChat.ExportChatMessagesResponse, err error) {
+	if err = validations.ValidateExportChatMessages(ctx, &request); err != nil {
+		return response, err
+	}
+
+	deviceID := deviceIDFromContext(ctx)
+	if deviceID == "" {
+		return response, fmt.Errorf("device identification required")
+	}
+
+	chat, err := service.chatStorageRepo.GetChat(ctx, request.ChatJID)
+	if err != nil {
+		logrus.WithError(err).WithField("chat_jid", request.ChatJID).Error("Failed to get chat info")
+		return response, err
+	}
+	if chat == nil {
+		return response, fmt.Errorf("chat with JID %s not found", request.ChatJID)
+	}
+
+	extension := "jsonl"
+	if request.Format == domainChat.ExportFormatCSV {
+		extension = "csv"
+	}
+	fileName := fmt.Sprintf("chat-%s-messages.%s", strings.ReplaceAll(request.ChatJID, "@", "_"), extension)
+
+	job, err := service.jobs.Start(ctx, domainJob.TypeChatExport, func(ctx context.Context, task domainJob.Task) (any, error) {
+		total, err := service.chatStorageRepo.GetChatMessageCountByDevice(ctx, deviceID, request.ChatJID)
+		if err != nil {
+			return nil, err
+		}
+		file, err := task.CreateOutput(fileName)
+		if err != nil {
+			return nil, err
+		}
+		buffered := bufio.NewWriter(file)
+		written, err := service.writeChatExport(ctx, deviceID, request, buffered, func(written int) {
+			if total > 0 {
+				task.SetProgress(int(int64(written) * 100 / total))
+			}
+		})
+		if err == nil {
+			err = buffered.Flush()
+		}
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, err
+		}
+		return domainChat.ExportChatMessagesResult{
+			ChatJID:  request.ChatJID,
+			Format:   request.Format,
+			FileName: fileName,
+			Messages: written,
+		}, nil
+	})
+	if err != nil {
+		return response, err
+	}
+	response.JobID = job.ID
+	response.Status = fmt.Sprintf("Chat export queued as job %s", job.ID)
+	return response, nil
+}
+
+// writeChatExport streams the device's messages of the chat to w in the
+// requested format. progress is called with the number of messages written
+// so far after each one.
+func (service serviceChat) writeChatExport(ctx context.Context, deviceID string, request domainChat.ExportChatMessagesRequest, w io.Writer, progress func(written int)) (written int, err error) {
+	filter := &domainChatStorage.MessageFilter{DeviceID: deviceID, ChatJID: request.ChatJID}
+	toRecord := func(message *domainChatStorage.Message) exportedMessage {
+		return exportedMessage{
+			ID:        message.ID,
+			Sender:    message.Sender,
+			Timestamp: message.Timestamp.Format(time.RFC3339),
+			Content:   message.Content,
+			MediaType: message.MediaType,
+			Filename:  message.Filename,
+		}
+	}
+
+	if request.Format == domainChat.ExportFormatCSV {
+		writer := csv.NewWriter(w)
+		if err = writer.Write([]string{"id", "sender", "timestamp", "content", "media_type", "filename"}); err != nil {
+			return written, err
+		}
+		err = service.chatStorageRepo.StreamMessages(ctx, filter, func(message *domainChatStorage.Message) error {
+			record := toRecord(message)
+			if err := writer.Write([]string{record.ID, record.Sender, record.Timestamp, record.Content, record.MediaType, record.Filename}); err != nil {
+				return err
+			}
+			written++
+			progress(written)
+			return nil
+		})
+		writer.Flush()
+		if err != nil {
+			return written, err
+		}
+		return written, writer.Error()
+	}
+
+	encoder := json.NewEncoder(w)
+	err = service.chatStorageRepo.StreamMessages(ctx, filter, func(message *domainChatStorage.Message) error {
+		if err := encoder.Encode(toRecord(message)); err != nil {
+			return err
+		}
+		written++
+		progress(written)
+		return nil
+	})
+	return written, err
+}
+
+const (
+	// historySyncTimeout is how long SyncChatHistory waits for the phone,
+	// short of the request timeout so it can answer with what was synced
+	historySyncTimeout = 30 * time.Second
+	historySyncBucket  = "history_sync"
+)
+
+// historySyncLimit lets the history of a chat be requested once a minute;
+// requesting it more often risks tripping WhatsApp's abuse detection.
+var historySyncLimit = ratelimit.Limit{PerMinute: 1, Burst: 1}
+
+// SyncChatHistory asks the phone for up to request.Count messages sent before
+// the oldest message stored of the chat, so that paging back can go past what
+// the device received so far. It waits for the phone to answer and reports
+// how many older messages were stored.
+func (service serviceChat) SyncChatHistory(ctx context.Context, request domainChat.SyncChatHistoryRequest) (response domainChat.SyncChatHistoryResponse, err error) {
+	if err = validations.ValidateSyncChatHistory(ctx, &request); err != nil {
+		return response, err
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+	jid, err := utils.ValidateJidWithLogin(client, request.ChatJID)
+	if err != nil {
+		return response, err
+	}
+	deviceID := deviceIDFromContext(ctx)
+	if deviceID == "" {
+		return response, fmt.Errorf("device identification required")
+	}
+	chatJID := jid.ToNonAD().String()
+
+	oldest, err := service.chatStorageRepo.GetOldestMessage(ctx, deviceID, chatJID)
+	if err != nil {
+		return response, fmt.Errorf("failed to get the oldest message: %w", err)
+	}
+	if oldest == nil {
+		return response, pkgError.ValidationError(fmt.Sprintf("chat %s has no stored messages to sync history before", chatJID))
+	}
+
+	limitKey := deviceID + "|" + chatJID
+	if allowed, wait := service.historySyncs.Take(limitKey, historySyncBucket, historySyncLimit, time.Now()); !allowed {
+		return response, pkgError.RateLimitError(fmt.Sprintf("history of chat %s was requested recently, retry in %ds", chatJID, int(math.Ceil(wait.Seconds()))))
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, historySyncTimeout)
+	defer cancel()
+	if err = whatsapp.RequestChatHistory(waitCtx, client, deviceID, oldest, request.Count); err != nil {
+		// Only requests the phone got count against the limit
+		if errors.Is(err, whatsapp.ErrHistoryNotRequested) {
+			service.historySyncs.Refund(limitKey, historySyncBucket, time.Now())
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return response, pkgError.RequestTimeout("the phone didn't answer the history request in time; messages it sends later are still stored")
+		}
+		return response, err
+	}
+
+	// Everything older than the previous oldest message is new
+	older, err := service.chatStorageRepo.GetMessages(ctx, &domainChatStorage.MessageFilter{
+		DeviceID: deviceID,
+		ChatJID:  chatJID,
+		Before:   &oldest.Timestamp,
+		BeforeID: oldest.ID,
+	})
+	if err != nil {
+		return response, fmt.Errorf("failed to count synced messages: %w", err)
+	}
+
+	response.ChatJID = chatJID
+	response.Synced = len(older)
+	logrus.WithFields(logrus.Fields{
+		"chat_jid": chatJID,
+		"synced":   response.Synced,
+	}).Info("Synced chat history on demand")
+	return response, nil
+}
+
+const (
+	importBatchSize = 500 // Rows handed to StoreMessagesBatch at a time
+	maxImportErrors = 100 // Malformed rows reported back in detail
+)
+
+// ImportChatMessages loads a file produced by ExportChatMessages into the chat.
+// Messages already stored under the same ID are updated in place, and malformed
+// rows are skipped and reported instead of aborting the import.
+func (service serviceChat) ImportChatMessages(ctx context.Context, request domainChat.ImportChatMessagesRequest) (response domainChat.ImportChatMessagesResponse, err error) {
+	if err = validations.ValidateImportChatMessages(ctx, &request); err != nil {
+		return response, err
+	}
+
+	deviceID := deviceIDFromContext(ctx)
+	if deviceID == "" {
+		return response, fmt.Errorf("device identification required")
+	}
+
+	file, err := request.File.Open()
+	if err != nil {
+		return response, err
+	}
+	defer file.Close()
+
+	return service.importChatMessages(ctx, deviceID, request.ChatJID, file, request.Format)
+}
+
+func (service serviceChat) importChatMessages(ctx context.Context, deviceID, chatJID string, r io.Reader, format string) (response domainChat.ImportChatMessagesResponse, err error) {
+	response.Errors = []domainChat.ImportChatMessagesError{}
+
+	// The export has no direction column, so recover it from the sender
+	var ownUser string
+	if own, parseErr := types.ParseJID(deviceID); parseErr == nil {
+		ownUser = own.User
+	}
+
+	seen := make(map[string]struct{})
+	batch := make([]*domainChatStorage.Message, 0, importBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		stored, err := service.chatStorageRepo.StoreMessagesBatch(ctx, batch)
+		if err != nil {
+			return err
+		}
+		// The repository drops messages with neither content nor media
+		response.Imported += stored
+		response.Skipped += len(batch) - stored
+		batch = batch[:0]
+		return nil
+	}
+
+	add := func(line int, record exportedMessage, rowErr error) error {
+		var timestamp time.Time
+		if rowErr == nil && record.ID == "" {
+			rowErr = fmt.Errorf("id is required")
+		}
+		if rowErr == nil {
+			if timestamp, rowErr = time.Parse(time.RFC3339, record.Timestamp); rowErr != nil {
+				rowErr = fmt.Errorf("invalid timestamp %q", record.Timestamp)
+			}
+		}
+		if rowErr != nil {
+			response.Skipped++
+			if len(response.Errors) < maxImportErrors {
+				response.Errors = append(response.Errors, domainChat.ImportChatMessagesError{Line: line, Error: rowErr.Error()})
+			}
+			return nil
+		}
+
+		if _, duplicate := seen[record.ID]; duplicate {
+			response.Skipped++
+			return nil
+		}
+		seen[record.ID] = struct{}{}
+
+		message := &domainChatStorage.Message{
+			ID:        record.ID,
+			ChatJID:   chatJID,
+			DeviceID:  deviceID,
+			Sender:    record.Sender,
+			Content:   record.Content,
+			Timestamp: timestamp,
+			MediaType: record.MediaType,
+			Filename:  record.Filename,
+		}
+		if sender, parseErr := types.ParseJID(record.Sender); parseErr == nil && ownUser != "" {
+			message.IsFromMe = sender.User == ownUser
+		}
+
+		batch = append(batch, message)
+		if len(batch) == importBatchSize {
+			return flush()
+		}
+		return nil
+	}
+
+	if format == domainChat.ExportFormatCSV {
+		err = readExportedCSV(r, add)
+	} else {
+		err = readExportedJSON(r, add)
+	}
+	if err == nil {
+		err = flush()
+	}
+	return response, err
+}
+
+// readExportedJSON calls fn for every non-empty line of a JSON export, passing
+// the decoding error of malformed lines.
+func readExportedJSON(r io.Reader, fn func(line int, record exportedMessage, err error) error) error {
+	reader := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, readErr := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(data)) > 0 {
+			var record exportedMessage
+			if err := fn(line, record, json.Unmarshal(data, &record)); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+// readExportedCSV calls fn for every record of a CSV export. Columns are matched
+// by the header row, so only id and timestamp have to be present.
+func readExportedCSV(r io.Reader, fn func(line int, record exportedMessage, err error) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return pkgError.ValidationError(fmt.Sprintf("invalid csv header: %v", err))
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, required := range []string{"id", "timestamp"} {
+		if _, ok := columns[required]; !ok {
+			return pkgError.ValidationError(fmt.Sprintf("csv header is missing the %s column", required))
+		}
+	}
+	field := func(values []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(values) {
+			return values[i]
+		}
+		return ""
+	}
+
+	for {
+		values, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			if err := fn(parseErr.StartLine, exportedMessage{}, parseErr.Err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		line, _ := reader.FieldPos(0)
+		record := exportedMessage{
+			ID:        field(values, "id"),
+			Sender:    field(values, "sender"),
+			Timestamp: field(values, "timestamp"),
+			Content:   field(values, "content"),
+			MediaType: field(values, "media_type"),
+			Filename:  field(values, "filename"),
+		}
+		if err := fn(line, record, nil); err != nil {
+			return err
+		}
+	}
+}
+
+func (service serviceChat) GetStorageStatistics(ctx context.Context) (response domainChat.StorageStatisticsResponse, err error) {
+	deviceID := deviceIDFromContext(ctx)
+	if deviceID == "" {
+		return response, fmt.Errorf("device identification required")
+	}
+
+	stats, err := service.chatStorageRepo.GetStorageStatisticsByDevice(ctx, deviceID)
+	if err != nil {
+		logrus.WithError(err).WithField("device_id", deviceID).Error("Failed to get storage statistics")
+		return response, err
+	}
+
+	response = domainChat.StorageStatisticsResponse{
+		DeviceID:        stats.DeviceID,
+		ChatCount:       stats.ChatCount,
+		MessageCount:    stats.MessageCount,
+		MediaBytes:      stats.MediaBytes,
+		DistinctSenders: stats.DistinctSenders,
+	}
+	if stats.OldestMessage != nil {
+		response.OldestMessage = stats.OldestMessage.Format(time.RFC3339)
+	}
+	if stats.NewestMessage != nil {
+		response.NewestMessage = stats.NewestMessage.Format(time.RFC3339)
+	}
+
+	return response, nil
+}
+
+func (service serviceChat) PruneMessages(ctx context.Context, request domainChat.PruneMessagesRequest) (response domainChat.PruneMessagesResponse, err error) {
+	if err = validations.ValidatePruneMessages(ctx, &request); err != nil {
+		return response, err
+	}
+
+	// Messages are stored under the device JID, so map a registered device ID to it
+	deviceID := strings.TrimSpace(request.DeviceID)
+	if deviceID != "" {
+		if dm := whatsapp.GetDeviceManager(); dm != nil {
+			if inst, ok := dm.GetDevice(deviceID); ok && inst != nil && inst.JID() != "" {
+				deviceID = inst.JID()
+			}
+		}
+	} else {
+		deviceID = deviceIDFromContext(ctx)
+	}
+	if deviceID == "" {
+		return response, pkgError.ValidationError("device_id: cannot be blank.")
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -request.Days)
+	deleted, err := service.chatStorageRepo.PruneMessagesBefore(ctx, deviceID, cutoff)
+	if err != nil {
+		logrus.WithError(err).WithField("device_id", deviceID).Error("Failed to prune messages")
+		return response, err
+	}
+	logrus.WithContext(ctx).Infof("[CHAT_STORAGE] Pruned %d messages older than %s for device %s", deleted, cutoff.Format(time.RFC3339), deviceID)
+
+	response.DeviceID = deviceID
+	response.Cutoff = cutoff.Format(time.RFC3339)
+	response.DeletedMessages = deleted
+	return response, nil
+}
+
+func (service serviceChat) MergeChats(ctx context.Context, request domainChat.MergeChatsRequest) (response domainChat.MergeChatsResponse, err error) {
+	if err = validations.ValidateMergeChats(ctx, &request); err != nil {
+		return response, err
+	}
+
+	deviceID := deviceIDFromContext(ctx)
+	if deviceID == "" {
+		return response, fmt.Errorf("device identification required")
+	}
+
+	moved, err := service.chatStorageRepo.MergeChats(ctx, deviceID, request.FromJID, request.ToJID)
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"from_jid": request.FromJID,
+			"to_jid":   request.ToJID,
+		}).Error("Failed to merge chats")
+		return response, err
+	}
+
+	// Remember the mapping so later messages from the LID land in the merged chat
+	if strings.HasSuffix(request.FromJID, "@lid") && strings.HasSuffix(request.ToJID, "@s.whatsapp.net") {
+		if err := service.chatStorageRepo.StoreLIDMapping(ctx, deviceID, request.FromJID, request.ToJID); err != nil {
+			logrus.WithError(err).WithField("lid", request.FromJID).Warn("Failed to store LID mapping")
+		}
+	}
+	logrus.WithContext(ctx).Infof("[CHAT_STORAGE] Merged %d messages from %s into %s for device %s", moved, request.FromJID, request.ToJID, deviceID)
+
+	response.FromJID = request.FromJID
+	response.ToJID = request.ToJID
+	response.MovedMessages = moved
+	return response, nil
+}
+
+// ListCalls returns the incoming calls of the device, newest first.
+func (service serviceChat) ListCalls(ctx context.Context, request domainChat.ListCallsRequest) (response domainChat.ListCallsResponse, err error) {
+	if err = validations.ValidateListCalls(ctx, &request); err != nil {
+		return response, err
+	}
+
+	deviceID := deviceIDFromContext(ctx)
+	if deviceID == "" {
+		return response, fmt.Errorf("device identification required")
+	}
+
+	filter := &domainChatStorage.CallFilter{
+		DeviceID: deviceID,
+		Limit:    request.Limit,
+		Offset:   request.Offset,
+	}
+	if request.FromJID != "" {
+		jid, err := utils.ParseJID(request.FromJID)
+		if err != nil {
+			return response, err
+		}
+		filter.FromJID = jid.ToNonAD().String()
+	}
+	if request.StartTime != nil && *request.StartTime != "" {
+		startTime, _ := time.Parse(time.RFC3339, *request.StartTime)
+		filter.StartTime = &startTime
+	}
+	if request.EndTime != nil && *request.EndTime != "" {
+		endTime, _ := time.Parse(time.RFC3339, *request.EndTime)
+		filter.EndTime = &endTime
+	}
+
+	calls, err := service.chatStorageRepo.GetCalls(ctx, filter)
+	if err != nil {
+		return response, err
+	}
+
+	response.Data = make([]domainChat.CallInfo, 0, len(calls))
+	for _, call := range calls {
+		response.Data = append(response.Data, domainChat.CallInfo{
+			CallID:    call.CallID,
+			From:      call.FromJID,
+			Timestamp: call.Timestamp.Format(time.RFC3339),
+			IsVideo:   call.IsVideo,
+			Outcome:   call.Outcome,
+			Duration:  call.Duration,
+		})
+	}
+	return response, nil
+}
@@ -0,0 +1,417 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	domainChat "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chat"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainJob "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/job"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMessageCursorRoundTrip(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 123456789, time.UTC)
+
+	gotTS, gotID, err := decodeMessageCursor(encodeMessageCursor(ts, "3EB0|ABC"))
+	require.NoError(t, err)
+	assert.True(t, ts.Equal(gotTS))
+	assert.Equal(t, "3EB0|ABC", gotID)
+}
+
+func TestDecodeMessageCursor_Invalid(t *testing.T) {
+	for _, cursor := range []string{"not base64!", "bm9waXBl", "YWJjfGlk"} {
+		_, _, err := decodeMessageCursor(cursor)
+		assert.Error(t, err, cursor)
+	}
+}
+
+func TestSearchMessagesPagination(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+
+	base := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i, id := range []string{"m1", "m2", "m3"} {
+		require.NoError(t, repo.StoreMessage(context.Background(), &domainChatStorage.Message{
+			ID: id, ChatJID: "628111@s.whatsapp.net", DeviceID: "dev-1", Content: "invoice " + id,
+			Timestamp: base.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+
+	ctx := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-1", nil, nil))
+	service := serviceChat{chatStorageRepo: repo}
+
+	first, err := service.SearchMessages(ctx, domainChat.SearchMessagesRequest{Query: " invoice ", Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, first.Data, 2)
+	assert.Equal(t, "m3", first.Data[0].ID)
+	assert.True(t, first.Pagination.HasMore)
+
+	second, err := service.SearchMessages(ctx, domainChat.SearchMessagesRequest{Query: "invoice", Limit: 2, Offset: 2})
+	require.NoError(t, err)
+	require.Len(t, second.Data, 1)
+	assert.Equal(t, "m1", second.Data[0].ID)
+	assert.False(t, second.Pagination.HasMore)
+
+	_, err = service.SearchMessages(ctx, domainChat.SearchMessagesRequest{Query: "x"})
+	assert.Error(t, err)
+}
+
+func TestExportChatMessages(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+
+	chatJID := "628111@s.whatsapp.net"
+	base := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	_, err = repo.StoreMessagesBatch(context.Background(), []*domainChatStorage.Message{
+		{ID: "m2", ChatJID: chatJID, DeviceID: "dev-1", Sender: chatJID, Content: "see, \"attached\"", MediaType: "document", Filename: "invoice.pdf", Timestamp: base.Add(time.Minute)},
+		{ID: "m1", ChatJID: chatJID, DeviceID: "dev-1", Sender: chatJID, Content: "hello", Timestamp: base},
+	})
+	require.NoError(t, err)
+
+	ctx := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-1", nil, nil))
+	jobs := newJobService(repo, 1, t.TempDir())
+	service := serviceChat{chatStorageRepo: repo, jobs: jobs}
+
+	export := func(format string) string {
+		response, err := service.ExportChatMessages(ctx, domainChat.ExportChatMessagesRequest{ChatJID: chatJID, Format: format})
+		require.NoError(t, err)
+		job := waitForJob(t, jobs, ctx, response.JobID)
+		require.Equal(t, domainChatStorage.JobStatusSucceeded, job.Status, job.Error)
+		assert.True(t, job.HasOutput)
+
+		var result domainChat.ExportChatMessagesResult
+		require.NoError(t, json.Unmarshal(job.Result, &result))
+		assert.Equal(t, 2, result.Messages)
+
+		output, err := jobs.GetJobOutput(ctx, response.JobID)
+		require.NoError(t, err)
+		assert.Equal(t, result.FileName, output.Name)
+		content, err := os.ReadFile(output.Path)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	assert.Equal(t, "id,sender,timestamp,content,media_type,filename\n"+
+		"m1,628111@s.whatsapp.net,2024-05-01T00:00:00Z,hello,,\n"+
+		"m2,628111@s.whatsapp.net,2024-05-01T00:01:00Z,\"see, \"\"attached\"\"\",document,invoice.pdf\n", export("csv"))
+	assert.Equal(t, `{"id":"m1","sender":"628111@s.whatsapp.net","timestamp":"2024-05-01T00:00:00Z","content":"hello","media_type":"","filename":""}`+"\n"+
+		`{"id":"m2","sender":"628111@s.whatsapp.net","timestamp":"2024-05-01T00:01:00Z","content":"see, \"attached\"","media_type":"document","filename":"invoice.pdf"}`+"\n", export(""))
+
+	// Errors are reported before a job starts
+	_, err = service.ExportChatMessages(ctx, domainChat.ExportChatMessagesRequest{ChatJID: "628999@s.whatsapp.net"})
+	assert.Error(t, err)
+	_, err = service.ExportChatMessages(ctx, domainChat.ExportChatMessagesRequest{ChatJID: chatJID, Format: "xml"})
+	assert.Error(t, err)
+	listed, err := jobs.ListJobs(ctx, domainJob.ListJobsRequest{})
+	require.NoError(t, err)
+	assert.Len(t, listed, 2)
+}
+
+func TestImportChatMessages_RoundTrip(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+	service := serviceChat{chatStorageRepo: repo}
+
+	chatJID := "628111@s.whatsapp.net"
+	base := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	_, err = repo.StoreMessagesBatch(context.Background(), []*domainChatStorage.Message{
+		{ID: "m1", ChatJID: chatJID, DeviceID: "628999@s.whatsapp.net", Sender: chatJID, Content: "hello", Timestamp: base},
+		{ID: "m2", ChatJID: chatJID, DeviceID: "628999@s.whatsapp.net", Sender: "628999@s.whatsapp.net", Content: "a, \"b\"", MediaType: "document", Filename: "b.pdf", Timestamp: base.Add(time.Minute)},
+	})
+	require.NoError(t, err)
+
+	source := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("628999@s.whatsapp.net", nil, nil))
+	target := "628777@s.whatsapp.net"
+	for _, format := range []string{"json", "csv"} {
+		var exported bytes.Buffer
+		_, err := service.writeChatExport(source, "628999@s.whatsapp.net", domainChat.ExportChatMessagesRequest{ChatJID: chatJID, Format: format}, &exported, func(int) {})
+		require.NoError(t, err)
+
+		response, err := service.importChatMessages(context.Background(), target, chatJID, &exported, format)
+		require.NoError(t, err, format)
+		assert.Equal(t, 2, response.Imported, format)
+		assert.Zero(t, response.Skipped, format)
+		assert.Empty(t, response.Errors, format)
+
+		messages, err := repo.GetMessages(context.Background(), &domainChatStorage.MessageFilter{DeviceID: target, ChatJID: chatJID})
+		require.NoError(t, err)
+		require.Len(t, messages, 2, format)
+		assert.Equal(t, "m2", messages[0].ID)
+		assert.Equal(t, "a, \"b\"", messages[0].Content)
+		assert.Equal(t, "b.pdf", messages[0].Filename)
+		assert.True(t, base.Add(time.Minute).Equal(messages[0].Timestamp))
+		assert.False(t, messages[0].IsFromMe)
+		assert.False(t, messages[1].IsFromMe)
+
+		chat, err := repo.GetChatByDevice(context.Background(), target, chatJID)
+		require.NoError(t, err)
+		require.NotNil(t, chat, format)
+	}
+}
+
+func TestImportChatMessages_ReportsMalformedRows(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+	service := serviceChat{chatStorageRepo: repo}
+
+	input := strings.Join([]string{
+		`{"id":"m1","sender":"628999@s.whatsapp.net","timestamp":"2024-05-01T00:00:00Z","content":"mine"}`,
+		`not json`,
+		``,
+		`{"id":"m2","timestamp":"yesterday","content":"x"}`,
+		`{"timestamp":"2024-05-01T00:00:00Z","content":"x"}`,
+		`{"id":"m1","timestamp":"2024-05-01T00:00:00Z","content":"duplicate"}`,
+		`{"id":"m3","timestamp":"2024-05-01T00:02:00Z"}`,
+	}, "\n")
+	response, err := service.importChatMessages(context.Background(), "628999:3@s.whatsapp.net", "628111@s.whatsapp.net", strings.NewReader(input), "json")
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Imported)
+	assert.Equal(t, 5, response.Skipped)
+	require.Len(t, response.Errors, 3)
+	assert.Equal(t, 2, response.Errors[0].Line)
+	assert.Equal(t, domainChat.ImportChatMessagesError{Line: 4, Error: `invalid timestamp "yesterday"`}, response.Errors[1])
+	assert.Equal(t, domainChat.ImportChatMessagesError{Line: 5, Error: "id is required"}, response.Errors[2])
+
+	message, err := repo.GetMessageByID(context.Background(), "m1")
+	require.NoError(t, err)
+	require.NotNil(t, message)
+	assert.True(t, message.IsFromMe)
+
+	csvInput := "id,timestamp,content\nc1,2024-05-01T00:00:00Z,ok\nc2,\"2024-05-01T00:00:00Z,broken\"x\nc3,2024-05-01T00:03:00Z,fine\n"
+	response, err = service.importChatMessages(context.Background(), "628999@s.whatsapp.net", "628111@s.whatsapp.net", strings.NewReader(csvInput), "csv")
+	require.NoError(t, err)
+	assert.Equal(t, 2, response.Imported)
+	assert.Equal(t, 1, response.Skipped)
+	require.Len(t, response.Errors, 1)
+	assert.Equal(t, 3, response.Errors[0].Line)
+
+	_, err = service.importChatMessages(context.Background(), "628999@s.whatsapp.net", "628111@s.whatsapp.net", strings.NewReader("sender,content\n"), "csv")
+	assert.Error(t, err)
+}
+
+func TestDownloadMessageMedia_Lookup(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+
+	chatJID := "628111@s.whatsapp.net"
+	base := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	_, err = repo.StoreMessagesBatch(context.Background(), []*domainChatStorage.Message{
+		{ID: "img", ChatJID: chatJID, DeviceID: "dev-1", Sender: chatJID, MediaType: "image", Filename: "photo.jpg", URL: "https://mmg.whatsapp.net/x", MediaKey: []byte("key"), Timestamp: base},
+		{ID: "text", ChatJID: chatJID, DeviceID: "dev-1", Sender: chatJID, Content: "hello", Timestamp: base},
+	})
+	require.NoError(t, err)
+
+	ctx := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-1", nil, nil))
+	service := serviceChat{chatStorageRepo: repo}
+
+	// An empty device ID falls back to the device in ctx
+	message, err := service.storedMediaMessage(ctx, "", chatJID, "img")
+	require.NoError(t, err)
+	assert.Equal(t, "photo.jpg", message.Filename)
+
+	_, err = service.storedMediaMessage(ctx, "", chatJID, "text")
+	assert.ErrorContains(t, err, "does not contain downloadable media")
+	_, err = service.storedMediaMessage(ctx, "", "628999@s.whatsapp.net", "img")
+	assert.ErrorContains(t, err, "not found")
+	_, err = service.storedMediaMessage(ctx, "dev-2", chatJID, "img")
+	assert.ErrorContains(t, err, "not found")
+	_, err = service.storedMediaMessage(context.Background(), "", chatJID, "img")
+	assert.ErrorContains(t, err, "device identification required")
+
+	// Downloading needs a connected client
+	_, _, err = service.DownloadMessageMedia(ctx, "", chatJID, "img")
+	assert.ErrorIs(t, err, pkgError.ErrWaCLI)
+}
+
+func TestListCalls(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+
+	base := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i, from := range []string{"628111@s.whatsapp.net", "628222@s.whatsapp.net"} {
+		require.NoError(t, repo.StoreCall(context.Background(), &domainChatStorage.Call{
+			DeviceID: "dev-1", CallID: from, FromJID: from, Timestamp: base.Add(time.Duration(i) * time.Hour), Outcome: domainChatStorage.CallOutcomeMissed,
+		}))
+	}
+
+	ctx := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-1", nil, nil))
+	service := serviceChat{chatStorageRepo: repo}
+
+	response, err := service.ListCalls(ctx, domainChat.ListCallsRequest{FromJID: "628222"})
+	require.NoError(t, err)
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, domainChat.CallInfo{
+		CallID: "628222@s.whatsapp.net", From: "628222@s.whatsapp.net", Timestamp: "2024-05-01T01:00:00Z", Outcome: "missed",
+	}, response.Data[0])
+
+	start := "2024-05-01T00:30:00Z"
+	response, err = service.ListCalls(ctx, domainChat.ListCallsRequest{EndTime: &start})
+	require.NoError(t, err)
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, "628111@s.whatsapp.net", response.Data[0].From)
+
+	_, err = service.ListCalls(context.Background(), domainChat.ListCallsRequest{})
+	assert.ErrorContains(t, err, "device identification required")
+}
+
+func TestGetPinnedMessages(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+
+	chatJID := "120363000000000000@g.us"
+	pinnedUntil, expiredAt := time.Now().Add(time.Hour), time.Now().Add(-time.Hour)
+	for id, until := range map[string]*time.Time{"pinned": &pinnedUntil, "expired": &expiredAt, "plain": nil} {
+		require.NoError(t, repo.StoreMessage(context.Background(), &domainChatStorage.Message{
+			ID: id, ChatJID: chatJID, DeviceID: "dev-1", Content: id, Timestamp: time.Now(),
+		}))
+		require.NoError(t, repo.SetMessagePinnedUntil(context.Background(), "dev-1", id, chatJID, until))
+	}
+
+	ctx := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-1", nil, nil))
+	service := serviceChat{chatStorageRepo: repo}
+
+	response, err := service.GetPinnedMessages(ctx, domainChat.GetPinnedMessagesRequest{ChatJID: chatJID})
+	require.NoError(t, err)
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, "pinned", response.Data[0].ID)
+	assert.Equal(t, pinnedUntil.Format(time.RFC3339), response.Data[0].PinnedUntil)
+}
+
+func TestMarkRead(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+	ctx := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-1", nil, nil))
+
+	group := types.NewJID("120363000000000000", types.GroupServer)
+	otherGroup := types.NewJID("120363111111111111", types.GroupServer)
+	alice := types.NewJID("628111", types.DefaultUserServer)
+	bob := types.NewJID("628222", types.DefaultUserServer)
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for i, m := range []struct {
+		id     string
+		chat   types.JID
+		sender types.JID
+		fromMe bool
+	}{
+		{"A", group, alice, false},
+		{"B", group, types.NewADJID("628222", 0, 5), false},
+		{"C", group, alice, false},
+		{"MINE", group, bob, true},
+		{"ELSEWHERE", otherGroup, alice, false},
+	} {
+		require.NoError(t, repo.CreateMessage(ctx, &events.Message{
+			Info: types.MessageInfo{
+				MessageSource: types.MessageSource{Chat: m.chat, Sender: m.sender, IsFromMe: m.fromMe, IsGroup: true},
+				ID:            m.id,
+				Timestamp:     base.Add(time.Duration(i) * time.Minute),
+			},
+			Message: &waE2E.Message{Conversation: proto.String("hi")},
+		}))
+	}
+
+	receipts := map[types.JID][]types.MessageID{}
+	originalMarkRead := markReadFn
+	t.Cleanup(func() { markReadFn = originalMarkRead })
+	markReadFn = func(_ context.Context, _ *whatsmeow.Client, ids []types.MessageID, chat, sender types.JID) error {
+		assert.Equal(t, group, chat)
+		receipts[sender] = append(receipts[sender], ids...)
+		return nil
+	}
+	unread := func() int {
+		chat, err := repo.GetChatByDevice(ctx, "dev-1", group.String())
+		require.NoError(t, err)
+		return chat.UnreadCount
+	}
+	service := serviceChat{chatStorageRepo: repo}
+	require.Equal(t, 3, unread())
+
+	response, err := service.markRead(ctx, nil, group, domainChat.MarkChatReadRequest{MessageIDs: []string{"A", "B", "ELSEWHERE", "MINE", "UNKNOWN"}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, response.ReceiptsSent)
+	assert.Equal(t, map[types.JID][]types.MessageID{alice: {"A"}, bob: {"B"}}, receipts, "one receipt per sender, without the device")
+	assert.Equal(t, []domainChat.MarkReadError{
+		{MessageID: "ELSEWHERE", Error: "message belongs to chat " + otherGroup.String()},
+		{MessageID: "MINE", Error: "message was sent by you"},
+		{MessageID: "UNKNOWN", Error: "message not found"},
+	}, response.Errors)
+	assert.Equal(t, 1, unread())
+
+	clear(receipts)
+	response, err = service.markRead(ctx, nil, group, domainChat.MarkChatReadRequest{AllBefore: base.Add(time.Hour).Format(time.RFC3339)})
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.ReceiptsSent, "only C was still unread")
+	assert.Equal(t, map[types.JID][]types.MessageID{alice: {"C"}}, receipts)
+	assert.Equal(t, 0, unread())
+}
+
+func TestDisappearingDuration(t *testing.T) {
+	assert.Equal(t, "off", disappearingDuration(0))
+	assert.Equal(t, "24h", disappearingDuration(86400))
+	assert.Equal(t, "90d", disappearingDuration(7776000))
+	assert.Equal(t, "3600s", disappearingDuration(3600), "timers WhatsApp no longer offers")
+}
+
+func TestChatType(t *testing.T) {
+	assert.Equal(t, "user", chatType(&domainChatStorage.Chat{JID: "628111@s.whatsapp.net"}))
+	assert.Equal(t, "user", chatType(&domainChatStorage.Chat{JID: "123456789@lid"}))
+	assert.Equal(t, "group", chatType(&domainChatStorage.Chat{JID: "120363000000000000@g.us"}))
+	assert.Equal(t, "newsletter", chatType(&domainChatStorage.Chat{JID: "120363000000000000@newsletter"}))
+	assert.Equal(t, "status", chatType(&domainChatStorage.Chat{JID: "status@broadcast"}))
+	assert.Equal(t, "community_announce", chatType(&domainChatStorage.Chat{JID: "120363000000000000@g.us", ChatType: domainChatStorage.ChatTypeCommunityAnnounce}))
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainContact "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/contact"
+	domainJob "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/job"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
+	"github.com/sirupsen/logrus"
+)
+
+type serviceContact struct {
+	chatStorageRepo domainChatStorage.IChatStorageRepository
+	jobs            domainJob.IJobUsecase
+}
+
+func NewContactService(chatStorageRepo domainChatStorage.IChatStorageRepository, jobs domainJob.IJobUsecase) domainContact.IContactUsecase {
+	return &serviceContact{
+		chatStorageRepo: chatStorageRepo,
+		jobs:            jobs,
+	}
+}
+
+func (service serviceContact) ListContacts(ctx context.Context, request domainContact.ListContactsRequest) (response domainContact.ListContactsResponse, err error) {
+	if err = validations.ValidateListContacts(ctx, &request); err != nil {
+		return response, err
+	}
+
+	deviceID := deviceIDFromContext(ctx)
+	if deviceID == "" {
+		return response, fmt.Errorf("device identification required")
+	}
+
+	filter := &domainChatStorage.ContactFilter{
+		DeviceID: deviceID,
+		Limit:    request.Limit,
+		Offset:   request.Offset,
+		Search:   request.Search,
+	}
+
+	contacts, err := service.chatStorageRepo.GetContacts(ctx, filter)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get contacts from storage")
+		return response, err
+	}
+
+	total, err := service.chatStorageRepo.CountContacts(ctx, filter)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to count contacts")
+		return response, err
+	}
+
+	response.Data = make([]domainContact.ContactInfo, 0, len(contacts))
+	for _, contact := range contacts {
+		response.Data = append(response.Data, domainContact.ContactInfo{
+			JID:          contact.JID,
+			FullName:     contact.FullName,
+			FirstName:    contact.FirstName,
+			PushName:     contact.PushName,
+			BusinessName: contact.BusinessName,
+			UpdatedAt:    contact.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+	response.Pagination = domainContact.PaginationResponse{
+		Limit:  request.Limit,
+		Offset: request.Offset,
+		Total:  int(total),
+	}
+	return response, nil
+}
+
+func (service serviceContact) SyncContacts(ctx context.Context) (response domainContact.SyncContactsResponse, err error) {
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+
+	deviceID := deviceIDFromContext(ctx)
+	if deviceID == "" {
+		return response, fmt.Errorf("device identification required")
+	}
+
+	job, err := service.jobs.Start(ctx, domainJob.TypeContactSync, func(ctx context.Context, _ domainJob.Task) (any, error) {
+		synced, err := whatsapp.SyncContacts(ctx, client, service.chatStorageRepo, deviceID)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to sync contacts")
+			return nil, err
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"device_id": deviceID,
+			"synced":    synced,
+		}).Info("Synced contacts successfully")
+		return domainContact.SyncContactsResult{Synced: synced}, nil
+	})
+	if err != nil {
+		return response, err
+	}
+	response.JobID = job.ID
+	response.Status = fmt.Sprintf("Contact sync queued as job %s", job.ID)
+	return response, nil
+}
@@ -3,10 +3,14 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
 	domainDevice "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/device"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/websocket"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
 )
 
 type serviceDevice struct {
@@ -43,12 +47,17 @@ func (s *serviceDevice) GetDevice(_ context.Context, deviceID string) (*domainDe
 	return nil, fmt.Errorf("device %s not found", deviceID)
 }
 
-func (s *serviceDevice) AddDevice(ctx context.Context, deviceID string) (*domainDevice.Device, error) {
+func (s *serviceDevice) AddDevice(ctx context.Context, request domainDevice.AddDeviceRequest) (*domainDevice.Device, error) {
 	if s.manager == nil {
 		return nil, fmt.Errorf("device manager not initialized")
 	}
+	request.DeviceID = strings.TrimSpace(request.DeviceID)
+	request.DisplayName = strings.TrimSpace(request.DisplayName)
+	if err := validations.ValidateAddDevice(ctx, request); err != nil {
+		return nil, err
+	}
 
-	inst, err := s.manager.CreateDevice(ctx, deviceID)
+	inst, err := s.manager.CreateDevice(ctx, request.DeviceID, request.DisplayName)
 	if err != nil {
 		return nil, err
 	}
@@ -56,14 +65,61 @@ func (s *serviceDevice) AddDevice(ctx context.Context, deviceID string) (*domain
 	return &device, nil
 }
 
-func (s *serviceDevice) RemoveDevice(_ context.Context, deviceID string) error {
+func (s *serviceDevice) RemoveDevice(ctx context.Context, deviceID string) error {
 	if s.manager == nil {
 		return fmt.Errorf("device manager not initialized")
 	}
-	s.manager.RemoveDevice(deviceID)
+	if _, ok := s.manager.GetDevice(deviceID); !ok {
+		return fmt.Errorf("device %s not found", deviceID)
+	}
+
+	if err := s.manager.PurgeDevice(ctx, deviceID); err != nil {
+		return err
+	}
+
+	// Broadcast device removal so UI clients can refresh.
+	websocket.Broadcast <- websocket.BroadcastMessage{
+		Code:    "DEVICE_REMOVED",
+		Message: fmt.Sprintf("Device %s logged out and removed", deviceID),
+		Result: map[string]any{
+			"device_id": deviceID,
+			"devices":   s.listInstances(),
+		},
+	}
+
 	return nil
 }
 
+func (s *serviceDevice) RenameDevice(ctx context.Context, deviceID string, request domainDevice.RenameDeviceRequest) (*domainDevice.Device, error) {
+	if s.manager == nil {
+		return nil, fmt.Errorf("device manager not initialized")
+	}
+	if _, ok := s.manager.GetDevice(deviceID); !ok {
+		return nil, fmt.Errorf("device %s not found", deviceID)
+	}
+	request.Name = strings.TrimSpace(request.Name)
+	if err := validations.ValidateRenameDevice(ctx, request); err != nil {
+		return nil, err
+	}
+
+	if err := s.manager.RenameDevice(ctx, deviceID, request.Name); err != nil {
+		return nil, err
+	}
+
+	websocket.Broadcast <- websocket.BroadcastMessage{
+		Code:    "DEVICE_RENAMED",
+		Message: fmt.Sprintf("Device %s renamed to %s", deviceID, request.Name),
+		Result: map[string]any{
+			"device_id": deviceID,
+			"devices":   s.listInstances(),
+		},
+	}
+
+	inst, _ := s.manager.GetDevice(deviceID)
+	device := convertInstance(inst)
+	return &device, nil
+}
+
 func (s *serviceDevice) LoginDevice(_ context.Context, _ string) error {
 	return fmt.Errorf("device login per ID is not implemented yet")
 }
@@ -77,31 +133,28 @@ func (s *serviceDevice) LogoutDevice(ctx context.Context, deviceID string) error
 		return fmt.Errorf("device manager not initialized")
 	}
 
-	if err := s.manager.PurgeDevice(ctx, deviceID); err != nil {
+	if err := s.manager.LogoutDevice(ctx, deviceID); err != nil {
 		return err
 	}
 
-	// Broadcast device removal so UI clients can refresh.
-	var devices []domainDevice.Device
-	if s.manager != nil {
-		for _, inst := range s.manager.ListDevices() {
-			inst.UpdateStateFromClient()
-			devices = append(devices, convertInstance(inst))
-		}
-	}
-
 	websocket.Broadcast <- websocket.BroadcastMessage{
-		Code:    "DEVICE_REMOVED",
-		Message: fmt.Sprintf("Device %s logged out and removed", deviceID),
-		Result: map[string]any{
-			"device_id": deviceID,
-			"devices":   devices,
-		},
+		Code:    "LOGOUT_COMPLETE",
+		Message: fmt.Sprintf("Device %s logged out", deviceID),
+		Result:  map[string]string{"device_id": deviceID},
 	}
 
 	return nil
 }
 
+func (s *serviceDevice) listInstances() []domainDevice.Device {
+	var devices []domainDevice.Device
+	for _, inst := range s.manager.ListDevices() {
+		inst.UpdateStateFromClient()
+		devices = append(devices, convertInstance(inst))
+	}
+	return devices
+}
+
 func (s *serviceDevice) ReconnectDevice(_ context.Context, deviceID string) error {
 	if s.manager == nil {
 		return fmt.Errorf("device manager not initialized")
@@ -122,27 +175,150 @@ func (s *serviceDevice) ReconnectDevice(_ context.Context, deviceID string) erro
 	return fmt.Errorf("device %s not found", deviceID)
 }
 
-func (s *serviceDevice) GetStatus(_ context.Context, deviceID string) (bool, bool, error) {
+func (s *serviceDevice) GetStatus(_ context.Context, deviceID string) (*domainDevice.Device, error) {
 	if s.manager == nil {
-		return false, false, fmt.Errorf("device manager not initialized")
+		return nil, fmt.Errorf("device manager not initialized")
 	}
 	if inst, ok := s.manager.GetDevice(deviceID); ok {
 		inst.UpdateStateFromClient()
-		client := inst.GetClient()
-		if client == nil {
-			return false, false, nil
-		}
+		device := convertInstance(inst)
+		return &device, nil
+	}
+	return nil, fmt.Errorf("device %s not found", deviceID)
+}
 
-		if client.Store == nil || client.Store.ID == nil {
-			return false, false, nil
+func (s *serviceDevice) GetSettings(ctx context.Context, deviceID string) (*domainDevice.DeviceSettings, error) {
+	if s.manager == nil {
+		return nil, fmt.Errorf("device manager not initialized")
+	}
+	if _, ok := s.manager.GetDevice(deviceID); !ok {
+		return nil, fmt.Errorf("device %s not found", deviceID)
+	}
+
+	settings, err := s.manager.DeviceSettings(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	return &domainDevice.DeviceSettings{
+		AutoRejectCalls:       settings.AutoRejectCalls,
+		AutoRejectCallMessage: settings.AutoRejectCallMessage,
+		AutoReplyMessage:      settings.AutoReplyMessage,
+		AutoMarkRead:          settings.AutoMarkRead,
+	}, nil
+}
+
+func (s *serviceDevice) UpdateSettings(ctx context.Context, deviceID string, settings domainDevice.DeviceSettings) (*domainDevice.DeviceSettings, error) {
+	if s.manager == nil {
+		return nil, fmt.Errorf("device manager not initialized")
+	}
+	if _, ok := s.manager.GetDevice(deviceID); !ok {
+		return nil, fmt.Errorf("device %s not found", deviceID)
+	}
+	if err := validations.ValidateDeviceSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	if err := s.manager.SaveDeviceSettings(ctx, deviceID, domainChatStorage.DeviceSettings{
+		AutoRejectCalls:       settings.AutoRejectCalls,
+		AutoRejectCallMessage: settings.AutoRejectCallMessage,
+		AutoReplyMessage:      settings.AutoReplyMessage,
+		AutoMarkRead:          settings.AutoMarkRead,
+	}); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// PatchSettings changes the settings given in patch and keeps the others. A
+// setting can only be reset to the global value through UpdateSettings.
+func (s *serviceDevice) PatchSettings(ctx context.Context, deviceID string, patch domainDevice.DeviceSettings) (*domainDevice.DeviceSettings, error) {
+	current, err := s.GetSettings(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if patch.AutoRejectCalls != nil {
+		current.AutoRejectCalls = patch.AutoRejectCalls
+	}
+	if patch.AutoRejectCallMessage != nil {
+		current.AutoRejectCallMessage = patch.AutoRejectCallMessage
+	}
+	if patch.AutoReplyMessage != nil {
+		current.AutoReplyMessage = patch.AutoReplyMessage
+	}
+	if patch.AutoMarkRead != nil {
+		current.AutoMarkRead = patch.AutoMarkRead
+	}
+	return s.UpdateSettings(ctx, deviceID, *current)
+}
+
+func (s *serviceDevice) GetWebhook(ctx context.Context, deviceID string) (*domainDevice.DeviceWebhook, error) {
+	if s.manager == nil {
+		return nil, fmt.Errorf("device manager not initialized")
+	}
+	if _, ok := s.manager.GetDevice(deviceID); !ok {
+		return nil, fmt.Errorf("device %s not found", deviceID)
+	}
+
+	webhook, err := s.manager.DeviceWebhook(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	return toDomainDeviceWebhook(webhook), nil
+}
+
+func (s *serviceDevice) UpdateWebhook(ctx context.Context, deviceID string, webhook domainDevice.DeviceWebhook) (*domainDevice.DeviceWebhook, error) {
+	if s.manager == nil {
+		return nil, fmt.Errorf("device manager not initialized")
+	}
+	if _, ok := s.manager.GetDevice(deviceID); !ok {
+		return nil, fmt.Errorf("device %s not found", deviceID)
+	}
+	if err := validations.ValidateDeviceWebhook(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	// Sending back the masked secret from GET keeps the stored one
+	secret := webhook.WebhookSecret
+	if secret == domainChatStorage.MaskedWebhookSecret {
+		current, err := s.manager.DeviceWebhook(ctx, deviceID)
+		if err != nil {
+			return nil, err
 		}
+		secret = current.WebhookSecret
+	}
 
-		// Update state snapshot based on live client flags
-		state := deriveState(inst)
-		_ = state
-		return client.IsConnected(), client.IsLoggedIn(), nil
+	stored := domainChatStorage.DeviceWebhook{
+		WebhookURL:    webhook.WebhookURL,
+		WebhookSecret: secret,
+		WebhookEvents: strings.Join(webhook.WebhookEvents, ","),
 	}
-	return false, false, fmt.Errorf("device %s not found", deviceID)
+	if err := s.manager.SaveDeviceWebhook(ctx, deviceID, stored); err != nil {
+		return nil, err
+	}
+	return toDomainDeviceWebhook(stored), nil
+}
+
+func (s *serviceDevice) DeleteWebhook(ctx context.Context, deviceID string) error {
+	if s.manager == nil {
+		return fmt.Errorf("device manager not initialized")
+	}
+	if _, ok := s.manager.GetDevice(deviceID); !ok {
+		return fmt.Errorf("device %s not found", deviceID)
+	}
+	return s.manager.SaveDeviceWebhook(ctx, deviceID, domainChatStorage.DeviceWebhook{})
+}
+
+// toDomainDeviceWebhook converts a stored device webhook for API responses,
+// masking its secret.
+func toDomainDeviceWebhook(stored domainChatStorage.DeviceWebhook) *domainDevice.DeviceWebhook {
+	webhook := &domainDevice.DeviceWebhook{WebhookURL: stored.WebhookURL, WebhookEvents: []string{}}
+	if stored.WebhookSecret != "" {
+		webhook.WebhookSecret = domainChatStorage.MaskedWebhookSecret
+	}
+	if stored.WebhookEvents != "" {
+		webhook.WebhookEvents = strings.Split(stored.WebhookEvents, ",")
+	}
+	return webhook
 }
 
 func convertInstance(inst *whatsapp.DeviceInstance) domainDevice.Device {
@@ -151,15 +327,31 @@ func convertInstance(inst *whatsapp.DeviceInstance) domainDevice.Device {
 	}
 
 	state := deriveState(inst)
+	activity := inst.Activity()
 
 	return domainDevice.Device{
-		ID:          inst.ID(),
-		PhoneNumber: inst.PhoneNumber(),
-		DisplayName: inst.DisplayName(),
-		State:       state,
-		JID:         inst.JID(),
-		CreatedAt:   inst.CreatedAt(),
+		ID:              inst.ID(),
+		PhoneNumber:     inst.PhoneNumber(),
+		DisplayName:     inst.DisplayName(),
+		State:           state,
+		JID:             inst.JID(),
+		CreatedAt:       inst.CreatedAt(),
+		IsConnected:     inst.IsConnected(),
+		IsLoggedIn:      inst.IsLoggedIn(),
+		PushName:        inst.PushName(),
+		AvatarID:        inst.AvatarID(),
+		LastSeenEventAt: timeOrNil(activity.LastEventAt),
+		LastConnectAt:   timeOrNil(activity.LastConnectAt),
+		ReconnectCount:  activity.ReconnectCount,
+	}
+}
+
+// timeOrNil returns nil for the zero time so JSON shows null instead of year 1.
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
 	}
+	return &t
 }
 
 func deriveState(inst *whatsapp.DeviceInstance) domainDevice.DeviceState {
@@ -2,13 +2,18 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/types"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
 	domainGroup "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/group"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
 	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
@@ -16,56 +21,198 @@ import (
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
 )
 
-type serviceGroup struct{}
+type serviceGroup struct {
+	chatStorageRepo domainChatStorage.IChatStorageRepository
+}
 
-func NewGroupService() domainGroup.IGroupUsecase {
-	return &serviceGroup{}
+func NewGroupService(chatStorageRepo domainChatStorage.IChatStorageRepository) domainGroup.IGroupUsecase {
+	return &serviceGroup{
+		chatStorageRepo: chatStorageRepo,
+	}
 }
 
-func (service serviceGroup) JoinGroupWithLink(ctx context.Context, request domainGroup.JoinGroupWithLinkRequest) (groupID string, err error) {
+// JoinGroupWithLink joins the group of an invite link. The group is looked
+// up first, so groups that need admin approval are reported as pending
+// rather than joined; joined groups are stored as a chat right away.
+func (service serviceGroup) JoinGroupWithLink(ctx context.Context, request domainGroup.JoinGroupWithLinkRequest) (response domainGroup.JoinGroupWithLinkResponse, err error) {
 	if err = validations.ValidateJoinGroupWithLink(ctx, request); err != nil {
-		return groupID, err
+		return response, err
 	}
 
 	client := whatsapp.ClientFromContext(ctx)
 	if client == nil {
-		return groupID, pkgError.ErrWaCLI
+		return response, pkgError.ErrWaCLI
 	}
 	utils.MustLogin(client)
 
-	jid, err := client.JoinGroupWithLink(ctx, request.Link)
+	code, _ := utils.GroupInviteCode(request.Link)
+	groupInfo, err := client.GetGroupInfoFromLink(ctx, code)
 	if err != nil {
-		return
+		return response, inviteLinkError(err)
+	}
+
+	jid, err := client.JoinGroupWithLink(ctx, code)
+	if err != nil {
+		return response, inviteLinkError(err)
+	}
+	response.GroupID = jid.String()
+
+	if groupInfo.IsJoinApprovalRequired {
+		response.Status = domainGroup.JoinStatusPendingApproval
+		return response, nil
 	}
-	return jid.String(), nil
+	response.Status = domainGroup.JoinStatusJoined
+
+	chat := &domainChatStorage.Chat{DeviceID: deviceIDFromContext(ctx), JID: jid.String(), Name: groupInfo.Name, LastMessageTime: time.Now()}
+	if err := service.chatStorageRepo.StoreChat(ctx, chat); err != nil {
+		logrus.WithContext(ctx).Warnf("Failed to store chat of joined group %s: %v", jid, err)
+	}
+	return response, nil
 }
 
-func (service serviceGroup) LeaveGroup(ctx context.Context, request domainGroup.LeaveGroupRequest) (err error) {
+// inviteLinkError turns the invite link errors of whatsmeow into validation
+// errors, since they come from the link the caller sent.
+func inviteLinkError(err error) error {
+	switch {
+	case errors.Is(err, whatsmeow.ErrInviteLinkInvalid):
+		return pkgError.ValidationError("link: invite link is not valid")
+	case errors.Is(err, whatsmeow.ErrInviteLinkRevoked):
+		return pkgError.ValidationError("link: invite link has been revoked")
+	}
+	return err
+}
+
+// LeaveGroup leaves a group and marks its chat as left, keeping the history.
+// Leaving as the only admin is allowed, but the response warns about it.
+func (service serviceGroup) LeaveGroup(ctx context.Context, request domainGroup.LeaveGroupRequest) (response domainGroup.LeaveGroupResponse, err error) {
 	if err = validations.ValidateLeaveGroup(ctx, request); err != nil {
-		return err
+		return response, err
 	}
 
 	client := whatsapp.ClientFromContext(ctx)
 	if client == nil {
-		return pkgError.ErrWaCLI
+		return response, pkgError.ErrWaCLI
 	}
 
 	JID, err := utils.ValidateJidWithLogin(client, request.GroupID)
 	if err != nil {
-		return err
+		return response, err
+	}
+
+	// The members are only needed for the warning, so failing to get them doesn't stop the leave
+	if groupInfo, err := groupInfoFn(ctx, client, JID); err == nil && isOnlyAdmin(groupInfo.Participants, client.Store.GetJID(), client.Store.GetLID()) {
+		response.Warning = "You were the only admin of this group, the remaining participants have no admin until WhatsApp appoints one"
+	}
+
+	if err = client.LeaveGroup(ctx, JID); err != nil {
+		return response, err
 	}
+	response.GroupID = JID.String()
 
-	return client.LeaveGroup(ctx, JID)
+	leftAt := time.Now()
+	if err := service.chatStorageRepo.UpdateChatFlags(ctx, deviceIDFromContext(ctx), JID.String(), domainChatStorage.ChatFlags{LeftAt: &leftAt}); err != nil {
+		logrus.WithContext(ctx).Warnf("Failed to mark group %s as left: %v", JID, err)
+	}
+	return response, nil
+}
+
+// isOnlyAdmin reports whether the device, known by own, is the one admin of a
+// group that has other participants.
+func isOnlyAdmin(participants []types.GroupParticipant, own ...types.JID) bool {
+	admins := slices.DeleteFunc(slices.Clone(participants), func(participant types.GroupParticipant) bool {
+		return !participant.IsAdmin && !participant.IsSuperAdmin
+	})
+	return len(participants) > 1 && len(admins) == 1 && isOwnParticipant(admins[0], own...)
 }
 
-func (service serviceGroup) CreateGroup(ctx context.Context, request domainGroup.CreateGroupRequest) (groupID string, err error) {
+// ListGroups lists the groups the device participates in, most recently
+// active first. WhatsApp returns every group in one response, so the pages
+// are cut from it.
+func (service serviceGroup) ListGroups(ctx context.Context, request domainGroup.ListGroupsRequest) (response domainGroup.ListGroupsResponse, err error) {
+	if err = validations.ValidateListGroups(ctx, &request); err != nil {
+		return response, err
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+	utils.MustLogin(client)
+
+	groups, err := client.GetJoinedGroups(ctx)
+	if err != nil {
+		return response, err
+	}
+	whatsapp.StoreCommunityChatTypes(ctx, service.chatStorageRepo, deviceIDFromContext(ctx), groups)
+
+	chats, err := service.chatStorageRepo.GetChats(ctx, &domainChatStorage.ChatFilter{DeviceID: deviceIDFromContext(ctx), ChatType: domainChatStorage.ChatTypeGroup})
+	if err != nil {
+		logrus.WithContext(ctx).Warnf("Failed to load stored group chats: %v", err)
+	}
+
+	summaries := groupSummaries(groups, chats, client.Store.GetJID(), client.Store.GetLID())
+	start := min(request.Offset, len(summaries))
+	end := min(start+request.Limit, len(summaries))
+	response.Data = summaries[start:end]
+	response.Pagination = domainGroup.PaginationResponse{Limit: request.Limit, Offset: request.Offset, Total: len(summaries)}
+	return response, nil
+}
+
+// groupSummaries combines the joined groups with their stored chats, sorted
+// by last message, then name. Groups without stored messages come last.
+func groupSummaries(groups []*types.GroupInfo, chats []*domainChatStorage.Chat, own ...types.JID) []domainGroup.GroupSummary {
+	stored := make(map[string]*domainChatStorage.Chat, len(chats))
+	for _, chat := range chats {
+		stored[chat.JID] = chat
+	}
+
+	summaries := make([]domainGroup.GroupSummary, 0, len(groups))
+	for _, group := range groups {
+		summary := domainGroup.GroupSummary{
+			JID:              group.JID.String(),
+			Name:             group.Name,
+			Topic:            group.Topic,
+			ParticipantCount: max(group.ParticipantCount, len(group.Participants)),
+			IsAdmin:          isGroupAdmin(group.Participants, own...),
+			IsAnnounce:       group.IsAnnounce,
+			IsLocked:         group.IsLocked,
+			CreatedAt:        group.GroupCreated,
+		}
+		if chat, ok := stored[summary.JID]; ok {
+			summary.UnreadCount = chat.UnreadCount
+			if !chat.LastMessageTime.IsZero() {
+				summary.LastMessageTime = &chat.LastMessageTime
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+
+	slices.SortStableFunc(summaries, func(a, b domainGroup.GroupSummary) int {
+		switch {
+		case a.LastMessageTime == nil && b.LastMessageTime != nil:
+			return 1
+		case a.LastMessageTime != nil && b.LastMessageTime == nil:
+			return -1
+		case a.LastMessageTime != nil && !a.LastMessageTime.Equal(*b.LastMessageTime):
+			return b.LastMessageTime.Compare(*a.LastMessageTime)
+		}
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return summaries
+}
+
+// CreateGroup creates a group with the announce and restrict settings in
+// place from the start, then sets its description. Participants that can't
+// be added don't fail the creation; each one gets its own status. The group
+// is stored as a chat right away, so it's listed before its first message.
+func (service serviceGroup) CreateGroup(ctx context.Context, request domainGroup.CreateGroupRequest) (response domainGroup.CreateGroupResponse, err error) {
 	if err = validations.ValidateCreateGroup(ctx, request); err != nil {
-		return groupID, err
+		return response, err
 	}
 
 	client := whatsapp.ClientFromContext(ctx)
 	if client == nil {
-		return groupID, pkgError.ErrWaCLI
+		return response, pkgError.ErrWaCLI
 	}
 	utils.MustLogin(client)
 
@@ -77,6 +224,8 @@ func (service serviceGroup) CreateGroup(ctx context.Context, request domainGroup
 	groupConfig := whatsmeow.ReqCreateGroup{
 		Name:              request.Title,
 		Participants:      participantsJID,
+		GroupAnnounce:     types.GroupAnnounce{IsAnnounce: request.Announce},
+		GroupLocked:       types.GroupLocked{IsLocked: request.Restrict},
 		GroupParent:       types.GroupParent{},
 		GroupLinkedParent: types.GroupLinkedParent{},
 	}
@@ -86,7 +235,126 @@ func (service serviceGroup) CreateGroup(ctx context.Context, request domainGroup
 		return
 	}
 
-	return groupInfo.JID.String(), nil
+	if request.Description != "" {
+		if err := client.SetGroupTopic(ctx, groupInfo.JID, "", "", request.Description); err != nil {
+			logrus.WithContext(ctx).Warnf("Created group %s but failed to set its description: %v", groupInfo.JID, err)
+		}
+	}
+
+	deviceID := deviceIDFromContext(ctx)
+	createdAt := groupInfo.GroupCreated
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	chat := &domainChatStorage.Chat{DeviceID: deviceID, JID: groupInfo.JID.String(), Name: request.Title, LastMessageTime: createdAt}
+	if err := service.chatStorageRepo.StoreChat(ctx, chat); err != nil {
+		logrus.WithContext(ctx).Warnf("Failed to store chat of created group %s: %v", groupInfo.JID, err)
+	}
+	if err := service.chatStorageRepo.SyncGroupParticipants(ctx, deviceID, groupInfo.JID.String(), whatsapp.GroupParticipantsFromInfo(ctx, groupInfo, client)); err != nil {
+		logrus.WithContext(ctx).Warnf("Failed to cache participants of created group %s: %v", groupInfo.JID, err)
+	}
+
+	response.GroupID = groupInfo.JID.String()
+	// The creator is listed too, but wasn't requested
+	added := slices.DeleteFunc(slices.Clone(groupInfo.Participants), func(participant types.GroupParticipant) bool {
+		return isOwnParticipant(participant, client.Store.GetJID(), client.Store.GetLID())
+	})
+	response.Participants = participantStatuses(whatsmeow.ParticipantChangeAdd, added)
+	return response, nil
+}
+
+// groupParticipantErrors explains the error codes WhatsApp returns for
+// participants a change failed for.
+var groupParticipantErrors = map[int]string{
+	400: "Not a valid WhatsApp account",
+	403: "Their privacy settings don't allow adding them, send them the invite code instead",
+	404: "Not a participant of the group",
+	408: "They recently left the group and can't be added back yet",
+	409: "Already a participant",
+}
+
+// participantChangeMessages describes a change that succeeded.
+var participantChangeMessages = map[whatsmeow.ParticipantChange]string{
+	whatsmeow.ParticipantChangeAdd:     "Participant added",
+	whatsmeow.ParticipantChangeRemove:  "Participant removed",
+	whatsmeow.ParticipantChangePromote: "Participant promoted",
+	whatsmeow.ParticipantChangeDemote:  "Participant demoted",
+}
+
+// participantStatuses reports whether action succeeded for each participant
+// WhatsApp answered with. Participants that can only be invited come with the
+// invite code WhatsApp issued for them.
+func participantStatuses(action whatsmeow.ParticipantChange, participants []types.GroupParticipant) []domainGroup.ParticipantStatus {
+	result := make([]domainGroup.ParticipantStatus, 0, len(participants))
+	for _, participant := range participants {
+		status := domainGroup.ParticipantStatus{
+			Participant: participantJID(participant).String(),
+			Status:      "success",
+			Message:     participantChangeMessages[action],
+		}
+		if participant.Error != 0 {
+			status.Status, status.Code = "error", participant.Error
+			status.Message = groupParticipantErrors[participant.Error]
+			if status.Message == "" {
+				status.Message = fmt.Sprintf("Failed to %s participant (error %d)", action, participant.Error)
+			}
+		}
+		if participant.AddRequest != nil {
+			status.InviteCode = participant.AddRequest.Code
+			if !participant.AddRequest.Expiration.IsZero() {
+				status.InviteExpiration = &participant.AddRequest.Expiration
+			}
+		}
+		result = append(result, status)
+	}
+	return result
+}
+
+// participantJID returns the JID a participant is reported and cached by,
+// the phone number rather than the LID when it's known.
+func participantJID(participant types.GroupParticipant) types.JID {
+	jid := participant.JID
+	if jid.Server == types.HiddenUserServer && !participant.PhoneNumber.IsEmpty() {
+		jid = participant.PhoneNumber
+	}
+	return jid.ToNonAD()
+}
+
+// isOwnParticipant reports whether participant is the device, known by any
+// of the JIDs in own.
+func isOwnParticipant(participant types.GroupParticipant, own ...types.JID) bool {
+	return slices.ContainsFunc(own, func(jid types.JID) bool {
+		jid = jid.ToNonAD()
+		return !jid.IsEmpty() && (participant.JID.ToNonAD() == jid || participant.PhoneNumber.ToNonAD() == jid || participant.LID.ToNonAD() == jid)
+	})
+}
+
+// isGroupAdmin reports whether the device, known by own, is an admin of a
+// group with these participants.
+func isGroupAdmin(participants []types.GroupParticipant, own ...types.JID) bool {
+	return slices.ContainsFunc(participants, func(participant types.GroupParticipant) bool {
+		return (participant.IsAdmin || participant.IsSuperAdmin) && isOwnParticipant(participant, own...)
+	})
+}
+
+// canEditGroup reports whether the device, known by own, may change group.
+// The name, topic and photo are open to every member while the group isn't
+// locked; adminOnly changes never are.
+func canEditGroup(group *types.GroupInfo, adminOnly bool, own ...types.JID) bool {
+	return (!adminOnly && !group.IsLocked) || isGroupAdmin(group.Participants, own...)
+}
+
+// requireGroupAdmin fails with NotGroupAdminError when the device may not
+// make the change to the group that action describes.
+func requireGroupAdmin(ctx context.Context, client *whatsmeow.Client, groupJID types.JID, adminOnly bool, action string) error {
+	groupInfo, err := groupInfoFn(ctx, client, groupJID)
+	if err != nil {
+		return err
+	}
+	if !canEditGroup(groupInfo, adminOnly, client.Store.GetJID(), client.Store.GetLID()) {
+		return pkgError.NotGroupAdminError(fmt.Sprintf("you must be an admin of group %s to %s", groupJID, action))
+	}
+	return nil
 }
 
 func (service serviceGroup) GetGroupInfoFromLink(ctx context.Context, request domainGroup.GetGroupInfoFromLinkRequest) (response domainGroup.GetGroupInfoFromLinkResponse, err error) {
@@ -100,9 +368,10 @@ func (service serviceGroup) GetGroupInfoFromLink(ctx context.Context, request do
 	}
 	utils.MustLogin(client)
 
-	groupInfo, err := client.GetGroupInfoFromLink(ctx, request.Link)
+	code, _ := utils.GroupInviteCode(request.Link)
+	groupInfo, err := client.GetGroupInfoFromLink(ctx, code)
 	if err != nil {
-		return response, err
+		return response, inviteLinkError(err)
 	}
 
 	response = domainGroup.GetGroupInfoFromLinkResponse{
@@ -110,16 +379,24 @@ func (service serviceGroup) GetGroupInfoFromLink(ctx context.Context, request do
 		Name:             groupInfo.Name,
 		Topic:            groupInfo.Topic,
 		CreatedAt:        groupInfo.GroupCreated,
-		ParticipantCount: len(groupInfo.Participants),
+		ParticipantCount: max(groupInfo.ParticipantCount, len(groupInfo.Participants)),
 		IsLocked:         groupInfo.IsLocked,
 		IsAnnounce:       groupInfo.IsAnnounce,
 		IsEphemeral:      groupInfo.IsEphemeral,
 		Description:      groupInfo.Topic, // Topic serves as description
+		RequiresApproval: groupInfo.IsJoinApprovalRequired,
+	}
+	if creator := groupInfo.OwnerPN; !creator.IsEmpty() {
+		response.Creator = creator.ToNonAD().String()
+	} else if !groupInfo.OwnerJID.IsEmpty() {
+		response.Creator = whatsapp.NormalizeJIDFromLID(ctx, groupInfo.OwnerJID, client).ToNonAD().String()
 	}
 
 	return response, nil
 }
 
+// ManageParticipant adds, removes, promotes or demotes participants and
+// reports the outcome for each one, keeping the participant cache in step.
 func (service serviceGroup) ManageParticipant(ctx context.Context, request domainGroup.ParticipantRequest) (result []domainGroup.ParticipantStatus, err error) {
 	if err = validations.ValidateParticipant(ctx, request); err != nil {
 		return result, err
@@ -141,28 +418,49 @@ func (service serviceGroup) ManageParticipant(ctx context.Context, request domai
 		return result, err
 	}
 
+	// Only admins can act on other members; ask first rather than relay the server's refusal
+	if request.Action != whatsmeow.ParticipantChangeAdd {
+		if err = requireGroupAdmin(ctx, client, groupJID, true, fmt.Sprintf("%s participants", request.Action)); err != nil {
+			return result, err
+		}
+	}
+
 	participants, err := client.UpdateGroupParticipants(ctx, groupJID, participantsJID, request.Action)
 	if err != nil {
 		return result, err
 	}
 
+	service.cacheParticipantChange(ctx, client, groupJID, request.Action, participants)
+	return participantStatuses(request.Action, participants), nil
+}
+
+// cacheParticipantChange applies a change to the participant cache for the
+// participants it succeeded for.
+func (service serviceGroup) cacheParticipantChange(ctx context.Context, client *whatsmeow.Client, groupJID types.JID, action whatsmeow.ParticipantChange, participants []types.GroupParticipant) {
+	var changed []string
 	for _, participant := range participants {
-		if participant.Error == 403 && participant.AddRequest != nil {
-			result = append(result, domainGroup.ParticipantStatus{
-				Participant: participant.JID.String(),
-				Status:      "error",
-				Message:     "Failed to add participant",
-			})
-		} else {
-			result = append(result, domainGroup.ParticipantStatus{
-				Participant: participant.JID.String(),
-				Status:      "success",
-				Message:     "Action success",
-			})
+		if participant.Error == 0 {
+			changed = append(changed, whatsapp.NormalizeJIDFromLID(ctx, participantJID(participant), client).ToNonAD().String())
 		}
 	}
+	if len(changed) == 0 {
+		return
+	}
 
-	return result, nil
+	change := domainChatStorage.GroupParticipantChange{Timestamp: time.Now()}
+	switch action {
+	case whatsmeow.ParticipantChangeAdd:
+		change.Join = changed
+	case whatsmeow.ParticipantChangeRemove:
+		change.Leave = changed
+	case whatsmeow.ParticipantChangePromote:
+		change.Promote = changed
+	case whatsmeow.ParticipantChangeDemote:
+		change.Demote = changed
+	}
+	if err := service.chatStorageRepo.UpdateGroupParticipants(ctx, deviceIDFromContext(ctx), groupJID.String(), change); err != nil {
+		logrus.WithContext(ctx).Warnf("Failed to update participant cache of group %s: %v", groupJID, err)
+	}
 }
 
 func (service serviceGroup) GetGroupParticipants(ctx context.Context, request domainGroup.GetGroupParticipantsRequest) (response domainGroup.GetGroupParticipantsResponse, err error) {
@@ -185,6 +483,96 @@ func (service serviceGroup) GetGroupParticipants(ctx context.Context, request do
 		return response, err
 	}
 
+	return groupParticipantsResponse(ctx, client, groupJID, groupInfo), nil
+}
+
+// GetCachedGroupParticipants answers from the participant cache, loading it
+// from WhatsApp when asked to or when the group is not cached yet.
+func (service serviceGroup) GetCachedGroupParticipants(ctx context.Context, request domainGroup.GetGroupParticipantsRequest) (response domainGroup.GetGroupParticipantsResponse, err error) {
+	if err = validations.ValidateGetGroupParticipants(ctx, request); err != nil {
+		return response, err
+	}
+	if request.Refresh {
+		return service.RefreshGroupParticipants(ctx, request)
+	}
+
+	deviceID := deviceIDFromContext(ctx)
+	if deviceID == "" {
+		return response, fmt.Errorf("device identification required")
+	}
+
+	groupJID, err := utils.ParseJID(request.GroupID)
+	if err != nil {
+		return response, err
+	}
+
+	participants, err := service.chatStorageRepo.GetGroupParticipants(ctx, deviceID, groupJID.String())
+	if err != nil {
+		return response, err
+	}
+	if len(participants) == 0 {
+		return service.RefreshGroupParticipants(ctx, request)
+	}
+
+	response.GroupID = groupJID.String()
+	if chat, err := service.chatStorageRepo.GetChatByDevice(ctx, deviceID, groupJID.String()); err == nil && chat != nil {
+		response.Name = chat.Name
+	}
+	response.Participants = make([]domainGroup.GroupParticipant, 0, len(participants))
+	for _, p := range participants {
+		participant := domainGroup.GroupParticipant{
+			JID:          p.ParticipantJID,
+			PhoneNumber:  strings.Split(p.ParticipantJID, "@")[0],
+			IsAdmin:      p.IsAdmin,
+			IsSuperAdmin: p.IsSuperAdmin,
+		}
+		if p.JoinedAt != nil {
+			participant.JoinedAt = p.JoinedAt.Format(time.RFC3339)
+		}
+		response.Participants = append(response.Participants, participant)
+	}
+	return response, nil
+}
+
+// RefreshGroupParticipants fetches the group from WhatsApp, reconciles the
+// participant cache with it and returns the live participants.
+func (service serviceGroup) RefreshGroupParticipants(ctx context.Context, request domainGroup.GetGroupParticipantsRequest) (response domainGroup.GetGroupParticipantsResponse, err error) {
+	if err = validations.ValidateGetGroupParticipants(ctx, request); err != nil {
+		return response, err
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+
+	groupJID, err := utils.ValidateJidWithLogin(client, request.GroupID)
+	if err != nil {
+		return response, err
+	}
+
+	groupInfo, err := client.GetGroupInfo(ctx, groupJID)
+	if err != nil {
+		return response, err
+	}
+
+	deviceID := deviceIDFromContext(ctx)
+	if deviceID == "" {
+		return response, fmt.Errorf("device identification required")
+	}
+
+	participants := whatsapp.GroupParticipantsFromInfo(ctx, groupInfo, client)
+	if err = service.chatStorageRepo.SyncGroupParticipants(ctx, deviceID, groupJID.String(), participants); err != nil {
+		logrus.WithError(err).WithField("group_id", groupJID.String()).Error("Failed to cache group participants")
+		return response, err
+	}
+
+	return groupParticipantsResponse(ctx, client, groupJID, groupInfo), nil
+}
+
+// groupParticipantsResponse lists the members of groupInfo, resolving display
+// names from the contact store and verified business names.
+func groupParticipantsResponse(ctx context.Context, client *whatsmeow.Client, groupJID types.JID, groupInfo *types.GroupInfo) (response domainGroup.GetGroupParticipantsResponse) {
 	response.GroupID = groupJID.String()
 	if groupInfo != nil {
 		response.Name = groupInfo.GroupName.Name
@@ -234,7 +622,7 @@ func (service serviceGroup) GetGroupParticipants(ctx context.Context, request do
 		}
 	}
 
-	return response, nil
+	return response
 }
 
 func (service serviceGroup) GetGroupRequestParticipants(ctx context.Context, request domainGroup.GetGroupRequestParticipantsRequest) (result []domainGroup.GetGroupRequestParticipantsResponse, err error) {
@@ -280,9 +668,11 @@ func (service serviceGroup) GetGroupRequestParticipants(ctx context.Context, req
 			displayName = info.VerifiedName.Details.GetVerifiedName()
 		}
 
+		// Requesters may be known by their LID only
+		phoneJID := whatsapp.NormalizeJIDFromLID(ctx, participant.JID, client)
 		result = append(result, domainGroup.GetGroupRequestParticipantsResponse{
 			JID:         participant.JID.String(),
-			PhoneNumber: participant.JID.User,
+			PhoneNumber: phoneJID.User,
 			DisplayName: displayName,
 			RequestedAt: participant.RequestedAt,
 		})
@@ -311,28 +701,40 @@ func (service serviceGroup) ManageGroupRequestParticipants(ctx context.Context,
 		return result, err
 	}
 
+	if err = requireGroupAdmin(ctx, client, groupJID, true, fmt.Sprintf("%s join requests", request.Action)); err != nil {
+		return result, err
+	}
+
 	participants, err := client.UpdateGroupRequestParticipants(ctx, groupJID, participantsJID, request.Action)
 	if err != nil {
 		return result, err
 	}
 
+	return joinRequestStatuses(request.Action, participants), nil
+}
+
+// joinRequestMessages describes an action on a join request that succeeded.
+var joinRequestMessages = map[whatsmeow.ParticipantRequestChange]string{
+	whatsmeow.ParticipantChangeApprove: "Request approved",
+	whatsmeow.ParticipantChangeReject:  "Request rejected",
+}
+
+// joinRequestStatuses reports whether action succeeded for the join request
+// of each participant WhatsApp answered with.
+func joinRequestStatuses(action whatsmeow.ParticipantRequestChange, participants []types.GroupParticipant) []domainGroup.ParticipantStatus {
+	result := make([]domainGroup.ParticipantStatus, 0, len(participants))
 	for _, participant := range participants {
-		if participant.Error != 0 {
-			result = append(result, domainGroup.ParticipantStatus{
-				Participant: participant.JID.String(),
-				Status:      "error",
-				Message:     fmt.Sprintf("Action %s failed (code %d)", request.Action, participant.Error),
-			})
-		} else {
-			result = append(result, domainGroup.ParticipantStatus{
-				Participant: participant.JID.String(),
-				Status:      "success",
-				Message:     fmt.Sprintf("Action %s success", request.Action),
-			})
+		status := domainGroup.ParticipantStatus{Participant: participantJID(participant).String(), Status: "success", Message: joinRequestMessages[action]}
+		switch participant.Error {
+		case 0:
+		case 404:
+			status.Status, status.Code, status.Message = "error", participant.Error, "No pending request to join from this participant"
+		default:
+			status.Status, status.Code, status.Message = "error", participant.Error, fmt.Sprintf("Failed to %s request (error %d)", action, participant.Error)
 		}
+		result = append(result, status)
 	}
-
-	return result, nil
+	return result
 }
 
 func (service serviceGroup) participantToJID(ctx context.Context, participants []string) ([]types.JID, error) {
@@ -370,6 +772,9 @@ func (service serviceGroup) SetGroupPhoto(ctx context.Context, request domainGro
 	if err != nil {
 		return pictureID, err
 	}
+	if err = requireGroupAdmin(ctx, client, groupJID, false, "change its photo"); err != nil {
+		return pictureID, err
+	}
 
 	var photoBytes []byte
 	if request.Photo != nil {
@@ -412,8 +817,23 @@ func (service serviceGroup) SetGroupName(ctx context.Context, request domainGrou
 	if err != nil {
 		return err
 	}
+	if err = requireGroupAdmin(ctx, client, groupJID, false, "rename it"); err != nil {
+		return err
+	}
 
-	return client.SetGroupName(ctx, groupJID, request.Name)
+	if err = client.SetGroupName(ctx, groupJID, request.Name); err != nil {
+		return err
+	}
+
+	// Chat lists show the new name right away rather than after the notification
+	deviceID := deviceIDFromContext(ctx)
+	if chat, err := service.chatStorageRepo.GetChatByDevice(ctx, deviceID, groupJID.String()); err == nil && chat != nil {
+		chat.Name = request.Name
+		if err := service.chatStorageRepo.StoreChat(ctx, chat); err != nil {
+			logrus.WithContext(ctx).Warnf("Failed to store new name of group %s: %v", groupJID, err)
+		}
+	}
+	return nil
 }
 
 func (service serviceGroup) SetGroupLocked(ctx context.Context, request domainGroup.SetGroupLockedRequest) (err error) {
@@ -430,6 +850,9 @@ func (service serviceGroup) SetGroupLocked(ctx context.Context, request domainGr
 	if err != nil {
 		return err
 	}
+	if err = requireGroupAdmin(ctx, client, groupJID, true, "change who can edit its info"); err != nil {
+		return err
+	}
 
 	return client.SetGroupLocked(ctx, groupJID, request.Locked)
 }
@@ -448,6 +871,9 @@ func (service serviceGroup) SetGroupAnnounce(ctx context.Context, request domain
 	if err != nil {
 		return err
 	}
+	if err = requireGroupAdmin(ctx, client, groupJID, true, "change who can send messages"); err != nil {
+		return err
+	}
 
 	return client.SetGroupAnnounce(ctx, groupJID, request.Announce)
 }
@@ -466,11 +892,65 @@ func (service serviceGroup) SetGroupTopic(ctx context.Context, request domainGro
 	if err != nil {
 		return err
 	}
+	if err = requireGroupAdmin(ctx, client, groupJID, false, "change its topic"); err != nil {
+		return err
+	}
 
 	// SetGroupTopic with auto-generated IDs (previousID and newID will be handled automatically)
 	return client.SetGroupTopic(ctx, groupJID, "", "", request.Topic)
 }
 
+// SetGroupSettings applies the settings given in request one by one. WhatsApp
+// echoes each change as a group notification, which is what the group.updated
+// webhook event is sent for.
+func (service serviceGroup) SetGroupSettings(ctx context.Context, request domainGroup.SetGroupSettingsRequest) (err error) {
+	if err = validations.ValidateSetGroupSettings(ctx, request); err != nil {
+		return err
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return pkgError.ErrWaCLI
+	}
+
+	groupJID, err := utils.ValidateJidWithLogin(client, request.GroupID)
+	if err != nil {
+		return err
+	}
+	// Of these settings only the disappearing timer is open to members of unlocked groups
+	adminOnly := request.Announce != nil || request.Locked != nil || request.MemberAddMode != ""
+	if err = requireGroupAdmin(ctx, client, groupJID, adminOnly, "change its settings"); err != nil {
+		return err
+	}
+
+	if request.Locked != nil {
+		if err = client.SetGroupLocked(ctx, groupJID, *request.Locked); err != nil {
+			return fmt.Errorf("failed to set locked: %w", err)
+		}
+	}
+	if request.Announce != nil {
+		if err = client.SetGroupAnnounce(ctx, groupJID, *request.Announce); err != nil {
+			return fmt.Errorf("failed to set announce: %w", err)
+		}
+	}
+	if request.MemberAddMode != "" {
+		if err = client.SetGroupMemberAddMode(ctx, groupJID, types.GroupMemberAddMode(request.MemberAddMode)); err != nil {
+			return fmt.Errorf("failed to set member_add_mode: %w", err)
+		}
+	}
+	if request.DisappearingTimer != nil {
+		timer := *request.DisappearingTimer
+		if err = client.SetDisappearingTimer(ctx, groupJID, time.Duration(timer)*time.Second, time.Now()); err != nil {
+			return fmt.Errorf("failed to set disappearing_timer: %w", err)
+		}
+		// Messages sent right away use the new timer
+		if _, err := service.chatStorageRepo.SetEphemeralExpiration(ctx, deviceIDFromContext(ctx), groupJID.String(), timer); err != nil {
+			logrus.WithContext(ctx).Warnf("Failed to store disappearing timer of group %s: %v", groupJID, err)
+		}
+	}
+	return nil
+}
+
 // GroupInfo retrieves detailed information about a WhatsApp group
 func (service serviceGroup) GroupInfo(ctx context.Context, request domainGroup.GroupInfoRequest) (response domainGroup.GroupInfoResponse, err error) {
 	// Validate the incoming request
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+
+	domainGroup "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/group"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
+)
+
+// ListCommunities lists the communities the device is a member of, each with
+// all of its linked groups, including those the device hasn't joined.
+func (service serviceGroup) ListCommunities(ctx context.Context) (response domainGroup.ListCommunitiesResponse, err error) {
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+	utils.MustLogin(client)
+
+	groups, err := client.GetJoinedGroups(ctx)
+	if err != nil {
+		return response, err
+	}
+	whatsapp.StoreCommunityChatTypes(ctx, service.chatStorageRepo, deviceIDFromContext(ctx), groups)
+
+	joined := make(map[types.JID]bool, len(groups))
+	for _, group := range groups {
+		joined[group.JID] = true
+	}
+
+	response.Data = []domainGroup.Community{}
+	for _, group := range groups {
+		if !group.IsParent {
+			continue
+		}
+		linked, err := client.GetSubGroups(ctx, group.JID)
+		if err != nil {
+			return response, fmt.Errorf("failed to get the groups of community %s: %w", group.JID, err)
+		}
+		response.Data = append(response.Data, communityFromInfo(group, linked, joined, client.Store.GetJID(), client.Store.GetLID()))
+	}
+	return response, nil
+}
+
+// communityFromInfo describes a community with its linked groups, the
+// announcement group first and the others by name.
+func communityFromInfo(info *types.GroupInfo, linked []*types.GroupLinkTarget, joined map[types.JID]bool, own ...types.JID) domainGroup.Community {
+	community := domainGroup.Community{
+		JID:       info.JID.String(),
+		Name:      info.Name,
+		Topic:     info.Topic,
+		IsAdmin:   isGroupAdmin(info.Participants, own...),
+		CreatedAt: info.GroupCreated,
+		Groups:    make([]domainGroup.CommunityGroup, 0, len(linked)),
+	}
+	for _, group := range linked {
+		community.Groups = append(community.Groups, domainGroup.CommunityGroup{
+			JID:            group.JID.String(),
+			Name:           group.Name,
+			IsAnnouncement: group.IsDefaultSubGroup,
+			IsMember:       joined[group.JID],
+		})
+	}
+	slices.SortStableFunc(community.Groups, func(a, b domainGroup.CommunityGroup) int {
+		if a.IsAnnouncement != b.IsAnnouncement {
+			if a.IsAnnouncement {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return community
+}
+
+// LinkCommunityGroup links an existing group to a community. WhatsApp only
+// lets admins of both link a group.
+func (service serviceGroup) LinkCommunityGroup(ctx context.Context, request domainGroup.CommunityGroupRequest) (err error) {
+	client, community, group, err := communityGroupJIDs(ctx, request)
+	if err != nil {
+		return err
+	}
+	if err = requireCommunityAdmin(ctx, client, community, "link groups to it"); err != nil {
+		return err
+	}
+	if err = requireGroupAdmin(ctx, client, group, true, "link it to a community"); err != nil {
+		return err
+	}
+	return client.LinkGroup(ctx, community, group)
+}
+
+// UnlinkCommunityGroup removes a group from a community. The group itself
+// and its members stay as they are.
+func (service serviceGroup) UnlinkCommunityGroup(ctx context.Context, request domainGroup.CommunityGroupRequest) (err error) {
+	client, community, group, err := communityGroupJIDs(ctx, request)
+	if err != nil {
+		return err
+	}
+	if err = requireCommunityAdmin(ctx, client, community, "unlink groups from it"); err != nil {
+		return err
+	}
+	return client.UnlinkGroup(ctx, community, group)
+}
+
+func communityGroupJIDs(ctx context.Context, request domainGroup.CommunityGroupRequest) (client *whatsmeow.Client, community, group types.JID, err error) {
+	if err = validations.ValidateCommunityGroup(ctx, request); err != nil {
+		return nil, community, group, err
+	}
+
+	client = whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return nil, community, group, pkgError.ErrWaCLI
+	}
+
+	if community, err = utils.ValidateJidWithLogin(client, request.CommunityID); err != nil {
+		return nil, community, group, err
+	}
+	if group, err = utils.ValidateJidWithLogin(client, request.GroupID); err != nil {
+		return nil, community, group, err
+	}
+	return client, community, group, nil
+}
+
+// requireCommunityAdmin fails unless jid is a community the device is an
+// admin of.
+func requireCommunityAdmin(ctx context.Context, client *whatsmeow.Client, jid types.JID, action string) error {
+	info, err := groupInfoFn(ctx, client, jid)
+	if err != nil {
+		return err
+	}
+	if !info.IsParent {
+		return pkgError.ValidationError(fmt.Sprintf("%s is not a community", jid))
+	}
+	if !isGroupAdmin(info.Participants, client.Store.GetJID(), client.Store.GetLID()) {
+		return pkgError.NotGroupAdminError(fmt.Sprintf("you must be an admin of community %s to %s", jid, action))
+	}
+	return nil
+}
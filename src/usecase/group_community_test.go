@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	domainGroup "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/group"
+	"github.com/stretchr/testify/assert"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestCommunityFromInfo(t *testing.T) {
+	own := types.NewJID("628000", types.DefaultUserServer)
+	created := time.Date(2026, time.March, 9, 9, 0, 0, 0, time.UTC)
+	info := &types.GroupInfo{
+		JID:          types.NewJID("120363000000000001", types.GroupServer),
+		GroupName:    types.GroupName{Name: "Neighbours"},
+		GroupParent:  types.GroupParent{IsParent: true},
+		GroupCreated: created,
+		Participants: []types.GroupParticipant{{JID: own, IsSuperAdmin: true}},
+	}
+	announcements := types.NewJID("120363000000000002", types.GroupServer)
+	garden := types.NewJID("120363000000000003", types.GroupServer)
+	cars := types.NewJID("120363000000000004", types.GroupServer)
+	linked := []*types.GroupLinkTarget{
+		{JID: garden, GroupName: types.GroupName{Name: "garden"}},
+		{JID: announcements, GroupName: types.GroupName{Name: "Neighbours"}, GroupIsDefaultSub: types.GroupIsDefaultSub{IsDefaultSubGroup: true}},
+		{JID: cars, GroupName: types.GroupName{Name: "Cars"}},
+	}
+
+	community := communityFromInfo(info, linked, map[types.JID]bool{announcements: true, garden: true}, own)
+	assert.Equal(t, "120363000000000001@g.us", community.JID)
+	assert.True(t, community.IsAdmin)
+	assert.Equal(t, created, community.CreatedAt)
+	assert.Equal(t, []domainGroup.CommunityGroup{
+		{JID: "120363000000000002@g.us", Name: "Neighbours", IsAnnouncement: true, IsMember: true},
+		{JID: "120363000000000004@g.us", Name: "Cars"},
+		{JID: "120363000000000003@g.us", Name: "garden", IsMember: true},
+	}, community.Groups)
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainGroup "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/group"
+	"github.com/stretchr/testify/assert"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestParticipantStatuses(t *testing.T) {
+	expiration := time.Date(2026, time.March, 9, 9, 0, 0, 0, time.UTC)
+	participants := []types.GroupParticipant{
+		{JID: types.NewJID("11111", types.HiddenUserServer), PhoneNumber: types.NewJID("628111", types.DefaultUserServer)},
+		{JID: types.NewJID("628222", types.DefaultUserServer), Error: 403, AddRequest: &types.GroupParticipantAddRequest{Code: "AbCdEf", Expiration: expiration}},
+		{JID: types.NewJID("628333", types.DefaultUserServer), Error: 408},
+		{JID: types.NewJID("628444", types.DefaultUserServer), Error: 500},
+	}
+
+	result := participantStatuses(whatsmeow.ParticipantChangeAdd, participants)
+	assert.Equal(t, []domainGroup.ParticipantStatus{
+		{Participant: "628111@s.whatsapp.net", Status: "success", Message: "Participant added"},
+		{Participant: "628222@s.whatsapp.net", Status: "error", Message: "Their privacy settings don't allow adding them, send them the invite code instead", Code: 403, InviteCode: "AbCdEf", InviteExpiration: &expiration},
+		{Participant: "628333@s.whatsapp.net", Status: "error", Message: "They recently left the group and can't be added back yet", Code: 408},
+		{Participant: "628444@s.whatsapp.net", Status: "error", Message: "Failed to add participant (error 500)", Code: 500},
+	}, result)
+
+	result = participantStatuses(whatsmeow.ParticipantChangeDemote, participants[:1])
+	assert.Equal(t, "Participant demoted", result[0].Message)
+}
+
+func TestJoinRequestStatuses(t *testing.T) {
+	participants := []types.GroupParticipant{
+		{JID: types.NewJID("11111", types.HiddenUserServer), PhoneNumber: types.NewJID("628111", types.DefaultUserServer)},
+		{JID: types.NewJID("628222", types.DefaultUserServer), Error: 404},
+		{JID: types.NewJID("628333", types.DefaultUserServer), Error: 500},
+	}
+
+	assert.Equal(t, []domainGroup.ParticipantStatus{
+		{Participant: "628111@s.whatsapp.net", Status: "success", Message: "Request approved"},
+		{Participant: "628222@s.whatsapp.net", Status: "error", Message: "No pending request to join from this participant", Code: 404},
+		{Participant: "628333@s.whatsapp.net", Status: "error", Message: "Failed to approve request (error 500)", Code: 500},
+	}, joinRequestStatuses(whatsmeow.ParticipantChangeApprove, participants))
+	assert.Equal(t, "Request rejected", joinRequestStatuses(whatsmeow.ParticipantChangeReject, participants[:1])[0].Message)
+}
+
+func TestIsGroupAdmin(t *testing.T) {
+	own := types.NewADJID("628000", 0, 12)
+	ownLID := types.NewJID("99000", types.HiddenUserServer)
+	member := types.GroupParticipant{JID: types.NewJID("628111", types.DefaultUserServer), IsAdmin: true}
+
+	assert.True(t, isGroupAdmin([]types.GroupParticipant{member, {JID: ownLID, IsSuperAdmin: true}}, own, ownLID))
+	assert.True(t, isGroupAdmin([]types.GroupParticipant{{JID: ownLID, PhoneNumber: own.ToNonAD(), IsAdmin: true}}, own, types.EmptyJID), "matched by phone number")
+	assert.False(t, isGroupAdmin([]types.GroupParticipant{member, {JID: ownLID}}, own, ownLID), "another member is the admin")
+	assert.True(t, isOwnParticipant(types.GroupParticipant{JID: own.ToNonAD()}, own), "device part is ignored")
+}
+
+func TestCanEditGroup(t *testing.T) {
+	own := types.NewJID("628000", types.DefaultUserServer)
+	member := &types.GroupInfo{Participants: []types.GroupParticipant{{JID: own}}}
+	admin := &types.GroupInfo{Participants: []types.GroupParticipant{{JID: own, IsAdmin: true}}}
+
+	assert.True(t, canEditGroup(member, false, own), "members edit the info of unlocked groups")
+	assert.False(t, canEditGroup(member, true, own))
+
+	member.IsLocked, admin.IsLocked = true, true
+	assert.False(t, canEditGroup(member, false, own), "locked groups only let admins edit the info")
+	assert.True(t, canEditGroup(admin, false, own))
+	assert.True(t, canEditGroup(admin, true, own))
+}
+
+func TestIsOnlyAdmin(t *testing.T) {
+	own := types.NewJID("628000", types.DefaultUserServer)
+	member := types.GroupParticipant{JID: types.NewJID("628111", types.DefaultUserServer)}
+
+	assert.True(t, isOnlyAdmin([]types.GroupParticipant{{JID: own, IsSuperAdmin: true}, member}, own))
+	assert.False(t, isOnlyAdmin([]types.GroupParticipant{{JID: own, IsAdmin: true}}, own), "nobody is left without an admin")
+	assert.False(t, isOnlyAdmin([]types.GroupParticipant{{JID: own, IsAdmin: true}, {JID: member.JID, IsAdmin: true}}, own))
+	assert.False(t, isOnlyAdmin([]types.GroupParticipant{{JID: own}, {JID: member.JID, IsAdmin: true}}, own))
+}
+
+func TestGroupSummaries(t *testing.T) {
+	own := types.NewJID("628000", types.DefaultUserServer)
+	earlier := time.Date(2026, time.March, 9, 9, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+	groups := []*types.GroupInfo{
+		{JID: types.NewJID("120363000000000001", types.GroupServer), GroupName: types.GroupName{Name: "quiet"}},
+		{JID: types.NewJID("120363000000000002", types.GroupServer), GroupName: types.GroupName{Name: "Archive"}},
+		{JID: types.NewJID("120363000000000003", types.GroupServer), GroupName: types.GroupName{Name: "busy"}, Participants: []types.GroupParticipant{{JID: own, IsAdmin: true}}, ParticipantCount: 1},
+		{JID: types.NewJID("120363000000000004", types.GroupServer), GroupName: types.GroupName{Name: "older"}},
+	}
+	chats := []*domainChatStorage.Chat{
+		{JID: "120363000000000003@g.us", LastMessageTime: later, UnreadCount: 4},
+		{JID: "120363000000000004@g.us", LastMessageTime: earlier},
+	}
+
+	summaries := groupSummaries(groups, chats, own)
+	names := make([]string, 0, len(summaries))
+	for _, summary := range summaries {
+		names = append(names, summary.Name)
+	}
+	assert.Equal(t, []string{"busy", "older", "Archive", "quiet"}, names, "by last message, then name")
+	assert.Equal(t, &later, summaries[0].LastMessageTime)
+	assert.Equal(t, 4, summaries[0].UnreadCount)
+	assert.Equal(t, 1, summaries[0].ParticipantCount)
+	assert.True(t, summaries[0].IsAdmin)
+	assert.Nil(t, summaries[2].LastMessageTime)
+}
@@ -0,0 +1,377 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainJob "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/job"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	jobQueueSize = 100
+	// jobInterruptedReason is the error of jobs a previous run left unfinished
+	jobInterruptedReason = "server restarted before the job finished"
+)
+
+var (
+	errJobQueueFull = errors.New("too many jobs queued, try again later")
+	errJobCanceled  = errors.New("job canceled")
+	errJobShutdown  = errors.New("server shut down before the job finished")
+)
+
+// serviceJob runs jobs on a pool of workers. Their state is kept in chat
+// storage, so jobs outlive the process, and the files they produce under
+// outputDir, one folder per job.
+type serviceJob struct {
+	chatStorageRepo domainChatStorage.IChatStorageRepository
+	outputDir       string
+	queue           chan *backgroundJob
+
+	// pending counts the jobs queued or running
+	pending utils.InFlight
+
+	mu     sync.Mutex
+	active map[string]*backgroundJob // Queued and running jobs by ID
+}
+
+// backgroundJob is a job queued or running in this process. record is
+// guarded by serviceJob.mu.
+type backgroundJob struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	run    domainJob.Run
+	record domainChatStorage.Job
+	done   chan struct{}
+}
+
+// NewJobService starts workers running that many jobs at the same time.
+func NewJobService(chatStorageRepo domainChatStorage.IChatStorageRepository, workers int) domainJob.IJobUsecase {
+	return newJobService(chatStorageRepo, workers, config.PathJobs)
+}
+
+func newJobService(chatStorageRepo domainChatStorage.IChatStorageRepository, workers int, outputDir string) *serviceJob {
+	service := &serviceJob{
+		chatStorageRepo: chatStorageRepo,
+		outputDir:       outputDir,
+		queue:           make(chan *backgroundJob, jobQueueSize),
+		active:          make(map[string]*backgroundJob),
+	}
+	for range max(workers, 1) {
+		go service.work()
+	}
+	return service
+}
+
+// Start queues run as a job of the device in ctx. The job keeps the values
+// of ctx, such as the device and the request ID, but not its cancellation.
+func (service *serviceJob) Start(ctx context.Context, jobType string, run domainJob.Run) (domainJob.Job, error) {
+	now := time.Now()
+	jobCtx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	job := &backgroundJob{
+		ctx:    jobCtx,
+		cancel: cancel,
+		run:    run,
+		done:   make(chan struct{}),
+		record: domainChatStorage.Job{
+			ID:        uuid.NewString(),
+			DeviceID:  deviceIDFromContext(ctx),
+			Type:      jobType,
+			Status:    domainChatStorage.JobStatusQueued,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+	if err := service.chatStorageRepo.StoreJob(ctx, &job.record); err != nil {
+		cancel(nil)
+		return domainJob.Job{}, fmt.Errorf("failed to store job: %w", err)
+	}
+
+	// Workers change the record as soon as the job is queued
+	response := service.jobFromRecord(&job.record)
+	service.pending.Add()
+	service.mu.Lock()
+	service.active[job.record.ID] = job
+	service.mu.Unlock()
+
+	select {
+	case service.queue <- job:
+		return response, nil
+	default:
+		service.finish(job, nil, errJobQueueFull)
+		return domainJob.Job{}, errJobQueueFull
+	}
+}
+
+func (service *serviceJob) ListJobs(ctx context.Context, request domainJob.ListJobsRequest) ([]domainJob.Job, error) {
+	if err := validations.ValidateListJobs(ctx, &request); err != nil {
+		return nil, err
+	}
+	records, err := service.chatStorageRepo.ListJobs(ctx, &domainChatStorage.JobFilter{
+		DeviceID: deviceIDFromContext(ctx),
+		Type:     request.Type,
+		Status:   request.Status,
+		Limit:    request.Limit,
+		Offset:   request.Offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	jobs := make([]domainJob.Job, 0, len(records))
+	for _, record := range records {
+		jobs = append(jobs, service.jobFromRecord(service.current(record)))
+	}
+	return jobs, nil
+}
+
+func (service *serviceJob) GetJob(ctx context.Context, id string) (domainJob.Job, error) {
+	record, err := service.loadJob(ctx, id)
+	if err != nil {
+		return domainJob.Job{}, err
+	}
+	return service.jobFromRecord(service.current(record)), nil
+}
+
+// GetJobOutput returns the file a succeeded job produced.
+func (service *serviceJob) GetJobOutput(ctx context.Context, id string) (domainJob.Output, error) {
+	record, err := service.loadJob(ctx, id)
+	if err != nil {
+		return domainJob.Output{}, err
+	}
+	if record.Status != domainChatStorage.JobStatusSucceeded {
+		return domainJob.Output{}, pkgError.ValidationError(fmt.Sprintf("job %s is %s, its output is ready once it succeeded", id, record.Status))
+	}
+	entries, err := os.ReadDir(filepath.Join(service.outputDir, record.ID))
+	if err != nil || len(entries) == 0 {
+		return domainJob.Output{}, pkgError.NotFoundError(fmt.Sprintf("job %s has no output", id))
+	}
+	name := entries[0].Name()
+	return domainJob.Output{Path: filepath.Join(service.outputDir, record.ID, name), Name: name}, nil
+}
+
+// CancelJob cancels a queued or running job and waits for it to stop, or
+// deletes a finished job and its output.
+func (service *serviceJob) CancelJob(ctx context.Context, id string) (domainJob.Job, error) {
+	service.mu.Lock()
+	job, ok := service.active[id]
+	service.mu.Unlock()
+	if ok && job.record.DeviceID == deviceIDFromContext(ctx) {
+		job.cancel(errJobCanceled)
+		select {
+		case <-job.done:
+		case <-ctx.Done():
+			return domainJob.Job{}, ctx.Err()
+		}
+		return service.GetJob(ctx, id)
+	}
+
+	record, err := service.loadJob(ctx, id)
+	if err != nil {
+		return domainJob.Job{}, err
+	}
+	response := service.jobFromRecord(record)
+	if _, err := service.chatStorageRepo.DeleteJob(ctx, record.DeviceID, record.ID); err != nil {
+		return response, fmt.Errorf("failed to delete job: %w", err)
+	}
+	if err := os.RemoveAll(filepath.Join(service.outputDir, record.ID)); err != nil {
+		logrus.WithContext(ctx).Warnf("Failed to delete the output of job %s: %v", record.ID, err)
+	}
+	return response, nil
+}
+
+// InterruptJobs marks the jobs a previous run left queued or running as
+// interrupted. Call it before the first job starts.
+func (service *serviceJob) InterruptJobs(ctx context.Context) error {
+	interrupted, err := service.chatStorageRepo.InterruptJobs(ctx, jobInterruptedReason)
+	if err != nil {
+		return fmt.Errorf("failed to mark unfinished jobs interrupted: %w", err)
+	}
+	if interrupted > 0 {
+		logrus.Warnf("Marked %d job(s) left unfinished by the last run as interrupted", interrupted)
+	}
+	return nil
+}
+
+// Stop cancels the jobs in progress, which end up interrupted, and waits
+// for them to stop.
+func (service *serviceJob) Stop(ctx context.Context) error {
+	service.mu.Lock()
+	for _, job := range service.active {
+		job.cancel(errJobShutdown)
+	}
+	service.mu.Unlock()
+
+	if err := service.pending.Wait(ctx); err != nil {
+		return fmt.Errorf("%d job(s) still running: %w", service.pending.Count(), err)
+	}
+	return nil
+}
+
+func (service *serviceJob) work() {
+	for job := range service.queue {
+		service.runJob(job)
+	}
+}
+
+func (service *serviceJob) runJob(job *backgroundJob) {
+	if job.ctx.Err() != nil {
+		service.finish(job, nil, nil)
+		return
+	}
+	service.update(job, func(record *domainChatStorage.Job) bool {
+		record.Status = domainChatStorage.JobStatusRunning
+		return true
+	})
+	result, err := runSafely(job.ctx, job.run, jobTask{service: service, job: job})
+	service.finish(job, result, err)
+}
+
+// runSafely turns a panic of run, e.g. of a usecase asserting the device is
+// logged in, into an error of the job.
+func runSafely(ctx context.Context, run domainJob.Run, task domainJob.Task) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return run(ctx, task)
+}
+
+// finish records how job ended. A job whose context was canceled ends
+// canceled or interrupted, whatever it returned; only succeeded jobs keep
+// their output.
+func (service *serviceJob) finish(job *backgroundJob, result any, err error) {
+	defer service.pending.Done()
+
+	service.update(job, func(record *domainChatStorage.Job) bool {
+		cause := context.Cause(job.ctx)
+		switch {
+		case errors.Is(cause, errJobCanceled):
+			record.Status, record.Error = domainChatStorage.JobStatusCanceled, cause.Error()
+		case errors.Is(cause, errJobShutdown):
+			record.Status, record.Error = domainChatStorage.JobStatusInterrupted, cause.Error()
+		case err != nil:
+			record.Status, record.Error = domainChatStorage.JobStatusFailed, err.Error()
+		default:
+			encoded, err := json.Marshal(result)
+			if err != nil {
+				record.Status, record.Error = domainChatStorage.JobStatusFailed, fmt.Sprintf("failed to encode the result: %v", err)
+				break
+			}
+			record.Status, record.Progress, record.Result = domainChatStorage.JobStatusSucceeded, 100, string(encoded)
+		}
+		return true
+	})
+	if job.record.Status != domainChatStorage.JobStatusSucceeded {
+		if err := os.RemoveAll(filepath.Join(service.outputDir, job.record.ID)); err != nil {
+			logrus.WithContext(job.ctx).Warnf("Failed to delete the output of job %s: %v", job.record.ID, err)
+		}
+	}
+	logrus.WithContext(job.ctx).Infof("Job %s (%s) %s", job.record.ID, job.record.Type, job.record.Status)
+
+	service.mu.Lock()
+	delete(service.active, job.record.ID)
+	service.mu.Unlock()
+	job.cancel(nil)
+	close(job.done)
+}
+
+// update changes the record of job and stores it when change reports a
+// change. Records are stored even once the job's context ended.
+func (service *serviceJob) update(job *backgroundJob, change func(*domainChatStorage.Job) bool) {
+	service.mu.Lock()
+	if !change(&job.record) {
+		service.mu.Unlock()
+		return
+	}
+	job.record.UpdatedAt = time.Now()
+	record := job.record
+	service.mu.Unlock()
+
+	if err := service.chatStorageRepo.StoreJob(context.WithoutCancel(job.ctx), &record); err != nil {
+		logrus.WithContext(job.ctx).Errorf("Failed to store job %s: %v", record.ID, err)
+	}
+}
+
+func (service *serviceJob) loadJob(ctx context.Context, id string) (*domainChatStorage.Job, error) {
+	record, err := service.chatStorageRepo.GetJob(ctx, deviceIDFromContext(ctx), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	if record == nil {
+		return nil, pkgError.NotFoundError(fmt.Sprintf("job %s not found", id))
+	}
+	return record, nil
+}
+
+// current returns the state of the job in memory while it is queued or
+// running, as its progress isn't stored until it finished.
+func (service *serviceJob) current(record *domainChatStorage.Job) *domainChatStorage.Job {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	if job, ok := service.active[record.ID]; ok && job.record.DeviceID == record.DeviceID {
+		active := job.record
+		return &active
+	}
+	return record
+}
+
+func (service *serviceJob) jobFromRecord(record *domainChatStorage.Job) domainJob.Job {
+	job := domainJob.Job{
+		ID:        record.ID,
+		Type:      record.Type,
+		Status:    record.Status,
+		Progress:  record.Progress,
+		Error:     record.Error,
+		CreatedAt: record.CreatedAt,
+		UpdatedAt: record.UpdatedAt,
+	}
+	if record.Result != "" {
+		job.Result = json.RawMessage(record.Result)
+	}
+	if record.Status == domainChatStorage.JobStatusSucceeded {
+		_, err := os.Stat(filepath.Join(service.outputDir, record.ID))
+		job.HasOutput = err == nil
+	}
+	return job
+}
+
+// jobTask is the domainJob.Task of a running job.
+type jobTask struct {
+	service *serviceJob
+	job     *backgroundJob
+}
+
+// SetProgress records the percent done, below 100 until the job succeeded.
+// It is only kept in memory until the job finishes: jobs report progress
+// while reading from chat storage, which may not have a connection to spare.
+func (task jobTask) SetProgress(percent int) {
+	percent = min(max(percent, 0), 99)
+	task.service.mu.Lock()
+	defer task.service.mu.Unlock()
+	if task.job.record.Progress != percent {
+		task.job.record.Progress = percent
+		task.job.record.UpdatedAt = time.Now()
+	}
+}
+
+func (task jobTask) CreateOutput(name string) (io.WriteCloser, error) {
+	dir := filepath.Join(task.service.outputDir, task.job.record.ID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return os.Create(filepath.Join(dir, filepath.Base(name)))
+}
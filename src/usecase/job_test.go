@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainJob "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/job"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitForJob waits for the job to finish and returns it.
+func waitForJob(t *testing.T, service domainJob.IJobUsecase, ctx context.Context, id string) domainJob.Job {
+	t.Helper()
+	var job domainJob.Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = service.GetJob(ctx, id)
+		require.NoError(t, err)
+		return job.Status != domainChatStorage.JobStatusQueued && job.Status != domainChatStorage.JobStatusRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestJobs(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+
+	ctx := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-1", nil, nil))
+	service := newJobService(repo, 2, t.TempDir())
+
+	t.Run("records the result and output", func(t *testing.T) {
+		started, err := service.Start(ctx, domainJob.TypeChatExport, func(ctx context.Context, task domainJob.Task) (any, error) {
+			task.SetProgress(50)
+			output, err := task.CreateOutput("../export.jsonl")
+			if err != nil {
+				return nil, err
+			}
+			if _, err := output.Write([]byte("{}\n")); err != nil {
+				return nil, err
+			}
+			return map[string]int{"messages": 1}, output.Close()
+		})
+		require.NoError(t, err)
+		assert.Equal(t, domainChatStorage.JobStatusQueued, started.Status)
+
+		job := waitForJob(t, service, ctx, started.ID)
+		assert.Equal(t, domainChatStorage.JobStatusSucceeded, job.Status)
+		assert.Equal(t, 100, job.Progress)
+		assert.JSONEq(t, `{"messages":1}`, string(job.Result))
+		assert.True(t, job.HasOutput)
+
+		output, err := service.GetJobOutput(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "export.jsonl", output.Name, "outputs stay in the job's folder")
+		content, err := os.ReadFile(output.Path)
+		require.NoError(t, err)
+		assert.Equal(t, "{}\n", string(content))
+
+		other := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-2", nil, nil))
+		_, err = service.GetJob(other, job.ID)
+		assert.IsType(t, pkgError.NotFoundError(""), err, "jobs belong to a device")
+
+		// Deleting a finished job removes its output too
+		_, err = service.CancelJob(ctx, job.ID)
+		require.NoError(t, err)
+		_, err = service.GetJob(ctx, job.ID)
+		assert.IsType(t, pkgError.NotFoundError(""), err)
+		_, err = os.Stat(output.Path)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("records failures and panics", func(t *testing.T) {
+		failed, err := service.Start(ctx, domainJob.TypeContactSync, func(context.Context, domainJob.Task) (any, error) {
+			return nil, errors.New("not logged in")
+		})
+		require.NoError(t, err)
+		panicked, err := service.Start(ctx, domainJob.TypeContactSync, func(context.Context, domainJob.Task) (any, error) {
+			panic("no client")
+		})
+		require.NoError(t, err)
+
+		job := waitForJob(t, service, ctx, failed.ID)
+		assert.Equal(t, domainChatStorage.JobStatusFailed, job.Status)
+		assert.Equal(t, "not logged in", job.Error)
+		job = waitForJob(t, service, ctx, panicked.ID)
+		assert.Equal(t, domainChatStorage.JobStatusFailed, job.Status)
+		assert.Equal(t, "no client", job.Error)
+
+		_, err = service.GetJobOutput(ctx, failed.ID)
+		assert.IsType(t, pkgError.ValidationError(""), err)
+	})
+
+	t.Run("cancels running jobs", func(t *testing.T) {
+		running := make(chan struct{})
+		started, err := service.Start(ctx, domainJob.TypeChatExport, func(ctx context.Context, task domainJob.Task) (any, error) {
+			output, err := task.CreateOutput("partial.jsonl")
+			if err != nil {
+				return nil, err
+			}
+			defer output.Close()
+			task.SetProgress(30)
+			close(running)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		require.NoError(t, err)
+		<-running
+
+		job, err := service.GetJob(ctx, started.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domainChatStorage.JobStatusRunning, job.Status)
+		assert.Equal(t, 30, job.Progress, "progress is reported while running")
+
+		job, err = service.CancelJob(ctx, started.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domainChatStorage.JobStatusCanceled, job.Status)
+		assert.False(t, job.HasOutput)
+		_, err = os.Stat(filepath.Join(service.outputDir, started.ID))
+		assert.True(t, os.IsNotExist(err), "partial outputs are removed")
+	})
+
+	t.Run("lists the newest jobs first", func(t *testing.T) {
+		jobs, err := service.ListJobs(ctx, domainJob.ListJobsRequest{Type: domainJob.TypeContactSync})
+		require.NoError(t, err)
+		require.Len(t, jobs, 2)
+		for _, job := range jobs {
+			assert.Equal(t, domainJob.TypeContactSync, job.Type)
+		}
+
+		_, err = service.ListJobs(ctx, domainJob.ListJobsRequest{Status: "done"})
+		assert.IsType(t, pkgError.ValidationError(""), err)
+	})
+
+	t.Run("interrupts jobs at shutdown", func(t *testing.T) {
+		running := make(chan struct{})
+		started, err := service.Start(ctx, domainJob.TypeContactSync, func(ctx context.Context, _ domainJob.Task) (any, error) {
+			close(running)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		require.NoError(t, err)
+		<-running
+
+		stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		require.NoError(t, service.Stop(stopCtx))
+		job, err := service.GetJob(ctx, started.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domainChatStorage.JobStatusInterrupted, job.Status)
+	})
+
+	t.Run("marks jobs of a previous run interrupted", func(t *testing.T) {
+		now := time.Now()
+		require.NoError(t, repo.StoreJob(ctx, &domainChatStorage.Job{
+			ID: "left-over", DeviceID: "dev-1", Type: domainJob.TypeChatExport,
+			Status: domainChatStorage.JobStatusRunning, Progress: 40, CreatedAt: now, UpdatedAt: now,
+		}))
+		require.NoError(t, service.InterruptJobs(ctx))
+
+		job, err := service.GetJob(ctx, "left-over")
+		require.NoError(t, err)
+		assert.Equal(t, domainChatStorage.JobStatusInterrupted, job.Status)
+		assert.Equal(t, jobInterruptedReason, job.Error)
+		assert.Empty(t, job.Result)
+	})
+}
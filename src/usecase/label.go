@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainLabel "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/label"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
+	"github.com/google/uuid"
+)
+
+type serviceLabel struct {
+	chatStorageRepo domainChatStorage.IChatStorageRepository
+}
+
+func NewLabelService(chatStorageRepo domainChatStorage.IChatStorageRepository) domainLabel.ILabelUsecase {
+	return &serviceLabel{
+		chatStorageRepo: chatStorageRepo,
+	}
+}
+
+func (service serviceLabel) CreateLabel(ctx context.Context, request domainLabel.CreateLabelRequest) (domainLabel.Label, error) {
+	deviceID := deviceIDFromContext(ctx)
+	now := time.Now()
+	label := domainLabel.Label{
+		ID:        uuid.NewString(),
+		Name:      strings.TrimSpace(request.Name),
+		Color:     request.Color,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := service.validate(ctx, deviceID, label); err != nil {
+		return label, err
+	}
+	if err := service.store(ctx, deviceID, label); err != nil {
+		return label, err
+	}
+	return label, nil
+}
+
+// ListLabels returns the labels of the device by name.
+func (service serviceLabel) ListLabels(ctx context.Context) ([]domainLabel.Label, error) {
+	records, err := service.chatStorageRepo.ListLabels(ctx, deviceIDFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list labels: %w", err)
+	}
+	labels := make([]domainLabel.Label, 0, len(records))
+	for _, record := range records {
+		labels = append(labels, labelFromRecord(record))
+	}
+	return labels, nil
+}
+
+func (service serviceLabel) GetLabel(ctx context.Context, id string) (domainLabel.Label, error) {
+	deviceID := deviceIDFromContext(ctx)
+	records, err := service.chatStorageRepo.ListLabels(ctx, deviceID)
+	if err != nil {
+		return domainLabel.Label{}, fmt.Errorf("failed to get label: %w", err)
+	}
+	// Listing is what counts the chats of a label
+	for _, record := range records {
+		if record.ID == id {
+			return labelFromRecord(record), nil
+		}
+	}
+	return domainLabel.Label{}, pkgError.NotFoundError(fmt.Sprintf("label %s not found", id))
+}
+
+func (service serviceLabel) UpdateLabel(ctx context.Context, request domainLabel.UpdateLabelRequest) (domainLabel.Label, error) {
+	label, err := service.GetLabel(ctx, request.ID)
+	if err != nil {
+		return label, err
+	}
+	if request.Name != nil {
+		label.Name = strings.TrimSpace(*request.Name)
+	}
+	if request.Color != nil {
+		label.Color = *request.Color
+	}
+	label.UpdatedAt = time.Now()
+
+	deviceID := deviceIDFromContext(ctx)
+	if err := service.validate(ctx, deviceID, label); err != nil {
+		return label, err
+	}
+	if err := service.store(ctx, deviceID, label); err != nil {
+		return label, err
+	}
+	return label, nil
+}
+
+// DeleteLabel deletes a label and removes it from the chats it tags.
+func (service serviceLabel) DeleteLabel(ctx context.Context, id string) error {
+	found, err := service.chatStorageRepo.DeleteLabel(ctx, deviceIDFromContext(ctx), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete label: %w", err)
+	}
+	if !found {
+		return pkgError.NotFoundError(fmt.Sprintf("label %s not found", id))
+	}
+	return nil
+}
+
+func (service serviceLabel) SetChatLabels(ctx context.Context, request domainLabel.SetChatLabelsRequest) (response domainLabel.SetChatLabelsResponse, err error) {
+	if err = validations.ValidateSetChatLabels(ctx, &request); err != nil {
+		return response, err
+	}
+	deviceID := deviceIDFromContext(ctx)
+
+	chat, err := service.chatStorageRepo.GetChatByDevice(ctx, deviceID, request.ChatJID)
+	if err != nil {
+		return response, fmt.Errorf("failed to get chat: %w", err)
+	}
+	if chat == nil {
+		return response, pkgError.NotFoundError(fmt.Sprintf("chat %s not found", request.ChatJID))
+	}
+
+	var labelIDs []string
+	for _, id := range request.LabelIDs {
+		if slices.Contains(labelIDs, id) {
+			continue
+		}
+		label, err := service.chatStorageRepo.GetLabel(ctx, deviceID, id)
+		if err != nil {
+			return response, fmt.Errorf("failed to get label: %w", err)
+		}
+		if label == nil {
+			return response, pkgError.NotFoundError(fmt.Sprintf("label %s not found", id))
+		}
+		labelIDs = append(labelIDs, id)
+	}
+
+	if err := service.chatStorageRepo.SetChatLabels(ctx, deviceID, chat.JID, labelIDs); err != nil {
+		return response, fmt.Errorf("failed to store chat labels: %w", err)
+	}
+	labels, err := service.chatStorageRepo.ListLabels(ctx, deviceID)
+	if err != nil {
+		return response, fmt.Errorf("failed to list labels: %w", err)
+	}
+
+	response.ChatJID = chat.JID
+	response.Labels = make([]domainLabel.Label, 0, len(labelIDs))
+	for _, record := range labels {
+		if slices.Contains(labelIDs, record.ID) {
+			response.Labels = append(response.Labels, labelFromRecord(record))
+		}
+	}
+	return response, nil
+}
+
+// validate checks label and that no other label of the device has its name.
+func (service serviceLabel) validate(ctx context.Context, deviceID string, label domainLabel.Label) error {
+	if err := validations.ValidateLabel(ctx, label); err != nil {
+		return err
+	}
+	existing, err := service.chatStorageRepo.ListLabels(ctx, deviceID)
+	if err != nil {
+		return fmt.Errorf("failed to list labels: %w", err)
+	}
+	for _, other := range existing {
+		if other.ID != label.ID && strings.EqualFold(other.Name, label.Name) {
+			return pkgError.ValidationError(fmt.Sprintf("name: a label named %q already exists", other.Name))
+		}
+	}
+	return nil
+}
+
+func (service serviceLabel) store(ctx context.Context, deviceID string, label domainLabel.Label) error {
+	err := service.chatStorageRepo.StoreLabel(ctx, &domainChatStorage.Label{
+		ID:        label.ID,
+		DeviceID:  deviceID,
+		Name:      label.Name,
+		Color:     label.Color,
+		CreatedAt: label.CreatedAt,
+		UpdatedAt: label.UpdatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store label: %w", err)
+	}
+	return nil
+}
+
+func labelFromRecord(record *domainChatStorage.Label) domainLabel.Label {
+	return domainLabel.Label{
+		ID:        record.ID,
+		Name:      record.Name,
+		Color:     record.Color,
+		ChatCount: record.ChatCount,
+		CreatedAt: record.CreatedAt,
+		UpdatedAt: record.UpdatedAt,
+	}
+}
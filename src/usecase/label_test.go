@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	domainChat "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chat"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainLabel "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/label"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelLifecycle(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+	for _, jid := range []string{"628111@s.whatsapp.net", "628222@s.whatsapp.net"} {
+		require.NoError(t, repo.StoreChat(context.Background(), &domainChatStorage.Chat{DeviceID: "dev-1", JID: jid, Name: jid, LastMessageTime: time.Now()}))
+	}
+
+	ctx := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-1", nil, nil))
+	service := serviceLabel{chatStorageRepo: repo}
+	chats := serviceChat{chatStorageRepo: repo}
+
+	support, err := service.CreateLabel(ctx, domainLabel.CreateLabelRequest{Name: " support ", Color: "#25D366"})
+	require.NoError(t, err)
+	assert.Equal(t, "support", support.Name)
+	vip, err := service.CreateLabel(ctx, domainLabel.CreateLabelRequest{Name: "VIP"})
+	require.NoError(t, err)
+
+	_, err = service.CreateLabel(ctx, domainLabel.CreateLabelRequest{Name: "Support"})
+	assert.IsType(t, pkgError.ValidationError(""), err, "names are unique ignoring case")
+
+	set, err := service.SetChatLabels(ctx, domainLabel.SetChatLabelsRequest{ChatJID: "628111@s.whatsapp.net", LabelIDs: []string{vip.ID, support.ID, vip.ID}})
+	require.NoError(t, err)
+	require.Len(t, set.Labels, 2)
+	assert.Equal(t, "support", set.Labels[0].Name)
+	assert.Equal(t, 1, set.Labels[0].ChatCount)
+
+	_, err = service.SetChatLabels(ctx, domainLabel.SetChatLabelsRequest{ChatJID: "628999@s.whatsapp.net", LabelIDs: []string{vip.ID}})
+	assert.IsType(t, pkgError.NotFoundError(""), err, "only stored chats can be labeled")
+	_, err = service.SetChatLabels(ctx, domainLabel.SetChatLabelsRequest{ChatJID: "628222@s.whatsapp.net", LabelIDs: []string{"missing"}})
+	assert.IsType(t, pkgError.NotFoundError(""), err)
+
+	listed, err := chats.ListChats(ctx, domainChat.ListChatsRequest{Limit: 10, Label: "SUPPORT"})
+	require.NoError(t, err)
+	require.Len(t, listed.Data, 1)
+	assert.Equal(t, "628111@s.whatsapp.net", listed.Data[0].JID)
+	assert.Equal(t, []domainChat.ChatLabel{{ID: support.ID, Name: "support", Color: "#25D366"}, {ID: vip.ID, Name: "VIP"}}, listed.Data[0].Labels)
+
+	listed, err = chats.ListChats(ctx, domainChat.ListChatsRequest{Limit: 10, Label: "unknown"})
+	require.NoError(t, err)
+	assert.Empty(t, listed.Data)
+
+	renamed := "priority"
+	updated, err := service.UpdateLabel(ctx, domainLabel.UpdateLabelRequest{ID: vip.ID, Name: &renamed})
+	require.NoError(t, err)
+	assert.Equal(t, "priority", updated.Name)
+	assert.Equal(t, 1, updated.ChatCount)
+
+	require.NoError(t, service.DeleteLabel(ctx, support.ID))
+	assert.IsType(t, pkgError.NotFoundError(""), service.DeleteLabel(ctx, support.ID))
+	listed, err = chats.ListChats(ctx, domainChat.ListChatsRequest{Limit: 10})
+	require.NoError(t, err)
+	for _, chat := range listed.Data {
+		for _, label := range chat.Labels {
+			assert.NotEqual(t, support.ID, label.ID, "deleted labels are removed from chats")
+		}
+	}
+
+	other := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-2", nil, nil))
+	_, err = service.GetLabel(other, vip.ID)
+	assert.IsType(t, pkgError.NotFoundError(""), err, "labels belong to one device")
+}
@@ -15,7 +15,6 @@ import (
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
 	"github.com/sirupsen/logrus"
-	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/appstate"
 	"go.mau.fi/whatsmeow/proto/waCommon"
 	"go.mau.fi/whatsmeow/proto/waE2E"
@@ -24,6 +23,10 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
+// messageRevokeWindow is how long after sending a message WhatsApp still
+// lets it be deleted for everyone.
+const messageRevokeWindow = 60 * time.Hour
+
 type serviceMessage struct {
 	chatStorageRepo domainChatStorage.IChatStorageRepository
 }
@@ -54,7 +57,12 @@ func (service serviceMessage) MarkAsRead(ctx context.Context, request domainMess
 		return response, err
 	}
 
-	logrus.Info(map[string]any{
+	chatJID := whatsapp.NormalizeJIDFromLID(ctx, dataWaRecipient, client).String()
+	if err := service.chatStorageRepo.MarkChatRead(ctx, deviceIDFromContext(ctx), chatJID); err != nil {
+		logrus.WithError(err).WithField("chat_jid", chatJID).Warn("Failed to reset unread count")
+	}
+
+	logrus.WithContext(ctx).Info(map[string]any{
 		"phone":      request.Phone,
 		"message_id": request.MessageID,
 		"chat":       dataWaRecipient.String(),
@@ -81,40 +89,77 @@ func (service serviceMessage) ReactMessage(ctx context.Context, request domainMe
 		return response, err
 	}
 
-	// FromMe in reaction refers to whether the ORIGINAL message (being reacted to) was sent by us
-	isFromMe := true
-	message, err := service.chatStorageRepo.GetMessageByID(request.MessageID)
-	if err != nil {
-		logrus.Warnf("Failed to lookup message %s for reaction: %v, using fallback heuristic", request.MessageID, err)
-		isFromMe = len(request.MessageID) <= 22
-	} else if message != nil {
-		isFromMe = message.IsFromMe
-	} else {
-		logrus.Debugf("Message %s not found in database, using ID length heuristic for FromMe", request.MessageID)
-		isFromMe = len(request.MessageID) <= 22
-	}
-
+	chat, key := service.reactionKey(ctx, dataWaRecipient, request.MessageID)
 	msg := &waE2E.Message{
 		ReactionMessage: &waE2E.ReactionMessage{
-			Key: &waCommon.MessageKey{
-				FromMe:    proto.Bool(isFromMe),
-				ID:        proto.String(request.MessageID),
-				RemoteJID: proto.String(dataWaRecipient.String()),
-			},
+			Key:               key,
 			Text:              proto.String(request.Emoji),
 			SenderTimestampMS: proto.Int64(time.Now().UnixMilli()),
 		},
 	}
-	ts, err := client.SendMessage(ctx, dataWaRecipient, msg)
+	ts, err := client.SendMessage(ctx, chat, msg)
 	if err != nil {
 		return response, err
 	}
 
+	// Our own reactions don't come back as events, so record them here
+	if client.Store != nil && client.Store.ID != nil {
+		reaction := &domainChatStorage.Reaction{
+			MessageID: request.MessageID,
+			ChatJID:   chat.String(),
+			DeviceID:  deviceIDFromContext(ctx),
+			Sender:    client.Store.ID.ToNonAD().String(),
+			Emoji:     request.Emoji,
+			Timestamp: ts.Timestamp,
+		}
+		if err := service.chatStorageRepo.StoreReaction(ctx, reaction); err != nil {
+			logrus.WithContext(ctx).Warnf("Failed to store reaction to message %s: %v", request.MessageID, err)
+		}
+	}
+
 	response.MessageID = ts.ID
 	response.Status = fmt.Sprintf("Reaction sent to %s (server timestamp: %s)", request.Phone, ts.Timestamp)
 	return response, nil
 }
 
+// reactionKey returns the chat to react in and the key of message id, taken
+// from chat storage when the message is stored.
+func (service serviceMessage) reactionKey(ctx context.Context, chat types.JID, id string) (types.JID, *waCommon.MessageKey) {
+	key := &waCommon.MessageKey{ID: proto.String(id)}
+
+	message, err := service.chatStorageRepo.GetMessageByDevice(ctx, deviceIDFromContext(ctx), id)
+	if err != nil {
+		logrus.WithContext(ctx).Warnf("Failed to lookup message %s for reaction: %v, using fallback heuristic", id, err)
+		message = nil
+	}
+	if message != nil && sameChat(message.ChatJID, chat) {
+		return storedMessageKey(chat, message)
+	}
+	// IDs of messages sent from this API or WhatsApp Web are short
+	key.FromMe = proto.Bool(len(id) <= 22)
+	key.RemoteJID = proto.String(chat.String())
+	return chat, key
+}
+
+// storedMessageKey returns the chat a stored message belongs to and its key,
+// with the sender as the participant of group messages someone else sent.
+func storedMessageKey(chat types.JID, message *domainChatStorage.Message) (types.JID, *waCommon.MessageKey) {
+	if storedChat, err := types.ParseJID(message.ChatJID); err == nil {
+		chat = storedChat
+	}
+	key := &waCommon.MessageKey{
+		ID:        proto.String(message.ID),
+		FromMe:    proto.Bool(message.IsFromMe),
+		RemoteJID: proto.String(chat.String()),
+	}
+	if !message.IsFromMe && chat.Server == types.GroupServer {
+		if sender, err := types.ParseJID(message.Sender); err == nil && !sender.IsEmpty() {
+			key.Participant = proto.String(sender.ToNonAD().String())
+		}
+	}
+	return chat, key
+}
+
 func (service serviceMessage) RevokeMessage(ctx context.Context, request domainMessage.RevokeRequest) (response domainMessage.GenericResponse, err error) {
 	if err = validations.ValidateRevokeMessage(ctx, request); err != nil {
 		return response, err
@@ -130,16 +175,74 @@ func (service serviceMessage) RevokeMessage(ctx context.Context, request domainM
 		return response, err
 	}
 
-	ts, err := client.SendMessage(ctx, dataWaRecipient, client.BuildRevoke(dataWaRecipient, types.EmptyJID, request.MessageID))
+	chat, sender, err := service.revokeTarget(ctx, dataWaRecipient, request.MessageID, request.Participant, time.Now())
+	if err != nil {
+		return response, err
+	}
+
+	ts, err := client.SendMessage(ctx, chat, client.BuildRevoke(chat, sender, request.MessageID))
 	if err != nil {
 		return response, err
 	}
 
+	// Our own revocations don't come back as events, so record them here
+	if err := service.chatStorageRepo.MarkMessageRevoked(ctx, deviceIDFromContext(ctx), request.MessageID, chat.String(), config.ChatStorageKeepRevoked); err != nil {
+		logrus.WithContext(ctx).Warnf("Failed to mark message %s as revoked: %v", request.MessageID, err)
+	}
+
 	response.MessageID = ts.ID
 	response.Status = fmt.Sprintf("Revoke success %s (server timestamp: %s)", request.Phone, ts.Timestamp)
 	return response, nil
 }
 
+// revokeTarget works out the chat and sender to revoke message id with. A
+// stored message is revoked in the chat it was stored under, and someone
+// else's group message as an admin; participant names that sender when the
+// message isn't stored. Unknown messages are sent as given and left to
+// WhatsApp to refuse.
+func (service serviceMessage) revokeTarget(ctx context.Context, chat types.JID, id, participant string, now time.Time) (types.JID, types.JID, error) {
+	sender := types.EmptyJID
+	if participant != "" {
+		if chat.Server != types.GroupServer {
+			return chat, sender, pkgError.ValidationError("participant can only be set when revoking a group message")
+		}
+		jid, err := utils.ParseJID(participant)
+		if err != nil {
+			return chat, sender, pkgError.ValidationError(fmt.Sprintf("participant: %v", err))
+		}
+		sender = jid.ToNonAD()
+	}
+
+	message, err := service.chatStorageRepo.GetMessageByDevice(ctx, deviceIDFromContext(ctx), id)
+	if err != nil {
+		return chat, sender, fmt.Errorf("failed to look up message %s: %w", id, err)
+	}
+	if message == nil || !sameChat(message.ChatJID, chat) {
+		return chat, sender, nil
+	}
+	if now.Sub(message.Timestamp) > messageRevokeWindow {
+		return chat, sender, pkgError.RevokeWindowExpiredError(fmt.Sprintf("message %s was sent more than %s ago and can no longer be deleted for everyone", id, messageRevokeWindow))
+	}
+	if storedChat, err := types.ParseJID(message.ChatJID); err == nil {
+		chat = storedChat
+	}
+
+	if message.IsFromMe {
+		return chat, types.EmptyJID, nil
+	}
+	if chat.Server != types.GroupServer {
+		return chat, sender, pkgError.ValidationError("only your own messages can be deleted for everyone in a direct chat")
+	}
+	if sender.IsEmpty() {
+		jid, err := types.ParseJID(message.Sender)
+		if err != nil || jid.IsEmpty() {
+			return chat, sender, pkgError.ValidationError(fmt.Sprintf("sender of message %s is unknown, set participant", id))
+		}
+		sender = jid.ToNonAD()
+	}
+	return chat, sender, nil
+}
+
 func (service serviceMessage) DeleteMessage(ctx context.Context, request domainMessage.DeleteRequest) (err error) {
 	if err = validations.ValidateDeleteMessage(ctx, request); err != nil {
 		return err
@@ -195,17 +298,116 @@ func (service serviceMessage) UpdateMessage(ctx context.Context, request domainM
 		return response, err
 	}
 
+	original, err := service.editableMessage(ctx, dataWaRecipient, request.MessageID, time.Now())
+	if err != nil {
+		return response, err
+	}
+
 	msg := &waE2E.Message{Conversation: proto.String(request.Message)}
 	ts, err := client.SendMessage(ctx, dataWaRecipient, client.BuildEdit(dataWaRecipient, request.MessageID, msg))
 	if err != nil {
 		return response, err
 	}
 
+	// Our own edits don't come back as events, so record and report them here
+	if err := service.chatStorageRepo.StoreMessageEdit(ctx, deviceIDFromContext(ctx), request.MessageID, original.ChatJID, request.Message, ts.Timestamp); err != nil {
+		logrus.WithContext(ctx).Warnf("Failed to store edit of message %s: %v", request.MessageID, err)
+	}
+	whatsapp.ForwardSentEditToWebhook(client, dataWaRecipient, ts.ID, request.MessageID, request.Message, ts.Timestamp)
+
 	response.MessageID = ts.ID
 	response.Status = fmt.Sprintf("Update message success %s (server timestamp: %s)", request.Phone, ts.Timestamp)
 	return response, nil
 }
 
+// editableMessage returns the stored message id sent to chat, provided it
+// was sent by us no longer than config.WhatsappMessageEditWindow before now.
+func (service serviceMessage) editableMessage(ctx context.Context, chat types.JID, id string, now time.Time) (*domainChatStorage.Message, error) {
+	message, err := service.chatStorageRepo.GetMessageByDevice(ctx, deviceIDFromContext(ctx), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up message %s: %w", id, err)
+	}
+	if message == nil || !sameChat(message.ChatJID, chat) {
+		return nil, pkgError.NotFoundError(fmt.Sprintf("message %s not found in chat %s", id, chat))
+	}
+	if !message.IsFromMe {
+		return nil, pkgError.ValidationError("only messages sent by this device can be edited")
+	}
+	if window := config.WhatsappMessageEditWindow; window > 0 && now.Sub(message.Timestamp) > window {
+		return nil, pkgError.EditWindowExpiredError(fmt.Sprintf("message %s was sent more than %s ago and can no longer be edited", id, window))
+	}
+	return message, nil
+}
+
+// PinMessage implements message.IMessageService.
+func (service serviceMessage) PinMessage(ctx context.Context, request domainMessage.PinRequest) (response domainMessage.GenericResponse, err error) {
+	if err = validations.ValidatePinMessage(ctx, request); err != nil {
+		return response, err
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+
+	dataWaRecipient, err := utils.ValidateJidWithLogin(client, request.Phone)
+	if err != nil {
+		return response, err
+	}
+
+	message, err := service.chatStorageRepo.GetMessageByDevice(ctx, deviceIDFromContext(ctx), request.MessageID)
+	if err != nil {
+		return response, fmt.Errorf("failed to look up message %s: %w", request.MessageID, err)
+	}
+	if message == nil || !sameChat(message.ChatJID, dataWaRecipient) {
+		return response, pkgError.NotFoundError(fmt.Sprintf("message %s not found in chat %s", request.MessageID, dataWaRecipient))
+	}
+
+	var duration time.Duration
+	if request.IsPinned {
+		duration = domainMessage.PinDurations[request.Duration]
+	}
+	chat, key := storedMessageKey(dataWaRecipient, message)
+	ts, err := client.SendMessage(ctx, chat, buildPinMessage(key, duration))
+	if err != nil {
+		return response, err
+	}
+
+	// Our own pins don't come back as events, so record them here
+	var pinnedUntil *time.Time
+	if duration > 0 {
+		until := ts.Timestamp.Add(duration)
+		pinnedUntil = &until
+	}
+	if err := service.chatStorageRepo.SetMessagePinnedUntil(ctx, deviceIDFromContext(ctx), message.ID, message.ChatJID, pinnedUntil); err != nil {
+		logrus.WithContext(ctx).Warnf("Failed to store pin of message %s: %v", request.MessageID, err)
+	}
+
+	response.MessageID = ts.ID
+	if pinnedUntil != nil {
+		response.Status = fmt.Sprintf("Pinned message %s until %s", request.MessageID, pinnedUntil.Format(time.RFC3339))
+	} else {
+		response.Status = fmt.Sprintf("Unpinned message %s", request.MessageID)
+	}
+	return response, nil
+}
+
+// buildPinMessage pins the message with key for everyone in its chat for
+// duration, or unpins it when duration is 0.
+func buildPinMessage(key *waCommon.MessageKey, duration time.Duration) *waE2E.Message {
+	pin := &waE2E.PinInChatMessage{
+		Key:               key,
+		Type:              waE2E.PinInChatMessage_UNPIN_FOR_ALL.Enum(),
+		SenderTimestampMS: proto.Int64(time.Now().UnixMilli()),
+	}
+	msg := &waE2E.Message{PinInChatMessage: pin}
+	if duration > 0 {
+		pin.Type = waE2E.PinInChatMessage_PIN_FOR_ALL.Enum()
+		msg.MessageContextInfo = &waE2E.MessageContextInfo{MessageAddOnDurationInSecs: proto.Uint32(uint32(duration.Seconds()))}
+	}
+	return msg
+}
+
 // StarMessage implements message.IMessageService.
 func (service serviceMessage) StarMessage(ctx context.Context, request domainMessage.StarRequest) (err error) {
 	if err = validations.ValidateStarMessage(ctx, request); err != nil {
@@ -235,7 +437,192 @@ func (service serviceMessage) StarMessage(ctx context.Context, request domainMes
 	return nil
 }
 
+// GetReceipts implements message.IMessageService.
+// GetMessage returns a stored message of the device, with the request that
+// sent it when it was sent through the API.
+func (service serviceMessage) GetMessage(ctx context.Context, request domainMessage.GetMessageRequest) (response domainMessage.GetMessageResponse, err error) {
+	if err = validations.ValidateGetMessage(ctx, request); err != nil {
+		return response, err
+	}
+
+	deviceID := deviceIDFromContext(ctx)
+	if deviceID == "" {
+		return response, fmt.Errorf("device identification required")
+	}
+
+	message, err := service.chatStorageRepo.GetMessageByDevice(ctx, deviceID, request.MessageID)
+	if err != nil {
+		return response, fmt.Errorf("failed to look up message %s: %w", request.MessageID, err)
+	}
+	if message == nil {
+		return response, pkgError.NotFoundError(fmt.Sprintf("message %s not found", request.MessageID))
+	}
+
+	return domainMessage.GetMessageResponse{
+		ID:           message.ID,
+		ChatJID:      message.ChatJID,
+		SenderJID:    message.Sender,
+		Content:      message.Content,
+		Timestamp:    message.Timestamp.Format(time.RFC3339),
+		IsFromMe:     message.IsFromMe,
+		MediaType:    message.MediaType,
+		Filename:     message.Filename,
+		EditedAt:     formatEditedAt(message.EditedAt),
+		IsDeleted:    message.IsDeleted,
+		ReplyToID:    message.ReplyToID,
+		RequestID:    message.RequestID,
+		TemplateName: message.TemplateName,
+	}, nil
+}
+
+// GetReceipts implements message.IMessageService.
+func (service serviceMessage) GetReceipts(ctx context.Context, request domainMessage.GetReceiptsRequest) (response domainMessage.GetReceiptsResponse, err error) {
+	if err = validations.ValidateGetReceipts(ctx, request); err != nil {
+		return response, err
+	}
+
+	deviceID := deviceIDFromContext(ctx)
+	if deviceID == "" {
+		return response, fmt.Errorf("device identification required")
+	}
+
+	chatJID, err := utils.ParseJID(request.Phone)
+	if err != nil {
+		return response, pkgError.ValidationError(err.Error())
+	}
+
+	receipts, err := service.chatStorageRepo.GetReceiptsForMessage(ctx, deviceID, chatJID.ToNonAD().String(), request.MessageID)
+	if err != nil {
+		return response, err
+	}
+
+	response.MessageID = request.MessageID
+	response.ChatJID = chatJID.ToNonAD().String()
+	response.Receipts = make([]domainMessage.ReceiptInfo, 0, len(receipts))
+	for _, receipt := range receipts {
+		response.Receipts = append(response.Receipts, domainMessage.ReceiptInfo{
+			Recipient: receipt.Recipient,
+			Type:      receipt.Type,
+			Timestamp: receipt.Timestamp.Format(time.RFC3339),
+		})
+	}
+	return response, nil
+}
+
+// GetPoll implements message.IMessageService.
+func (service serviceMessage) GetPoll(ctx context.Context, request domainMessage.GetPollRequest) (response domainMessage.GetPollResponse, err error) {
+	if err = validations.ValidateGetPoll(ctx, request); err != nil {
+		return response, err
+	}
+
+	deviceID := deviceIDFromContext(ctx)
+	if deviceID == "" {
+		return response, fmt.Errorf("device identification required")
+	}
+
+	chatJID, err := utils.ParseJID(request.Phone)
+	if err != nil {
+		return response, pkgError.ValidationError(err.Error())
+	}
+
+	poll, err := service.chatStorageRepo.GetPoll(ctx, deviceID, chatJID.ToNonAD().String(), request.MessageID)
+	if err != nil {
+		return response, err
+	}
+	if poll == nil {
+		return response, fmt.Errorf("poll %s not found in chat %s", request.MessageID, chatJID.ToNonAD().String())
+	}
+
+	votes, err := service.chatStorageRepo.GetPollVotes(ctx, deviceID, poll.ChatJID, poll.MessageID)
+	if err != nil {
+		return response, err
+	}
+
+	response.MessageID = poll.MessageID
+	response.ChatJID = poll.ChatJID
+	response.Question = poll.Question
+	response.SelectableCount = poll.SelectableCount
+	response.TotalVoters = len(votes)
+	response.Options = make([]domainMessage.PollOptionInfo, 0, len(poll.Options))
+	index := make(map[string]int, len(poll.Options))
+	for _, name := range poll.Options {
+		index[name] = len(response.Options)
+		response.Options = append(response.Options, domainMessage.PollOptionInfo{Name: name, Voters: []string{}})
+	}
+	for _, vote := range votes {
+		for _, name := range vote.SelectedOptions {
+			i, ok := index[name]
+			if !ok {
+				// Options that could not be decoded are reported as they were stored
+				i = len(response.Options)
+				index[name] = i
+				response.Options = append(response.Options, domainMessage.PollOptionInfo{Name: name, Voters: []string{}})
+			}
+			response.Options[i].Votes++
+			response.Options[i].Voters = append(response.Options[i].Voters, vote.Voter)
+		}
+	}
+	return response, nil
+}
+
 // DownloadMedia implements message.IMessageService.
+// GetMessageMedia returns the local copy of the attachment of a stored message,
+// downloading it first when it was never downloaded or the file is gone.
+func (service serviceMessage) GetMessageMedia(ctx context.Context, request domainMessage.GetMessageMediaRequest) (response domainMessage.GetMessageMediaResponse, err error) {
+	if err = validations.ValidateGetMessageMedia(ctx, request); err != nil {
+		return response, err
+	}
+
+	deviceID := deviceIDFromContext(ctx)
+	if deviceID == "" {
+		return response, fmt.Errorf("device identification required")
+	}
+
+	chatJID, err := utils.ParseJID(request.Phone)
+	if err != nil {
+		return response, pkgError.ValidationError(err.Error())
+	}
+
+	message, err := service.chatStorageRepo.GetMessageByDevice(ctx, deviceID, request.MessageID)
+	if err != nil {
+		return response, err
+	}
+	if message == nil || message.ChatJID != chatJID.ToNonAD().String() {
+		return response, fmt.Errorf("message %s not found in chat %s", request.MessageID, chatJID.ToNonAD().String())
+	}
+
+	path := message.MediaPath
+	if path != "" {
+		if _, err := os.Stat(path); err != nil {
+			path = ""
+		}
+	}
+	if path == "" {
+		client := whatsapp.ClientFromContext(ctx)
+		if client == nil {
+			return response, pkgError.ErrWaCLI
+		}
+		if path, err = whatsapp.DownloadStoredMedia(ctx, client, service.chatStorageRepo, message); err != nil {
+			return response, err
+		}
+	}
+
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return response, fmt.Errorf("failed to read media file: %v", err)
+	}
+
+	response.MessageID = message.ID
+	response.MediaType = message.MediaType
+	response.Filename = message.Filename
+	if response.Filename == "" {
+		response.Filename = filepath.Base(path)
+	}
+	response.FilePath = path
+	response.FileSize = fileInfo.Size()
+	return response, nil
+}
+
 func (service serviceMessage) DownloadMedia(ctx context.Context, request domainMessage.DownloadMediaRequest) (response domainMessage.DownloadMediaResponse, err error) {
 	if err = validations.ValidateDownloadMedia(ctx, request); err != nil {
 		return response, err
@@ -252,7 +639,7 @@ func (service serviceMessage) DownloadMedia(ctx context.Context, request domainM
 	}
 
 	// Query the message from chat storage
-	message, err := service.chatStorageRepo.GetMessageByID(request.MessageID)
+	message, err := service.chatStorageRepo.GetMessageByID(ctx, request.MessageID)
 	if err != nil {
 		return response, fmt.Errorf("message not found: %v", err)
 	}
@@ -280,57 +667,13 @@ func (service serviceMessage) DownloadMedia(ctx context.Context, request domainM
 		return response, fmt.Errorf("failed to create directory: %v", err)
 	}
 
-	// Create a downloadable message interface based on media type
-	var downloadableMsg interface{}
-
-	switch message.MediaType {
-	case "image":
-		downloadableMsg = &waE2E.ImageMessage{
-			URL:           proto.String(message.URL),
-			MediaKey:      message.MediaKey,
-			FileSHA256:    message.FileSHA256,
-			FileEncSHA256: message.FileEncSHA256,
-			FileLength:    proto.Uint64(message.FileLength),
-		}
-	case "video":
-		downloadableMsg = &waE2E.VideoMessage{
-			URL:           proto.String(message.URL),
-			MediaKey:      message.MediaKey,
-			FileSHA256:    message.FileSHA256,
-			FileEncSHA256: message.FileEncSHA256,
-			FileLength:    proto.Uint64(message.FileLength),
-		}
-	case "audio":
-		downloadableMsg = &waE2E.AudioMessage{
-			URL:           proto.String(message.URL),
-			MediaKey:      message.MediaKey,
-			FileSHA256:    message.FileSHA256,
-			FileEncSHA256: message.FileEncSHA256,
-			FileLength:    proto.Uint64(message.FileLength),
-		}
-	case "document":
-		downloadableMsg = &waE2E.DocumentMessage{
-			URL:           proto.String(message.URL),
-			MediaKey:      message.MediaKey,
-			FileSHA256:    message.FileSHA256,
-			FileEncSHA256: message.FileEncSHA256,
-			FileLength:    proto.Uint64(message.FileLength),
-			FileName:      proto.String(message.Filename),
-		}
-	case "sticker":
-		downloadableMsg = &waE2E.StickerMessage{
-			URL:           proto.String(message.URL),
-			MediaKey:      message.MediaKey,
-			FileSHA256:    message.FileSHA256,
-			FileEncSHA256: message.FileEncSHA256,
-			FileLength:    proto.Uint64(message.FileLength),
-		}
-	default:
-		return response, fmt.Errorf("unsupported media type: %s", message.MediaType)
+	downloadableMsg, err := whatsapp.StoredMediaDownloadable(message)
+	if err != nil {
+		return response, err
 	}
 
 	// Download the media using existing utils.ExtractMedia function
-	extractedMedia, err := utils.ExtractMedia(ctx, client, dateDir, downloadableMsg.(whatsmeow.DownloadableMessage))
+	extractedMedia, err := utils.ExtractMedia(ctx, client, dateDir, downloadableMsg)
 	if err != nil {
 		return response, fmt.Errorf("failed to download media: %v", err)
 	}
@@ -338,7 +681,7 @@ func (service serviceMessage) DownloadMedia(ctx context.Context, request domainM
 	// Get file size
 	fileInfo, err := os.Stat(extractedMedia.MediaPath)
 	if err != nil {
-		logrus.Warnf("Could not get file size for %s: %v", extractedMedia.MediaPath, err)
+		logrus.WithContext(ctx).Warnf("Could not get file size for %s: %v", extractedMedia.MediaPath, err)
 	}
 
 	// Build response
@@ -351,7 +694,7 @@ func (service serviceMessage) DownloadMedia(ctx context.Context, request domainM
 		response.FileSize = fileInfo.Size()
 	}
 
-	logrus.Info(map[string]any{
+	logrus.WithContext(ctx).Info(map[string]any{
 		"message_id": request.MessageID,
 		"phone":      request.Phone,
 		"chat":       dataWaRecipient.String(),
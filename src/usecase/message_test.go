@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainMessage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/message"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mau.fi/whatsmeow/proto/waCommon"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestEditableMessage(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+
+	sentAt := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	contact := types.NewJID("628111", types.DefaultUserServer)
+	group := types.NewJID("120363000000000000", types.GroupServer)
+	for _, message := range []*domainChatStorage.Message{
+		{ID: "OWN", ChatJID: contact.String(), IsFromMe: true, Content: "see you at 10"},
+		{ID: "THEIRS", ChatJID: contact.String(), Sender: contact.String(), Content: "ok"},
+		{ID: "GROUP", ChatJID: group.String(), IsFromMe: true, Content: "the plan"},
+	} {
+		message.DeviceID, message.Timestamp = "dev-1", sentAt
+		require.NoError(t, repo.StoreMessage(context.Background(), message))
+	}
+
+	defaultWindow := config.WhatsappMessageEditWindow
+	t.Cleanup(func() { config.WhatsappMessageEditWindow = defaultWindow })
+	config.WhatsappMessageEditWindow = 15 * time.Minute
+
+	service := serviceMessage{chatStorageRepo: repo}
+	ctx := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-1", nil, nil))
+
+	message, err := service.editableMessage(ctx, contact, "OWN", sentAt.Add(14*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, "see you at 10", message.Content)
+
+	_, err = service.editableMessage(ctx, contact, "OWN", sentAt.Add(16*time.Minute))
+	assert.IsType(t, pkgError.EditWindowExpiredError(""), err)
+
+	_, err = service.editableMessage(ctx, contact, "THEIRS", sentAt)
+	assert.IsType(t, pkgError.ValidationError(""), err)
+
+	_, err = service.editableMessage(ctx, contact, "UNKNOWN", sentAt)
+	assert.IsType(t, pkgError.NotFoundError(""), err)
+
+	_, err = service.editableMessage(ctx, types.NewJID("120363111111111111", types.GroupServer), "GROUP", sentAt)
+	assert.IsType(t, pkgError.NotFoundError(""), err, "the message belongs to another group")
+
+	config.WhatsappMessageEditWindow = 0
+	_, err = service.editableMessage(ctx, contact, "OWN", sentAt.Add(24*time.Hour))
+	assert.NoError(t, err, "a zero window doesn't expire")
+}
+
+func TestRevokeTarget(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+
+	sentAt := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	contact := types.NewJID("628111", types.DefaultUserServer)
+	contactLID := types.NewJID("123456789", types.HiddenUserServer)
+	group := types.NewJID("120363000000000000", types.GroupServer)
+	for _, message := range []*domainChatStorage.Message{
+		{ID: "OWN", ChatJID: contactLID.String(), IsFromMe: true, Content: "see you at 10"},
+		{ID: "THEIRS", ChatJID: contact.String(), Sender: contact.String(), Content: "ok"},
+		{ID: "MEMBER", ChatJID: group.String(), Sender: "628222:5@s.whatsapp.net", Content: "spam"},
+	} {
+		message.DeviceID, message.Timestamp = "dev-1", sentAt
+		require.NoError(t, repo.StoreMessage(context.Background(), message))
+	}
+
+	service := serviceMessage{chatStorageRepo: repo}
+	ctx := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-1", nil, nil))
+
+	chat, sender, err := service.revokeTarget(ctx, contact, "OWN", "", sentAt.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, contactLID, chat, "revoked in the chat it was stored under")
+	assert.True(t, sender.IsEmpty())
+
+	_, _, err = service.revokeTarget(ctx, contact, "OWN", "", sentAt.Add(messageRevokeWindow+time.Minute))
+	assert.IsType(t, pkgError.RevokeWindowExpiredError(""), err)
+
+	_, _, err = service.revokeTarget(ctx, contact, "THEIRS", "", sentAt)
+	assert.IsType(t, pkgError.ValidationError(""), err)
+
+	chat, sender, err = service.revokeTarget(ctx, group, "MEMBER", "", sentAt)
+	require.NoError(t, err)
+	assert.Equal(t, group, chat)
+	assert.Equal(t, types.NewJID("628222", types.DefaultUserServer), sender, "admin revoke of the stored sender")
+
+	chat, sender, err = service.revokeTarget(ctx, group, "UNKNOWN", "+628333", sentAt)
+	require.NoError(t, err)
+	assert.Equal(t, group, chat)
+	assert.Equal(t, types.NewJID("628333", types.DefaultUserServer), sender)
+
+	_, _, err = service.revokeTarget(ctx, contact, "UNKNOWN", "628333", sentAt)
+	assert.IsType(t, pkgError.ValidationError(""), err, "participant only applies to groups")
+
+	chat, sender, err = service.revokeTarget(ctx, contact, "UNKNOWN", "", sentAt)
+	require.NoError(t, err)
+	assert.Equal(t, contact, chat)
+	assert.True(t, sender.IsEmpty())
+}
+
+func TestReactionKey(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+
+	contact := types.NewJID("628111", types.DefaultUserServer)
+	contactLID := types.NewJID("123456789", types.HiddenUserServer)
+	group := types.NewJID("120363000000000000", types.GroupServer)
+	for _, message := range []*domainChatStorage.Message{
+		{ID: "3EB0OWN", ChatJID: contactLID.String(), IsFromMe: true, Content: "see you at 10"},
+		{ID: "MEMBER", ChatJID: group.String(), Sender: "628222:5@s.whatsapp.net", Content: "the plan"},
+		{ID: "MINE", ChatJID: group.String(), IsFromMe: true, Content: "agreed"},
+	} {
+		message.DeviceID, message.Timestamp = "dev-1", time.Now()
+		require.NoError(t, repo.StoreMessage(context.Background(), message))
+	}
+
+	service := serviceMessage{chatStorageRepo: repo}
+	ctx := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-1", nil, nil))
+
+	chat, key := service.reactionKey(ctx, contact, "3EB0OWN")
+	assert.Equal(t, contactLID, chat)
+	assert.True(t, key.GetFromMe())
+	assert.Equal(t, contactLID.String(), key.GetRemoteJID())
+	assert.Nil(t, key.Participant)
+
+	chat, key = service.reactionKey(ctx, group, "MEMBER")
+	assert.Equal(t, group, chat)
+	assert.False(t, key.GetFromMe())
+	assert.Equal(t, "628222@s.whatsapp.net", key.GetParticipant())
+
+	_, key = service.reactionKey(ctx, group, "MINE")
+	assert.True(t, key.GetFromMe())
+	assert.Nil(t, key.Participant)
+
+	chat, key = service.reactionKey(ctx, contact, "3A1B2C3D4E5F60718293A4B5C6D7E8F9")
+	assert.Equal(t, contact, chat)
+	assert.False(t, key.GetFromMe(), "long IDs come from phones, so they aren't ours")
+	assert.Equal(t, "3A1B2C3D4E5F60718293A4B5C6D7E8F9", key.GetID())
+}
+
+func TestBuildPinMessage(t *testing.T) {
+	key := &waCommon.MessageKey{ID: proto.String("3EB0OWN"), FromMe: proto.Bool(true), RemoteJID: proto.String("628111@s.whatsapp.net")}
+
+	pin := buildPinMessage(key, domainMessage.PinDurations["24h"])
+	assert.Equal(t, waE2E.PinInChatMessage_PIN_FOR_ALL, pin.GetPinInChatMessage().GetType())
+	assert.Equal(t, "3EB0OWN", pin.GetPinInChatMessage().GetKey().GetID())
+	assert.Equal(t, uint32(86400), pin.GetMessageContextInfo().GetMessageAddOnDurationInSecs())
+
+	unpin := buildPinMessage(key, 0)
+	assert.Equal(t, waE2E.PinInChatMessage_UNPIN_FOR_ALL, unpin.GetPinInChatMessage().GetType())
+	assert.Nil(t, unpin.MessageContextInfo)
+}
+
+func TestGetMessage(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+
+	sentAt := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.StoreMessage(context.Background(), &domainChatStorage.Message{
+		ID: "SENT", ChatJID: "628111@s.whatsapp.net", DeviceID: "dev-1", IsFromMe: true,
+		Content: "see you at 10", Timestamp: sentAt, RequestID: "req-42",
+	}))
+
+	service := serviceMessage{chatStorageRepo: repo}
+	ctx := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-1", nil, nil))
+
+	message, err := service.GetMessage(ctx, domainMessage.GetMessageRequest{MessageID: "SENT"})
+	require.NoError(t, err)
+	assert.Equal(t, "req-42", message.RequestID)
+	assert.Equal(t, "see you at 10", message.Content)
+	assert.Equal(t, "2026-03-02T09:00:00Z", message.Timestamp)
+
+	_, err = service.GetMessage(ctx, domainMessage.GetMessageRequest{MessageID: "UNKNOWN"})
+	assert.IsType(t, pkgError.NotFoundError(""), err)
+
+	otherDevice := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-2", nil, nil))
+	_, err = service.GetMessage(otherDevice, domainMessage.GetMessageRequest{MessageID: "SENT"})
+	assert.IsType(t, pkgError.NotFoundError(""), err, "the message belongs to another device")
+}
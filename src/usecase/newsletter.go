@@ -2,18 +2,393 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
 
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
 	domainNewsletter "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/newsletter"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
 	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
 )
 
-type serviceNewsletter struct{}
+type serviceNewsletter struct {
+	chatStorageRepo domainChatStorage.IChatStorageRepository
+}
+
+func NewNewsletterService(chatStorageRepo domainChatStorage.IChatStorageRepository) domainNewsletter.INewsletterUsecase {
+	return &serviceNewsletter{
+		chatStorageRepo: chatStorageRepo,
+	}
+}
+
+// CreateNewsletter creates a channel owned by the device. The picture is
+// processed like a group photo, since both are square profile pictures.
+func (service serviceNewsletter) CreateNewsletter(ctx context.Context, request domainNewsletter.CreateNewsletterRequest) (response domainNewsletter.Newsletter, err error) {
+	if err = validations.ValidateCreateNewsletter(ctx, request); err != nil {
+		return response, err
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+	utils.MustLogin(client)
+
+	params := whatsmeow.CreateNewsletterParams{Name: request.Name, Description: request.Description}
+	if request.Picture != nil {
+		picture, err := utils.ProcessGroupPhoto(request.Picture)
+		if err != nil {
+			return response, err
+		}
+		params.Picture = picture.Bytes()
+	}
+
+	metadata, err := client.CreateNewsletter(ctx, params)
+	if err != nil {
+		return response, err
+	}
+	if metadata == nil {
+		return response, pkgError.InternalServerError("WhatsApp did not return the created newsletter")
+	}
+
+	service.storeNewsletterChat(ctx, metadata.ID, metadata.ThreadMeta.Name.Text)
+	return newsletterFromMetadata(metadata), nil
+}
+
+// ListNewsletters lists the channels the device follows or owns.
+func (service serviceNewsletter) ListNewsletters(ctx context.Context) (response domainNewsletter.ListNewslettersResponse, err error) {
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+	utils.MustLogin(client)
+
+	subscribed, err := client.GetSubscribedNewsletters(ctx)
+	if err != nil {
+		return response, err
+	}
+
+	response.Data = make([]domainNewsletter.Newsletter, 0, len(subscribed))
+	for _, metadata := range subscribed {
+		if metadata != nil {
+			response.Data = append(response.Data, newsletterFromMetadata(metadata))
+		}
+	}
+	return response, nil
+}
+
+// Follow follows a channel and stores its chat, so its messages have a chat
+// to attach to.
+func (service serviceNewsletter) Follow(ctx context.Context, request domainNewsletter.FollowRequest) (response domainNewsletter.Newsletter, err error) {
+	if err = validations.ValidateFollowNewsletter(ctx, request); err != nil {
+		return response, err
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+
+	JID, err := utils.ValidateJidWithLogin(client, request.NewsletterID)
+	if err != nil {
+		return response, err
+	}
+
+	if err = client.FollowNewsletter(ctx, JID); err != nil {
+		return response, err
+	}
+
+	// Following succeeded, so a failed lookup only costs the channel's details
+	metadata, err := client.GetNewsletterInfo(ctx, JID)
+	if err != nil || metadata == nil {
+		logrus.WithError(err).Warnf("Failed to get info of followed newsletter %s", JID)
+		service.storeNewsletterChat(ctx, JID, "")
+		return domainNewsletter.Newsletter{ID: JID.String(), Name: JID.User}, nil
+	}
+	service.storeNewsletterChat(ctx, JID, metadata.ThreadMeta.Name.Text)
+	return newsletterFromMetadata(metadata), nil
+}
 
-func NewNewsletterService() domainNewsletter.INewsletterUsecase {
-	return &serviceNewsletter{}
+// Mute mutes or unmutes a channel, and the stored chat along with it.
+func (service serviceNewsletter) Mute(ctx context.Context, request domainNewsletter.MuteRequest) (err error) {
+	if err = validations.ValidateMuteNewsletter(ctx, request); err != nil {
+		return err
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return pkgError.ErrWaCLI
+	}
+
+	JID, err := utils.ValidateJidWithLogin(client, request.NewsletterID)
+	if err != nil {
+		return err
+	}
+
+	mute := request.Mute == nil || *request.Mute
+	if err = client.NewsletterToggleMute(ctx, JID, mute); err != nil {
+		return err
+	}
+
+	mutedUntil := time.Time{}
+	if mute {
+		mutedUntil = domainChatStorage.MutedForever
+	}
+	if err := service.chatStorageRepo.UpdateChatFlags(ctx, deviceIDFromContext(ctx), JID.String(), domainChatStorage.ChatFlags{MutedUntil: &mutedUntil}); err != nil {
+		logrus.WithError(err).Warnf("Failed to store mute state of newsletter %s", JID)
+	}
+	return nil
+}
+
+// Send publishes a text or image post to a channel the device owns or
+// administers. Channel media isn't encrypted, so images go through the
+// newsletter upload and are sent with the handle it returns. The post is
+// stored with its server ID, which reactions refer to.
+func (service serviceNewsletter) Send(ctx context.Context, request domainNewsletter.SendRequest) (response domainNewsletter.SendResponse, err error) {
+	if err = validations.ValidateSendNewsletter(ctx, request); err != nil {
+		return response, err
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+
+	JID, err := utils.ValidateJidWithLogin(client, request.NewsletterID)
+	if err != nil {
+		return response, err
+	}
+	metadata, err := service.requireNewsletterAdmin(ctx, client, JID)
+	if err != nil {
+		return response, err
+	}
+
+	msg := newsletterTextPost(request.Message)
+	var extra whatsmeow.SendRequestExtra
+	if request.Image != nil || (request.ImageURL != nil && *request.ImageURL != "") {
+		prepared, err := newsletterImage(request)
+		if err != nil {
+			return response, err
+		}
+		uploaded, err := client.UploadNewsletter(ctx, prepared.data, whatsmeow.MediaImage)
+		if err != nil {
+			return response, pkgError.WaUploadMediaError(fmt.Sprintf("failed to upload image: %v", err))
+		}
+		msg = newsletterImagePost(prepared, uploaded, request.Message)
+		extra.MediaHandle = uploaded.Handle
+	}
+
+	sent, err := sendMessageFn(ctx, client, JID, msg, extra)
+	if err != nil {
+		return response, err
+	}
+
+	service.storeNewsletterChat(ctx, JID, metadata.ThreadMeta.Name.Text)
+	service.storeNewsletterPost(ctx, client, JID, msg, sent)
+
+	return domainNewsletter.SendResponse{
+		MessageID: sent.ID,
+		ServerID:  int64(sent.ServerID),
+		Timestamp: sent.Timestamp.Format(time.RFC3339),
+	}, nil
+}
+
+// React reacts to a channel post. Channel reactions address posts by their
+// server ID rather than the message ID; the reaction is stored against the
+// post when it is in chat storage.
+func (service serviceNewsletter) React(ctx context.Context, request domainNewsletter.ReactRequest) (err error) {
+	if err = validations.ValidateReactNewsletter(ctx, request); err != nil {
+		return err
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return pkgError.ErrWaCLI
+	}
+
+	JID, err := utils.ValidateJidWithLogin(client, request.NewsletterID)
+	if err != nil {
+		return err
+	}
+
+	if err = client.NewsletterSendReaction(ctx, JID, types.MessageServerID(request.ServerID), request.Emoji, ""); err != nil {
+		return err
+	}
+
+	deviceID := deviceIDFromContext(ctx)
+	posts, err := service.chatStorageRepo.GetMessages(ctx, &domainChatStorage.MessageFilter{DeviceID: deviceID, ChatJID: JID.String(), ServerID: request.ServerID, Limit: 1})
+	if err != nil || len(posts) == 0 {
+		return nil
+	}
+	sender := ""
+	if client.Store != nil && client.Store.ID != nil {
+		sender = client.Store.ID.ToNonAD().String()
+	}
+	if err := service.chatStorageRepo.StoreReaction(ctx, &domainChatStorage.Reaction{
+		MessageID: posts[0].ID,
+		ChatJID:   JID.String(),
+		DeviceID:  deviceID,
+		Sender:    sender,
+		Emoji:     request.Emoji,
+		Timestamp: time.Now(),
+	}); err != nil {
+		logrus.WithError(err).Warnf("Failed to store reaction to newsletter %s post %d", JID, request.ServerID)
+	}
+	return nil
+}
+
+// requireNewsletterAdmin fails with NotNewsletterAdminError unless the device
+// owns or administers the channel.
+func (service serviceNewsletter) requireNewsletterAdmin(ctx context.Context, client *whatsmeow.Client, jid types.JID) (*types.NewsletterMetadata, error) {
+	metadata, err := client.GetNewsletterInfo(ctx, jid)
+	if err != nil {
+		return nil, err
+	}
+	if metadata == nil {
+		return nil, pkgError.NotFoundError(fmt.Sprintf("newsletter %s not found", jid))
+	}
+	if !isNewsletterAdmin(metadata) {
+		return nil, pkgError.NotNewsletterAdminError(fmt.Sprintf("you must be an owner or admin of newsletter %s to post to it", jid))
+	}
+	return metadata, nil
+}
+
+func isNewsletterAdmin(metadata *types.NewsletterMetadata) bool {
+	if metadata.ViewerMeta == nil {
+		return false
+	}
+	return metadata.ViewerMeta.Role == types.NewsletterRoleOwner || metadata.ViewerMeta.Role == types.NewsletterRoleAdmin
+}
+
+// newsletterImage reads the image of a post from the upload or its URL and
+// prepares it like any sent image. Animated images would only show their
+// first frame, so they are refused.
+func newsletterImage(request domainNewsletter.SendRequest) (preparedImage, error) {
+	var data []byte
+	if request.ImageURL != nil && *request.ImageURL != "" {
+		downloaded, _, err := utils.DownloadImageFromURL(*request.ImageURL)
+		if err != nil {
+			return preparedImage{}, pkgError.InternalServerError(fmt.Sprintf("failed to download image from URL %v", err))
+		}
+		data = downloaded
+	} else {
+		file, err := request.Image.Open()
+		if err != nil {
+			return preparedImage{}, pkgError.InternalServerError(fmt.Sprintf("failed to read image %v", err))
+		}
+		data, err = io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return preparedImage{}, pkgError.InternalServerError(fmt.Sprintf("failed to read image %v", err))
+		}
+	}
+
+	prepared, err := prepareImage(data, config.WhatsappImageMaxDimension)
+	if errors.Is(err, errAnimatedImage) {
+		return preparedImage{}, pkgError.InvalidMediaError("animated images can't be posted to newsletters")
+	}
+	return prepared, err
+}
+
+func newsletterTextPost(text string) *waE2E.Message {
+	return &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{Text: proto.String(text)}}
+}
+
+// newsletterImagePost builds an image post from a newsletter upload, which
+// has no media key or encrypted hash.
+func newsletterImagePost(prepared preparedImage, uploaded whatsmeow.UploadResponse, caption string) *waE2E.Message {
+	return &waE2E.Message{ImageMessage: &waE2E.ImageMessage{
+		JPEGThumbnail: prepared.thumbnail,
+		Caption:       proto.String(caption),
+		URL:           proto.String(uploaded.URL),
+		DirectPath:    proto.String(uploaded.DirectPath),
+		Mimetype:      proto.String(prepared.mimeType),
+		FileSHA256:    uploaded.FileSHA256,
+		FileLength:    proto.Uint64(uploaded.FileLength),
+		Width:         proto.Uint32(uint32(prepared.width)),
+		Height:        proto.Uint32(uint32(prepared.height)),
+	}}
+}
+
+// storeNewsletterPost stores a published post with its server ID.
+func (service serviceNewsletter) storeNewsletterPost(ctx context.Context, client *whatsmeow.Client, jid types.JID, msg *waE2E.Message, sent whatsmeow.SendResponse) {
+	sender := jid.String()
+	if client.Store != nil && client.Store.ID != nil {
+		sender = client.Store.ID.String()
+	}
+	mediaType, filename, url, mediaKey, fileSHA256, fileEncSHA256, fileLength := utils.ExtractMediaInfo(msg)
+	if err := service.chatStorageRepo.StoreMessage(ctx, &domainChatStorage.Message{
+		ID:            sent.ID,
+		ChatJID:       jid.String(),
+		DeviceID:      deviceIDFromContext(ctx),
+		Sender:        sender,
+		Content:       utils.ExtractMessageTextFromProto(msg),
+		Timestamp:     sent.Timestamp,
+		IsFromMe:      true,
+		MediaType:     mediaType,
+		Filename:      filename,
+		URL:           url,
+		MediaKey:      mediaKey,
+		FileSHA256:    fileSHA256,
+		FileEncSHA256: fileEncSHA256,
+		FileLength:    fileLength,
+		ServerID:      int64(sent.ServerID),
+	}); err != nil {
+		logrus.WithError(err).Warnf("Failed to store post %s of newsletter %s", sent.ID, jid)
+	}
+}
+
+// storeNewsletterChat stores the chat of a channel the device just followed
+// or created. Chats that are already stored are left as they are.
+func (service serviceNewsletter) storeNewsletterChat(ctx context.Context, jid types.JID, name string) {
+	deviceID := deviceIDFromContext(ctx)
+	if chat, err := service.chatStorageRepo.GetChatByDevice(ctx, deviceID, jid.String()); err != nil || chat != nil {
+		return
+	}
+	if name == "" {
+		name = jid.User
+	}
+	if err := service.chatStorageRepo.StoreChat(ctx, &domainChatStorage.Chat{DeviceID: deviceID, JID: jid.String(), Name: name, LastMessageTime: time.Now()}); err != nil {
+		logrus.WithError(err).Warnf("Failed to store chat of newsletter %s", jid)
+	}
+}
+
+func newsletterFromMetadata(metadata *types.NewsletterMetadata) domainNewsletter.Newsletter {
+	thread := metadata.ThreadMeta
+	newsletter := domainNewsletter.Newsletter{
+		ID:              metadata.ID.String(),
+		Name:            thread.Name.Text,
+		Description:     thread.Description.Text,
+		SubscriberCount: thread.SubscriberCount,
+		Verified:        thread.VerificationState == types.NewsletterVerificationStateVerified,
+	}
+	if thread.InviteCode != "" {
+		newsletter.InviteLink = whatsmeow.NewsletterLinkPrefix + thread.InviteCode
+	}
+	if metadata.ViewerMeta != nil {
+		newsletter.Role = string(metadata.ViewerMeta.Role)
+		newsletter.Muted = metadata.ViewerMeta.Mute == types.NewsletterMuteOn
+	}
+	if thread.Picture != nil && thread.Picture.URL != "" {
+		newsletter.PictureURL = thread.Picture.URL
+	} else if thread.Preview.URL != "" {
+		newsletter.PictureURL = thread.Preview.URL
+	}
+	if !thread.CreationTime.IsZero() {
+		newsletter.CreatedAt = thread.CreationTime.Format(time.RFC3339)
+	}
+	return newsletter
 }
 
 func (service serviceNewsletter) Unfollow(ctx context.Context, request domainNewsletter.UnfollowRequest) (err error) {
@@ -33,3 +408,137 @@ func (service serviceNewsletter) Unfollow(ctx context.Context, request domainNew
 
 	return client.UnfollowNewsletter(ctx, JID)
 }
+
+// GetNewsletterMessages serves the latest channel messages from chat storage
+// and only asks WhatsApp when fewer than Count are stored. Fetched messages are
+// stored so the next call can be answered locally.
+func (service serviceNewsletter) GetNewsletterMessages(ctx context.Context, request domainNewsletter.GetNewsletterMessagesRequest) (response domainNewsletter.GetNewsletterMessagesResponse, err error) {
+	if err = validations.ValidateGetNewsletterMessages(ctx, &request); err != nil {
+		return response, err
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+
+	JID, err := utils.ValidateJidWithLogin(client, request.NewsletterID)
+	if err != nil {
+		return response, err
+	}
+	if JID.Server != types.NewsletterServer {
+		return response, pkgError.ValidationError("newsletter_id: must be a newsletter JID")
+	}
+
+	deviceID := deviceIDFromContext(ctx)
+	response.NewsletterID = JID.String()
+
+	stored, err := service.chatStorageRepo.GetMessages(ctx, &domainChatStorage.MessageFilter{
+		DeviceID: deviceID,
+		ChatJID:  JID.String(),
+		Limit:    request.Count,
+	})
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to get newsletter messages from storage")
+	}
+	if len(stored) >= request.Count {
+		return storedNewsletterMessages(response, stored), nil
+	}
+
+	fetched, err := client.GetNewsletterMessages(ctx, JID, &whatsmeow.GetNewsletterMessagesParams{Count: request.Count})
+	if err != nil {
+		if len(stored) > 0 {
+			logrus.WithError(err).Warn("Failed to fetch newsletter messages, serving stored ones")
+			return storedNewsletterMessages(response, stored), nil
+		}
+		return response, err
+	}
+
+	service.storeNewsletterMessages(ctx, client, deviceID, JID, fetched)
+
+	response.Source = "server"
+	response.Data = make([]domainNewsletter.NewsletterMessage, 0, len(fetched))
+	sort.SliceStable(fetched, func(i, j int) bool {
+		return fetched[i].Timestamp.After(fetched[j].Timestamp)
+	})
+	for _, message := range fetched {
+		mediaType, _, _, _, _, _, _ := utils.ExtractMediaInfo(message.Message)
+		response.Data = append(response.Data, domainNewsletter.NewsletterMessage{
+			ID:             message.MessageID,
+			ServerID:       int64(message.MessageServerID),
+			Content:        utils.ExtractMessageTextFromProto(message.Message),
+			MediaType:      mediaType,
+			Timestamp:      message.Timestamp.Format(time.RFC3339),
+			ViewsCount:     message.ViewsCount,
+			ReactionCounts: message.ReactionCounts,
+		})
+	}
+	return response, nil
+}
+
+func storedNewsletterMessages(response domainNewsletter.GetNewsletterMessagesResponse, stored []*domainChatStorage.Message) domainNewsletter.GetNewsletterMessagesResponse {
+	response.Source = "storage"
+	response.Data = make([]domainNewsletter.NewsletterMessage, 0, len(stored))
+	for _, message := range stored {
+		response.Data = append(response.Data, domainNewsletter.NewsletterMessage{
+			ID:        message.ID,
+			ServerID:  message.ServerID,
+			Content:   message.Content,
+			MediaType: message.MediaType,
+			Timestamp: message.Timestamp.Format(time.RFC3339),
+		})
+	}
+	return response
+}
+
+// storeNewsletterMessages keeps fetched channel messages in chat storage,
+// creating the channel's chat under its name the first time.
+func (service serviceNewsletter) storeNewsletterMessages(ctx context.Context, client *whatsmeow.Client, deviceID string, jid types.JID, fetched []*types.NewsletterMessage) {
+	if len(fetched) == 0 {
+		return
+	}
+
+	messages := make([]*domainChatStorage.Message, 0, len(fetched))
+	latest := time.Time{}
+	for _, message := range fetched {
+		if message.Message == nil {
+			continue
+		}
+		mediaType, filename, url, mediaKey, fileSHA256, fileEncSHA256, fileLength := utils.ExtractMediaInfo(message.Message)
+		messages = append(messages, &domainChatStorage.Message{
+			ID:            message.MessageID,
+			ChatJID:       jid.String(),
+			DeviceID:      deviceID,
+			Sender:        jid.String(),
+			Content:       utils.ExtractMessageTextFromProto(message.Message),
+			Timestamp:     message.Timestamp,
+			MediaType:     mediaType,
+			Filename:      filename,
+			URL:           url,
+			MediaKey:      mediaKey,
+			FileSHA256:    fileSHA256,
+			FileEncSHA256: fileEncSHA256,
+			FileLength:    fileLength,
+			ServerID:      int64(message.MessageServerID),
+		})
+		if message.Timestamp.After(latest) {
+			latest = message.Timestamp
+		}
+	}
+	if len(messages) == 0 {
+		return
+	}
+
+	if chat, err := service.chatStorageRepo.GetChatByDevice(ctx, deviceID, jid.String()); err == nil && chat == nil {
+		name := jid.User
+		if metadata, err := client.GetNewsletterInfo(ctx, jid); err == nil && metadata != nil && metadata.ThreadMeta.Name.Text != "" {
+			name = metadata.ThreadMeta.Name.Text
+		}
+		if err := service.chatStorageRepo.StoreChat(ctx, &domainChatStorage.Chat{DeviceID: deviceID, JID: jid.String(), Name: name, LastMessageTime: latest}); err != nil {
+			logrus.WithError(err).Warn("Failed to store newsletter chat")
+		}
+	}
+	if _, err := service.chatStorageRepo.StoreMessagesBatch(ctx, messages); err != nil {
+		logrus.WithError(err).Warn("Failed to store fetched newsletter messages")
+	}
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	domainNewsletter "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/newsletter"
+	"github.com/stretchr/testify/assert"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestNewsletterFromMetadata(t *testing.T) {
+	metadata := &types.NewsletterMetadata{
+		ID: types.NewJID("120363123456789", types.NewsletterServer),
+		ThreadMeta: types.NewsletterThreadMetadata{
+			InviteCode:        "0029VaAbCdEf",
+			Name:              types.NewsletterText{Text: "Street news"},
+			Description:       types.NewsletterText{Text: "What happens on our street"},
+			SubscriberCount:   1250,
+			VerificationState: types.NewsletterVerificationStateVerified,
+			Preview:           types.ProfilePictureInfo{URL: "https://mmg.whatsapp.net/preview.jpg"},
+		},
+		ViewerMeta: &types.NewsletterViewerMetadata{Mute: types.NewsletterMuteOn, Role: types.NewsletterRoleSubscriber},
+	}
+	metadata.ThreadMeta.CreationTime.Time = time.Date(2026, time.March, 9, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, domainNewsletter.Newsletter{
+		ID:              "120363123456789@newsletter",
+		Name:            "Street news",
+		Description:     "What happens on our street",
+		InviteLink:      "https://whatsapp.com/channel/0029VaAbCdEf",
+		SubscriberCount: 1250,
+		Verified:        true,
+		Role:            "subscriber",
+		Muted:           true,
+		PictureURL:      "https://mmg.whatsapp.net/preview.jpg",
+		CreatedAt:       "2026-03-09T09:00:00Z",
+	}, newsletterFromMetadata(metadata))
+
+	metadata.ViewerMeta = nil
+	metadata.ThreadMeta.Picture = &types.ProfilePictureInfo{URL: "https://mmg.whatsapp.net/full.jpg"}
+	newsletter := newsletterFromMetadata(metadata)
+	assert.Empty(t, newsletter.Role, "not followed")
+	assert.Equal(t, "https://mmg.whatsapp.net/full.jpg", newsletter.PictureURL)
+}
+
+func TestIsNewsletterAdmin(t *testing.T) {
+	assert.True(t, isNewsletterAdmin(&types.NewsletterMetadata{ViewerMeta: &types.NewsletterViewerMetadata{Role: types.NewsletterRoleOwner}}))
+	assert.True(t, isNewsletterAdmin(&types.NewsletterMetadata{ViewerMeta: &types.NewsletterViewerMetadata{Role: types.NewsletterRoleAdmin}}))
+	assert.False(t, isNewsletterAdmin(&types.NewsletterMetadata{ViewerMeta: &types.NewsletterViewerMetadata{Role: types.NewsletterRoleSubscriber}}))
+	assert.False(t, isNewsletterAdmin(&types.NewsletterMetadata{}), "not followed")
+}
+
+func TestNewsletterImagePost(t *testing.T) {
+	prepared := preparedImage{data: []byte{1, 2, 3}, mimeType: "image/jpeg", width: 640, height: 480, thumbnail: []byte{9}}
+	uploaded := whatsmeow.UploadResponse{URL: "https://mmg.whatsapp.net/newsletter/abc", DirectPath: "/newsletter/abc", FileSHA256: []byte{4}, FileLength: 3, Handle: "handle"}
+
+	image := newsletterImagePost(prepared, uploaded, "Road closed").GetImageMessage()
+	assert.Equal(t, "https://mmg.whatsapp.net/newsletter/abc", image.GetURL())
+	assert.Equal(t, "/newsletter/abc", image.GetDirectPath())
+	assert.Equal(t, "Road closed", image.GetCaption())
+	assert.Equal(t, uint64(3), image.GetFileLength())
+	assert.Equal(t, uint32(640), image.GetWidth())
+	assert.Nil(t, image.MediaKey, "newsletter media isn't encrypted")
+	assert.Equal(t, "hello", newsletterTextPost("hello").GetExtendedTextMessage().GetText())
+}
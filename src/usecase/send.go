@@ -5,8 +5,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
 	"math"
 	"mime"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"os/exec"
@@ -26,6 +31,7 @@ import (
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/ui/rest/helpers"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
 	"github.com/disintegration/imaging"
+	"github.com/dustin/go-humanize"
 	fiberUtils "github.com/gofiber/fiber/v2/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/valyala/fasthttp"
@@ -41,44 +47,97 @@ var webpCanvasSizeRegex = regexp.MustCompile(`Canvas size:\s*(\d+)\s*x\s*(\d+)`)
 type serviceSend struct {
 	appService      app.IAppUsecase
 	chatStorageRepo domainChatStorage.IChatStorageRepository
+	queue           *outboundQueue
 }
 
 func NewSendService(appService app.IAppUsecase, chatStorageRepo domainChatStorage.IChatStorageRepository) domainSend.ISendUsecase {
 	return &serviceSend{
 		appService:      appService,
 		chatStorageRepo: chatStorageRepo,
+		queue:           newOutboundQueue(config.WhatsappSendWorkers, config.WhatsappSendRate, config.WhatsappSendRecipientGap, config.WhatsappSendMaxRetries),
 	}
 }
 
-// wrapSendMessage wraps the message sending process with message ID saving
-func (service serviceSend) wrapSendMessage(ctx context.Context, client *whatsmeow.Client, recipient types.JID, msg *waE2E.Message, content string) (whatsmeow.SendResponse, error) {
-	ts, err := client.SendMessage(ctx, recipient, msg)
+// sendMessageFn is swapped in tests to avoid a live WhatsApp connection.
+var sendMessageFn = func(ctx context.Context, client *whatsmeow.Client, to types.JID, msg *waE2E.Message, extra whatsmeow.SendRequestExtra) (whatsmeow.SendResponse, error) {
+	return client.SendMessage(ctx, to, msg, extra)
+}
+
+// wrapSendMessage sends the message through the outbound queue and saves it
+// once sent, quoting the message request replies to. In async mode it returns
+// as soon as the message is queued.
+func (service serviceSend) wrapSendMessage(ctx context.Context, client *whatsmeow.Client, recipient types.JID, msg *waE2E.Message, content string, request domainSend.BaseRequest) (sentMessage, error) {
+	if err := service.applyReply(ctx, client, recipient, msg, request); err != nil {
+		return sentMessage{}, err
+	}
+	service.applyChatExpiration(ctx, recipient, msg)
+
+	if request.Async {
+		// The job outlives the request, so keep the device from ctx but not its cancellation
+		job := newSendJob(context.WithoutCancel(ctx), client, recipient, msg)
+		job.typing = request.Typing
+		job.onSent = func(ctx context.Context, ts whatsmeow.SendResponse) {
+			service.storeSentMessage(ctx, client, recipient, msg, content, ts)
+		}
+		queued := sentMessage{SendResponse: whatsmeow.SendResponse{ID: job.info.MessageID}, JobID: job.info.ID}
+		if err := service.queue.submit(job); err != nil {
+			return sentMessage{}, err
+		}
+		return queued, nil
+	}
+
+	job := newSendJob(ctx, client, recipient, msg)
+	job.typing = request.Typing
+	ts, err := service.queue.send(job)
 	if err != nil {
-		return whatsmeow.SendResponse{}, err
+		return sentMessage{}, err
 	}
+	service.storeSentMessage(ctx, client, recipient, msg, content, ts)
+	return sentMessage{SendResponse: ts}, nil
+}
 
+// storeSentMessage saves a sent message to chat storage in the background.
+func (service serviceSend) storeSentMessage(ctx context.Context, client *whatsmeow.Client, recipient types.JID, msg *waE2E.Message, content string, ts whatsmeow.SendResponse) {
 	// Store the sent message using chatstorage
 	senderJID := ""
-	if client.Store.ID != nil {
+	if client != nil && client.Store != nil && client.Store.ID != nil {
 		senderJID = client.Store.ID.String()
 	}
 
+	var media *domainChatStorage.MediaInfo
+	if mediaType, filename, url, mediaKey, fileSHA256, fileEncSHA256, fileLength := utils.ExtractMediaInfo(msg); mediaType != "" {
+		media = &domainChatStorage.MediaInfo{
+			MessageID:     ts.ID,
+			ChatJID:       recipient.String(),
+			MediaType:     mediaType,
+			Filename:      filename,
+			URL:           url,
+			MediaKey:      mediaKey,
+			FileSHA256:    fileSHA256,
+			FileEncSHA256: fileEncSHA256,
+			FileLength:    fileLength,
+			Location:      whatsapp.ExtractLocation(msg),
+			Contacts:      whatsapp.ExtractContactCards(msg),
+			IsAnimated:    msg.GetStickerMessage().GetIsAnimated(),
+			IsViewOnce:    utils.IsViewOnceMessage(msg),
+		}
+	}
+
 	// Store message asynchronously with timeout
-	// Use a goroutine to avoid blocking the send operation
+	// Use a goroutine to avoid blocking the send operation; keep the device from ctx
+	// but not its cancellation, since the request may finish before the write does.
 	go func() {
-		storeCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Second)
 		defer cancel()
 
-		if err := service.chatStorageRepo.StoreSentMessageWithContext(storeCtx, ts.ID, senderJID, recipient.String(), content, ts.Timestamp); err != nil {
+		if err := service.chatStorageRepo.StoreSentMessageWithContext(storeCtx, ts.ID, senderJID, recipient.String(), content, ts.Timestamp, media); err != nil {
 			if errors.Is(err, context.DeadlineExceeded) {
-				logrus.Warn("Timeout storing sent message")
+				logrus.WithContext(ctx).Warn("Timeout storing sent message")
 			} else {
-				logrus.Warnf("Failed to store sent message: %v", err)
+				logrus.WithContext(ctx).Warnf("Failed to store sent message: %v", err)
 			}
 		}
 	}()
-
-	return ts, nil
 }
 
 func (service serviceSend) SendText(ctx context.Context, request domainSend.MessageRequest) (response domainSend.GenericResponse, err error) {
@@ -87,6 +146,14 @@ func (service serviceSend) SendText(ctx context.Context, request domainSend.Mess
 		return response, err
 	}
 
+	if request.TemplateName != "" {
+		request.Message, err = renderTemplate(ctx, service.chatStorageRepo, request.TemplateName, request.Variables, request.TemplateStrict)
+		if err != nil {
+			return response, err
+		}
+		ctx = utils.ContextWithTemplateName(ctx, request.TemplateName)
+	}
+
 	client := whatsapp.ClientFromContext(ctx)
 	if client == nil {
 		return response, pkgError.ErrWaCLI
@@ -97,10 +164,16 @@ func (service serviceSend) SendText(ctx context.Context, request domainSend.Mess
 		return response, err
 	}
 
+	// Mentions may rewrite the numbers written in the text
+	text, parsedMentions, err := service.resolveMentions(ctx, client, dataWaRecipient, request.Message, request.MentionRequest)
+	if err != nil {
+		return response, err
+	}
+
 	// Create base message
 	msg := &waE2E.Message{
 		ExtendedTextMessage: &waE2E.ExtendedTextMessage{
-			Text:        proto.String(request.Message),
+			Text:        proto.String(text),
 			ContextInfo: &waE2E.ContextInfo{},
 		},
 	}
@@ -111,84 +184,25 @@ func (service serviceSend) SendText(ctx context.Context, request domainSend.Mess
 		msg.ExtendedTextMessage.ContextInfo.ForwardingScore = proto.Uint32(100)
 	}
 
-	// Set disappearing message duration if provided
+	// Set disappearing message duration if provided, otherwise the chat's timer applies
 	if request.BaseRequest.Duration != nil && *request.BaseRequest.Duration > 0 {
 		msg.ExtendedTextMessage.ContextInfo.Expiration = proto.Uint32(uint32(*request.BaseRequest.Duration))
-	} else {
-		msg.ExtendedTextMessage.ContextInfo.Expiration = proto.Uint32(service.getDefaultEphemeralExpiration(request.BaseRequest.Phone))
-	}
-
-	// Get mentions from text (existing behavior - parses @phone from message text)
-	parsedMentions := service.getMentionFromText(ctx, request.Message)
-
-	// Add explicit mentions from request.Mentions (ghost mentions - no @ required in text)
-	if len(request.Mentions) > 0 {
-		explicitMentions := service.getMentionsFromList(ctx, request.Mentions, dataWaRecipient)
-		parsedMentions = append(parsedMentions, explicitMentions...)
-		// Deduplicate to avoid mentioning the same person twice
-		parsedMentions = utils.UniqueStrings(parsedMentions)
 	}
 
 	if len(parsedMentions) > 0 {
 		msg.ExtendedTextMessage.ContextInfo.MentionedJID = parsedMentions
 	}
 
-	// Reply message
-	if request.ReplyMessageID != nil && *request.ReplyMessageID != "" {
-		message, err := service.chatStorageRepo.GetMessageByID(*request.ReplyMessageID)
-		if err != nil {
-			logrus.Warnf("Error retrieving reply message ID %s: %v, continuing without reply context", *request.ReplyMessageID, err)
-		} else if message != nil { // Only set reply context if we found the message
-			// Ensure we use a full JID (user@server) for the Participant field
-			// Use the sender JID from storage as-is. Modern storage should already provide
-			// fully-qualified JIDs (e.g., user@s.whatsapp.net or group@g.us). Avoid mutating
-			// the JID here to prevent corrupting valid group or special JIDs.
-			participantJID := message.Sender
-
-			// Build base ContextInfo with reply details
-			ctxInfo := &waE2E.ContextInfo{
-				StanzaID:    request.ReplyMessageID,
-				Participant: proto.String(participantJID),
-				QuotedMessage: &waE2E.Message{
-					Conversation: proto.String(message.Content),
-				},
-			}
-
-			// Preserve forwarding flag if set
-			if request.BaseRequest.IsForwarded {
-				ctxInfo.IsForwarded = proto.Bool(true)
-				ctxInfo.ForwardingScore = proto.Uint32(100)
-			}
-
-			// Preserve disappearing message duration if provided
-			if request.BaseRequest.Duration != nil && *request.BaseRequest.Duration > 0 {
-				ctxInfo.Expiration = proto.Uint32(uint32(*request.BaseRequest.Duration))
-			} else {
-				ctxInfo.Expiration = proto.Uint32(service.getDefaultEphemeralExpiration(participantJID))
-			}
-
-			// Preserve mentions
-			if len(parsedMentions) > 0 {
-				ctxInfo.MentionedJID = parsedMentions
-			}
-
-			msg.ExtendedTextMessage = &waE2E.ExtendedTextMessage{
-				Text:        proto.String(request.Message),
-				ContextInfo: ctxInfo,
-			}
-		} else {
-			logrus.Warnf("Reply message ID %s not found in storage, continuing without reply context", *request.ReplyMessageID)
-		}
+	if request.SimulateTyping {
+		request.BaseRequest.Typing = typingDelay(text)
 	}
 
-	ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, request.Message)
+	ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, text, request.BaseRequest)
 	if err != nil {
 		return response, err
 	}
 
-	response.MessageID = ts.ID
-	response.Status = fmt.Sprintf("Message sent to %s (server timestamp: %s)", request.Phone, ts.Timestamp.String())
-	return response, nil
+	return ts.response(fmt.Sprintf("Message sent to %s", request.Phone)), nil
 }
 
 func (service serviceSend) SendImage(ctx context.Context, request domainSend.ImageRequest) (response domainSend.GenericResponse, err error) {
@@ -207,120 +221,57 @@ func (service serviceSend) SendImage(ctx context.Context, request domainSend.Ima
 		return response, err
 	}
 
-	var (
-		imagePath      string
-		imageThumbnail string
-		imageName      string
-		deletedItems   []string
-		oriImagePath   string
-	)
-
-	if request.ImageURL != nil && *request.ImageURL != "" {
-		// Download image from URL
-		imageData, fileName, err := utils.DownloadImageFromURL(*request.ImageURL)
-		if err != nil {
-			return response, pkgError.InternalServerError(fmt.Sprintf("failed to download image from URL %v", err))
-		}
-
-		// Check if the downloaded image is WebP and convert to PNG if needed
-		mimeType := http.DetectContentType(imageData)
-		if mimeType == "image/webp" {
-			// Convert WebP to PNG
-			webpImage, err := imaging.Decode(bytes.NewReader(imageData))
-			if err != nil {
-				return response, pkgError.InternalServerError(fmt.Sprintf("failed to decode WebP image %v", err))
-			}
-
-			// Change file extension to PNG
-			if strings.HasSuffix(strings.ToLower(fileName), ".webp") {
-				fileName = fileName[:len(fileName)-5] + ".png"
-			} else {
-				fileName = fileName + ".png"
-			}
-
-			// Convert to PNG format
-			var pngBuffer bytes.Buffer
-			err = imaging.Encode(&pngBuffer, webpImage, imaging.PNG)
-			if err != nil {
-				return response, pkgError.InternalServerError(fmt.Sprintf("failed to convert WebP to PNG %v", err))
-			}
-			imageData = pngBuffer.Bytes()
-		}
-
-		oriImagePath = fmt.Sprintf("%s/%s", config.PathSendItems, fileName)
-		imageName = fileName
-		err = os.WriteFile(oriImagePath, imageData, 0644)
-		if err != nil {
-			return response, pkgError.InternalServerError(fmt.Sprintf("failed to save downloaded image %v", err))
-		}
-	} else if request.Image != nil {
-		// Save image to server
-		oriImagePath = fmt.Sprintf("%s/%s", config.PathSendItems, request.Image.Filename)
-		err = fasthttp.SaveMultipartFile(request.Image, oriImagePath)
-		if err != nil {
-			return response, err
-		}
-		imageName = request.Image.Filename
-	}
-	deletedItems = append(deletedItems, oriImagePath)
-
-	/* Generate thumbnail with smalled image size */
-	srcImage, err := imaging.Open(oriImagePath)
+	imageData, imageName, err := readImage(request.Image, request.ImageURL)
 	if err != nil {
-		return response, pkgError.InternalServerError(fmt.Sprintf("Failed to open image file '%s' for thumbnail generation: %v. Possible causes: file not found, unsupported format, or permission denied.", oriImagePath, err))
+		return response, err
 	}
 
-	// Resize Thumbnail
-	resizedImage := imaging.Resize(srcImage, 100, 0, imaging.Lanczos)
-	imageThumbnail = fmt.Sprintf("%s/thumbnails-%s", config.PathSendItems, imageName)
-	if err = imaging.Save(resizedImage, imageThumbnail); err != nil {
-		return response, pkgError.InternalServerError(fmt.Sprintf("failed to save thumbnail %v", err))
+	maxDimension := config.WhatsappImageMaxDimension
+	if request.Compress && (maxDimension <= 0 || maxDimension > imageCompressDimension) {
+		maxDimension = imageCompressDimension
 	}
-	deletedItems = append(deletedItems, imageThumbnail)
-
-	if request.Compress {
-		// Resize image
-		openImageBuffer, err := imaging.Open(oriImagePath)
-		if err != nil {
-			return response, pkgError.InternalServerError(fmt.Sprintf("Failed to open image file '%s' for compression: %v. Possible causes: file not found, unsupported format, or permission denied.", oriImagePath, err))
-		}
-		newImage := imaging.Resize(openImageBuffer, 600, 0, imaging.Lanczos)
-		newImagePath := fmt.Sprintf("%s/new-%s", config.PathSendItems, imageName)
-		if err = imaging.Save(newImage, newImagePath); err != nil {
-			return response, pkgError.InternalServerError(fmt.Sprintf("failed to save image %v", err))
+	prepared, err := prepareImage(imageData, maxDimension)
+	if errors.Is(err, errAnimatedImage) {
+		// An image message would only show the first frame
+		gifPath := fmt.Sprintf("%s/%s", config.PathSendItems, fiberUtils.UUIDv4()+filepath.Base(imageName))
+		if err = os.WriteFile(gifPath, imageData, 0644); err != nil {
+			return response, pkgError.InternalServerError(fmt.Sprintf("failed to store image in server %v", err))
 		}
-		deletedItems = append(deletedItems, newImagePath)
-		imagePath = newImagePath
-	} else {
-		imagePath = oriImagePath
+		return service.sendVideoFile(ctx, client, dataWaRecipient, gifPath, domainSend.VideoRequest{
+			BaseRequest:    request.BaseRequest,
+			Caption:        request.Caption,
+			ViewOnce:       request.ViewOnce,
+			GIFPlayback:    true,
+			MentionRequest: request.MentionRequest,
+		})
 	}
-
-	// Send to WA server
-	dataWaCaption := request.Caption
-	dataWaImage, err := os.ReadFile(imagePath)
 	if err != nil {
 		return response, err
 	}
-	uploadedImage, err := service.uploadMedia(ctx, client, whatsmeow.MediaImage, dataWaImage, dataWaRecipient)
+
+	caption, mentions, err := service.resolveMentions(ctx, client, dataWaRecipient, request.Caption, request.MentionRequest)
 	if err != nil {
-		fmt.Printf("failed to upload file: %v", err)
 		return response, err
 	}
-	dataWaThumbnail, err := os.ReadFile(imageThumbnail)
+
+	// Send to WA server
+	uploadedImage, err := service.uploadMedia(ctx, client, whatsmeow.MediaImage, prepared.data, dataWaRecipient)
 	if err != nil {
-		return response, pkgError.InternalServerError(fmt.Sprintf("failed to read thumbnail %v", err))
+		return response, pkgError.InternalServerError(fmt.Sprintf("Failed to upload file: %v", err))
 	}
 
 	msg := &waE2E.Message{ImageMessage: &waE2E.ImageMessage{
-		JPEGThumbnail: dataWaThumbnail,
-		Caption:       proto.String(dataWaCaption),
+		JPEGThumbnail: prepared.thumbnail,
+		Caption:       proto.String(caption),
 		URL:           proto.String(uploadedImage.URL),
 		DirectPath:    proto.String(uploadedImage.DirectPath),
 		MediaKey:      uploadedImage.MediaKey,
-		Mimetype:      proto.String(http.DetectContentType(dataWaImage)),
+		Mimetype:      proto.String(prepared.mimeType),
 		FileEncSHA256: uploadedImage.FileEncSHA256,
 		FileSHA256:    uploadedImage.FileSHA256,
-		FileLength:    proto.Uint64(uint64(len(dataWaImage))),
+		FileLength:    proto.Uint64(uint64(len(prepared.data))),
+		Width:         proto.Uint32(uint32(prepared.width)),
+		Height:        proto.Uint32(uint32(prepared.height)),
 		ViewOnce:      proto.Bool(request.ViewOnce),
 	}}
 
@@ -339,24 +290,27 @@ func (service serviceSend) SendImage(ctx context.Context, request domainSend.Ima
 		msg.ImageMessage.ContextInfo.Expiration = proto.Uint32(uint32(*request.BaseRequest.Duration))
 	}
 
-	caption := "🖼️ Image"
-	if request.Caption != "" {
-		caption = "🖼️ " + request.Caption
-	}
-	ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, caption)
-	go func() {
-		errDelete := utils.RemoveFile(0, deletedItems...)
-		if errDelete != nil {
-			fmt.Println("error when deleting picture: ", errDelete)
+	if len(mentions) > 0 {
+		if msg.ImageMessage.ContextInfo == nil {
+			msg.ImageMessage.ContextInfo = &waE2E.ContextInfo{}
 		}
-	}()
+		msg.ImageMessage.ContextInfo.MentionedJID = mentions
+	}
+
+	if request.ViewOnce {
+		msg = viewOnce(msg)
+	}
+
+	content := "🖼️ Image"
+	if caption != "" {
+		content = "🖼️ " + caption
+	}
+	ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, content, request.BaseRequest)
 	if err != nil {
 		return response, err
 	}
 
-	response.MessageID = ts.ID
-	response.Status = fmt.Sprintf("Message sent to %s (server timestamp: %s)", request.BaseRequest.Phone, ts.Timestamp.String())
-	return response, nil
+	return ts.response(fmt.Sprintf("Message sent to %s", request.BaseRequest.Phone)), nil
 }
 
 func (service serviceSend) SendFile(ctx context.Context, request domainSend.FileRequest) (response domainSend.GenericResponse, err error) {
@@ -430,14 +384,12 @@ func (service serviceSend) SendFile(ctx context.Context, request domainSend.File
 	if request.Caption != "" {
 		caption = "📄 " + request.Caption
 	}
-	ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, caption)
+	ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, caption, request.BaseRequest)
 	if err != nil {
 		return response, err
 	}
 
-	response.MessageID = ts.ID
-	response.Status = fmt.Sprintf("Document sent to %s (server timestamp: %s)", request.BaseRequest.Phone, ts.Timestamp.String())
-	return response, nil
+	return ts.response(fmt.Sprintf("Document sent to %s", request.BaseRequest.Phone)), nil
 }
 
 func resolveDocumentMIME(filename string, fileBytes []byte) string {
@@ -479,6 +431,14 @@ func resolveAudioMIME(filename string, audioBytes []byte) string {
 	return detectedMime
 }
 
+// isOggOpus reports whether audio is an OGG file carrying Opus, which
+// WhatsApp plays as a voice note as is. The first OGG page of such files
+// holds the OpusHead identification header.
+func isOggOpus(audio []byte) bool {
+	head := audio[:min(len(audio), 64)]
+	return bytes.HasPrefix(head, []byte("OggS")) && bytes.Contains(head, []byte("OpusHead"))
+}
+
 // runFFProbe executes ffprobe with the given arguments and returns the output.
 // Returns empty output and error if ffprobe is not available or fails.
 func runFFProbe(args ...string) ([]byte, error) {
@@ -624,141 +584,163 @@ func (service serviceSend) SendVideo(ctx context.Context, request domainSend.Vid
 		return response, err
 	}
 
-	var (
-		videoPath      string
-		videoThumbnail string
-		deletedItems   []string
-	)
+	oriVideoPath, err := saveVideo(request.Video, request.VideoURL)
+	if err != nil {
+		return response, err
+	}
 
-	// Ensure temporary files are always removed, even on early returns
-	defer func() {
-		if len(deletedItems) > 0 {
-			// Run cleanup in background with slight delay to avoid race with open handles
-			go utils.RemoveFile(1, deletedItems...)
+	return service.sendVideoFile(ctx, client, dataWaRecipient, oriVideoPath, request)
+}
+
+// readImage returns the uploaded image or downloads it from imageURL, along
+// with its file name.
+func readImage(image *multipart.FileHeader, imageURL *string) (imageData []byte, imageName string, err error) {
+	if imageURL != nil && *imageURL != "" {
+		imageData, imageName, err = utils.DownloadImageFromURL(*imageURL)
+		if err != nil {
+			return nil, "", pkgError.InternalServerError(fmt.Sprintf("failed to download image from URL %v", err))
 		}
-	}()
+		return imageData, imageName, nil
+	}
+	if image == nil {
+		// This should not happen due to validation, but guard anyway
+		return nil, "", pkgError.ValidationError("either Image or ImageURL must be provided")
+	}
 
-	generateUUID := fiberUtils.UUIDv4()
+	imageFile, err := image.Open()
+	if err != nil {
+		return nil, "", pkgError.InternalServerError(fmt.Sprintf("failed to read image %v", err))
+	}
+	defer imageFile.Close()
+	if imageData, err = io.ReadAll(imageFile); err != nil {
+		return nil, "", pkgError.InternalServerError(fmt.Sprintf("failed to read image %v", err))
+	}
+	return imageData, image.Filename, nil
+}
 
-	var oriVideoPath string
+// saveVideo stores the uploaded video, or downloads it from videoURL, to the
+// send items folder and returns its path.
+func saveVideo(video *multipart.FileHeader, videoURL *string) (string, error) {
+	generateUUID := fiberUtils.UUIDv4()
 
 	// Determine source of video (URL or uploaded file)
-	if request.VideoURL != nil && *request.VideoURL != "" {
+	if videoURL != nil && *videoURL != "" {
 		// Download video bytes
-		videoBytes, fileName, errDownload := utils.DownloadVideoFromURL(*request.VideoURL)
+		videoBytes, fileName, errDownload := utils.DownloadVideoFromURL(*videoURL)
 		if errDownload != nil {
-			return response, pkgError.InternalServerError(fmt.Sprintf("failed to download video from URL %v", errDownload))
+			return "", pkgError.InternalServerError(fmt.Sprintf("failed to download video from URL %v", errDownload))
 		}
 		// Build file path to save the downloaded video temporarily
-		oriVideoPath = fmt.Sprintf("%s/%s", config.PathSendItems, generateUUID+fileName)
+		oriVideoPath := fmt.Sprintf("%s/%s", config.PathSendItems, generateUUID+fileName)
 		if errWrite := os.WriteFile(oriVideoPath, videoBytes, 0644); errWrite != nil {
-			return response, pkgError.InternalServerError(fmt.Sprintf("failed to store downloaded video in server %v", errWrite))
-		}
-	} else if request.Video != nil {
-		// Save uploaded video to server
-		oriVideoPath = fmt.Sprintf("%s/%s", config.PathSendItems, generateUUID+request.Video.Filename)
-		err = fasthttp.SaveMultipartFile(request.Video, oriVideoPath)
-		if err != nil {
-			return response, pkgError.InternalServerError(fmt.Sprintf("failed to store video in server %v", err))
+			return "", pkgError.InternalServerError(fmt.Sprintf("failed to store downloaded video in server %v", errWrite))
 		}
-	} else {
+		return oriVideoPath, nil
+	}
+	if video == nil {
 		// This should not happen due to validation, but guard anyway
-		return response, pkgError.ValidationError("either Video or VideoURL must be provided")
+		return "", pkgError.ValidationError("either Video or VideoURL must be provided")
 	}
 
-	// Check if ffmpeg is installed
-	_, err = exec.LookPath("ffmpeg")
-	if err != nil {
-		return response, pkgError.InternalServerError("ffmpeg not installed")
+	// Save uploaded video to server
+	oriVideoPath := fmt.Sprintf("%s/%s", config.PathSendItems, generateUUID+video.Filename)
+	if err := fasthttp.SaveMultipartFile(video, oriVideoPath); err != nil {
+		return "", pkgError.InternalServerError(fmt.Sprintf("failed to store video in server %v", err))
+	}
+	return oriVideoPath, nil
+}
+
+// sendVideoFile sends the video stored at oriVideoPath, transcoding it when
+// needed, and removes the file afterwards.
+func (service serviceSend) sendVideoFile(ctx context.Context, client *whatsmeow.Client, dataWaRecipient types.JID, oriVideoPath string, request domainSend.VideoRequest) (response domainSend.GenericResponse, err error) {
+	var (
+		videoPath    string
+		deletedItems = []string{oriVideoPath}
+	)
+
+	// Ensure temporary files are always removed, even on early returns
+	defer func() {
+		// Run cleanup in background with slight delay to avoid race with open handles
+		go utils.RemoveFile(1, deletedItems...)
+	}()
+
+	for _, tool := range []string{"ffmpeg", "ffprobe"} {
+		if _, err = exec.LookPath(tool); err != nil {
+			return response, pkgError.InternalServerError(fmt.Sprintf("%s is not installed, it is needed to send videos", tool))
+		}
 	}
 
-	// Generate thumbnail using ffmpeg
-	thumbnailVideoPath := fmt.Sprintf("%s/%s", config.PathSendItems, generateUUID+".png")
-	cmdThumbnail := exec.Command("ffmpeg", "-i", oriVideoPath, "-ss", "00:00:01.000", "-vframes", "1", thumbnailVideoPath)
-	err = cmdThumbnail.Run()
+	caption, mentions, err := service.resolveMentions(ctx, client, dataWaRecipient, request.Caption, request.MentionRequest)
 	if err != nil {
-		return response, pkgError.InternalServerError(fmt.Sprintf("failed to create thumbnail %v", err))
+		return response, err
 	}
 
-	// Resize Thumbnail
-	srcImage, err := imaging.Open(thumbnailVideoPath)
+	// Fail fast on files that aren't videos, before spending time on them
+	probe, err := probeVideo(oriVideoPath)
 	if err != nil {
-		return response, pkgError.InternalServerError(fmt.Sprintf("Failed to open generated video thumbnail image '%s': %v. Possible causes: file not found, unsupported format, or permission denied.", thumbnailVideoPath, err))
+		return response, err
 	}
-	resizedImage := imaging.Resize(srcImage, 100, 0, imaging.Lanczos)
-	thumbnailResizeVideoPath := fmt.Sprintf("%s/thumbnails-%s", config.PathSendItems, generateUUID+".png")
-	if err = imaging.Save(resizedImage, thumbnailResizeVideoPath); err != nil {
-		return response, pkgError.InternalServerError(fmt.Sprintf("failed to save thumbnail %v", err))
+	if request.GIFPlayback && probe.Duration > videoGIFMaxSeconds {
+		return response, pkgError.ValidationError(fmt.Sprintf("video is %.0f seconds long, GIF playback is limited to %d seconds", probe.Duration, videoGIFMaxSeconds))
 	}
 
-	deletedItems = append(deletedItems, thumbnailVideoPath)
-	deletedItems = append(deletedItems, thumbnailResizeVideoPath)
-	videoThumbnail = thumbnailResizeVideoPath
-
-	// Compress if requested
+	mimeType, err := sniffContentType(oriVideoPath)
+	if err != nil {
+		return response, err
+	}
+	maxDimension := videoMaxDimension
 	if request.Compress {
-		compresVideoPath := fmt.Sprintf("%s/%s", config.PathSendItems, generateUUID+".mp4")
-
-		// Use proper compression settings to reduce file size
-		// -crf 28: Constant Rate Factor (18-28 is good range, higher = smaller file)
-		// -preset medium: Balance between encoding speed and compression efficiency
-		// -c:v libx264: Use H.264 codec for video
-		// -c:a aac: Use AAC codec for audio
-		// -movflags +faststart: Optimize for web streaming
-		// -vf scale=720:-2: Scale video to max width 720px, maintain aspect ratio
-		cmdCompress := exec.Command("ffmpeg", "-i", oriVideoPath,
-			"-c:v", "libx264",
-			"-crf", "28",
-			"-preset", "fast",
-			"-vf", "scale=720:-2",
-			"-c:a", "aac",
-			"-b:a", "128k",
-			"-movflags", "+faststart",
-			"-y", // Overwrite output file if it exists
-			compresVideoPath)
-
-		// Capture both stdout and stderr for better error reporting
-		output, err := cmdCompress.CombinedOutput()
-		if err != nil {
-			logrus.Errorf("ffmpeg compression failed: %v, output: %s", err, string(output))
-			return response, pkgError.InternalServerError(fmt.Sprintf("failed to compress video: %v", err))
+		maxDimension = videoCompressDimension
+	}
+	videoPath = oriVideoPath
+	if request.Compress || probe.needsTranscode(mimeType, maxDimension) {
+		videoPath = fmt.Sprintf("%s/%s", config.PathSendItems, fiberUtils.UUIDv4()+".mp4")
+		deletedItems = append(deletedItems, videoPath)
+		if err = transcodeVideo(oriVideoPath, videoPath, config.WhatsappVideoCRF, maxDimension, request.GIFPlayback); err != nil {
+			logrus.WithContext(ctx).Error(err)
+			return response, err
+		}
+		if probe, err = probeVideo(videoPath); err != nil {
+			return response, err
 		}
-
-		videoPath = compresVideoPath
-		deletedItems = append(deletedItems, compresVideoPath)
-	} else {
-		videoPath = oriVideoPath
 	}
-	deletedItems = append(deletedItems, oriVideoPath)
 
 	//Send to WA server
 	dataWaVideo, err := os.ReadFile(videoPath)
 	if err != nil {
 		return response, err
 	}
-	uploaded, err := service.uploadMedia(ctx, client, whatsmeow.MediaVideo, dataWaVideo, dataWaRecipient)
+	if int64(len(dataWaVideo)) > config.WhatsappSettingMaxVideoSize {
+		return response, pkgError.InvalidMediaError(fmt.Sprintf("video is %s after transcoding, more than the %s limit; send a shorter video or raise the CRF",
+			humanize.Bytes(uint64(len(dataWaVideo))), humanize.Bytes(uint64(config.WhatsappSettingMaxVideoSize))))
+	}
+
+	// A missing thumbnail only shows a grey box, so don't fail the send over it
+	dataWaThumbnail, err := videoThumbnail(videoPath, probe.Duration)
 	if err != nil {
-		return response, pkgError.InternalServerError(fmt.Sprintf("Failed to upload file: %v", err))
+		logrus.WithContext(ctx).Warnf("Failed to create video thumbnail, sending without it: %v", err)
 	}
-	dataWaThumbnail, err := os.ReadFile(videoThumbnail)
+
+	uploaded, err := service.uploadMedia(ctx, client, whatsmeow.MediaVideo, dataWaVideo, dataWaRecipient)
 	if err != nil {
-		return response, err
+		return response, pkgError.InternalServerError(fmt.Sprintf("Failed to upload file: %v", err))
 	}
 
 	msg := &waE2E.Message{VideoMessage: &waE2E.VideoMessage{
-		URL:                 proto.String(uploaded.URL),
-		Mimetype:            proto.String(http.DetectContentType(dataWaVideo)),
-		Caption:             proto.String(request.Caption),
-		FileLength:          proto.Uint64(uploaded.FileLength),
-		FileSHA256:          uploaded.FileSHA256,
-		FileEncSHA256:       uploaded.FileEncSHA256,
-		MediaKey:            uploaded.MediaKey,
-		DirectPath:          proto.String(uploaded.DirectPath),
-		ViewOnce:            proto.Bool(request.ViewOnce),
-		JPEGThumbnail:       dataWaThumbnail,
-		ThumbnailEncSHA256:  dataWaThumbnail,
-		ThumbnailSHA256:     dataWaThumbnail,
-		ThumbnailDirectPath: proto.String(uploaded.DirectPath),
+		URL:           proto.String(uploaded.URL),
+		Mimetype:      proto.String("video/mp4"),
+		Caption:       proto.String(caption),
+		FileLength:    proto.Uint64(uploaded.FileLength),
+		FileSHA256:    uploaded.FileSHA256,
+		FileEncSHA256: uploaded.FileEncSHA256,
+		MediaKey:      uploaded.MediaKey,
+		DirectPath:    proto.String(uploaded.DirectPath),
+		ViewOnce:      proto.Bool(request.ViewOnce),
+		JPEGThumbnail: dataWaThumbnail,
+		Seconds:       proto.Uint32(uint32(math.Round(probe.Duration))),
+		Width:         proto.Uint32(uint32(probe.Width)),
+		Height:        proto.Uint32(uint32(probe.Height)),
+		GifPlayback:   proto.Bool(request.GIFPlayback),
 	}}
 
 	if request.BaseRequest.IsForwarded {
@@ -775,18 +757,27 @@ func (service serviceSend) SendVideo(ctx context.Context, request domainSend.Vid
 		msg.VideoMessage.ContextInfo.Expiration = proto.Uint32(uint32(*request.BaseRequest.Duration))
 	}
 
-	caption := "🎥 Video"
-	if request.Caption != "" {
-		caption = "🎥 " + request.Caption
+	if len(mentions) > 0 {
+		if msg.VideoMessage.ContextInfo == nil {
+			msg.VideoMessage.ContextInfo = &waE2E.ContextInfo{}
+		}
+		msg.VideoMessage.ContextInfo.MentionedJID = mentions
+	}
+
+	if request.ViewOnce {
+		msg = viewOnce(msg)
+	}
+
+	content := "🎥 Video"
+	if caption != "" {
+		content = "🎥 " + caption
 	}
-	ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, caption)
+	ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, content, request.BaseRequest)
 	if err != nil {
 		return response, err
 	}
 
-	response.MessageID = ts.ID
-	response.Status = fmt.Sprintf("Video sent to %s (server timestamp: %s)", request.BaseRequest.Phone, ts.Timestamp.String())
-	return response, nil
+	return ts.response(fmt.Sprintf("Video sent to %s", request.BaseRequest.Phone)), nil
 }
 
 func (service serviceSend) SendContact(ctx context.Context, request domainSend.ContactRequest) (response domainSend.GenericResponse, err error) {
@@ -805,37 +796,70 @@ func (service serviceSend) SendContact(ctx context.Context, request domainSend.C
 		return response, err
 	}
 
-	msgVCard := fmt.Sprintf("BEGIN:VCARD\nVERSION:3.0\nN:;%v;;;\nFN:%v\nTEL;type=CELL;waid=%v:+%v\nEND:VCARD",
-		request.ContactName, request.ContactName, request.ContactPhone, request.ContactPhone)
-	msg := &waE2E.Message{ContactMessage: &waE2E.ContactMessage{
-		DisplayName: proto.String(request.ContactName),
-		Vcard:       proto.String(msgVCard),
-	}}
+	msg, content := buildContactMessage(request)
+	contextInfo := messageContextInfo(msg)
 
 	if request.BaseRequest.IsForwarded {
-		msg.ContactMessage.ContextInfo = &waE2E.ContextInfo{
+		*contextInfo = &waE2E.ContextInfo{
 			IsForwarded:     proto.Bool(true),
 			ForwardingScore: proto.Uint32(100),
 		}
 	}
 
 	if request.BaseRequest.Duration != nil && *request.BaseRequest.Duration > 0 {
-		if msg.ContactMessage.ContextInfo == nil {
-			msg.ContactMessage.ContextInfo = &waE2E.ContextInfo{}
+		if *contextInfo == nil {
+			*contextInfo = &waE2E.ContextInfo{}
 		}
-		msg.ContactMessage.ContextInfo.Expiration = proto.Uint32(uint32(*request.BaseRequest.Duration))
+		(*contextInfo).Expiration = proto.Uint32(uint32(*request.BaseRequest.Duration))
 	}
 
-	content := "👤 " + request.ContactName
-
-	ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, content)
+	ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, content, request.BaseRequest)
 	if err != nil {
 		return response, err
 	}
 
-	response.MessageID = ts.ID
-	response.Status = fmt.Sprintf("Contact sent to %s (server timestamp: %s)", request.BaseRequest.Phone, ts.Timestamp.String())
-	return response, nil
+	return ts.response(fmt.Sprintf("Contact sent to %s", request.BaseRequest.Phone)), nil
+}
+
+// buildContactMessage renders the cards of request as vCards: a contact
+// message for one card, a contacts array message for several.
+func buildContactMessage(request domainSend.ContactRequest) (*waE2E.Message, string) {
+	cards := request.Contacts
+	if len(cards) == 0 {
+		cards = []domainSend.ContactCard{{
+			ContactName:   request.ContactName,
+			ContactPhone:  request.ContactPhone,
+			ContactPhones: request.ContactPhones,
+			Organization:  request.Organization,
+		}}
+	}
+
+	contacts := make([]*waE2E.ContactMessage, len(cards))
+	names := make([]string, len(cards))
+	for i, card := range cards {
+		phones := make([]string, 0, len(card.ContactPhones)+1)
+		for _, phone := range append([]string{card.ContactPhone}, card.ContactPhones...) {
+			phones = append(phones, utils.NormalizePhoneDigits(phone))
+		}
+		contacts[i] = &waE2E.ContactMessage{
+			DisplayName: proto.String(card.ContactName),
+			Vcard: proto.String(utils.BuildVCard(utils.VCard{
+				DisplayName:  card.ContactName,
+				PhoneNumbers: utils.UniqueStrings(phones),
+				Organization: card.Organization,
+			})),
+		}
+		names[i] = card.ContactName
+	}
+
+	if len(contacts) == 1 {
+		return &waE2E.Message{ContactMessage: contacts[0]}, "👤 " + names[0]
+	}
+	displayName := fmt.Sprintf("%d contacts", len(contacts))
+	return &waE2E.Message{ContactsArrayMessage: &waE2E.ContactsArrayMessage{
+		DisplayName: proto.String(displayName),
+		Contacts:    contacts,
+	}}, "👥 " + strings.Join(names, ", ")
 }
 
 func (service serviceSend) SendLink(ctx context.Context, request domainSend.LinkRequest) (response domainSend.GenericResponse, err error) {
@@ -861,9 +885,9 @@ func (service serviceSend) SendLink(ctx context.Context, request domainSend.Link
 
 	// Log image dimensions if available, otherwise note it's a square image or dimensions not available
 	if metadata.Width != nil && metadata.Height != nil {
-		logrus.Debugf("Image dimensions: %dx%d", *metadata.Width, *metadata.Height)
+		logrus.WithContext(ctx).Debugf("Image dimensions: %dx%d", *metadata.Width, *metadata.Height)
 	} else {
-		logrus.Debugf("Image dimensions: Square image or dimensions not available")
+		logrus.WithContext(ctx).Debugf("Image dimensions: Square image or dimensions not available")
 	}
 
 	// Create the message
@@ -901,7 +925,7 @@ func (service serviceSend) SendLink(ctx context.Context, request domainSend.Link
 			msg.ExtendedTextMessage.ThumbnailHeight = metadata.Height
 			msg.ExtendedTextMessage.ThumbnailWidth = metadata.Width
 		} else {
-			logrus.Warnf("Failed to upload thumbnail: %v, continue without uploaded thumbnail", err)
+			logrus.WithContext(ctx).Warnf("Failed to upload thumbnail: %v, continue without uploaded thumbnail", err)
 		}
 	}
 
@@ -909,14 +933,12 @@ func (service serviceSend) SendLink(ctx context.Context, request domainSend.Link
 	if request.Caption != "" {
 		content = "🔗 " + request.Caption
 	}
-	ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, content)
+	ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, content, request.BaseRequest)
 	if err != nil {
 		return response, err
 	}
 
-	response.MessageID = ts.ID
-	response.Status = fmt.Sprintf("Link sent to %s (server timestamp: %s)", request.BaseRequest.Phone, ts.Timestamp.String())
-	return response, nil
+	return ts.response(fmt.Sprintf("Link sent to %s", request.BaseRequest.Phone)), nil
 }
 
 func (service serviceSend) SendLocation(ctx context.Context, request domainSend.LocationRequest) (response domainSend.GenericResponse, err error) {
@@ -935,13 +957,22 @@ func (service serviceSend) SendLocation(ctx context.Context, request domainSend.
 		return response, err
 	}
 
+	latitude, longitude := utils.StrToFloat64(request.Latitude), utils.StrToFloat64(request.Longitude)
+
 	// Compose WhatsApp Proto
 	msg := &waE2E.Message{
 		LocationMessage: &waE2E.LocationMessage{
-			DegreesLatitude:  proto.Float64(utils.StrToFloat64(request.Latitude)),
-			DegreesLongitude: proto.Float64(utils.StrToFloat64(request.Longitude)),
+			DegreesLatitude:  proto.Float64(latitude),
+			DegreesLongitude: proto.Float64(longitude),
+			JPEGThumbnail:    locationThumbnail(ctx, latitude, longitude),
 		},
 	}
+	if request.Name != "" {
+		msg.LocationMessage.Name = proto.String(request.Name)
+	}
+	if request.Address != "" {
+		msg.LocationMessage.Address = proto.String(request.Address)
+	}
 
 	if request.BaseRequest.IsForwarded {
 		msg.LocationMessage.ContextInfo = &waE2E.ContextInfo{
@@ -958,16 +989,74 @@ func (service serviceSend) SendLocation(ctx context.Context, request domainSend.
 	}
 
 	content := "📍 " + request.Latitude + ", " + request.Longitude
+	if request.Name != "" {
+		content = "📍 " + request.Name
+	}
 
 	// Send WhatsApp Message Proto
-	ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, content)
+	ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, content, request.BaseRequest)
 	if err != nil {
 		return response, err
 	}
 
-	response.MessageID = ts.ID
-	response.Status = fmt.Sprintf("Send location success %s (server timestamp: %s)", request.BaseRequest.Phone, ts.Timestamp.String())
-	return response, nil
+	return ts.response(fmt.Sprintf("Send location success %s", request.BaseRequest.Phone)), nil
+}
+
+// SendLiveLocation starts sharing a live location. WhatsApp's live location
+// message carries no share length, so LiveDuration only labels the share in
+// chat history; the share ends on the recipient's side once no update follows.
+func (service serviceSend) SendLiveLocation(ctx context.Context, request domainSend.LiveLocationRequest) (response domainSend.GenericResponse, err error) {
+	err = validations.ValidateSendLiveLocation(ctx, request)
+	if err != nil {
+		return response, err
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+
+	dataWaRecipient, err := utils.ValidateJidWithLogin(client, request.BaseRequest.Phone)
+	if err != nil {
+		return response, err
+	}
+
+	latitude, longitude := utils.StrToFloat64(request.Latitude), utils.StrToFloat64(request.Longitude)
+	msg := &waE2E.Message{
+		LiveLocationMessage: &waE2E.LiveLocationMessage{
+			DegreesLatitude:  proto.Float64(latitude),
+			DegreesLongitude: proto.Float64(longitude),
+			SequenceNumber:   proto.Int64(time.Now().UnixMilli()),
+			JPEGThumbnail:    locationThumbnail(ctx, latitude, longitude),
+			ContextInfo:      &waE2E.ContextInfo{},
+		},
+	}
+	if request.Caption != "" {
+		msg.LiveLocationMessage.Caption = proto.String(request.Caption)
+	}
+	if request.AccuracyInMeters > 0 {
+		msg.LiveLocationMessage.AccuracyInMeters = proto.Uint32(uint32(request.AccuracyInMeters))
+	}
+	if request.BaseRequest.IsForwarded {
+		msg.LiveLocationMessage.ContextInfo.IsForwarded = proto.Bool(true)
+		msg.LiveLocationMessage.ContextInfo.ForwardingScore = proto.Uint32(100)
+	}
+	if request.BaseRequest.Duration != nil && *request.BaseRequest.Duration > 0 {
+		msg.LiveLocationMessage.ContextInfo.Expiration = proto.Uint32(uint32(*request.BaseRequest.Duration))
+	}
+
+	liveFor := time.Duration(request.LiveDuration) * time.Second
+	content := fmt.Sprintf("📍 Live location for %s", liveFor)
+	if request.Caption != "" {
+		content += ": " + request.Caption
+	}
+
+	ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, content, request.BaseRequest)
+	if err != nil {
+		return response, err
+	}
+
+	return ts.response(fmt.Sprintf("Send live location success %s", request.BaseRequest.Phone)), nil
 }
 
 func (service serviceSend) SendAudio(ctx context.Context, request domainSend.AudioRequest) (response domainSend.GenericResponse, err error) {
@@ -1001,7 +1090,7 @@ func (service serviceSend) SendAudio(ctx context.Context, request domainSend.Aud
 	defer func() {
 		for _, path := range deletedItems {
 			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
-				logrus.Warnf("Failed to cleanup temporary audio file %s: %v", path, err)
+				logrus.WithContext(ctx).Warnf("Failed to cleanup temporary audio file %s: %v", path, err)
 			}
 		}
 	}()
@@ -1049,18 +1138,22 @@ func (service serviceSend) SendAudio(ctx context.Context, request domainSend.Aud
 		waveformData = generateWaveform(tempAudioPath)
 	}
 
+	// Long recordings are refused before the costly conversion
+	if request.PTT && config.WhatsappVoiceNoteMaxDuration > 0 {
+		if duration := time.Duration(audioDuration) * time.Second; duration > config.WhatsappVoiceNoteMaxDuration {
+			return response, pkgError.ValidationError(fmt.Sprintf("voice note is %s long, longer than the maximum of %s", duration, config.WhatsappVoiceNoteMaxDuration))
+		}
+	}
+
 	// If PTT is requested, convert audio to OGG Opus format for WhatsApp voice note compatibility
 	// WhatsApp clients require OGG Opus format for voice notes to play correctly
 	if request.PTT {
-		// Check if already OGG format - skip conversion
-		isAlreadyOgg := strings.HasPrefix(audioMimeType, "audio/ogg") ||
-			strings.HasPrefix(audioMimeType, "application/ogg")
-
-		if !isAlreadyOgg {
+		// Already OGG Opus - skip conversion. Other OGG codecs such as Vorbis still need it.
+		if !isOggOpus(audioBytes) {
 			// Check if ffmpeg is installed
 			_, err := exec.LookPath("ffmpeg")
 			if err != nil {
-				return response, pkgError.InternalServerError("ffmpeg not installed (required for PTT voice notes)")
+				return response, pkgError.InternalServerError("ffmpeg is not installed; it is required to convert audio to a voice note (ptt), or send OGG Opus audio instead")
 			}
 
 			// Get absolute base directory for temporary files
@@ -1109,7 +1202,7 @@ func (service serviceSend) SendAudio(ctx context.Context, request domainSend.Aud
 			cmdConvert.Stderr = &stderr
 
 			if err := cmdConvert.Run(); err != nil {
-				logrus.Errorf("ffmpeg PTT conversion failed: %v, stderr: %s", err, stderr.String())
+				logrus.WithContext(ctx).Errorf("ffmpeg PTT conversion failed: %v, stderr: %s", err, stderr.String())
 				return response, pkgError.InternalServerError(fmt.Sprintf("failed to convert audio to OGG Opus for PTT: %v", err))
 			}
 
@@ -1122,7 +1215,7 @@ func (service serviceSend) SendAudio(ctx context.Context, request domainSend.Aud
 			// Update MIME type to OGG Opus
 			audioMimeType = "audio/ogg; codecs=opus"
 
-			logrus.Infof("Converted audio to OGG Opus for PTT: %d bytes", len(audioBytes))
+			logrus.WithContext(ctx).Infof("Converted audio to OGG Opus for PTT: %d bytes", len(audioBytes))
 		} else {
 			// Already OGG format, ensure MIME type is correctly set
 			audioMimeType = "audio/ogg; codecs=opus"
@@ -1167,17 +1260,19 @@ func (service serviceSend) SendAudio(ctx context.Context, request domainSend.Aud
 
 	content := "🎵 Audio"
 
-	ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, content)
+	ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, content, request.BaseRequest)
 	if err != nil {
 		return response, err
 	}
 
-	response.MessageID = ts.ID
-	response.Status = fmt.Sprintf("Send audio success %s (server timestamp: %s)", request.BaseRequest.Phone, ts.Timestamp.String())
-	return response, nil
+	return ts.response(fmt.Sprintf("Send audio success %s", request.BaseRequest.Phone)), nil
 }
 
 func (service serviceSend) SendPoll(ctx context.Context, request domainSend.PollRequest) (response domainSend.GenericResponse, err error) {
+	if request.MaxAnswer == 0 {
+		request.MaxAnswer = request.MaxAnswers
+	}
+
 	err = validations.ValidateSendPoll(ctx, request)
 	if err != nil {
 		return response, err
@@ -1204,14 +1299,25 @@ func (service serviceSend) SendPoll(ctx context.Context, request domainSend.Poll
 		msg.PollCreationMessage.ContextInfo.Expiration = proto.Uint32(uint32(*request.BaseRequest.Duration))
 	}
 
-	ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, content)
+	ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, content, request.BaseRequest)
 	if err != nil {
 		return response, err
 	}
 
-	response.MessageID = ts.ID
-	response.Status = fmt.Sprintf("Send poll success %s (server timestamp: %s)", request.BaseRequest.Phone, ts.Timestamp.String())
-	return response, nil
+	// Keep the options so votes, which only carry option hashes, can be decoded
+	poll := &domainChatStorage.Poll{
+		MessageID:       ts.ID,
+		ChatJID:         dataWaRecipient.String(),
+		DeviceID:        deviceIDFromContext(ctx),
+		Question:        request.Question,
+		Options:         request.Options,
+		SelectableCount: request.MaxAnswer,
+	}
+	if err := service.chatStorageRepo.StorePoll(ctx, poll); err != nil {
+		logrus.WithContext(ctx).Warnf("Failed to store sent poll %s: %v", ts.ID, err)
+	}
+
+	return ts.response(fmt.Sprintf("Send poll success %s", request.BaseRequest.Phone)), nil
 }
 
 func (service serviceSend) SendPresence(ctx context.Context, request domainSend.PresenceRequest) (response domainSend.GenericResponse, err error) {
@@ -1295,24 +1401,15 @@ func (service serviceSend) getMentionFromText(ctx context.Context, messages stri
 }
 
 // getMentionsFromList converts a list of phone numbers to JIDs for ghost mentions
-// Special keyword "@everyone" will fetch all group participants
-func (service serviceSend) getMentionsFromList(ctx context.Context, mentions []string, recipientJID types.JID) (result []string) {
+// outside groups. The "@everyone" keyword only applies to groups and is skipped.
+func (service serviceSend) getMentionsFromList(ctx context.Context, mentions []string) (result []string) {
 	client := whatsapp.ClientFromContext(ctx)
 	if client == nil {
 		return result
 	}
 
 	for _, mention := range mentions {
-		// Handle @everyone keyword - fetch all group participants
 		if mention == "@everyone" {
-			if recipientJID.Server == types.GroupServer {
-				groupInfo, err := client.GetGroupInfo(ctx, recipientJID)
-				if err == nil && groupInfo != nil {
-					for _, participant := range groupInfo.Participants {
-						result = append(result, participant.JID.String())
-					}
-				}
-			}
 			continue
 		}
 
@@ -1357,7 +1454,7 @@ func (service serviceSend) SendSticker(ctx context.Context, request domainSend.S
 		// Delete temporary files
 		for _, path := range deletedItems {
 			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
-				logrus.Warnf("Failed to cleanup temporary file %s: %v", path, err)
+				logrus.WithContext(ctx).Warnf("Failed to cleanup temporary file %s: %v", path, err)
 			}
 		}
 	}()
@@ -1404,10 +1501,10 @@ func (service serviceSend) SendSticker(ctx context.Context, request domainSend.S
 	defer infoCancel()
 	isAnimatedSticker, webpWidth, webpHeight := getWebPInfo(infoCtx, stickerPath)
 	if isAnimatedSticker {
-		logrus.Info("Detected animated WebP sticker")
+		logrus.WithContext(ctx).Info("Detected animated WebP sticker")
 
 		// Validate dimensions - must be exactly 512x512 for animated stickers
-		if webpWidth != 512 || webpHeight != 512 {
+		if webpWidth != stickerSize || webpHeight != stickerSize {
 			return response, pkgError.ValidationError(
 				fmt.Sprintf("animated WebP stickers must be exactly 512x512 pixels (got %dx%d). Please resize your sticker before uploading.", webpWidth, webpHeight))
 		}
@@ -1417,7 +1514,7 @@ func (service serviceSend) SendSticker(ctx context.Context, request domainSend.S
 		if statErr != nil {
 			return response, pkgError.InternalServerError(fmt.Sprintf("failed to stat sticker file: %v", statErr))
 		}
-		if fileInfo.Size() > 500*1024 {
+		if fileInfo.Size() > maxAnimatedStickerSize {
 			return response, pkgError.ValidationError(
 				fmt.Sprintf("animated WebP stickers must be under 500KB (got %d KB). Please reduce the file size.", fileInfo.Size()/1024))
 		}
@@ -1428,62 +1525,29 @@ func (service serviceSend) SendSticker(ctx context.Context, request domainSend.S
 			return response, pkgError.InternalServerError(fmt.Sprintf("failed to read animated sticker: %v", err))
 		}
 
-		logrus.Infof("Using animated WebP sticker directly: %dx%d, %d bytes", webpWidth, webpHeight, len(stickerBytes))
-
-		// Upload sticker to WhatsApp servers
-		stickerUploaded, err := service.uploadMedia(ctx, client, whatsmeow.MediaImage, stickerBytes, dataWaRecipient)
-		if err != nil {
-			return response, pkgError.WaUploadMediaError(fmt.Sprintf("failed to upload sticker: %v", err))
-		}
-
-		// Create animated sticker message
-		msg := &waE2E.Message{
-			StickerMessage: &waE2E.StickerMessage{
-				URL:           proto.String(stickerUploaded.URL),
-				DirectPath:    proto.String(stickerUploaded.DirectPath),
-				Mimetype:      proto.String("image/webp"),
-				FileLength:    proto.Uint64(stickerUploaded.FileLength),
-				FileSHA256:    stickerUploaded.FileSHA256,
-				FileEncSHA256: stickerUploaded.FileEncSHA256,
-				MediaKey:      stickerUploaded.MediaKey,
-				Width:         proto.Uint32(uint32(webpWidth)),
-				Height:        proto.Uint32(uint32(webpHeight)),
-				IsAnimated:    proto.Bool(true),
-			},
-		}
-
-		if request.BaseRequest.IsForwarded {
-			msg.StickerMessage.ContextInfo = &waE2E.ContextInfo{
-				IsForwarded:     proto.Bool(true),
-				ForwardingScore: proto.Uint32(100),
-			}
-		}
+		logrus.WithContext(ctx).Infof("Using animated WebP sticker directly: %dx%d, %d bytes", webpWidth, webpHeight, len(stickerBytes))
+		return service.sendStickerMessage(ctx, client, dataWaRecipient, request, stickerBytes, webpWidth, webpHeight, true)
+	}
 
-		if request.BaseRequest.Duration != nil && *request.BaseRequest.Duration > 0 {
-			if msg.StickerMessage.ContextInfo == nil {
-				msg.StickerMessage.ContextInfo = &waE2E.ContextInfo{}
-			}
-			msg.StickerMessage.ContextInfo.Expiration = proto.Uint32(uint32(*request.BaseRequest.Duration))
-		}
+	// Animated GIFs are converted to animated WebP so they keep playing
+	if isAnimatedGIF(stickerPath) {
+		logrus.WithContext(ctx).Info("Detected animated GIF sticker")
 
-		content := "🎨 Animated Sticker"
+		webpPath := filepath.Join(absBaseDir, fmt.Sprintf("sticker_%s.webp", fiberUtils.UUIDv4()))
+		deletedItems = append(deletedItems, webpPath)
 
-		// Send the animated sticker message
-		ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, content)
+		stickerBytes, err = convertAnimatedGIFSticker(ctx, stickerPath, webpPath)
 		if err != nil {
 			return response, err
 		}
-
-		response.MessageID = ts.ID
-		response.Status = fmt.Sprintf("Animated sticker sent to %s (server timestamp: %s)", request.Phone, ts.Timestamp.String())
-		return response, nil
+		return service.sendStickerMessage(ctx, client, dataWaRecipient, request, stickerBytes, stickerSize, stickerSize, true)
 	}
 
 	// Convert image to WebP format for sticker (512x512 max size)
 	srcImage, err := imaging.Open(stickerPath)
 	if err != nil {
 		// Fallback for animated WebP (imaging.Open doesn't support animated WebP)
-		logrus.Warnf("imaging.Open failed for %s: %v. Trying animated WebP fallback...", stickerPath, err)
+		logrus.WithContext(ctx).Warnf("imaging.Open failed for %s: %v. Trying animated WebP fallback...", stickerPath, err)
 
 		fallbackPngPath := filepath.Join(absBaseDir, fmt.Sprintf("fallback_%s.png", fiberUtils.UUIDv4()))
 		deletedItems = append(deletedItems, fallbackPngPath)
@@ -1501,7 +1565,7 @@ func (service serviceSend) SendSticker(ctx context.Context, request domainSend.S
 		// Try webpmux + dwebp for animated WebP (extract first frame)
 		if _, lookErr := exec.LookPath("webpmux"); lookErr == nil {
 			if _, lookErr := exec.LookPath("dwebp"); lookErr == nil {
-				logrus.Info("Trying webpmux to extract first frame from animated WebP...")
+				logrus.WithContext(ctx).Info("Trying webpmux to extract first frame from animated WebP...")
 				extractedFramePath := filepath.Join(absBaseDir, fmt.Sprintf("frame_%s.webp", fiberUtils.UUIDv4()))
 				deletedItems = append(deletedItems, extractedFramePath)
 
@@ -1515,12 +1579,12 @@ func (service serviceSend) SendSticker(ctx context.Context, request domainSend.S
 					cmdDwebp.Stderr = &stderrDwebp
 					if errDwebp := cmdDwebp.Run(); errDwebp == nil {
 						conversionSuccess = true
-						logrus.Info("webpmux + dwebp conversion successful for animated WebP")
+						logrus.WithContext(ctx).Info("webpmux + dwebp conversion successful for animated WebP")
 					} else {
-						logrus.Errorf("dwebp failed on extracted frame: %v, stderr: %s", errDwebp, stderrDwebp.String())
+						logrus.WithContext(ctx).Errorf("dwebp failed on extracted frame: %v, stderr: %s", errDwebp, stderrDwebp.String())
 					}
 				} else {
-					logrus.Errorf("webpmux frame extraction failed: %v, stderr: %s", errWebpmux, stderrWebpmux.String())
+					logrus.WithContext(ctx).Errorf("webpmux frame extraction failed: %v, stderr: %s", errWebpmux, stderrWebpmux.String())
 				}
 			}
 		}
@@ -1533,7 +1597,7 @@ func (service serviceSend) SendSticker(ctx context.Context, request domainSend.S
 		if err != nil {
 			return response, pkgError.InternalServerError(fmt.Sprintf("failed to open fallback PNG image: %v", err))
 		}
-		logrus.Info("Fallback conversion successful")
+		logrus.WithContext(ctx).Info("Fallback conversion successful")
 	}
 
 	// Resize image to max 512x512 maintaining aspect ratio
@@ -1549,6 +1613,9 @@ func (service serviceSend) SendSticker(ctx context.Context, request domainSend.S
 		}
 	}
 
+	// Center the image on a transparent 512x512 canvas, the size WhatsApp expects
+	srcImage = padStickerCanvas(srcImage)
+
 	// Convert to WebP using external command (ffmpeg or cwebp)
 	webpPath := filepath.Join(absBaseDir, fmt.Sprintf("sticker_%s.webp", fiberUtils.UUIDv4()))
 	deletedItems = append(deletedItems, webpPath)
@@ -1594,8 +1661,13 @@ func (service serviceSend) SendSticker(ctx context.Context, request domainSend.S
 		return response, pkgError.InternalServerError(fmt.Sprintf("failed to read WebP sticker: %v", err))
 	}
 
+	return service.sendStickerMessage(ctx, client, dataWaRecipient, request, stickerBytes, srcImage.Bounds().Dx(), srcImage.Bounds().Dy(), false)
+}
+
+// sendStickerMessage uploads an already converted WebP sticker and sends it.
+func (service serviceSend) sendStickerMessage(ctx context.Context, client *whatsmeow.Client, recipient types.JID, request domainSend.StickerRequest, stickerBytes []byte, width, height int, animated bool) (response domainSend.GenericResponse, err error) {
 	// Upload sticker to WhatsApp servers
-	stickerUploaded, err := service.uploadMedia(ctx, client, whatsmeow.MediaImage, stickerBytes, dataWaRecipient)
+	stickerUploaded, err := service.uploadMedia(ctx, client, whatsmeow.MediaImage, stickerBytes, recipient)
 	if err != nil {
 		return response, pkgError.WaUploadMediaError(fmt.Sprintf("failed to upload sticker: %v", err))
 	}
@@ -1610,9 +1682,9 @@ func (service serviceSend) SendSticker(ctx context.Context, request domainSend.S
 			FileSHA256:    stickerUploaded.FileSHA256,
 			FileEncSHA256: stickerUploaded.FileEncSHA256,
 			MediaKey:      stickerUploaded.MediaKey,
-			Width:         proto.Uint32(uint32(srcImage.Bounds().Dx())),
-			Height:        proto.Uint32(uint32(srcImage.Bounds().Dy())),
-			IsAnimated:    proto.Bool(false),
+			Width:         proto.Uint32(uint32(width)),
+			Height:        proto.Uint32(uint32(height)),
+			IsAnimated:    proto.Bool(animated),
 		},
 	}
 
@@ -1631,16 +1703,19 @@ func (service serviceSend) SendSticker(ctx context.Context, request domainSend.S
 	}
 
 	content := "🎨 Sticker"
+	label := "Sticker"
+	if animated {
+		content = "🎨 Animated Sticker"
+		label = "Animated sticker"
+	}
 
 	// Send the sticker message
-	ts, err := service.wrapSendMessage(ctx, client, dataWaRecipient, msg, content)
+	ts, err := service.wrapSendMessage(ctx, client, recipient, msg, content, request.BaseRequest)
 	if err != nil {
 		return response, err
 	}
 
-	response.MessageID = ts.ID
-	response.Status = fmt.Sprintf("Sticker sent to %s (server timestamp: %s)", request.Phone, ts.Timestamp.String())
-	return response, nil
+	return ts.response(fmt.Sprintf("%s sent to %s", label, request.Phone)), nil
 }
 
 func (service serviceSend) uploadMedia(ctx context.Context, client *whatsmeow.Client, mediaType whatsmeow.MediaType, media []byte, recipient types.JID) (uploaded whatsmeow.UploadResponse, err error) {
@@ -1680,7 +1755,7 @@ func getWebPInfo(ctx context.Context, filePath string) (isAnimated bool, width i
 		width, errW = strconv.Atoi(matches[1])
 		height, errH = strconv.Atoi(matches[2])
 		if errW != nil || errH != nil {
-			logrus.Warnf("Failed to parse WebP dimensions from '%s': width=%v, height=%v", outputStr, errW, errH)
+			logrus.WithContext(ctx).Warnf("Failed to parse WebP dimensions from '%s': width=%v, height=%v", outputStr, errW, errH)
 			return isAnimated, 0, 0
 		}
 	}
@@ -1688,20 +1763,126 @@ func getWebPInfo(ctx context.Context, filePath string) (isAnimated bool, width i
 	return isAnimated, width, height
 }
 
-func (service serviceSend) getDefaultEphemeralExpiration(jid string) (expiration uint32) {
-	expiration = 0
-	if jid == "" {
-		return expiration
-	}
+// stickerSize is the width and height of the canvas WhatsApp renders stickers on.
+const stickerSize = 512
 
-	chat, err := service.chatStorageRepo.GetChat(jid)
+// maxAnimatedStickerSize is the largest animated sticker WhatsApp accepts.
+const maxAnimatedStickerSize = 500 * 1024
+
+// padStickerCanvas centers img on a transparent stickerSize x stickerSize
+// canvas. img must already fit inside the canvas.
+func padStickerCanvas(img image.Image) image.Image {
+	canvas := imaging.New(stickerSize, stickerSize, color.NRGBA{})
+	return imaging.PasteCenter(canvas, img)
+}
+
+// isAnimatedGIF reports whether the file is a GIF with more than one frame.
+func isAnimatedGIF(filePath string) bool {
+	f, err := os.Open(filePath)
 	if err != nil {
-		return expiration
+		return false
+	}
+	defer f.Close()
+
+	header := make([]byte, 6)
+	if _, err := io.ReadFull(f, header); err != nil || !bytes.HasPrefix(header, []byte("GIF8")) {
+		return false
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return false
+	}
+
+	anim, err := gif.DecodeAll(f)
+	return err == nil && len(anim.Image) > 1
+}
+
+// convertAnimatedGIFSticker converts an animated GIF into an animated 512x512
+// WebP at webpPath, lowering the quality until it fits the sticker size limit.
+func convertAnimatedGIFSticker(ctx context.Context, gifPath, webpPath string) ([]byte, error) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return nil, pkgError.InternalServerError("ffmpeg is required to convert animated GIF stickers")
+	}
+
+	convCtx, cancel := context.WithTimeout(ctx, 90*time.Second)
+	defer cancel()
+
+	filter := fmt.Sprintf("fps=15,scale=%[1]d:%[1]d:force_original_aspect_ratio=decrease:flags=lanczos,format=rgba,pad=%[1]d:%[1]d:(ow-iw)/2:(oh-ih)/2:color=0x00000000", stickerSize)
+	for _, quality := range []string{"60", "40", "20"} {
+		cmd := exec.CommandContext(convCtx, "ffmpeg", "-y", "-i", gifPath, "-vf", filter, "-vcodec", "libwebp", "-lossless", "0", "-compression_level", "6", "-q:v", quality, "-loop", "0", "-an", "-vsync", "0", webpPath)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, pkgError.InternalServerError(fmt.Sprintf("failed to convert animated sticker to WebP: %v, stderr: %s", err, stderr.String()))
+		}
+
+		stickerBytes, err := os.ReadFile(webpPath)
+		if err != nil {
+			return nil, pkgError.InternalServerError(fmt.Sprintf("failed to read animated sticker: %v", err))
+		}
+		if len(stickerBytes) <= maxAnimatedStickerSize {
+			logrus.WithContext(ctx).Infof("Converted animated GIF sticker at quality %s: %d bytes", quality, len(stickerBytes))
+			return stickerBytes, nil
+		}
 	}
 
-	if chat != nil && chat.EphemeralExpiration != 0 {
-		expiration = chat.EphemeralExpiration
+	return nil, pkgError.ValidationError(fmt.Sprintf("animated sticker is larger than %d KB after conversion. Please use a shorter or smaller GIF.", maxAnimatedStickerSize/1024))
+}
+
+// applyChatExpiration sets the disappearing message timer stored for the chat
+// on msg, unless the request already chose a duration, so sent messages follow
+// the timer the chat members agreed on.
+func (service serviceSend) applyChatExpiration(ctx context.Context, recipient types.JID, msg *waE2E.Message) {
+	contextInfo := messageContextInfo(msg)
+	if contextInfo == nil || (*contextInfo).GetExpiration() != 0 {
+		return
+	}
+
+	chat, err := service.chatStorageRepo.GetChatByDevice(ctx, deviceIDFromContext(ctx), recipient.ToNonAD().String())
+	if err != nil || chat == nil || chat.EphemeralExpiration == 0 {
+		return
 	}
+	if *contextInfo == nil {
+		*contextInfo = &waE2E.ContextInfo{}
+	}
+	(*contextInfo).Expiration = proto.Uint32(chat.EphemeralExpiration)
+}
+
+// viewOnce wraps media the way WhatsApp apps send view-once messages; the
+// ViewOnce flag on the media alone isn't honoured by recent apps.
+func viewOnce(msg *waE2E.Message) *waE2E.Message {
+	return &waE2E.Message{ViewOnceMessageV2: &waE2E.FutureProofMessage{Message: msg}}
+}
 
-	return expiration
+// messageContextInfo returns where the context info of a sent message lives,
+// or nil for message types that carry none.
+func messageContextInfo(msg *waE2E.Message) **waE2E.ContextInfo {
+	switch {
+	case msg.GetViewOnceMessageV2().GetMessage() != nil:
+		return messageContextInfo(msg.ViewOnceMessageV2.Message)
+	case msg.GetExtendedTextMessage() != nil:
+		return &msg.ExtendedTextMessage.ContextInfo
+	case msg.GetImageMessage() != nil:
+		return &msg.ImageMessage.ContextInfo
+	case msg.GetVideoMessage() != nil:
+		return &msg.VideoMessage.ContextInfo
+	case msg.GetPtvMessage() != nil:
+		return &msg.PtvMessage.ContextInfo
+	case msg.GetAudioMessage() != nil:
+		return &msg.AudioMessage.ContextInfo
+	case msg.GetDocumentMessage() != nil:
+		return &msg.DocumentMessage.ContextInfo
+	case msg.GetStickerMessage() != nil:
+		return &msg.StickerMessage.ContextInfo
+	case msg.GetContactMessage() != nil:
+		return &msg.ContactMessage.ContextInfo
+	case msg.GetContactsArrayMessage() != nil:
+		return &msg.ContactsArrayMessage.ContextInfo
+	case msg.GetLocationMessage() != nil:
+		return &msg.LocationMessage.ContextInfo
+	case msg.GetLiveLocationMessage() != nil:
+		return &msg.LiveLocationMessage.ContextInfo
+	case msg.GetPollCreationMessage() != nil:
+		return &msg.PollCreationMessage.ContextInfo
+	}
+	return nil
 }
@@ -0,0 +1,255 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
+	fiberUtils "github.com/gofiber/fiber/v2/utils"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// bulkRecipient is one recipient of a bulk send and how it went.
+type bulkRecipient struct {
+	jid    types.JID
+	text   string
+	result domainSend.BulkResult
+}
+
+// SendBulk sends the same text, or the same template filled in per
+// recipient, to every phone, one after the other. All recipients are checked
+// and rendered before the first message goes out; a recipient that fails the
+// check or the send doesn't stop the batch, but one that fails to render
+// fails the whole request.
+func (service serviceSend) SendBulk(ctx context.Context, request domainSend.BulkMessageRequest) (response domainSend.BulkResponse, err error) {
+	err = validations.ValidateSendBulk(ctx, request)
+	if err != nil {
+		return response, err
+	}
+
+	texts, err := service.renderBulkTexts(ctx, request)
+	if err != nil {
+		return response, err
+	}
+	if request.TemplateName != "" {
+		ctx = utils.ContextWithTemplateName(ctx, request.TemplateName)
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+	utils.MustLogin(client)
+
+	recipients := resolveBulkRecipients(ctx, client, request.Phones)
+	for i := range recipients {
+		recipients[i].text = texts[recipients[i].result.Phone]
+	}
+	delay := bulkDelay(request.DelayMsMin, request.DelayMsMax)
+
+	if !request.Async {
+		service.sendBulk(ctx, client, recipients, delay, func(int, domainSend.BulkResult) {})
+		return bulkResponse(bulkResults(recipients)), nil
+	}
+
+	// The batch outlives the request, so keep the device from ctx but not its cancellation
+	job := newBulkJob(context.WithoutCancel(ctx), bulkResults(recipients))
+	response = bulkResponse(bulkResults(recipients))
+	response.JobID = job.info.ID
+	response.Status = fmt.Sprintf("Bulk message queued as job %s", job.info.ID)
+
+	service.queue.pending.Add()
+	service.queue.track(job)
+	go func() {
+		defer service.queue.pending.Done()
+		service.sendBulk(job.ctx, client, recipients, delay, func(i int, result domainSend.BulkResult) {
+			service.queue.update(job, func(info *domainSend.Job) { info.Results[i] = result })
+		})
+		summary := bulkResponse(bulkResults(recipients))
+		service.queue.update(job, func(info *domainSend.Job) {
+			job.finished = time.Now()
+			if summary.Sent == 0 {
+				info.Status, info.Error = domainSend.JobStatusFailed, "no recipient could be sent to"
+				return
+			}
+			sentAt := time.Now()
+			info.Status, info.SentAt = domainSend.JobStatusSent, &sentAt
+		})
+		logrus.WithContext(ctx).Infof("Bulk send job %s finished: %s", response.JobID, summary.Status)
+	}()
+
+	return response, nil
+}
+
+// renderBulkTexts returns the text of every phone of request. Templates are
+// rendered with the shared variables and the phone's own on top of them.
+func (service serviceSend) renderBulkTexts(ctx context.Context, request domainSend.BulkMessageRequest) (map[string]string, error) {
+	texts := make(map[string]string, len(request.Phones))
+	if request.TemplateName == "" {
+		for _, phone := range request.Phones {
+			texts[phone] = request.Message
+		}
+		return texts, nil
+	}
+
+	record, err := loadTemplate(ctx, service.chatStorageRepo, request.TemplateName)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := utils.ParseMessageTemplate(request.TemplateName, record.Body)
+	if err != nil {
+		return nil, pkgError.ValidationError(fmt.Sprintf("template %s: %v", request.TemplateName, err))
+	}
+	for _, phone := range request.Phones {
+		variables := maps.Clone(request.Variables)
+		if variables == nil {
+			variables = make(map[string]string)
+		}
+		maps.Copy(variables, request.RecipientVariables[phone])
+		text, err := parsed.Render(variables, request.TemplateStrict)
+		if err != nil {
+			return nil, pkgError.ValidationError(fmt.Sprintf("phone %s: template %s: %v", phone, request.TemplateName, err))
+		}
+		texts[phone] = text
+	}
+	return texts, nil
+}
+
+// resolveBulkRecipients checks every phone the way single sends do, including
+// the IsOnWhatsApp lookup when account validation is enabled. Those lookups
+// are made in bulk up front, so the per-phone checks are answered from the
+// cache. Repeated phones are only sent to once.
+func resolveBulkRecipients(ctx context.Context, client *whatsmeow.Client, phones []string) []bulkRecipient {
+	jids := make([]string, 0, len(phones))
+	users := make([]string, 0, len(phones))
+	for _, phone := range phones {
+		jid := phone
+		utils.SanitizePhone(&jid)
+		jids = append(jids, jid)
+		if user, ok := strings.CutSuffix(jid, config.WhatsappTypeUser); ok {
+			users = append(users, utils.StripPhonePrefix(user))
+		}
+	}
+	if config.WhatsappAccountValidation && len(users) > 0 {
+		if _, err := utils.CheckOnWhatsApp(ctx, client, users); err != nil {
+			logrus.WithContext(ctx).Warnf("Failed to check bulk recipients on WhatsApp, checking them one by one: %v", err)
+		}
+	}
+
+	recipients := make([]bulkRecipient, 0, len(phones))
+	seen := make(map[string]bool, len(phones))
+	for i, phone := range phones {
+		jid := jids[i]
+		if seen[jid] {
+			continue
+		}
+		seen[jid] = true
+
+		recipient := bulkRecipient{result: domainSend.BulkResult{Phone: phone, Status: domainSend.BulkStatusPending}}
+		parsed, err := utils.ValidateJidWithLogin(client, jid)
+		if err != nil {
+			recipient.result.Status, recipient.result.Error = domainSend.BulkStatusInvalid, err.Error()
+		}
+		recipient.jid = parsed
+		recipients = append(recipients, recipient)
+	}
+	return recipients
+}
+
+// sendBulk sends their text to the pending recipients in order, pausing for delay
+// between two sends, and reports the result of each one. Messages still go
+// through the outbound queue, so its rate limits apply on top of the pause.
+func (service serviceSend) sendBulk(ctx context.Context, client *whatsmeow.Client, recipients []bulkRecipient, delay func() time.Duration, report func(int, domainSend.BulkResult)) {
+	attempted := 0
+	for i := range recipients {
+		recipient := &recipients[i]
+		if recipient.result.Status != domainSend.BulkStatusPending {
+			continue
+		}
+
+		if attempted > 0 {
+			_ = sleepContext(ctx, delay())
+		}
+		attempted++
+
+		if err := ctx.Err(); err != nil {
+			recipient.result.Status, recipient.result.Error = domainSend.BulkStatusFailed, err.Error()
+			report(i, recipient.result)
+			continue
+		}
+
+		msg := &waE2E.Message{
+			ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+				Text:        proto.String(recipient.text),
+				ContextInfo: &waE2E.ContextInfo{},
+			},
+		}
+		ts, err := service.wrapSendMessage(ctx, client, recipient.jid, msg, recipient.text, domainSend.BaseRequest{})
+		if err != nil {
+			logrus.WithContext(ctx).Warnf("Bulk send to %s failed: %v", recipient.result.Phone, err)
+			recipient.result.Status, recipient.result.Error = domainSend.BulkStatusFailed, err.Error()
+		} else {
+			recipient.result.Status, recipient.result.MessageID = domainSend.BulkStatusSent, ts.ID
+		}
+		report(i, recipient.result)
+	}
+}
+
+// bulkDelay returns a random pause between minMs and maxMs milliseconds, so a
+// batch doesn't go out at a machine-like pace.
+func bulkDelay(minMs, maxMs int) func() time.Duration {
+	return func() time.Duration {
+		if maxMs <= minMs {
+			return time.Duration(minMs) * time.Millisecond
+		}
+		return time.Duration(minMs+rand.IntN(maxMs-minMs+1)) * time.Millisecond
+	}
+}
+
+func newBulkJob(ctx context.Context, results []domainSend.BulkResult) *sendJob {
+	return &sendJob{
+		ctx:      ctx,
+		deviceID: deviceIDFromContext(ctx),
+		info: domainSend.Job{
+			ID:       fiberUtils.UUIDv4(),
+			Status:   domainSend.JobStatusQueued,
+			QueuedAt: time.Now(),
+			Results:  results,
+		},
+	}
+}
+
+func bulkResults(recipients []bulkRecipient) []domainSend.BulkResult {
+	results := make([]domainSend.BulkResult, len(recipients))
+	for i, recipient := range recipients {
+		results[i] = recipient.result
+	}
+	return results
+}
+
+func bulkResponse(results []domainSend.BulkResult) domainSend.BulkResponse {
+	response := domainSend.BulkResponse{Results: results}
+	for _, result := range results {
+		switch result.Status {
+		case domainSend.BulkStatusSent:
+			response.Sent++
+		case domainSend.BulkStatusFailed, domainSend.BulkStatusInvalid:
+			response.Failed++
+		}
+	}
+	response.Status = fmt.Sprintf("Bulk message sent to %d of %d recipients", response.Sent, len(results))
+	return response
+}
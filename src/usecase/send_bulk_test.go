@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestSendBulkContinuesAfterFailures(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+
+	stubSendMessage(t, func(attempt int32, extra whatsmeow.SendRequestExtra) (whatsmeow.SendResponse, error) {
+		if attempt == 1 {
+			return whatsmeow.SendResponse{}, whatsmeow.ErrNotLoggedIn
+		}
+		return whatsmeow.SendResponse{ID: extra.ID, Timestamp: time.Now()}, nil
+	})
+
+	recipients := []bulkRecipient{
+		{jid: types.NewJID("628111", types.DefaultUserServer), text: "Promo today", result: domainSend.BulkResult{Phone: "628111", Status: domainSend.BulkStatusPending}},
+		{result: domainSend.BulkResult{Phone: "628000", Status: domainSend.BulkStatusInvalid, Error: "Phone 628000 is not on whatsapp"}},
+		{jid: types.NewJID("628222", types.DefaultUserServer), text: "Promo today", result: domainSend.BulkResult{Phone: "628222", Status: domainSend.BulkStatusPending}},
+	}
+	delays := 0
+	var reported []int
+
+	service := serviceSend{chatStorageRepo: repo, queue: newTestQueue(0)}
+	ctx := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-1", nil, nil))
+	service.sendBulk(ctx, nil, recipients, func() time.Duration {
+		delays++
+		return 0
+	}, func(i int, _ domainSend.BulkResult) { reported = append(reported, i) })
+
+	response := bulkResponse(bulkResults(recipients))
+	assert.Equal(t, domainSend.BulkStatusFailed, response.Results[0].Status)
+	assert.NotEmpty(t, response.Results[0].Error)
+	assert.Equal(t, domainSend.BulkStatusInvalid, response.Results[1].Status, "invalid recipients are never sent to")
+	assert.Equal(t, domainSend.BulkStatusSent, response.Results[2].Status)
+	assert.NotEmpty(t, response.Results[2].MessageID)
+	assert.Equal(t, 1, response.Sent)
+	assert.Equal(t, 2, response.Failed)
+	assert.Equal(t, "Bulk message sent to 1 of 3 recipients", response.Status)
+
+	assert.Equal(t, 1, delays, "only pauses between two sends")
+	assert.Equal(t, []int{0, 2}, reported)
+}
+
+func TestSendBulkStopsWithContext(t *testing.T) {
+	attempts := stubSendMessage(t, func(int32, whatsmeow.SendRequestExtra) (whatsmeow.SendResponse, error) {
+		return whatsmeow.SendResponse{}, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	recipients := []bulkRecipient{
+		{jid: types.NewJID("628111", types.DefaultUserServer), text: "Promo today", result: domainSend.BulkResult{Phone: "628111", Status: domainSend.BulkStatusPending}},
+		{jid: types.NewJID("628222", types.DefaultUserServer), text: "Promo today", result: domainSend.BulkResult{Phone: "628222", Status: domainSend.BulkStatusPending}},
+	}
+
+	service := serviceSend{queue: newTestQueue(0)}
+	service.sendBulk(ctx, nil, recipients, func() time.Duration { return 0 }, func(int, domainSend.BulkResult) {})
+
+	for _, recipient := range recipients {
+		assert.Equal(t, domainSend.BulkStatusFailed, recipient.result.Status)
+		assert.Equal(t, context.Canceled.Error(), recipient.result.Error)
+	}
+	assert.Zero(t, attempts.Load())
+}
+
+func TestBulkDelay(t *testing.T) {
+	delay := bulkDelay(100, 300)
+	for range 50 {
+		d := delay()
+		assert.GreaterOrEqual(t, d, 100*time.Millisecond)
+		assert.LessOrEqual(t, d, 300*time.Millisecond)
+	}
+	assert.Equal(t, 250*time.Millisecond, bulkDelay(250, 250)())
+	assert.Zero(t, bulkDelay(0, 0)())
+}
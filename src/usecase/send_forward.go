@@ -0,0 +1,340 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// forwardUploadTypes are the upload types of the attachments that can be
+// forwarded, by stored media type.
+var forwardUploadTypes = map[string]whatsmeow.MediaType{
+	"image":      whatsmeow.MediaImage,
+	"sticker":    whatsmeow.MediaImage,
+	"video":      whatsmeow.MediaVideo,
+	"video_note": whatsmeow.MediaVideo,
+	"audio":      whatsmeow.MediaAudio,
+	"document":   whatsmeow.MediaDocument,
+}
+
+// ForwardMessage sends a copy of a stored message to every recipient, marked
+// as forwarded. Attachments reuse the media pointers kept in chat storage, so
+// nothing is downloaded or uploaded while WhatsApp still serves them; once it
+// doesn't, the attachment is uploaded again, once for all recipients. A
+// recipient that fails doesn't stop the others.
+func (service serviceSend) ForwardMessage(ctx context.Context, request domainSend.ForwardRequest) (response domainSend.BulkResponse, err error) {
+	err = validations.ValidateForwardMessage(ctx, request)
+	if err != nil {
+		return response, err
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+	utils.MustLogin(client)
+
+	stored, err := service.forwardableMessage(ctx, request.MessageID)
+	if err != nil {
+		return response, err
+	}
+
+	media := storedMediaPointers(stored)
+	reuploaded := false
+	if stored.MediaType != "" && media.URL != "" && mediaURLExpired(media.URL, time.Now()) {
+		reuploaded = true
+		if media, err = service.reuploadForwardedMedia(ctx, client, stored); err != nil {
+			return response, err
+		}
+	}
+	msg, err := forwardedMessage(stored, media)
+	if err != nil {
+		return response, err
+	}
+
+	recipients := resolveBulkRecipients(ctx, client, request.Recipients())
+	for i := range recipients {
+		recipient := &recipients[i]
+		if recipient.result.Status != domainSend.BulkStatusPending {
+			continue
+		}
+		if recipient.jid.Server == types.NewsletterServer && media.URL != "" {
+			recipient.result.Status, recipient.result.Error = domainSend.BulkStatusFailed, "attachments can't be forwarded to a newsletter"
+			continue
+		}
+
+		ts, err := service.wrapSendMessage(ctx, client, recipient.jid, proto.Clone(msg).(*waE2E.Message), stored.Content, domainSend.BaseRequest{})
+		if err != nil && !reuploaded && media.URL != "" && errors.Is(err, whatsmeow.ErrServerReturnedError) {
+			logrus.WithContext(ctx).Warnf("Forward of message %s to %s was rejected, uploading its media again: %v", stored.ID, recipient.result.Phone, err)
+			reuploaded = true
+			if media, err = service.reuploadForwardedMedia(ctx, client, stored); err == nil {
+				if msg, err = forwardedMessage(stored, media); err == nil {
+					ts, err = service.wrapSendMessage(ctx, client, recipient.jid, proto.Clone(msg).(*waE2E.Message), stored.Content, domainSend.BaseRequest{})
+				}
+			}
+		}
+		if err != nil {
+			logrus.WithContext(ctx).Warnf("Forward of message %s to %s failed: %v", stored.ID, recipient.result.Phone, err)
+			recipient.result.Status, recipient.result.Error = domainSend.BulkStatusFailed, err.Error()
+			continue
+		}
+		recipient.result.Status, recipient.result.MessageID = domainSend.BulkStatusSent, ts.ID
+	}
+
+	response = bulkResponse(bulkResults(recipients))
+	response.Status = fmt.Sprintf("Message %s forwarded to %d of %d recipients", request.MessageID, response.Sent, len(recipients))
+	return response, nil
+}
+
+// forwardableMessage looks up the stored message to forward. Polls and
+// view-once media can't be forwarded.
+func (service serviceSend) forwardableMessage(ctx context.Context, messageID string) (*domainChatStorage.Message, error) {
+	deviceID := deviceIDFromContext(ctx)
+	stored, err := service.chatStorageRepo.GetMessageByDevice(ctx, deviceID, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up message %s: %w", messageID, err)
+	}
+	if stored == nil {
+		return nil, pkgError.NotFoundError(fmt.Sprintf("message %s not found", messageID))
+	}
+	if stored.IsViewOnce {
+		return nil, pkgError.ValidationError(fmt.Sprintf("message %s is view-once and can't be forwarded", messageID))
+	}
+	if stored.MediaType == "" {
+		if poll, err := service.chatStorageRepo.GetPoll(ctx, deviceID, stored.ChatJID, stored.ID); err == nil && poll != nil {
+			return nil, pkgError.ValidationError(fmt.Sprintf("message %s is a poll and can't be forwarded", messageID))
+		}
+	}
+	return stored, nil
+}
+
+// reuploadForwardedMedia uploads the attachment of stored again, from its
+// local copy when there is one and downloaded from WhatsApp otherwise.
+func (service serviceSend) reuploadForwardedMedia(ctx context.Context, client *whatsmeow.Client, stored *domainChatStorage.Message) (whatsmeow.UploadResponse, error) {
+	uploadType, ok := forwardUploadTypes[stored.MediaType]
+	if !ok {
+		return whatsmeow.UploadResponse{}, pkgError.ValidationError(fmt.Sprintf("%s messages can't be forwarded", stored.MediaType))
+	}
+
+	var data []byte
+	if stored.MediaPath != "" {
+		local, err := os.ReadFile(stored.MediaPath)
+		if err != nil {
+			logrus.WithContext(ctx).Warnf("Failed to read the local copy of message %s, downloading it: %v", stored.ID, err)
+		}
+		data = local
+	}
+	if data == nil {
+		downloadable, err := whatsapp.StoredMediaDownloadable(stored)
+		if err != nil {
+			return whatsmeow.UploadResponse{}, err
+		}
+		data, err = client.Download(ctx, downloadable)
+		if errors.Is(err, whatsmeow.ErrMediaDownloadFailedWith404) || errors.Is(err, whatsmeow.ErrMediaDownloadFailedWith410) {
+			return whatsmeow.UploadResponse{}, pkgError.MediaExpiredError(fmt.Sprintf("media of message %s is no longer available on WhatsApp servers, request it again before forwarding", stored.ID))
+		}
+		if err != nil {
+			return whatsmeow.UploadResponse{}, fmt.Errorf("failed to download media: %w", err)
+		}
+	}
+
+	uploaded, err := client.Upload(ctx, data, uploadType)
+	if err != nil {
+		return whatsmeow.UploadResponse{}, fmt.Errorf("failed to upload media: %w", err)
+	}
+	return uploaded, nil
+}
+
+// storedMediaPointers returns the media pointers kept with a stored message,
+// the way an upload would have returned them.
+func storedMediaPointers(stored *domainChatStorage.Message) whatsmeow.UploadResponse {
+	return whatsmeow.UploadResponse{
+		URL:           stored.URL,
+		DirectPath:    mediaDirectPath(stored.URL),
+		MediaKey:      stored.MediaKey,
+		FileEncSHA256: stored.FileEncSHA256,
+		FileSHA256:    stored.FileSHA256,
+		FileLength:    stored.FileLength,
+	}
+}
+
+// mediaDirectPath derives the direct path of an attachment from its URL: the
+// path and query, without the mms3 flag the URL carries on top.
+func mediaDirectPath(mediaURL string) string {
+	parsed, err := url.Parse(mediaURL)
+	if err != nil || parsed.Path == "" {
+		return ""
+	}
+	var params []string
+	for _, param := range strings.Split(parsed.RawQuery, "&") {
+		if param != "" && !strings.HasPrefix(param, "mms3=") {
+			params = append(params, param)
+		}
+	}
+	if len(params) == 0 {
+		return parsed.EscapedPath()
+	}
+	return parsed.EscapedPath() + "?" + strings.Join(params, "&")
+}
+
+// mediaURLExpired reports whether WhatsApp stopped serving an attachment URL,
+// going by the expiry its oe parameter carries as hex Unix seconds. URLs
+// without one are assumed to be valid.
+func mediaURLExpired(mediaURL string, now time.Time) bool {
+	parsed, err := url.Parse(mediaURL)
+	if err != nil {
+		return false
+	}
+	expires, err := strconv.ParseInt(parsed.Query().Get("oe"), 16, 64)
+	if err != nil {
+		return false
+	}
+	return now.After(time.Unix(expires, 0))
+}
+
+// forwardedMessage rebuilds the message stored as stored, marked as
+// forwarded, with media as the pointers of its attachment. Live locations
+// are forwarded as the last position they shared.
+func forwardedMessage(stored *domainChatStorage.Message, media whatsmeow.UploadResponse) (*waE2E.Message, error) {
+	contextInfo := &waE2E.ContextInfo{
+		IsForwarded:     proto.Bool(true),
+		ForwardingScore: proto.Uint32(1),
+	}
+
+	switch stored.MediaType {
+	case "":
+		if stored.Content == "" {
+			return nil, pkgError.ValidationError(fmt.Sprintf("message %s has no content to forward", stored.ID))
+		}
+		return &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+			Text:        proto.String(stored.Content),
+			ContextInfo: contextInfo,
+		}}, nil
+	case "location", "live_location":
+		if stored.Location == nil {
+			return nil, pkgError.ValidationError(fmt.Sprintf("message %s has no stored location to forward", stored.ID))
+		}
+		return &waE2E.Message{LocationMessage: &waE2E.LocationMessage{
+			DegreesLatitude:  proto.Float64(stored.Location.Latitude),
+			DegreesLongitude: proto.Float64(stored.Location.Longitude),
+			Name:             proto.String(stored.Location.Name),
+			Address:          proto.String(stored.Location.Address),
+			ContextInfo:      contextInfo,
+		}}, nil
+	case "contact":
+		contacts := make([]*waE2E.ContactMessage, len(stored.Contacts))
+		for i, card := range stored.Contacts {
+			contacts[i] = &waE2E.ContactMessage{DisplayName: proto.String(card.DisplayName), Vcard: proto.String(card.VCard)}
+		}
+		switch len(contacts) {
+		case 0:
+			return nil, pkgError.ValidationError(fmt.Sprintf("message %s has no stored contacts to forward", stored.ID))
+		case 1:
+			contacts[0].ContextInfo = contextInfo
+			return &waE2E.Message{ContactMessage: contacts[0]}, nil
+		}
+		return &waE2E.Message{ContactsArrayMessage: &waE2E.ContactsArrayMessage{
+			DisplayName: proto.String(fmt.Sprintf("%d contacts", len(contacts))),
+			Contacts:    contacts,
+			ContextInfo: contextInfo,
+		}}, nil
+	}
+
+	if _, ok := forwardUploadTypes[stored.MediaType]; !ok {
+		return nil, pkgError.ValidationError(fmt.Sprintf("%s messages can't be forwarded", stored.MediaType))
+	}
+	if media.URL == "" || len(media.MediaKey) == 0 {
+		return nil, pkgError.ValidationError(fmt.Sprintf("media keys of message %s weren't stored, it can't be forwarded", stored.ID))
+	}
+
+	switch stored.MediaType {
+	case "image":
+		return &waE2E.Message{ImageMessage: &waE2E.ImageMessage{
+			URL:           proto.String(media.URL),
+			DirectPath:    proto.String(media.DirectPath),
+			MediaKey:      media.MediaKey,
+			FileEncSHA256: media.FileEncSHA256,
+			FileSHA256:    media.FileSHA256,
+			FileLength:    proto.Uint64(media.FileLength),
+			Mimetype:      proto.String("image/jpeg"),
+			Caption:       proto.String(stored.Content),
+			ContextInfo:   contextInfo,
+		}}, nil
+	case "video", "video_note":
+		video := &waE2E.VideoMessage{
+			URL:           proto.String(media.URL),
+			DirectPath:    proto.String(media.DirectPath),
+			MediaKey:      media.MediaKey,
+			FileEncSHA256: media.FileEncSHA256,
+			FileSHA256:    media.FileSHA256,
+			FileLength:    proto.Uint64(media.FileLength),
+			Mimetype:      proto.String("video/mp4"),
+			Caption:       proto.String(stored.Content),
+			ContextInfo:   contextInfo,
+		}
+		if stored.MediaType == "video_note" {
+			return &waE2E.Message{PtvMessage: video}, nil
+		}
+		return &waE2E.Message{VideoMessage: video}, nil
+	case "audio":
+		return &waE2E.Message{AudioMessage: &waE2E.AudioMessage{
+			URL:           proto.String(media.URL),
+			DirectPath:    proto.String(media.DirectPath),
+			MediaKey:      media.MediaKey,
+			FileEncSHA256: media.FileEncSHA256,
+			FileSHA256:    media.FileSHA256,
+			FileLength:    proto.Uint64(media.FileLength),
+			Mimetype:      proto.String("audio/ogg; codecs=opus"),
+			ContextInfo:   contextInfo,
+		}}, nil
+	case "sticker":
+		return &waE2E.Message{StickerMessage: &waE2E.StickerMessage{
+			URL:           proto.String(media.URL),
+			DirectPath:    proto.String(media.DirectPath),
+			MediaKey:      media.MediaKey,
+			FileEncSHA256: media.FileEncSHA256,
+			FileSHA256:    media.FileSHA256,
+			FileLength:    proto.Uint64(media.FileLength),
+			Mimetype:      proto.String("image/webp"),
+			IsAnimated:    proto.Bool(stored.IsAnimated),
+			ContextInfo:   contextInfo,
+		}}, nil
+	}
+
+	mimeType := mime.TypeByExtension(filepath.Ext(stored.Filename))
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return &waE2E.Message{DocumentMessage: &waE2E.DocumentMessage{
+		URL:           proto.String(media.URL),
+		DirectPath:    proto.String(media.DirectPath),
+		MediaKey:      media.MediaKey,
+		FileEncSHA256: media.FileEncSHA256,
+		FileSHA256:    media.FileSHA256,
+		FileLength:    proto.Uint64(media.FileLength),
+		Mimetype:      proto.String(mimeType),
+		FileName:      proto.String(stored.Filename),
+		Title:         proto.String(stored.Filename),
+		Caption:       proto.String(stored.Content),
+		ContextInfo:   contextInfo,
+	}}, nil
+}
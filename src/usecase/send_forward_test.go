@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const forwardMediaURL = "https://mmg.whatsapp.net/v/t62.7118-24/12345_67890_n.enc?ccb=11-4&oh=01_Q5Aa&oe=6A1B2C3D&_nc_sid=5e03e0&mms3=true"
+
+func TestForwardedMessage(t *testing.T) {
+	image := &domainChatStorage.Message{
+		ID: "IMG", MediaType: "image", Content: "look at this", URL: forwardMediaURL,
+		MediaKey: []byte{1}, FileSHA256: []byte{2}, FileEncSHA256: []byte{3}, FileLength: 2048,
+	}
+	msg, err := forwardedMessage(image, storedMediaPointers(image))
+	require.NoError(t, err)
+	assert.Equal(t, forwardMediaURL, msg.GetImageMessage().GetURL())
+	assert.Equal(t, "/v/t62.7118-24/12345_67890_n.enc?ccb=11-4&oh=01_Q5Aa&oe=6A1B2C3D&_nc_sid=5e03e0", msg.GetImageMessage().GetDirectPath())
+	assert.Equal(t, []byte{1}, msg.GetImageMessage().GetMediaKey())
+	assert.Equal(t, uint64(2048), msg.GetImageMessage().GetFileLength())
+	assert.Equal(t, "look at this", msg.GetImageMessage().GetCaption())
+	assert.True(t, msg.GetImageMessage().GetContextInfo().GetIsForwarded())
+
+	document := &domainChatStorage.Message{ID: "DOC", MediaType: "document", Filename: "report.pdf", URL: forwardMediaURL, MediaKey: []byte{1}}
+	msg, err = forwardedMessage(document, storedMediaPointers(document))
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", msg.GetDocumentMessage().GetMimetype())
+	assert.Equal(t, "report.pdf", msg.GetDocumentMessage().GetFileName())
+
+	msg, err = forwardedMessage(&domainChatStorage.Message{ID: "TXT", Content: "hello"}, storedMediaPointers(&domainChatStorage.Message{}))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", msg.GetExtendedTextMessage().GetText())
+	assert.True(t, msg.GetExtendedTextMessage().GetContextInfo().GetIsForwarded())
+
+	live := &domainChatStorage.Message{ID: "LOC", MediaType: "live_location", Location: &domainChatStorage.Location{Latitude: -6.2, Longitude: 106.8}}
+	msg, err = forwardedMessage(live, storedMediaPointers(live))
+	require.NoError(t, err)
+	assert.Equal(t, -6.2, msg.GetLocationMessage().GetDegreesLatitude(), "live locations are forwarded as a static one")
+
+	contacts := &domainChatStorage.Message{ID: "VCF", MediaType: "contact", Contacts: []domainChatStorage.ContactCard{
+		{DisplayName: "Alice", VCard: "BEGIN:VCARD\nFN:Alice\nEND:VCARD"},
+		{DisplayName: "Bob", VCard: "BEGIN:VCARD\nFN:Bob\nEND:VCARD"},
+	}}
+	msg, err = forwardedMessage(contacts, storedMediaPointers(contacts))
+	require.NoError(t, err)
+	assert.Len(t, msg.GetContactsArrayMessage().GetContacts(), 2)
+
+	noKeys := &domainChatStorage.Message{ID: "OLD", MediaType: "video"}
+	_, err = forwardedMessage(noKeys, storedMediaPointers(noKeys))
+	assert.IsType(t, pkgError.ValidationError(""), err)
+
+	_, err = forwardedMessage(&domainChatStorage.Message{ID: "EMPTY"}, storedMediaPointers(&domainChatStorage.Message{}))
+	assert.IsType(t, pkgError.ValidationError(""), err)
+}
+
+func TestMediaURLExpired(t *testing.T) {
+	// oe=6A1B2C3D is 2026-05-30 18:28:13 UTC
+	expiry := time.Unix(0x6A1B2C3D, 0)
+	assert.False(t, mediaURLExpired(forwardMediaURL, expiry.Add(-time.Hour)))
+	assert.True(t, mediaURLExpired(forwardMediaURL, expiry.Add(time.Hour)))
+	assert.False(t, mediaURLExpired("https://mmg.whatsapp.net/d/f/abc.enc", expiry.Add(time.Hour)), "no expiry to go by")
+}
+
+func TestForwardableMessage(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+
+	chat := "628111@s.whatsapp.net"
+	for _, message := range []*domainChatStorage.Message{
+		{ID: "TEXT", Content: "hello"},
+		{ID: "ONCE", MediaType: "image", IsViewOnce: true},
+		{ID: "POLL", Content: "Lunch?"},
+	} {
+		message.DeviceID, message.ChatJID, message.Timestamp = "dev-1", chat, time.Now()
+		require.NoError(t, repo.StoreMessage(context.Background(), message))
+	}
+	require.NoError(t, repo.StorePoll(context.Background(), &domainChatStorage.Poll{MessageID: "POLL", ChatJID: chat, DeviceID: "dev-1", Question: "Lunch?", Options: []string{"Yes", "No"}}))
+
+	service := serviceSend{chatStorageRepo: repo}
+	ctx := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-1", nil, nil))
+
+	stored, err := service.forwardableMessage(ctx, "TEXT")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", stored.Content)
+
+	_, err = service.forwardableMessage(ctx, "ONCE")
+	assert.IsType(t, pkgError.ValidationError(""), err)
+
+	_, err = service.forwardableMessage(ctx, "POLL")
+	assert.IsType(t, pkgError.ValidationError(""), err)
+
+	_, err = service.forwardableMessage(ctx, "UNKNOWN")
+	assert.IsType(t, pkgError.NotFoundError(""), err)
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"net/http"
+
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/disintegration/imaging"
+)
+
+const (
+	// imageMaxFileSize is the size re-encoded images are brought under
+	imageMaxFileSize       = 5 << 20
+	imageCompressDimension = 600
+	imageThumbnailSize     = 100
+)
+
+// errAnimatedImage is returned by prepareImage for animated GIFs, which are
+// sent as looping videos since image messages only show the first frame.
+var errAnimatedImage = errors.New("image is animated")
+
+// preparedImage is an image ready to be uploaded.
+type preparedImage struct {
+	data      []byte
+	mimeType  string
+	width     int
+	height    int
+	thumbnail []byte
+}
+
+// prepareImage decodes a JPEG, PNG, WebP or GIF image, turns it upright
+// following its EXIF orientation and shrinks it to fit maxDimension, 0
+// keeping its size. PNGs that didn't change are sent as they are; anything
+// else is re-encoded as a JPEG under imageMaxFileSize, which also drops EXIF
+// data such as the GPS position.
+func prepareImage(data []byte, maxDimension int) (preparedImage, error) {
+	mimeType := http.DetectContentType(data)
+	if mimeType == "image/gif" {
+		if anim, err := gif.DecodeAll(bytes.NewReader(data)); err == nil && len(anim.Image) > 1 {
+			return preparedImage{}, errAnimatedImage
+		}
+	}
+
+	img, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+	if err != nil {
+		return preparedImage{}, pkgError.InvalidMediaError(fmt.Sprintf("file is not a supported image (JPEG, PNG, WebP or GIF): %v", err))
+	}
+
+	resized := false
+	if bounds := img.Bounds(); maxDimension > 0 && max(bounds.Dx(), bounds.Dy()) > maxDimension {
+		img = imaging.Fit(img, maxDimension, maxDimension, imaging.Lanczos)
+		resized = true
+	}
+
+	prepared := preparedImage{data: data, mimeType: mimeType}
+	if mimeType != "image/png" || resized || len(data) > imageMaxFileSize {
+		prepared.data, img, err = encodeJPEGUnder(img, imageMaxFileSize)
+		if err != nil {
+			return preparedImage{}, err
+		}
+		prepared.mimeType = "image/jpeg"
+	}
+	prepared.width, prepared.height = img.Bounds().Dx(), img.Bounds().Dy()
+
+	var thumbnail bytes.Buffer
+	err = imaging.Encode(&thumbnail, imaging.Fit(img, imageThumbnailSize, imageThumbnailSize, imaging.Lanczos), imaging.JPEG, imaging.JPEGQuality(70))
+	if err != nil {
+		return preparedImage{}, pkgError.InternalServerError(fmt.Sprintf("failed to encode image thumbnail: %v", err))
+	}
+	prepared.thumbnail = thumbnail.Bytes()
+	return prepared, nil
+}
+
+// encodeJPEGUnder encodes img as a JPEG of at most limit bytes, lowering the
+// quality first and then the size. Transparent parts turn white rather than
+// black. It returns the image that was encoded.
+func encodeJPEGUnder(img image.Image, limit int) ([]byte, image.Image, error) {
+	bounds := img.Bounds()
+	img = imaging.Overlay(imaging.New(bounds.Dx(), bounds.Dy(), color.White), img, image.Point{}, 1)
+
+	var encoded bytes.Buffer
+	for {
+		for quality := 90; quality >= 60; quality -= 10 {
+			encoded.Reset()
+			if err := imaging.Encode(&encoded, img, imaging.JPEG, imaging.JPEGQuality(quality)); err != nil {
+				return nil, nil, pkgError.InternalServerError(fmt.Sprintf("failed to encode image: %v", err))
+			}
+			if encoded.Len() <= limit {
+				return encoded.Bytes(), img, nil
+			}
+		}
+		// Still too big at the lowest quality, so shrink it and try again
+		img = imaging.Resize(img, img.Bounds().Dx()*3/4, 0, imaging.Lanczos)
+	}
+}
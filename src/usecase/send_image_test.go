@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"math/rand/v2"
+	"net/http"
+	"testing"
+
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withEXIFOrientation inserts an APP1 segment with the given orientation
+// right after the SOI marker of a JPEG.
+func withEXIFOrientation(jpg []byte, orientation byte) []byte {
+	tiff := []byte{
+		'M', 'M', 0, 42, 0, 0, 0, 8, // Big endian header, IFD at offset 8
+		0, 1, // One entry
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, orientation, 0, 0, // Orientation, SHORT, 1 value
+		0, 0, 0, 0, // No next IFD
+	}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := append([]byte{0xFF, 0xE1, 0, byte(len(payload) + 2)}, payload...)
+	return append(append(jpg[:2:2], segment...), jpg[2:]...)
+}
+
+func encodeTestImage(t *testing.T, img image.Image, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if format == "png" {
+		require.NoError(t, png.Encode(&buf, img))
+	} else {
+		require.NoError(t, jpeg.Encode(&buf, img, nil))
+	}
+	return buf.Bytes()
+}
+
+func TestPrepareImage(t *testing.T) {
+	landscape := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+
+	t.Run("applies EXIF orientation", func(t *testing.T) {
+		prepared, err := prepareImage(withEXIFOrientation(encodeTestImage(t, landscape, "jpeg"), 6), 0)
+		require.NoError(t, err)
+		assert.Equal(t, "image/jpeg", prepared.mimeType)
+		assert.Equal(t, 20, prepared.width)
+		assert.Equal(t, 40, prepared.height)
+		assert.Equal(t, "image/jpeg", http.DetectContentType(prepared.thumbnail))
+	})
+
+	t.Run("keeps small PNGs as they are", func(t *testing.T) {
+		data := encodeTestImage(t, landscape, "png")
+		prepared, err := prepareImage(data, 100)
+		require.NoError(t, err)
+		assert.Equal(t, data, prepared.data)
+		assert.Equal(t, "image/png", prepared.mimeType)
+	})
+
+	t.Run("downscales to the max dimension", func(t *testing.T) {
+		prepared, err := prepareImage(encodeTestImage(t, landscape, "png"), 10)
+		require.NoError(t, err)
+		assert.Equal(t, "image/jpeg", prepared.mimeType)
+		assert.Equal(t, 10, prepared.width)
+		assert.Equal(t, 5, prepared.height)
+	})
+
+	t.Run("refuses animated GIFs", func(t *testing.T) {
+		palette := color.Palette{color.Black, color.White}
+		anim := &gif.GIF{
+			Image: []*image.Paletted{image.NewPaletted(image.Rect(0, 0, 4, 4), palette), image.NewPaletted(image.Rect(0, 0, 4, 4), palette)},
+			Delay: []int{10, 10},
+		}
+		var buf bytes.Buffer
+		require.NoError(t, gif.EncodeAll(&buf, anim))
+		_, err := prepareImage(buf.Bytes(), 0)
+		assert.ErrorIs(t, err, errAnimatedImage)
+	})
+
+	t.Run("refuses files that aren't images", func(t *testing.T) {
+		_, err := prepareImage([]byte("%PDF-1.4 not an image"), 0)
+		assert.IsType(t, pkgError.InvalidMediaError(""), err)
+	})
+}
+
+func TestEncodeJPEGUnder(t *testing.T) {
+	// Noise doesn't compress, so it takes shrinking to fit
+	noise := image.NewNRGBA(image.Rect(0, 0, 300, 300))
+	for i := range noise.Pix {
+		noise.Pix[i] = byte(rand.IntN(256))
+	}
+
+	data, img, err := encodeJPEGUnder(noise, 20<<10)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(data), 20<<10)
+	assert.Less(t, img.Bounds().Dx(), 300)
+
+	decoded, err := jpeg.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, img.Bounds().Size(), decoded.Bounds().Size())
+}
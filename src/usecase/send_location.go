@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/disintegration/imaging"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	locationThumbnailSize    = 100
+	locationThumbnailMaxSize = 5 << 20
+)
+
+// locationThumbnailFn renders the JPEG thumbnail embedded in sent locations.
+// It returns nil without an error when thumbnails are off; replace it to plug
+// in another renderer.
+var locationThumbnailFn = staticMapThumbnail
+
+// locationThumbnail returns the thumbnail for a location, or nil when there is
+// none. A failed render doesn't stop the location from being sent.
+func locationThumbnail(ctx context.Context, latitude, longitude float64) []byte {
+	thumbnail, err := locationThumbnailFn(ctx, latitude, longitude)
+	if err != nil {
+		logrus.WithContext(ctx).Warnf("Failed to render location thumbnail, sending without it: %v", err)
+		return nil
+	}
+	return thumbnail
+}
+
+// staticMapThumbnail fetches config.WhatsappLocationThumbnailURL, with {lat}
+// and {lng} filled in, and scales the image down to a JPEG thumbnail.
+func staticMapThumbnail(ctx context.Context, latitude, longitude float64) ([]byte, error) {
+	if config.WhatsappLocationThumbnailURL == "" {
+		return nil, nil
+	}
+
+	url := strings.NewReplacer(
+		"{lat}", strconv.FormatFloat(latitude, 'f', -1, 64),
+		"{lng}", strconv.FormatFloat(longitude, 'f', -1, 64),
+	).Replace(config.WhatsappLocationThumbnailURL)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("static map request failed with status: %s", resp.Status)
+	}
+
+	img, err := imaging.Decode(io.LimitReader(resp.Body, locationThumbnailMaxSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode static map: %w", err)
+	}
+	var thumbnail bytes.Buffer
+	err = imaging.Encode(&thumbnail, imaging.Fit(img, locationThumbnailSize, locationThumbnailSize, imaging.Lanczos), imaging.JPEG, imaging.JPEGQuality(80))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode location thumbnail: %w", err)
+	}
+	return thumbnail.Bytes(), nil
+}
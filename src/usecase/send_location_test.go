@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticMapThumbnail(t *testing.T) {
+	originalURL := config.WhatsappLocationThumbnailURL
+	t.Cleanup(func() { config.WhatsappLocationThumbnailURL = originalURL })
+
+	config.WhatsappLocationThumbnailURL = ""
+	thumbnail, err := staticMapThumbnail(context.Background(), -7.797068, 110.370529)
+	require.NoError(t, err)
+	assert.Nil(t, thumbnail, "thumbnails are off without a URL")
+
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+		img.Set(10, 10, color.RGBA{R: 255, A: 255})
+		_ = png.Encode(w, img)
+	}))
+	t.Cleanup(server.Close)
+
+	config.WhatsappLocationThumbnailURL = server.URL + "/map?center={lat},{lng}"
+	thumbnail, err = staticMapThumbnail(context.Background(), -7.797068, 110.370529)
+	require.NoError(t, err)
+	assert.Equal(t, "center=-7.797068,110.370529", query)
+
+	decoded, format, err := image.Decode(bytes.NewReader(thumbnail))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, image.Pt(100, 50), decoded.Bounds().Size())
+}
+
+func TestLocationThumbnailFailureIsIgnored(t *testing.T) {
+	originalURL := config.WhatsappLocationThumbnailURL
+	t.Cleanup(func() { config.WhatsappLocationThumbnailURL = originalURL })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	t.Cleanup(server.Close)
+
+	config.WhatsappLocationThumbnailURL = server.URL
+	assert.Nil(t, locationThumbnail(context.Background(), 1, 2))
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// mentionToken matches a number mentioned in a text, e.g. @628123456789 or
+// @+628123456789.
+var mentionToken = regexp.MustCompile(`@\+?(\d+)`)
+
+// groupInfoFn fetches a group with its participants; replaced in tests.
+var groupInfoFn = func(ctx context.Context, client *whatsmeow.Client, jid types.JID) (*types.GroupInfo, error) {
+	return client.GetGroupInfo(ctx, jid)
+}
+
+// resolveMentions works out who text mentions when sent to recipient, and
+// returns the text rewritten to the @number form WhatsApp expects.
+func (service serviceSend) resolveMentions(ctx context.Context, client *whatsmeow.Client, recipient types.JID, text string, request domainSend.MentionRequest) (string, []string, error) {
+	mentionAll := request.MentionAll || slices.Contains(request.Mentions, "@everyone")
+
+	if recipient.Server != types.GroupServer {
+		if request.MentionAll {
+			return "", nil, pkgError.ValidationError("mention_all can only be used when sending to a group")
+		}
+		text = mentionToken.ReplaceAllString(text, "@$1")
+		mentions := append(service.getMentionFromText(ctx, text), service.getMentionsFromList(ctx, request.Mentions)...)
+		return text, utils.UniqueStrings(mentions), nil
+	}
+
+	if !mentionAll && len(request.Mentions) == 0 && !mentionToken.MatchString(text) {
+		return text, nil, nil
+	}
+	group, err := groupInfoFn(ctx, client, recipient)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get the participants of %s for mentions: %w", recipient, err)
+	}
+	return mentionGroupParticipants(text, request.Mentions, mentionAll, group.Participants)
+}
+
+// mentionGroupParticipants matches the numbers written in text and the
+// explicit mentions against the participants of a group. Numbers in the text
+// are rewritten to the JID the group addresses the participant by, so that
+// groups using LIDs still ping them; numbers that aren't participants are
+// left as plain text. Explicit mentions of non-participants are refused.
+func mentionGroupParticipants(text string, mentions []string, mentionAll bool, participants []types.GroupParticipant) (string, []string, error) {
+	if mentionAll && len(participants) > config.WhatsappMentionAllMaxParticipants {
+		return "", nil, pkgError.ValidationError(fmt.Sprintf("group has %d participants, mention_all is limited to %d", len(participants), config.WhatsappMentionAllMaxParticipants))
+	}
+
+	// Participants by the user part of their JID, phone number and LID
+	byUser := make(map[string]types.JID, len(participants)*2)
+	for _, participant := range participants {
+		for _, id := range []types.JID{participant.JID, participant.PhoneNumber, participant.LID} {
+			if !id.IsEmpty() {
+				byUser[id.User] = participant.JID
+			}
+		}
+	}
+
+	var mentioned []string
+	seen := make(map[types.JID]bool)
+	mention := func(jid types.JID) {
+		if !seen[jid] {
+			seen[jid] = true
+			mentioned = append(mentioned, jid.String())
+		}
+	}
+
+	text = mentionToken.ReplaceAllStringFunc(text, func(token string) string {
+		jid, ok := byUser[strings.TrimLeft(token, "@+")]
+		if !ok {
+			return token
+		}
+		mention(jid)
+		return "@" + jid.User
+	})
+
+	var notParticipants []string
+	for _, m := range mentions {
+		if m == "@everyone" {
+			continue
+		}
+		user, _, _ := strings.Cut(strings.TrimPrefix(m, "+"), "@")
+		jid, ok := byUser[user]
+		if !ok {
+			notParticipants = append(notParticipants, m)
+			continue
+		}
+		mention(jid)
+	}
+	if len(notParticipants) > 0 {
+		return "", nil, pkgError.ValidationError(fmt.Sprintf("can't mention %s: not a participant of the group", strings.Join(notParticipants, ", ")))
+	}
+
+	if mentionAll {
+		for _, participant := range participants {
+			mention(participant.JID)
+		}
+	}
+	return text, mentioned, nil
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestMentionGroupParticipants(t *testing.T) {
+	pn := types.NewJID("628111", types.DefaultUserServer)
+	lid := types.NewJID("12345", types.HiddenUserServer)
+	participants := []types.GroupParticipant{
+		{JID: pn, PhoneNumber: pn},
+		{JID: lid, PhoneNumber: types.NewJID("628222", types.DefaultUserServer), LID: lid},
+	}
+
+	t.Run("rewrites numbers in the text", func(t *testing.T) {
+		text, mentions, err := mentionGroupParticipants("hi @+628111 and @628222, not @628999", nil, false, participants)
+		require.NoError(t, err)
+		assert.Equal(t, "hi @628111 and @12345, not @628999", text, "LID participants are written as their LID")
+		assert.Equal(t, []string{pn.String(), lid.String()}, mentions)
+	})
+
+	t.Run("explicit mentions", func(t *testing.T) {
+		text, mentions, err := mentionGroupParticipants("hello", []string{"+628222", "628111@s.whatsapp.net", "628222"}, false, participants)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", text)
+		assert.Equal(t, []string{lid.String(), pn.String()}, mentions)
+	})
+
+	t.Run("refuses non-participants", func(t *testing.T) {
+		_, _, err := mentionGroupParticipants("hello", []string{"628111", "628999", "628888"}, false, participants)
+		assert.Equal(t, pkgError.ValidationError("can't mention 628999, 628888: not a participant of the group"), err)
+	})
+
+	t.Run("mention all", func(t *testing.T) {
+		_, mentions, err := mentionGroupParticipants("@628222 meeting at 10", nil, true, participants)
+		require.NoError(t, err)
+		assert.Equal(t, []string{lid.String(), pn.String()}, mentions)
+
+		original := config.WhatsappMentionAllMaxParticipants
+		config.WhatsappMentionAllMaxParticipants = 1
+		t.Cleanup(func() { config.WhatsappMentionAllMaxParticipants = original })
+		_, _, err = mentionGroupParticipants("meeting at 10", nil, true, participants)
+		assert.Equal(t, pkgError.ValidationError("group has 2 participants, mention_all is limited to 1"), err)
+	})
+}
+
+func TestResolveMentions(t *testing.T) {
+	group := types.NewJID("120363000000000000", types.GroupServer)
+	var fetched int
+	originalGroupInfo := groupInfoFn
+	groupInfoFn = func(context.Context, *whatsmeow.Client, types.JID) (*types.GroupInfo, error) {
+		fetched++
+		return &types.GroupInfo{Participants: []types.GroupParticipant{{JID: types.NewJID("628111", types.DefaultUserServer)}}}, nil
+	}
+	t.Cleanup(func() { groupInfoFn = originalGroupInfo })
+
+	service := serviceSend{}
+	text, mentions, err := service.resolveMentions(context.Background(), nil, group, "no mentions here", domainSend.MentionRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "no mentions here", text)
+	assert.Empty(t, mentions)
+	assert.Zero(t, fetched, "participants are only fetched when something is mentioned")
+
+	_, mentions, err = service.resolveMentions(context.Background(), nil, group, "all hands", domainSend.MentionRequest{Mentions: []string{"@everyone"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"628111@s.whatsapp.net"}, mentions)
+
+	_, _, err = service.resolveMentions(context.Background(), nil, types.NewJID("628111", types.DefaultUserServer), "hi", domainSend.MentionRequest{MentionAll: true})
+	assert.Equal(t, pkgError.ValidationError("mention_all can only be used when sending to a group"), err)
+}
@@ -0,0 +1,367 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	fiberUtils "github.com/gofiber/fiber/v2/utils"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+)
+
+const (
+	outboundQueueSize = 1000
+	// outboundJobTTL is how long finished async jobs stay readable
+	outboundJobTTL = time.Hour
+	// outboundPruneSize is how many chats or jobs are tracked before the
+	// stale ones are dropped
+	outboundPruneSize = 1024
+)
+
+var errOutboundQueueFull = errors.New("send queue is full, try again later")
+
+// outboundQueue sends messages through a pool of workers. Sends of a device
+// are spaced out by interval, sends to the same chat by recipientGap, and
+// sends that fail for a transient reason are retried with backoff.
+type outboundQueue struct {
+	jobs         chan *sendJob
+	interval     time.Duration
+	recipientGap time.Duration
+	maxRetries   int
+	retryDelay   time.Duration // Doubled after every attempt
+
+	// pending counts jobs queued or being sent, including async bulk sends
+	pending utils.InFlight
+
+	mu       sync.Mutex
+	nextSend map[string]time.Time // Earliest next send per device
+	lastSend map[string]time.Time // Last send per device and chat
+	tracked  map[string]*sendJob  // Async jobs by ID
+}
+
+// sendJob is a message waiting for or going through the queue. info and
+// finished are guarded by the queue's mutex.
+type sendJob struct {
+	ctx       context.Context
+	deviceID  string
+	client    *whatsmeow.Client
+	recipient types.JID
+	msg       *waE2E.Message
+	typing    time.Duration // Typing indicator shown before the first attempt
+	onSent    func(context.Context, whatsmeow.SendResponse)
+
+	done     chan struct{}
+	resp     whatsmeow.SendResponse
+	err      error
+	info     domainSend.Job
+	finished time.Time
+}
+
+// newOutboundQueue starts workers sending at most rate messages per second per
+// device. A rate of zero or less doesn't limit sends.
+func newOutboundQueue(workers int, rate float64, recipientGap time.Duration, maxRetries int) *outboundQueue {
+	q := &outboundQueue{
+		jobs:         make(chan *sendJob, outboundQueueSize),
+		recipientGap: recipientGap,
+		maxRetries:   max(maxRetries, 0),
+		retryDelay:   time.Second,
+		nextSend:     make(map[string]time.Time),
+		lastSend:     make(map[string]time.Time),
+		tracked:      make(map[string]*sendJob),
+	}
+	if rate > 0 {
+		q.interval = time.Duration(float64(time.Second) / rate)
+	}
+	for range max(workers, 1) {
+		go q.work()
+	}
+	return q
+}
+
+func newSendJob(ctx context.Context, client *whatsmeow.Client, recipient types.JID, msg *waE2E.Message) *sendJob {
+	return &sendJob{
+		ctx:       ctx,
+		deviceID:  deviceIDFromContext(ctx),
+		client:    client,
+		recipient: recipient,
+		msg:       msg,
+		done:      make(chan struct{}),
+		info: domainSend.Job{
+			ID:        fiberUtils.UUIDv4(),
+			Status:    domainSend.JobStatusQueued,
+			Recipient: recipient.String(),
+			MessageID: client.GenerateMessageID(),
+			QueuedAt:  time.Now(),
+		},
+	}
+}
+
+// send queues job and waits for it to be sent. A job whose context ends
+// while it waits is dropped by the worker unless it is already being sent.
+func (q *outboundQueue) send(job *sendJob) (whatsmeow.SendResponse, error) {
+	q.pending.Add()
+	select {
+	case q.jobs <- job:
+	case <-job.ctx.Done():
+		q.pending.Done()
+		return whatsmeow.SendResponse{}, job.ctx.Err()
+	}
+	select {
+	case <-job.done:
+		return job.resp, job.err
+	case <-job.ctx.Done():
+		return whatsmeow.SendResponse{}, job.ctx.Err()
+	}
+}
+
+// submit queues job without waiting for it. The job can be looked up by its
+// ID until outboundJobTTL after it finished.
+func (q *outboundQueue) submit(job *sendJob) error {
+	q.pending.Add()
+	q.track(job)
+
+	select {
+	case q.jobs <- job:
+		return nil
+	default:
+		q.mu.Lock()
+		delete(q.tracked, job.info.ID)
+		q.mu.Unlock()
+		q.pending.Done()
+		return errOutboundQueueFull
+	}
+}
+
+// drain waits for the queued jobs to be sent. New requests are refused by
+// then, but the jobs of requests in progress are still taken. Async jobs
+// still unsent when ctx ends are lost with the process, so they are marked
+// failed and logged for their messages to be sent again.
+func (q *outboundQueue) drain(ctx context.Context) error {
+	err := q.pending.Wait(ctx)
+	if err == nil {
+		return nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	for _, job := range q.tracked {
+		if job.info.Status != domainSend.JobStatusQueued {
+			continue
+		}
+		job.info.Status, job.info.Error = domainSend.JobStatusFailed, "server shut down before the message was sent"
+		job.finished = now
+		logrus.WithContext(job.ctx).Warnf("Send job %s to %s (message %s) was not sent before shutdown", job.info.ID, job.info.Recipient, job.info.MessageID)
+	}
+	return fmt.Errorf("%d message(s) still queued: %w", q.pending.Count(), err)
+}
+
+// Drain waits until the queued messages are sent or ctx ends.
+func (service serviceSend) Drain(ctx context.Context) error {
+	return service.queue.drain(ctx)
+}
+
+// track makes job readable by its ID, also for jobs that never go through
+// the workers such as bulk sends.
+func (q *outboundQueue) track(job *sendJob) {
+	q.mu.Lock()
+	q.pruneJobs(time.Now())
+	q.tracked[job.info.ID] = job
+	q.mu.Unlock()
+}
+
+// GetJob reports the progress of a message sent in async mode by the device
+// in ctx.
+func (service serviceSend) GetJob(ctx context.Context, jobID string) (domainSend.Job, error) {
+	job, info, ok := service.queue.job(jobID)
+	if !ok || job.deviceID != deviceIDFromContext(ctx) {
+		return domainSend.Job{}, fmt.Errorf("send job %s not found", jobID)
+	}
+	return info, nil
+}
+
+// job returns a snapshot of the async job with the given ID.
+func (q *outboundQueue) job(jobID string) (*sendJob, domainSend.Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.tracked[jobID]
+	if !ok {
+		return nil, domainSend.Job{}, false
+	}
+	info := job.info
+	info.Results = slices.Clone(info.Results)
+	return job, info, true
+}
+
+func (q *outboundQueue) work() {
+	for job := range q.jobs {
+		q.run(job)
+	}
+}
+
+func (q *outboundQueue) run(job *sendJob) {
+	defer q.pending.Done()
+
+	var resp whatsmeow.SendResponse
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = sleepContext(job.ctx, time.Until(q.reserve(job.deviceID, job.recipient))); err != nil {
+			break
+		}
+		if attempt == 0 && job.typing > 0 {
+			if err = simulateTyping(job.ctx, job.client, job.recipient, job.typing); err != nil {
+				break
+			}
+		}
+		resp, err = sendMessageFn(job.ctx, job.client, job.recipient, job.msg, whatsmeow.SendRequestExtra{ID: job.info.MessageID})
+		q.update(job, func(info *domainSend.Job) { info.Attempts = attempt + 1 })
+		if err == nil || attempt >= q.maxRetries || !isTransientSendError(err) {
+			break
+		}
+
+		delay := q.retryDelay << attempt
+		logrus.WithContext(job.ctx).Warnf("Failed to send message %s to %s, retrying in %s: %v", job.info.MessageID, job.recipient, delay, err)
+		if err = sleepContext(job.ctx, delay); err != nil {
+			break
+		}
+	}
+
+	if err == nil && job.onSent != nil {
+		job.onSent(job.ctx, resp)
+	}
+
+	job.resp, job.err = resp, err
+	q.update(job, func(info *domainSend.Job) {
+		job.finished = time.Now()
+		if err != nil {
+			info.Status, info.Error = domainSend.JobStatusFailed, err.Error()
+			return
+		}
+		sentAt := time.Now()
+		info.Status, info.SentAt = domainSend.JobStatusSent, &sentAt
+		if resp.ID != "" {
+			info.MessageID = resp.ID
+		}
+	})
+	close(job.done)
+}
+
+// update changes the reported state of job.
+func (q *outboundQueue) update(job *sendJob, change func(*domainSend.Job)) {
+	q.mu.Lock()
+	change(&job.info)
+	q.mu.Unlock()
+}
+
+// reserve returns when the next message of deviceID to recipient may be sent
+// and books that slot.
+func (q *outboundQueue) reserve(deviceID string, recipient types.JID) time.Time {
+	now := time.Now()
+	if q.interval <= 0 && q.recipientGap <= 0 {
+		return now
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	chatKey := deviceID + "|" + recipient.ToNonAD().String()
+	slot := now
+	if next, ok := q.nextSend[deviceID]; ok && next.After(slot) {
+		slot = next
+	}
+	if last, ok := q.lastSend[chatKey]; ok && last.Add(q.recipientGap).After(slot) {
+		slot = last.Add(q.recipientGap)
+	}
+
+	if len(q.lastSend) >= outboundPruneSize {
+		for key, last := range q.lastSend {
+			if now.Sub(last) >= q.recipientGap {
+				delete(q.lastSend, key)
+			}
+		}
+	}
+	q.nextSend[deviceID] = slot.Add(q.interval)
+	q.lastSend[chatKey] = slot
+	return slot
+}
+
+// pruneJobs drops async jobs that finished more than outboundJobTTL ago.
+// Callers hold q.mu.
+func (q *outboundQueue) pruneJobs(now time.Time) {
+	if len(q.tracked) < outboundPruneSize {
+		return
+	}
+	for id, job := range q.tracked {
+		if job.info.Status != domainSend.JobStatusQueued && now.Sub(job.finished) > outboundJobTTL {
+			delete(q.tracked, id)
+		}
+	}
+}
+
+// isTransientSendError reports whether a failed send may succeed later: the
+// device was offline, WhatsApp didn't answer in time or asked to slow down.
+func isTransientSendError(err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, whatsmeow.ErrNotConnected),
+		errors.Is(err, whatsmeow.ErrMessageTimedOut),
+		errors.Is(err, whatsmeow.ErrIQTimedOut),
+		errors.Is(err, whatsmeow.ErrIQRateOverLimit),
+		errors.Is(err, whatsmeow.ErrIQServiceUnavailable):
+		return true
+	case errors.Is(err, whatsmeow.ErrServerReturnedError):
+		// whatsmeow appends the error code of the send response
+		msg := err.Error()
+		code, _ := strconv.Atoi(msg[strings.LastIndex(msg, " ")+1:])
+		return code == 429 || code == 479 || code == 500 || code == 503
+	}
+	return false
+}
+
+// sleepContext waits for d, or returns the error of ctx if it ends first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sentMessage is a message wrapSendMessage sent, or queued when JobID is set.
+type sentMessage struct {
+	whatsmeow.SendResponse
+	JobID string
+}
+
+// response describes the message to the caller. sent tells what was sent to
+// whom, e.g. "Message sent to 628123".
+func (m sentMessage) response(sent string) domainSend.GenericResponse {
+	if m.JobID != "" {
+		return domainSend.GenericResponse{
+			MessageID: m.ID,
+			JobID:     m.JobID,
+			Status:    fmt.Sprintf("Message queued as job %s", m.JobID),
+		}
+	}
+	return domainSend.GenericResponse{
+		MessageID: m.ID,
+		Status:    fmt.Sprintf("%s (server timestamp: %s)", sent, m.Timestamp.String()),
+	}
+}
@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+func stubSendMessage(t *testing.T, send func(attempt int32, extra whatsmeow.SendRequestExtra) (whatsmeow.SendResponse, error)) *atomic.Int32 {
+	t.Helper()
+	var attempts atomic.Int32
+	originalSend := sendMessageFn
+	sendMessageFn = func(_ context.Context, _ *whatsmeow.Client, _ types.JID, _ *waE2E.Message, extra whatsmeow.SendRequestExtra) (whatsmeow.SendResponse, error) {
+		return send(attempts.Add(1), extra)
+	}
+	t.Cleanup(func() { sendMessageFn = originalSend })
+	return &attempts
+}
+
+func newTestQueue(maxRetries int) *outboundQueue {
+	q := newOutboundQueue(1, 0, 0, maxRetries)
+	q.retryDelay = time.Millisecond
+	return q
+}
+
+func TestOutboundQueueRetriesTransientErrors(t *testing.T) {
+	attempts := stubSendMessage(t, func(attempt int32, extra whatsmeow.SendRequestExtra) (whatsmeow.SendResponse, error) {
+		if attempt < 3 {
+			return whatsmeow.SendResponse{}, whatsmeow.ErrNotConnected
+		}
+		return whatsmeow.SendResponse{ID: extra.ID, Timestamp: time.Now()}, nil
+	})
+
+	q := newTestQueue(3)
+	recipient := types.NewJID("628123", types.DefaultUserServer)
+	job := newSendJob(context.Background(), nil, recipient, &waE2E.Message{Conversation: proto.String("hi")})
+	resp, err := q.send(job)
+	require.NoError(t, err)
+	assert.Equal(t, job.info.MessageID, resp.ID, "retries keep the message ID")
+	assert.Equal(t, int32(3), attempts.Load())
+	assert.Equal(t, 3, job.info.Attempts)
+}
+
+func TestOutboundQueueGivesUp(t *testing.T) {
+	recipient := types.NewJID("628123", types.DefaultUserServer)
+
+	t.Run("after the last retry", func(t *testing.T) {
+		attempts := stubSendMessage(t, func(int32, whatsmeow.SendRequestExtra) (whatsmeow.SendResponse, error) {
+			return whatsmeow.SendResponse{}, fmt.Errorf("%w %d", whatsmeow.ErrServerReturnedError, 479)
+		})
+		_, err := newTestQueue(2).send(newSendJob(context.Background(), nil, recipient, &waE2E.Message{}))
+		require.ErrorIs(t, err, whatsmeow.ErrServerReturnedError)
+		assert.Equal(t, int32(3), attempts.Load())
+	})
+
+	t.Run("on errors that won't go away", func(t *testing.T) {
+		attempts := stubSendMessage(t, func(int32, whatsmeow.SendRequestExtra) (whatsmeow.SendResponse, error) {
+			return whatsmeow.SendResponse{}, whatsmeow.ErrNotLoggedIn
+		})
+		_, err := newTestQueue(2).send(newSendJob(context.Background(), nil, recipient, &waE2E.Message{}))
+		require.ErrorIs(t, err, whatsmeow.ErrNotLoggedIn)
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+}
+
+func TestOutboundQueueAsyncJob(t *testing.T) {
+	release := make(chan struct{})
+	stubSendMessage(t, func(_ int32, extra whatsmeow.SendRequestExtra) (whatsmeow.SendResponse, error) {
+		<-release
+		return whatsmeow.SendResponse{ID: extra.ID, Timestamp: time.Now()}, nil
+	})
+
+	service := serviceSend{queue: newTestQueue(0)}
+	ctx := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-1", nil, nil))
+	recipient := types.NewJID("628123", types.DefaultUserServer)
+
+	var stored atomic.Bool
+	job := newSendJob(ctx, nil, recipient, &waE2E.Message{Conversation: proto.String("hi")})
+	job.onSent = func(context.Context, whatsmeow.SendResponse) { stored.Store(true) }
+	require.NoError(t, service.queue.submit(job))
+
+	info, err := service.GetJob(ctx, job.info.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domainSend.JobStatusQueued, info.Status)
+	assert.Equal(t, recipient.String(), info.Recipient)
+	assert.NotEmpty(t, info.MessageID)
+
+	otherDevice := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-2", nil, nil))
+	_, err = service.GetJob(otherDevice, job.info.ID)
+	assert.Error(t, err, "jobs are only visible to the device that queued them")
+
+	close(release)
+	require.Eventually(t, func() bool {
+		info, err = service.GetJob(ctx, job.info.ID)
+		return err == nil && info.Status == domainSend.JobStatusSent
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.NotNil(t, info.SentAt)
+	assert.Equal(t, 1, info.Attempts)
+	assert.True(t, stored.Load(), "sent messages are stored")
+}
+
+func TestOutboundQueueDrain(t *testing.T) {
+	const sendTime = 100 * time.Millisecond
+	stubSendMessage(t, func(_ int32, extra whatsmeow.SendRequestExtra) (whatsmeow.SendResponse, error) {
+		time.Sleep(sendTime)
+		return whatsmeow.SendResponse{ID: extra.ID, Timestamp: time.Now()}, nil
+	})
+	recipient := types.NewJID("628123", types.DefaultUserServer)
+
+	t.Run("waits for queued sends", func(t *testing.T) {
+		service := serviceSend{queue: newTestQueue(0)}
+		first := newSendJob(context.Background(), nil, recipient, &waE2E.Message{})
+		second := newSendJob(context.Background(), nil, recipient, &waE2E.Message{})
+		require.NoError(t, service.queue.submit(first))
+		require.NoError(t, service.queue.submit(second))
+
+		start := time.Now()
+		require.NoError(t, service.Drain(context.Background()))
+		assert.GreaterOrEqual(t, time.Since(start), 2*sendTime, "the single worker sends one job after the other")
+		_, info, _ := service.queue.job(second.info.ID)
+		assert.Equal(t, domainSend.JobStatusSent, info.Status)
+	})
+
+	t.Run("gives up when the timeout ends", func(t *testing.T) {
+		service := serviceSend{queue: newTestQueue(0)}
+		first := newSendJob(context.Background(), nil, recipient, &waE2E.Message{})
+		second := newSendJob(context.Background(), nil, recipient, &waE2E.Message{})
+		require.NoError(t, service.queue.submit(first))
+		require.NoError(t, service.queue.submit(second))
+
+		ctx, cancel := context.WithTimeout(context.Background(), sendTime/2)
+		defer cancel()
+		err := service.Drain(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "2 message(s) still queued")
+		_, info, _ := service.queue.job(second.info.ID)
+		assert.Equal(t, domainSend.JobStatusFailed, info.Status, "unsent jobs are reported as lost")
+
+		// Let the workers finish before the send stub is restored
+		require.NoError(t, service.Drain(context.Background()))
+	})
+}
+
+func TestOutboundQueueReserve(t *testing.T) {
+	q := &outboundQueue{
+		interval:     100 * time.Millisecond,
+		recipientGap: time.Second,
+		nextSend:     make(map[string]time.Time),
+		lastSend:     make(map[string]time.Time),
+	}
+	alice := types.NewJID("628111", types.DefaultUserServer)
+	bob := types.NewJID("628222", types.DefaultUserServer)
+
+	first := q.reserve("dev-1", alice)
+	second := q.reserve("dev-1", bob)
+	third := q.reserve("dev-1", alice)
+	other := q.reserve("dev-2", alice)
+
+	assert.Equal(t, 100*time.Millisecond, second.Sub(first), "sends of a device are spaced by the rate")
+	assert.Equal(t, time.Second, third.Sub(first), "sends to the same chat are spaced by the gap")
+	assert.WithinDuration(t, first, other, 50*time.Millisecond, "devices are limited separately")
+}
+
+func TestIsTransientSendError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{whatsmeow.ErrNotConnected, true},
+		{whatsmeow.ErrMessageTimedOut, true},
+		{whatsmeow.ErrIQRateOverLimit, true},
+		{fmt.Errorf("%w %d", whatsmeow.ErrServerReturnedError, 479), true},
+		{fmt.Errorf("%w %d", whatsmeow.ErrServerReturnedError, 400), false},
+		{whatsmeow.ErrNotLoggedIn, false},
+		{errors.New("invalid JID"), false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, isTransientSendError(tt.err), "%v", tt.err)
+	}
+}
+
+func TestSentMessageResponse(t *testing.T) {
+	sentAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	sent := sentMessage{SendResponse: whatsmeow.SendResponse{ID: "3EB0SENT", Timestamp: sentAt}}
+	assert.Equal(t, domainSend.GenericResponse{
+		MessageID: "3EB0SENT",
+		Status:    "Message sent to 628123 (server timestamp: " + sentAt.String() + ")",
+	}, sent.response("Message sent to 628123"))
+
+	queued := sentMessage{SendResponse: whatsmeow.SendResponse{ID: "3EB0QUEUED"}, JobID: "job-1"}
+	assert.Equal(t, domainSend.GenericResponse{
+		MessageID: "3EB0QUEUED",
+		JobID:     "job-1",
+		Status:    "Message queued as job job-1",
+	}, queued.response("Message sent to 628123"))
+}
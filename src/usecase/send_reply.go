@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// applyReply makes msg quote request.ReplyMessageID. The quoted sender and
+// content come from chat storage; a message that isn't stored is quoted by
+// its ID only, or refused in strict mode.
+func (service serviceSend) applyReply(ctx context.Context, client *whatsmeow.Client, chat types.JID, msg *waE2E.Message, request domainSend.BaseRequest) error {
+	if request.ReplyMessageID == nil || *request.ReplyMessageID == "" {
+		return nil
+	}
+	replyID := *request.ReplyMessageID
+	contextInfo := messageContextInfo(msg)
+	if contextInfo == nil {
+		return nil
+	}
+
+	quoted, err := service.chatStorageRepo.GetMessageByDevice(ctx, deviceIDFromContext(ctx), replyID)
+	if err != nil {
+		if request.ReplyStrict {
+			return fmt.Errorf("failed to look up reply message %s: %w", replyID, err)
+		}
+		logrus.WithContext(ctx).Warnf("Error retrieving reply message ID %s: %v, quoting it by ID only", replyID, err)
+		quoted = nil
+	}
+	if quoted != nil && !sameChat(quoted.ChatJID, chat) {
+		quoted = nil
+	}
+	if quoted == nil && request.ReplyStrict {
+		return pkgError.NotFoundError(fmt.Sprintf("reply message %s not found in chat %s", replyID, chat))
+	}
+
+	if *contextInfo == nil {
+		*contextInfo = &waE2E.ContextInfo{}
+	}
+	(*contextInfo).StanzaID = proto.String(replyID)
+	if quoted == nil {
+		logrus.WithContext(ctx).Debugf("Reply message ID %s not found in storage, quoting it by ID only", replyID)
+		return nil
+	}
+	(*contextInfo).Participant = proto.String(quotedParticipant(client, chat, quoted).String())
+	(*contextInfo).QuotedMessage = quotedMessage(quoted)
+	return nil
+}
+
+// sameChat reports whether a message stored in storedChat belongs to chat.
+// Direct chats may be stored under the LID or the phone number of the
+// contact, so only group chats have to match exactly.
+func sameChat(storedChat string, chat types.JID) bool {
+	stored, err := types.ParseJID(storedChat)
+	if err != nil {
+		return false
+	}
+	if stored.Server == types.GroupServer || chat.Server == types.GroupServer {
+		return stored.ToNonAD() == chat.ToNonAD()
+	}
+	return true
+}
+
+// quotedParticipant returns who sent the quoted message: a member in groups,
+// which differs from the chat JID, and either side in direct chats.
+func quotedParticipant(client *whatsmeow.Client, chat types.JID, quoted *domainChatStorage.Message) types.JID {
+	if sender, err := types.ParseJID(quoted.Sender); err == nil && !sender.IsEmpty() {
+		return sender.ToNonAD()
+	}
+	if quoted.IsFromMe && client != nil && client.Store != nil && client.Store.ID != nil {
+		return client.Store.ID.ToNonAD()
+	}
+	return chat.ToNonAD()
+}
+
+// quotedMessage rebuilds the preview of a stored message shown above the
+// reply.
+func quotedMessage(quoted *domainChatStorage.Message) *waE2E.Message {
+	switch quoted.MediaType {
+	case "image":
+		return &waE2E.Message{ImageMessage: &waE2E.ImageMessage{Caption: proto.String(quoted.Content)}}
+	case "video":
+		return &waE2E.Message{VideoMessage: &waE2E.VideoMessage{Caption: proto.String(quoted.Content)}}
+	case "audio":
+		return &waE2E.Message{AudioMessage: &waE2E.AudioMessage{}}
+	case "sticker":
+		return &waE2E.Message{StickerMessage: &waE2E.StickerMessage{}}
+	case "document":
+		return &waE2E.Message{DocumentMessage: &waE2E.DocumentMessage{
+			FileName: proto.String(quoted.Filename),
+			Caption:  proto.String(quoted.Content),
+		}}
+	}
+	return &waE2E.Message{Conversation: proto.String(quoted.Content)}
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestApplyReply(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+
+	contact := types.NewJID("628111", types.DefaultUserServer)
+	group := types.NewJID("120363000000000000", types.GroupServer)
+	for _, message := range []*domainChatStorage.Message{
+		{ID: "OWN", ChatJID: contact.String(), Sender: "628999:3@s.whatsapp.net", IsFromMe: true, Content: "see you at 10"},
+		{ID: "GROUP", ChatJID: group.String(), Sender: "628222@s.whatsapp.net", Content: "the plan", MediaType: "image"},
+	} {
+		message.DeviceID, message.Timestamp = "dev-1", time.Now()
+		require.NoError(t, repo.StoreMessage(context.Background(), message))
+	}
+
+	service := serviceSend{chatStorageRepo: repo, queue: newTestQueue(0)}
+	ctx := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-1", nil, nil))
+	reply := func(chat types.JID, id string, strict bool) (*waE2E.ContextInfo, error) {
+		msg := &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{Text: proto.String("ok")}}
+		err := service.applyReply(ctx, nil, chat, msg, domainSend.BaseRequest{ReplyMessageID: &id, ReplyStrict: strict})
+		return msg.GetExtendedTextMessage().GetContextInfo(), err
+	}
+
+	t.Run("own message", func(t *testing.T) {
+		info, err := reply(contact, "OWN", false)
+		require.NoError(t, err)
+		assert.Equal(t, "OWN", info.GetStanzaID())
+		assert.Equal(t, "628999@s.whatsapp.net", info.GetParticipant(), "the device part is dropped")
+		assert.Equal(t, "see you at 10", info.GetQuotedMessage().GetConversation())
+	})
+
+	t.Run("group message", func(t *testing.T) {
+		info, err := reply(group, "GROUP", true)
+		require.NoError(t, err)
+		assert.Equal(t, "628222@s.whatsapp.net", info.GetParticipant(), "the sender, not the group")
+		assert.Equal(t, "the plan", info.GetQuotedMessage().GetImageMessage().GetCaption())
+	})
+
+	t.Run("message that isn't stored", func(t *testing.T) {
+		info, err := reply(contact, "UNKNOWN", false)
+		require.NoError(t, err)
+		assert.Equal(t, "UNKNOWN", info.GetStanzaID())
+		assert.Nil(t, info.Participant)
+		assert.Nil(t, info.QuotedMessage)
+
+		_, err = reply(contact, "UNKNOWN", true)
+		assert.IsType(t, pkgError.NotFoundError(""), err)
+	})
+
+	t.Run("message of another group", func(t *testing.T) {
+		_, err := reply(types.NewJID("120363111111111111", types.GroupServer), "GROUP", true)
+		assert.IsType(t, pkgError.NotFoundError(""), err)
+	})
+
+	t.Run("strict replies that fail aren't sent", func(t *testing.T) {
+		attempts := stubSendMessage(t, func(int32, whatsmeow.SendRequestExtra) (whatsmeow.SendResponse, error) {
+			return whatsmeow.SendResponse{}, nil
+		})
+		id := "UNKNOWN"
+		_, err := service.wrapSendMessage(ctx, nil, contact, &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{}}, "ok",
+			domainSend.BaseRequest{ReplyMessageID: &id, ReplyStrict: true})
+		assert.Error(t, err)
+		assert.Zero(t, attempts.Load())
+	})
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultStatusBackground is the background of text statuses sent without one
+const defaultStatusBackground = "#1E6E4F"
+
+// statusTextColor is the color text statuses are written in, as WhatsApp
+// apps always use white
+const statusTextColor uint32 = 0xFFFFFFFF
+
+// SendStatus posts a status to the status broadcast. whatsmeow sends it to the
+// contacts the account's status privacy settings allow.
+func (service serviceSend) SendStatus(ctx context.Context, request domainSend.StatusRequest) (response domainSend.GenericResponse, err error) {
+	if err = validations.ValidateSendStatus(ctx, request); err != nil {
+		return response, err
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+	utils.MustLogin(client)
+
+	recipient := types.StatusBroadcastJID
+	base := domainSend.BaseRequest{Phone: recipient.String()}
+
+	switch request.Type {
+	case domainSend.StatusTypeImage:
+		return service.sendStatusImage(ctx, client, request, base)
+	case domainSend.StatusTypeVideo:
+		oriVideoPath, err := saveVideo(request.Video, request.VideoURL)
+		if err != nil {
+			return response, err
+		}
+		if response, err = service.sendVideoFile(ctx, client, recipient, oriVideoPath, domainSend.VideoRequest{BaseRequest: base, Caption: request.Caption}); err != nil {
+			return response, err
+		}
+		response.Status = "Video status posted"
+		return response, nil
+	}
+
+	ts, err := service.wrapSendMessage(ctx, client, recipient, statusTextMessage(request), request.Text, base)
+	if err != nil {
+		return response, err
+	}
+	return ts.response("Text status posted"), nil
+}
+
+func (service serviceSend) sendStatusImage(ctx context.Context, client *whatsmeow.Client, request domainSend.StatusRequest, base domainSend.BaseRequest) (response domainSend.GenericResponse, err error) {
+	imageData, _, err := readImage(request.Image, request.ImageURL)
+	if err != nil {
+		return response, err
+	}
+	prepared, err := prepareImage(imageData, config.WhatsappImageMaxDimension)
+	if errors.Is(err, errAnimatedImage) {
+		return response, pkgError.ValidationError("animated images can't be posted as an image status, post them as a video status")
+	}
+	if err != nil {
+		return response, err
+	}
+
+	uploaded, err := client.Upload(ctx, prepared.data, whatsmeow.MediaImage)
+	if err != nil {
+		return response, pkgError.InternalServerError(fmt.Sprintf("Failed to upload file: %v", err))
+	}
+
+	msg := &waE2E.Message{ImageMessage: &waE2E.ImageMessage{
+		JPEGThumbnail: prepared.thumbnail,
+		Caption:       proto.String(request.Caption),
+		URL:           proto.String(uploaded.URL),
+		DirectPath:    proto.String(uploaded.DirectPath),
+		MediaKey:      uploaded.MediaKey,
+		Mimetype:      proto.String(prepared.mimeType),
+		FileEncSHA256: uploaded.FileEncSHA256,
+		FileSHA256:    uploaded.FileSHA256,
+		FileLength:    proto.Uint64(uint64(len(prepared.data))),
+		Width:         proto.Uint32(uint32(prepared.width)),
+		Height:        proto.Uint32(uint32(prepared.height)),
+	}}
+
+	content := "🖼️ Image"
+	if request.Caption != "" {
+		content = "🖼️ " + request.Caption
+	}
+	ts, err := service.wrapSendMessage(ctx, client, types.StatusBroadcastJID, msg, content, base)
+	if err != nil {
+		return response, err
+	}
+	return ts.response("Image status posted"), nil
+}
+
+// statusTextMessage builds a text status with its background color and font.
+func statusTextMessage(request domainSend.StatusRequest) *waE2E.Message {
+	background := request.BackgroundColor
+	if background == "" {
+		background = defaultStatusBackground
+	}
+	// Validation only lets #RRGGBB colors through; statuses are opaque
+	rgb, _ := strconv.ParseUint(strings.TrimPrefix(background, "#"), 16, 32)
+
+	text := &waE2E.ExtendedTextMessage{
+		Text:           proto.String(request.Text),
+		TextArgb:       proto.Uint32(statusTextColor),
+		BackgroundArgb: proto.Uint32(0xFF000000 | uint32(rgb)),
+	}
+	if request.Font != "" {
+		text.Font = waE2E.ExtendedTextMessage_FontType(waE2E.ExtendedTextMessage_FontType_value[strings.ToUpper(request.Font)]).Enum()
+	}
+	return &waE2E.Message{ExtendedTextMessage: text}
+}
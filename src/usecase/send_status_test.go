@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"testing"
+
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	"github.com/stretchr/testify/assert"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+)
+
+func TestStatusTextMessage(t *testing.T) {
+	msg := statusTextMessage(domainSend.StatusRequest{Type: "text", Text: "Gone fishing", BackgroundColor: "#FF8800", Font: "fb_script"})
+	text := msg.GetExtendedTextMessage()
+	assert.Equal(t, "Gone fishing", text.GetText())
+	assert.Equal(t, uint32(0xFFFF8800), text.GetBackgroundArgb())
+	assert.Equal(t, statusTextColor, text.GetTextArgb())
+	assert.Equal(t, waE2E.ExtendedTextMessage_FB_SCRIPT, text.GetFont())
+
+	text = statusTextMessage(domainSend.StatusRequest{Type: "text", Text: "hi"}).GetExtendedTextMessage()
+	assert.Equal(t, uint32(0xFF1E6E4F), text.GetBackgroundArgb(), "default background")
+	assert.Nil(t, text.Font)
+}
@@ -1,6 +1,29 @@
 package usecase
 
-import "testing"
+import (
+	"context"
+	"database/sql"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+	_ "modernc.org/sqlite"
+)
 
 func TestResolveDocumentMIME(t *testing.T) {
 	tests := []struct {
@@ -39,3 +62,166 @@ func TestResolveDocumentMIME(t *testing.T) {
 		})
 	}
 }
+
+func TestWrapSendMessageStoresSentText(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+
+	originalSend := sendMessageFn
+	sentAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	sendMessageFn = func(context.Context, *whatsmeow.Client, types.JID, *waE2E.Message, whatsmeow.SendRequestExtra) (whatsmeow.SendResponse, error) {
+		return whatsmeow.SendResponse{ID: "3EB0SENT", Timestamp: sentAt}, nil
+	}
+	t.Cleanup(func() { sendMessageFn = originalSend })
+
+	ctx := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-1", nil, nil))
+	recipient := types.NewJID("628123456789", types.DefaultUserServer)
+	msg := &waE2E.Message{Conversation: proto.String("hello there")}
+
+	service := serviceSend{chatStorageRepo: repo, queue: newOutboundQueue(1, 0, 0, 0)}
+	_, err = service.wrapSendMessage(ctx, nil, recipient, msg, "hello there", domainSend.BaseRequest{})
+	require.NoError(t, err)
+
+	var messages []*domainChatStorage.Message
+	require.Eventually(t, func() bool {
+		messages, err = repo.GetMessages(context.Background(), &domainChatStorage.MessageFilter{DeviceID: "dev-1", ChatJID: recipient.String()})
+		return err == nil && len(messages) == 1
+	}, 2*time.Second, 20*time.Millisecond)
+
+	assert.Equal(t, "3EB0SENT", messages[0].ID)
+	assert.Equal(t, "hello there", messages[0].Content)
+	assert.True(t, messages[0].IsFromMe)
+
+	chat, err := repo.GetChatByDevice(context.Background(), "dev-1", recipient.String())
+	require.NoError(t, err)
+	require.NotNil(t, chat)
+	assert.True(t, sentAt.Equal(chat.LastMessageTime))
+}
+
+func TestWrapSendMessageAppliesChatExpiration(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+
+	var sent *waE2E.Message
+	originalSend := sendMessageFn
+	sendMessageFn = func(_ context.Context, _ *whatsmeow.Client, _ types.JID, msg *waE2E.Message, _ whatsmeow.SendRequestExtra) (whatsmeow.SendResponse, error) {
+		sent = msg
+		return whatsmeow.SendResponse{ID: "3EB0SENT", Timestamp: time.Now()}, nil
+	}
+	t.Cleanup(func() { sendMessageFn = originalSend })
+
+	ctx := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-1", nil, nil))
+	withTimer := types.NewJID("628123456789", types.DefaultUserServer)
+	withoutTimer := types.NewJID("628987654321", types.DefaultUserServer)
+	_, err = repo.SetEphemeralExpiration(ctx, "dev-1", withTimer.String(), 604800)
+	require.NoError(t, err)
+
+	service := serviceSend{chatStorageRepo: repo, queue: newOutboundQueue(1, 0, 0, 0)}
+
+	_, err = service.wrapSendMessage(ctx, nil, withTimer, &waE2E.Message{ImageMessage: &waE2E.ImageMessage{}}, "🖼️ Image", domainSend.BaseRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(604800), sent.GetImageMessage().GetContextInfo().GetExpiration(), "the chat timer applies")
+
+	_, err = service.wrapSendMessage(ctx, nil, withTimer, &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+		Text:        proto.String("hi"),
+		ContextInfo: &waE2E.ContextInfo{Expiration: proto.Uint32(86400)},
+	}}, "hi", domainSend.BaseRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(86400), sent.GetExtendedTextMessage().GetContextInfo().GetExpiration(), "an explicit duration wins")
+
+	_, err = service.wrapSendMessage(ctx, nil, withTimer, viewOnce(&waE2E.Message{ImageMessage: &waE2E.ImageMessage{}}), "🖼️ Image", domainSend.BaseRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(604800), sent.GetViewOnceMessageV2().GetMessage().GetImageMessage().GetContextInfo().GetExpiration(), "view-once media are unwrapped")
+
+	_, err = service.wrapSendMessage(ctx, nil, withoutTimer, &waE2E.Message{ImageMessage: &waE2E.ImageMessage{}}, "🖼️ Image", domainSend.BaseRequest{})
+	require.NoError(t, err)
+	assert.Nil(t, sent.GetImageMessage().GetContextInfo(), "chats without a timer are left alone")
+}
+
+func TestIsAnimatedGIF(t *testing.T) {
+	dir := t.TempDir()
+	palette := color.Palette{color.Black, color.White}
+	writeGIF := func(name string, frames int) string {
+		anim := &gif.GIF{}
+		for i := 0; i < frames; i++ {
+			anim.Image = append(anim.Image, image.NewPaletted(image.Rect(0, 0, 4, 4), palette))
+			anim.Delay = append(anim.Delay, 10)
+		}
+		path := filepath.Join(dir, name)
+		f, err := os.Create(path)
+		require.NoError(t, err)
+		require.NoError(t, gif.EncodeAll(f, anim))
+		require.NoError(t, f.Close())
+		return path
+	}
+
+	assert.True(t, isAnimatedGIF(writeGIF("animated.gif", 3)))
+	assert.False(t, isAnimatedGIF(writeGIF("static.gif", 1)))
+
+	png := filepath.Join(dir, "image.png")
+	require.NoError(t, imaging.Save(image.NewNRGBA(image.Rect(0, 0, 4, 4)), png))
+	assert.False(t, isAnimatedGIF(png))
+	assert.False(t, isAnimatedGIF(filepath.Join(dir, "missing.gif")))
+}
+
+func TestPadStickerCanvas(t *testing.T) {
+	src := imaging.New(512, 256, color.NRGBA{R: 255, A: 255})
+	padded := padStickerCanvas(src)
+
+	assert.Equal(t, 512, padded.Bounds().Dx())
+	assert.Equal(t, 512, padded.Bounds().Dy())
+	_, _, _, alpha := padded.At(0, 0).RGBA()
+	assert.Zero(t, alpha, "padding should be transparent")
+	r, _, _, _ := padded.At(256, 256).RGBA()
+	assert.Equal(t, uint32(0xffff), r, "image should be centered")
+}
+
+func TestBuildContactMessage(t *testing.T) {
+	msg, content := buildContactMessage(domainSend.ContactRequest{
+		ContactName:   "Aldino",
+		ContactPhone:  "+62 812-3456-7890",
+		ContactPhones: []string{"6281234567890", "6221555010"},
+		Organization:  "Acme",
+	})
+	require.NotNil(t, msg.GetContactMessage())
+	assert.Equal(t, "👤 Aldino", content)
+	assert.Equal(t, "Aldino", msg.GetContactMessage().GetDisplayName())
+	assert.Equal(t, "BEGIN:VCARD\nVERSION:3.0\nN:;Aldino;;;\nFN:Aldino\nORG:Acme;\n"+
+		"TEL;type=CELL;waid=6281234567890:+6281234567890\nTEL;type=CELL;waid=6221555010:+6221555010\nEND:VCARD",
+		msg.GetContactMessage().GetVcard(), "numbers are normalized and repeated ones dropped")
+
+	msg, content = buildContactMessage(domainSend.ContactRequest{
+		Contacts: []domainSend.ContactCard{
+			{ContactName: "Aldino", ContactPhone: "6281234567890"},
+			{ContactName: "Budi", ContactPhone: "6289876543210"},
+		},
+	})
+	require.NotNil(t, msg.GetContactsArrayMessage())
+	assert.Equal(t, "👥 Aldino, Budi", content)
+	assert.Equal(t, "2 contacts", msg.GetContactsArrayMessage().GetDisplayName())
+	require.Len(t, msg.GetContactsArrayMessage().GetContacts(), 2)
+	assert.Equal(t, "Budi", msg.GetContactsArrayMessage().GetContacts()[1].GetDisplayName())
+	assert.NotNil(t, messageContextInfo(msg), "contacts arrays take forwarding and expiration context")
+}
+
+func TestIsOggOpus(t *testing.T) {
+	oggPage := func(packet string) []byte {
+		page := append([]byte("OggS"), make([]byte, 24)...)
+		return append(page, packet...)
+	}
+
+	assert.True(t, isOggOpus(oggPage("OpusHead\x01\x01")))
+	assert.False(t, isOggOpus(oggPage("\x01vorbis")), "OGG Vorbis still needs converting")
+	assert.False(t, isOggOpus([]byte("ID3\x04\x00OpusHead")), "only OGG containers count")
+	assert.False(t, isOggOpus(nil))
+}
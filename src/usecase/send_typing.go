@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/sirupsen/logrus"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// sendChatPresenceFn is swapped in tests to avoid a live WhatsApp connection.
+var sendChatPresenceFn = func(ctx context.Context, client *whatsmeow.Client, to types.JID, state types.ChatPresence) error {
+	return client.SendChatPresence(ctx, to, state, types.ChatPresenceMediaText)
+}
+
+// typingDelay is how long someone would take to type text, capped by
+// config.WhatsappTypingDelayMax.
+func typingDelay(text string) time.Duration {
+	delay := time.Duration(len([]rune(text))) * config.WhatsappTypingDelayPerChar
+	return min(delay, config.WhatsappTypingDelayMax)
+}
+
+// simulateTyping shows the typing indicator in chat for d. Failing to send
+// the indicator, e.g. while the socket reconnects, doesn't stop the message
+// from being sent; only the end of ctx does.
+func simulateTyping(ctx context.Context, client *whatsmeow.Client, chat types.JID, d time.Duration) error {
+	if err := sendChatPresenceFn(ctx, client, chat, types.ChatPresenceComposing); err != nil {
+		logrus.WithContext(ctx).Warnf("Failed to send typing presence to %s, sending without it: %v", chat, err)
+		return ctx.Err()
+	}
+	return sleepContext(ctx, d)
+}
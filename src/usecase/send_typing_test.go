@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTypingDelay(t *testing.T) {
+	perChar, maxDelay := config.WhatsappTypingDelayPerChar, config.WhatsappTypingDelayMax
+	t.Cleanup(func() { config.WhatsappTypingDelayPerChar, config.WhatsappTypingDelayMax = perChar, maxDelay })
+	config.WhatsappTypingDelayPerChar, config.WhatsappTypingDelayMax = 50*time.Millisecond, 5*time.Second
+
+	assert.Equal(t, 250*time.Millisecond, typingDelay("hello"))
+	assert.Equal(t, 100*time.Millisecond, typingDelay("👋🏽"), "counted in characters, not bytes")
+	assert.Equal(t, 5*time.Second, typingDelay(strings.Repeat("a", 1000)))
+}
+
+func TestOutboundQueueSimulatesTyping(t *testing.T) {
+	originalPresence := sendChatPresenceFn
+	t.Cleanup(func() { sendChatPresenceFn = originalPresence })
+	recipient := types.NewJID("628123", types.DefaultUserServer)
+
+	t.Run("before the first attempt", func(t *testing.T) {
+		var states []types.ChatPresence
+		sendChatPresenceFn = func(_ context.Context, _ *whatsmeow.Client, _ types.JID, state types.ChatPresence) error {
+			states = append(states, state)
+			return nil
+		}
+		attempts := stubSendMessage(t, func(attempt int32, extra whatsmeow.SendRequestExtra) (whatsmeow.SendResponse, error) {
+			if attempt < 2 {
+				return whatsmeow.SendResponse{}, whatsmeow.ErrNotConnected
+			}
+			return whatsmeow.SendResponse{ID: extra.ID}, nil
+		})
+
+		job := newSendJob(context.Background(), nil, recipient, &waE2E.Message{Conversation: proto.String("hi")})
+		job.typing = 20 * time.Millisecond
+		start := time.Now()
+		_, err := newTestQueue(1).send(job)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), job.typing)
+		assert.Equal(t, []types.ChatPresence{types.ChatPresenceComposing}, states, "retries don't type again")
+		assert.Equal(t, int32(2), attempts.Load())
+	})
+
+	t.Run("sends anyway when the indicator fails", func(t *testing.T) {
+		sendChatPresenceFn = func(context.Context, *whatsmeow.Client, types.JID, types.ChatPresence) error {
+			return whatsmeow.ErrNotConnected
+		}
+		attempts := stubSendMessage(t, func(_ int32, extra whatsmeow.SendRequestExtra) (whatsmeow.SendResponse, error) {
+			return whatsmeow.SendResponse{ID: extra.ID}, nil
+		})
+
+		job := newSendJob(context.Background(), nil, recipient, &waE2E.Message{Conversation: proto.String("hi")})
+		job.typing = time.Hour
+		_, err := newTestQueue(0).send(job)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+}
@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/disintegration/imaging"
+)
+
+const (
+	// videoMaxDimension is the longest side of transcoded videos, and
+	// videoCompressDimension the one used when compression is requested
+	videoMaxDimension      = 1280
+	videoCompressDimension = 720
+	videoThumbnailWidth    = 100
+	// videoGIFMaxSeconds is the longest video sent with GIF playback
+	videoGIFMaxSeconds = 30
+)
+
+// videoProbe is what ffprobe found out about a video file.
+type videoProbe struct {
+	VideoCodec  string
+	PixelFormat string
+	AudioCodec  string // Empty when the video has no sound
+	Width       int    // As displayed, i.e. with rotation applied
+	Height      int
+	Duration    float64 // Seconds
+}
+
+// probeVideo runs ffprobe on path. Files ffprobe can't read or that hold no
+// video are reported as pkgError.InvalidMediaError.
+func probeVideo(path string) (videoProbe, error) {
+	output, err := runFFProbe("-v", "error", "-print_format", "json", "-show_format", "-show_streams", path)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return videoProbe{}, pkgError.InvalidMediaError(fmt.Sprintf("file is not a video: %s", strings.TrimSpace(string(exitErr.Stderr))))
+		}
+		return videoProbe{}, pkgError.InternalServerError(fmt.Sprintf("failed to probe video: %v", err))
+	}
+	return parseVideoProbe(output)
+}
+
+// parseVideoProbe reads the JSON output of ffprobe -show_format -show_streams.
+// Images and cover art are also reported as video streams by ffprobe, so a
+// video needs a duration and a stream that isn't an attached picture.
+func parseVideoProbe(output []byte) (videoProbe, error) {
+	var data struct {
+		Streams []struct {
+			CodecType   string `json:"codec_type"`
+			CodecName   string `json:"codec_name"`
+			PixFmt      string `json:"pix_fmt"`
+			Width       int    `json:"width"`
+			Height      int    `json:"height"`
+			Disposition struct {
+				AttachedPic int `json:"attached_pic"`
+			} `json:"disposition"`
+			Tags struct {
+				Rotate string `json:"rotate"`
+			} `json:"tags"`
+			SideDataList []struct {
+				Rotation int `json:"rotation"`
+			} `json:"side_data_list"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(output, &data); err != nil {
+		return videoProbe{}, pkgError.InternalServerError(fmt.Sprintf("failed to read ffprobe output: %v", err))
+	}
+
+	var probe videoProbe
+	probe.Duration, _ = strconv.ParseFloat(data.Format.Duration, 64)
+	for _, stream := range data.Streams {
+		switch {
+		case stream.CodecType == "video" && stream.Disposition.AttachedPic == 0 && probe.VideoCodec == "":
+			probe.VideoCodec, probe.PixelFormat = stream.CodecName, stream.PixFmt
+			probe.Width, probe.Height = stream.Width, stream.Height
+
+			rotation, _ := strconv.Atoi(stream.Tags.Rotate)
+			for _, sideData := range stream.SideDataList {
+				if sideData.Rotation != 0 {
+					rotation = sideData.Rotation
+				}
+			}
+			if rotation%180 != 0 {
+				probe.Width, probe.Height = probe.Height, probe.Width
+			}
+		case stream.CodecType == "audio" && probe.AudioCodec == "":
+			probe.AudioCodec = stream.CodecName
+		}
+	}
+
+	if probe.VideoCodec == "" || probe.Duration <= 0 {
+		return videoProbe{}, pkgError.InvalidMediaError("file is not a video: no video stream found")
+	}
+	return probe, nil
+}
+
+// needsTranscode reports whether a video has to be converted to play inline
+// everywhere, iOS being the pickiest: H.264 in 8-bit 4:2:0 with AAC sound in
+// an MP4 container, no larger than maxDimension.
+func (p videoProbe) needsTranscode(mimeType string, maxDimension int) bool {
+	return mimeType != "video/mp4" ||
+		p.VideoCodec != "h264" ||
+		p.PixelFormat != "yuv420p" ||
+		(p.AudioCodec != "" && p.AudioCodec != "aac") ||
+		max(p.Width, p.Height) > maxDimension
+}
+
+// transcodeArgs returns the ffmpeg arguments converting input to a compatible
+// MP4. GIF playback drops the sound, as WhatsApp plays those videos muted.
+func transcodeArgs(input, output string, crf, maxDimension int, gifPlayback bool) []string {
+	args := []string{
+		"-y", "-v", "error",
+		"-i", input,
+		"-c:v", "libx264",
+		"-preset", "fast",
+		"-crf", strconv.Itoa(crf),
+		"-pix_fmt", "yuv420p",
+		"-vf", fmt.Sprintf("scale='min(iw,%[1]d)':'min(ih,%[1]d)':force_original_aspect_ratio=decrease:force_divisible_by=2", maxDimension),
+		"-movflags", "+faststart",
+	}
+	if gifPlayback {
+		args = append(args, "-an")
+	} else {
+		args = append(args, "-c:a", "aac", "-b:a", "128k")
+	}
+	return append(args, output)
+}
+
+// transcodeVideo converts input with transcodeArgs, reporting ffmpeg's own
+// error message when it fails.
+func transcodeVideo(input, output string, crf, maxDimension int, gifPlayback bool) error {
+	cmd := exec.Command("ffmpeg", transcodeArgs(input, output, crf, maxDimension, gifPlayback)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return pkgError.InternalServerError(fmt.Sprintf("failed to transcode video: %v: %s", err, strings.TrimSpace(string(out))))
+	}
+	return nil
+}
+
+// sniffContentType detects the MIME type of the file at path from its first
+// bytes.
+func sniffContentType(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+// videoThumbnail grabs a frame at one second, or halfway through shorter
+// videos, as a JPEG thumbnail.
+func videoThumbnail(path string, duration float64) ([]byte, error) {
+	frame, err := runFFMpeg(
+		"-v", "error",
+		"-ss", strconv.FormatFloat(min(1, duration/2), 'f', 3, 64),
+		"-i", path,
+		"-frames:v", "1",
+		"-f", "image2pipe",
+		"-c:v", "png",
+		"pipe:1",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract video frame: %w", err)
+	}
+
+	img, err := imaging.Decode(bytes.NewReader(frame))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode video frame: %w", err)
+	}
+	var thumbnail bytes.Buffer
+	err = imaging.Encode(&thumbnail, imaging.Resize(img, videoThumbnailWidth, 0, imaging.Lanczos), imaging.JPEG, imaging.JPEGQuality(80))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode video thumbnail: %w", err)
+	}
+	return thumbnail.Bytes(), nil
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"testing"
+
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVideoProbe(t *testing.T) {
+	t.Run("rotated phone video", func(t *testing.T) {
+		probe, err := parseVideoProbe([]byte(`{
+			"streams": [
+				{"codec_type": "video", "codec_name": "hevc", "pix_fmt": "yuv420p10le", "width": 1920, "height": 1080,
+				 "side_data_list": [{"rotation": -90}]},
+				{"codec_type": "audio", "codec_name": "aac"}
+			],
+			"format": {"format_name": "mov,mp4,m4a,3gp,3g2,mj2", "duration": "12.480000"}
+		}`))
+		require.NoError(t, err)
+		assert.Equal(t, videoProbe{
+			VideoCodec:  "hevc",
+			PixelFormat: "yuv420p10le",
+			AudioCodec:  "aac",
+			Width:       1080,
+			Height:      1920,
+			Duration:    12.48,
+		}, probe)
+	})
+
+	t.Run("audio with cover art", func(t *testing.T) {
+		_, err := parseVideoProbe([]byte(`{
+			"streams": [
+				{"codec_type": "audio", "codec_name": "mp3"},
+				{"codec_type": "video", "codec_name": "mjpeg", "width": 500, "height": 500, "disposition": {"attached_pic": 1}}
+			],
+			"format": {"format_name": "mp3", "duration": "180.0"}
+		}`))
+		assert.IsType(t, pkgError.InvalidMediaError(""), err)
+	})
+
+	t.Run("still image", func(t *testing.T) {
+		_, err := parseVideoProbe([]byte(`{
+			"streams": [{"codec_type": "video", "codec_name": "png", "width": 64, "height": 64}],
+			"format": {"format_name": "png_pipe"}
+		}`))
+		assert.IsType(t, pkgError.InvalidMediaError(""), err)
+	})
+}
+
+func TestVideoNeedsTranscode(t *testing.T) {
+	compatible := videoProbe{VideoCodec: "h264", PixelFormat: "yuv420p", AudioCodec: "aac", Width: 1280, Height: 720, Duration: 5}
+	assert.False(t, compatible.needsTranscode("video/mp4", videoMaxDimension))
+
+	silent := compatible
+	silent.AudioCodec = ""
+	assert.False(t, silent.needsTranscode("video/mp4", videoMaxDimension), "videos without sound are fine")
+
+	assert.True(t, compatible.needsTranscode("application/octet-stream", videoMaxDimension), "e.g. QuickTime containers")
+	assert.True(t, compatible.needsTranscode("video/mp4", videoCompressDimension), "too large")
+
+	for _, change := range []func(*videoProbe){
+		func(p *videoProbe) { p.VideoCodec = "hevc" },
+		func(p *videoProbe) { p.PixelFormat = "yuv444p" },
+		func(p *videoProbe) { p.AudioCodec = "opus" },
+	} {
+		probe := compatible
+		change(&probe)
+		assert.True(t, probe.needsTranscode("video/mp4", videoMaxDimension), "%+v", probe)
+	}
+}
+
+func TestTranscodeArgs(t *testing.T) {
+	args := transcodeArgs("in.mov", "out.mp4", 23, 720, false)
+	assert.Equal(t, "in.mov", args[4])
+	assert.Equal(t, "out.mp4", args[len(args)-1])
+	assert.Contains(t, args, "23")
+	assert.Contains(t, args, "scale='min(iw,720)':'min(ih,720)':force_original_aspect_ratio=decrease:force_divisible_by=2")
+	assert.Contains(t, args, "aac")
+	assert.NotContains(t, args, "-an")
+
+	gif := transcodeArgs("in.mov", "out.mp4", 28, 1280, true)
+	assert.Contains(t, gif, "-an", "GIF playback drops the sound")
+	assert.NotContains(t, gif, "aac")
+}
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainTemplate "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/template"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
+)
+
+type serviceTemplate struct {
+	chatStorageRepo domainChatStorage.IChatStorageRepository
+}
+
+func NewTemplateService(chatStorageRepo domainChatStorage.IChatStorageRepository) domainTemplate.ITemplateUsecase {
+	return &serviceTemplate{
+		chatStorageRepo: chatStorageRepo,
+	}
+}
+
+func (service serviceTemplate) CreateTemplate(ctx context.Context, request domainTemplate.CreateTemplateRequest) (domainTemplate.Template, error) {
+	deviceID := deviceIDFromContext(ctx)
+	now := time.Now()
+	tmpl := domainTemplate.Template{
+		Name:      strings.TrimSpace(request.Name),
+		Body:      request.Body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := validations.ValidateTemplate(ctx, tmpl); err != nil {
+		return tmpl, err
+	}
+	existing, err := service.chatStorageRepo.GetTemplate(ctx, deviceID, tmpl.Name)
+	if err != nil {
+		return tmpl, fmt.Errorf("failed to get template: %w", err)
+	}
+	if existing != nil {
+		return tmpl, pkgError.ValidationError(fmt.Sprintf("name: a template named %q already exists", tmpl.Name))
+	}
+	return service.store(ctx, deviceID, tmpl)
+}
+
+// ListTemplates returns the templates of the device by name.
+func (service serviceTemplate) ListTemplates(ctx context.Context) ([]domainTemplate.Template, error) {
+	records, err := service.chatStorageRepo.ListTemplates(ctx, deviceIDFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	templates := make([]domainTemplate.Template, 0, len(records))
+	for _, record := range records {
+		templates = append(templates, templateFromRecord(record))
+	}
+	return templates, nil
+}
+
+func (service serviceTemplate) GetTemplate(ctx context.Context, name string) (domainTemplate.Template, error) {
+	record, err := loadTemplate(ctx, service.chatStorageRepo, name)
+	if err != nil {
+		return domainTemplate.Template{}, err
+	}
+	return templateFromRecord(record), nil
+}
+
+func (service serviceTemplate) UpdateTemplate(ctx context.Context, request domainTemplate.UpdateTemplateRequest) (domainTemplate.Template, error) {
+	tmpl, err := service.GetTemplate(ctx, request.Name)
+	if err != nil {
+		return tmpl, err
+	}
+	tmpl.Body = request.Body
+	tmpl.UpdatedAt = time.Now()
+	if err := validations.ValidateTemplate(ctx, tmpl); err != nil {
+		return tmpl, err
+	}
+	return service.store(ctx, deviceIDFromContext(ctx), tmpl)
+}
+
+func (service serviceTemplate) DeleteTemplate(ctx context.Context, name string) error {
+	found, err := service.chatStorageRepo.DeleteTemplate(ctx, deviceIDFromContext(ctx), name)
+	if err != nil {
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+	if !found {
+		return pkgError.NotFoundError(fmt.Sprintf("template %s not found", name))
+	}
+	return nil
+}
+
+func (service serviceTemplate) store(ctx context.Context, deviceID string, tmpl domainTemplate.Template) (domainTemplate.Template, error) {
+	record := &domainChatStorage.Template{
+		DeviceID:  deviceID,
+		Name:      tmpl.Name,
+		Body:      tmpl.Body,
+		CreatedAt: tmpl.CreatedAt,
+		UpdatedAt: tmpl.UpdatedAt,
+	}
+	if err := service.chatStorageRepo.StoreTemplate(ctx, record); err != nil {
+		return tmpl, fmt.Errorf("failed to store template: %w", err)
+	}
+	return templateFromRecord(record), nil
+}
+
+// loadTemplate returns the template of the device in ctx named name.
+func loadTemplate(ctx context.Context, repo domainChatStorage.IChatStorageRepository, name string) (*domainChatStorage.Template, error) {
+	record, err := repo.GetTemplate(ctx, deviceIDFromContext(ctx), name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+	if record == nil {
+		return nil, pkgError.NotFoundError(fmt.Sprintf("template %s not found", name))
+	}
+	return record, nil
+}
+
+// renderTemplate renders the stored template name with variables. Rendering
+// errors are validation errors of the send request.
+func renderTemplate(ctx context.Context, repo domainChatStorage.IChatStorageRepository, name string, variables map[string]string, strict bool) (string, error) {
+	record, err := loadTemplate(ctx, repo, name)
+	if err != nil {
+		return "", err
+	}
+	parsed, err := utils.ParseMessageTemplate(name, record.Body)
+	if err != nil {
+		return "", pkgError.ValidationError(fmt.Sprintf("template %s: %v", name, err))
+	}
+	text, err := parsed.Render(variables, strict)
+	if err != nil {
+		return "", pkgError.ValidationError(fmt.Sprintf("template %s: %v", name, err))
+	}
+	return text, nil
+}
+
+func templateFromRecord(record *domainChatStorage.Template) domainTemplate.Template {
+	tmpl := domainTemplate.Template{
+		Name:      record.Name,
+		Body:      record.Body,
+		Variables: []string{},
+		CreatedAt: record.CreatedAt,
+		UpdatedAt: record.UpdatedAt,
+	}
+	if parsed, err := utils.ParseMessageTemplate(record.Name, record.Body); err == nil && parsed.Variables != nil {
+		tmpl.Variables = parsed.Variables
+	}
+	return tmpl
+}
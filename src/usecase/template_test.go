@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
+	domainTemplate "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/template"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/chatstorage"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateLifecycle(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	repo := chatstorage.NewStorageRepository(db)
+	require.NoError(t, repo.InitializeSchema(context.Background()))
+
+	ctx := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-1", nil, nil))
+	service := serviceTemplate{chatStorageRepo: repo}
+
+	created, err := service.CreateTemplate(ctx, domainTemplate.CreateTemplateRequest{Name: "promo", Body: "Hi {{.name}}, {{.discount}} off {{.day}}!"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name", "discount", "day"}, created.Variables)
+
+	_, err = service.CreateTemplate(ctx, domainTemplate.CreateTemplateRequest{Name: "promo", Body: "Hi"})
+	assert.IsType(t, pkgError.ValidationError(""), err, "names are unique")
+	_, err = service.CreateTemplate(ctx, domainTemplate.CreateTemplateRequest{Name: "loop", Body: "{{range .items}}x{{end}}"})
+	assert.IsType(t, pkgError.ValidationError(""), err, "only placeholders are allowed")
+
+	other := whatsapp.ContextWithDevice(context.Background(), whatsapp.NewDeviceInstance("dev-2", nil, nil))
+	_, err = service.GetTemplate(other, "promo")
+	assert.IsType(t, pkgError.NotFoundError(""), err, "templates belong to a device")
+
+	t.Run("renders bulk texts before sending", func(t *testing.T) {
+		sender := serviceSend{chatStorageRepo: repo}
+		request := domainSend.BulkMessageRequest{
+			Phones:             []string{"628111", "628222"},
+			TemplateRequest:    domainSend.TemplateRequest{TemplateName: "promo", Variables: map[string]string{"name": "there", "discount": "10%", "day": "today"}},
+			RecipientVariables: map[string]map[string]string{"628222": {"name": "Bob & Ann"}},
+		}
+		texts, err := sender.renderBulkTexts(ctx, request)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"628111": "Hi there, 10% off today!",
+			"628222": "Hi Bob & Ann, 10% off today!",
+		}, texts, "values aren't escaped")
+
+		request.TemplateStrict = true
+		request.Variables = map[string]string{"discount": "10%", "day": "today"}
+		_, err = sender.renderBulkTexts(ctx, request)
+		assert.Equal(t, pkgError.ValidationError("phone 628111: template promo: missing variables: name"), err)
+
+		request.TemplateName = "missing"
+		_, err = sender.renderBulkTexts(ctx, request)
+		assert.IsType(t, pkgError.NotFoundError(""), err)
+	})
+
+	updated, err := service.UpdateTemplate(ctx, domainTemplate.UpdateTemplateRequest{Name: "promo", Body: "Hello {{.name}}"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name"}, updated.Variables)
+	assert.True(t, created.CreatedAt.Equal(updated.CreatedAt))
+
+	listed, err := service.ListTemplates(ctx)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "Hello {{.name}}", listed[0].Body)
+
+	require.NoError(t, service.DeleteTemplate(ctx, "promo"))
+	assert.IsType(t, pkgError.NotFoundError(""), service.DeleteTemplate(ctx, "promo"))
+}
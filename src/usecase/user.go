@@ -6,25 +6,32 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"net/http"
 	"time"
 
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
 	domainUser "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/user"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/infrastructure/whatsapp"
 	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/validations"
 	"github.com/disintegration/imaging"
+	"github.com/sirupsen/logrus"
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/appstate"
 	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
 )
 
 type serviceUser struct {
-	// Remove the WaCli field - we'll use the global client instead
+	chatStorageRepo domainChatStorage.IChatStorageRepository
 }
 
-func NewUserService() domainUser.IUserUsecase {
-	return &serviceUser{}
+func NewUserService(chatStorageRepo domainChatStorage.IChatStorageRepository) domainUser.IUserUsecase {
+	return &serviceUser{
+		chatStorageRepo: chatStorageRepo,
+	}
 }
 
 func (service serviceUser) Info(ctx context.Context, request domainUser.InfoRequest) (response domainUser.InfoResponse, err error) {
@@ -61,13 +68,27 @@ func (service serviceUser) Info(ctx context.Context, request domainUser.InfoRequ
 		}
 	}
 
+	// Unknown numbers would otherwise come back as an empty profile
+	if dataWaRecipient.Server == types.DefaultUserServer {
+		checked, err := utils.CheckOnWhatsApp(ctx, client, []string{dataWaRecipient.User})
+		if err != nil {
+			return response, err
+		}
+		if !checked[dataWaRecipient.User].Registered {
+			return response, pkgError.NotFoundError(fmt.Sprintf("%s is not on WhatsApp", dataWaRecipient.User))
+		}
+	}
+
 	jids = append(jids, dataWaRecipient)
 	resp, err := client.GetUserInfo(ctx, jids)
 	if err != nil {
 		return response, err
 	}
+	if len(resp) == 0 {
+		return response, pkgError.NotFoundError(fmt.Sprintf("%s is not on WhatsApp", dataWaRecipient))
+	}
 
-	for _, userInfo := range resp {
+	for jid, userInfo := range resp {
 		var device []domainUser.InfoResponseDataDevice
 		for _, j := range userInfo.Devices {
 			device = append(device, domainUser.InfoResponseDataDevice{
@@ -80,12 +101,20 @@ func (service serviceUser) Info(ctx context.Context, request domainUser.InfoRequ
 		}
 
 		data := domainUser.InfoResponseData{
-			Status:    userInfo.Status,
-			PictureID: userInfo.PictureID,
-			Devices:   device,
+			Status:      userInfo.Status,
+			PictureID:   userInfo.PictureID,
+			DeviceCount: len(userInfo.Devices),
+			Devices:     device,
+		}
+		// The picture and business details are extras, so failing to get them doesn't fail the lookup
+		if data.Picture, err = profilePicture(ctx, client, jid, request.Preview); err != nil {
+			logrus.WithContext(ctx).Warnf("Failed to get profile picture of %s: %v", jid, err)
 		}
 		if userInfo.VerifiedName != nil {
 			data.VerifiedName = fmt.Sprintf("%v", *userInfo.VerifiedName)
+			if data.Business, err = businessDetails(ctx, client, jid); err != nil {
+				logrus.WithContext(ctx).Warnf("Failed to get business profile of %s: %v", jid, err)
+			}
 		}
 		response.Data = append(response.Data, data)
 	}
@@ -157,6 +186,60 @@ func (service serviceUser) Avatar(ctx context.Context, request domainUser.Avatar
 	return response, nil
 }
 
+// AvatarImage fetches the profile picture from the WhatsApp CDN, so clients
+// don't have to follow its URLs, which expire. A hidden or missing picture is
+// not found.
+func (service serviceUser) AvatarImage(ctx context.Context, request domainUser.AvatarRequest) (response domainUser.AvatarImage, err error) {
+	avatar, err := service.Avatar(ctx, request)
+	if errors.Is(err, whatsmeow.ErrProfilePictureUnauthorized) || errors.Is(err, whatsmeow.ErrProfilePictureNotSet) || (err == nil && avatar.URL == "") {
+		return response, pkgError.NotFoundError(fmt.Sprintf("%s has no profile picture you can see", request.Phone))
+	}
+	if err != nil {
+		return response, err
+	}
+
+	// The body is streamed after the handler returns, so the download can't end with ctx
+	req, err := http.NewRequestWithContext(context.WithoutCancel(ctx), http.MethodGet, avatar.URL, nil)
+	if err != nil {
+		return response, err
+	}
+	resp, err := avatarHTTPClient.Do(req)
+	if err != nil {
+		return response, pkgError.InternalServerError(fmt.Sprintf("failed to download profile picture: %v", err))
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return response, pkgError.InternalServerError(fmt.Sprintf("failed to download profile picture: %s", resp.Status))
+	}
+
+	response.Body = resp.Body
+	response.ContentType = resp.Header.Get("Content-Type")
+	if response.ContentType == "" {
+		response.ContentType = "image/jpeg"
+	}
+	response.Size = resp.ContentLength
+	return response, nil
+}
+
+// avatarHTTPClient downloads profile pictures, which are small.
+var avatarHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// profilePicture returns the picture of jid, or nil when they have none or
+// hide it from this account.
+func profilePicture(ctx context.Context, client *whatsmeow.Client, jid types.JID, preview bool) (*domainUser.AvatarResponse, error) {
+	pictureCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	pic, err := client.GetProfilePictureInfo(pictureCtx, jid, &whatsmeow.GetProfilePictureParams{Preview: preview})
+	if errors.Is(err, whatsmeow.ErrProfilePictureUnauthorized) || errors.Is(err, whatsmeow.ErrProfilePictureNotSet) {
+		return nil, nil
+	}
+	if err != nil || pic == nil {
+		return nil, err
+	}
+	return &domainUser.AvatarResponse{URL: pic.URL, ID: pic.ID, Type: pic.Type}, nil
+}
+
 // MyListGroups returns all groups the user has joined.
 //
 // ⚠️ KNOWN LIMITATION: This endpoint returns a maximum of 500 groups due to a WhatsApp protocol limitation.
@@ -221,6 +304,85 @@ func (service serviceUser) MyPrivacySetting(ctx context.Context) (response domai
 	return response, nil
 }
 
+// Privacy fetches the privacy settings of the account from WhatsApp.
+func (service serviceUser) Privacy(ctx context.Context) (response domainUser.PrivacySettings, err error) {
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+	utils.MustLogin(client)
+
+	settings, err := client.TryFetchPrivacySettings(ctx, true)
+	if err != nil {
+		return response, err
+	}
+	return privacySettings(*settings), nil
+}
+
+// SetPrivacy applies the settings of the request one by one and returns the
+// settings in effect afterwards.
+func (service serviceUser) SetPrivacy(ctx context.Context, request domainUser.SetPrivacyRequest) (response domainUser.PrivacySettings, err error) {
+	if err = validations.ValidateSetPrivacy(ctx, request); err != nil {
+		return response, err
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+	utils.MustLogin(client)
+
+	changes := []struct {
+		field   string
+		setting types.PrivacySettingType
+		value   string
+	}{
+		{"last_seen", types.PrivacySettingTypeLastSeen, request.LastSeen},
+		{"profile_photo", types.PrivacySettingTypeProfile, request.ProfilePhoto},
+		{"status", types.PrivacySettingTypeStatus, request.Status},
+		{"read_receipts", types.PrivacySettingTypeReadReceipts, request.ReadReceipts},
+		{"groups_add", types.PrivacySettingTypeGroupAdd, request.GroupsAdd},
+		{"online", types.PrivacySettingTypeOnline, request.Online},
+	}
+	var settings types.PrivacySettings
+	for _, change := range changes {
+		if change.value == "" {
+			continue
+		}
+		if settings, err = client.SetPrivacySetting(ctx, change.setting, privacyValue(change.value)); err != nil {
+			return response, fmt.Errorf("failed to set %s: %w", change.field, err)
+		}
+	}
+	return privacySettings(settings), nil
+}
+
+// privacyValue converts an API privacy value to the one WhatsApp uses, which
+// calls everyone "all".
+func privacyValue(value string) types.PrivacySetting {
+	if value == "everyone" {
+		return types.PrivacySettingAll
+	}
+	return types.PrivacySetting(value)
+}
+
+func privacyName(value types.PrivacySetting) string {
+	if value == types.PrivacySettingAll {
+		return "everyone"
+	}
+	return string(value)
+}
+
+func privacySettings(settings types.PrivacySettings) domainUser.PrivacySettings {
+	return domainUser.PrivacySettings{
+		LastSeen:     privacyName(settings.LastSeen),
+		ProfilePhoto: privacyName(settings.Profile),
+		Status:       privacyName(settings.Status),
+		ReadReceipts: privacyName(settings.ReadReceipts),
+		GroupsAdd:    privacyName(settings.GroupAdd),
+		Online:       privacyName(settings.Online),
+	}
+}
+
 func (service serviceUser) MyListContacts(ctx context.Context) (response domainUser.MyListContactsResponse, err error) {
 	client := whatsapp.ClientFromContext(ctx)
 	if client == nil {
@@ -243,62 +405,91 @@ func (service serviceUser) MyListContacts(ctx context.Context) (response domainU
 	return response, nil
 }
 
-func (service serviceUser) ChangeAvatar(ctx context.Context, request domainUser.ChangeAvatarRequest) (err error) {
+// avatarMaxSize is the side of the square profile pictures WhatsApp shows.
+const avatarMaxSize = 640
+
+// ChangeAvatar sets the profile picture of the account to the center square
+// of the image, scaled down to avatarMaxSize, and returns its ID.
+func (service serviceUser) ChangeAvatar(ctx context.Context, request domainUser.ChangeAvatarRequest) (response domainUser.ChangeAvatarResponse, err error) {
+	if err = validations.ValidateChangeAvatar(ctx, request); err != nil {
+		return response, err
+	}
+
 	client := whatsapp.ClientFromContext(ctx)
 	if client == nil {
-		return pkgError.ErrWaCLI
+		return response, pkgError.ErrWaCLI
 	}
 	utils.MustLogin(client)
 
 	file, err := request.Avatar.Open()
 	if err != nil {
-		return err
+		return response, err
 	}
 	defer file.Close()
 
-	// Read original image
-	srcImage, err := imaging.Decode(file)
+	srcImage, err := imaging.Decode(file, imaging.AutoOrientation(true))
 	if err != nil {
-		return fmt.Errorf("failed to decode image: %v", err)
+		return response, pkgError.ValidationError(fmt.Sprintf("avatar: failed to decode image: %v", err))
 	}
 
-	// Get original dimensions
-	bounds := srcImage.Bounds()
-	width := bounds.Dx()
-	height := bounds.Dy()
+	var buf bytes.Buffer
+	if err = imaging.Encode(&buf, squareAvatar(srcImage), imaging.JPEG, imaging.JPEGQuality(80)); err != nil {
+		return response, fmt.Errorf("failed to encode image: %v", err)
+	}
 
-	// Calculate new dimensions for 1:1 aspect ratio
-	size := width
-	if height < width {
-		size = height
+	// An empty JID targets the profile of the account itself
+	response.PictureID, err = client.SetGroupPhoto(ctx, types.JID{}, buf.Bytes())
+	if err != nil {
+		return response, err
 	}
-	if size > 640 {
-		size = 640
+	if device, ok := whatsapp.DeviceFromContext(ctx); ok {
+		device.SetAvatarID(response.PictureID)
 	}
+	return response, nil
+}
 
-	// Create a square crop from the center
-	left := (width - size) / 2
-	top := (height - size) / 2
-	croppedImage := imaging.Crop(srcImage, image.Rect(left, top, left+size, top+size))
+// squareAvatar crops the center square of img and scales it down to
+// avatarMaxSize when it is larger.
+func squareAvatar(img image.Image) image.Image {
+	bounds := img.Bounds()
+	size := min(bounds.Dx(), bounds.Dy())
+	square := imaging.CropCenter(img, size, size)
+	if size > avatarMaxSize {
+		square = imaging.Resize(square, avatarMaxSize, avatarMaxSize, imaging.Lanczos)
+	}
+	return square
+}
 
-	// Resize if needed
-	if size > 640 {
-		croppedImage = imaging.Resize(croppedImage, 640, 640, imaging.Lanczos)
+// RemoveAvatar removes the profile picture of the account.
+func (service serviceUser) RemoveAvatar(ctx context.Context) (err error) {
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return pkgError.ErrWaCLI
 	}
+	utils.MustLogin(client)
 
-	// Convert to bytes
-	var buf bytes.Buffer
-	err = imaging.Encode(&buf, croppedImage, imaging.JPEG, imaging.JPEGQuality(80))
-	if err != nil {
-		return fmt.Errorf("failed to encode image: %v", err)
+	if _, err = client.SetGroupPhoto(ctx, types.JID{}, nil); err != nil {
+		return err
+	}
+	if device, ok := whatsapp.DeviceFromContext(ctx); ok {
+		device.SetAvatarID("")
 	}
+	return nil
+}
 
-	_, err = client.SetGroupPhoto(ctx, types.JID{}, buf.Bytes())
-	if err != nil {
+// ChangeAbout sets the about text shown on the profile of the account.
+func (service serviceUser) ChangeAbout(ctx context.Context, request domainUser.ChangeAboutRequest) (err error) {
+	if err = validations.ValidateChangeAbout(ctx, request); err != nil {
 		return err
 	}
 
-	return nil
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return pkgError.ErrWaCLI
+	}
+	utils.MustLogin(client)
+
+	return client.SetStatusMessage(ctx, request.About)
 }
 
 func (service serviceUser) ChangePushName(ctx context.Context, request domainUser.ChangePushNameRequest) (err error) {
@@ -315,6 +506,20 @@ func (service serviceUser) ChangePushName(ctx context.Context, request domainUse
 	return nil
 }
 
+func (service serviceUser) ChangePresence(ctx context.Context, request domainUser.ChangePresenceRequest) (err error) {
+	if err = validations.ValidateChangePresence(ctx, request); err != nil {
+		return err
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return pkgError.ErrWaCLI
+	}
+	utils.MustLogin(client)
+
+	return client.SendPresence(ctx, types.Presence(request.State))
+}
+
 func (service serviceUser) IsOnWhatsApp(ctx context.Context, request domainUser.CheckRequest) (response domainUser.CheckResponse, err error) {
 	client := whatsapp.ClientFromContext(ctx)
 	if client == nil {
@@ -329,6 +534,114 @@ func (service serviceUser) IsOnWhatsApp(ctx context.Context, request domainUser.
 	return response, nil
 }
 
+// CheckPhones normalizes every number and checks the valid ones in bulk.
+// Registered numbers get the JID WhatsApp knows them by.
+func (service serviceUser) CheckPhones(ctx context.Context, request domainUser.CheckPhonesRequest) (response domainUser.CheckPhonesResponse, err error) {
+	if err = validations.ValidateCheckPhones(ctx, request); err != nil {
+		return response, err
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+	utils.MustLogin(client)
+
+	response.Data = make([]domainUser.CheckPhoneResult, len(request.Phones))
+	normalized := make([]string, 0, len(request.Phones))
+	for i, phone := range request.Phones {
+		response.Data[i].Input = phone
+		digits, err := utils.NormalizePhoneNumber(phone, config.WhatsappDefaultCountryCode)
+		if err != nil {
+			response.Data[i].Error = err.Error()
+			continue
+		}
+		response.Data[i].NormalizedJID = types.NewJID(digits, types.DefaultUserServer).String()
+		normalized = append(normalized, digits)
+	}
+
+	checked, err := utils.CheckOnWhatsApp(ctx, client, normalized)
+	if err != nil {
+		return domainUser.CheckPhonesResponse{}, err
+	}
+	for i := range response.Data {
+		result := &response.Data[i]
+		if result.Error != "" {
+			continue
+		}
+		if found := checked[utils.ExtractPhoneFromJID(result.NormalizedJID)]; found.Registered {
+			result.Registered = true
+			if !found.JID.IsEmpty() {
+				result.NormalizedJID = found.JID.String()
+			}
+			response.Registered++
+		}
+	}
+	return response, nil
+}
+
+func (service serviceUser) Block(ctx context.Context, request domainUser.BlockRequest) (response domainUser.BlocklistResponse, err error) {
+	return service.updateBlocklist(ctx, request, events.BlocklistChangeActionBlock)
+}
+
+func (service serviceUser) Unblock(ctx context.Context, request domainUser.BlockRequest) (response domainUser.BlocklistResponse, err error) {
+	return service.updateBlocklist(ctx, request, events.BlocklistChangeActionUnblock)
+}
+
+// updateBlocklist blocks or unblocks a contact and stores the blocklist
+// WhatsApp answers with, so GET /chats can filter on it right away.
+func (service serviceUser) updateBlocklist(ctx context.Context, request domainUser.BlockRequest, action events.BlocklistChangeAction) (response domainUser.BlocklistResponse, err error) {
+	if err = validations.ValidateBlock(ctx, request); err != nil {
+		return response, err
+	}
+
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+
+	jid, err := utils.ValidateJidWithLogin(client, request.Phone)
+	if err != nil {
+		return response, err
+	}
+
+	blocklist, err := client.UpdateBlocklist(ctx, jid, action)
+	if err != nil {
+		return response, err
+	}
+	if err := whatsapp.StoreBlocklist(ctx, service.chatStorageRepo, deviceIDFromContext(ctx), client, blocklist); err != nil {
+		logrus.WithError(err).Warn("Failed to store blocklist")
+	}
+	return blocklistResponse(blocklist), nil
+}
+
+// Blocklist fetches the current blocklist from WhatsApp and refreshes the
+// stored blocked flags with it.
+func (service serviceUser) Blocklist(ctx context.Context) (response domainUser.BlocklistResponse, err error) {
+	client := whatsapp.ClientFromContext(ctx)
+	if client == nil {
+		return response, pkgError.ErrWaCLI
+	}
+	utils.MustLogin(client)
+
+	blocklist, err := client.GetBlocklist(ctx)
+	if err != nil {
+		return response, err
+	}
+	if err := whatsapp.StoreBlocklist(ctx, service.chatStorageRepo, deviceIDFromContext(ctx), client, blocklist); err != nil {
+		logrus.WithError(err).Warn("Failed to store blocklist")
+	}
+	return blocklistResponse(blocklist), nil
+}
+
+func blocklistResponse(blocklist *types.Blocklist) domainUser.BlocklistResponse {
+	response := domainUser.BlocklistResponse{Data: make([]string, 0, len(blocklist.JIDs))}
+	for _, jid := range blocklist.JIDs {
+		response.Data = append(response.Data, jid.String())
+	}
+	return response
+}
+
 func (service serviceUser) BusinessProfile(ctx context.Context, request domainUser.BusinessProfileRequest) (response domainUser.BusinessProfileResponse, err error) {
 	err = validations.ValidateBusinessProfile(ctx, request)
 	if err != nil {
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+
+	domainUser "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/user"
+	"go.mau.fi/whatsmeow"
+	waBinary "go.mau.fi/whatsmeow/binary"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// businessDetails gets the public business profile of jid. It sends the
+// query behind client.GetBusinessProfile itself, because whatsmeow doesn't
+// parse the description and websites out of the answer.
+func businessDetails(ctx context.Context, client *whatsmeow.Client, jid types.JID) (*domainUser.InfoBusiness, error) {
+	resp, err := client.DangerousInternals().SendIQ(ctx, whatsmeow.DangerousInfoQuery{
+		Namespace: "w:biz",
+		Type:      "get",
+		To:        types.ServerJID,
+		Content: []waBinary.Node{{
+			Tag:   "business_profile",
+			Attrs: waBinary.Attrs{"v": "244"},
+			Content: []waBinary.Node{{
+				Tag:   "profile",
+				Attrs: waBinary.Attrs{"jid": jid},
+			}},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseBusinessDetails(resp), nil
+}
+
+// parseBusinessDetails reads the answer to a business_profile query. The
+// category is the first one listed, which WhatsApp shows as the main one.
+func parseBusinessDetails(resp *waBinary.Node) *domainUser.InfoBusiness {
+	profile := resp.GetChildByTag("business_profile", "profile")
+	text := func(node waBinary.Node) string {
+		content, _ := node.Content.([]byte)
+		return string(content)
+	}
+
+	business := &domainUser.InfoBusiness{
+		Description: text(profile.GetChildByTag("description")),
+		Email:       text(profile.GetChildByTag("email")),
+		Address:     text(profile.GetChildByTag("address")),
+	}
+	for _, website := range profile.GetChildrenByTag("website") {
+		if url := text(website); url != "" {
+			business.Websites = append(business.Websites, url)
+		}
+	}
+	categories := profile.GetChildByTag("categories")
+	if listed := categories.GetChildrenByTag("category"); len(listed) > 0 {
+		business.Category = text(listed[0])
+	}
+	return business
+}
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"testing"
+
+	domainUser "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/user"
+	"github.com/stretchr/testify/assert"
+	waBinary "go.mau.fi/whatsmeow/binary"
+)
+
+func TestParseBusinessDetails(t *testing.T) {
+	resp := &waBinary.Node{Tag: "iq", Content: []waBinary.Node{{
+		Tag: "business_profile",
+		Content: []waBinary.Node{{
+			Tag: "profile",
+			Content: []waBinary.Node{
+				{Tag: "description", Content: []byte("Fresh bread every morning")},
+				{Tag: "website", Content: []byte("https://bakery.example")},
+				{Tag: "website", Content: []byte("https://shop.bakery.example")},
+				{Tag: "email", Content: []byte("hello@bakery.example")},
+				{Tag: "address", Content: []byte("Jl. Roti 1, Jakarta")},
+				{Tag: "categories", Content: []waBinary.Node{
+					{Tag: "category", Attrs: waBinary.Attrs{"id": "1"}, Content: []byte("Bakery")},
+					{Tag: "category", Attrs: waBinary.Attrs{"id": "2"}, Content: []byte("Cafe")},
+				}},
+			},
+		}},
+	}}}
+
+	assert.Equal(t, &domainUser.InfoBusiness{
+		Category:    "Bakery",
+		Description: "Fresh bread every morning",
+		Websites:    []string{"https://bakery.example", "https://shop.bakery.example"},
+		Email:       "hello@bakery.example",
+		Address:     "Jl. Roti 1, Jakarta",
+	}, parseBusinessDetails(resp))
+
+	assert.Equal(t, &domainUser.InfoBusiness{}, parseBusinessDetails(&waBinary.Node{Tag: "iq"}), "nothing filled in")
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"image"
+	"testing"
+
+	domainUser "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/user"
+	"github.com/stretchr/testify/assert"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestPrivacySettings(t *testing.T) {
+	assert.Equal(t, types.PrivacySettingAll, privacyValue("everyone"))
+	assert.Equal(t, types.PrivacySettingContactBlacklist, privacyValue("contact_blacklist"))
+	assert.Equal(t, types.PrivacySettingMatchLastSeen, privacyValue("match_last_seen"))
+
+	assert.Equal(t, domainUser.PrivacySettings{
+		LastSeen:     "contacts",
+		ProfilePhoto: "everyone",
+		Status:       "contact_blacklist",
+		ReadReceipts: "none",
+		GroupsAdd:    "everyone",
+		Online:       "match_last_seen",
+	}, privacySettings(types.PrivacySettings{
+		LastSeen:     types.PrivacySettingContacts,
+		Profile:      types.PrivacySettingAll,
+		Status:       types.PrivacySettingContactBlacklist,
+		ReadReceipts: types.PrivacySettingNone,
+		GroupAdd:     types.PrivacySettingAll,
+		CallAdd:      types.PrivacySettingKnown,
+		Online:       types.PrivacySettingMatchLastSeen,
+	}))
+}
+
+func TestSquareAvatar(t *testing.T) {
+	square := squareAvatar(image.NewRGBA(image.Rect(0, 0, 1600, 900)))
+	assert.Equal(t, image.Rect(0, 0, avatarMaxSize, avatarMaxSize), square.Bounds(), "large images are scaled down, not cut")
+
+	square = squareAvatar(image.NewRGBA(image.Rect(0, 0, 300, 500)))
+	assert.Equal(t, image.Rect(0, 0, 300, 300), square.Bounds(), "small images keep their size")
+}
@@ -0,0 +1,25 @@
+package validations
+
+import (
+	"context"
+
+	domainAPIKey "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/apikey"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+var apiKeyScopes = []any{domainAPIKey.ScopeRead, domainAPIKey.ScopeSend, domainAPIKey.ScopeAdmin}
+
+func ValidateCreateAPIKey(ctx context.Context, request domainAPIKey.CreateAPIKeyRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.Label, validation.Required, validation.RuneLength(1, 100)),
+		validation.Field(&request.Scopes, validation.Required, validation.Each(validation.In(apiKeyScopes...).Error("must be one of: read, send, admin"))),
+		validation.Field(&request.DeviceIDs, validation.Each(validation.Required, validation.RuneLength(1, 128))),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
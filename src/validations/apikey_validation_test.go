@@ -0,0 +1,55 @@
+package validations
+
+import (
+	"context"
+	"testing"
+
+	domainAPIKey "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/apikey"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCreateAPIKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainAPIKey.CreateAPIKeyRequest
+		err     any
+	}{
+		{
+			name:    "should success with label and scopes",
+			request: domainAPIKey.CreateAPIKeyRequest{Label: "crm", Scopes: []string{"read", "send"}},
+			err:     nil,
+		},
+		{
+			name:    "should success bound to devices",
+			request: domainAPIKey.CreateAPIKeyRequest{Label: "crm", Scopes: []string{"admin"}, DeviceIDs: []string{"sales"}},
+			err:     nil,
+		},
+		{
+			name:    "should error without label",
+			request: domainAPIKey.CreateAPIKeyRequest{Scopes: []string{"read"}},
+			err:     pkgError.ValidationError("label: cannot be blank."),
+		},
+		{
+			name:    "should error without scopes",
+			request: domainAPIKey.CreateAPIKeyRequest{Label: "crm"},
+			err:     pkgError.ValidationError("scopes: cannot be blank."),
+		},
+		{
+			name:    "should error with unknown scope",
+			request: domainAPIKey.CreateAPIKeyRequest{Label: "crm", Scopes: []string{"read", "write"}},
+			err:     pkgError.ValidationError("scopes: (1: must be one of: read, send, admin.)."),
+		},
+		{
+			name:    "should error with empty device id",
+			request: domainAPIKey.CreateAPIKeyRequest{Label: "crm", Scopes: []string{"read"}, DeviceIDs: []string{""}},
+			err:     pkgError.ValidationError("device_ids: (0: cannot be blank.)."),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCreateAPIKey(context.Background(), tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
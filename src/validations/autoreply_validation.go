@@ -0,0 +1,36 @@
+package validations
+
+import (
+	"context"
+	"regexp"
+
+	domainAutoReply "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/autoreply"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+var autoReplyMatchTypes = []any{domainAutoReply.MatchExact, domainAutoReply.MatchContains, domainAutoReply.MatchRegex}
+
+// ValidateAutoReplyRule validates a rule as it will be stored, after defaults
+// and updates are applied.
+func ValidateAutoReplyRule(ctx context.Context, rule domainAutoReply.Rule) error {
+	err := validation.ValidateStructWithContext(ctx, &rule,
+		validation.Field(&rule.Pattern, validation.Required, validation.RuneLength(1, 1024)),
+		validation.Field(&rule.MatchType, validation.Required, validation.In(autoReplyMatchTypes...).Error("must be one of: exact, contains, regex")),
+		validation.Field(&rule.ReplyText, validation.Required, validation.RuneLength(1, 4096)),
+		validation.Field(&rule.Position, validation.Min(0)),
+	)
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	if rule.OnlyGroups && rule.OnlyDM {
+		return pkgError.ValidationError("only_groups and only_dm can't both be set")
+	}
+	if rule.MatchType == domainAutoReply.MatchRegex {
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return pkgError.ValidationError("pattern: invalid regular expression: " + err.Error())
+		}
+	}
+	return nil
+}
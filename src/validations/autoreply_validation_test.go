@@ -0,0 +1,65 @@
+package validations
+
+import (
+	"context"
+	"testing"
+
+	domainAutoReply "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/autoreply"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAutoReplyRule(t *testing.T) {
+	tests := []struct {
+		name string
+		rule domainAutoReply.Rule
+		err  any
+	}{
+		{
+			name: "should success with a keyword",
+			rule: domainAutoReply.Rule{Pattern: "price", MatchType: "contains", ReplyText: "See our catalog"},
+			err:  nil,
+		},
+		{
+			name: "should success with a regex",
+			rule: domainAutoReply.Rule{Pattern: `(?i)^order\s+#?\d+$`, MatchType: "regex", ReplyText: "We're on it", OnlyDM: true},
+			err:  nil,
+		},
+		{
+			name: "should error without pattern",
+			rule: domainAutoReply.Rule{MatchType: "exact", ReplyText: "Hi"},
+			err:  pkgError.ValidationError("pattern: cannot be blank."),
+		},
+		{
+			name: "should error with unknown match type",
+			rule: domainAutoReply.Rule{Pattern: "hi", MatchType: "glob", ReplyText: "Hi"},
+			err:  pkgError.ValidationError("match_type: must be one of: exact, contains, regex."),
+		},
+		{
+			name: "should error without reply",
+			rule: domainAutoReply.Rule{Pattern: "hi", MatchType: "exact"},
+			err:  pkgError.ValidationError("reply_text: cannot be blank."),
+		},
+		{
+			name: "should error with negative position",
+			rule: domainAutoReply.Rule{Pattern: "hi", MatchType: "exact", ReplyText: "Hi", Position: -1},
+			err:  pkgError.ValidationError("position: must be no less than 0."),
+		},
+		{
+			name: "should error when limited to groups and direct chats",
+			rule: domainAutoReply.Rule{Pattern: "hi", MatchType: "exact", ReplyText: "Hi", OnlyGroups: true, OnlyDM: true},
+			err:  pkgError.ValidationError("only_groups and only_dm can't both be set"),
+		},
+		{
+			name: "should error with invalid regex",
+			rule: domainAutoReply.Rule{Pattern: "order (", MatchType: "regex", ReplyText: "Hi"},
+			err:  pkgError.ValidationError("pattern: invalid regular expression: error parsing regexp: missing closing ): `order (`"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAutoReplyRule(context.Background(), tt.rule)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
@@ -2,12 +2,20 @@ package validations
 
 import (
 	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
 
 	domainChat "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chat"
 	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
+// chatTypes are the chat_type filter values accepted when listing chats.
+var chatTypes = []any{"user", "group", "newsletter", "status"}
+
 func ValidateListChats(ctx context.Context, request *domainChat.ListChatsRequest) error {
 	// Set default limit if not provided
 	if request.Limit == 0 {
@@ -17,6 +25,7 @@ func ValidateListChats(ctx context.Context, request *domainChat.ListChatsRequest
 	err := validation.ValidateStructWithContext(ctx, request,
 		validation.Field(&request.Limit, validation.Min(1), validation.Max(100)),
 		validation.Field(&request.Offset, validation.Min(0)),
+		validation.Field(&request.ChatType, validation.In(chatTypes...)),
 	)
 
 	if err != nil {
@@ -26,6 +35,10 @@ func ValidateListChats(ctx context.Context, request *domainChat.ListChatsRequest
 	return nil
 }
 
+// chatMessageMediaTypes are the media_type filter values accepted when listing
+// messages; "text" selects messages without media.
+var chatMessageMediaTypes = []any{"image", "video", "video_note", "audio", "document", "sticker", "text"}
+
 func ValidateGetChatMessages(ctx context.Context, request *domainChat.GetChatMessagesRequest) error {
 	// Set default limit if not provided
 	if request.Limit == 0 {
@@ -36,6 +49,118 @@ func ValidateGetChatMessages(ctx context.Context, request *domainChat.GetChatMes
 		validation.Field(&request.ChatJID, validation.Required),
 		validation.Field(&request.Limit, validation.Min(1), validation.Max(100)),
 		validation.Field(&request.Offset, validation.Min(0)),
+		validation.Field(&request.StartTime, validation.Date(time.RFC3339).Error("must be an ISO-8601 timestamp, e.g. 2024-05-01T00:00:00Z")),
+		validation.Field(&request.EndTime, validation.Date(time.RFC3339).Error("must be an ISO-8601 timestamp, e.g. 2024-05-07T23:59:59Z")),
+		validation.Field(&request.MediaTypes, validation.Each(validation.In(chatMessageMediaTypes...))),
+		validation.Field(&request.Sender, validation.By(validateSenderJID)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	if request.StartTime != nil && request.EndTime != nil && *request.StartTime != "" && *request.EndTime != "" {
+		from, _ := time.Parse(time.RFC3339, *request.StartTime)
+		to, _ := time.Parse(time.RFC3339, *request.EndTime)
+		if from.After(to) {
+			return pkgError.ValidationError("start_time: must not be after end_time")
+		}
+	}
+
+	return nil
+}
+
+func ValidateSearchMessages(ctx context.Context, request *domainChat.SearchMessagesRequest) error {
+	// Set default limit if not provided
+	if request.Limit == 0 {
+		request.Limit = 25
+	}
+
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.Query, validation.Required, validation.Length(2, 200)),
+		validation.Field(&request.Limit, validation.Min(1), validation.Max(100)),
+		validation.Field(&request.Offset, validation.Min(0)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateGetMessageEditHistory(ctx context.Context, request *domainChat.GetMessageEditHistoryRequest) error {
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.ChatJID, validation.Required),
+		validation.Field(&request.MessageID, validation.Required),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateGetPinnedMessages(ctx context.Context, request *domainChat.GetPinnedMessagesRequest) error {
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.ChatJID, validation.Required),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateExportChatMessages(ctx context.Context, request *domainChat.ExportChatMessagesRequest) error {
+	if request.Format == "" {
+		request.Format = domainChat.ExportFormatJSON
+	}
+
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.ChatJID, validation.Required),
+		validation.Field(&request.Format, validation.In(domainChat.ExportFormatJSON, domainChat.ExportFormatCSV)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateSyncChatHistory(ctx context.Context, request *domainChat.SyncChatHistoryRequest) error {
+	if request.Count == 0 {
+		request.Count = domainChat.DefaultHistorySyncCount
+	}
+
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.ChatJID, validation.Required),
+		validation.Field(&request.Count, validation.Min(1), validation.Max(100)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateImportChatMessages(ctx context.Context, request *domainChat.ImportChatMessagesRequest) error {
+	// Without an explicit format, trust the extension the export gave the file
+	if request.Format == "" && request.File != nil {
+		request.Format = domainChat.ExportFormatJSON
+		if strings.EqualFold(filepath.Ext(request.File.Filename), ".csv") {
+			request.Format = domainChat.ExportFormatCSV
+		}
+	}
+
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.ChatJID, validation.Required),
+		validation.Field(&request.File, validation.Required),
+		validation.Field(&request.Format, validation.In(domainChat.ExportFormatJSON, domainChat.ExportFormatCSV)),
 	)
 
 	if err != nil {
@@ -65,9 +190,17 @@ var ValidTimerValues = []uint32{
 	7776000, // 90 days
 }
 
+// ValidateSetDisappearingTimer also sets TimerSeconds from Duration when the
+// request gives one.
 func ValidateSetDisappearingTimer(ctx context.Context, request *domainChat.SetDisappearingTimerRequest) error {
+	durations := make([]any, 0, len(domainChat.DisappearingDurations))
+	for duration := range domainChat.DisappearingDurations {
+		durations = append(durations, duration)
+	}
+
 	err := validation.ValidateStructWithContext(ctx, request,
 		validation.Field(&request.ChatJID, validation.Required),
+		validation.Field(&request.Duration, validation.In(durations...).Error("must be one of: off, 24h, 7d, 90d")),
 		validation.Field(&request.TimerSeconds, validation.By(validateTimerValue)),
 	)
 
@@ -75,6 +208,14 @@ func ValidateSetDisappearingTimer(ctx context.Context, request *domainChat.SetDi
 		return pkgError.ValidationError(err.Error())
 	}
 
+	if request.Duration != "" {
+		timer := domainChat.DisappearingDurations[request.Duration]
+		if request.TimerSeconds != 0 && request.TimerSeconds != timer {
+			return pkgError.ValidationError("duration and timer_seconds disagree, set only one of them")
+		}
+		request.TimerSeconds = timer
+	}
+
 	return nil
 }
 
@@ -91,6 +232,17 @@ func validateTimerValue(value interface{}) error {
 	return pkgError.ValidationError("timer_seconds must be one of: 0 (off), 86400 (24h), 604800 (7d), 7776000 (90d)")
 }
 
+func validateSenderJID(value interface{}) error {
+	sender, _ := value.(string)
+	if sender == "" {
+		return nil
+	}
+	if _, err := utils.ParseJID(sender); err != nil {
+		return errors.New("must be a valid JID")
+	}
+	return nil
+}
+
 func ValidateArchiveChat(ctx context.Context, request *domainChat.ArchiveChatRequest) error {
 	err := validation.ValidateStructWithContext(ctx, request,
 		validation.Field(&request.ChatJID, validation.Required),
@@ -102,3 +254,90 @@ func ValidateArchiveChat(ctx context.Context, request *domainChat.ArchiveChatReq
 
 	return nil
 }
+
+func ValidateMarkChatRead(ctx context.Context, request *domainChat.MarkChatReadRequest) error {
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.ChatJID, validation.Required),
+		validation.Field(&request.MessageIDs, validation.Length(0, 1000), validation.Each(validation.Required)),
+		validation.Field(&request.AllBefore, validation.Date(time.RFC3339).Error("must be an ISO-8601 timestamp, e.g. 2024-05-01T00:00:00Z")),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	if (len(request.MessageIDs) == 0) == (request.AllBefore == "") {
+		return pkgError.ValidationError("either message_ids or all_before is required, not both")
+	}
+
+	return nil
+}
+
+func ValidateSetChatPresence(ctx context.Context, request *domainChat.SetChatPresenceRequest) error {
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.ChatJID, validation.Required),
+		validation.Field(&request.State, validation.Required, validation.In("typing", "recording", "paused")),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidatePruneMessages(ctx context.Context, request *domainChat.PruneMessagesRequest) error {
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.Days, validation.Required, validation.Min(1)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateMergeChats(ctx context.Context, request *domainChat.MergeChatsRequest) error {
+	request.FromJID = strings.TrimSpace(request.FromJID)
+	request.ToJID = strings.TrimSpace(request.ToJID)
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.FromJID, validation.Required),
+		validation.Field(&request.ToJID, validation.Required, validation.NotIn(request.FromJID).Error("must differ from from_jid")),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateListCalls(ctx context.Context, request *domainChat.ListCallsRequest) error {
+	// Set default limit if not provided
+	if request.Limit == 0 {
+		request.Limit = 50
+	}
+
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.Limit, validation.Min(1), validation.Max(100)),
+		validation.Field(&request.Offset, validation.Min(0)),
+		validation.Field(&request.FromJID, validation.By(validateSenderJID)),
+		validation.Field(&request.StartTime, validation.Date(time.RFC3339).Error("must be an ISO-8601 timestamp, e.g. 2024-05-01T00:00:00Z")),
+		validation.Field(&request.EndTime, validation.Date(time.RFC3339).Error("must be an ISO-8601 timestamp, e.g. 2024-05-07T23:59:59Z")),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	if request.StartTime != nil && request.EndTime != nil && *request.StartTime != "" && *request.EndTime != "" {
+		from, _ := time.Parse(time.RFC3339, *request.StartTime)
+		to, _ := time.Parse(time.RFC3339, *request.EndTime)
+		if from.After(to) {
+			return pkgError.ValidationError("start_time: must not be after end_time")
+		}
+	}
+
+	return nil
+}
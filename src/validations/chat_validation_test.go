@@ -2,6 +2,7 @@ package validations
 
 import (
 	"context"
+	"mime/multipart"
 	"testing"
 
 	domainChat "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chat"
@@ -50,6 +51,22 @@ func TestValidateListChats(t *testing.T) {
 			}},
 			err: pkgError.ValidationError("limit: must be no greater than 100."),
 		},
+		{
+			name: "should success with newsletter chat type",
+			args: args{request: domainChat.ListChatsRequest{
+				Limit:    25,
+				ChatType: "newsletter",
+			}},
+			err: nil,
+		},
+		{
+			name: "should error with unknown chat type",
+			args: args{request: domainChat.ListChatsRequest{
+				Limit:    25,
+				ChatType: "channel",
+			}},
+			err: pkgError.ValidationError("chat_type: must be a valid value."),
+		},
 		{
 			name: "should error with negative offset",
 			args: args{request: domainChat.ListChatsRequest{
@@ -131,6 +148,80 @@ func TestValidateGetChatMessages(t *testing.T) {
 			}},
 			err: pkgError.ValidationError("offset: must be no less than 0."),
 		},
+		{
+			name: "should success with time range",
+			args: args{request: domainChat.GetChatMessagesRequest{
+				ChatJID:   "6289685028129@s.whatsapp.net",
+				Limit:     50,
+				StartTime: strPtr("2024-05-01T00:00:00Z"),
+				EndTime:   strPtr("2024-05-07T23:59:59+07:00"),
+			}},
+			err: nil,
+		},
+		{
+			name: "should error with malformed start_time",
+			args: args{request: domainChat.GetChatMessagesRequest{
+				ChatJID:   "6289685028129@s.whatsapp.net",
+				Limit:     50,
+				StartTime: strPtr("2024-05-01"),
+			}},
+			err: pkgError.ValidationError("start_time: must be an ISO-8601 timestamp, e.g. 2024-05-01T00:00:00Z."),
+		},
+		{
+			name: "should error with malformed end_time",
+			args: args{request: domainChat.GetChatMessagesRequest{
+				ChatJID: "6289685028129@s.whatsapp.net",
+				Limit:   50,
+				EndTime: strPtr("last week"),
+			}},
+			err: pkgError.ValidationError("end_time: must be an ISO-8601 timestamp, e.g. 2024-05-07T23:59:59Z."),
+		},
+		{
+			name: "should error when start_time is after end_time",
+			args: args{request: domainChat.GetChatMessagesRequest{
+				ChatJID:   "6289685028129@s.whatsapp.net",
+				Limit:     50,
+				StartTime: strPtr("2024-05-08T00:00:00Z"),
+				EndTime:   strPtr("2024-05-01T00:00:00Z"),
+			}},
+			err: pkgError.ValidationError("start_time: must not be after end_time"),
+		},
+		{
+			name: "should success with media types",
+			args: args{request: domainChat.GetChatMessagesRequest{
+				ChatJID:    "6289685028129@s.whatsapp.net",
+				Limit:      50,
+				MediaTypes: []string{"image", "document", "text"},
+			}},
+			err: nil,
+		},
+		{
+			name: "should error with unknown media type",
+			args: args{request: domainChat.GetChatMessagesRequest{
+				ChatJID:    "6289685028129@s.whatsapp.net",
+				Limit:      50,
+				MediaTypes: []string{"image", "gif"},
+			}},
+			err: pkgError.ValidationError("media_type: (1: must be a valid value.)."),
+		},
+		{
+			name: "should success with sender",
+			args: args{request: domainChat.GetChatMessagesRequest{
+				ChatJID: "120363024512399999@g.us",
+				Limit:   50,
+				Sender:  "6289685028129:12@s.whatsapp.net",
+			}},
+			err: nil,
+		},
+		{
+			name: "should error with malformed sender",
+			args: args{request: domainChat.GetChatMessagesRequest{
+				ChatJID: "120363024512399999@g.us",
+				Limit:   50,
+				Sender:  "@s.whatsapp.net",
+			}},
+			err: pkgError.ValidationError("sender: must be a valid JID."),
+		},
 	}
 
 	for _, tt := range tests {
@@ -184,6 +275,99 @@ func TestValidatePinChat(t *testing.T) {
 	}
 }
 
+func TestValidateMarkChatRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainChat.MarkChatReadRequest
+		err     any
+	}{
+		{
+			name:    "should success with message ids",
+			request: domainChat.MarkChatReadRequest{ChatJID: "120363025246125486@g.us", MessageIDs: []string{"3EB0A1", "3EB0A2"}},
+		},
+		{
+			name:    "should success with all_before",
+			request: domainChat.MarkChatReadRequest{ChatJID: "6289685028129@s.whatsapp.net", AllBefore: "2024-05-01T10:00:00Z"},
+		},
+		{
+			name:    "should error without message ids or all_before",
+			request: domainChat.MarkChatReadRequest{ChatJID: "6289685028129@s.whatsapp.net"},
+			err:     pkgError.ValidationError("either message_ids or all_before is required, not both"),
+		},
+		{
+			name:    "should error with both message ids and all_before",
+			request: domainChat.MarkChatReadRequest{ChatJID: "6289685028129@s.whatsapp.net", MessageIDs: []string{"3EB0A1"}, AllBefore: "2024-05-01T10:00:00Z"},
+			err:     pkgError.ValidationError("either message_ids or all_before is required, not both"),
+		},
+		{
+			name:    "should error with invalid all_before",
+			request: domainChat.MarkChatReadRequest{ChatJID: "6289685028129@s.whatsapp.net", AllBefore: "yesterday"},
+			err:     pkgError.ValidationError("all_before: must be an ISO-8601 timestamp, e.g. 2024-05-01T00:00:00Z."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMarkChatRead(context.Background(), &tt.request)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, tt.err, err)
+			}
+		})
+	}
+}
+
+func TestValidateSetChatPresence(t *testing.T) {
+	type args struct {
+		request domainChat.SetChatPresenceRequest
+	}
+	tests := []struct {
+		name string
+		args args
+		err  any
+	}{
+		{
+			name: "should success with typing",
+			args: args{request: domainChat.SetChatPresenceRequest{
+				ChatJID: "6289685028129@s.whatsapp.net",
+				State:   "typing",
+			}},
+			err: nil,
+		},
+		{
+			name: "should success with recording",
+			args: args{request: domainChat.SetChatPresenceRequest{
+				ChatJID: "120363025246125486@g.us",
+				State:   "recording",
+			}},
+			err: nil,
+		},
+		{
+			name: "should error with unknown state",
+			args: args{request: domainChat.SetChatPresenceRequest{
+				ChatJID: "6289685028129@s.whatsapp.net",
+				State:   "composing",
+			}},
+			err: pkgError.ValidationError("state: must be a valid value."),
+		},
+		{
+			name: "should error with empty chat_jid",
+			args: args{request: domainChat.SetChatPresenceRequest{
+				State: "paused",
+			}},
+			err: pkgError.ValidationError("chat_jid: cannot be blank."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSetChatPresence(context.Background(), &tt.args.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
 func TestValidateSetDisappearingTimer(t *testing.T) {
 	type args struct {
 		request domainChat.SetDisappearingTimerRequest
@@ -241,6 +425,23 @@ func TestValidateSetDisappearingTimer(t *testing.T) {
 			}},
 			err: pkgError.ValidationError("timer_seconds: timer_seconds must be one of: 0 (off), 86400 (24h), 604800 (7d), 7776000 (90d)."),
 		},
+		{
+			name: "should error with unknown duration",
+			args: args{request: domainChat.SetDisappearingTimerRequest{
+				ChatJID:  "6289685028129@s.whatsapp.net",
+				Duration: "30d",
+			}},
+			err: pkgError.ValidationError("duration: must be one of: off, 24h, 7d, 90d."),
+		},
+		{
+			name: "should error when duration and timer disagree",
+			args: args{request: domainChat.SetDisappearingTimerRequest{
+				ChatJID:      "6289685028129@s.whatsapp.net",
+				Duration:     "7d",
+				TimerSeconds: 86400,
+			}},
+			err: pkgError.ValidationError("duration and timer_seconds disagree, set only one of them"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -249,4 +450,116 @@ func TestValidateSetDisappearingTimer(t *testing.T) {
 			assert.Equal(t, tt.err, err)
 		})
 	}
+
+	request := domainChat.SetDisappearingTimerRequest{ChatJID: "6289685028129@s.whatsapp.net", Duration: "90d"}
+	assert.NoError(t, ValidateSetDisappearingTimer(context.Background(), &request))
+	assert.Equal(t, uint32(7776000), request.TimerSeconds, "set from the duration")
+
+	request = domainChat.SetDisappearingTimerRequest{ChatJID: "6289685028129@s.whatsapp.net", Duration: "off"}
+	assert.NoError(t, ValidateSetDisappearingTimer(context.Background(), &request))
+	assert.Zero(t, request.TimerSeconds)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestValidatePruneMessages(t *testing.T) {
+	type args struct {
+		request domainChat.PruneMessagesRequest
+	}
+	tests := []struct {
+		name string
+		args args
+		err  any
+	}{
+		{
+			name: "should success with valid request",
+			args: args{request: domainChat.PruneMessagesRequest{Days: 30, DeviceID: "device-1"}},
+			err:  nil,
+		},
+		{
+			name: "should error with missing days",
+			args: args{request: domainChat.PruneMessagesRequest{}},
+			err:  pkgError.ValidationError("days: cannot be blank."),
+		},
+		{
+			name: "should error with negative days",
+			args: args{request: domainChat.PruneMessagesRequest{Days: -1}},
+			err:  pkgError.ValidationError("days: must be no less than 1."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePruneMessages(context.Background(), &tt.args.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestValidateMergeChats(t *testing.T) {
+	request := domainChat.MergeChatsRequest{FromJID: " 123456789@lid ", ToJID: "628123@s.whatsapp.net"}
+	assert.NoError(t, ValidateMergeChats(context.Background(), &request))
+	assert.Equal(t, "123456789@lid", request.FromJID)
+
+	request = domainChat.MergeChatsRequest{ToJID: "628123@s.whatsapp.net"}
+	assert.Equal(t, pkgError.ValidationError("from_jid: cannot be blank."), ValidateMergeChats(context.Background(), &request))
+
+	request = domainChat.MergeChatsRequest{FromJID: "628123@s.whatsapp.net", ToJID: "628123@s.whatsapp.net"}
+	assert.Equal(t, pkgError.ValidationError("to_jid: must differ from from_jid."), ValidateMergeChats(context.Background(), &request))
+}
+
+func TestValidateExportChatMessages(t *testing.T) {
+	request := domainChat.ExportChatMessagesRequest{ChatJID: "6289685028129@s.whatsapp.net"}
+	assert.NoError(t, ValidateExportChatMessages(context.Background(), &request))
+	assert.Equal(t, domainChat.ExportFormatJSON, request.Format)
+
+	request.Format = "csv"
+	assert.NoError(t, ValidateExportChatMessages(context.Background(), &request))
+
+	request.Format = "xml"
+	assert.Equal(t, pkgError.ValidationError("format: must be a valid value."), ValidateExportChatMessages(context.Background(), &request))
+
+	assert.Equal(t, pkgError.ValidationError("chat_jid: cannot be blank."), ValidateExportChatMessages(context.Background(), &domainChat.ExportChatMessagesRequest{}))
+}
+
+func TestValidateSyncChatHistory(t *testing.T) {
+	request := domainChat.SyncChatHistoryRequest{ChatJID: "6289685028129@s.whatsapp.net"}
+	assert.NoError(t, ValidateSyncChatHistory(context.Background(), &request))
+	assert.Equal(t, domainChat.DefaultHistorySyncCount, request.Count)
+
+	request.Count = 101
+	assert.Equal(t, pkgError.ValidationError("count: must be no greater than 100."), ValidateSyncChatHistory(context.Background(), &request))
+
+	request.Count = -1
+	assert.Equal(t, pkgError.ValidationError("count: must be no less than 1."), ValidateSyncChatHistory(context.Background(), &request))
+}
+
+func TestValidateImportChatMessages(t *testing.T) {
+	request := domainChat.ImportChatMessagesRequest{
+		ChatJID: "6289685028129@s.whatsapp.net",
+		File:    &multipart.FileHeader{Filename: "chat-export.CSV"},
+	}
+	assert.NoError(t, ValidateImportChatMessages(context.Background(), &request))
+	assert.Equal(t, domainChat.ExportFormatCSV, request.Format)
+
+	request.Format = ""
+	request.File.Filename = "chat-export.jsonl"
+	assert.NoError(t, ValidateImportChatMessages(context.Background(), &request))
+	assert.Equal(t, domainChat.ExportFormatJSON, request.Format)
+
+	request.Format = "xml"
+	assert.Equal(t, pkgError.ValidationError("format: must be a valid value."), ValidateImportChatMessages(context.Background(), &request))
+
+	assert.Equal(t, pkgError.ValidationError("file: cannot be blank."),
+		ValidateImportChatMessages(context.Background(), &domainChat.ImportChatMessagesRequest{ChatJID: "6289685028129@s.whatsapp.net"}))
+}
+
+func TestValidateGetMessageEditHistory(t *testing.T) {
+	request := domainChat.GetMessageEditHistoryRequest{ChatJID: "6289685028129@s.whatsapp.net", MessageID: "3EB0B430B6F8F1D0E053AC120E0A9E5C"}
+	assert.NoError(t, ValidateGetMessageEditHistory(context.Background(), &request))
+
+	request.MessageID = ""
+	assert.Equal(t, pkgError.ValidationError("message_id: cannot be blank."), ValidateGetMessageEditHistory(context.Background(), &request))
 }
@@ -0,0 +1,50 @@
+package validations
+
+import (
+	"context"
+	"testing"
+
+	domainContact "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/contact"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateListContacts(t *testing.T) {
+	tests := []struct {
+		name      string
+		request   domainContact.ListContactsRequest
+		err       any
+		wantLimit int
+	}{
+		{
+			name:      "should success with valid request",
+			request:   domainContact.ListContactsRequest{Limit: 100, Offset: 20, Search: "ali"},
+			wantLimit: 100,
+		},
+		{
+			name:      "should default zero limit",
+			request:   domainContact.ListContactsRequest{},
+			wantLimit: 50,
+		},
+		{
+			name:      "should error with limit too high",
+			request:   domainContact.ListContactsRequest{Limit: 501},
+			err:       pkgError.ValidationError("limit: must be no greater than 500."),
+			wantLimit: 501,
+		},
+		{
+			name:      "should error with negative offset",
+			request:   domainContact.ListContactsRequest{Limit: 10, Offset: -1},
+			err:       pkgError.ValidationError("offset: must be no less than 0."),
+			wantLimit: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateListContacts(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.wantLimit, tt.request.Limit)
+		})
+	}
+}
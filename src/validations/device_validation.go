@@ -0,0 +1,71 @@
+package validations
+
+import (
+	"context"
+	"regexp"
+
+	domainDevice "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/device"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+var (
+	httpURLPattern      = regexp.MustCompile(`^https?://`)
+	webhookEventPattern = regexp.MustCompile(`^[a-z_]+(\.[a-z_]+)*$`)
+)
+
+func ValidateAddDevice(ctx context.Context, request domainDevice.AddDeviceRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.DeviceID, validation.RuneLength(0, 128)),
+		validation.Field(&request.DisplayName, validation.RuneLength(0, 100)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+// WhatsApp truncates push names longer than this
+const maxPushNameLength = 25
+
+func ValidateRenameDevice(ctx context.Context, request domainDevice.RenameDeviceRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.Name, validation.Required, validation.RuneLength(1, maxPushNameLength)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateDeviceSettings(ctx context.Context, request domainDevice.DeviceSettings) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.AutoRejectCallMessage, validation.RuneLength(0, 4096)),
+		validation.Field(&request.AutoReplyMessage, validation.RuneLength(0, 4096)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateDeviceWebhook(ctx context.Context, request domainDevice.DeviceWebhook) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.WebhookURL, validation.Required, is.URL, validation.Match(httpURLPattern).Error("must be an http(s) URL")),
+		validation.Field(&request.WebhookSecret, validation.RuneLength(0, 256)),
+		validation.Field(&request.WebhookEvents, validation.Each(validation.Required, validation.Match(webhookEventPattern).Error("must be an event name"))),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
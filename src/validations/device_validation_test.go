@@ -0,0 +1,170 @@
+package validations
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	domainDevice "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/device"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAddDevice(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainDevice.AddDeviceRequest
+		err     any
+	}{
+		{
+			name:    "should success without fields",
+			request: domainDevice.AddDeviceRequest{},
+			err:     nil,
+		},
+		{
+			name:    "should success with id and display name",
+			request: domainDevice.AddDeviceRequest{DeviceID: "sales", DisplayName: "Sales team"},
+			err:     nil,
+		},
+		{
+			name:    "should error with too long display name",
+			request: domainDevice.AddDeviceRequest{DisplayName: strings.Repeat("a", 101)},
+			err:     pkgError.ValidationError("display_name: the length must be no more than 100."),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAddDevice(context.Background(), tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestValidateRenameDevice(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainDevice.RenameDeviceRequest
+		err     any
+	}{
+		{
+			name:    "should success with name",
+			request: domainDevice.RenameDeviceRequest{Name: "Front desk"},
+			err:     nil,
+		},
+		{
+			name:    "should success with 25 characters",
+			request: domainDevice.RenameDeviceRequest{Name: strings.Repeat("é", 25)},
+			err:     nil,
+		},
+		{
+			name:    "should error without name",
+			request: domainDevice.RenameDeviceRequest{},
+			err:     pkgError.ValidationError("name: cannot be blank."),
+		},
+		{
+			name:    "should error with too long name",
+			request: domainDevice.RenameDeviceRequest{Name: strings.Repeat("a", 26)},
+			err:     pkgError.ValidationError("name: the length must be between 1 and 25."),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRenameDevice(context.Background(), tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestValidateDeviceSettings(t *testing.T) {
+	enabled := true
+	message := "Sorry, I can't take calls right now"
+	empty := ""
+	tooLong := strings.Repeat("a", 4097)
+
+	tests := []struct {
+		name    string
+		request domainDevice.DeviceSettings
+		err     any
+	}{
+		{
+			name:    "should success without overrides",
+			request: domainDevice.DeviceSettings{},
+			err:     nil,
+		},
+		{
+			name:    "should success with reply message",
+			request: domainDevice.DeviceSettings{AutoRejectCalls: &enabled, AutoRejectCallMessage: &message},
+			err:     nil,
+		},
+		{
+			name:    "should success with empty reply message",
+			request: domainDevice.DeviceSettings{AutoRejectCallMessage: &empty},
+			err:     nil,
+		},
+		{
+			name:    "should error with too long reply message",
+			request: domainDevice.DeviceSettings{AutoRejectCallMessage: &tooLong},
+			err:     pkgError.ValidationError("auto_reject_call_message: the length must be no more than 4096."),
+		},
+		{
+			name:    "should success with auto-reply and mark read",
+			request: domainDevice.DeviceSettings{AutoReplyMessage: &message, AutoMarkRead: &enabled},
+			err:     nil,
+		},
+		{
+			name:    "should error with too long auto-reply message",
+			request: domainDevice.DeviceSettings{AutoReplyMessage: &tooLong},
+			err:     pkgError.ValidationError("auto_reply_message: the length must be no more than 4096."),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDeviceSettings(context.Background(), tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestValidateDeviceWebhook(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainDevice.DeviceWebhook
+		err     any
+	}{
+		{
+			name:    "should success with URL only",
+			request: domainDevice.DeviceWebhook{WebhookURL: "https://customer-a.example/hook"},
+			err:     nil,
+		},
+		{
+			name: "should success with secret and events",
+			request: domainDevice.DeviceWebhook{
+				WebhookURL:    "http://10.0.0.5:8080/hook",
+				WebhookSecret: "customer-a-secret",
+				WebhookEvents: []string{"message", "message.ack", "chat.ephemeral_changed"},
+			},
+			err: nil,
+		},
+		{
+			name:    "should error without URL",
+			request: domainDevice.DeviceWebhook{WebhookEvents: []string{"message"}},
+			err:     pkgError.ValidationError("webhook_url: cannot be blank."),
+		},
+		{
+			name:    "should error with non-http URL",
+			request: domainDevice.DeviceWebhook{WebhookURL: "ftp://customer-a.example/hook"},
+			err:     pkgError.ValidationError("webhook_url: must be an http(s) URL."),
+		},
+		{
+			name:    "should error with invalid event name",
+			request: domainDevice.DeviceWebhook{WebhookURL: "https://customer-a.example/hook", WebhookEvents: []string{"message", "message,ack"}},
+			err:     pkgError.ValidationError("webhook_events: (1: must be an event name.)."),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDeviceWebhook(context.Background(), tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
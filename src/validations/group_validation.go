@@ -2,16 +2,19 @@ package validations
 
 import (
 	"context"
+	"errors"
 
 	domainGroup "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/group"
 	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
 )
 
 func ValidateJoinGroupWithLink(ctx context.Context, request domainGroup.JoinGroupWithLinkRequest) error {
 	err := validation.ValidateStructWithContext(ctx, &request,
-		validation.Field(&request.Link, validation.Required),
+		validation.Field(&request.Link, validation.Required, validation.By(validateInviteLink)),
 	)
 
 	if err != nil {
@@ -23,7 +26,7 @@ func ValidateJoinGroupWithLink(ctx context.Context, request domainGroup.JoinGrou
 
 func ValidateGetGroupInfoFromLink(ctx context.Context, request domainGroup.GetGroupInfoFromLinkRequest) error {
 	err := validation.ValidateStructWithContext(ctx, &request,
-		validation.Field(&request.Link, validation.Required),
+		validation.Field(&request.Link, validation.Required, validation.By(validateInviteLink)),
 	)
 
 	if err != nil {
@@ -33,6 +36,15 @@ func ValidateGetGroupInfoFromLink(ctx context.Context, request domainGroup.GetGr
 	return nil
 }
 
+// validateInviteLink checks that a group invite link carries a code.
+func validateInviteLink(value any) error {
+	link, _ := value.(string)
+	if _, ok := utils.GroupInviteCode(link); !ok {
+		return errors.New("must be a chat.whatsapp.com invite link")
+	}
+	return nil
+}
+
 func ValidateLeaveGroup(ctx context.Context, request domainGroup.LeaveGroupRequest) error {
 	err := validation.ValidateStructWithContext(ctx, &request,
 		validation.Field(&request.GroupID, validation.Required),
@@ -45,9 +57,39 @@ func ValidateLeaveGroup(ctx context.Context, request domainGroup.LeaveGroupReque
 	return nil
 }
 
+func ValidateCommunityGroup(ctx context.Context, request domainGroup.CommunityGroupRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.CommunityID, validation.Required),
+		validation.Field(&request.GroupID, validation.Required),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateListGroups(ctx context.Context, request *domainGroup.ListGroupsRequest) error {
+	if request.Limit == 0 {
+		request.Limit = 50
+	}
+
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.Limit, validation.Min(1), validation.Max(500)),
+		validation.Field(&request.Offset, validation.Min(0)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
 func ValidateCreateGroup(ctx context.Context, request domainGroup.CreateGroupRequest) error {
 	err := validation.ValidateStructWithContext(ctx, &request,
-		validation.Field(&request.Title, validation.Required),
+		validation.Field(&request.Title, validation.Required, validation.RuneLength(1, 25)),
 		validation.Field(&request.Participants, validation.Required),
 		validation.Field(&request.Participants, validation.Each(validation.Required)),
 	)
@@ -64,6 +106,12 @@ func ValidateParticipant(ctx context.Context, request domainGroup.ParticipantReq
 		validation.Field(&request.GroupID, validation.Required),
 		validation.Field(&request.Participants, validation.Required),
 		validation.Field(&request.Participants, validation.Each(validation.Required)),
+		validation.Field(&request.Action, validation.Required, validation.In(
+			whatsmeow.ParticipantChangeAdd,
+			whatsmeow.ParticipantChangeRemove,
+			whatsmeow.ParticipantChangePromote,
+			whatsmeow.ParticipantChangeDemote,
+		)),
 	)
 
 	if err != nil {
@@ -205,6 +253,31 @@ func ValidateSetGroupTopic(ctx context.Context, request domainGroup.SetGroupTopi
 	return nil
 }
 
+func ValidateSetGroupSettings(ctx context.Context, request domainGroup.SetGroupSettingsRequest) error {
+	timers := make([]any, len(ValidTimerValues))
+	for i, timer := range ValidTimerValues {
+		timers[i] = timer
+	}
+
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.GroupID, validation.Required),
+		validation.Field(&request.MemberAddMode, validation.In(string(types.GroupMemberAddModeAdmin), string(types.GroupMemberAddModeAllMember)).
+			Error("must be admin_add or all_member_add")),
+		validation.Field(&request.DisappearingTimer, validation.In(timers...).
+			Error("must be one of: 0 (off), 86400 (24h), 604800 (7d), 7776000 (90d)")),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	if request.Announce == nil && request.Locked == nil && request.MemberAddMode == "" && request.DisappearingTimer == nil {
+		return pkgError.ValidationError("at least one of announce, locked, member_add_mode or disappearing_timer is required")
+	}
+
+	return nil
+}
+
 func ValidateGroupInfo(ctx context.Context, request domainGroup.GroupInfoRequest) error {
 	err := validation.ValidateStructWithContext(ctx, &request,
 		validation.Field(&request.GroupID, validation.Required),
@@ -33,6 +33,13 @@ func TestValidateJoinGroupWithLink(t *testing.T) {
 			}},
 			err: pkgError.ValidationError("link: cannot be blank."),
 		},
+		{
+			name: "should error with a link to another site",
+			args: args{request: domainGroup.JoinGroupWithLinkRequest{
+				Link: "https://example.com/ABC123XYZ",
+			}},
+			err: pkgError.ValidationError("link: must be a chat.whatsapp.com invite link."),
+		},
 	}
 
 	for _, tt := range tests {
@@ -101,6 +108,24 @@ func TestValidateCreateGroup(t *testing.T) {
 			}},
 			err: pkgError.ValidationError("title: cannot be blank."),
 		},
+		{
+			name: "should error with a title longer than 25 characters",
+			args: args{request: domainGroup.CreateGroupRequest{
+				Title:        "Neighbourhood Watch Team 2",
+				Participants: []string{"+6281234567890@s.whatsapp.net"},
+			}},
+			err: pkgError.ValidationError("title: the length must be between 1 and 25."),
+		},
+		{
+			name: "should success with a title of 25 characters counted as runes",
+			args: args{request: domainGroup.CreateGroupRequest{
+				Title:        "Família Gonçalves 🎉🎉🎉🎉🎉🎉🎉",
+				Participants: []string{"+6281234567890@s.whatsapp.net"},
+				Announce:     true,
+				Restrict:     true,
+			}},
+			err: nil,
+		},
 		{
 			name: "should error with empty participants",
 			args: args{request: domainGroup.CreateGroupRequest{
@@ -197,6 +222,23 @@ func TestValidateParticipant(t *testing.T) {
 			}},
 			err: pkgError.ValidationError("participants: (1: cannot be blank.)."),
 		},
+		{
+			name: "should error with an unknown action",
+			args: args{request: domainGroup.ParticipantRequest{
+				GroupID:      "123456789@g.us",
+				Participants: []string{"+6281234567890@s.whatsapp.net"},
+				Action:       "kick",
+			}},
+			err: pkgError.ValidationError("action: must be a valid value."),
+		},
+		{
+			name: "should error without an action",
+			args: args{request: domainGroup.ParticipantRequest{
+				GroupID:      "123456789@g.us",
+				Participants: []string{"+6281234567890@s.whatsapp.net"},
+			}},
+			err: pkgError.ValidationError("action: cannot be blank."),
+		},
 		{
 			name: "should success with single participant",
 			args: args{request: domainGroup.ParticipantRequest{
@@ -674,6 +716,84 @@ func TestValidateSetGroupTopic(t *testing.T) {
 	}
 }
 
+func TestValidateSetGroupSettings(t *testing.T) {
+	enabled := true
+	week, hour := uint32(604800), uint32(3600)
+	off := uint32(0)
+
+	type args struct {
+		request domainGroup.SetGroupSettingsRequest
+	}
+	tests := []struct {
+		name string
+		args args
+		err  any
+	}{
+		{
+			name: "should success with announce and member add mode",
+			args: args{request: domainGroup.SetGroupSettingsRequest{
+				GroupID:       "123456789@g.us",
+				Announce:      &enabled,
+				MemberAddMode: "admin_add",
+			}},
+			err: nil,
+		},
+		{
+			name: "should success with a supported disappearing timer",
+			args: args{request: domainGroup.SetGroupSettingsRequest{
+				GroupID:           "123456789@g.us",
+				DisappearingTimer: &week,
+			}},
+			err: nil,
+		},
+		{
+			name: "should success turning the disappearing timer off",
+			args: args{request: domainGroup.SetGroupSettingsRequest{
+				GroupID:           "123456789@g.us",
+				DisappearingTimer: &off,
+			}},
+			err: nil,
+		},
+		{
+			name: "should error with empty group id",
+			args: args{request: domainGroup.SetGroupSettingsRequest{
+				Locked: &enabled,
+			}},
+			err: pkgError.ValidationError("group_id: cannot be blank."),
+		},
+		{
+			name: "should error with no settings",
+			args: args{request: domainGroup.SetGroupSettingsRequest{
+				GroupID: "123456789@g.us",
+			}},
+			err: pkgError.ValidationError("at least one of announce, locked, member_add_mode or disappearing_timer is required"),
+		},
+		{
+			name: "should error with unknown member add mode",
+			args: args{request: domainGroup.SetGroupSettingsRequest{
+				GroupID:       "123456789@g.us",
+				MemberAddMode: "everyone",
+			}},
+			err: pkgError.ValidationError("member_add_mode: must be admin_add or all_member_add."),
+		},
+		{
+			name: "should error with unsupported disappearing timer",
+			args: args{request: domainGroup.SetGroupSettingsRequest{
+				GroupID:           "123456789@g.us",
+				DisappearingTimer: &hour,
+			}},
+			err: pkgError.ValidationError("disappearing_timer: must be one of: 0 (off), 86400 (24h), 604800 (7d), 7776000 (90d)."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSetGroupSettings(context.Background(), tt.args.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
 func TestValidateGroupInfo(t *testing.T) {
 	type args struct {
 		request domainGroup.GroupInfoRequest
@@ -706,3 +826,84 @@ func TestValidateGroupInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateListGroups(t *testing.T) {
+	tests := []struct {
+		name      string
+		request   domainGroup.ListGroupsRequest
+		err       any
+		wantLimit int
+	}{
+		{
+			name:      "should success with valid request",
+			request:   domainGroup.ListGroupsRequest{Limit: 100, Offset: 200},
+			wantLimit: 100,
+		},
+		{
+			name:      "should default zero limit",
+			request:   domainGroup.ListGroupsRequest{},
+			wantLimit: 50,
+		},
+		{
+			name:      "should error with limit too high",
+			request:   domainGroup.ListGroupsRequest{Limit: 501},
+			err:       pkgError.ValidationError("limit: must be no greater than 500."),
+			wantLimit: 501,
+		},
+		{
+			name:      "should error with negative offset",
+			request:   domainGroup.ListGroupsRequest{Limit: 10, Offset: -1},
+			err:       pkgError.ValidationError("offset: must be no less than 0."),
+			wantLimit: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateListGroups(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.wantLimit, tt.request.Limit)
+		})
+	}
+}
+
+func TestValidateCommunityGroup(t *testing.T) {
+	type args struct {
+		request domainGroup.CommunityGroupRequest
+	}
+	tests := []struct {
+		name string
+		args args
+		err  any
+	}{
+		{
+			name: "should success with community and group",
+			args: args{request: domainGroup.CommunityGroupRequest{
+				CommunityID: "120363000000000001@g.us",
+				GroupID:     "120363000000000002@g.us",
+			}},
+			err: nil,
+		},
+		{
+			name: "should error with empty group id",
+			args: args{request: domainGroup.CommunityGroupRequest{
+				CommunityID: "120363000000000001@g.us",
+			}},
+			err: pkgError.ValidationError("group_id: cannot be blank."),
+		},
+		{
+			name: "should error with empty community id",
+			args: args{request: domainGroup.CommunityGroupRequest{
+				GroupID: "120363000000000002@g.us",
+			}},
+			err: pkgError.ValidationError("community_id: cannot be blank."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCommunityGroup(context.Background(), tt.args.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
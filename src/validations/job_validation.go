@@ -0,0 +1,26 @@
+package validations
+
+import (
+	"context"
+
+	domainChatStorage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/chatstorage"
+	domainJob "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/job"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+func ValidateListJobs(ctx context.Context, request *domainJob.ListJobsRequest) error {
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.Type, validation.In(domainJob.TypeChatExport, domainJob.TypeContactSync)),
+		validation.Field(&request.Status, validation.In(
+			domainChatStorage.JobStatusQueued, domainChatStorage.JobStatusRunning, domainChatStorage.JobStatusSucceeded,
+			domainChatStorage.JobStatusFailed, domainChatStorage.JobStatusCanceled, domainChatStorage.JobStatusInterrupted,
+		)),
+		validation.Field(&request.Limit, validation.Min(0), validation.Max(100)),
+		validation.Field(&request.Offset, validation.Min(0)),
+	)
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+	return nil
+}
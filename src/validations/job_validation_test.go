@@ -0,0 +1,51 @@
+package validations
+
+import (
+	"context"
+	"testing"
+
+	domainJob "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/job"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateListJobs(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainJob.ListJobsRequest
+		err     string
+	}{
+		{
+			name:    "should success without filters",
+			request: domainJob.ListJobsRequest{Limit: 50},
+		},
+		{
+			name:    "should success with type and status",
+			request: domainJob.ListJobsRequest{Type: domainJob.TypeChatExport, Status: "interrupted", Limit: 10, Offset: 20},
+		},
+		{
+			name:    "should error with an unknown type",
+			request: domainJob.ListJobsRequest{Type: "bulk_send"},
+			err:     "type: must be a valid value.",
+		},
+		{
+			name:    "should error with an unknown status",
+			request: domainJob.ListJobsRequest{Status: "done"},
+			err:     "status: must be a valid value.",
+		},
+		{
+			name:    "should error with a limit over 100",
+			request: domainJob.ListJobsRequest{Limit: 101},
+			err:     "limit: must be no greater than 100.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateListJobs(context.Background(), &tt.request)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
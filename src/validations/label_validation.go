@@ -0,0 +1,36 @@
+package validations
+
+import (
+	"context"
+	"regexp"
+
+	domainLabel "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/label"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+var labelColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// ValidateLabel validates a label as it will be stored, after updates are
+// applied.
+func ValidateLabel(ctx context.Context, label domainLabel.Label) error {
+	err := validation.ValidateStructWithContext(ctx, &label,
+		validation.Field(&label.Name, validation.Required, validation.RuneLength(1, 100)),
+		validation.Field(&label.Color, validation.Match(labelColorPattern).Error("must be a hex color like #25D366")),
+	)
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+	return nil
+}
+
+func ValidateSetChatLabels(ctx context.Context, request *domainLabel.SetChatLabelsRequest) error {
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.ChatJID, validation.Required),
+		validation.Field(&request.LabelIDs, validation.Length(0, 50), validation.Each(validation.Required)),
+	)
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package validations
+
+import (
+	"context"
+	"testing"
+
+	domainLabel "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/label"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLabel(t *testing.T) {
+	tests := []struct {
+		name  string
+		label domainLabel.Label
+		err   any
+	}{
+		{
+			name:  "should success with a color",
+			label: domainLabel.Label{Name: "support", Color: "#25d366"},
+			err:   nil,
+		},
+		{
+			name:  "should success without a color",
+			label: domainLabel.Label{Name: "VIP"},
+			err:   nil,
+		},
+		{
+			name:  "should error without name",
+			label: domainLabel.Label{Color: "#25D366"},
+			err:   pkgError.ValidationError("name: cannot be blank."),
+		},
+		{
+			name:  "should error with a named color",
+			label: domainLabel.Label{Name: "support", Color: "green"},
+			err:   pkgError.ValidationError("color: must be a hex color like #25D366."),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateLabel(context.Background(), tt.label)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestValidateSetChatLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainLabel.SetChatLabelsRequest
+		err     any
+	}{
+		{
+			name:    "should success with labels",
+			request: domainLabel.SetChatLabelsRequest{ChatJID: "628123@s.whatsapp.net", LabelIDs: []string{"a", "b"}},
+			err:     nil,
+		},
+		{
+			name:    "should success clearing the labels",
+			request: domainLabel.SetChatLabelsRequest{ChatJID: "628123@s.whatsapp.net"},
+			err:     nil,
+		},
+		{
+			name:    "should error with a blank label ID",
+			request: domainLabel.SetChatLabelsRequest{ChatJID: "628123@s.whatsapp.net", LabelIDs: []string{"a", ""}},
+			err:     pkgError.ValidationError("label_ids: (1: cannot be blank.)."),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSetChatLabels(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
@@ -2,9 +2,11 @@ package validations
 
 import (
 	"context"
+	"errors"
 
 	domainMessage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/message"
 	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/clipperhouse/uax29/v2/graphemes"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
@@ -35,6 +37,44 @@ func ValidateRevokeMessage(ctx context.Context, request domainMessage.RevokeRequ
 	return nil
 }
 
+func ValidateGetMessage(ctx context.Context, request domainMessage.GetMessageRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.MessageID, validation.Required),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateGetReceipts(ctx context.Context, request domainMessage.GetReceiptsRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.Phone, validation.Required),
+		validation.Field(&request.MessageID, validation.Required),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateGetPoll(ctx context.Context, request domainMessage.GetPollRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.Phone, validation.Required),
+		validation.Field(&request.MessageID, validation.Required),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
 func ValidateUpdateMessage(ctx context.Context, request domainMessage.UpdateMessageRequest) error {
 	err := validation.ValidateStructWithContext(ctx, &request,
 		validation.Field(&request.Phone, validation.Required),
@@ -53,7 +93,7 @@ func ValidateReactMessage(ctx context.Context, request domainMessage.ReactionReq
 	err := validation.ValidateStructWithContext(ctx, &request,
 		validation.Field(&request.Phone, validation.Required),
 		validation.Field(&request.MessageID, validation.Required),
-		validation.Field(&request.Emoji, validation.Required),
+		validation.Field(&request.Emoji, validation.By(validateReactionEmoji)),
 	)
 
 	if err != nil {
@@ -63,6 +103,20 @@ func ValidateReactMessage(ctx context.Context, request domainMessage.ReactionReq
 	return nil
 }
 
+// validateReactionEmoji accepts a single grapheme cluster, so emojis with
+// skin tones, ZWJ sequences and flags pass. Empty removes the reaction.
+func validateReactionEmoji(value interface{}) error {
+	emoji, _ := value.(string)
+	if emoji == "" {
+		return nil
+	}
+	clusters := graphemes.FromString(emoji)
+	if !clusters.Next() || clusters.Value() != emoji {
+		return errors.New("must be a single emoji")
+	}
+	return nil
+}
+
 func ValidateDeleteMessage(ctx context.Context, request domainMessage.DeleteRequest) error {
 	err := validation.ValidateStructWithContext(ctx, &request,
 		validation.Field(&request.Phone, validation.Required),
@@ -90,6 +144,33 @@ func ValidateStarMessage(ctx context.Context, request domainMessage.StarRequest)
 	return nil
 }
 
+func ValidatePinMessage(ctx context.Context, request domainMessage.PinRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.Phone, validation.Required),
+		validation.Field(&request.MessageID, validation.Required),
+		validation.Field(&request.Duration, validation.When(request.IsPinned, validation.Required, validation.In("24h", "7d", "30d").Error("must be 24h, 7d or 30d"))),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateGetMessageMedia(ctx context.Context, request domainMessage.GetMessageMediaRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.Phone, validation.Required),
+		validation.Field(&request.MessageID, validation.Required),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
 func ValidateDownloadMedia(ctx context.Context, request domainMessage.DownloadMediaRequest) error {
 	err := validation.ValidateStructWithContext(ctx, &request,
 		validation.Field(&request.Phone, validation.Required),
@@ -122,13 +122,49 @@ func TestValidateReactMessage(t *testing.T) {
 			err: pkgError.ValidationError("message_id: cannot be blank."),
 		},
 		{
-			name: "should error with empty emoji",
+			name: "should success with empty emoji removing the reaction",
 			args: args{request: domainMessage.ReactionRequest{
 				Phone:     "6281234567890@s.whatsapp.net",
 				MessageID: "3EB0789ABC123456",
 				Emoji:     "",
 			}},
-			err: pkgError.ValidationError("emoji: cannot be blank."),
+			err: nil,
+		},
+		{
+			name: "should success with skin tone emoji",
+			args: args{request: domainMessage.ReactionRequest{
+				Phone:     "6281234567890@s.whatsapp.net",
+				MessageID: "3EB0789ABC123456",
+				Emoji:     "👍🏽",
+			}},
+			err: nil,
+		},
+		{
+			name: "should success with ZWJ sequence emoji",
+			args: args{request: domainMessage.ReactionRequest{
+				Phone:     "6281234567890@s.whatsapp.net",
+				MessageID: "3EB0789ABC123456",
+				Emoji:     "👩🏻‍💻",
+			}},
+			err: nil,
+		},
+		{
+			name: "should success with flag emoji",
+			args: args{request: domainMessage.ReactionRequest{
+				Phone:     "6281234567890@s.whatsapp.net",
+				MessageID: "3EB0789ABC123456",
+				Emoji:     "🇮🇩",
+			}},
+			err: nil,
+		},
+		{
+			name: "should error with two emojis",
+			args: args{request: domainMessage.ReactionRequest{
+				Phone:     "6281234567890@s.whatsapp.net",
+				MessageID: "3EB0789ABC123456",
+				Emoji:     "👍👍",
+			}},
+			err: pkgError.ValidationError("emoji: must be a single emoji."),
 		},
 		{
 			name: "should error with all empty fields",
@@ -137,7 +173,7 @@ func TestValidateReactMessage(t *testing.T) {
 				MessageID: "",
 				Emoji:     "",
 			}},
-			err: pkgError.ValidationError("emoji: cannot be blank; message_id: cannot be blank; phone: cannot be blank."),
+			err: pkgError.ValidationError("message_id: cannot be blank; phone: cannot be blank."),
 		},
 	}
 
@@ -150,7 +186,6 @@ func TestValidateReactMessage(t *testing.T) {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), "phone: cannot be blank")
 				assert.Contains(t, err.Error(), "message_id: cannot be blank")
-				assert.Contains(t, err.Error(), "emoji: cannot be blank")
 			} else {
 				assert.Equal(t, tt.err, err)
 			}
@@ -291,3 +326,136 @@ func TestValidateStarMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatePinMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainMessage.PinRequest
+		err     any
+	}{
+		{
+			name:    "should success pinning for 7 days",
+			request: domainMessage.PinRequest{Phone: "6281234567890@s.whatsapp.net", MessageID: "3EB0789ABC123456", Duration: "7d", IsPinned: true},
+		},
+		{
+			name:    "should success unpinning without duration",
+			request: domainMessage.PinRequest{Phone: "6281234567890@s.whatsapp.net", MessageID: "3EB0789ABC123456"},
+		},
+		{
+			name:    "should error pinning without duration",
+			request: domainMessage.PinRequest{Phone: "6281234567890@s.whatsapp.net", MessageID: "3EB0789ABC123456", IsPinned: true},
+			err:     pkgError.ValidationError("duration: cannot be blank."),
+		},
+		{
+			name:    "should error with unsupported duration",
+			request: domainMessage.PinRequest{Phone: "6281234567890@s.whatsapp.net", MessageID: "3EB0789ABC123456", Duration: "2d", IsPinned: true},
+			err:     pkgError.ValidationError("duration: must be 24h, 7d or 30d."),
+		},
+		{
+			name:    "should error with empty message id",
+			request: domainMessage.PinRequest{Phone: "6281234567890@s.whatsapp.net", Duration: "24h", IsPinned: true},
+			err:     pkgError.ValidationError("message_id: cannot be blank."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePinMessage(context.Background(), tt.request)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, tt.err, err)
+			}
+		})
+	}
+}
+
+func TestValidateGetReceipts(t *testing.T) {
+	type args struct {
+		request domainMessage.GetReceiptsRequest
+	}
+	tests := []struct {
+		name string
+		args args
+		err  any
+	}{
+		{
+			name: "should success with valid phone and message id",
+			args: args{request: domainMessage.GetReceiptsRequest{
+				Phone:     "120363024512399999@g.us",
+				MessageID: "3EB0789ABC123456",
+			}},
+			err: nil,
+		},
+		{
+			name: "should error with empty phone",
+			args: args{request: domainMessage.GetReceiptsRequest{
+				MessageID: "3EB0789ABC123456",
+			}},
+			err: pkgError.ValidationError("phone: cannot be blank."),
+		},
+		{
+			name: "should error with empty message id",
+			args: args{request: domainMessage.GetReceiptsRequest{
+				Phone: "6281234567890@s.whatsapp.net",
+			}},
+			err: pkgError.ValidationError("message_id: cannot be blank."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGetReceipts(context.Background(), tt.args.request)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, tt.err, err)
+			}
+		})
+	}
+}
+
+func TestValidateGetPoll(t *testing.T) {
+	type args struct {
+		request domainMessage.GetPollRequest
+	}
+	tests := []struct {
+		name string
+		args args
+		err  any
+	}{
+		{
+			name: "should success with valid phone and message id",
+			args: args{request: domainMessage.GetPollRequest{
+				Phone:     "120363024512399999@g.us",
+				MessageID: "3EB0789ABC123456",
+			}},
+			err: nil,
+		},
+		{
+			name: "should error with empty phone",
+			args: args{request: domainMessage.GetPollRequest{
+				MessageID: "3EB0789ABC123456",
+			}},
+			err: pkgError.ValidationError("phone: cannot be blank."),
+		},
+		{
+			name: "should error with empty message id",
+			args: args{request: domainMessage.GetPollRequest{
+				Phone: "6281234567890@s.whatsapp.net",
+			}},
+			err: pkgError.ValidationError("message_id: cannot be blank."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGetPoll(context.Background(), tt.args.request)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, tt.err, err)
+			}
+		})
+	}
+}
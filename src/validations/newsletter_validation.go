@@ -2,14 +2,125 @@ package validations
 
 import (
 	"context"
+	"regexp"
+
 	domainNewsletter "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/newsletter"
 	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
 )
 
+// newsletterJID accepts channel JIDs, e.g. 120363123456789@newsletter.
+var newsletterJID = validation.Match(regexp.MustCompile(`^\d+@newsletter$`)).Error("must be a newsletter JID like 120363123456789@newsletter")
+
+func ValidateCreateNewsletter(ctx context.Context, request domainNewsletter.CreateNewsletterRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.Name, validation.Required, validation.RuneLength(1, 100)),
+		validation.Field(&request.Description, validation.RuneLength(0, 2048)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	if request.Picture != nil {
+		contentType := request.Picture.Header.Get("Content-Type")
+		if contentType != "" && !isImageContentType(contentType) {
+			return pkgError.ValidationError("uploaded file must be an image")
+		}
+	}
+
+	return nil
+}
+
+func ValidateFollowNewsletter(ctx context.Context, request domainNewsletter.FollowRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.NewsletterID, validation.Required, newsletterJID),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateMuteNewsletter(ctx context.Context, request domainNewsletter.MuteRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.NewsletterID, validation.Required, newsletterJID),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateSendNewsletter(ctx context.Context, request domainNewsletter.SendRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.NewsletterID, validation.Required, newsletterJID),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	hasImage := request.Image != nil || (request.ImageURL != nil && *request.ImageURL != "")
+	if !hasImage && request.Message == "" {
+		return pkgError.ValidationError("either message or an image must be provided")
+	}
+
+	if request.Image != nil {
+		contentType := request.Image.Header.Get("Content-Type")
+		if contentType != "" && !isImageContentType(contentType) {
+			return pkgError.ValidationError("uploaded file must be an image")
+		}
+	}
+
+	if request.ImageURL != nil && *request.ImageURL != "" {
+		if err := validation.Validate(*request.ImageURL, is.URL); err != nil {
+			return pkgError.ValidationError("image_url must be a valid URL")
+		}
+	}
+
+	return nil
+}
+
+func ValidateReactNewsletter(ctx context.Context, request domainNewsletter.ReactRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.NewsletterID, validation.Required, newsletterJID),
+		validation.Field(&request.ServerID, validation.Required, validation.Min(int64(1))),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
 func ValidateUnfollowNewsletter(ctx context.Context, request domainNewsletter.UnfollowRequest) error {
 	err := validation.ValidateStructWithContext(ctx, &request,
-		validation.Field(&request.NewsletterID, validation.Required),
+		validation.Field(&request.NewsletterID, validation.Required, newsletterJID),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateGetNewsletterMessages(ctx context.Context, request *domainNewsletter.GetNewsletterMessagesRequest) error {
+	// Set default count if not provided
+	if request.Count == 0 {
+		request.Count = 20
+	}
+
+	err := validation.ValidateStructWithContext(ctx, request,
+		validation.Field(&request.NewsletterID, validation.Required, newsletterJID),
+		validation.Field(&request.Count, validation.Min(1), validation.Max(100)),
 	)
 
 	if err != nil {
@@ -2,6 +2,9 @@ package validations
 
 import (
 	"context"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
 	"testing"
 
 	domainNewsletter "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/newsletter"
@@ -26,11 +29,11 @@ func TestValidateUnfollowNewsletter(t *testing.T) {
 			err: nil,
 		},
 		{
-			name: "should success with different newsletter id format",
+			name: "should error with a non newsletter id",
 			args: args{request: domainNewsletter.UnfollowRequest{
-				NewsletterID: "newsletter-abc123xyz",
+				NewsletterID: "120363123456789@g.us",
 			}},
-			err: nil,
+			err: pkgError.ValidationError("newsletter_id: must be a newsletter JID like 120363123456789@newsletter."),
 		},
 		{
 			name: "should error with empty newsletter id",
@@ -48,3 +51,152 @@ func TestValidateUnfollowNewsletter(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateGetNewsletterMessages(t *testing.T) {
+	tests := []struct {
+		name      string
+		request   domainNewsletter.GetNewsletterMessagesRequest
+		wantCount int
+		err       any
+	}{
+		{
+			name:      "should default count",
+			request:   domainNewsletter.GetNewsletterMessagesRequest{NewsletterID: "120363123456789@newsletter"},
+			wantCount: 20,
+			err:       nil,
+		},
+		{
+			name:      "should error with count too high",
+			request:   domainNewsletter.GetNewsletterMessagesRequest{NewsletterID: "120363123456789@newsletter", Count: 101},
+			wantCount: 101,
+			err:       pkgError.ValidationError("count: must be no greater than 100."),
+		},
+		{
+			name:      "should error with empty newsletter id",
+			request:   domainNewsletter.GetNewsletterMessagesRequest{Count: 10},
+			wantCount: 10,
+			err:       pkgError.ValidationError("newsletter_id: cannot be blank."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGetNewsletterMessages(context.Background(), &tt.request)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.wantCount, tt.request.Count)
+		})
+	}
+}
+
+func TestValidateCreateNewsletter(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainNewsletter.CreateNewsletterRequest
+		err     any
+	}{
+		{
+			name:    "should success with name and description",
+			request: domainNewsletter.CreateNewsletterRequest{Name: "Street news", Description: "What happens on our street"},
+		},
+		{
+			name:    "should error with empty name",
+			request: domainNewsletter.CreateNewsletterRequest{Description: "What happens on our street"},
+			err:     pkgError.ValidationError("name: cannot be blank."),
+		},
+		{
+			name:    "should error with name too long",
+			request: domainNewsletter.CreateNewsletterRequest{Name: strings.Repeat("a", 101)},
+			err:     pkgError.ValidationError("name: the length must be between 1 and 100."),
+		},
+		{
+			name: "should error with a picture that is not an image",
+			request: domainNewsletter.CreateNewsletterRequest{Name: "Street news", Picture: &multipart.FileHeader{
+				Filename: "notes.txt",
+				Header:   textproto.MIMEHeader{"Content-Type": []string{"text/plain"}},
+			}},
+			err: pkgError.ValidationError("uploaded file must be an image"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCreateNewsletter(context.Background(), tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestValidateFollowNewsletter(t *testing.T) {
+	assert.NoError(t, ValidateFollowNewsletter(context.Background(), domainNewsletter.FollowRequest{NewsletterID: "120363123456789@newsletter"}))
+	assert.Equal(t, pkgError.ValidationError("newsletter_id: cannot be blank."),
+		ValidateFollowNewsletter(context.Background(), domainNewsletter.FollowRequest{}))
+	assert.Equal(t, pkgError.ValidationError("newsletter_id: must be a newsletter JID like 120363123456789@newsletter."),
+		ValidateFollowNewsletter(context.Background(), domainNewsletter.FollowRequest{NewsletterID: "120363123456789"}))
+}
+
+func TestValidateMuteNewsletter(t *testing.T) {
+	muted := false
+	assert.NoError(t, ValidateMuteNewsletter(context.Background(), domainNewsletter.MuteRequest{NewsletterID: "120363123456789@newsletter"}))
+	assert.NoError(t, ValidateMuteNewsletter(context.Background(), domainNewsletter.MuteRequest{NewsletterID: "120363123456789@newsletter", Mute: &muted}))
+	assert.Equal(t, pkgError.ValidationError("newsletter_id: must be a newsletter JID like 120363123456789@newsletter."),
+		ValidateMuteNewsletter(context.Background(), domainNewsletter.MuteRequest{NewsletterID: "628123@s.whatsapp.net"}))
+}
+
+func TestValidateSendNewsletter(t *testing.T) {
+	newsletter := "120363123456789@newsletter"
+	imageURL := "https://example.com/photo.jpg"
+	badURL := "not a url"
+	tests := []struct {
+		name    string
+		request domainNewsletter.SendRequest
+		err     any
+	}{
+		{
+			name:    "should success with a message",
+			request: domainNewsletter.SendRequest{NewsletterID: newsletter, Message: "Road closed today"},
+		},
+		{
+			name:    "should success with an image url and no caption",
+			request: domainNewsletter.SendRequest{NewsletterID: newsletter, ImageURL: &imageURL},
+		},
+		{
+			name:    "should error without message or image",
+			request: domainNewsletter.SendRequest{NewsletterID: newsletter},
+			err:     pkgError.ValidationError("either message or an image must be provided"),
+		},
+		{
+			name:    "should error with a group id",
+			request: domainNewsletter.SendRequest{NewsletterID: "120363123456789@g.us", Message: "hi"},
+			err:     pkgError.ValidationError("newsletter_id: must be a newsletter JID like 120363123456789@newsletter."),
+		},
+		{
+			name:    "should error with an invalid image url",
+			request: domainNewsletter.SendRequest{NewsletterID: newsletter, ImageURL: &badURL},
+			err:     pkgError.ValidationError("image_url must be a valid URL"),
+		},
+		{
+			name: "should error with a file that is not an image",
+			request: domainNewsletter.SendRequest{NewsletterID: newsletter, Image: &multipart.FileHeader{
+				Filename: "notes.txt",
+				Header:   textproto.MIMEHeader{"Content-Type": []string{"text/plain"}},
+			}},
+			err: pkgError.ValidationError("uploaded file must be an image"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSendNewsletter(context.Background(), tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestValidateReactNewsletter(t *testing.T) {
+	assert.NoError(t, ValidateReactNewsletter(context.Background(), domainNewsletter.ReactRequest{NewsletterID: "120363123456789@newsletter", ServerID: 118, Emoji: "👍"}))
+	assert.NoError(t, ValidateReactNewsletter(context.Background(), domainNewsletter.ReactRequest{NewsletterID: "120363123456789@newsletter", ServerID: 118}), "an empty emoji removes the reaction")
+	assert.Equal(t, pkgError.ValidationError("server_id: cannot be blank."),
+		ValidateReactNewsletter(context.Background(), domainNewsletter.ReactRequest{NewsletterID: "120363123456789@newsletter"}))
+	assert.Equal(t, pkgError.ValidationError("server_id: must be no less than 1."),
+		ValidateReactNewsletter(context.Background(), domainNewsletter.ReactRequest{NewsletterID: "120363123456789@newsletter", ServerID: -1}))
+}
@@ -0,0 +1,33 @@
+package validations
+
+import (
+	"context"
+
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/ratelimit"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// maxRateLimitBurst keeps a typo from disabling the limit in all but name
+const maxRateLimitBurst = 10000
+
+func ValidateRateLimits(ctx context.Context, limits ratelimit.Limits) error {
+	err := validation.ValidateStructWithContext(ctx, &limits,
+		validation.Field(&limits.Send, validation.By(validateRateLimit)),
+		validation.Field(&limits.Bulk, validation.By(validateRateLimit)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func validateRateLimit(value any) error {
+	limit, _ := value.(ratelimit.Limit)
+	return validation.ValidateStruct(&limit,
+		validation.Field(&limit.PerMinute, validation.Min(0)),
+		validation.Field(&limit.Burst, validation.Min(0), validation.Max(maxRateLimitBurst)),
+	)
+}
@@ -0,0 +1,45 @@
+package validations
+
+import (
+	"context"
+	"testing"
+
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRateLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits ratelimit.Limits
+		err    any
+	}{
+		{
+			name:   "should success with limits",
+			limits: ratelimit.Limits{Send: ratelimit.Limit{PerMinute: 60, Burst: 10}, Bulk: ratelimit.Limit{PerMinute: 2, Burst: 1}},
+			err:    nil,
+		},
+		{
+			name:   "should success without limits",
+			limits: ratelimit.Limits{},
+			err:    nil,
+		},
+		{
+			name:   "should error with negative rate",
+			limits: ratelimit.Limits{Send: ratelimit.Limit{PerMinute: -1}},
+			err:    pkgError.ValidationError("send: (per_minute: must be no less than 0.)."),
+		},
+		{
+			name:   "should error with too big burst",
+			limits: ratelimit.Limits{Bulk: ratelimit.Limit{PerMinute: 1, Burst: 10001}},
+			err:    pkgError.ValidationError("bulk: (burst: must be no greater than 10000.)."),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRateLimits(context.Background(), tt.limits)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
@@ -3,11 +3,15 @@ package validations
 import (
 	"context"
 	"fmt"
+	"mime/multipart"
+	"regexp"
+	"slices"
 	"sort"
 
 	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
 	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
 	"github.com/dustin/go-humanize"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
@@ -60,7 +64,7 @@ func validatePhoneNumber(phone string) error {
 func ValidateSendMessage(ctx context.Context, request domainSend.MessageRequest) error {
 	err := validation.ValidateStructWithContext(ctx, &request,
 		validation.Field(&request.Phone, validation.Required),
-		validation.Field(&request.Message, validation.Required),
+		validation.Field(&request.Message, validation.When(request.TemplateName == "", validation.Required).Else(validation.Empty.Error("must be blank when template_name is set"))),
 	)
 
 	if err != nil {
@@ -77,7 +81,11 @@ func ValidateSendMessage(ctx context.Context, request domainSend.MessageRequest)
 		return err
 	}
 
-	// Validate mentions if provided
+	return validateMentions(request.MentionRequest)
+}
+
+// validateMentions checks the explicit mentions of a text or caption.
+func validateMentions(request domainSend.MentionRequest) error {
 	for _, mention := range request.Mentions {
 		// Skip validation for special @everyone keyword
 		if mention == "@everyone" {
@@ -87,49 +95,83 @@ func ValidateSendMessage(ctx context.Context, request domainSend.MessageRequest)
 			return pkgError.ValidationError(fmt.Sprintf("mention %s: phone number must be in international format", mention))
 		}
 	}
-
 	return nil
 }
 
-func ValidateSendImage(ctx context.Context, request domainSend.ImageRequest) error {
+// bulkMaxDelayMs caps the pause between two recipients of a bulk send
+const bulkMaxDelayMs = 60000
+
+func ValidateSendBulk(ctx context.Context, request domainSend.BulkMessageRequest) error {
 	err := validation.ValidateStructWithContext(ctx, &request,
-		validation.Field(&request.Phone, validation.Required),
+		validation.Field(&request.Phones, validation.Required, validation.Length(0, config.WhatsappSendBulkMaxRecipients)),
+		validation.Field(&request.Message, validation.When(request.TemplateName == "", validation.Required).Else(validation.Empty.Error("must be blank when template_name is set"))),
+		validation.Field(&request.DelayMsMin, validation.Min(0), validation.Max(bulkMaxDelayMs)),
+		validation.Field(&request.DelayMsMax, validation.Min(request.DelayMsMin), validation.Max(bulkMaxDelayMs)),
 	)
 
 	if err != nil {
 		return pkgError.ValidationError(err.Error())
 	}
 
-	// Custom validation for phone number format
-	if err := validatePhoneNumber(request.Phone); err != nil {
-		return err
+	for _, phone := range request.Phones {
+		if err := validatePhoneNumber(phone); err != nil {
+			return pkgError.ValidationError(fmt.Sprintf("phone %s: phone number must be in international format", phone))
+		}
 	}
 
-	if request.Image == nil && (request.ImageURL == nil || *request.ImageURL == "") {
-		return pkgError.ValidationError("either Image or ImageURL must be provided")
+	if len(request.RecipientVariables) > 0 && request.TemplateName == "" {
+		return pkgError.ValidationError("recipient_variables: only allowed with template_name.")
 	}
-
-	if request.Image != nil {
-		availableMimes := map[string]bool{
-			"image/jpeg": true,
-			"image/jpg":  true,
-			"image/png":  true,
+	for phone := range request.RecipientVariables {
+		if !slices.Contains(request.Phones, phone) {
+			return pkgError.ValidationError(fmt.Sprintf("recipient_variables: %s is not one of the phones.", phone))
 		}
+	}
 
-		if !availableMimes[request.Image.Header.Get("Content-Type")] {
-			return pkgError.ValidationError("your image is not allowed. please use jpg/jpeg/png")
-		}
+	return nil
+}
+
+func ValidateForwardMessage(ctx context.Context, request domainSend.ForwardRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.MessageID, validation.Required),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
 	}
 
-	if request.ImageURL != nil {
-		if *request.ImageURL == "" {
-			return pkgError.ValidationError("ImageURL cannot be empty")
+	recipients := request.Recipients()
+	if len(recipients) == 0 {
+		return pkgError.ValidationError("to_phone: either to_phone or to_phones is required.")
+	}
+	if len(recipients) > config.WhatsappSendBulkMaxRecipients {
+		return pkgError.ValidationError(fmt.Sprintf("to_phones: the length must be no more than %d.", config.WhatsappSendBulkMaxRecipients))
+	}
+	for _, phone := range recipients {
+		if err := validatePhoneNumber(phone); err != nil {
+			return pkgError.ValidationError(fmt.Sprintf("phone %s: phone number must be in international format", phone))
 		}
+	}
 
-		err := validation.Validate(*request.ImageURL, is.URL)
-		if err != nil {
-			return pkgError.ValidationError("ImageURL must be a valid URL")
-		}
+	return nil
+}
+
+func ValidateSendImage(ctx context.Context, request domainSend.ImageRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.Phone, validation.Required),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	// Custom validation for phone number format
+	if err := validatePhoneNumber(request.Phone); err != nil {
+		return err
+	}
+
+	if err := validateImageSource(request.Image, request.ImageURL); err != nil {
+		return err
 	}
 
 	// Validate duration
@@ -137,7 +179,7 @@ func ValidateSendImage(ctx context.Context, request domainSend.ImageRequest) err
 		return err
 	}
 
-	return nil
+	return validateMentions(request.MentionRequest)
 }
 
 func ValidateSendSticker(ctx context.Context, request domainSend.StickerRequest) error {
@@ -252,53 +294,107 @@ func ValidateSendVideo(ctx context.Context, request domainSend.VideoRequest) err
 		return err
 	}
 
+	if err := validateVideoSource(request.Video, request.VideoURL); err != nil {
+		return err
+	}
+
+	if err := validateDuration(request.Duration); err != nil {
+		return err
+	}
+
+	return validateMentions(request.MentionRequest)
+}
+
+// validateImageSource checks the uploaded image or the URL to download it from.
+func validateImageSource(image *multipart.FileHeader, imageURL *string) error {
+	if image == nil && (imageURL == nil || *imageURL == "") {
+		return pkgError.ValidationError("either Image or ImageURL must be provided")
+	}
+
+	if image != nil {
+		availableMimes := map[string]bool{
+			"image/jpeg": true,
+			"image/jpg":  true,
+			"image/png":  true,
+			"image/webp": true,
+			"image/gif":  true,
+		}
+
+		if !availableMimes[image.Header.Get("Content-Type")] {
+			return pkgError.ValidationError("your image is not allowed. please use jpg/jpeg/png/webp/gif")
+		}
+	}
+
+	if imageURL != nil {
+		if *imageURL == "" {
+			return pkgError.ValidationError("ImageURL cannot be empty")
+		}
+
+		err := validation.Validate(*imageURL, is.URL)
+		if err != nil {
+			return pkgError.ValidationError("ImageURL must be a valid URL")
+		}
+	}
+
+	return nil
+}
+
+// validateVideoSource checks the uploaded video or the URL to download it from.
+func validateVideoSource(video *multipart.FileHeader, videoURL *string) error {
 	// Ensure at least one of Video or VideoURL is provided
-	if request.Video == nil && (request.VideoURL == nil || *request.VideoURL == "") {
+	if video == nil && (videoURL == nil || *videoURL == "") {
 		return pkgError.ValidationError("either Video or VideoURL must be provided")
 	}
 
 	// If Video file provided perform MIME / size validation
-	if request.Video != nil {
+	if video != nil {
 		availableMimes := map[string]bool{
 			"video/mp4":        true,
 			"video/x-matroska": true,
 			"video/avi":        true,
 			"video/x-msvideo":  true,
+			"video/quicktime":  true,
+			"video/webm":       true,
+			"video/3gpp":       true,
 		}
 
-		if !availableMimes[request.Video.Header.Get("Content-Type")] {
-			return pkgError.ValidationError("your video type is not allowed. please use mp4/mkv/avi/x-msvideo")
+		if !availableMimes[video.Header.Get("Content-Type")] {
+			return pkgError.ValidationError("your video type is not allowed. please use mp4/mkv/avi/x-msvideo/mov/webm/3gp")
 		}
 
-		if request.Video.Size > config.WhatsappSettingMaxVideoSize { // 30MB
+		if video.Size > config.WhatsappSettingMaxVideoSize { // 30MB
 			maxSizeString := humanize.Bytes(uint64(config.WhatsappSettingMaxVideoSize))
 			return pkgError.ValidationError(fmt.Sprintf("max video upload is %s, please upload in cloud and send via text if your file is higher than %s", maxSizeString, maxSizeString))
 		}
 	}
 
 	// If VideoURL provided, validate url
-	if request.VideoURL != nil {
-		if *request.VideoURL == "" {
+	if videoURL != nil {
+		if *videoURL == "" {
 			return pkgError.ValidationError("VideoURL cannot be empty")
 		}
 
-		if err := validation.Validate(*request.VideoURL, is.URL); err != nil {
+		if err := validation.Validate(*videoURL, is.URL); err != nil {
 			return pkgError.ValidationError("VideoURL must be a valid URL")
 		}
 	}
 
-	if err := validateDuration(request.Duration); err != nil {
-		return err
-	}
-
 	return nil
 }
 
+// maxContactCards caps the cards of one contacts array message
+const maxContactCards = 50
+
+// e164Digits matches an E.164 number without its + prefix
+var e164Digits = regexp.MustCompile(`^[1-9][0-9]{7,14}$`)
+
 func ValidateSendContact(ctx context.Context, request domainSend.ContactRequest) error {
+	single := len(request.Contacts) == 0
 	err := validation.ValidateStructWithContext(ctx, &request,
 		validation.Field(&request.Phone, validation.Required),
-		validation.Field(&request.ContactPhone, validation.Required),
-		validation.Field(&request.ContactName, validation.Required),
+		validation.Field(&request.ContactPhone, validation.When(single, validation.Required)),
+		validation.Field(&request.ContactName, validation.When(single, validation.Required)),
+		validation.Field(&request.Contacts, validation.Length(0, maxContactCards)),
 	)
 
 	if err != nil {
@@ -310,9 +406,26 @@ func ValidateSendContact(ctx context.Context, request domainSend.ContactRequest)
 		return err
 	}
 
-	// Custom validation for contact phone number format
-	if err := validatePhoneNumber(request.ContactPhone); err != nil {
-		return pkgError.ValidationError("contact " + err.Error())
+	if single {
+		for _, phone := range append([]string{request.ContactPhone}, request.ContactPhones...) {
+			if err := validateContactPhone(phone); err != nil {
+				return err
+			}
+		}
+	}
+	for i, card := range request.Contacts {
+		err := validation.ValidateStruct(&card,
+			validation.Field(&card.ContactName, validation.Required),
+			validation.Field(&card.ContactPhone, validation.Required),
+		)
+		if err != nil {
+			return pkgError.ValidationError(fmt.Sprintf("contacts %d: %s", i+1, err.Error()))
+		}
+		for _, phone := range append([]string{card.ContactPhone}, card.ContactPhones...) {
+			if err := validateContactPhone(phone); err != nil {
+				return pkgError.ValidationError(fmt.Sprintf("contacts %d: %s", i+1, err.Error()))
+			}
+		}
 	}
 
 	if err := validateDuration(request.Duration); err != nil {
@@ -322,6 +435,18 @@ func ValidateSendContact(ctx context.Context, request domainSend.ContactRequest)
 	return nil
 }
 
+// validateContactPhone checks that a shared contact's number is a plausible
+// E.164 number once the formatting people type is dropped.
+func validateContactPhone(phone string) error {
+	if err := validatePhoneNumber(phone); err != nil {
+		return pkgError.ValidationError("contact " + err.Error())
+	}
+	if !e164Digits.MatchString(utils.NormalizePhoneDigits(phone)) {
+		return pkgError.ValidationError(fmt.Sprintf("contact phone %s must be an international number of 8 to 15 digits", phone))
+	}
+	return nil
+}
+
 func ValidateSendLink(ctx context.Context, request domainSend.LinkRequest) error {
 	err := validation.ValidateStructWithContext(ctx, &request,
 		validation.Field(&request.Phone, validation.Required),
@@ -368,6 +493,37 @@ func ValidateSendLocation(ctx context.Context, request domainSend.LocationReques
 	return nil
 }
 
+// Live location shares last from a minute up to WhatsApp's 8 hour maximum
+const (
+	liveLocationMinDuration = 60
+	liveLocationMaxDuration = 8 * 60 * 60
+)
+
+func ValidateSendLiveLocation(ctx context.Context, request domainSend.LiveLocationRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.Phone, validation.Required),
+		validation.Field(&request.Latitude, validation.Required, is.Latitude),
+		validation.Field(&request.Longitude, validation.Required, is.Longitude),
+		validation.Field(&request.AccuracyInMeters, validation.Min(0)),
+		validation.Field(&request.LiveDuration, validation.Required, validation.Min(liveLocationMinDuration), validation.Max(liveLocationMaxDuration)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	// Custom validation for phone number format
+	if err := validatePhoneNumber(request.Phone); err != nil {
+		return err
+	}
+
+	if err := validateDuration(request.Duration); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func ValidateSendAudio(ctx context.Context, request domainSend.AudioRequest) error {
 	err := validation.ValidateStructWithContext(ctx, &request,
 		validation.Field(&request.Phone, validation.Required),
@@ -443,6 +599,12 @@ func ValidateSendAudio(ctx context.Context, request domainSend.AudioRequest) err
 	return nil
 }
 
+// WhatsApp polls have between pollMinOptions and pollMaxOptions options
+const (
+	pollMinOptions = 2
+	pollMaxOptions = 12
+)
+
 func ValidateSendPoll(ctx context.Context, request domainSend.PollRequest) error {
 	// Validate options first to ensure it is not blank before validating MaxAnswer
 	if len(request.Options) == 0 {
@@ -453,7 +615,7 @@ func ValidateSendPoll(ctx context.Context, request domainSend.PollRequest) error
 		validation.Field(&request.Phone, validation.Required),
 		validation.Field(&request.Question, validation.Required),
 
-		validation.Field(&request.Options, validation.Each(validation.Required)),
+		validation.Field(&request.Options, validation.Length(pollMinOptions, pollMaxOptions), validation.Each(validation.Required)),
 
 		validation.Field(&request.MaxAnswer, validation.Required),
 		validation.Field(&request.MaxAnswer, validation.Min(1)),
@@ -485,6 +647,40 @@ func ValidateSendPoll(ctx context.Context, request domainSend.PollRequest) error
 	return nil
 }
 
+// maxStatusTextLength is the most characters WhatsApp shows of a text status
+const maxStatusTextLength = 700
+
+// statusBackgroundColor matches the #RRGGBB background of a text status
+var statusBackgroundColor = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+func ValidateSendStatus(ctx context.Context, request domainSend.StatusRequest) error {
+	statusFonts := make([]any, len(domainSend.StatusFonts))
+	for i, font := range domainSend.StatusFonts {
+		statusFonts[i] = font
+	}
+	isText := request.Type == domainSend.StatusTypeText
+
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.Type, validation.Required, validation.In(domainSend.StatusTypeText, domainSend.StatusTypeImage, domainSend.StatusTypeVideo)),
+		validation.Field(&request.Text, validation.When(isText, validation.Required, validation.RuneLength(1, maxStatusTextLength)).Else(validation.Empty)),
+		validation.Field(&request.BackgroundColor, validation.When(isText, validation.Match(statusBackgroundColor).Error("must be a color like #1E6E4F")).Else(validation.Empty)),
+		validation.Field(&request.Font, validation.When(isText, validation.In(statusFonts...)).Else(validation.Empty)),
+		validation.Field(&request.Caption, validation.When(isText, validation.Empty)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	switch request.Type {
+	case domainSend.StatusTypeImage:
+		return validateImageSource(request.Image, request.ImageURL)
+	case domainSend.StatusTypeVideo:
+		return validateVideoSource(request.Video, request.VideoURL)
+	}
+	return nil
+}
+
 func ValidateSendPresence(ctx context.Context, request domainSend.PresenceRequest) error {
 	err := validation.ValidateStructWithContext(ctx, &request,
 		validation.Field(&request.Type, validation.In("available", "unavailable")),
@@ -5,6 +5,7 @@ import (
 	"mime/multipart"
 	"testing"
 
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/config"
 	domainMessage "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/message"
 	domainSend "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/send"
 	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
@@ -60,6 +61,129 @@ func TestValidateSendMessage(t *testing.T) {
 	}
 }
 
+func TestValidateSendBulk(t *testing.T) {
+	originalMax := config.WhatsappSendBulkMaxRecipients
+	t.Cleanup(func() { config.WhatsappSendBulkMaxRecipients = originalMax })
+	config.WhatsappSendBulkMaxRecipients = 2
+
+	tests := []struct {
+		name    string
+		request domainSend.BulkMessageRequest
+		err     any
+	}{
+		{
+			name:    "should success with phones, message and delays",
+			request: domainSend.BulkMessageRequest{Phones: []string{"628111", "628222"}, Message: "Promo today", DelayMsMin: 1000, DelayMsMax: 3000},
+			err:     nil,
+		},
+		{
+			name:    "should error without phones",
+			request: domainSend.BulkMessageRequest{Message: "Promo today"},
+			err:     pkgError.ValidationError("phones: cannot be blank."),
+		},
+		{
+			name:    "should error with more phones than allowed",
+			request: domainSend.BulkMessageRequest{Phones: []string{"628111", "628222", "628333"}, Message: "Promo today"},
+			err:     pkgError.ValidationError("phones: the length must be no more than 2."),
+		},
+		{
+			name:    "should error with empty message",
+			request: domainSend.BulkMessageRequest{Phones: []string{"628111"}},
+			err:     pkgError.ValidationError("message: cannot be blank."),
+		},
+		{
+			name: "should success with a template instead of a message",
+			request: domainSend.BulkMessageRequest{
+				Phones:             []string{"628111", "628222"},
+				TemplateRequest:    domainSend.TemplateRequest{TemplateName: "promo", Variables: map[string]string{"day": "today"}},
+				RecipientVariables: map[string]map[string]string{"628222": {"name": "Bob"}},
+			},
+			err: nil,
+		},
+		{
+			name:    "should error with both a template and a message",
+			request: domainSend.BulkMessageRequest{Phones: []string{"628111"}, Message: "Promo today", TemplateRequest: domainSend.TemplateRequest{TemplateName: "promo"}},
+			err:     pkgError.ValidationError("message: must be blank when template_name is set."),
+		},
+		{
+			name: "should error with variables of another phone",
+			request: domainSend.BulkMessageRequest{
+				Phones:             []string{"628111"},
+				TemplateRequest:    domainSend.TemplateRequest{TemplateName: "promo"},
+				RecipientVariables: map[string]map[string]string{"628333": {"name": "Bob"}},
+			},
+			err: pkgError.ValidationError("recipient_variables: 628333 is not one of the phones."),
+		},
+		{
+			name:    "should error when the longest delay is below the shortest",
+			request: domainSend.BulkMessageRequest{Phones: []string{"628111"}, Message: "Promo today", DelayMsMin: 3000, DelayMsMax: 1000},
+			err:     pkgError.ValidationError("delay_ms_max: must be no less than 3000."),
+		},
+		{
+			name:    "should error with a local phone number",
+			request: domainSend.BulkMessageRequest{Phones: []string{"628111", "08111"}, Message: "Promo today"},
+			err:     pkgError.ValidationError("phone 08111: phone number must be in international format"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSendBulk(context.Background(), tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestValidateForwardMessage(t *testing.T) {
+	originalMax := config.WhatsappSendBulkMaxRecipients
+	t.Cleanup(func() { config.WhatsappSendBulkMaxRecipients = originalMax })
+	config.WhatsappSendBulkMaxRecipients = 2
+
+	tests := []struct {
+		name    string
+		request domainSend.ForwardRequest
+		err     any
+	}{
+		{
+			name:    "should success with to_phone",
+			request: domainSend.ForwardRequest{MessageID: "3EB0ABC", ToPhone: "628111"},
+			err:     nil,
+		},
+		{
+			name:    "should success with to_phone and to_phones",
+			request: domainSend.ForwardRequest{MessageID: "3EB0ABC", ToPhone: "628111", ToPhones: []string{"120363000000000000@g.us"}},
+			err:     nil,
+		},
+		{
+			name:    "should error without message_id",
+			request: domainSend.ForwardRequest{ToPhone: "628111"},
+			err:     pkgError.ValidationError("message_id: cannot be blank."),
+		},
+		{
+			name:    "should error without recipients",
+			request: domainSend.ForwardRequest{MessageID: "3EB0ABC"},
+			err:     pkgError.ValidationError("to_phone: either to_phone or to_phones is required."),
+		},
+		{
+			name:    "should error with more recipients than allowed",
+			request: domainSend.ForwardRequest{MessageID: "3EB0ABC", ToPhone: "628111", ToPhones: []string{"628222", "628333"}},
+			err:     pkgError.ValidationError("to_phones: the length must be no more than 2."),
+		},
+		{
+			name:    "should error with a local phone number",
+			request: domainSend.ForwardRequest{MessageID: "3EB0ABC", ToPhones: []string{"628111", "08111"}},
+			err:     pkgError.ValidationError("phone 08111: phone number must be in international format"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateForwardMessage(context.Background(), tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
 func TestValidateSendImage(t *testing.T) {
 	image := &multipart.FileHeader{
 		Filename: "sample-image.png",
@@ -118,7 +242,23 @@ func TestValidateSendImage(t *testing.T) {
 					Header:   map[string][]string{"Content-Type": {"application/pdf"}},
 				},
 			}},
-			err: pkgError.ValidationError("your image is not allowed. please use jpg/jpeg/png"),
+			err: pkgError.ValidationError("your image is not allowed. please use jpg/jpeg/png/webp/gif"),
+		},
+		{
+			name: "should error with local format mention in caption",
+			args: args{request: domainSend.ImageRequest{
+				BaseRequest: domainSend.BaseRequest{
+					Phone: "1728937129312@s.whatsapp.net",
+				},
+				Caption: "look @08123",
+				Image: &multipart.FileHeader{
+					Filename: "sample-image.png",
+					Size:     100,
+					Header:   map[string][]string{"Content-Type": {"image/png"}},
+				},
+				MentionRequest: domainSend.MentionRequest{Mentions: []string{"08123"}},
+			}},
+			err: pkgError.ValidationError("mention 08123: phone number must be in international format"),
 		},
 	}
 
@@ -256,7 +396,7 @@ func TestValidateSendVideo(t *testing.T) {
 				ViewOnce: false,
 				Compress: false,
 			}},
-			err: pkgError.ValidationError("your video type is not allowed. please use mp4/mkv/avi/x-msvideo"),
+			err: pkgError.ValidationError("your video type is not allowed. please use mp4/mkv/avi/x-msvideo/mov/webm/3gp"),
 		},
 		{
 			name: "should error with empty video and video_url",
@@ -521,6 +661,56 @@ func TestValidateSendContact(t *testing.T) {
 			}},
 			err: pkgError.ValidationError("contact_phone: cannot be blank."),
 		},
+		{
+			name: "should error with a contact phone that is too short",
+			args: args{request: domainSend.ContactRequest{
+				BaseRequest: domainSend.BaseRequest{
+					Phone: "1728937129312@s.whatsapp.net",
+				},
+				ContactName:  "Aldino",
+				ContactPhone: "62788",
+			}},
+			err: pkgError.ValidationError("contact phone 62788 must be an international number of 8 to 15 digits"),
+		},
+		{
+			name: "should success with a formatted contact phone and organization",
+			args: args{request: domainSend.ContactRequest{
+				BaseRequest: domainSend.BaseRequest{
+					Phone: "1728937129312@s.whatsapp.net",
+				},
+				ContactName:   "Aldino",
+				ContactPhone:  "+62 812-3456-7890",
+				ContactPhones: []string{"+1 (415) 555-0100"},
+				Organization:  "Acme",
+			}},
+			err: nil,
+		},
+		{
+			name: "should success with several contacts",
+			args: args{request: domainSend.ContactRequest{
+				BaseRequest: domainSend.BaseRequest{
+					Phone: "1728937129312@s.whatsapp.net",
+				},
+				Contacts: []domainSend.ContactCard{
+					{ContactName: "Aldino", ContactPhone: "62788712738123"},
+					{ContactName: "Budi", ContactPhone: "6289876543210"},
+				},
+			}},
+			err: nil,
+		},
+		{
+			name: "should error with a contact without name in the array",
+			args: args{request: domainSend.ContactRequest{
+				BaseRequest: domainSend.BaseRequest{
+					Phone: "1728937129312@s.whatsapp.net",
+				},
+				Contacts: []domainSend.ContactCard{
+					{ContactName: "Aldino", ContactPhone: "62788712738123"},
+					{ContactPhone: "6289876543210"},
+				},
+			}},
+			err: pkgError.ValidationError("contacts 2: contact_name: cannot be blank."),
+		},
 	}
 
 	for _, tt := range tests {
@@ -616,6 +806,43 @@ func TestValidateSendLocation(t *testing.T) {
 	}
 }
 
+func TestValidateSendLiveLocation(t *testing.T) {
+	base := domainSend.BaseRequest{Phone: "1728937129312@s.whatsapp.net"}
+	tests := []struct {
+		name    string
+		request domainSend.LiveLocationRequest
+		err     any
+	}{
+		{
+			name:    "should success with coordinates and duration",
+			request: domainSend.LiveLocationRequest{BaseRequest: base, Latitude: "-7.797068", Longitude: "110.370529", LiveDuration: 900},
+			err:     nil,
+		},
+		{
+			name:    "should error without duration",
+			request: domainSend.LiveLocationRequest{BaseRequest: base, Latitude: "-7.797068", Longitude: "110.370529"},
+			err:     pkgError.ValidationError("live_duration: cannot be blank."),
+		},
+		{
+			name:    "should error with a duration over 8 hours",
+			request: domainSend.LiveLocationRequest{BaseRequest: base, Latitude: "-7.797068", Longitude: "110.370529", LiveDuration: 28801},
+			err:     pkgError.ValidationError("live_duration: must be no greater than 28800."),
+		},
+		{
+			name:    "should error with an out of range latitude",
+			request: domainSend.LiveLocationRequest{BaseRequest: base, Latitude: "97.1", Longitude: "110.370529", LiveDuration: 900},
+			err:     pkgError.ValidationError("latitude: must be a valid latitude."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSendLiveLocation(context.Background(), tt.request)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
 func TestValidateSendAudio(t *testing.T) {
 	audio := &multipart.FileHeader{
 		Filename: "sample-audio.mp3",
@@ -766,6 +993,42 @@ func TestValidateSendPoll(t *testing.T) {
 			}},
 			err: pkgError.ValidationError("max_answer: must be no greater than 3."),
 		},
+		{
+			name: "should error with a single option",
+			args: args{request: domainSend.PollRequest{
+				BaseRequest: domainSend.BaseRequest{
+					Phone: "1728937129312@s.whatsapp.net",
+				},
+				Question:  "Coming tonight?",
+				Options:   []string{"Yes"},
+				MaxAnswer: 1,
+			}},
+			err: pkgError.ValidationError("options: the length must be between 2 and 12."),
+		},
+		{
+			name: "should error with more than 12 options",
+			args: args{request: domainSend.PollRequest{
+				BaseRequest: domainSend.BaseRequest{
+					Phone: "1728937129312@s.whatsapp.net",
+				},
+				Question:  "Pick a month",
+				Options:   []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec", "Smarch"},
+				MaxAnswer: 1,
+			}},
+			err: pkgError.ValidationError("options: the length must be between 2 and 12."),
+		},
+		{
+			name: "should success sending to a newsletter",
+			args: args{request: domainSend.PollRequest{
+				BaseRequest: domainSend.BaseRequest{
+					Phone: "120363025246125486@newsletter",
+				},
+				Question:  "Next topic?",
+				Options:   []string{"Go", "Rust"},
+				MaxAnswer: 1,
+			}},
+			err: nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1111,3 +1374,62 @@ func TestValidateSendAudio_WithDuration(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateSendStatus(t *testing.T) {
+	imageURL := "https://example.com/image.jpg"
+	tests := []struct {
+		name    string
+		request domainSend.StatusRequest
+		err     any
+	}{
+		{
+			name:    "should success with text status",
+			request: domainSend.StatusRequest{Type: "text", Text: "Gone fishing", BackgroundColor: "#1e6e4f", Font: "fb_script"},
+		},
+		{
+			name:    "should success with image status",
+			request: domainSend.StatusRequest{Type: "image", Caption: "Sunset", ImageURL: &imageURL},
+		},
+		{
+			name:    "should error with unknown type",
+			request: domainSend.StatusRequest{Type: "audio"},
+			err:     pkgError.ValidationError("type: must be a valid value."),
+		},
+		{
+			name:    "should error with text status without text",
+			request: domainSend.StatusRequest{Type: "text"},
+			err:     pkgError.ValidationError("text: cannot be blank."),
+		},
+		{
+			name:    "should error with invalid background color",
+			request: domainSend.StatusRequest{Type: "text", Text: "hi", BackgroundColor: "green"},
+			err:     pkgError.ValidationError("background_color: must be a color like #1E6E4F."),
+		},
+		{
+			name:    "should error with unknown font",
+			request: domainSend.StatusRequest{Type: "text", Text: "hi", Font: "comic_sans"},
+			err:     pkgError.ValidationError("font: must be a valid value."),
+		},
+		{
+			name:    "should error with text on image status",
+			request: domainSend.StatusRequest{Type: "image", Text: "hi", ImageURL: &imageURL},
+			err:     pkgError.ValidationError("text: must be blank."),
+		},
+		{
+			name:    "should error with video status without video",
+			request: domainSend.StatusRequest{Type: "video"},
+			err:     pkgError.ValidationError("either Video or VideoURL must be provided"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSendStatus(context.Background(), tt.request)
+			if tt.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package validations
+
+import (
+	"context"
+	"regexp"
+
+	domainTemplate "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/template"
+	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
+	"github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/utils"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+var templateNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// ValidateTemplate validates a template as it will be stored.
+func ValidateTemplate(ctx context.Context, tmpl domainTemplate.Template) error {
+	err := validation.ValidateStructWithContext(ctx, &tmpl,
+		validation.Field(&tmpl.Name, validation.Required,
+			validation.Match(templateNamePattern).Error("must be 1-64 letters, digits, '_', '.' or '-'")),
+		validation.Field(&tmpl.Body, validation.Required, validation.RuneLength(1, 4096),
+			validation.By(func(any) error {
+				_, err := utils.ParseMessageTemplate("body", tmpl.Body)
+				return err
+			})),
+	)
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+	return nil
+}
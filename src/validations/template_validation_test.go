@@ -0,0 +1,52 @@
+package validations
+
+import (
+	"context"
+	"testing"
+
+	domainTemplate "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/template"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template domainTemplate.Template
+		err      string
+	}{
+		{
+			name:     "should success with placeholders",
+			template: domainTemplate.Template{Name: "order.shipped", Body: "Hi {{.name}}, order {{.order}} is on its way"},
+		},
+		{
+			name:     "should error without name",
+			template: domainTemplate.Template{Body: "Hi"},
+			err:      "name: cannot be blank.",
+		},
+		{
+			name:     "should error with spaces in the name",
+			template: domainTemplate.Template{Name: "order shipped", Body: "Hi"},
+			err:      "name: must be 1-64 letters, digits, '_', '.' or '-'.",
+		},
+		{
+			name:     "should error with a function call",
+			template: domainTemplate.Template{Name: "greeting", Body: `Hi {{printf "%s" .name}}`},
+			err:      "body: body: only {{.name}} placeholders are allowed",
+		},
+		{
+			name:     "should error with an unclosed action",
+			template: domainTemplate.Template{Name: "greeting", Body: "Hi {{.name"},
+			err:      "body: template: body:1: unclosed action",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTemplate(context.Background(), tt.template)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
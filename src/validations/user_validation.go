@@ -44,3 +44,96 @@ func ValidateBusinessProfile(ctx context.Context, request domainUser.BusinessPro
 
 	return nil
 }
+
+func ValidateChangePresence(ctx context.Context, request domainUser.ChangePresenceRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.State, validation.Required, validation.In("available", "unavailable")),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+// MaxAboutLength is the most characters WhatsApp keeps of an about text.
+const MaxAboutLength = 139
+
+func ValidateChangeAvatar(ctx context.Context, request domainUser.ChangeAvatarRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.Avatar, validation.Required),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateChangeAbout(ctx context.Context, request domainUser.ChangeAboutRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.About, validation.Required, validation.RuneLength(1, MaxAboutLength)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+func ValidateBlock(ctx context.Context, request domainUser.BlockRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.Phone, validation.Required),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
+
+// privacyAudiences are the values of the settings that pick who sees something.
+var privacyAudiences = []any{"everyone", "contacts", "contact_blacklist", "none"}
+
+// ValidateSetPrivacy checks every setting against the values WhatsApp accepts
+// for it, e.g. read receipts can't be limited to contacts.
+func ValidateSetPrivacy(ctx context.Context, request domainUser.SetPrivacyRequest) error {
+	settings := &request.PrivacySettings
+	err := validation.ValidateStructWithContext(ctx, settings,
+		validation.Field(&settings.LastSeen, validation.In(privacyAudiences...)),
+		validation.Field(&settings.ProfilePhoto, validation.In(privacyAudiences...)),
+		validation.Field(&settings.Status, validation.In(privacyAudiences...)),
+		validation.Field(&settings.GroupsAdd, validation.In(privacyAudiences...)),
+		validation.Field(&settings.ReadReceipts, validation.In("everyone", "none")),
+		validation.Field(&settings.Online, validation.In("everyone", "match_last_seen")),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	if *settings == (domainUser.PrivacySettings{}) {
+		return pkgError.ValidationError("at least one of last_seen, profile_photo, status, read_receipts, groups_add or online is required")
+	}
+
+	return nil
+}
+
+// MaxCheckPhones is the most numbers one POST /user/check may check.
+const MaxCheckPhones = 500
+
+func ValidateCheckPhones(ctx context.Context, request domainUser.CheckPhonesRequest) error {
+	err := validation.ValidateStructWithContext(ctx, &request,
+		validation.Field(&request.Phones, validation.Required, validation.Length(1, MaxCheckPhones)),
+	)
+
+	if err != nil {
+		return pkgError.ValidationError(err.Error())
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	domainUser "github.com/aldinokemal/go-whatsapp-web-multidevice/domains/user"
 	pkgError "github.com/aldinokemal/go-whatsapp-web-multidevice/pkg/error"
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
 )
 
@@ -110,3 +111,81 @@ func TestValidateBusinessProfile(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateChangePresence(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domainUser.ChangePresenceRequest
+		err     any
+	}{
+		{
+			name:    "should success with available",
+			request: domainUser.ChangePresenceRequest{State: "available"},
+		},
+		{
+			name:    "should success with unavailable",
+			request: domainUser.ChangePresenceRequest{State: "unavailable"},
+		},
+		{
+			name:    "should error with empty state",
+			request: domainUser.ChangePresenceRequest{},
+			err:     pkgError.ValidationError("state: cannot be blank."),
+		},
+		{
+			name:    "should error with unknown state",
+			request: domainUser.ChangePresenceRequest{State: "typing"},
+			err:     pkgError.ValidationError("state: must be a valid value."),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateChangePresence(context.Background(), tt.request)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, tt.err, err)
+			}
+		})
+	}
+}
+
+func TestValidateCheckPhones(t *testing.T) {
+	assert.NoError(t, ValidateCheckPhones(context.Background(), domainUser.CheckPhonesRequest{Phones: []string{"+62 812-3456-789", "08123456789"}}))
+	assert.Equal(t, pkgError.ValidationError("phones: cannot be blank."),
+		ValidateCheckPhones(context.Background(), domainUser.CheckPhonesRequest{}))
+	assert.Equal(t, pkgError.ValidationError("phones: the length must be between 1 and 500."),
+		ValidateCheckPhones(context.Background(), domainUser.CheckPhonesRequest{Phones: make([]string, MaxCheckPhones+1)}))
+}
+
+func TestValidateBlock(t *testing.T) {
+	assert.NoError(t, ValidateBlock(context.Background(), domainUser.BlockRequest{Phone: "628123456789"}))
+	assert.Equal(t, pkgError.ValidationError("phone: cannot be blank."),
+		ValidateBlock(context.Background(), domainUser.BlockRequest{}))
+}
+
+func TestValidateSetPrivacy(t *testing.T) {
+	valid := domainUser.SetPrivacyRequest{PrivacySettings: domainUser.PrivacySettings{
+		LastSeen: "contacts", ProfilePhoto: "contact_blacklist", ReadReceipts: "none", Online: "match_last_seen",
+	}}
+	assert.NoError(t, ValidateSetPrivacy(context.Background(), valid))
+
+	assert.Equal(t, pkgError.ValidationError("at least one of last_seen, profile_photo, status, read_receipts, groups_add or online is required"),
+		ValidateSetPrivacy(context.Background(), domainUser.SetPrivacyRequest{}))
+	assert.Equal(t, pkgError.ValidationError("read_receipts: must be a valid value."),
+		ValidateSetPrivacy(context.Background(), domainUser.SetPrivacyRequest{PrivacySettings: domainUser.PrivacySettings{ReadReceipts: "contacts"}}))
+	assert.Equal(t, pkgError.ValidationError("online: must be a valid value."),
+		ValidateSetPrivacy(context.Background(), domainUser.SetPrivacyRequest{PrivacySettings: domainUser.PrivacySettings{Online: "none"}}))
+	assert.Equal(t, pkgError.ValidationError("groups_add: must be a valid value."),
+		ValidateSetPrivacy(context.Background(), domainUser.SetPrivacyRequest{PrivacySettings: domainUser.PrivacySettings{GroupsAdd: "all"}}))
+}
+
+func TestValidateChangeAbout(t *testing.T) {
+	assert.NoError(t, ValidateChangeAbout(context.Background(), domainUser.ChangeAboutRequest{About: strings.Repeat("é", MaxAboutLength)}))
+	assert.Equal(t, pkgError.ValidationError("about: cannot be blank."),
+		ValidateChangeAbout(context.Background(), domainUser.ChangeAboutRequest{}))
+	assert.Equal(t, pkgError.ValidationError("about: the length must be between 1 and 139."),
+		ValidateChangeAbout(context.Background(), domainUser.ChangeAboutRequest{About: strings.Repeat("a", MaxAboutLength+1)}))
+	assert.Equal(t, pkgError.ValidationError("avatar: cannot be blank."),
+		ValidateChangeAvatar(context.Background(), domainUser.ChangeAvatarRequest{}))
+}
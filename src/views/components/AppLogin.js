@@ -11,6 +11,7 @@ export default {
             login_link: '',
             login_duration_sec: 0,
             countdown_timer: null,
+            event_source: null,
         }
     },
     methods: {
@@ -19,12 +20,14 @@ export default {
                 if (this.loggedIn) throw Error('You are already logged in.');
 
                 await this.submitApi();
+                this.openEventStream();
                 $('#modalLogin').modal({
                     onApprove: function () {
                         return false;
                     },
                     onHidden: () => {
                         this.stopCountdown();
+                        this.closeEventStream();
                     }
                 }).modal('show');
             } catch (err) {
@@ -57,8 +60,8 @@ export default {
             this.countdown_timer = setInterval(() => {
                 if (this.login_duration_sec > 0) {
                     this.login_duration_sec--;
-                } else {
-                    // Auto refresh when countdown reaches 0
+                } else if (!this.event_source) {
+                    // Auto refresh when countdown reaches 0; the event stream pushes new codes by itself
                     this.autoRefresh();
                 }
             }, 1000);
@@ -69,6 +72,37 @@ export default {
                 this.countdown_timer = null;
             }
         },
+        openEventStream() {
+            this.closeEventStream();
+            if (!window.EventSource) return;
+
+            const deviceId = window.http.defaults.headers.common['X-Device-Id'];
+            const query = deviceId ? `?device_id=${deviceId}` : '';
+            this.event_source = new EventSource(`${window.http.defaults.baseURL}/app/events${query}`);
+            this.event_source.addEventListener('qr', (event) => {
+                const data = JSON.parse(event.data);
+                this.login_link = data.image;
+                this.login_duration_sec = data.duration;
+                this.startCountdown();
+            });
+            this.event_source.addEventListener('connected', () => {
+                this.stopCountdown();
+                this.closeEventStream();
+                $('#modalLogin').modal('hide');
+            });
+            this.event_source.onerror = () => {
+                // Fall back to refreshing the QR code on expiry
+                if (this.event_source && this.event_source.readyState === EventSource.CLOSED) {
+                    this.event_source = null;
+                }
+            };
+        },
+        closeEventStream() {
+            if (this.event_source) {
+                this.event_source.close();
+                this.event_source = null;
+            }
+        },
         async autoRefresh() {
             try {
                 console.log('QR Code expired, auto refreshing...');
@@ -83,6 +117,7 @@ export default {
     beforeUnmount() {
         // Clean up timer when component is destroyed
         this.stopCountdown();
+        this.closeEventStream();
     },
     template: `
     <div class="green card" @click="openModal" style="cursor: pointer">